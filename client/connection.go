@@ -42,6 +42,9 @@ type ConnectionArgs struct {
 	// Authentication interactor
 	AuthInteractor []httpbakery.Interactor
 
+	// OpenID Connect tokens (used with the "oidc" authentication type, updated in place on login or refresh)
+	OIDCTokens *OIDCTokens
+
 	// Custom proxy
 	Proxy func(*http.Request) (*url.URL, error)
 
@@ -295,6 +298,38 @@ func ConnectSimpleStreams(url string, args *ConnectionArgs) (ImageServer, error)
 	return &server, nil
 }
 
+// ConnectOCI lets you connect to a remote OCI or Docker image registry over HTTPs.
+//
+// Unless the remote server is trusted by the system CA, the remote certificate must be provided (TLSServerCert).
+func ConnectOCI(url string, args *ConnectionArgs) (ImageServer, error) {
+	logger.Debug("Connecting to a remote OCI registry", logger.Ctx{"URL": url})
+
+	// Cleanup URL
+	url = strings.TrimSuffix(url, "/")
+
+	// Use empty args if not specified
+	if args == nil {
+		args = &ConnectionArgs{}
+	}
+
+	// Initialize the client struct
+	server := ProtocolOCI{
+		httpHost:        url,
+		httpUserAgent:   args.UserAgent,
+		httpCertificate: args.TLSServerCert,
+	}
+
+	// Setup the HTTP client
+	httpClient, err := tlsHTTPClient(args.HTTPClient, args.TLSClientCert, args.TLSClientKey, args.TLSCA, args.TLSServerCert, args.InsecureSkipVerify, args.Proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	server.http = httpClient
+
+	return &server, nil
+}
+
 // Internal function called by ConnectLXD and ConnectPublicLXD
 func httpsLXD(ctx context.Context, requestURL string, args *ConnectionArgs) (InstanceServer, error) {
 	// Use empty args if not specified
@@ -323,7 +358,7 @@ func httpsLXD(ctx context.Context, requestURL string, args *ConnectionArgs) (Ins
 		eventListeners:     make(map[string][]*EventListener),
 	}
 
-	if args.AuthType == "candid" {
+	if shared.StringInSlice(args.AuthType, []string{"candid", "oidc"}) {
 		server.RequireAuthenticated(true)
 	}
 
@@ -340,6 +375,8 @@ func httpsLXD(ctx context.Context, requestURL string, args *ConnectionArgs) (Ins
 	server.http = httpClient
 	if args.AuthType == "candid" {
 		server.setupBakeryClient()
+	} else if args.AuthType == "oidc" {
+		server.oidcClient = newOIDCClient(args.Proxy, args.OIDCTokens)
 	}
 
 	// Test the connection and seed the server information
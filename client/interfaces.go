@@ -88,6 +88,15 @@ type InstanceServer interface {
 	DeleteCertificate(fingerprint string) (err error)
 	CreateCertificateToken(certificate api.CertificatesPost) (op Operation, err error)
 
+	// Authorization group functions ("auth_groups" API extension)
+	GetAuthGroupNames() (names []string, err error)
+	GetAuthGroups() (groups []api.AuthGroup, err error)
+	GetAuthGroup(name string) (group *api.AuthGroup, ETag string, err error)
+	CreateAuthGroup(group api.AuthGroupsPost) (err error)
+	UpdateAuthGroup(name string, group api.AuthGroupPut, ETag string) (err error)
+	RenameAuthGroup(name string, group api.AuthGroupPost) (err error)
+	DeleteAuthGroup(name string) (err error)
+
 	// Container functions
 	//
 	// Deprecated: Those functions are deprecated and won't be updated anymore.
@@ -167,6 +176,10 @@ type InstanceServer interface {
 	UpdateInstances(state api.InstancesPut, ETag string) (op Operation, err error)
 
 	ExecInstance(instanceName string, exec api.InstanceExecPost, args *InstanceExecArgs) (op Operation, err error)
+	GetInstanceExecSessions(instanceName string) (sessions []api.InstanceExecSession, err error)
+	GetInstanceExecSession(instanceName string, sessionID string) (session *api.InstanceExecSession, ETag string, err error)
+	AttachInstanceExecSession(instanceName string, sessionID string, attach api.InstanceExecSessionPost, args *InstanceExecArgs) (op Operation, err error)
+	DeleteInstanceExecSession(instanceName string, sessionID string) (err error)
 	ConsoleInstance(instanceName string, console api.InstanceConsolePost, args *InstanceConsoleArgs) (op Operation, err error)
 	ConsoleInstanceDynamic(instanceName string, console api.InstanceConsolePost, args *InstanceConsoleArgs) (Operation, func(io.ReadWriteCloser) error, error)
 
@@ -202,6 +215,8 @@ type InstanceServer interface {
 	GetInstanceState(name string) (state *api.InstanceState, ETag string, err error)
 	UpdateInstanceState(name string, state api.InstanceStatePut, ETag string) (op Operation, err error)
 
+	GetInstanceGuest(name string) (guest *api.InstanceGuest, err error)
+
 	GetInstanceLogfiles(name string) (logfiles []string, err error)
 	GetInstanceLogfile(name string, filename string) (content io.ReadCloser, err error)
 	DeleteInstanceLogfile(name string, filename string) (err error)
@@ -242,6 +257,16 @@ type InstanceServer interface {
 	RenameNetwork(name string, network api.NetworkPost) (err error)
 	DeleteNetwork(name string) (err error)
 
+	// Network allocation functions ("network_allocations" API extension)
+	GetNetworkAllocations() (allocations []api.NetworkAllocation, err error)
+	GetNetworkAllocationsAllProjects() (allocations []api.NetworkAllocation, err error)
+	GetNetworkReservationAddresses(networkName string) ([]string, error)
+	GetNetworkReservations(networkName string) ([]api.NetworkReservation, error)
+	GetNetworkReservation(networkName string, address string) (reservation *api.NetworkReservation, ETag string, err error)
+	CreateNetworkReservation(networkName string, reservation api.NetworkReservationsPost) error
+	UpdateNetworkReservation(networkName string, address string, reservation api.NetworkReservationPut, ETag string) (err error)
+	DeleteNetworkReservation(networkName string, address string) (err error)
+
 	// Network forward functions ("network_forward" API extension)
 	GetNetworkForwardAddresses(networkName string) ([]string, error)
 	GetNetworkForwards(networkName string) ([]api.NetworkForward, error)
@@ -250,10 +275,19 @@ type InstanceServer interface {
 	UpdateNetworkForward(networkName string, listenAddress string, forward api.NetworkForwardPut, ETag string) (err error)
 	DeleteNetworkForward(networkName string, listenAddress string) (err error)
 
+	// Network load balancer functions ("network_load_balancer" API extension)
+	GetNetworkLoadBalancerAddresses(networkName string) ([]string, error)
+	GetNetworkLoadBalancers(networkName string) ([]api.NetworkLoadBalancer, error)
+	GetNetworkLoadBalancer(networkName string, listenAddress string) (loadBalancer *api.NetworkLoadBalancer, ETag string, err error)
+	CreateNetworkLoadBalancer(networkName string, loadBalancer api.NetworkLoadBalancersPost) error
+	UpdateNetworkLoadBalancer(networkName string, listenAddress string, loadBalancer api.NetworkLoadBalancerPut, ETag string) (err error)
+	DeleteNetworkLoadBalancer(networkName string, listenAddress string) (err error)
+
 	// Network peer functions ("network_peer" API extension)
 	GetNetworkPeerNames(networkName string) ([]string, error)
 	GetNetworkPeers(networkName string) ([]api.NetworkPeer, error)
 	GetNetworkPeer(networkName string, peerName string) (peer *api.NetworkPeer, ETag string, err error)
+	GetNetworkPeerInvitations(networkName string) ([]api.NetworkPeerInvitation, error)
 	CreateNetworkPeer(networkName string, peer api.NetworkPeersPost) error
 	UpdateNetworkPeer(networkName string, peerName string, peer api.NetworkPeerPut, ETag string) (err error)
 	DeleteNetworkPeer(networkName string, peerName string) (err error)
@@ -268,6 +302,15 @@ type InstanceServer interface {
 	RenameNetworkACL(name string, acl api.NetworkACLPost) (err error)
 	DeleteNetworkACL(name string) (err error)
 
+	// Network QoS policy functions ("network_qos" API extension)
+	GetNetworkQoSPolicyNames() (names []string, err error)
+	GetNetworkQoSPolicies() (policies []api.NetworkQoSPolicy, err error)
+	GetNetworkQoSPolicy(name string) (policy *api.NetworkQoSPolicy, ETag string, err error)
+	CreateNetworkQoSPolicy(policy api.NetworkQoSPoliciesPost) (err error)
+	UpdateNetworkQoSPolicy(name string, policy api.NetworkQoSPolicyPut, ETag string) (err error)
+	RenameNetworkQoSPolicy(name string, policy api.NetworkQoSPolicyPost) (err error)
+	DeleteNetworkQoSPolicy(name string) (err error)
+
 	// Network zone functions ("network_dns" API extension)
 	GetNetworkZoneNames() (names []string, err error)
 	GetNetworkZones() (zones []api.NetworkZone, err error)
@@ -352,6 +395,20 @@ type InstanceServer interface {
 	GetStoragePoolVolumeBackupFile(pool string, volName string, name string, req *BackupFileRequest) (resp *BackupFileResponse, err error)
 	CreateStoragePoolVolumeFromBackup(pool string, args StoragePoolVolumeBackupArgs) (op Operation, err error)
 
+	// Storage bucket functions ("storage_buckets" API extension)
+	GetStoragePoolBucketNames(poolName string) ([]string, error)
+	GetStoragePoolBuckets(poolName string) ([]api.StorageBucket, error)
+	GetStoragePoolBucket(poolName string, bucketName string) (bucket *api.StorageBucket, ETag string, err error)
+	CreateStoragePoolBucket(poolName string, bucket api.StorageBucketsPost) error
+	UpdateStoragePoolBucket(poolName string, bucketName string, bucket api.StorageBucketPut, ETag string) (err error)
+	DeleteStoragePoolBucket(poolName string, bucketName string) (err error)
+	GetStoragePoolBucketKeyNames(poolName string, bucketName string) ([]string, error)
+	GetStoragePoolBucketKeys(poolName string, bucketName string) ([]api.StorageBucketKey, error)
+	GetStoragePoolBucketKey(poolName string, bucketName string, keyName string) (key *api.StorageBucketKey, ETag string, err error)
+	CreateStoragePoolBucketKey(poolName string, bucketName string, key api.StorageBucketKeysPost) (newKey *api.StorageBucketKey, err error)
+	UpdateStoragePoolBucketKey(poolName string, bucketName string, keyName string, key api.StorageBucketKeyPut, ETag string) (err error)
+	DeleteStoragePoolBucketKey(poolName string, bucketName string, keyName string) (err error)
+
 	// Cluster functions ("cluster" API extensions)
 	GetCluster() (cluster *api.Cluster, ETag string, err error)
 	UpdateCluster(cluster api.ClusterPut, ETag string) (op Operation, err error)
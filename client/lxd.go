@@ -50,6 +50,8 @@ type ProtocolLXD struct {
 	bakeryInteractor     []httpbakery.Interactor
 	requireAuthenticated bool
 
+	oidcClient *oidcClient
+
 	clusterTarget string
 	project       string
 }
@@ -142,7 +144,7 @@ func (r *ProtocolLXD) GetHTTPClient() (*http.Client, error) {
 	return r.http, nil
 }
 
-// DoHTTP performs a Request, using macaroon authentication if set.
+// DoHTTP performs a Request, using macaroon or OpenID Connect authentication if set.
 func (r *ProtocolLXD) DoHTTP(req *http.Request) (*http.Response, error) {
 	r.addClientHeaders(req)
 
@@ -151,6 +153,10 @@ func (r *ProtocolLXD) DoHTTP(req *http.Request) (*http.Response, error) {
 		return r.bakeryClient.Do(req)
 	}
 
+	if r.oidcClient != nil {
+		return r.oidcClient.do(r.http, req)
+	}
+
 	return r.http.Do(req)
 }
 
@@ -158,6 +164,7 @@ func (r *ProtocolLXD) DoHTTP(req *http.Request) (*http.Response, error) {
 // User-Agent (if r.httpUserAgent is set).
 // X-LXD-authenticated (if r.requireAuthenticated is set).
 // Bakery authentication header and cookie (if r.bakeryClient is set).
+// OpenID Connect bearer token (if r.oidcClient is set and has an access token).
 func (r *ProtocolLXD) addClientHeaders(req *http.Request) {
 	if r.httpUserAgent != "" {
 		req.Header.Set("User-Agent", r.httpUserAgent)
@@ -174,6 +181,10 @@ func (r *ProtocolLXD) addClientHeaders(req *http.Request) {
 			req.AddCookie(cookie)
 		}
 	}
+
+	if r.oidcClient != nil && r.oidcClient.tokens.AccessToken != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", r.oidcClient.tokens.AccessToken))
+	}
 }
 
 // RequireAuthenticated sets whether we expect to be authenticated with the server
@@ -0,0 +1,120 @@
+package lxd
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/lxc/lxd/shared/api"
+)
+
+// GetAuthGroupNames returns a list of authorization group names.
+func (r *ProtocolLXD) GetAuthGroupNames() ([]string, error) {
+	if !r.HasExtension("auth_groups") {
+		return nil, fmt.Errorf(`The server is missing the required "auth_groups" API extension`)
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	baseURL := "/auth/groups"
+	_, err := r.queryStruct("GET", baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetAuthGroups returns a list of authorization group structs.
+func (r *ProtocolLXD) GetAuthGroups() ([]api.AuthGroup, error) {
+	if !r.HasExtension("auth_groups") {
+		return nil, fmt.Errorf(`The server is missing the required "auth_groups" API extension`)
+	}
+
+	groups := []api.AuthGroup{}
+
+	// Fetch the raw value.
+	_, err := r.queryStruct("GET", "/auth/groups?recursion=1", nil, "", &groups)
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// GetAuthGroup returns an authorization group entry for the provided name.
+func (r *ProtocolLXD) GetAuthGroup(name string) (*api.AuthGroup, string, error) {
+	if !r.HasExtension("auth_groups") {
+		return nil, "", fmt.Errorf(`The server is missing the required "auth_groups" API extension`)
+	}
+
+	group := api.AuthGroup{}
+
+	// Fetch the raw value.
+	etag, err := r.queryStruct("GET", fmt.Sprintf("/auth/groups/%s", url.PathEscape(name)), nil, "", &group)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &group, etag, nil
+}
+
+// CreateAuthGroup defines a new authorization group using the provided struct.
+func (r *ProtocolLXD) CreateAuthGroup(group api.AuthGroupsPost) error {
+	if !r.HasExtension("auth_groups") {
+		return fmt.Errorf(`The server is missing the required "auth_groups" API extension`)
+	}
+
+	// Send the request.
+	_, _, err := r.query("POST", "/auth/groups", group, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateAuthGroup updates the authorization group to match the provided struct.
+func (r *ProtocolLXD) UpdateAuthGroup(name string, group api.AuthGroupPut, ETag string) error {
+	if !r.HasExtension("auth_groups") {
+		return fmt.Errorf(`The server is missing the required "auth_groups" API extension`)
+	}
+
+	// Send the request.
+	_, _, err := r.query("PUT", fmt.Sprintf("/auth/groups/%s", url.PathEscape(name)), group, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RenameAuthGroup renames an existing authorization group entry.
+func (r *ProtocolLXD) RenameAuthGroup(name string, group api.AuthGroupPost) error {
+	if !r.HasExtension("auth_groups") {
+		return fmt.Errorf(`The server is missing the required "auth_groups" API extension`)
+	}
+
+	// Send the request.
+	_, _, err := r.query("POST", fmt.Sprintf("/auth/groups/%s", url.PathEscape(name)), group, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteAuthGroup deletes an existing authorization group.
+func (r *ProtocolLXD) DeleteAuthGroup(name string) error {
+	if !r.HasExtension("auth_groups") {
+		return fmt.Errorf(`The server is missing the required "auth_groups" API extension`)
+	}
+
+	// Send the request.
+	_, _, err := r.query("DELETE", fmt.Sprintf("/auth/groups/%s", url.PathEscape(name)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
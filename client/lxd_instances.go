@@ -891,6 +891,12 @@ func (r *ProtocolLXD) ExecInstance(instanceName string, exec api.InstanceExecPos
 		}
 	}
 
+	if exec.Detachable {
+		if !r.HasExtension("instance_exec_sessions") {
+			return nil, fmt.Errorf("The server is missing the required \"instance_exec_sessions\" API extension")
+		}
+	}
+
 	var uri string
 
 	if r.IsAgent() {
@@ -909,130 +915,231 @@ func (r *ProtocolLXD) ExecInstance(instanceName string, exec api.InstanceExecPos
 	if err != nil {
 		return nil, err
 	}
-	opAPI := op.Get()
 
 	// Process additional arguments
 	if args != nil {
-		// Parse the fds
-		fds := map[string]string{}
-
-		value, ok := opAPI.Metadata["fds"]
-		if ok {
-			values := value.(map[string]any)
-			for k, v := range values {
-				fds[k] = v.(string)
-			}
+		err = r.execInstanceStreams(op.Get(), exec.Interactive, args)
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		// Call the control handler with a connection to the control socket
-		if args.Control != nil && fds["control"] != "" {
-			conn, err := r.GetOperationWebsocket(opAPI.ID, fds["control"])
-			if err != nil {
-				return nil, err
-			}
+	return op, nil
+}
+
+// execInstanceStreams connects the websockets of an exec operation to the exec arguments.
+func (r *ProtocolLXD) execInstanceStreams(opAPI api.Operation, interactive bool, args *InstanceExecArgs) error {
+	// Parse the fds
+	fds := map[string]string{}
 
-			go args.Control(conn)
+	value, ok := opAPI.Metadata["fds"]
+	if ok {
+		values := value.(map[string]any)
+		for k, v := range values {
+			fds[k] = v.(string)
 		}
+	}
 
-		if exec.Interactive {
-			// Handle interactive sections
-			if args.Stdin != nil && args.Stdout != nil {
-				// Connect to the websocket
-				conn, err := r.GetOperationWebsocket(opAPI.ID, fds["0"])
-				if err != nil {
-					return nil, err
-				}
+	// Call the control handler with a connection to the control socket
+	if args.Control != nil && fds["control"] != "" {
+		conn, err := r.GetOperationWebsocket(opAPI.ID, fds["control"])
+		if err != nil {
+			return err
+		}
 
-				// And attach stdin and stdout to it
-				go func() {
-					shared.WebsocketSendStream(conn, args.Stdin, -1)
-					<-shared.WebsocketRecvStream(args.Stdout, conn)
-					conn.Close()
+		go args.Control(conn)
+	}
+
+	if interactive {
+		// Handle interactive sections
+		if args.Stdin != nil && args.Stdout != nil {
+			// Connect to the websocket
+			conn, err := r.GetOperationWebsocket(opAPI.ID, fds["0"])
+			if err != nil {
+				return err
+			}
+
+			// And attach stdin and stdout to it
+			go func() {
+				shared.WebsocketSendStream(conn, args.Stdin, -1)
+				<-shared.WebsocketRecvStream(args.Stdout, conn)
+				conn.Close()
 
-					if args.DataDone != nil {
-						close(args.DataDone)
-					}
-				}()
-			} else {
 				if args.DataDone != nil {
 					close(args.DataDone)
 				}
-			}
+			}()
 		} else {
-			// Handle non-interactive sessions
-			dones := map[int]chan bool{}
-			conns := []*websocket.Conn{}
+			if args.DataDone != nil {
+				close(args.DataDone)
+			}
+		}
+	} else {
+		// Handle non-interactive sessions
+		dones := map[int]chan bool{}
+		conns := []*websocket.Conn{}
 
-			// Handle stdin
-			if fds["0"] != "" {
-				conn, err := r.GetOperationWebsocket(opAPI.ID, fds["0"])
-				if err != nil {
-					return nil, err
-				}
+		// Handle stdin
+		if fds["0"] != "" {
+			conn, err := r.GetOperationWebsocket(opAPI.ID, fds["0"])
+			if err != nil {
+				return err
+			}
+
+			conns = append(conns, conn)
+			dones[0] = shared.WebsocketSendStream(conn, args.Stdin, -1)
+		}
+
+		// Handle stdout
+		if fds["1"] != "" {
+			conn, err := r.GetOperationWebsocket(opAPI.ID, fds["1"])
+			if err != nil {
+				return err
+			}
 
-				conns = append(conns, conn)
-				dones[0] = shared.WebsocketSendStream(conn, args.Stdin, -1)
+			conns = append(conns, conn)
+			dones[1] = shared.WebsocketRecvStream(args.Stdout, conn)
+		}
+
+		// Handle stderr
+		if fds["2"] != "" {
+			conn, err := r.GetOperationWebsocket(opAPI.ID, fds["2"])
+			if err != nil {
+				return err
 			}
 
-			// Handle stdout
-			if fds["1"] != "" {
-				conn, err := r.GetOperationWebsocket(opAPI.ID, fds["1"])
-				if err != nil {
-					return nil, err
+			conns = append(conns, conn)
+			dones[2] = shared.WebsocketRecvStream(args.Stderr, conn)
+		}
+
+		// Wait for everything to be done
+		go func() {
+			for i, chDone := range dones {
+				// Skip stdin, dealing with it separately below
+				if i == 0 {
+					continue
 				}
 
-				conns = append(conns, conn)
-				dones[1] = shared.WebsocketRecvStream(args.Stdout, conn)
+				<-chDone
 			}
 
-			// Handle stderr
-			if fds["2"] != "" {
-				conn, err := r.GetOperationWebsocket(opAPI.ID, fds["2"])
-				if err != nil {
-					return nil, err
+			if fds["0"] != "" {
+				if args.Stdin != nil {
+					args.Stdin.Close()
 				}
 
-				conns = append(conns, conn)
-				dones[2] = shared.WebsocketRecvStream(args.Stderr, conn)
+				// Empty the stdin channel but don't block on it as
+				// stdin may be stuck in Read()
+				go func() {
+					<-dones[0]
+				}()
 			}
 
-			// Wait for everything to be done
-			go func() {
-				for i, chDone := range dones {
-					// Skip stdin, dealing with it separately below
-					if i == 0 {
-						continue
-					}
+			for _, conn := range conns {
+				conn.Close()
+			}
 
-					<-chDone
-				}
+			if args.DataDone != nil {
+				close(args.DataDone)
+			}
+		}()
+	}
 
-				if fds["0"] != "" {
-					if args.Stdin != nil {
-						args.Stdin.Close()
-					}
+	return nil
+}
 
-					// Empty the stdin channel but don't block on it as
-					// stdin may be stuck in Read()
-					go func() {
-						<-dones[0]
-					}()
-				}
+// GetInstanceExecSessions returns the detachable exec sessions of the instance.
+func (r *ProtocolLXD) GetInstanceExecSessions(instanceName string) ([]api.InstanceExecSession, error) {
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
 
-				for _, conn := range conns {
-					conn.Close()
-				}
+	if !r.HasExtension("instance_exec_sessions") {
+		return nil, fmt.Errorf("The server is missing the required \"instance_exec_sessions\" API extension")
+	}
 
-				if args.DataDone != nil {
-					close(args.DataDone)
-				}
-			}()
+	// Fetch the raw value
+	sessions := []api.InstanceExecSession{}
+
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s/%s/exec-sessions?recursion=1", path, url.PathEscape(instanceName)), nil, "", &sessions)
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// GetInstanceExecSession returns the detachable exec session of the instance with the given ID.
+func (r *ProtocolLXD) GetInstanceExecSession(instanceName string, sessionID string) (*api.InstanceExecSession, string, error) {
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !r.HasExtension("instance_exec_sessions") {
+		return nil, "", fmt.Errorf("The server is missing the required \"instance_exec_sessions\" API extension")
+	}
+
+	// Fetch the raw value
+	session := api.InstanceExecSession{}
+
+	etag, err := r.queryStruct("GET", fmt.Sprintf("%s/%s/exec-sessions/%s", path, url.PathEscape(instanceName), url.PathEscape(sessionID)), nil, "", &session)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &session, etag, nil
+}
+
+// AttachInstanceExecSession attaches to a detachable exec session of the instance.
+func (r *ProtocolLXD) AttachInstanceExecSession(instanceName string, sessionID string, attach api.InstanceExecSessionPost, args *InstanceExecArgs) (Operation, error) {
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	if !r.HasExtension("instance_exec_sessions") {
+		return nil, fmt.Errorf("The server is missing the required \"instance_exec_sessions\" API extension")
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("%s/%s/exec-sessions/%s", path, url.PathEscape(instanceName), url.PathEscape(sessionID)), attach, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Process additional arguments
+	if args != nil {
+		err = r.execInstanceStreams(op.Get(), true, args)
+		if err != nil {
+			return nil, err
 		}
 	}
 
 	return op, nil
 }
 
+// DeleteInstanceExecSession kills the command of a detachable exec session of the instance and removes the session.
+func (r *ProtocolLXD) DeleteInstanceExecSession(instanceName string, sessionID string) error {
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return err
+	}
+
+	if !r.HasExtension("instance_exec_sessions") {
+		return fmt.Errorf("The server is missing the required \"instance_exec_sessions\" API extension")
+	}
+
+	// Send the request
+	_, _, err = r.query("DELETE", fmt.Sprintf("%s/%s/exec-sessions/%s", path, url.PathEscape(instanceName), url.PathEscape(sessionID)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // GetInstanceFile retrieves the provided path from the instance.
 func (r *ProtocolLXD) GetInstanceFile(instanceName string, filePath string) (io.ReadCloser, *InstanceFileResponse, error) {
 	var err error
@@ -1784,6 +1891,36 @@ func (r *ProtocolLXD) GetInstanceState(name string) (*api.InstanceState, string,
 	return &state, etag, nil
 }
 
+// GetInstanceGuest returns the information reported by the guest of the provided virtual machine name.
+func (r *ProtocolLXD) GetInstanceGuest(name string) (*api.InstanceGuest, error) {
+	var uri string
+
+	if r.IsAgent() {
+		uri = "/guest"
+	} else {
+		if !r.HasExtension("instances_vm_guest_info") {
+			return nil, fmt.Errorf("The server is missing the required \"instances_vm_guest_info\" API extension")
+		}
+
+		path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+		if err != nil {
+			return nil, err
+		}
+
+		uri = fmt.Sprintf("%s/%s/guest", path, url.PathEscape(name))
+	}
+
+	guest := api.InstanceGuest{}
+
+	// Fetch the raw value
+	_, err := r.queryStruct("GET", uri, nil, "", &guest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &guest, nil
+}
+
 // UpdateInstanceState updates the instance to match the requested state.
 func (r *ProtocolLXD) UpdateInstanceState(name string, state api.InstanceStatePut, ETag string) (Operation, error) {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
@@ -2339,6 +2476,10 @@ func (r *ProtocolLXD) CreateInstanceBackup(instanceName string, backup api.Insta
 		return nil, fmt.Errorf("The server is missing the required \"container_backup\" API extension")
 	}
 
+	if backup.IncrementalFrom != "" && !r.HasExtension("backup_vm_incremental") {
+		return nil, fmt.Errorf("The server is missing the required \"backup_vm_incremental\" API extension")
+	}
+
 	// Send the request
 	op, _, err := r.queryOperation("POST", fmt.Sprintf("%s/%s/backups", path, url.PathEscape(instanceName)), backup, "")
 	if err != nil {
@@ -0,0 +1,139 @@
+package lxd
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/lxc/lxd/shared/api"
+)
+
+// GetNetworkAllocations returns a list of the addresses allocated on the managed networks of the project.
+func (r *ProtocolLXD) GetNetworkAllocations() ([]api.NetworkAllocation, error) {
+	if !r.HasExtension("network_allocations") {
+		return nil, fmt.Errorf(`The server is missing the required "network_allocations" API extension`)
+	}
+
+	allocations := []api.NetworkAllocation{}
+
+	// Fetch the raw value.
+	_, err := r.queryStruct("GET", "/network-allocations", nil, "", &allocations)
+	if err != nil {
+		return nil, err
+	}
+
+	return allocations, nil
+}
+
+// GetNetworkAllocationsAllProjects returns a list of the addresses allocated on the managed networks of all projects.
+func (r *ProtocolLXD) GetNetworkAllocationsAllProjects() ([]api.NetworkAllocation, error) {
+	if !r.HasExtension("network_allocations") {
+		return nil, fmt.Errorf(`The server is missing the required "network_allocations" API extension`)
+	}
+
+	allocations := []api.NetworkAllocation{}
+
+	// Fetch the raw value.
+	_, err := r.queryStruct("GET", "/network-allocations?all-projects=true", nil, "", &allocations)
+	if err != nil {
+		return nil, err
+	}
+
+	return allocations, nil
+}
+
+// GetNetworkReservationAddresses returns a list of network reserved addresses.
+func (r *ProtocolLXD) GetNetworkReservationAddresses(networkName string) ([]string, error) {
+	if !r.HasExtension("network_allocations") {
+		return nil, fmt.Errorf(`The server is missing the required "network_allocations" API extension`)
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	baseURL := fmt.Sprintf("/networks/%s/reservations", url.PathEscape(networkName))
+	_, err := r.queryStruct("GET", baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetNetworkReservations returns a list of Network reservation structs.
+func (r *ProtocolLXD) GetNetworkReservations(networkName string) ([]api.NetworkReservation, error) {
+	if !r.HasExtension("network_allocations") {
+		return nil, fmt.Errorf(`The server is missing the required "network_allocations" API extension`)
+	}
+
+	reservations := []api.NetworkReservation{}
+
+	// Fetch the raw value.
+	_, err := r.queryStruct("GET", fmt.Sprintf("/networks/%s/reservations?recursion=1", url.PathEscape(networkName)), nil, "", &reservations)
+	if err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+// GetNetworkReservation returns a Network reservation entry for the provided network and address.
+func (r *ProtocolLXD) GetNetworkReservation(networkName string, address string) (*api.NetworkReservation, string, error) {
+	if !r.HasExtension("network_allocations") {
+		return nil, "", fmt.Errorf(`The server is missing the required "network_allocations" API extension`)
+	}
+
+	reservation := api.NetworkReservation{}
+
+	// Fetch the raw value.
+	etag, err := r.queryStruct("GET", fmt.Sprintf("/networks/%s/reservations/%s", url.PathEscape(networkName), url.PathEscape(address)), nil, "", &reservation)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &reservation, etag, nil
+}
+
+// CreateNetworkReservation defines a new network reservation using the provided struct.
+func (r *ProtocolLXD) CreateNetworkReservation(networkName string, reservation api.NetworkReservationsPost) error {
+	if !r.HasExtension("network_allocations") {
+		return fmt.Errorf(`The server is missing the required "network_allocations" API extension`)
+	}
+
+	// Send the request.
+	_, _, err := r.query("POST", fmt.Sprintf("/networks/%s/reservations", url.PathEscape(networkName)), reservation, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateNetworkReservation updates the network reservation to match the provided struct.
+func (r *ProtocolLXD) UpdateNetworkReservation(networkName string, address string, reservation api.NetworkReservationPut, ETag string) error {
+	if !r.HasExtension("network_allocations") {
+		return fmt.Errorf(`The server is missing the required "network_allocations" API extension`)
+	}
+
+	// Send the request.
+	_, _, err := r.query("PUT", fmt.Sprintf("/networks/%s/reservations/%s", url.PathEscape(networkName), url.PathEscape(address)), reservation, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteNetworkReservation deletes an existing network reservation.
+func (r *ProtocolLXD) DeleteNetworkReservation(networkName string, address string) error {
+	if !r.HasExtension("network_allocations") {
+		return fmt.Errorf(`The server is missing the required "network_allocations" API extension`)
+	}
+
+	// Send the request.
+	_, _, err := r.query("DELETE", fmt.Sprintf("/networks/%s/reservations/%s", url.PathEscape(networkName), url.PathEscape(address)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
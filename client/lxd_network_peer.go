@@ -59,6 +59,23 @@ func (r *ProtocolLXD) GetNetworkPeer(networkName string, peerName string) (*api.
 	return &peer, etag, nil
 }
 
+// GetNetworkPeerInvitations returns a list of pending peerings initiated by other networks towards the network.
+func (r *ProtocolLXD) GetNetworkPeerInvitations(networkName string) ([]api.NetworkPeerInvitation, error) {
+	if !r.HasExtension("network_peer_invitations") {
+		return nil, fmt.Errorf(`The server is missing the required "network_peer_invitations" API extension`)
+	}
+
+	invitations := []api.NetworkPeerInvitation{}
+
+	// Fetch the raw value.
+	_, err := r.queryStruct("GET", fmt.Sprintf("/networks/%s/peer-invitations", url.PathEscape(networkName)), nil, "", &invitations)
+	if err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
 // CreateNetworkPeer defines a new network peer using the provided struct.
 // Returns true if the peer connection has been mutually created. Returns false if peering has been only initiated.
 func (r *ProtocolLXD) CreateNetworkPeer(networkName string, peer api.NetworkPeersPost) error {
@@ -66,6 +83,10 @@ func (r *ProtocolLXD) CreateNetworkPeer(networkName string, peer api.NetworkPeer
 		return fmt.Errorf(`The server is missing the required "network_peer" API extension`)
 	}
 
+	if peer.Type != "" && !r.HasExtension("network_peer_invitations") {
+		return fmt.Errorf(`The server is missing the required "network_peer_invitations" API extension`)
+	}
+
 	// Send the request.
 	_, _, err := r.query("POST", fmt.Sprintf("/networks/%s/peers", url.PathEscape(networkName)), peer, "")
 	if err != nil {
@@ -0,0 +1,120 @@
+package lxd
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/lxc/lxd/shared/api"
+)
+
+// GetNetworkQoSPolicyNames returns a list of network QoS policy names.
+func (r *ProtocolLXD) GetNetworkQoSPolicyNames() ([]string, error) {
+	if !r.HasExtension("network_qos") {
+		return nil, fmt.Errorf(`The server is missing the required "network_qos" API extension`)
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	baseURL := "/network-qos"
+	_, err := r.queryStruct("GET", baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetNetworkQoSPolicies returns a list of network QoS policy structs.
+func (r *ProtocolLXD) GetNetworkQoSPolicies() ([]api.NetworkQoSPolicy, error) {
+	if !r.HasExtension("network_qos") {
+		return nil, fmt.Errorf(`The server is missing the required "network_qos" API extension`)
+	}
+
+	policies := []api.NetworkQoSPolicy{}
+
+	// Fetch the raw value.
+	_, err := r.queryStruct("GET", "/network-qos?recursion=1", nil, "", &policies)
+	if err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// GetNetworkQoSPolicy returns a network QoS policy entry for the provided name.
+func (r *ProtocolLXD) GetNetworkQoSPolicy(name string) (*api.NetworkQoSPolicy, string, error) {
+	if !r.HasExtension("network_qos") {
+		return nil, "", fmt.Errorf(`The server is missing the required "network_qos" API extension`)
+	}
+
+	policy := api.NetworkQoSPolicy{}
+
+	// Fetch the raw value.
+	etag, err := r.queryStruct("GET", fmt.Sprintf("/network-qos/%s", url.PathEscape(name)), nil, "", &policy)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &policy, etag, nil
+}
+
+// CreateNetworkQoSPolicy defines a new network QoS policy using the provided struct.
+func (r *ProtocolLXD) CreateNetworkQoSPolicy(policy api.NetworkQoSPoliciesPost) error {
+	if !r.HasExtension("network_qos") {
+		return fmt.Errorf(`The server is missing the required "network_qos" API extension`)
+	}
+
+	// Send the request.
+	_, _, err := r.query("POST", "/network-qos", policy, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateNetworkQoSPolicy updates the network QoS policy to match the provided struct.
+func (r *ProtocolLXD) UpdateNetworkQoSPolicy(name string, policy api.NetworkQoSPolicyPut, ETag string) error {
+	if !r.HasExtension("network_qos") {
+		return fmt.Errorf(`The server is missing the required "network_qos" API extension`)
+	}
+
+	// Send the request.
+	_, _, err := r.query("PUT", fmt.Sprintf("/network-qos/%s", url.PathEscape(name)), policy, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RenameNetworkQoSPolicy renames an existing network QoS policy entry.
+func (r *ProtocolLXD) RenameNetworkQoSPolicy(name string, policy api.NetworkQoSPolicyPost) error {
+	if !r.HasExtension("network_qos") {
+		return fmt.Errorf(`The server is missing the required "network_qos" API extension`)
+	}
+
+	// Send the request.
+	_, _, err := r.query("POST", fmt.Sprintf("/network-qos/%s", url.PathEscape(name)), policy, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteNetworkQoSPolicy deletes an existing network QoS policy.
+func (r *ProtocolLXD) DeleteNetworkQoSPolicy(name string) error {
+	if !r.HasExtension("network_qos") {
+		return fmt.Errorf(`The server is missing the required "network_qos" API extension`)
+	}
+
+	// Send the request.
+	_, _, err := r.query("DELETE", fmt.Sprintf("/network-qos/%s", url.PathEscape(name)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,314 @@
+package lxd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/macaroon-bakery.v2/httpbakery"
+)
+
+// OIDCTokens represents the tokens obtained from an OpenID Connect provider.
+type OIDCTokens struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// oidcProviderConfig represents the relevant fields of the OpenID Connect discovery document.
+type oidcProviderConfig struct {
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+}
+
+// oidcTokenResponse represents the response of an OAuth 2.0 token endpoint.
+type oidcTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	Error        string `json:"error"`
+	ErrorDesc    string `json:"error_description"`
+}
+
+// oidcDeviceAuthResponse represents the response of an OAuth 2.0 device authorization endpoint.
+type oidcDeviceAuthResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+// oidcClient authenticates requests using OpenID Connect bearer tokens, logging in with the device
+// authorization grant when the server requires it.
+type oidcClient struct {
+	// httpClient is used to talk to the OpenID Connect provider.
+	httpClient *http.Client
+	tokens     *OIDCTokens
+}
+
+// newOIDCClient returns an oidcClient that updates tokens in place.
+func newOIDCClient(proxy func(*http.Request) (*url.URL, error), tokens *OIDCTokens) *oidcClient {
+	if proxy == nil {
+		proxy = http.ProxyFromEnvironment
+	}
+
+	if tokens == nil {
+		tokens = &OIDCTokens{}
+	}
+
+	return &oidcClient{
+		httpClient: &http.Client{Transport: &http.Transport{Proxy: proxy}},
+		tokens:     tokens,
+	}
+}
+
+// do sends the request to LXD with the current access token. If the server reports that authentication is
+// needed, the tokens are refreshed (or a new login is performed) and the request is retried once.
+func (o *oidcClient) do(lxdClient *http.Client, req *http.Request) (*http.Response, error) {
+	if o.tokens.AccessToken != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", o.tokens.AccessToken))
+	}
+
+	resp, err := lxdClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer := resp.Header.Get("X-LXD-OIDC-issuer")
+	clientID := resp.Header.Get("X-LXD-OIDC-clientid")
+	if resp.StatusCode != http.StatusUnauthorized || issuer == "" || clientID == "" {
+		return resp, nil
+	}
+
+	// The request can only be retried if its body can be replayed.
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	_ = resp.Body.Close()
+
+	err = o.authenticate(req.Context(), issuer, clientID, resp.Header.Get("X-LXD-OIDC-audience"))
+	if err != nil {
+		return nil, err
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		retry.Body, err = req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	retry.Header.Set("Authorization", fmt.Sprintf("Bearer %s", o.tokens.AccessToken))
+
+	return lxdClient.Do(retry)
+}
+
+// authenticate obtains a new access token, using the refresh token if possible.
+func (o *oidcClient) authenticate(ctx context.Context, issuer string, clientID string, audience string) error {
+	provider := oidcProviderConfig{}
+	err := o.getJSON(ctx, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", &provider)
+	if err != nil {
+		return fmt.Errorf("Failed getting OpenID Connect configuration: %w", err)
+	}
+
+	if o.tokens.RefreshToken != "" && provider.TokenEndpoint != "" {
+		values := url.Values{}
+		values.Set("grant_type", "refresh_token")
+		values.Set("refresh_token", o.tokens.RefreshToken)
+		values.Set("client_id", clientID)
+
+		token, err := o.postForm(ctx, provider.TokenEndpoint, values)
+		if err == nil && token.Error == "" && token.AccessToken != "" {
+			o.setTokens(token)
+			return nil
+		}
+	}
+
+	return o.deviceLogin(ctx, provider, clientID, audience)
+}
+
+// deviceLogin performs the OAuth 2.0 device authorization grant (RFC 8628).
+func (o *oidcClient) deviceLogin(ctx context.Context, provider oidcProviderConfig, clientID string, audience string) error {
+	if provider.DeviceAuthorizationEndpoint == "" || provider.TokenEndpoint == "" {
+		return fmt.Errorf("OpenID Connect provider doesn't support the device authorization flow")
+	}
+
+	values := url.Values{}
+	values.Set("client_id", clientID)
+	values.Set("scope", "openid offline_access")
+	if audience != "" {
+		values.Set("audience", audience)
+	}
+
+	resp, err := o.post(ctx, provider.DeviceAuthorizationEndpoint, values)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Failed starting OpenID Connect device authorization: %s", resp.Status)
+	}
+
+	deviceAuth := oidcDeviceAuthResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&deviceAuth)
+	if err != nil {
+		return err
+	}
+
+	verificationURI := deviceAuth.VerificationURIComplete
+	if verificationURI == "" {
+		verificationURI = deviceAuth.VerificationURI
+	}
+
+	u, err := url.Parse(verificationURI)
+	if err != nil {
+		return fmt.Errorf("Invalid OpenID Connect verification URI %q: %w", verificationURI, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Code: %s\n\n", deviceAuth.UserCode)
+	_ = httpbakery.OpenWebBrowser(u)
+
+	interval := time.Duration(deviceAuth.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	expiresIn := time.Duration(deviceAuth.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = 5 * time.Minute
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, expiresIn)
+	defer cancel()
+
+	values = url.Values{}
+	values.Set("grant_type", "urn:ietf:params:oauth:grant-type:device_code")
+	values.Set("device_code", deviceAuth.DeviceCode)
+	values.Set("client_id", clientID)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Timed out waiting for OpenID Connect login")
+		case <-time.After(interval):
+		}
+
+		token, err := o.postForm(ctx, provider.TokenEndpoint, values)
+		if err != nil {
+			return err
+		}
+
+		switch token.Error {
+		case "":
+			if token.AccessToken == "" {
+				return fmt.Errorf("OpenID Connect provider didn't return an access token")
+			}
+
+			o.setTokens(token)
+			return nil
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			interval += 5 * time.Second
+			continue
+		default:
+			if token.ErrorDesc != "" {
+				return fmt.Errorf("OpenID Connect login failed: %s", token.ErrorDesc)
+			}
+
+			return fmt.Errorf("OpenID Connect login failed: %s", token.Error)
+		}
+	}
+}
+
+// setTokens updates the tokens from a token endpoint response.
+func (o *oidcClient) setTokens(token *oidcTokenResponse) {
+	o.tokens.AccessToken = token.AccessToken
+
+	// Keep the previous refresh token if the provider didn't issue a new one.
+	if token.RefreshToken != "" {
+		o.tokens.RefreshToken = token.RefreshToken
+	}
+
+	o.tokens.Expiry = time.Time{}
+	if token.ExpiresIn > 0 {
+		o.tokens.Expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+}
+
+// post sends a form encoded POST request.
+func (o *oidcClient) post(ctx context.Context, endpoint string, values url.Values) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBufferString(values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	return o.httpClient.Do(req)
+}
+
+// postForm sends a form encoded POST request to a token endpoint and decodes the response.
+// OAuth 2.0 errors are returned in the Error field of the response rather than as an error.
+func (o *oidcClient) postForm(ctx context.Context, endpoint string, values url.Values) (*oidcTokenResponse, error) {
+	resp, err := o.post(ctx, endpoint, values)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	token := &oidcTokenResponse{}
+	err = json.Unmarshal(body, token)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing OpenID Connect token response (%s): %w", resp.Status, err)
+	}
+
+	if resp.StatusCode != http.StatusOK && token.Error == "" {
+		return nil, fmt.Errorf("Failed getting OpenID Connect token: %s", resp.Status)
+	}
+
+	return token, nil
+}
+
+// getJSON performs a GET request and decodes the JSON response into target.
+func (o *oidcClient) getJSON(ctx context.Context, endpoint string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Unexpected response status %q", resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(target)
+}
@@ -0,0 +1,80 @@
+package lxd
+
+import (
+	"fmt"
+
+	"github.com/lxc/lxd/shared/api"
+)
+
+// Image handling functions
+
+// GetImages isn't relevant for the OCI protocol.
+func (r *ProtocolOCI) GetImages() ([]api.Image, error) {
+	return nil, fmt.Errorf("Listing images isn't supported by the OCI protocol")
+}
+
+// GetImageFingerprints isn't relevant for the OCI protocol.
+func (r *ProtocolOCI) GetImageFingerprints() ([]string, error) {
+	return nil, fmt.Errorf("Listing images isn't supported by the OCI protocol")
+}
+
+// GetImage returns the public image entry for the given image reference. Fingerprints of OCI images are only
+// known once an LXD server has built them, so the reference is used in its place.
+func (r *ProtocolOCI) GetImage(name string) (*api.Image, string, error) {
+	image := api.Image{}
+	image.Fingerprint = name
+	image.Public = true
+	image.Type = "container"
+
+	return &image, "", nil
+}
+
+// GetImageFile isn't relevant for the OCI protocol, images are pulled by the LXD server.
+func (r *ProtocolOCI) GetImageFile(fingerprint string, req ImageFileRequest) (*ImageFileResponse, error) {
+	return nil, fmt.Errorf("Downloading image files isn't supported by the OCI protocol")
+}
+
+// GetImageSecret isn't relevant for the OCI protocol.
+func (r *ProtocolOCI) GetImageSecret(fingerprint string) (string, error) {
+	return "", fmt.Errorf("Private images aren't supported by the OCI protocol")
+}
+
+// GetPrivateImage isn't relevant for the OCI protocol.
+func (r *ProtocolOCI) GetPrivateImage(fingerprint string, secret string) (*api.Image, string, error) {
+	return nil, "", fmt.Errorf("Private images aren't supported by the OCI protocol")
+}
+
+// GetPrivateImageFile isn't relevant for the OCI protocol.
+func (r *ProtocolOCI) GetPrivateImageFile(fingerprint string, secret string, req ImageFileRequest) (*ImageFileResponse, error) {
+	return nil, fmt.Errorf("Private images aren't supported by the OCI protocol")
+}
+
+// GetImageAliases isn't relevant for the OCI protocol.
+func (r *ProtocolOCI) GetImageAliases() ([]api.ImageAliasesEntry, error) {
+	return nil, fmt.Errorf("Listing aliases isn't supported by the OCI protocol")
+}
+
+// GetImageAliasNames isn't relevant for the OCI protocol.
+func (r *ProtocolOCI) GetImageAliasNames() ([]string, error) {
+	return nil, fmt.Errorf("Listing aliases isn't supported by the OCI protocol")
+}
+
+// GetImageAlias isn't relevant for the OCI protocol, images are referenced by name.
+func (r *ProtocolOCI) GetImageAlias(name string) (*api.ImageAliasesEntry, string, error) {
+	return nil, "", fmt.Errorf("Aliases aren't supported by the OCI protocol")
+}
+
+// GetImageAliasType isn't relevant for the OCI protocol, images are referenced by name.
+func (r *ProtocolOCI) GetImageAliasType(imageType string, name string) (*api.ImageAliasesEntry, string, error) {
+	return nil, "", fmt.Errorf("Aliases aren't supported by the OCI protocol")
+}
+
+// GetImageAliasArchitectures isn't relevant for the OCI protocol, images are referenced by name.
+func (r *ProtocolOCI) GetImageAliasArchitectures(imageType string, name string) (map[string]*api.ImageAliasesEntry, error) {
+	return nil, fmt.Errorf("Aliases aren't supported by the OCI protocol")
+}
+
+// ExportImage exports (copies) an image to a remote server.
+func (r *ProtocolOCI) ExportImage(fingerprint string, image api.ImageExportPost) (Operation, error) {
+	return nil, fmt.Errorf("Exporting images isn't supported by the OCI protocol")
+}
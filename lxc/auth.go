@@ -0,0 +1,863 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/lxc/lxd/lxc/utils"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	cli "github.com/lxc/lxd/shared/cmd"
+	"github.com/lxc/lxd/shared/i18n"
+	"github.com/lxc/lxd/shared/termios"
+)
+
+type cmdAuth struct {
+	global *cmdGlobal
+}
+
+func (c *cmdAuth) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("auth")
+	cmd.Short = i18n.G("Manage authorization")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage authorization`))
+
+	// Group
+	authGroupCmd := cmdAuthGroup{global: c.global}
+	cmd.AddCommand(authGroupCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { cmd.Usage() }
+
+	return cmd
+}
+
+type cmdAuthGroup struct {
+	global *cmdGlobal
+}
+
+func (c *cmdAuthGroup) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("group")
+	cmd.Short = i18n.G("Manage authorization groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage authorization groups
+
+Authorization groups grant permissions on projects, instances, networks and
+storage pools to the client certificates that are members of the group.`))
+
+	// Create
+	authGroupCreateCmd := cmdAuthGroupCreate{global: c.global}
+	cmd.AddCommand(authGroupCreateCmd.Command())
+
+	// Delete
+	authGroupDeleteCmd := cmdAuthGroupDelete{global: c.global}
+	cmd.AddCommand(authGroupDeleteCmd.Command())
+
+	// Edit
+	authGroupEditCmd := cmdAuthGroupEdit{global: c.global}
+	cmd.AddCommand(authGroupEditCmd.Command())
+
+	// Identity
+	authGroupIdentityCmd := cmdAuthGroupIdentity{global: c.global}
+	cmd.AddCommand(authGroupIdentityCmd.Command())
+
+	// List
+	authGroupListCmd := cmdAuthGroupList{global: c.global}
+	cmd.AddCommand(authGroupListCmd.Command())
+
+	// Permission
+	authGroupPermissionCmd := cmdAuthGroupPermission{global: c.global}
+	cmd.AddCommand(authGroupPermissionCmd.Command())
+
+	// Rename
+	authGroupRenameCmd := cmdAuthGroupRename{global: c.global}
+	cmd.AddCommand(authGroupRenameCmd.Command())
+
+	// Show
+	authGroupShowCmd := cmdAuthGroupShow{global: c.global}
+	cmd.AddCommand(authGroupShowCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { cmd.Usage() }
+
+	return cmd
+}
+
+// Create
+type cmdAuthGroupCreate struct {
+	global *cmdGlobal
+
+	flagDescription string
+}
+
+func (c *cmdAuthGroupCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<group>"))
+	cmd.Short = i18n.G("Create authorization groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Create authorization groups`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc auth group create viewers
+
+lxc auth group create viewers < group.yaml
+    Create an authorization group with the permissions and identities from group.yaml`))
+	cmd.Flags().StringVar(&c.flagDescription, "description", "", i18n.G("Group description")+"``")
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdAuthGroupCreate) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing group name"))
+	}
+
+	// If stdin isn't a terminal, read yaml from it.
+	var groupPut api.AuthGroupPut
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		err = yaml.UnmarshalStrict(contents, &groupPut)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.flagDescription != "" {
+		groupPut.Description = c.flagDescription
+	}
+
+	// Create the authorization group.
+	group := api.AuthGroupsPost{
+		AuthGroupPost: api.AuthGroupPost{
+			Name: resource.name,
+		},
+		AuthGroupPut: groupPut,
+	}
+
+	err = resource.server.CreateAuthGroup(group)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Authorization group %s created")+"\n", resource.name)
+	}
+
+	return nil
+}
+
+// Delete
+type cmdAuthGroupDelete struct {
+	global *cmdGlobal
+}
+
+func (c *cmdAuthGroupDelete) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<group>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Delete authorization groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Delete authorization groups`))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdAuthGroupDelete) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing group name"))
+	}
+
+	// Delete the authorization group.
+	err = resource.server.DeleteAuthGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Authorization group %s deleted")+"\n", resource.name)
+	}
+
+	return nil
+}
+
+// Edit
+type cmdAuthGroupEdit struct {
+	global *cmdGlobal
+}
+
+func (c *cmdAuthGroupEdit) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<group>"))
+	cmd.Short = i18n.G("Edit authorization groups as YAML")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Edit authorization groups as YAML`))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdAuthGroupEdit) helpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the authorization group.
+### Any line starting with a '# will be ignored.
+###
+### A sample configuration looks like:
+### name: operators
+### description: Operators of the web instances
+### permissions:
+### - entity_type: project
+###   project: web
+###   entitlement: can_view
+### - entity_type: instance
+###   project: web
+###   name: web01
+###   entitlement: can_exec
+### identities:
+### - 2a1ab6d9e1a9d64b31d1fd1d95e7d79cc1b4ae75dfa9be1a6af7e9a4eb1a8e8e
+###
+### Note that the name is shown but cannot be changed`)
+}
+
+func (c *cmdAuthGroupEdit) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing group name"))
+	}
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		// Allow output of `lxc auth group show` command to be passed in here, but only take the contents
+		// of the AuthGroupPut fields when updating the group. The other fields are silently discarded.
+		newdata := api.AuthGroup{}
+		err = yaml.UnmarshalStrict(contents, &newdata)
+		if err != nil {
+			return err
+		}
+
+		return resource.server.UpdateAuthGroup(resource.name, newdata.Writable(), "")
+	}
+
+	// Get the current config.
+	group, etag, err := resource.server.GetAuthGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&group)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor.
+	content, err := shared.TextEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor.
+		newdata := api.AuthGroup{} // We show the full group info, but only send the writable fields.
+		err = yaml.UnmarshalStrict(content, &newdata)
+		if err == nil {
+			err = resource.server.UpdateAuthGroup(resource.name, newdata.Writable(), etag)
+		}
+
+		// Respawn the editor.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// List
+type cmdAuthGroupList struct {
+	global *cmdGlobal
+
+	flagFormat string
+}
+
+func (c *cmdAuthGroupList) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List available authorization groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`List available authorization groups`))
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdAuthGroupList) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name != "" {
+		return fmt.Errorf(i18n.G("Filtering isn't supported yet"))
+	}
+
+	groups, err := resource.server.GetAuthGroups()
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, group := range groups {
+		details := []string{
+			group.Name,
+			group.Description,
+			fmt.Sprintf("%d", len(group.Permissions)),
+			fmt.Sprintf("%d", len(group.Identities)),
+		}
+
+		data = append(data, details)
+	}
+
+	sort.Sort(utils.ByName(data))
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("DESCRIPTION"),
+		i18n.G("PERMISSIONS"),
+		i18n.G("IDENTITIES"),
+	}
+
+	return utils.RenderTable(c.flagFormat, header, data, groups)
+}
+
+// Rename
+type cmdAuthGroupRename struct {
+	global *cmdGlobal
+}
+
+func (c *cmdAuthGroupRename) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("rename", i18n.G("[<remote>:]<group> <new-name>"))
+	cmd.Aliases = []string{"mv"}
+	cmd.Short = i18n.G("Rename authorization groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Rename authorization groups`))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdAuthGroupRename) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing group name"))
+	}
+
+	// Rename the authorization group.
+	err = resource.server.RenameAuthGroup(resource.name, api.AuthGroupPost{Name: args[1]})
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Authorization group %s renamed to %s")+"\n", resource.name, args[1])
+	}
+
+	return nil
+}
+
+// Show
+type cmdAuthGroupShow struct {
+	global *cmdGlobal
+}
+
+func (c *cmdAuthGroupShow) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<group>"))
+	cmd.Short = i18n.G("Show authorization group configurations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Show authorization group configurations`))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdAuthGroupShow) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing group name"))
+	}
+
+	// Show the authorization group.
+	group, _, err := resource.server.GetAuthGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&group)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Identity
+type cmdAuthGroupIdentity struct {
+	global *cmdGlobal
+}
+
+func (c *cmdAuthGroupIdentity) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("identity")
+	cmd.Short = i18n.G("Manage authorization group identities")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage authorization group identities
+
+Identities are referred to by the fingerprint of their trusted client certificate.`))
+
+	// Add
+	authGroupIdentityAddCmd := cmdAuthGroupIdentityAdd{global: c.global}
+	cmd.AddCommand(authGroupIdentityAddCmd.Command())
+
+	// Remove
+	authGroupIdentityRemoveCmd := cmdAuthGroupIdentityRemove{global: c.global}
+	cmd.AddCommand(authGroupIdentityRemoveCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { cmd.Usage() }
+
+	return cmd
+}
+
+// Identity add
+type cmdAuthGroupIdentityAdd struct {
+	global *cmdGlobal
+}
+
+func (c *cmdAuthGroupIdentityAdd) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("add", i18n.G("[<remote>:]<group> <fingerprint>"))
+	cmd.Short = i18n.G("Add identities to authorization groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Add identities to authorization groups`))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdAuthGroupIdentityAdd) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing group name"))
+	}
+
+	// Get the authorization group.
+	group, etag, err := resource.server.GetAuthGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if shared.StringInSlice(args[1], group.Identities) {
+		return fmt.Errorf(i18n.G("Identity %s is already a member of authorization group %s"), args[1], resource.name)
+	}
+
+	group.Identities = append(group.Identities, args[1])
+
+	return resource.server.UpdateAuthGroup(resource.name, group.Writable(), etag)
+}
+
+// Identity remove
+type cmdAuthGroupIdentityRemove struct {
+	global *cmdGlobal
+}
+
+func (c *cmdAuthGroupIdentityRemove) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("remove", i18n.G("[<remote>:]<group> <fingerprint>"))
+	cmd.Short = i18n.G("Remove identities from authorization groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Remove identities from authorization groups`))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdAuthGroupIdentityRemove) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing group name"))
+	}
+
+	// Get the authorization group.
+	group, etag, err := resource.server.GetAuthGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if !shared.StringInSlice(args[1], group.Identities) {
+		return fmt.Errorf(i18n.G("Identity %s isn't a member of authorization group %s"), args[1], resource.name)
+	}
+
+	identities := make([]string, 0, len(group.Identities))
+	for _, identity := range group.Identities {
+		if identity == args[1] {
+			continue
+		}
+
+		identities = append(identities, identity)
+	}
+
+	group.Identities = identities
+
+	return resource.server.UpdateAuthGroup(resource.name, group.Writable(), etag)
+}
+
+// Permission
+type cmdAuthGroupPermission struct {
+	global *cmdGlobal
+}
+
+func (c *cmdAuthGroupPermission) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("permission")
+	cmd.Short = i18n.G("Manage authorization group permissions")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage authorization group permissions
+
+Permissions are made of an entity type, an entity and an entitlement.
+
+Supported entity types and entity formats:
+ - project: <project>
+ - instance: <project>/<instance>
+ - network: <project>/<network>
+ - storage_pool: <pool>
+
+Supported entitlements are can_view, can_edit, can_exec and can_manage_snapshots.
+Networks and storage pools only support can_view and can_edit.`))
+
+	// Add
+	authGroupPermissionAddCmd := cmdAuthGroupPermissionAdd{global: c.global}
+	cmd.AddCommand(authGroupPermissionAddCmd.Command())
+
+	// Remove
+	authGroupPermissionRemoveCmd := cmdAuthGroupPermissionRemove{global: c.global}
+	cmd.AddCommand(authGroupPermissionRemoveCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { cmd.Usage() }
+
+	return cmd
+}
+
+// authGroupPermissionFromArgs builds a permission from the entity type, entity and entitlement arguments.
+func authGroupPermissionFromArgs(entityType string, entity string, entitlement string) (api.AuthPermission, error) {
+	permission := api.AuthPermission{
+		EntityType:  entityType,
+		Entitlement: entitlement,
+	}
+
+	switch entityType {
+	case "project":
+		permission.Project = entity
+	case "storage_pool":
+		permission.Name = entity
+	case "instance", "network":
+		fields := strings.SplitN(entity, "/", 2)
+		if len(fields) != 2 || fields[0] == "" || fields[1] == "" {
+			return permission, fmt.Errorf(i18n.G("Entities of type %s must be specified as <project>/<name>"), entityType)
+		}
+
+		permission.Project = fields[0]
+		permission.Name = fields[1]
+	default:
+		return permission, fmt.Errorf(i18n.G("Unknown entity type %s"), entityType)
+	}
+
+	return permission, nil
+}
+
+// Permission add
+type cmdAuthGroupPermissionAdd struct {
+	global *cmdGlobal
+}
+
+func (c *cmdAuthGroupPermissionAdd) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("add", i18n.G("[<remote>:]<group> <entity_type> <entity> <entitlement>"))
+	cmd.Short = i18n.G("Add permissions to authorization groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Add permissions to authorization groups`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc auth group permission add viewers project default can_view
+    Allow members of "viewers" to view everything in the "default" project.
+
+lxc auth group permission add operators instance default/c1 can_exec
+    Allow members of "operators" to run commands in instance "c1" of the "default" project.`))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdAuthGroupPermissionAdd) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 4, 4)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing group name"))
+	}
+
+	permission, err := authGroupPermissionFromArgs(args[1], args[2], args[3])
+	if err != nil {
+		return err
+	}
+
+	// Get the authorization group.
+	group, etag, err := resource.server.GetAuthGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range group.Permissions {
+		if existing == permission {
+			return fmt.Errorf(i18n.G("Permission already exists in authorization group %s"), resource.name)
+		}
+	}
+
+	group.Permissions = append(group.Permissions, permission)
+
+	return resource.server.UpdateAuthGroup(resource.name, group.Writable(), etag)
+}
+
+// Permission remove
+type cmdAuthGroupPermissionRemove struct {
+	global *cmdGlobal
+}
+
+func (c *cmdAuthGroupPermissionRemove) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("remove", i18n.G("[<remote>:]<group> <entity_type> <entity> <entitlement>"))
+	cmd.Short = i18n.G("Remove permissions from authorization groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Remove permissions from authorization groups`))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdAuthGroupPermissionRemove) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 4, 4)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing group name"))
+	}
+
+	permission, err := authGroupPermissionFromArgs(args[1], args[2], args[3])
+	if err != nil {
+		return err
+	}
+
+	// Get the authorization group.
+	group, etag, err := resource.server.GetAuthGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	permissions := make([]api.AuthPermission, 0, len(group.Permissions))
+	for _, existing := range group.Permissions {
+		if existing == permission {
+			found = true
+			continue
+		}
+
+		permissions = append(permissions, existing)
+	}
+
+	if !found {
+		return fmt.Errorf(i18n.G("Permission not found in authorization group %s"), resource.name)
+	}
+
+	group.Permissions = permissions
+
+	return resource.server.UpdateAuthGroup(resource.name, group.Writable(), etag)
+}
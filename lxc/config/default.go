@@ -14,6 +14,13 @@ var ImagesRemote = Remote{
 	Protocol: "simplestreams",
 }
 
+// DockerRemote is the Docker Hub image registry (over OCI)
+var DockerRemote = Remote{
+	Addr:     "https://docker.io",
+	Public:   true,
+	Protocol: "oci",
+}
+
 // UbuntuRemote is the Ubuntu image server (over simplestreams)
 var UbuntuRemote = Remote{
 	Addr:     "https://cloud-images.ubuntu.com/releases",
@@ -39,6 +46,7 @@ var StaticRemotes = map[string]Remote{
 
 // DefaultRemotes is the list of default remotes
 var DefaultRemotes = map[string]Remote{
+	"docker":       DockerRemote,
 	"images":       ImagesRemote,
 	"local":        LocalRemote,
 	"ubuntu":       UbuntuRemote,
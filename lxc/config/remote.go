@@ -2,6 +2,7 @@ package config
 
 import (
 	"crypto/x509"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"io/ioutil"
@@ -65,7 +66,7 @@ func (c *Config) GetInstanceServer(name string) (lxd.InstanceServer, error) {
 	}
 
 	// Quick checks.
-	if remote.Public || remote.Protocol == "simplestreams" {
+	if remote.Public || shared.StringInSlice(remote.Protocol, []string{"simplestreams", "oci"}) {
 		return nil, fmt.Errorf("The remote isn't a private LXD server")
 	}
 
@@ -94,7 +95,7 @@ func (c *Config) GetInstanceServer(name string) (lxd.InstanceServer, error) {
 	}
 
 	// HTTPs
-	if remote.AuthType != "candid" && (args.TLSClientCert == "" || args.TLSClientKey == "") {
+	if !shared.StringInSlice(remote.AuthType, []string{"candid", "oidc"}) && (args.TLSClientCert == "" || args.TLSClientKey == "") {
 		return nil, fmt.Errorf("Missing TLS client certificate and key")
 	}
 
@@ -161,6 +162,16 @@ func (c *Config) GetImageServer(name string) (lxd.ImageServer, error) {
 		return d, nil
 	}
 
+	// HTTPs (OCI registry)
+	if remote.Protocol == "oci" {
+		d, err := lxd.ConnectOCI(remote.Addr, args)
+		if err != nil {
+			return nil, err
+		}
+
+		return d, nil
+	}
+
 	// HTTPs (public LXD)
 	if remote.Public {
 		d, err := lxd.ConnectPublicLXD(remote.Addr, args)
@@ -243,6 +254,30 @@ func (c *Config) getConnectionArgs(name string) (*lxd.ConnectionArgs, error) {
 		}
 
 		args.CookieJar = c.cookieJars[name]
+	} else if args.AuthType == "oidc" {
+		if c.oidcTokens == nil || c.oidcTokens[name] == nil {
+			tokens := &lxd.OIDCTokens{}
+
+			if shared.PathExists(c.OIDCTokenPath(name)) {
+				content, err := ioutil.ReadFile(c.OIDCTokenPath(name))
+				if err != nil {
+					return nil, err
+				}
+
+				err = json.Unmarshal(content, tokens)
+				if err != nil {
+					return nil, fmt.Errorf("Failed parsing OpenID Connect tokens %q: %w", c.OIDCTokenPath(name), err)
+				}
+			}
+
+			if c.oidcTokens == nil {
+				c.oidcTokens = map[string]*lxd.OIDCTokens{}
+			}
+
+			c.oidcTokens[name] = tokens
+		}
+
+		args.OIDCTokens = c.oidcTokens[name]
 	}
 
 	// Stop here if no TLS involved
@@ -261,7 +296,7 @@ func (c *Config) getConnectionArgs(name string) (*lxd.ConnectionArgs, error) {
 	}
 
 	// Stop here if no client certificate involved
-	if remote.Protocol == "simplestreams" || remote.AuthType == "candid" {
+	if shared.StringInSlice(remote.Protocol, []string{"simplestreams", "oci"}) || shared.StringInSlice(remote.AuthType, []string{"candid", "oidc"}) {
 		return &args, nil
 	}
 
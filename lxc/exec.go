@@ -31,6 +31,8 @@ type cmdExec struct {
 	flagUser                uint32
 	flagGroup               uint32
 	flagCwd                 string
+	flagDetachable          bool
+	flagAttach              string
 
 	interactive bool
 }
@@ -49,7 +51,17 @@ executable, passing the shell commands as arguments, for example:
 
   lxc exec <instance> -- sh -c "cd /tmp && pwd"
 
-Mode defaults to non-interactive, interactive mode is selected if both stdin AND stdout are terminals (stderr is ignored).`))
+Mode defaults to non-interactive, interactive mode is selected if both stdin AND stdout are terminals (stderr is ignored).
+
+With --detachable, the interactive command runs in a session that survives the client
+disconnecting, and that can be reattached to with --attach. The sessions are listed by
+"lxc info".`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc exec c1 --detachable -- bash
+    Run bash in a detachable session.
+
+lxc exec c1 --attach 0d9e5d1c-7d7b-4f6e-9a48-4b36dbd0a3b6
+    Reattach to the session.`))
 
 	cmd.RunE = c.Run
 	cmd.Flags().StringArrayVar(&c.flagEnvironment, "env", nil, i18n.G("Environment variable to set (e.g. HOME=/home/foo)")+"``")
@@ -60,6 +72,8 @@ Mode defaults to non-interactive, interactive mode is selected if both stdin AND
 	cmd.Flags().Uint32Var(&c.flagUser, "user", 0, i18n.G("User ID to run the command as (default 0)")+"``")
 	cmd.Flags().Uint32Var(&c.flagGroup, "group", 0, i18n.G("Group ID to run the command as (default 0)")+"``")
 	cmd.Flags().StringVar(&c.flagCwd, "cwd", "", i18n.G("Directory to run the command in (default /root)")+"``")
+	cmd.Flags().BoolVar(&c.flagDetachable, "detachable", false, i18n.G("Run the command in a session surviving disconnects (requires interactive mode)"))
+	cmd.Flags().StringVar(&c.flagAttach, "attach", "", i18n.G("Attach to a detachable exec session")+"``")
 
 	return cmd
 }
@@ -85,11 +99,22 @@ func (c *cmdExec) Run(cmd *cobra.Command, args []string) error {
 	conf := c.global.conf
 
 	// Quick checks.
-	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	var exit bool
+	var err error
+	if c.flagAttach != "" {
+		exit, err = c.global.CheckArgs(cmd, args, 1, 1)
+	} else {
+		exit, err = c.global.CheckArgs(cmd, args, 2, -1)
+	}
+
 	if exit {
 		return err
 	}
 
+	if c.flagAttach != "" && c.flagDetachable {
+		return fmt.Errorf(i18n.G("You can't pass --attach and --detachable at the same time"))
+	}
+
 	if c.flagForceInteractive && c.flagForceNonInteractive {
 		return fmt.Errorf(i18n.G("You can't pass -t and -T at the same time"))
 	}
@@ -133,7 +158,9 @@ func (c *cmdExec) Run(cmd *cobra.Command, args []string) error {
 	stdoutTerminal := termios.IsTerminal(stdoutFd)
 
 	// Determine interaction mode
-	if c.flagDisableStdin {
+	if c.flagAttach != "" {
+		c.interactive = true
+	} else if c.flagDisableStdin {
 		c.interactive = false
 	} else if c.flagMode == "interactive" || c.flagForceInteractive {
 		c.interactive = true
@@ -143,6 +170,10 @@ func (c *cmdExec) Run(cmd *cobra.Command, args []string) error {
 		c.interactive = stdinTerminal && stdoutTerminal
 	}
 
+	if c.flagDetachable && !c.interactive {
+		return fmt.Errorf(i18n.G("Detachable exec sessions require interactive mode"))
+	}
+
 	// Record terminal state
 	var oldttystate *termios.State
 	if c.interactive && stdinTerminal {
@@ -174,6 +205,27 @@ func (c *cmdExec) Run(cmd *cobra.Command, args []string) error {
 
 	stdout := getStdout()
 
+	if c.flagAttach != "" {
+		attach := api.InstanceExecSessionPost{
+			Width:  width,
+			Height: height,
+		}
+
+		execArgs := lxd.InstanceExecArgs{
+			Stdin:    stdin,
+			Stdout:   stdout,
+			Control:  handler,
+			DataDone: make(chan bool),
+		}
+
+		op, err := d.AttachInstanceExecSession(name, c.flagAttach, attach, &execArgs)
+		if err != nil {
+			return err
+		}
+
+		return c.waitSession(op, execArgs.DataDone, remote, name, c.flagAttach, oldttystate)
+	}
+
 	// Prepare the command
 	req := api.InstanceExecPost{
 		Command:     args[1:],
@@ -185,6 +237,7 @@ func (c *cmdExec) Run(cmd *cobra.Command, args []string) error {
 		User:        c.flagUser,
 		Group:       c.flagGroup,
 		Cwd:         c.flagCwd,
+		Detachable:  c.flagDetachable,
 	}
 
 	execArgs := lxd.InstanceExecArgs{
@@ -201,6 +254,12 @@ func (c *cmdExec) Run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if c.flagDetachable {
+		sessionID, _ := op.Get().Metadata["session"].(string)
+
+		return c.waitSession(op, execArgs.DataDone, remote, name, sessionID, oldttystate)
+	}
+
 	// Wait for the operation to complete
 	err = op.Wait()
 	if err != nil {
@@ -214,3 +273,33 @@ func (c *cmdExec) Run(cmd *cobra.Command, args []string) error {
 	c.global.ret = int(opAPI.Metadata["return"].(float64))
 	return nil
 }
+
+// waitSession waits for the client to detach from the exec session or for its command to exit. When detached, it
+// tells how to reattach to the session.
+func (c *cmdExec) waitSession(op lxd.Operation, dataDone chan bool, remote string, name string, sessionID string, oldttystate *termios.State) error {
+	err := op.Wait()
+	if err == nil {
+		// Wait for any remaining I/O to be flushed
+		<-dataDone
+
+		exitCode, ok := op.Get().Metadata["return"].(float64)
+		if ok {
+			c.global.ret = int(exitCode)
+			return nil
+		}
+	}
+
+	// Restore the terminal before printing.
+	if oldttystate != nil {
+		termios.Restore(getStdinFd(), oldttystate)
+	}
+
+	instance := name
+	if remote != c.global.conf.DefaultRemote {
+		instance = remote + ":" + name
+	}
+
+	fmt.Fprintf(os.Stderr, i18n.G("Detached from exec session %s, reattach with: lxc exec %s --attach %s")+"\n", sessionID, instance, sessionID)
+
+	return err
+}
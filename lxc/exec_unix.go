@@ -62,6 +62,12 @@ func (c *cmdExec) controlSocketHandler(control *websocket.Conn) {
 				return
 			}
 		case unix.SIGHUP:
+			if c.flagDetachable || c.flagAttach != "" {
+				// Closing the control connection detaches from the session, leaving the command running.
+				logger.Debugf("Received '%s signal', detaching from the exec session.", sig)
+				return
+			}
+
 			file, err := os.OpenFile("/dev/tty", os.O_RDONLY|unix.O_NOCTTY|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0666)
 			if err == nil {
 				file.Close()
@@ -1,13 +1,16 @@
 package main
 
 import (
+	"archive/tar"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
 
 	"github.com/lxc/lxd/client"
 	"github.com/lxc/lxd/lxc/utils"
@@ -23,6 +26,7 @@ type cmdExport struct {
 	flagInstanceOnly         bool
 	flagOptimizedStorage     bool
 	flagCompressionAlgorithm string
+	flagIncrementalFrom      string
 }
 
 func (c *cmdExport) Command() *cobra.Command {
@@ -33,7 +37,10 @@ func (c *cmdExport) Command() *cobra.Command {
 		`Export instances as backup tarballs.`))
 	cmd.Example = cli.FormatSection("", i18n.G(
 		`lxc export u1 backup0.tar.gz
-    Download a backup tarball of the u1 instance.`))
+    Download a backup tarball of the u1 instance.
+
+lxc export v1 backup1.tar.gz --incremental-from backup0.tar.gz
+    Download a backup tarball of the root disk blocks of the running v1 virtual machine changed since backup0.tar.gz.`))
 
 	cmd.RunE = c.Run
 	cmd.Flags().BoolVar(&c.flagInstanceOnly, "instance-only", false,
@@ -41,6 +48,7 @@ func (c *cmdExport) Command() *cobra.Command {
 	cmd.Flags().BoolVar(&c.flagOptimizedStorage, "optimized-storage", false,
 		i18n.G("Use storage driver optimized format (can only be restored on a similar pool)"))
 	cmd.Flags().StringVar(&c.flagCompressionAlgorithm, "compression", "", i18n.G("Compression algorithm to use (none for uncompressed)")+"``")
+	cmd.Flags().StringVar(&c.flagIncrementalFrom, "incremental-from", "", i18n.G("Previous backup tarball to only include the root disk changes since (virtual machines only)")+"``")
 
 	return cmd
 }
@@ -67,6 +75,21 @@ func (c *cmdExport) Run(cmd *cobra.Command, args []string) error {
 
 	instanceOnly := c.flagInstanceOnly
 
+	// Incremental backups only include the root disk changes since the checkpoint of the previous backup.
+	var incrementalFrom string
+	if c.flagIncrementalFrom != "" {
+		if c.flagOptimizedStorage {
+			return fmt.Errorf(i18n.G("Incremental backups can't use the storage driver optimized format"))
+		}
+
+		incrementalFrom, err = c.backupCheckpoint(c.flagIncrementalFrom)
+		if err != nil {
+			return err
+		}
+
+		instanceOnly = true
+	}
+
 	req := api.InstanceBackupsPost{
 		Name:                 "",
 		ExpiresAt:            time.Now().Add(24 * time.Hour),
@@ -74,6 +97,7 @@ func (c *cmdExport) Run(cmd *cobra.Command, args []string) error {
 		InstanceOnly:         instanceOnly,
 		OptimizedStorage:     c.flagOptimizedStorage,
 		CompressionAlgorithm: c.flagCompressionAlgorithm,
+		IncrementalFrom:      incrementalFrom,
 	}
 
 	op, err := d.CreateInstanceBackup(name, req)
@@ -158,3 +182,88 @@ func (c *cmdExport) Run(cmd *cobra.Command, args []string) error {
 	progress.Done(i18n.G("Backup exported successfully!"))
 	return nil
 }
+
+// backupCheckpoint returns the checkpoint recorded in the index of the virtual machine backup tarball at path.
+func (c *cmdExport) backupCheckpoint(path string) (string, error) {
+	f, err := os.Open(shared.HostPathFollow(path))
+	if err != nil {
+		return "", err
+	}
+
+	defer f.Close()
+
+	_, ext, unpacker, err := shared.DetectCompressionFile(f)
+	if err != nil {
+		return "", fmt.Errorf(i18n.G("Failed detecting compression of backup %q: %w"), path, err)
+	}
+
+	if ext == ".squashfs" || ext == ".qcow2" {
+		return "", fmt.Errorf(i18n.G("Backup %q isn't a tarball"), path)
+	}
+
+	_, err = f.Seek(0, 0)
+	if err != nil {
+		return "", err
+	}
+
+	var r io.Reader = f
+	if len(unpacker) > 0 {
+		cmd := exec.Command(unpacker[0], unpacker[1:]...)
+		cmd.Stdin = f
+
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return "", err
+		}
+
+		err = cmd.Start()
+		if err != nil {
+			return "", fmt.Errorf(i18n.G("Failed decompressing backup %q: %w"), path, err)
+		}
+
+		defer func() {
+			cmd.Process.Kill()
+			cmd.Wait()
+		}()
+
+		r = stdout
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return "", fmt.Errorf(i18n.G("Failed reading backup %q: %w"), path, err)
+		}
+
+		if hdr.Name != "backup/index.yaml" {
+			continue
+		}
+
+		index := struct {
+			Type       string `yaml:"type"`
+			Checkpoint string `yaml:"checkpoint"`
+		}{}
+
+		err = yaml.NewDecoder(tr).Decode(&index)
+		if err != nil {
+			return "", fmt.Errorf(i18n.G("Failed parsing index of backup %q: %w"), path, err)
+		}
+
+		if index.Type != string(api.InstanceTypeVM) {
+			return "", fmt.Errorf(i18n.G("Incremental backups are only supported for virtual machines"))
+		}
+
+		if index.Checkpoint == "" {
+			return "", fmt.Errorf(i18n.G("Backup %q has no checkpoint to base an incremental backup on"), path)
+		}
+
+		return index.Checkpoint, nil
+	}
+
+	return "", fmt.Errorf(i18n.G("Backup %q is missing its index"), path)
+}
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
@@ -25,6 +28,7 @@ type cmdInfo struct {
 
 	flagShowLog   bool
 	flagResources bool
+	flagProcesses bool
 	flagTarget    string
 }
 
@@ -35,7 +39,7 @@ func (c *cmdInfo) Command() *cobra.Command {
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
 		`Show instance or server information`))
 	cmd.Example = cli.FormatSection("", i18n.G(
-		`lxc info [<remote>:]<instance> [--show-log]
+		`lxc info [<remote>:]<instance> [--show-log] [--processes]
     For instance information.
 
 lxc info [<remote>:] [--resources]
@@ -44,6 +48,7 @@ lxc info [<remote>:] [--resources]
 	cmd.RunE = c.Run
 	cmd.Flags().BoolVar(&c.flagShowLog, "show-log", false, i18n.G("Show the instance's last 100 log lines?"))
 	cmd.Flags().BoolVar(&c.flagResources, "resources", false, i18n.G("Show the resources available to the server"))
+	cmd.Flags().BoolVar(&c.flagProcesses, "processes", false, i18n.G("Show the processes running in the virtual machine"))
 	cmd.Flags().StringVar(&c.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
 
 	return cmd
@@ -325,6 +330,93 @@ func (c *cmdInfo) renderCPU(cpu api.ResourcesCPUSocket, prefix string) {
 	}
 }
 
+// formatIDs returns the comma separated list of IDs.
+func (c *cmdInfo) formatIDs(ids []uint64) string {
+	values := make([]string, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, strconv.FormatUint(id, 10))
+	}
+
+	return strings.Join(values, ",")
+}
+
+func (c *cmdInfo) renderGuest(guest *api.InstanceGuest, layout string) {
+	fmt.Println("\n" + i18n.G("Guest:"))
+
+	if guest.OS.Name != "" {
+		fmt.Printf("  "+i18n.G("OS: %s")+"\n", strings.TrimSpace(fmt.Sprintf("%s %s", guest.OS.Name, guest.OS.Version)))
+	}
+
+	if guest.OS.Kernel != "" {
+		fmt.Printf("  "+i18n.G("Kernel: %s %s (%s)")+"\n", guest.OS.Kernel, guest.OS.KernelVersion, guest.OS.KernelArchitecture)
+	}
+
+	if guest.OS.Hostname != "" {
+		fmt.Printf("  "+i18n.G("Hostname: %s")+"\n", guest.OS.Hostname)
+	}
+
+	if len(guest.Users) > 0 {
+		fmt.Printf("  %s\n", i18n.G("Logged-in users:"))
+		for _, user := range guest.Users {
+			login := fmt.Sprintf("%s (%s)", user.Name, user.Terminal)
+			if user.Host != "" {
+				login += " " + fmt.Sprintf(i18n.G("from %s"), user.Host)
+			}
+
+			if shared.TimeIsSet(user.LoginAt) {
+				login += " " + fmt.Sprintf(i18n.G("since %s"), user.LoginAt.Local().Format(layout))
+			}
+
+			fmt.Printf("    %s\n", login)
+		}
+	}
+
+	if len(guest.Filesystems) > 0 {
+		fmt.Printf("  %s\n", i18n.G("Filesystems:"))
+		for _, fs := range guest.Filesystems {
+			fmt.Printf("    %s:\n", fs.Mountpoint)
+			fmt.Printf("      %s: %s (%s)\n", i18n.G("Device"), fs.Device, fs.Type)
+			fmt.Printf("      %s: %s / %s\n", i18n.G("Usage"), units.GetByteSizeStringIEC(fs.Used, 2), units.GetByteSizeStringIEC(fs.Total, 2))
+		}
+	}
+
+	if len(guest.Packages) > 0 {
+		fmt.Printf("  %s\n", i18n.G("Installed packages:"))
+		for _, pkgs := range guest.Packages {
+			fmt.Printf("    %s: %d\n", pkgs.Manager, pkgs.Count)
+		}
+	}
+
+	if c.flagProcesses && len(guest.Processes) > 0 {
+		fmt.Println("\n" + i18n.G("Processes:"))
+
+		processData := [][]string{}
+		for _, process := range guest.Processes {
+			processData = append(processData, []string{
+				strconv.FormatInt(process.PID, 10),
+				strconv.FormatInt(process.PPID, 10),
+				process.User,
+				process.State,
+				units.GetByteSizeStringIEC(process.Memory, 2),
+				(time.Duration(process.CPUTime) / time.Second * time.Second).String(),
+				process.Command,
+			})
+		}
+
+		processHeader := []string{
+			i18n.G("PID"),
+			i18n.G("PPID"),
+			i18n.G("User"),
+			i18n.G("State"),
+			i18n.G("Memory"),
+			i18n.G("CPU time"),
+			i18n.G("Command"),
+		}
+
+		_ = utils.RenderTable(utils.TableFormatTable, processHeader, processData, guest.Processes)
+	}
+}
+
 func (c *cmdInfo) remoteInfo(d lxd.InstanceServer) error {
 	// Targeting
 	if c.flagTarget != "" {
@@ -357,6 +449,10 @@ func (c *cmdInfo) remoteInfo(d lxd.InstanceServer) error {
 			}
 		}
 
+		if len(resources.CPU.MemoryEncryption) > 0 {
+			fmt.Printf("  "+i18n.G("Memory encryption: %s")+"\n", strings.Join(resources.CPU.MemoryEncryption, ", "))
+		}
+
 		// Memory
 		fmt.Printf("\n" + i18n.G("Memory:") + "\n")
 		if resources.Memory.HugepagesTotal > 0 {
@@ -370,6 +466,22 @@ func (c *cmdInfo) remoteInfo(d lxd.InstanceServer) error {
 			fmt.Printf("  " + i18n.G("NUMA nodes:"+"\n"))
 			for _, node := range resources.Memory.Nodes {
 				fmt.Printf("    "+i18n.G("Node %d:"+"\n"), node.NUMANode)
+
+				cpus := []uint64{}
+				for _, socket := range resources.CPU.Sockets {
+					for _, core := range socket.Cores {
+						for _, thread := range core.Threads {
+							if thread.NUMANode == node.NUMANode {
+								cpus = append(cpus, uint64(thread.ID))
+							}
+						}
+					}
+				}
+
+				if len(cpus) > 0 {
+					fmt.Printf("      "+i18n.G("CPUs: %s")+"\n", c.formatIDs(cpus))
+				}
+
 				if node.HugepagesTotal > 0 {
 					fmt.Printf("      " + i18n.G("Hugepages:"+"\n"))
 					fmt.Printf("        "+i18n.G("Free: %v")+"\n", units.GetByteSizeStringIEC(int64(node.HugepagesTotal-node.HugepagesUsed), 2))
@@ -486,6 +598,14 @@ func (c *cmdInfo) instanceInfo(d lxd.InstanceServer, remote config.Remote, name
 		fmt.Printf(i18n.G("Last Used: %s")+"\n", inst.LastUsedAt.Local().Format(layout))
 	}
 
+	if inst.State.MemoryEncryption != nil {
+		fmt.Printf(i18n.G("Memory encryption: %s")+"\n", inst.State.MemoryEncryption.Type)
+
+		if inst.State.MemoryEncryption.Measurement != "" {
+			fmt.Printf(i18n.G("Launch measurement: %s")+"\n", inst.State.MemoryEncryption.Measurement)
+		}
+	}
+
 	if inst.State.Pid != 0 {
 		fmt.Println("\n" + i18n.G("Resources:"))
 		// Processes
@@ -540,6 +660,22 @@ func (c *cmdInfo) instanceInfo(d lxd.InstanceServer, remote config.Remote, name
 			fmt.Print(memoryInfo)
 		}
 
+		// NUMA placement
+		if len(inst.State.NUMANodes) > 0 {
+			fmt.Printf("  %s\n", i18n.G("NUMA nodes:"))
+			for _, node := range inst.State.NUMANodes {
+				fmt.Printf("    "+i18n.G("Node %d:")+"\n", node.ID)
+				fmt.Printf("      "+i18n.G("CPUs: %s")+"\n", c.formatIDs(node.CPUs))
+				fmt.Printf("      "+i18n.G("Memory: %s")+"\n", units.GetByteSizeStringIEC(node.Memory, 2))
+
+				if len(node.HostCPUNodes) > 0 {
+					fmt.Printf("      "+i18n.G("Host CPU nodes: %s")+"\n", c.formatIDs(node.HostCPUNodes))
+				}
+
+				fmt.Printf("      "+i18n.G("Host memory nodes: %s")+"\n", c.formatIDs(node.HostMemoryNodes))
+			}
+		}
+
 		// Network usage and IP info
 		networkInfo := ""
 		if inst.State.Network != nil {
@@ -579,6 +715,57 @@ func (c *cmdInfo) instanceInfo(d lxd.InstanceServer, remote config.Remote, name
 		}
 	}
 
+	// Guest information
+	if inst.Type == "virtual-machine" && inst.StatusCode == api.Running && d.HasExtension("instances_vm_guest_info") {
+		guest, err := d.GetInstanceGuest(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Failed getting guest information: %v")+"\n", err)
+		} else {
+			c.renderGuest(guest, layout)
+		}
+	}
+
+	// List exec sessions
+	if inst.StatusCode == api.Running && d.HasExtension("instance_exec_sessions") {
+		sessions, err := d.GetInstanceExecSessions(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Failed getting exec sessions: %v")+"\n", err)
+		} else if len(sessions) > 0 {
+			fmt.Println("\n" + i18n.G("Exec sessions:"))
+
+			sessionData := [][]string{}
+			for _, session := range sessions {
+				status := session.Status
+				if session.Status == "Exited" {
+					status = fmt.Sprintf("%s (%d)", session.Status, session.Return)
+				}
+
+				attached := "NO"
+				if session.Attached {
+					attached = "YES"
+				}
+
+				sessionData = append(sessionData, []string{
+					session.ID,
+					strings.Join(session.Command, " "),
+					status,
+					attached,
+					session.CreatedAt.Local().Format(layout),
+				})
+			}
+
+			sessionHeader := []string{
+				i18n.G("ID"),
+				i18n.G("Command"),
+				i18n.G("Status"),
+				i18n.G("Attached"),
+				i18n.G("Created at"),
+			}
+
+			_ = utils.RenderTable(utils.TableFormatTable, sessionHeader, sessionData, sessions)
+		}
+	}
+
 	// List snapshots
 	firstSnapshot := true
 	if len(inst.Snapshots) > 0 {
@@ -679,6 +866,19 @@ func (c *cmdInfo) instanceInfo(d lxd.InstanceServer, remote config.Remote, name
 		_ = utils.RenderTable(utils.TableFormatTable, backupHeader, backupData, inst.Backups)
 	}
 
+	// Replication status, comparing against the local snapshots in creation order.
+	replicationSnapshots := make([]replicationSnapshot, 0, len(inst.Snapshots))
+	for _, snap := range inst.Snapshots {
+		fields := strings.Split(snap.Name, shared.SnapshotDelimiter)
+		replicationSnapshots = append(replicationSnapshots, replicationSnapshot{name: fields[len(fields)-1], createdAt: snap.CreatedAt})
+	}
+
+	sort.SliceStable(replicationSnapshots, func(i, j int) bool {
+		return replicationSnapshots[i].createdAt.Before(replicationSnapshots[j].createdAt)
+	})
+
+	renderReplicationInfo(inst.ExpandedConfig, replicationSnapshots, layout)
+
 	if showLog {
 		var log io.Reader
 		if inst.Type == "container" {
@@ -705,3 +905,70 @@ func (c *cmdInfo) instanceInfo(d lxd.InstanceServer, remote config.Remote, name
 
 	return nil
 }
+
+// replicationSnapshot is a local snapshot of an instance or custom volume.
+type replicationSnapshot struct {
+	name      string
+	createdAt time.Time
+}
+
+// replicationLag returns the number of local snapshots taken after the last replicated one, along with how long
+// before the newest local snapshot the last replicated one was taken. The snapshots are in creation order and the
+// lag is only known when the creation times of both snapshots are.
+func replicationLag(snapshots []replicationSnapshot, lastSnapshot string) (int, time.Duration, bool) {
+	pending := len(snapshots)
+	var replicatedAt time.Time
+
+	for i, snap := range snapshots {
+		if snap.name == lastSnapshot {
+			pending = len(snapshots) - i - 1
+			replicatedAt = snap.createdAt
+		}
+	}
+
+	if pending == 0 {
+		return 0, 0, true
+	}
+
+	newestAt := snapshots[len(snapshots)-1].createdAt
+	if !shared.TimeIsSet(newestAt) || !shared.TimeIsSet(replicatedAt) {
+		return pending, 0, false
+	}
+
+	return pending, newestAt.Sub(replicatedAt), true
+}
+
+// renderReplicationInfo prints the snapshot replication status of an instance or custom volume.
+func renderReplicationInfo(config map[string]string, snapshots []replicationSnapshot, layout string) {
+	target := config["snapshots.replicate.target"]
+	if target == "" {
+		return
+	}
+
+	if config["snapshots.replicate.target.project"] != "" {
+		target = fmt.Sprintf("%s (%s)", target, config["snapshots.replicate.target.project"])
+	}
+
+	fmt.Println("\n" + i18n.G("Replication:"))
+	fmt.Printf("  "+i18n.G("Target: %s")+"\n", target)
+
+	lastSnapshot := config["volatile.replicate.last_snapshot"]
+	if lastSnapshot == "" {
+		fmt.Printf("  "+i18n.G("Last replicated snapshot: %s")+"\n", i18n.G("none"))
+		return
+	}
+
+	fmt.Printf("  "+i18n.G("Last replicated snapshot: %s")+"\n", lastSnapshot)
+
+	lastSuccess, err := time.Parse(time.RFC3339, config["volatile.replicate.last_success"])
+	if err == nil {
+		fmt.Printf("  "+i18n.G("Last replicated at: %s")+"\n", lastSuccess.Local().Format(layout))
+	}
+
+	pending, lag, ok := replicationLag(snapshots, lastSnapshot)
+	fmt.Printf("  "+i18n.G("Pending snapshots: %d")+"\n", pending)
+
+	if ok {
+		fmt.Printf("  "+i18n.G("Lag: %s")+"\n", lag.Round(time.Second))
+	}
+}
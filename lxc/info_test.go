@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReplicationLag(t *testing.T) {
+	base := time.Date(2023, 2, 10, 12, 0, 0, 0, time.UTC)
+
+	snapshots := []replicationSnapshot{
+		{name: "snap0", createdAt: base},
+		{name: "snap1", createdAt: base.Add(time.Hour)},
+		{name: "snap2", createdAt: base.Add(3 * time.Hour)},
+	}
+
+	volSnapshots := []replicationSnapshot{{name: "snap0"}, {name: "snap1"}, {name: "snap2"}}
+
+	tests := []struct {
+		name         string
+		snapshots    []replicationSnapshot
+		lastSnapshot string
+		pending      int
+		lag          time.Duration
+		known        bool
+	}{
+		{"up to date", snapshots, "snap2", 0, 0, true},
+		{"behind", snapshots, "snap1", 1, 2 * time.Hour, true},
+		{"far behind", snapshots, "snap0", 2, 3 * time.Hour, true},
+		{"replicated snapshot deleted", snapshots, "snap-old", 3, 0, false},
+		{"no snapshots", nil, "snap0", 0, 0, true},
+		{"volume up to date", volSnapshots, "snap2", 0, 0, true},
+		{"volume behind", volSnapshots, "snap0", 2, 0, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pending, lag, known := replicationLag(test.snapshots, test.lastSnapshot)
+			if pending != test.pending || lag != test.lag || known != test.known {
+				t.Errorf("Got (%d, %s, %v), expected (%d, %s, %v)", pending, lag, known, test.pending, test.lag, test.known)
+			}
+		})
+	}
+}
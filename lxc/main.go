@@ -108,6 +108,10 @@ For help with any of those, simply call them with --help.`))
 	aliasCmd := cmdAlias{global: &globalCmd}
 	app.AddCommand(aliasCmd.Command())
 
+	// auth sub-command
+	authCmd := cmdAuth{global: &globalCmd}
+	app.AddCommand(authCmd.Command())
+
 	// cluster sub-command
 	clusterCmd := cmdCluster{global: &globalCmd}
 	app.AddCommand(clusterCmd.Command())
@@ -410,10 +414,13 @@ Or for a virtual machine: lxc launch ubuntu:20.04 --vm`)+"\n")
 }
 
 func (c *cmdGlobal) PostRun(cmd *cobra.Command, args []string) error {
-	// Macaroon teardown
+	// Macaroon and OpenID Connect teardown
 	if c.conf != nil && shared.PathExists(c.confPath) {
 		// Save cookies on exit
 		c.conf.SaveCookies()
+
+		// Save OpenID Connect tokens on exit
+		c.conf.SaveOIDCTokens()
 	}
 
 	return nil
@@ -73,14 +73,26 @@ func (c *cmdNetwork) Command() *cobra.Command {
 	networkListCmd := cmdNetworkList{global: c.global, network: c}
 	cmd.AddCommand(networkListCmd.Command())
 
+	// List allocations
+	networkListAllocationsCmd := cmdNetworkListAllocations{global: c.global, network: c}
+	cmd.AddCommand(networkListAllocationsCmd.Command())
+
 	// List leases
 	networkListLeasesCmd := cmdNetworkListLeases{global: c.global, network: c}
 	cmd.AddCommand(networkListLeasesCmd.Command())
 
+	// List reservations
+	networkListReservationsCmd := cmdNetworkListReservations{global: c.global, network: c}
+	cmd.AddCommand(networkListReservationsCmd.Command())
+
 	// Rename
 	networkRenameCmd := cmdNetworkRename{global: c.global, network: c}
 	cmd.AddCommand(networkRenameCmd.Command())
 
+	// Reserve
+	networkReserveCmd := cmdNetworkReserve{global: c.global, network: c}
+	cmd.AddCommand(networkReserveCmd.Command())
+
 	// Set
 	networkSetCmd := cmdNetworkSet{global: c.global, network: c}
 	cmd.AddCommand(networkSetCmd.Command())
@@ -89,6 +101,10 @@ func (c *cmdNetwork) Command() *cobra.Command {
 	networkShowCmd := cmdNetworkShow{global: c.global, network: c}
 	cmd.AddCommand(networkShowCmd.Command())
 
+	// Unreserve
+	networkUnreserveCmd := cmdNetworkUnreserve{global: c.global, network: c}
+	cmd.AddCommand(networkUnreserveCmd.Command())
+
 	// Unset
 	networkUnsetCmd := cmdNetworkUnset{global: c.global, network: c, networkSet: &networkSetCmd}
 	cmd.AddCommand(networkUnsetCmd.Command())
@@ -101,10 +117,18 @@ func (c *cmdNetwork) Command() *cobra.Command {
 	networkForwardCmd := cmdNetworkForward{global: c.global}
 	cmd.AddCommand(networkForwardCmd.Command())
 
+	// Load Balancer
+	networkLoadBalancerCmd := cmdNetworkLoadBalancer{global: c.global}
+	cmd.AddCommand(networkLoadBalancerCmd.Command())
+
 	// Peer
 	networkPeerCmd := cmdNetworkPeer{global: c.global}
 	cmd.AddCommand(networkPeerCmd.Command())
 
+	// QoS
+	networkQoSCmd := cmdNetworkQoS{global: c.global}
+	cmd.AddCommand(networkQoSCmd.Command())
+
 	// Zone
 	networkZoneCmd := cmdNetworkZone{global: c.global}
 	cmd.AddCommand(networkZoneCmd.Command())
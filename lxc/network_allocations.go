@@ -0,0 +1,300 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lxc/lxd/lxc/utils"
+	"github.com/lxc/lxd/shared/api"
+	cli "github.com/lxc/lxd/shared/cmd"
+	"github.com/lxc/lxd/shared/i18n"
+)
+
+// List allocations
+type cmdNetworkListAllocations struct {
+	global  *cmdGlobal
+	network *cmdNetwork
+
+	flagFormat      string
+	flagAllProjects bool
+}
+
+func (c *cmdNetworkListAllocations) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list-allocations", i18n.G("[<remote>:]"))
+	cmd.Short = i18n.G("List the addresses in use on managed networks")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`List the addresses in use on managed networks
+
+The addresses of the networks, their uplinks, forwards, load balancers and reservations are listed,
+as well as the addresses of the instance NICs.`))
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+	cmd.Flags().BoolVar(&c.flagAllProjects, "all-projects", false, i18n.G("Display addresses from all projects"))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkListAllocations) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	if c.global.flagProject != "" && c.flagAllProjects {
+		return fmt.Errorf(i18n.G("Can't specify --project with --all-projects"))
+	}
+
+	// Parse remote
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	// List the allocations
+	var allocations []api.NetworkAllocation
+	if c.flagAllProjects {
+		allocations, err = resource.server.GetNetworkAllocationsAllProjects()
+	} else {
+		allocations, err = resource.server.GetNetworkAllocations()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, allocation := range allocations {
+		nat := i18n.G("NO")
+		if allocation.NAT {
+			nat = i18n.G("YES")
+		}
+
+		entry := []string{allocation.UsedBy, allocation.Address, strings.ToUpper(allocation.Type), nat, allocation.Hwaddr, allocation.Network}
+		if c.flagAllProjects {
+			entry = append(entry, allocation.Project)
+		}
+
+		data = append(data, entry)
+	}
+
+	sort.Sort(utils.ByName(data))
+
+	header := []string{
+		i18n.G("USED BY"),
+		i18n.G("ADDRESS"),
+		i18n.G("TYPE"),
+		i18n.G("NAT"),
+		i18n.G("HARDWARE ADDRESS"),
+		i18n.G("NETWORK"),
+	}
+
+	if c.flagAllProjects {
+		header = append(header, i18n.G("PROJECT"))
+	}
+
+	return utils.RenderTable(c.flagFormat, header, data, allocations)
+}
+
+// List reservations
+type cmdNetworkListReservations struct {
+	global  *cmdGlobal
+	network *cmdNetwork
+
+	flagFormat string
+}
+
+func (c *cmdNetworkListReservations) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list-reservations", i18n.G("[<remote>:]<network>"))
+	cmd.Short = i18n.G("List address reservations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`List address reservations`))
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkListReservations) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	// List the reservations
+	reservations, err := resource.server.GetNetworkReservations(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, reservation := range reservations {
+		data = append(data, []string{reservation.Address, reservation.Instance, reservation.Project, reservation.Description})
+	}
+
+	sort.Sort(utils.ByName(data))
+
+	header := []string{
+		i18n.G("ADDRESS"),
+		i18n.G("INSTANCE"),
+		i18n.G("PROJECT"),
+		i18n.G("DESCRIPTION"),
+	}
+
+	return utils.RenderTable(c.flagFormat, header, data, reservations)
+}
+
+// Reserve
+type cmdNetworkReserve struct {
+	global  *cmdGlobal
+	network *cmdNetwork
+
+	flagInstance    string
+	flagDescription string
+}
+
+func (c *cmdNetworkReserve) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("reserve", i18n.G("[<remote>:]<network> <address>"))
+	cmd.Short = i18n.G("Reserve addresses on networks")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Reserve addresses on networks
+
+Reserved addresses aren't allocated dynamically. When an instance is specified, the address is
+assigned to the NICs of the instance that are connected to the network and don't have a static address.
+The instance doesn't need to exist yet.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc network reserve lxdbr0 10.0.0.10 --instance c1
+    Reserve 10.0.0.10 on lxdbr0 for the future instance c1.`))
+	cmd.Flags().StringVar(&c.flagInstance, "instance", "", i18n.G("Instance the address is reserved for")+"``")
+	cmd.Flags().StringVar(&c.flagDescription, "description", "", i18n.G("Reservation description")+"``")
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkReserve) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return fmt.Errorf(i18n.G("Missing address"))
+	}
+
+	// Reserve the address
+	reservation := api.NetworkReservationsPost{
+		Address: args[1],
+		NetworkReservationPut: api.NetworkReservationPut{
+			Description: c.flagDescription,
+			Instance:    c.flagInstance,
+		},
+	}
+
+	err = resource.server.CreateNetworkReservation(resource.name, reservation)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Address %s reserved on network %s")+"\n", args[1], resource.name)
+	}
+
+	return nil
+}
+
+// Unreserve
+type cmdNetworkUnreserve struct {
+	global  *cmdGlobal
+	network *cmdNetwork
+}
+
+func (c *cmdNetworkUnreserve) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("unreserve", i18n.G("[<remote>:]<network> <address>"))
+	cmd.Short = i18n.G("Release reserved addresses on networks")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Release reserved addresses on networks`))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkUnreserve) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return fmt.Errorf(i18n.G("Missing address"))
+	}
+
+	// Release the address
+	err = resource.server.DeleteNetworkReservation(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Address %s released on network %s")+"\n", args[1], resource.name)
+	}
+
+	return nil
+}
@@ -41,6 +41,14 @@ func (c *cmdNetworkPeer) Command() *cobra.Command {
 	networkPeerCreateCmd := cmdNetworkPeerCreate{global: c.global, networkPeer: c}
 	cmd.AddCommand(networkPeerCreateCmd.Command())
 
+	// List invitations.
+	networkPeerListInvitationsCmd := cmdNetworkPeerListInvitations{global: c.global, networkPeer: c}
+	cmd.AddCommand(networkPeerListInvitationsCmd.Command())
+
+	// Accept.
+	networkPeerAcceptCmd := cmdNetworkPeerAccept{global: c.global, networkPeer: c}
+	cmd.AddCommand(networkPeerAcceptCmd.Command())
+
 	// Get,
 	networkPeerGetCmd := cmdNetworkPeerGet{global: c.global, networkPeer: c}
 	cmd.AddCommand(networkPeerGetCmd.Command())
@@ -130,6 +138,7 @@ func (c *cmdNetworkPeerList) Run(cmd *cobra.Command, args []string) error {
 			peer.Description,
 			targetPeer,
 			strings.ToUpper(peer.Status),
+			peer.Type,
 		}
 
 		data = append(data, details)
@@ -141,6 +150,7 @@ func (c *cmdNetworkPeerList) Run(cmd *cobra.Command, args []string) error {
 		i18n.G("DESCRIPTION"),
 		i18n.G("PEER"),
 		i18n.G("STATE"),
+		i18n.G("TYPE"),
 	}
 
 	return utils.RenderTable(c.flagFormat, header, data, peers)
@@ -207,21 +217,37 @@ func (c *cmdNetworkPeerShow) Run(cmd *cobra.Command, args []string) error {
 type cmdNetworkPeerCreate struct {
 	global      *cmdGlobal
 	networkPeer *cmdNetworkPeer
+
+	flagType string
 }
 
 func (c *cmdNetworkPeerCreate) Command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("create", i18n.G("[<remote>:]<network> <peer_name> <[target project/]target_network> [key=value...]"))
+	cmd.Use = usage("create", i18n.G("[<remote>:]<network> <peer_name> [<[target project/]target_network>] [key=value...]"))
 	cmd.Short = i18n.G("Create new network peering")
-	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Create new network peering"))
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Create new network peering
+
+Peerings of the "uplink" type are created with the network's uplink and don't take a target network.`))
+	cmd.Example = cli.FormatSection("", i18n.G(`lxc network peer create ovn1 to-ovn2 project2/ovn2
+    Invite the "ovn2" network of the "project2" project to peer with "ovn1".
+
+lxc network peer create ovn1 routed --type=uplink
+    Peer "ovn1" with the routed subnets of its uplink network.`))
 	cmd.RunE = c.Run
+	cmd.Flags().StringVar(&c.flagType, "type", "", i18n.G("Peer type (network or uplink)")+"``")
 
 	return cmd
 }
 
 func (c *cmdNetworkPeerCreate) Run(cmd *cobra.Command, args []string) error {
+	// Uplink peers don't have a target network argument.
+	configArgsStart := 3
+	if c.flagType == "uplink" {
+		configArgsStart = 2
+	}
+
 	// Quick checks.
-	exit, err := c.global.CheckArgs(cmd, args, 3, -1)
+	exit, err := c.global.CheckArgs(cmd, args, configArgsStart, -1)
 	if exit {
 		return err
 	}
@@ -242,18 +268,19 @@ func (c *cmdNetworkPeerCreate) Run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf(i18n.G("Missing peer name"))
 	}
 
-	if args[2] == "" {
-		return fmt.Errorf(i18n.G("Missing target network"))
-	}
-
-	targetParts := strings.SplitN(args[2], "/", 2)
-
 	var targetProject, targetNetwork string
-	if len(targetParts) == 2 {
-		targetProject = targetParts[0]
-		targetNetwork = targetParts[1]
-	} else {
-		targetNetwork = targetParts[0]
+	if configArgsStart > 2 {
+		if args[2] == "" {
+			return fmt.Errorf(i18n.G("Missing target network"))
+		}
+
+		targetParts := strings.SplitN(args[2], "/", 2)
+		if len(targetParts) == 2 {
+			targetProject = targetParts[0]
+			targetNetwork = targetParts[1]
+		} else {
+			targetNetwork = targetParts[0]
+		}
 	}
 
 	// If stdin isn't a terminal, read yaml from it.
@@ -275,7 +302,7 @@ func (c *cmdNetworkPeerCreate) Run(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get config filters from arguments.
-	for i := 3; i < len(args); i++ {
+	for i := configArgsStart; i < len(args); i++ {
 		entry := strings.SplitN(args[i], "=", 2)
 		if len(entry) < 2 {
 			return fmt.Errorf(i18n.G("Bad key/value pair: %s"), args[i])
@@ -289,6 +316,7 @@ func (c *cmdNetworkPeerCreate) Run(cmd *cobra.Command, args []string) error {
 		Name:           args[1],
 		TargetProject:  targetProject,
 		TargetNetwork:  targetNetwork,
+		Type:           c.flagType,
 		NetworkPeerPut: peerPut,
 	}
 
@@ -317,6 +345,170 @@ func (c *cmdNetworkPeerCreate) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// List invitations.
+type cmdNetworkPeerListInvitations struct {
+	global      *cmdGlobal
+	networkPeer *cmdNetworkPeer
+
+	flagFormat string
+}
+
+func (c *cmdNetworkPeerListInvitations) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list-invitations", i18n.G("[<remote>:]<network>"))
+	cmd.Short = i18n.G("List pending peering invitations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`List pending peering invitations
+
+Invitations are peerings initiated by networks in other projects towards this network.`))
+
+	cmd.RunE = c.Run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	return cmd
+}
+
+func (c *cmdNetworkPeerListInvitations) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	invitations, err := resource.server.GetNetworkPeerInvitations(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data := make([][]string, 0, len(invitations))
+	for _, invitation := range invitations {
+		details := []string{
+			invitation.Name,
+			invitation.Description,
+			fmt.Sprintf("%s/%s", invitation.SourceProject, invitation.SourceNetwork),
+		}
+
+		data = append(data, details)
+	}
+	sort.Sort(utils.ByName(data))
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("DESCRIPTION"),
+		i18n.G("FROM"),
+	}
+
+	return utils.RenderTable(c.flagFormat, header, data, invitations)
+}
+
+// Accept.
+type cmdNetworkPeerAccept struct {
+	global      *cmdGlobal
+	networkPeer *cmdNetworkPeer
+}
+
+func (c *cmdNetworkPeerAccept) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("accept", i18n.G("[<remote>:]<network> <peer_name> <source project>/<source network> [key=value...]"))
+	cmd.Short = i18n.G("Accept a peering invitation")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Accept a peering invitation
+
+This creates the mutual peer of a pending peering initiated by a network in another project.`))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkPeerAccept) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return fmt.Errorf(i18n.G("Missing peer name"))
+	}
+
+	sourceParts := strings.SplitN(args[2], "/", 2)
+	if len(sourceParts) != 2 || sourceParts[0] == "" || sourceParts[1] == "" {
+		return fmt.Errorf(i18n.G("Invalid source network %q, must be <project>/<network>"), args[2])
+	}
+
+	client := resource.server
+
+	// Check that there is an invitation from the source network.
+	invitations, err := client.GetNetworkPeerInvitations(resource.name)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, invitation := range invitations {
+		if invitation.SourceProject == sourceParts[0] && invitation.SourceNetwork == sourceParts[1] {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf(i18n.G("No pending peering invitation from %q"), args[2])
+	}
+
+	peer := api.NetworkPeersPost{
+		Name:          args[1],
+		TargetProject: sourceParts[0],
+		TargetNetwork: sourceParts[1],
+		NetworkPeerPut: api.NetworkPeerPut{
+			Config: map[string]string{},
+		},
+	}
+
+	// Get config filters from arguments.
+	for i := 3; i < len(args); i++ {
+		entry := strings.SplitN(args[i], "=", 2)
+		if len(entry) < 2 {
+			return fmt.Errorf(i18n.G("Bad key/value pair: %s"), args[i])
+		}
+
+		peer.Config[entry[0]] = entry[1]
+	}
+
+	err = client.CreateNetworkPeer(resource.name, peer)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network peer %s created")+"\n", peer.Name)
+	}
+
+	return nil
+}
+
 // Get
 type cmdNetworkPeerGet struct {
 	global      *cmdGlobal
@@ -0,0 +1,635 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/lxc/lxd/lxc/utils"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/termios"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/lxc/lxd/shared/api"
+	cli "github.com/lxc/lxd/shared/cmd"
+	"github.com/lxc/lxd/shared/i18n"
+)
+
+type cmdNetworkQoS struct {
+	global *cmdGlobal
+}
+
+func (c *cmdNetworkQoS) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("qos")
+	cmd.Short = i18n.G("Manage network QoS policies")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Manage network QoS policies"))
+
+	// List.
+	networkQoSListCmd := cmdNetworkQoSList{global: c.global, networkQoS: c}
+	cmd.AddCommand(networkQoSListCmd.Command())
+
+	// Show.
+	networkQoSShowCmd := cmdNetworkQoSShow{global: c.global, networkQoS: c}
+	cmd.AddCommand(networkQoSShowCmd.Command())
+
+	// Get.
+	networkQoSGetCmd := cmdNetworkQoSGet{global: c.global, networkQoS: c}
+	cmd.AddCommand(networkQoSGetCmd.Command())
+
+	// Create.
+	networkQoSCreateCmd := cmdNetworkQoSCreate{global: c.global, networkQoS: c}
+	cmd.AddCommand(networkQoSCreateCmd.Command())
+
+	// Set.
+	networkQoSSetCmd := cmdNetworkQoSSet{global: c.global, networkQoS: c}
+	cmd.AddCommand(networkQoSSetCmd.Command())
+
+	// Unset.
+	networkQoSUnsetCmd := cmdNetworkQoSUnset{global: c.global, networkQoS: c, networkQoSSet: &networkQoSSetCmd}
+	cmd.AddCommand(networkQoSUnsetCmd.Command())
+
+	// Edit.
+	networkQoSEditCmd := cmdNetworkQoSEdit{global: c.global, networkQoS: c}
+	cmd.AddCommand(networkQoSEditCmd.Command())
+
+	// Rename.
+	networkQoSRenameCmd := cmdNetworkQoSRename{global: c.global, networkQoS: c}
+	cmd.AddCommand(networkQoSRenameCmd.Command())
+
+	// Delete.
+	networkQoSDeleteCmd := cmdNetworkQoSDelete{global: c.global, networkQoS: c}
+	cmd.AddCommand(networkQoSDeleteCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { cmd.Usage() }
+	return cmd
+}
+
+// List.
+type cmdNetworkQoSList struct {
+	global     *cmdGlobal
+	networkQoS *cmdNetworkQoS
+
+	flagFormat string
+}
+
+func (c *cmdNetworkQoSList) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List available network QoS policies")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("List available network QoS policies"))
+
+	cmd.RunE = c.Run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	return cmd
+}
+
+func (c *cmdNetworkQoSList) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	// List the networks.
+	if resource.name != "" {
+		return fmt.Errorf(i18n.G("Filtering isn't supported yet"))
+	}
+
+	policies, err := resource.server.GetNetworkQoSPolicies()
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, policy := range policies {
+		strUsedBy := fmt.Sprintf("%d", len(policy.UsedBy))
+		details := []string{
+			policy.Name,
+			policy.Description,
+			strUsedBy,
+		}
+
+		data = append(data, details)
+	}
+	sort.Sort(utils.ByName(data))
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("DESCRIPTION"),
+		i18n.G("USED BY"),
+	}
+
+	return utils.RenderTable(c.flagFormat, header, data, policies)
+}
+
+// Show.
+type cmdNetworkQoSShow struct {
+	global     *cmdGlobal
+	networkQoS *cmdNetworkQoS
+}
+
+func (c *cmdNetworkQoSShow) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<policy>"))
+	cmd.Short = i18n.G("Show network QoS policy configurations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Show network QoS policy configurations"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkQoSShow) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network QoS policy name"))
+	}
+
+	// Show the network QoS policy config.
+	policy, _, err := resource.server.GetNetworkQoSPolicy(resource.name)
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(policy.UsedBy)
+
+	data, err := yaml.Marshal(&policy)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Get.
+type cmdNetworkQoSGet struct {
+	global     *cmdGlobal
+	networkQoS *cmdNetworkQoS
+}
+
+func (c *cmdNetworkQoSGet) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("get", i18n.G("[<remote>:]<policy> <key>"))
+	cmd.Short = i18n.G("Get values for network QoS policy configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Get values for network QoS policy configuration keys"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkQoSGet) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network QoS policy name"))
+	}
+
+	resp, _, err := resource.server.GetNetworkQoSPolicy(resource.name)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range resp.Config {
+		if k == args[1] {
+			fmt.Printf("%s\n", v)
+		}
+	}
+
+	return nil
+}
+
+// Create.
+type cmdNetworkQoSCreate struct {
+	global     *cmdGlobal
+	networkQoS *cmdNetworkQoS
+}
+
+func (c *cmdNetworkQoSCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<policy> [key=value...]"))
+	cmd.Short = i18n.G("Create new network QoS policies")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Create new network QoS policies"))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkQoSCreate) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network QoS policy name"))
+	}
+
+	// If stdin isn't a terminal, read yaml from it.
+	var policyPut api.NetworkQoSPolicyPut
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		err = yaml.UnmarshalStrict(contents, &policyPut)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Create the network QoS policy.
+	policy := api.NetworkQoSPoliciesPost{
+		NetworkQoSPolicyPost: api.NetworkQoSPolicyPost{
+			Name: resource.name,
+		},
+		NetworkQoSPolicyPut: policyPut,
+	}
+
+	if policy.Config == nil {
+		policy.Config = map[string]string{}
+	}
+
+	for i := 1; i < len(args); i++ {
+		entry := strings.SplitN(args[i], "=", 2)
+		if len(entry) < 2 {
+			return fmt.Errorf(i18n.G("Bad key/value pair: %s"), args[i])
+		}
+
+		policy.Config[entry[0]] = entry[1]
+	}
+
+	err = resource.server.CreateNetworkQoSPolicy(policy)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network QoS policy %s created")+"\n", resource.name)
+	}
+
+	return nil
+}
+
+// Set.
+type cmdNetworkQoSSet struct {
+	global     *cmdGlobal
+	networkQoS *cmdNetworkQoS
+}
+
+func (c *cmdNetworkQoSSet) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("set", i18n.G("[<remote>:]<policy> <key>=<value>..."))
+	cmd.Short = i18n.G("Set network QoS policy configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Set network QoS policy configuration keys
+
+For backward compatibility, a single configuration key may still be set with:
+    lxc network qos set [<remote>:]<policy> <key> <value>`))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkQoSSet) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network QoS policy name"))
+	}
+
+	// Get the network QoS policy.
+	policy, etag, err := resource.server.GetNetworkQoSPolicy(resource.name)
+	if err != nil {
+		return err
+	}
+
+	// Set the keys.
+	keys, err := getConfig(args[1:]...)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range keys {
+		policy.Config[k] = v
+	}
+
+	return resource.server.UpdateNetworkQoSPolicy(resource.name, policy.Writable(), etag)
+}
+
+// Unset.
+type cmdNetworkQoSUnset struct {
+	global        *cmdGlobal
+	networkQoS    *cmdNetworkQoS
+	networkQoSSet *cmdNetworkQoSSet
+}
+
+func (c *cmdNetworkQoSUnset) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("unset", i18n.G("[<remote>:]<policy> <key>"))
+	cmd.Short = i18n.G("Unset network QoS policy configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Unset network QoS policy configuration keys"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkQoSUnset) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	args = append(args, "")
+	return c.networkQoSSet.Run(cmd, args)
+}
+
+// Edit.
+type cmdNetworkQoSEdit struct {
+	global     *cmdGlobal
+	networkQoS *cmdNetworkQoS
+}
+
+func (c *cmdNetworkQoSEdit) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<policy>"))
+	cmd.Short = i18n.G("Edit network QoS policy configurations as YAML")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Edit network QoS policy configurations as YAML"))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkQoSEdit) helpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the network QoS policy.
+### Any line starting with a '# will be ignored.
+###
+### A network QoS policy consists of a set of configuration items.
+###
+### An example would look like:
+### name: bulk
+### description: Bulk transfers
+### config:
+###   limits.ingress: 100Mbit
+###   limits.egress: 50Mbit
+###   priority: low
+###
+### Note that only the description and configuration keys can be changed.`)
+}
+
+func (c *cmdNetworkQoSEdit) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network QoS policy name"))
+	}
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		// Allow output of `lxc network qos show` command to passed in here, but only take the contents
+		// of the NetworkQoSPolicyPut fields when updating the policy. The other fields are silently discarded.
+		newdata := api.NetworkQoSPolicy{}
+		err = yaml.UnmarshalStrict(contents, &newdata)
+		if err != nil {
+			return err
+		}
+
+		return resource.server.UpdateNetworkQoSPolicy(resource.name, newdata.NetworkQoSPolicyPut, "")
+	}
+
+	// Get the current config.
+	policy, etag, err := resource.server.GetNetworkQoSPolicy(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&policy)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor.
+	content, err := shared.TextEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor.
+		newdata := api.NetworkQoSPolicy{} // We show the full policy info, but only send the writable fields.
+		err = yaml.UnmarshalStrict(content, &newdata)
+		if err == nil {
+			err = resource.server.UpdateNetworkQoSPolicy(resource.name, newdata.Writable(), etag)
+		}
+
+		// Respawn the editor.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// Rename.
+type cmdNetworkQoSRename struct {
+	global     *cmdGlobal
+	networkQoS *cmdNetworkQoS
+}
+
+func (c *cmdNetworkQoSRename) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("rename", i18n.G("[<remote>:]<policy> <new-name>"))
+	cmd.Aliases = []string{"mv"}
+	cmd.Short = i18n.G("Rename network QoS policies")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Rename network QoS policies"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkQoSRename) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network QoS policy name"))
+	}
+
+	// Rename the network.
+	err = resource.server.RenameNetworkQoSPolicy(resource.name, api.NetworkQoSPolicyPost{Name: args[1]})
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network QoS policy %s renamed to %s")+"\n", resource.name, args[1])
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdNetworkQoSDelete struct {
+	global     *cmdGlobal
+	networkQoS *cmdNetworkQoS
+}
+
+func (c *cmdNetworkQoSDelete) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<policy>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Delete network QoS policies")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Delete network QoS policies"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkQoSDelete) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network QoS policy name"))
+	}
+
+	// Delete the network QoS policy.
+	err = resource.server.DeleteNetworkQoSPolicy(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network QoS policy %s deleted")+"\n", resource.name)
+	}
+
+	return nil
+}
@@ -97,8 +97,8 @@ Basic authentication can be used when combined with the "simplestreams" protocol
 	cmd.RunE = c.Run
 	cmd.Flags().BoolVar(&c.flagAcceptCert, "accept-certificate", false, i18n.G("Accept certificate"))
 	cmd.Flags().StringVar(&c.flagPassword, "password", "", i18n.G("Remote admin password")+"``")
-	cmd.Flags().StringVar(&c.flagProtocol, "protocol", "", i18n.G("Server protocol (lxd or simplestreams)")+"``")
-	cmd.Flags().StringVar(&c.flagAuthType, "auth-type", "", i18n.G("Server authentication type (tls or candid)")+"``")
+	cmd.Flags().StringVar(&c.flagProtocol, "protocol", "", i18n.G("Server protocol (lxd, simplestreams or oci)")+"``")
+	cmd.Flags().StringVar(&c.flagAuthType, "auth-type", "", i18n.G("Server authentication type (tls, candid or oidc)")+"``")
 	cmd.Flags().BoolVar(&c.flagPublic, "public", false, i18n.G("Public image server"))
 	cmd.Flags().StringVar(&c.flagDomain, "domain", "", i18n.G("Candid domain to use")+"``")
 	cmd.Flags().StringVar(&c.flagProject, "project", "", i18n.G("Project to use for the remote")+"``")
@@ -287,6 +287,13 @@ func (c *cmdRemoteAdd) Run(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf(i18n.G("Only https URLs are supported for simplestreams"))
 		}
 
+		conf.Remotes[server] = config.Remote{Addr: addr, Public: true, Protocol: c.flagProtocol}
+		return conf.SaveConfig(c.global.confPath)
+	} else if c.flagProtocol == "oci" {
+		if !shared.StringInSlice(remoteURL.Scheme, []string{"http", "https"}) {
+			return fmt.Errorf(i18n.G("Only http and https URLs are supported for oci"))
+		}
+
 		conf.Remotes[server] = config.Remote{Addr: addr, Public: true, Protocol: c.flagProtocol}
 		return conf.SaveConfig(c.global.confPath)
 	} else if c.flagProtocol != "lxd" {
@@ -450,7 +457,7 @@ func (c *cmdRemoteAdd) Run(cmd *cobra.Command, args []string) error {
 		return conf.SaveConfig(c.global.confPath)
 	}
 
-	if c.flagAuthType == "candid" {
+	if shared.StringInSlice(c.flagAuthType, []string{"candid", "oidc"}) {
 		d.(lxd.InstanceServer).RequireAuthenticated(false)
 	}
 
@@ -651,7 +658,7 @@ func (c *cmdRemoteList) Run(cmd *cobra.Command, args []string) error {
 		if rc.AuthType == "" {
 			if strings.HasPrefix(rc.Addr, "unix:") {
 				rc.AuthType = "file access"
-			} else if rc.Protocol == "simplestreams" {
+			} else if shared.StringInSlice(rc.Protocol, []string{"simplestreams", "oci"}) {
 				rc.AuthType = "none"
 			} else {
 				rc.AuthType = "tls"
@@ -743,6 +750,15 @@ func (c *cmdRemoteRename) Run(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Rename the OpenID Connect tokens
+	oldPath = conf.OIDCTokenPath(args[0])
+	if shared.PathExists(oldPath) {
+		err := os.Rename(oldPath, conf.OIDCTokenPath(args[1]))
+		if err != nil {
+			return err
+		}
+	}
+
 	conf.Remotes[args[1]] = rc
 	delete(conf.Remotes, args[0])
 
@@ -803,6 +819,7 @@ func (c *cmdRemoteRemove) Run(cmd *cobra.Command, args []string) error {
 
 	os.Remove(conf.ServerCertPath(args[0]))
 	os.Remove(conf.CookiesPath(args[0]))
+	os.Remove(conf.OIDCTokenPath(args[0]))
 
 	return conf.SaveConfig(c.global.confPath)
 }
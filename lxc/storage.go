@@ -34,6 +34,10 @@ func (c *cmdStorage) Command() *cobra.Command {
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
 		`Manage storage pools and volumes`))
 
+	// Bucket
+	storageBucketCmd := cmdStorageBucket{global: c.global}
+	cmd.AddCommand(storageBucketCmd.Command())
+
 	// Create
 	storageCreateCmd := cmdStorageCreate{global: c.global, storage: c}
 	cmd.AddCommand(storageCreateCmd.Command())
@@ -0,0 +1,979 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	lxd "github.com/lxc/lxd/client"
+	"github.com/lxc/lxd/lxc/utils"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	cli "github.com/lxc/lxd/shared/cmd"
+	"github.com/lxc/lxd/shared/i18n"
+	"github.com/lxc/lxd/shared/termios"
+)
+
+type cmdStorageBucket struct {
+	global     *cmdGlobal
+	flagTarget string
+}
+
+func (c *cmdStorageBucket) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("bucket")
+	cmd.Short = i18n.G("Manage storage buckets")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Manage storage buckets"))
+
+	// List.
+	storageBucketListCmd := cmdStorageBucketList{global: c.global, storageBucket: c}
+	cmd.AddCommand(storageBucketListCmd.Command())
+
+	// Show.
+	storageBucketShowCmd := cmdStorageBucketShow{global: c.global, storageBucket: c}
+	cmd.AddCommand(storageBucketShowCmd.Command())
+
+	// Create.
+	storageBucketCreateCmd := cmdStorageBucketCreate{global: c.global, storageBucket: c}
+	cmd.AddCommand(storageBucketCreateCmd.Command())
+
+	// Get.
+	storageBucketGetCmd := cmdStorageBucketGet{global: c.global, storageBucket: c}
+	cmd.AddCommand(storageBucketGetCmd.Command())
+
+	// Set.
+	storageBucketSetCmd := cmdStorageBucketSet{global: c.global, storageBucket: c}
+	cmd.AddCommand(storageBucketSetCmd.Command())
+
+	// Unset.
+	storageBucketUnsetCmd := cmdStorageBucketUnset{global: c.global, storageBucket: c, storageBucketSet: &storageBucketSetCmd}
+	cmd.AddCommand(storageBucketUnsetCmd.Command())
+
+	// Edit.
+	storageBucketEditCmd := cmdStorageBucketEdit{global: c.global, storageBucket: c}
+	cmd.AddCommand(storageBucketEditCmd.Command())
+
+	// Delete.
+	storageBucketDeleteCmd := cmdStorageBucketDelete{global: c.global, storageBucket: c}
+	cmd.AddCommand(storageBucketDeleteCmd.Command())
+
+	// Key.
+	storageBucketKeyCmd := cmdStorageBucketKey{global: c.global, storageBucket: c}
+	cmd.AddCommand(storageBucketKeyCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { cmd.Usage() }
+	return cmd
+}
+
+// parseArgs parses the pool and bucket name arguments and returns the client to use.
+func (c *cmdStorageBucket) parseArgs(args []string) (lxd.InstanceServer, string, string, error) {
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return nil, "", "", fmt.Errorf(i18n.G("Missing pool name"))
+	}
+
+	bucketName := ""
+	if len(args) > 1 {
+		bucketName = args[1]
+		if bucketName == "" {
+			return nil, "", "", fmt.Errorf(i18n.G("Missing bucket name"))
+		}
+	}
+
+	client := resource.server
+
+	// If a target was specified, use the bucket on the given member.
+	if c.flagTarget != "" {
+		client = client.UseTarget(c.flagTarget)
+	}
+
+	return client, resource.name, bucketName, nil
+}
+
+// List.
+type cmdStorageBucketList struct {
+	global        *cmdGlobal
+	storageBucket *cmdStorageBucket
+
+	flagFormat string
+}
+
+func (c *cmdStorageBucketList) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]<pool>"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List storage buckets")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("List storage buckets"))
+	cmd.RunE = c.Run
+
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+	cmd.Flags().StringVar(&c.storageBucket.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+
+	return cmd
+}
+
+func (c *cmdStorageBucketList) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	client, poolName, _, err := c.storageBucket.parseArgs(args)
+	if err != nil {
+		return err
+	}
+
+	buckets, err := client.GetStoragePoolBuckets(poolName)
+	if err != nil {
+		return err
+	}
+
+	clustered := client.IsClustered()
+
+	data := make([][]string, 0, len(buckets))
+	for _, bucket := range buckets {
+		details := []string{
+			bucket.Name,
+			bucket.Description,
+			bucket.S3URL,
+		}
+
+		if clustered {
+			details = append(details, bucket.Location)
+		}
+
+		data = append(data, details)
+	}
+	sort.Sort(utils.ByName(data))
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("DESCRIPTION"),
+		i18n.G("S3 URL"),
+	}
+
+	if clustered {
+		header = append(header, i18n.G("LOCATION"))
+	}
+
+	return utils.RenderTable(c.flagFormat, header, data, buckets)
+}
+
+// Show.
+type cmdStorageBucketShow struct {
+	global        *cmdGlobal
+	storageBucket *cmdStorageBucket
+}
+
+func (c *cmdStorageBucketShow) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<pool> <bucket>"))
+	cmd.Short = i18n.G("Show storage bucket configurations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Show storage bucket configurations"))
+	cmd.RunE = c.Run
+
+	cmd.Flags().StringVar(&c.storageBucket.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+
+	return cmd
+}
+
+func (c *cmdStorageBucketShow) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	client, poolName, bucketName, err := c.storageBucket.parseArgs(args)
+	if err != nil {
+		return err
+	}
+
+	// Show the storage bucket config.
+	bucket, _, err := client.GetStoragePoolBucket(poolName, bucketName)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&bucket)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Create.
+type cmdStorageBucketCreate struct {
+	global        *cmdGlobal
+	storageBucket *cmdStorageBucket
+}
+
+func (c *cmdStorageBucketCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<pool> <bucket> [key=value...]"))
+	cmd.Short = i18n.G("Create new storage buckets")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Create new storage buckets
+
+An admin key is created for the new bucket and its credentials are printed.`))
+	cmd.RunE = c.Run
+
+	cmd.Flags().StringVar(&c.storageBucket.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+
+	return cmd
+}
+
+func (c *cmdStorageBucketCreate) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	client, poolName, bucketName, err := c.storageBucket.parseArgs(args)
+	if err != nil {
+		return err
+	}
+
+	// If stdin isn't a terminal, read yaml from it.
+	var bucketPut api.StorageBucketPut
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		err = yaml.UnmarshalStrict(contents, &bucketPut)
+		if err != nil {
+			return err
+		}
+	}
+
+	if bucketPut.Config == nil {
+		bucketPut.Config = map[string]string{}
+	}
+
+	// Get config filters from arguments.
+	for i := 2; i < len(args); i++ {
+		entry := strings.SplitN(args[i], "=", 2)
+		if len(entry) < 2 {
+			return fmt.Errorf(i18n.G("Bad key/value pair: %s"), args[i])
+		}
+
+		bucketPut.Config[entry[0]] = entry[1]
+	}
+
+	// Create the storage bucket.
+	bucket := api.StorageBucketsPost{
+		Name:             bucketName,
+		StorageBucketPut: bucketPut,
+	}
+
+	err = client.CreateStoragePoolBucket(poolName, bucket)
+	if err != nil {
+		return err
+	}
+
+	// Create an initial admin key so the new bucket is usable straight away.
+	key, err := client.CreateStoragePoolBucketKey(poolName, bucketName, api.StorageBucketKeysPost{
+		Name: "admin",
+		StorageBucketKeyPut: api.StorageBucketKeyPut{
+			Role: "admin",
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Storage bucket %s created")+"\n", bucketName)
+		fmt.Printf(i18n.G("Access key: %s")+"\n", key.AccessKey)
+		fmt.Printf(i18n.G("Secret key: %s")+"\n", key.SecretKey)
+	}
+
+	return nil
+}
+
+// Get.
+type cmdStorageBucketGet struct {
+	global        *cmdGlobal
+	storageBucket *cmdStorageBucket
+}
+
+func (c *cmdStorageBucketGet) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("get", i18n.G("[<remote>:]<pool> <bucket> <key>"))
+	cmd.Short = i18n.G("Get values for storage bucket configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Get values for storage bucket configuration keys"))
+	cmd.RunE = c.Run
+
+	cmd.Flags().StringVar(&c.storageBucket.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+
+	return cmd
+}
+
+func (c *cmdStorageBucketGet) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, 3)
+	if exit {
+		return err
+	}
+
+	client, poolName, bucketName, err := c.storageBucket.parseArgs(args)
+	if err != nil {
+		return err
+	}
+
+	// Get the current config.
+	bucket, _, err := client.GetStoragePoolBucket(poolName, bucketName)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range bucket.Config {
+		if k == args[2] {
+			fmt.Printf("%s\n", v)
+		}
+	}
+
+	return nil
+}
+
+// Set.
+type cmdStorageBucketSet struct {
+	global        *cmdGlobal
+	storageBucket *cmdStorageBucket
+}
+
+func (c *cmdStorageBucketSet) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("set", i18n.G("[<remote>:]<pool> <bucket> <key>=<value>..."))
+	cmd.Short = i18n.G("Set storage bucket configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Set storage bucket configuration keys
+
+For backward compatibility, a single configuration key may still be set with:
+    lxc storage bucket set [<remote>:]<pool> <bucket> <key> <value>`))
+	cmd.RunE = c.Run
+
+	cmd.Flags().StringVar(&c.storageBucket.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+
+	return cmd
+}
+
+func (c *cmdStorageBucketSet) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, -1)
+	if exit {
+		return err
+	}
+
+	client, poolName, bucketName, err := c.storageBucket.parseArgs(args)
+	if err != nil {
+		return err
+	}
+
+	// Get the current config.
+	bucket, etag, err := client.GetStoragePoolBucket(poolName, bucketName)
+	if err != nil {
+		return err
+	}
+
+	if bucket.Config == nil {
+		bucket.Config = map[string]string{}
+	}
+
+	// Set the keys.
+	keys, err := getConfig(args[2:]...)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range keys {
+		bucket.Config[k] = v
+	}
+
+	return client.UpdateStoragePoolBucket(poolName, bucketName, bucket.Writable(), etag)
+}
+
+// Unset.
+type cmdStorageBucketUnset struct {
+	global           *cmdGlobal
+	storageBucket    *cmdStorageBucket
+	storageBucketSet *cmdStorageBucketSet
+}
+
+func (c *cmdStorageBucketUnset) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("unset", i18n.G("[<remote>:]<pool> <bucket> <key>"))
+	cmd.Short = i18n.G("Unset storage bucket configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Unset storage bucket configuration keys"))
+	cmd.RunE = c.Run
+
+	cmd.Flags().StringVar(&c.storageBucket.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+
+	return cmd
+}
+
+func (c *cmdStorageBucketUnset) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, 3)
+	if exit {
+		return err
+	}
+
+	args = append(args, "")
+	return c.storageBucketSet.Run(cmd, args)
+}
+
+// Edit.
+type cmdStorageBucketEdit struct {
+	global        *cmdGlobal
+	storageBucket *cmdStorageBucket
+}
+
+func (c *cmdStorageBucketEdit) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<pool> <bucket>"))
+	cmd.Short = i18n.G("Edit storage bucket configurations as YAML")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Edit storage bucket configurations as YAML"))
+	cmd.RunE = c.Run
+
+	cmd.Flags().StringVar(&c.storageBucket.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+
+	return cmd
+}
+
+func (c *cmdStorageBucketEdit) helpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the storage bucket.
+### Any line starting with a '# will be ignored.
+###
+### A storage bucket consists of a set of
+### configuration items.
+###
+### name: bucket1
+### description: My first bucket
+### config:
+###   size: 5GiB
+### s3_url: https://192.0.2.1:9000/bucket1
+### location: lxd01
+###
+### Note that the name, s3_url and location cannot be changed.`)
+}
+
+func (c *cmdStorageBucketEdit) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	client, poolName, bucketName, err := c.storageBucket.parseArgs(args)
+	if err != nil {
+		return err
+	}
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		// Allow output of `lxc storage bucket show` command to passed in here, but only take the contents
+		// of the StorageBucketPut fields when updating. The other fields are silently discarded.
+		newData := api.StorageBucket{}
+		err = yaml.UnmarshalStrict(contents, &newData)
+		if err != nil {
+			return err
+		}
+
+		return client.UpdateStoragePoolBucket(poolName, bucketName, newData.Writable(), "")
+	}
+
+	// Get the current config.
+	bucket, etag, err := client.GetStoragePoolBucket(poolName, bucketName)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&bucket)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor.
+	content, err := shared.TextEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor.
+		newData := api.StorageBucket{} // We show the full info, but only send the writable fields.
+		err = yaml.UnmarshalStrict(content, &newData)
+		if err == nil {
+			err = client.UpdateStoragePoolBucket(poolName, bucketName, newData.Writable(), etag)
+		}
+
+		// Respawn the editor.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdStorageBucketDelete struct {
+	global        *cmdGlobal
+	storageBucket *cmdStorageBucket
+}
+
+func (c *cmdStorageBucketDelete) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<pool> <bucket>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Delete storage buckets")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Delete storage buckets"))
+	cmd.RunE = c.Run
+
+	cmd.Flags().StringVar(&c.storageBucket.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+
+	return cmd
+}
+
+func (c *cmdStorageBucketDelete) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	client, poolName, bucketName, err := c.storageBucket.parseArgs(args)
+	if err != nil {
+		return err
+	}
+
+	// Delete the storage bucket.
+	err = client.DeleteStoragePoolBucket(poolName, bucketName)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Storage bucket %s deleted")+"\n", bucketName)
+	}
+
+	return nil
+}
+
+// Key.
+type cmdStorageBucketKey struct {
+	global        *cmdGlobal
+	storageBucket *cmdStorageBucket
+}
+
+func (c *cmdStorageBucketKey) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("key")
+	cmd.Short = i18n.G("Manage storage bucket keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Manage storage bucket keys"))
+
+	// List.
+	storageBucketKeyListCmd := cmdStorageBucketKeyList{global: c.global, storageBucketKey: c}
+	cmd.AddCommand(storageBucketKeyListCmd.Command())
+
+	// Create.
+	storageBucketKeyCreateCmd := cmdStorageBucketKeyCreate{global: c.global, storageBucketKey: c}
+	cmd.AddCommand(storageBucketKeyCreateCmd.Command())
+
+	// Show.
+	storageBucketKeyShowCmd := cmdStorageBucketKeyShow{global: c.global, storageBucketKey: c}
+	cmd.AddCommand(storageBucketKeyShowCmd.Command())
+
+	// Edit.
+	storageBucketKeyEditCmd := cmdStorageBucketKeyEdit{global: c.global, storageBucketKey: c}
+	cmd.AddCommand(storageBucketKeyEditCmd.Command())
+
+	// Delete.
+	storageBucketKeyDeleteCmd := cmdStorageBucketKeyDelete{global: c.global, storageBucketKey: c}
+	cmd.AddCommand(storageBucketKeyDeleteCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { cmd.Usage() }
+	return cmd
+}
+
+// List Keys.
+type cmdStorageBucketKeyList struct {
+	global           *cmdGlobal
+	storageBucketKey *cmdStorageBucketKey
+
+	flagFormat string
+}
+
+func (c *cmdStorageBucketKeyList) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]<pool> <bucket>"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List storage bucket keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("List storage bucket keys"))
+	cmd.RunE = c.Run
+
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+	cmd.Flags().StringVar(&c.storageBucketKey.storageBucket.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+
+	return cmd
+}
+
+func (c *cmdStorageBucketKeyList) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	client, poolName, bucketName, err := c.storageBucketKey.storageBucket.parseArgs(args)
+	if err != nil {
+		return err
+	}
+
+	keys, err := client.GetStoragePoolBucketKeys(poolName, bucketName)
+	if err != nil {
+		return err
+	}
+
+	data := make([][]string, 0, len(keys))
+	for _, key := range keys {
+		details := []string{
+			key.Name,
+			key.Description,
+			key.Role,
+		}
+
+		data = append(data, details)
+	}
+	sort.Sort(utils.ByName(data))
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("DESCRIPTION"),
+		i18n.G("ROLE"),
+	}
+
+	return utils.RenderTable(c.flagFormat, header, data, keys)
+}
+
+// Create Key.
+type cmdStorageBucketKeyCreate struct {
+	global           *cmdGlobal
+	storageBucketKey *cmdStorageBucketKey
+
+	flagRole      string
+	flagAccessKey string
+	flagSecretKey string
+}
+
+func (c *cmdStorageBucketKeyCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<pool> <bucket> <key>"))
+	cmd.Short = i18n.G("Create key for a storage bucket")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Create key for a storage bucket
+
+Access and secret keys are generated if not specified.`))
+	cmd.RunE = c.Run
+
+	cmd.Flags().StringVar(&c.flagRole, "role", "read-only", i18n.G("Role (admin or read-only)")+"``")
+	cmd.Flags().StringVar(&c.flagAccessKey, "access-key", "", i18n.G("Access key (auto-generated if empty)")+"``")
+	cmd.Flags().StringVar(&c.flagSecretKey, "secret-key", "", i18n.G("Secret key (auto-generated if empty)")+"``")
+	cmd.Flags().StringVar(&c.storageBucketKey.storageBucket.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+
+	return cmd
+}
+
+func (c *cmdStorageBucketKeyCreate) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, 3)
+	if exit {
+		return err
+	}
+
+	client, poolName, bucketName, err := c.storageBucketKey.storageBucket.parseArgs(args)
+	if err != nil {
+		return err
+	}
+
+	if args[2] == "" {
+		return fmt.Errorf(i18n.G("Missing key name"))
+	}
+
+	req := api.StorageBucketKeysPost{
+		Name: args[2],
+		StorageBucketKeyPut: api.StorageBucketKeyPut{
+			Role:      c.flagRole,
+			AccessKey: c.flagAccessKey,
+			SecretKey: c.flagSecretKey,
+		},
+	}
+
+	key, err := client.CreateStoragePoolBucketKey(poolName, bucketName, req)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Storage bucket key %s added")+"\n", key.Name)
+		fmt.Printf(i18n.G("Access key: %s")+"\n", key.AccessKey)
+		fmt.Printf(i18n.G("Secret key: %s")+"\n", key.SecretKey)
+	}
+
+	return nil
+}
+
+// Show Key.
+type cmdStorageBucketKeyShow struct {
+	global           *cmdGlobal
+	storageBucketKey *cmdStorageBucketKey
+}
+
+func (c *cmdStorageBucketKeyShow) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<pool> <bucket> <key>"))
+	cmd.Short = i18n.G("Show storage bucket key configurations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Show storage bucket key configurations"))
+	cmd.RunE = c.Run
+
+	cmd.Flags().StringVar(&c.storageBucketKey.storageBucket.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+
+	return cmd
+}
+
+func (c *cmdStorageBucketKeyShow) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, 3)
+	if exit {
+		return err
+	}
+
+	client, poolName, bucketName, err := c.storageBucketKey.storageBucket.parseArgs(args)
+	if err != nil {
+		return err
+	}
+
+	if args[2] == "" {
+		return fmt.Errorf(i18n.G("Missing key name"))
+	}
+
+	key, _, err := client.GetStoragePoolBucketKey(poolName, bucketName, args[2])
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&key)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Edit Key.
+type cmdStorageBucketKeyEdit struct {
+	global           *cmdGlobal
+	storageBucketKey *cmdStorageBucketKey
+}
+
+func (c *cmdStorageBucketKeyEdit) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<pool> <bucket> <key>"))
+	cmd.Short = i18n.G("Edit storage bucket key as YAML")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Edit storage bucket key as YAML"))
+	cmd.RunE = c.Run
+
+	cmd.Flags().StringVar(&c.storageBucketKey.storageBucket.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+
+	return cmd
+}
+
+func (c *cmdStorageBucketKeyEdit) helpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the storage bucket key.
+### Any line starting with a '# will be ignored.
+###
+### name: key1
+### description: My first key
+### role: read-only
+### access-key: DJHJH6B9CFG2KUTWFHIW
+### secret-key: D16TqN8Vjh1fY0f3yfZG2o5ww+Eb+1zL2dpcIVKe
+###
+### Note that the name cannot be changed.`)
+}
+
+func (c *cmdStorageBucketKeyEdit) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, 3)
+	if exit {
+		return err
+	}
+
+	client, poolName, bucketName, err := c.storageBucketKey.storageBucket.parseArgs(args)
+	if err != nil {
+		return err
+	}
+
+	if args[2] == "" {
+		return fmt.Errorf(i18n.G("Missing key name"))
+	}
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		// Allow output of `lxc storage bucket key show` command to passed in here, but only take the
+		// contents of the StorageBucketKeyPut fields when updating. The other fields are silently discarded.
+		newData := api.StorageBucketKey{}
+		err = yaml.UnmarshalStrict(contents, &newData)
+		if err != nil {
+			return err
+		}
+
+		return client.UpdateStoragePoolBucketKey(poolName, bucketName, args[2], newData.Writable(), "")
+	}
+
+	// Get the current config.
+	key, etag, err := client.GetStoragePoolBucketKey(poolName, bucketName, args[2])
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&key)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor.
+	content, err := shared.TextEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor.
+		newData := api.StorageBucketKey{} // We show the full info, but only send the writable fields.
+		err = yaml.UnmarshalStrict(content, &newData)
+		if err == nil {
+			err = client.UpdateStoragePoolBucketKey(poolName, bucketName, args[2], newData.Writable(), etag)
+		}
+
+		// Respawn the editor.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// Delete Key.
+type cmdStorageBucketKeyDelete struct {
+	global           *cmdGlobal
+	storageBucketKey *cmdStorageBucketKey
+}
+
+func (c *cmdStorageBucketKeyDelete) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<pool> <bucket> <key>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Delete key from a storage bucket")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Delete key from a storage bucket"))
+	cmd.RunE = c.Run
+
+	cmd.Flags().StringVar(&c.storageBucketKey.storageBucket.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+
+	return cmd
+}
+
+func (c *cmdStorageBucketKeyDelete) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, 3)
+	if exit {
+		return err
+	}
+
+	client, poolName, bucketName, err := c.storageBucketKey.storageBucket.parseArgs(args)
+	if err != nil {
+		return err
+	}
+
+	if args[2] == "" {
+		return fmt.Errorf(i18n.G("Missing key name"))
+	}
+
+	err = client.DeleteStoragePoolBucketKey(poolName, bucketName, args[2])
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Storage bucket key %s removed")+"\n", args[2])
+	}
+
+	return nil
+}
@@ -1313,6 +1313,15 @@ func (c *cmdStorageVolumeInfo) Run(cmd *cobra.Command, args []string) error {
 		_ = utils.RenderTable(utils.TableFormatTable, backupHeader, backupData, volBackups)
 	}
 
+	// Volume snapshots don't record their creation time, but are listed in creation order.
+	replicationSnapshots := make([]replicationSnapshot, 0, len(volSnapshots))
+	for _, snap := range volSnapshots {
+		fields := strings.Split(snap.Name, shared.SnapshotDelimiter)
+		replicationSnapshots = append(replicationSnapshots, replicationSnapshot{name: fields[len(fields)-1]})
+	}
+
+	renderReplicationInfo(vol.Config, replicationSnapshots, layout)
+
 	return nil
 }
 
@@ -17,6 +17,8 @@ var api10Cmd = APIEndpoint{
 var api10 = []APIEndpoint{
 	api10Cmd,
 	execCmd,
+	freezeCmd,
+	guestCmd,
 	eventsCmd,
 	metricsCmd,
 	operationsCmd,
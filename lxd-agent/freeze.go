@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/lxd/lxd/response"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/logger"
+)
+
+var freezeCmd = APIEndpoint{
+	Path: "freeze",
+
+	Post:   APIEndpointAction{Handler: freezePost},
+	Delete: APIEndpointAction{Handler: freezeDelete},
+}
+
+// freezeHooksDir contains the executables run with the "freeze" argument before freezing the filesystems, and with
+// the "thaw" argument after thawing them, so that applications can flush and suspend their writes.
+const freezeHooksDir = "/etc/lxd-agent/freeze-hook.d"
+
+// freezeDefaultTimeout is the default time after which frozen filesystems are thawed if LXD doesn't thaw them.
+const freezeDefaultTimeout = 60 * time.Second
+
+// Filesystem freezing ioctl requests.
+const (
+	ioctlFIFREEZE = 0xC0045877
+	ioctlFITHAW   = 0xC0045878
+)
+
+// freezePostArgs represents the arguments of a freeze request.
+type freezePostArgs struct {
+	// Seconds after which the filesystems are thawed if not thawed by LXD.
+	Timeout int `json:"timeout"`
+}
+
+// filesystemFreezer tracks the frozen filesystems.
+type filesystemFreezer struct {
+	mu sync.Mutex
+
+	// Frozen mount points, in freezing order.
+	mountpoints []string
+
+	// Freeze hooks run, in running order.
+	hooks []string
+
+	// Automatic thaw once the timeout expires.
+	timer *time.Timer
+
+	// Whether the filesystems were thawed because the timeout expired.
+	expired bool
+
+	// Directory containing the freeze hooks.
+	hooksDir string
+
+	// Returns the mount points of the filesystems to freeze.
+	listMountpoints func() ([]string, error)
+
+	// Runs a freeze or thaw ioctl request on a mounted filesystem.
+	ioctl func(mountpoint string, request uint) error
+}
+
+var freezer = &filesystemFreezer{
+	hooksDir:        freezeHooksDir,
+	listMountpoints: freezeMountpoints,
+	ioctl:           ioctlFilesystem,
+}
+
+func freezePost(d *Daemon, r *http.Request) response.Response {
+	args := freezePostArgs{}
+
+	err := json.NewDecoder(r.Body).Decode(&args)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	timeout := freezeDefaultTimeout
+	if args.Timeout > 0 {
+		timeout = time.Duration(args.Timeout) * time.Second
+	}
+
+	err = freezer.freeze(timeout)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+func freezeDelete(d *Daemon, r *http.Request) response.Response {
+	err := freezer.thaw()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+// freeze runs the freeze hooks and then freezes the writable block device backed filesystems, until thawed or
+// until the timeout expires.
+func (f *filesystemFreezer) freeze(timeout time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.timer != nil {
+		return api.StatusErrorf(http.StatusConflict, "The filesystems are already frozen")
+	}
+
+	hooks, err := freezeHooks(f.hooksDir)
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range hooks {
+		_, err := shared.RunCommand(hook, "freeze")
+		if err != nil {
+			f.thawLocked()
+			return fmt.Errorf("Failed running freeze hook %q: %w", hook, err)
+		}
+
+		f.hooks = append(f.hooks, hook)
+	}
+
+	mountpoints, err := f.listMountpoints()
+	if err != nil {
+		f.thawLocked()
+		return err
+	}
+
+	// Freeze the nested mounts first.
+	for i := len(mountpoints) - 1; i >= 0; i-- {
+		err := f.ioctl(mountpoints[i], ioctlFIFREEZE)
+		if errors.Is(err, unix.EOPNOTSUPP) {
+			continue
+		}
+
+		if err != nil {
+			f.thawLocked()
+			return fmt.Errorf("Failed freezing filesystem %q: %w", mountpoints[i], err)
+		}
+
+		f.mountpoints = append(f.mountpoints, mountpoints[i])
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(timeout, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		// Skip if thawed in the meantime.
+		if f.timer != timer {
+			return
+		}
+
+		f.thawLocked()
+		f.expired = true
+		logger.Warn("Thawed filesystems after freeze timeout expired", logger.Ctx{"timeout": timeout})
+	})
+
+	f.timer = timer
+	f.expired = false
+
+	return nil
+}
+
+// thaw thaws the frozen filesystems and runs the thaw hooks. Fails if the filesystems aren't frozen anymore.
+func (f *filesystemFreezer) thaw() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.timer == nil {
+		if f.expired {
+			return api.StatusErrorf(http.StatusConflict, "The filesystems were thawed as the freeze timeout expired")
+		}
+
+		return api.StatusErrorf(http.StatusConflict, "The filesystems aren't frozen")
+	}
+
+	f.timer.Stop()
+
+	return f.thawLocked()
+}
+
+// thawLocked thaws the frozen filesystems in reverse order and then runs the thaw hooks of the freeze hooks that
+// were run, in reverse order too.
+func (f *filesystemFreezer) thawLocked() error {
+	var thawErr error
+
+	for i := len(f.mountpoints) - 1; i >= 0; i-- {
+		err := f.ioctl(f.mountpoints[i], ioctlFITHAW)
+		if err != nil && thawErr == nil {
+			thawErr = fmt.Errorf("Failed thawing filesystem %q: %w", f.mountpoints[i], err)
+		}
+	}
+
+	for i := len(f.hooks) - 1; i >= 0; i-- {
+		_, err := shared.RunCommand(f.hooks[i], "thaw")
+		if err != nil && thawErr == nil {
+			thawErr = fmt.Errorf("Failed running thaw hook %q: %w", f.hooks[i], err)
+		}
+	}
+
+	f.mountpoints = nil
+	f.hooks = nil
+	f.timer = nil
+
+	return thawErr
+}
+
+// freezeHooks returns the executables in the hooks directory, in lexical order.
+func freezeHooks(hooksDir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(hooksDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("Failed listing freeze hooks: %w", err)
+	}
+
+	hooks := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Mode()&0111 == 0 {
+			continue
+		}
+
+		hooks = append(hooks, filepath.Join(hooksDir, entry.Name()))
+	}
+
+	sort.Strings(hooks)
+
+	return hooks, nil
+}
+
+// freezeMountpoints returns the mount points of the writable block device backed filesystems, in mount order.
+// Filesystems mounted several times are only returned once.
+func freezeMountpoints() ([]string, error) {
+	file, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open /proc/self/mountinfo: %w", err)
+	}
+
+	defer file.Close()
+
+	mountpoints, err := parseFreezeMountpoints(file)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read /proc/self/mountinfo: %w", err)
+	}
+
+	return mountpoints, nil
+}
+
+// parseFreezeMountpoints returns the mount points of the writable block device backed filesystems listed in the
+// mountinfo content, in mount order. Filesystems mounted several times are only returned once.
+func parseFreezeMountpoints(mountinfo io.Reader) ([]string, error) {
+	devices := []string{}
+	mountpoints := []string{}
+
+	scanner := bufio.NewScanner(mountinfo)
+	for scanner.Scan() {
+		// Mount ID, parent ID, major:minor, root, mount point, mount options, optional fields, "-", filesystem
+		// type, source and super options.
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+
+		separator := -1
+		for i := 6; i < len(fields); i++ {
+			if fields[i] == "-" {
+				separator = i
+				break
+			}
+		}
+
+		if separator < 0 || separator+2 >= len(fields) {
+			continue
+		}
+
+		device := fields[2]
+		mountpoint := unescapeMountinfo(fields[4])
+		options := strings.Split(fields[5], ",")
+		fsType := fields[separator+1]
+		source := fields[separator+2]
+
+		if !strings.HasPrefix(source, "/dev/") || shared.StringInSlice("ro", options) || shared.StringInSlice(fsType, defFSTypesExcluded) {
+			continue
+		}
+
+		if shared.StringInSlice(device, devices) {
+			continue
+		}
+
+		devices = append(devices, device)
+		mountpoints = append(mountpoints, mountpoint)
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return mountpoints, nil
+}
+
+// unescapeMountinfo unescapes the octal sequences used for spaces, tabs, newlines and backslashes in mountinfo.
+func unescapeMountinfo(value string) string {
+	replacer := strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`)
+	return replacer.Replace(value)
+}
+
+// ioctlFilesystem runs a freeze or thaw ioctl request on the filesystem mounted at mountpoint.
+func ioctlFilesystem(mountpoint string, request uint) error {
+	fd, err := unix.Open(mountpoint, unix.O_RDONLY|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return err
+	}
+
+	defer unix.Close(fd)
+
+	return unix.IoctlSetInt(fd, request, 0)
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/lxd/shared/api"
+)
+
+func TestUnescapeMountinfo(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"/", "/"},
+		{"/mnt/data", "/mnt/data"},
+		{`/mnt/my\040data`, "/mnt/my data"},
+		{`/mnt/tab\011and\012newline`, "/mnt/tab\tand\nnewline"},
+		{`/mnt/back\134slash`, `/mnt/back\slash`},
+		{`/mnt/\040\040`, "/mnt/  "},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			assert.Equal(t, test.expected, unescapeMountinfo(test.value))
+		})
+	}
+}
+
+func TestParseFreezeMountpoints(t *testing.T) {
+	tests := []struct {
+		name      string
+		mountinfo string
+		expected  []string
+	}{
+		{
+			name: "typical guest",
+			mountinfo: `22 1 8:2 / / rw,relatime shared:1 - ext4 /dev/sda2 rw
+23 22 0:21 / /proc rw,nosuid,nodev,noexec,relatime shared:12 - proc proc rw
+24 22 0:22 / /sys rw,nosuid,nodev,noexec,relatime shared:2 - sysfs sysfs rw
+25 22 0:5 / /dev rw,nosuid,relatime shared:8 - devtmpfs udev rw,size=4020628k,nr_inodes=1005157,mode=755
+26 22 0:25 / /run rw,nosuid,nodev,noexec,relatime shared:10 - tmpfs tmpfs rw,size=807744k,mode=755
+27 22 8:1 / /boot/efi rw,relatime shared:30 - vfat /dev/sda1 rw,fmask=0077,dmask=0077
+28 22 7:0 / /snap/core20/1828 ro,nodev,relatime shared:31 - squashfs /dev/loop0 ro
+29 22 8:17 / /mnt/my\040data rw,relatime shared:32 - xfs /dev/sdb1 rw,attr2,inode64
+`,
+			expected: []string{"/", "/boot/efi", "/mnt/my data"},
+		},
+		{
+			name: "read-only and bind mounts",
+			mountinfo: `22 1 8:2 / / rw,relatime - ext4 /dev/sda2 rw
+30 22 8:2 /srv /var/srv rw,relatime - ext4 /dev/sda2 rw
+31 22 8:33 / /mnt/ro ro,relatime - ext4 /dev/sdc1 ro
+32 22 0:50 / /mnt/lxd_config rw,relatime - virtiofs config rw
+`,
+			expected: []string{"/"},
+		},
+		{
+			name: "optional fields and excluded filesystem types",
+			mountinfo: `22 1 8:2 / / rw,relatime shared:1 master:2 - btrfs /dev/vda2 rw,subvol=/
+40 22 11:0 / /media/cdrom rw,relatime shared:40 - iso9660 /dev/sr0 rw
+41 22 8:3 / /home rw,relatime propagate_from:1 unbindable - ext4 /dev/vda3 rw
+`,
+			expected: []string{"/", "/home"},
+		},
+		{
+			name: "malformed lines",
+			mountinfo: `22 1 8:2 / / rw,relatime
+23 1 8:3 / /data rw,relatime shared:1 ext4 /dev/sda3 rw
+24 1 8:4 / /other rw,relatime shared:1 -
+`,
+			expected: []string{},
+		},
+		{
+			name:      "empty",
+			mountinfo: "",
+			expected:  []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mountpoints, err := parseFreezeMountpoints(strings.NewReader(test.mountinfo))
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, mountpoints)
+		})
+	}
+}
+
+// freezeTestIoctl records the freeze and thaw ioctl requests, optionally failing some of them.
+type freezeTestIoctl struct {
+	mu       sync.Mutex
+	requests []string
+	failures map[string]error
+}
+
+func (i *freezeTestIoctl) ioctl(mountpoint string, request uint) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	action := "freeze"
+	if request == ioctlFITHAW {
+		action = "thaw"
+	}
+
+	err := i.failures[fmt.Sprintf("%s %s", action, mountpoint)]
+	if err == nil {
+		i.requests = append(i.requests, fmt.Sprintf("%s %s", action, mountpoint))
+	}
+
+	return err
+}
+
+func (i *freezeTestIoctl) recorded() []string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return append([]string{}, i.requests...)
+}
+
+// newTestFreezer returns a filesystemFreezer using fake mount points and ioctl requests, and freeze hooks in a
+// temporary directory which log their runs to the returned file.
+func newTestFreezer(t *testing.T, hooks map[string]string) (*filesystemFreezer, *freezeTestIoctl, string) {
+	hooksDir := t.TempDir()
+	logPath := filepath.Join(t.TempDir(), "hooks.log")
+
+	for name, script := range hooks {
+		content := fmt.Sprintf("#!/bin/sh\necho \"%s $1\" >> %s\n%s\n", name, logPath, script)
+		err := os.WriteFile(filepath.Join(hooksDir, name), []byte(content), 0755)
+		require.NoError(t, err)
+	}
+
+	// Neither non executable files nor directories are hooks.
+	err := os.WriteFile(filepath.Join(hooksDir, "README"), []byte("Not a hook"), 0644)
+	require.NoError(t, err)
+
+	err = os.Mkdir(filepath.Join(hooksDir, "subdir"), 0755)
+	require.NoError(t, err)
+
+	ioctl := &freezeTestIoctl{failures: map[string]error{}}
+
+	f := &filesystemFreezer{
+		hooksDir: hooksDir,
+		listMountpoints: func() ([]string, error) {
+			return []string{"/", "/boot/efi", "/mnt/data"}, nil
+		},
+		ioctl: ioctl.ioctl,
+	}
+
+	return f, ioctl, logPath
+}
+
+// hookRuns returns the hook runs logged to logPath.
+func hookRuns(t *testing.T, logPath string) []string {
+	content, err := os.ReadFile(logPath)
+	if os.IsNotExist(err) {
+		return []string{}
+	}
+
+	require.NoError(t, err)
+
+	return strings.Split(strings.TrimSpace(string(content)), "\n")
+}
+
+func TestFilesystemFreezer_FreezeThaw(t *testing.T) {
+	f, ioctl, logPath := newTestFreezer(t, map[string]string{"10-db": "", "20-app": ""})
+
+	err := f.freeze(time.Minute)
+	require.NoError(t, err)
+
+	// Nested mounts are frozen first, after running the hooks in lexical order.
+	assert.Equal(t, []string{"freeze /mnt/data", "freeze /boot/efi", "freeze /"}, ioctl.recorded())
+	assert.Equal(t, []string{"10-db freeze", "20-app freeze"}, hookRuns(t, logPath))
+
+	// Freezing again is refused.
+	err = f.freeze(time.Minute)
+	assert.True(t, api.StatusErrorCheck(err, http.StatusConflict))
+
+	err = f.thaw()
+	require.NoError(t, err)
+
+	// Filesystems are thawed and hooks run in reverse order.
+	assert.Equal(t, []string{"freeze /mnt/data", "freeze /boot/efi", "freeze /", "thaw /", "thaw /boot/efi", "thaw /mnt/data"}, ioctl.recorded())
+	assert.Equal(t, []string{"10-db freeze", "20-app freeze", "20-app thaw", "10-db thaw"}, hookRuns(t, logPath))
+
+	// Thawing again fails as nothing is frozen.
+	err = f.thaw()
+	assert.True(t, api.StatusErrorCheck(err, http.StatusConflict))
+	assert.EqualError(t, err, "The filesystems aren't frozen")
+
+	// The filesystems can be frozen again.
+	err = f.freeze(time.Minute)
+	require.NoError(t, err)
+
+	err = f.thaw()
+	require.NoError(t, err)
+}
+
+func TestFilesystemFreezer_Timeout(t *testing.T) {
+	f, ioctl, logPath := newTestFreezer(t, map[string]string{"10-db": ""})
+
+	err := f.freeze(10 * time.Millisecond)
+	require.NoError(t, err)
+
+	// The filesystems are thawed automatically once the timeout expires.
+	assert.Eventually(t, func() bool {
+		return len(ioctl.recorded()) == 6
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, []string{"freeze /mnt/data", "freeze /boot/efi", "freeze /", "thaw /", "thaw /boot/efi", "thaw /mnt/data"}, ioctl.recorded())
+	assert.Equal(t, []string{"10-db freeze", "10-db thaw"}, hookRuns(t, logPath))
+
+	// Thawing then reports that the timeout expired.
+	err = f.thaw()
+	assert.True(t, api.StatusErrorCheck(err, http.StatusConflict))
+	assert.EqualError(t, err, "The filesystems were thawed as the freeze timeout expired")
+
+	// Freezing again resets the expiry.
+	err = f.freeze(time.Minute)
+	require.NoError(t, err)
+
+	err = f.thaw()
+	require.NoError(t, err)
+}
+
+func TestFilesystemFreezer_ThawBeforeTimeout(t *testing.T) {
+	f, ioctl, _ := newTestFreezer(t, nil)
+
+	err := f.freeze(50 * time.Millisecond)
+	require.NoError(t, err)
+
+	err = f.thaw()
+	require.NoError(t, err)
+
+	// The stopped timer doesn't thaw the filesystems a second time.
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, []string{"freeze /mnt/data", "freeze /boot/efi", "freeze /", "thaw /", "thaw /boot/efi", "thaw /mnt/data"}, ioctl.recorded())
+
+	err = f.thaw()
+	assert.EqualError(t, err, "The filesystems aren't frozen")
+}
+
+func TestFilesystemFreezer_FreezeFailure(t *testing.T) {
+	f, ioctl, logPath := newTestFreezer(t, map[string]string{"10-db": ""})
+
+	// Filesystems not supporting freezing are skipped, other failures thaw what was already frozen.
+	ioctl.failures["freeze /mnt/data"] = unix.EOPNOTSUPP
+	ioctl.failures["freeze /"] = unix.EIO
+
+	err := f.freeze(time.Minute)
+	assert.EqualError(t, err, `Failed freezing filesystem "/": input/output error`)
+
+	assert.Equal(t, []string{"freeze /boot/efi", "thaw /boot/efi"}, ioctl.recorded())
+	assert.Equal(t, []string{"10-db freeze", "10-db thaw"}, hookRuns(t, logPath))
+
+	// Nothing is left frozen.
+	err = f.thaw()
+	assert.EqualError(t, err, "The filesystems aren't frozen")
+}
+
+func TestFilesystemFreezer_HookFailure(t *testing.T) {
+	f, ioctl, logPath := newTestFreezer(t, map[string]string{
+		"10-db":  "",
+		"20-app": `[ "$1" = "freeze" ] && exit 1 || true`,
+	})
+
+	err := f.freeze(time.Minute)
+	assert.ErrorContains(t, err, fmt.Sprintf("Failed running freeze hook %q", filepath.Join(f.hooksDir, "20-app")))
+
+	// The hooks already run are thawed and no filesystem is frozen.
+	assert.Empty(t, ioctl.recorded())
+	assert.Equal(t, []string{"10-db freeze", "20-app freeze", "10-db thaw"}, hookRuns(t, logPath))
+
+	err = f.thaw()
+	assert.EqualError(t, err, "The filesystems aren't frozen")
+}
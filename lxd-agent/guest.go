@@ -0,0 +1,435 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lxc/lxd/lxd/response"
+	"github.com/lxc/lxd/lxd/storage/filesystem"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/logger"
+	"github.com/lxc/lxd/shared/osarch"
+)
+
+var guestCmd = APIEndpoint{
+	Path: "guest",
+
+	Get: APIEndpointAction{Handler: guestGet},
+}
+
+// Layout of the utmp records on Linux, stored in native byte order.
+const (
+	utmpRecordSize  = 384
+	utmpMaxType     = 9
+	utmpUserProcess = 7
+	utmpLineOffset  = 8
+	utmpLineSize    = 32
+	utmpUserOffset  = 44
+	utmpUserSize    = 32
+	utmpHostOffset  = 76
+	utmpHostSize    = 256
+	utmpTimeOffset  = 340
+)
+
+// procClockTicks is the number of clock ticks per second used for the process times in /proc.
+const procClockTicks = 100
+
+func guestGet(d *Daemon, r *http.Request) response.Response {
+	return response.SyncResponse(true, renderGuest())
+}
+
+func renderGuest() *api.InstanceGuest {
+	guest := &api.InstanceGuest{
+		OS:          guestOS(),
+		Processes:   []api.InstanceGuestProcess{},
+		Users:       []api.InstanceGuestUser{},
+		Filesystems: []api.InstanceGuestFilesystem{},
+		Packages:    guestPackages(),
+	}
+
+	processes, err := guestProcesses("/proc")
+	if err != nil {
+		logger.Warn("Failed to get processes", logger.Ctx{"err": err})
+	} else {
+		guest.Processes = processes
+	}
+
+	users, err := guestUsers("/run/utmp")
+	if err != nil {
+		logger.Warn("Failed to get logged-in users", logger.Ctx{"err": err})
+	} else {
+		guest.Users = users
+	}
+
+	filesystems, err := guestFilesystems()
+	if err != nil {
+		logger.Warn("Failed to get filesystems", logger.Ctx{"err": err})
+	} else {
+		guest.Filesystems = filesystems
+	}
+
+	return guest
+}
+
+func guestOS() api.InstanceGuestOS {
+	guestOS := api.InstanceGuestOS{}
+
+	osRelease, err := osarch.GetLSBRelease()
+	if err != nil {
+		logger.Warn("Failed to get OS release", logger.Ctx{"err": err})
+	}
+
+	guestOS.Name = osRelease["NAME"]
+	guestOS.Version = osRelease["VERSION"]
+	guestOS.ID = osRelease["ID"]
+
+	uname, err := shared.Uname()
+	if err != nil {
+		logger.Warn("Failed to get kernel information", logger.Ctx{"err": err})
+	} else {
+		guestOS.Kernel = uname.Sysname
+		guestOS.KernelVersion = uname.Release
+		guestOS.KernelArchitecture = uname.Machine
+	}
+
+	guestOS.Hostname, _ = os.Hostname()
+
+	return guestOS
+}
+
+// guestProcesses returns the processes listed in the proc filesystem mounted at procPath.
+func guestProcesses(procPath string) ([]api.InstanceGuestProcess, error) {
+	entries, err := ioutil.ReadDir(procPath)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read dir %q: %w", procPath, err)
+	}
+
+	pageSize := int64(os.Getpagesize())
+	users := map[string]string{}
+	processes := []api.InstanceGuestProcess{}
+
+	for _, entry := range entries {
+		pid, err := strconv.ParseInt(entry.Name(), 10, 64)
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+
+		// Processes exiting in the meantime are skipped.
+		stat, err := ioutil.ReadFile(filepath.Join(procPath, entry.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+
+		process, comm, ok := parseProcessStat(stat, pageSize)
+		if !ok {
+			continue
+		}
+
+		process.PID = pid
+
+		cmdline, err := ioutil.ReadFile(filepath.Join(procPath, entry.Name(), "cmdline"))
+		if err == nil && len(cmdline) > 0 {
+			process.Command = strings.TrimSpace(strings.ReplaceAll(string(cmdline), "\x00", " "))
+		} else {
+			// Kernel threads have no command line.
+			process.Command = fmt.Sprintf("[%s]", comm)
+		}
+
+		st, err := os.Stat(filepath.Join(procPath, entry.Name()))
+		if err == nil {
+			uid := fmt.Sprintf("%d", st.Sys().(*syscall.Stat_t).Uid)
+
+			name, ok := users[uid]
+			if !ok {
+				name = uid
+
+				u, err := user.LookupId(uid)
+				if err == nil {
+					name = u.Username
+				}
+
+				users[uid] = name
+			}
+
+			process.User = name
+		}
+
+		processes = append(processes, process)
+	}
+
+	return processes, nil
+}
+
+// parseProcessStat parses the content of the stat file of a process, returning the process with its parent PID,
+// state, CPU time and memory set, along with its command name. Returns false if the content is malformed.
+func parseProcessStat(stat []byte, pageSize int64) (api.InstanceGuestProcess, string, bool) {
+	process := api.InstanceGuestProcess{}
+
+	// The command name is between parentheses and may contain spaces.
+	end := bytes.LastIndexByte(stat, ')')
+	start := bytes.IndexByte(stat, '(')
+	if start < 0 || end < start {
+		return process, "", false
+	}
+
+	fields := strings.Fields(string(stat[end+1:]))
+	if len(fields) < 22 {
+		return process, "", false
+	}
+
+	process.State = fields[0]
+	process.PPID, _ = strconv.ParseInt(fields[1], 10, 64)
+
+	// The user and system CPU times are in clock ticks.
+	utime, _ := strconv.ParseInt(fields[11], 10, 64)
+	stime, _ := strconv.ParseInt(fields[12], 10, 64)
+	process.CPUTime = (utime + stime) * int64(time.Second) / procClockTicks
+
+	// The resident set size is in pages.
+	rss, _ := strconv.ParseInt(fields[21], 10, 64)
+	process.Memory = rss * pageSize
+
+	return process, string(stat[start+1 : end]), true
+}
+
+// guestUsers returns the logged-in users recorded in the utmp file at path.
+func guestUsers(path string) ([]api.InstanceGuestUser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []api.InstanceGuestUser{}, nil
+		}
+
+		return nil, fmt.Errorf("Failed to open %s: %w", path, err)
+	}
+
+	defer file.Close()
+
+	users, err := parseUtmp(file)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read %s: %w", path, err)
+	}
+
+	return users, nil
+}
+
+// parseUtmp returns the logged-in users recorded in the utmp records. A truncated trailing record is ignored.
+func parseUtmp(utmp io.Reader) ([]api.InstanceGuestUser, error) {
+	users := []api.InstanceGuestUser{}
+	record := make([]byte, utmpRecordSize)
+	for {
+		_, err := io.ReadFull(utmp, record)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		// The record type is a small number, which tells the byte order apart.
+		var order binary.ByteOrder = binary.LittleEndian
+		if order.Uint16(record) > utmpMaxType {
+			order = binary.BigEndian
+		}
+
+		if order.Uint16(record) != utmpUserProcess {
+			continue
+		}
+
+		loginAt := order.Uint32(record[utmpTimeOffset:])
+
+		users = append(users, api.InstanceGuestUser{
+			Name:     utmpString(record[utmpUserOffset : utmpUserOffset+utmpUserSize]),
+			Terminal: utmpString(record[utmpLineOffset : utmpLineOffset+utmpLineSize]),
+			Host:     utmpString(record[utmpHostOffset : utmpHostOffset+utmpHostSize]),
+			LoginAt:  time.Unix(int64(loginAt), 0).UTC(),
+		})
+	}
+
+	return users, nil
+}
+
+// utmpString returns the NUL terminated string of a utmp record field.
+func utmpString(field []byte) string {
+	end := bytes.IndexByte(field, 0)
+	if end >= 0 {
+		field = field[:end]
+	}
+
+	return string(field)
+}
+
+func guestFilesystems() ([]api.InstanceGuestFilesystem, error) {
+	mounts, err := os.Open("/proc/mounts")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open /proc/mounts: %w", err)
+	}
+
+	defer mounts.Close()
+
+	filesystems, err := parseGuestMounts(mounts)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read /proc/mounts: %w", err)
+	}
+
+	for i, fs := range filesystems {
+		statfs, err := filesystem.StatVFS(fs.Mountpoint)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to stat %s: %w", fs.Mountpoint, err)
+		}
+
+		filesystems[i].Total = int64(statfs.Blocks) * int64(statfs.Bsize)
+		filesystems[i].Used = int64(statfs.Blocks-statfs.Bfree) * int64(statfs.Bsize)
+	}
+
+	return filesystems, nil
+}
+
+// parseGuestMounts returns the filesystems listed in the mounts content, without their sizes.
+// Pseudo filesystems and system mount points are skipped.
+func parseGuestMounts(mounts io.Reader) ([]api.InstanceGuestFilesystem, error) {
+	filesystems := []api.InstanceGuestFilesystem{}
+	scanner := bufio.NewScanner(mounts)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+
+		// Skip uninteresting mounts
+		if shared.StringInSlice(fields[2], defFSTypesExcluded) || defMountPointsExcluded.MatchString(fields[1]) {
+			continue
+		}
+
+		filesystems = append(filesystems, api.InstanceGuestFilesystem{
+			Mountpoint: unescapeMountinfo(fields[1]),
+			Device:     fields[0],
+			Type:       fields[2],
+		})
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return filesystems, nil
+}
+
+func guestPackages() []api.InstanceGuestPackages {
+	packages := []api.InstanceGuestPackages{}
+
+	counters := []struct {
+		manager string
+		count   func() (int64, error)
+	}{
+		{"dpkg", countDpkgPackages},
+		{"rpm", countRpmPackages},
+		{"apk", countApkPackages},
+		{"pacman", countPacmanPackages},
+		{"snap", countSnapPackages},
+	}
+
+	for _, counter := range counters {
+		count, err := counter.count()
+		if err != nil {
+			logger.Warn("Failed to count installed packages", logger.Ctx{"manager": counter.manager, "err": err})
+			continue
+		}
+
+		if count > 0 {
+			packages = append(packages, api.InstanceGuestPackages{Manager: counter.manager, Count: count})
+		}
+	}
+
+	return packages
+}
+
+// countFileLines returns the number of lines of the file with the prefix, or 0 if it doesn't exist.
+func countFileLines(path string, prefix string) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+
+	defer file.Close()
+
+	count := int64(0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), prefix) {
+			count++
+		}
+	}
+
+	return count, scanner.Err()
+}
+
+func countDpkgPackages() (int64, error) {
+	return countFileLines("/var/lib/dpkg/status", "Status: install ok installed")
+}
+
+func countApkPackages() (int64, error) {
+	return countFileLines("/lib/apk/db/installed", "P:")
+}
+
+func countRpmPackages() (int64, error) {
+	if !shared.PathExists("/var/lib/rpm") {
+		return 0, nil
+	}
+
+	output, err := shared.RunCommand("rpm", "-qa")
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(strings.Fields(output))), nil
+}
+
+func countPacmanPackages() (int64, error) {
+	return countDirs("/var/lib/pacman/local")
+}
+
+func countSnapPackages() (int64, error) {
+	return countDirs("/snap", "bin")
+}
+
+// countDirs returns the number of directories in path other than the excluded ones, or 0 if it doesn't exist.
+func countDirs(path string, excluded ...string) (int64, error) {
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+
+	count := int64(0)
+	for _, entry := range entries {
+		if entry.IsDir() && !shared.StringInSlice(entry.Name(), excluded) {
+			count++
+		}
+	}
+
+	return count, nil
+}
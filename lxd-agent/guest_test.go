@@ -0,0 +1,332 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/lxd/shared/api"
+)
+
+func TestParseProcessStat(t *testing.T) {
+	tests := []struct {
+		name     string
+		stat     string
+		ok       bool
+		comm     string
+		expected api.InstanceGuestProcess
+	}{
+		{
+			name: "init",
+			stat: "1 (systemd) S 0 1 1 0 -1 4194560 43862 2419498 113 1043 250 512 3346 1498 20 0 1 0 27 171634688 3237 18446744073709551615 1 1 0 0 0 0 671173123 4096 1260 0 0 0 17 3 0 0 0 0 0 0 0 0 0 0 0 0 0",
+			ok:   true,
+			comm: "systemd",
+			expected: api.InstanceGuestProcess{
+				PPID:    0,
+				State:   "S",
+				CPUTime: 7620 * int64(time.Millisecond),
+				Memory:  3237 * 4096,
+			},
+		},
+		{
+			name: "command with spaces and parentheses",
+			stat: "4242 (my (odd) cmd) R 1 4242 4242 0 -1 4194304 120 0 0 0 100 50 0 0 20 0 1 0 5000 10000000 256 18446744073709551615 1 1 0 0 0 0 0 0 0 0 0 0 17 0 0 0 0 0 0 0 0 0 0 0 0 0 0",
+			ok:   true,
+			comm: "my (odd) cmd",
+			expected: api.InstanceGuestProcess{
+				PPID:    1,
+				State:   "R",
+				CPUTime: 1500 * int64(time.Millisecond),
+				Memory:  256 * 4096,
+			},
+		},
+		{
+			name: "kernel thread",
+			stat: "2 (kthreadd) S 0 0 0 0 -1 2129984 0 0 0 0 0 3 0 0 20 0 1 0 27 0 0 18446744073709551615 0 0 0 0 0 0 0 2147483647 0 0 0 0 17 1 0 0 0 0 0 0 0 0 0 0 0 0 0",
+			ok:   true,
+			comm: "kthreadd",
+			expected: api.InstanceGuestProcess{
+				PPID:    0,
+				State:   "S",
+				CPUTime: 30 * int64(time.Millisecond),
+			},
+		},
+		{
+			name: "truncated",
+			stat: "1 (systemd) S 0 1 1 0",
+		},
+		{
+			name: "missing command",
+			stat: "1 systemd S 0 1 1 0 -1 4194560 43862 2419498 113 1043 250 512 3346 1498 20 0 1 0 27 171634688 3237",
+		},
+		{
+			name: "empty",
+			stat: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			process, comm, ok := parseProcessStat([]byte(test.stat), 4096)
+			assert.Equal(t, test.ok, ok)
+			if test.ok {
+				assert.Equal(t, test.comm, comm)
+				assert.Equal(t, test.expected, process)
+			}
+		})
+	}
+}
+
+func TestGuestProcesses(t *testing.T) {
+	procPath := t.TempDir()
+
+	files := map[string]string{
+		"1/stat":      "1 (systemd) S 0 1 1 0 -1 4194560 43862 2419498 113 1043 250 512 3346 1498 20 0 1 0 27 171634688 3237 18446744073709551615 1 1 0 0 0 0 671173123 4096 1260 0 0 0 17 3 0 0 0 0 0 0 0 0 0 0 0 0 0",
+		"1/cmdline":   "/sbin/init\x00splash\x00",
+		"2/stat":      "2 (kthreadd) S 0 0 0 0 -1 2129984 0 0 0 0 0 3 0 0 20 0 1 0 27 0 0 18446744073709551615 0 0 0 0 0 0 0 2147483647 0 0 0 0 17 1 0 0 0 0 0 0 0 0 0 0 0 0 0",
+		"2/cmdline":   "",
+		"300/stat":    "300 (bad",
+		"300/cmdline": "bad\x00",
+		"sys/stat":    "",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(procPath, name)
+
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		require.NoError(t, err)
+
+		err = os.WriteFile(path, []byte(content), 0644)
+		require.NoError(t, err)
+	}
+
+	// A process which exited while listing.
+	err := os.Mkdir(filepath.Join(procPath, "400"), 0755)
+	require.NoError(t, err)
+
+	processes, err := guestProcesses(procPath)
+	require.NoError(t, err)
+	require.Len(t, processes, 2)
+
+	assert.Equal(t, int64(1), processes[0].PID)
+	assert.Equal(t, "/sbin/init splash", processes[0].Command)
+	assert.NotEmpty(t, processes[0].User)
+
+	assert.Equal(t, int64(2), processes[1].PID)
+	assert.Equal(t, "[kthreadd]", processes[1].Command)
+
+	_, err = guestProcesses(filepath.Join(procPath, "missing"))
+	assert.Error(t, err)
+}
+
+// utmpRecord returns a utmp record encoded with the given byte order.
+func utmpRecord(order binary.ByteOrder, recordType uint16, line string, name string, host string, loginAt uint32) []byte {
+	record := make([]byte, utmpRecordSize)
+	order.PutUint16(record, recordType)
+	copy(record[utmpLineOffset:utmpLineOffset+utmpLineSize], line)
+	copy(record[utmpUserOffset:utmpUserOffset+utmpUserSize], name)
+	copy(record[utmpHostOffset:utmpHostOffset+utmpHostSize], host)
+	order.PutUint32(record[utmpTimeOffset:], loginAt)
+
+	return record
+}
+
+func TestParseUtmp(t *testing.T) {
+	const (
+		bootTime    = 2
+		loginProc   = 6
+		deadProcess = 8
+	)
+
+	tests := []struct {
+		name     string
+		records  [][]byte
+		expected []api.InstanceGuestUser
+	}{
+		{
+			name:     "empty",
+			expected: []api.InstanceGuestUser{},
+		},
+		{
+			name: "logged-in users",
+			records: [][]byte{
+				utmpRecord(binary.LittleEndian, bootTime, "~", "reboot", "5.15.0-60-generic", 1676000000),
+				utmpRecord(binary.LittleEndian, loginProc, "ttyS0", "LOGIN", "", 1676000010),
+				utmpRecord(binary.LittleEndian, utmpUserProcess, "pts/0", "ubuntu", "10.0.0.1", 1676000100),
+				utmpRecord(binary.LittleEndian, deadProcess, "pts/1", "", "", 1676000200),
+				utmpRecord(binary.LittleEndian, utmpUserProcess, "tty1", "root", "", 1676000300),
+			},
+			expected: []api.InstanceGuestUser{
+				{Name: "ubuntu", Terminal: "pts/0", Host: "10.0.0.1", LoginAt: time.Unix(1676000100, 0).UTC()},
+				{Name: "root", Terminal: "tty1", LoginAt: time.Unix(1676000300, 0).UTC()},
+			},
+		},
+		{
+			name: "big endian",
+			records: [][]byte{
+				utmpRecord(binary.BigEndian, bootTime, "~", "reboot", "5.15.0", 1676000000),
+				utmpRecord(binary.BigEndian, utmpUserProcess, "pts/0", "ubuntu", "", 1676000100),
+			},
+			expected: []api.InstanceGuestUser{
+				{Name: "ubuntu", Terminal: "pts/0", LoginAt: time.Unix(1676000100, 0).UTC()},
+			},
+		},
+		{
+			name: "full length fields",
+			records: [][]byte{
+				utmpRecord(binary.LittleEndian, utmpUserProcess, strings.Repeat("l", utmpLineSize), strings.Repeat("u", utmpUserSize), "", 1676000100),
+			},
+			expected: []api.InstanceGuestUser{
+				{Name: strings.Repeat("u", utmpUserSize), Terminal: strings.Repeat("l", utmpLineSize), LoginAt: time.Unix(1676000100, 0).UTC()},
+			},
+		},
+		{
+			name: "truncated record",
+			records: [][]byte{
+				utmpRecord(binary.LittleEndian, utmpUserProcess, "pts/0", "ubuntu", "", 1676000100),
+				utmpRecord(binary.LittleEndian, utmpUserProcess, "pts/1", "root", "", 1676000200)[:100],
+			},
+			expected: []api.InstanceGuestUser{
+				{Name: "ubuntu", Terminal: "pts/0", LoginAt: time.Unix(1676000100, 0).UTC()},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			users, err := parseUtmp(bytes.NewReader(bytes.Join(test.records, nil)))
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, users)
+		})
+	}
+}
+
+func TestGuestUsers(t *testing.T) {
+	// A missing utmp file means no logged-in users.
+	users, err := guestUsers(filepath.Join(t.TempDir(), "utmp"))
+	require.NoError(t, err)
+	assert.Empty(t, users)
+}
+
+func TestParseGuestMounts(t *testing.T) {
+	mounts := `sysfs /sys sysfs rw,nosuid,nodev,noexec,relatime 0 0
+proc /proc proc rw,nosuid,nodev,noexec,relatime 0 0
+udev /dev devtmpfs rw,nosuid,relatime,size=4020628k,nr_inodes=1005157,mode=755 0 0
+/dev/sda2 / ext4 rw,relatime 0 0
+tmpfs /run tmpfs rw,nosuid,nodev,noexec,relatime,size=807744k,mode=755 0 0
+/dev/sda1 /boot/efi vfat rw,relatime,fmask=0077,dmask=0077 0 0
+/dev/loop0 /snap/core20/1828 squashfs ro,nodev,relatime 0 0
+/dev/sdb1 /mnt/my\040data xfs rw,relatime 0 0
+/dev/sdc1 /var/lib/docker/overlay2 ext4 rw,relatime 0 0
+config /run/lxd_agent virtiofs rw,relatime 0 0
+malformed line
+`
+
+	filesystems, err := parseGuestMounts(strings.NewReader(mounts))
+	require.NoError(t, err)
+
+	assert.Equal(t, []api.InstanceGuestFilesystem{
+		{Mountpoint: "/", Device: "/dev/sda2", Type: "ext4"},
+		{Mountpoint: "/run", Device: "tmpfs", Type: "tmpfs"},
+		{Mountpoint: "/boot/efi", Device: "/dev/sda1", Type: "vfat"},
+		{Mountpoint: "/mnt/my data", Device: "/dev/sdb1", Type: "xfs"},
+		{Mountpoint: "/run/lxd_agent", Device: "config", Type: "virtiofs"},
+	}, filesystems)
+}
+
+func TestCountFileLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		prefix   string
+		expected int64
+	}{
+		{
+			name: "dpkg status",
+			content: `Package: adduser
+Status: install ok installed
+Priority: important
+
+Package: apt
+Status: install ok installed
+Priority: important
+
+Package: old-kernel
+Status: deinstall ok config-files
+Priority: optional
+
+Package: broken
+Status: install reinstreq half-installed
+`,
+			prefix:   "Status: install ok installed",
+			expected: 2,
+		},
+		{
+			name: "apk installed",
+			content: `C:Q1abc=
+P:alpine-baselayout
+V:3.4.0-r0
+
+C:Q1def=
+P:busybox
+V:1.35.0-r29
+`,
+			prefix:   "P:",
+			expected: 2,
+		},
+		{
+			name:     "empty",
+			content:  "",
+			prefix:   "P:",
+			expected: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "db")
+			err := os.WriteFile(path, []byte(test.content), 0644)
+			require.NoError(t, err)
+
+			count, err := countFileLines(path, test.prefix)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, count)
+		})
+	}
+
+	// Missing package databases mean no packages.
+	count, err := countFileLines(filepath.Join(t.TempDir(), "missing"), "P:")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestCountDirs(t *testing.T) {
+	path := t.TempDir()
+
+	for _, name := range []string{"bin", "core20", "lxd"} {
+		err := os.Mkdir(filepath.Join(path, name), 0755)
+		require.NoError(t, err)
+	}
+
+	err := os.WriteFile(filepath.Join(path, "README"), []byte("Not a package"), 0644)
+	require.NoError(t, err)
+
+	count, err := countDirs(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	count, err = countDirs(path, "bin")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	count, err = countDirs(filepath.Join(path, "missing"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/lxd/shared/logger"
+)
+
+// hotplugDevPathRegex matches the sysfs device paths of CPUs and memory blocks.
+var hotplugDevPathRegex = regexp.MustCompile(`^/devices/system/(cpu/cpu|memory/memory)[0-9]+$`)
+
+// parseHotplugUevent returns the sysfs device path of the CPU or memory block added by a kernel uevent.
+// Returns an empty string for any other uevent.
+func parseHotplugUevent(msg []byte) string {
+	fields := strings.Split(string(msg), "\x00")
+	if !strings.HasPrefix(fields[0], "add@") {
+		return ""
+	}
+
+	var devPath, subsystem string
+	for _, field := range fields[1:] {
+		key, value, found := strings.Cut(field, "=")
+		if !found {
+			continue
+		}
+
+		switch key {
+		case "DEVPATH":
+			devPath = value
+		case "SUBSYSTEM":
+			subsystem = value
+		}
+	}
+
+	if subsystem != "cpu" && subsystem != "memory" {
+		return ""
+	}
+
+	if !hotplugDevPathRegex.MatchString(devPath) || !strings.Contains(devPath, "/"+subsystem+"/") {
+		return ""
+	}
+
+	return devPath
+}
+
+// onlineHotplugResource brings online a hot-plugged CPU or memory block, given its device path under the sysfs
+// mount at sysfsPath. Returns whether it was onlined, resources which are already online are left untouched.
+// Memory is onlined as movable so that it can be unplugged again later on.
+func onlineHotplugResource(sysfsPath string, devPath string) (bool, error) {
+	path := filepath.Join(sysfsPath, devPath, "online")
+	offline := "0"
+	online := "1"
+
+	if strings.HasPrefix(devPath, "/devices/system/memory/") {
+		path = filepath.Join(sysfsPath, devPath, "state")
+		offline = "offline"
+		online = "online_movable"
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	if strings.TrimSpace(string(content)) != offline {
+		return false, nil
+	}
+
+	err = os.WriteFile(path, []byte(online), 0)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// startHotplugListener listens for the kernel uevents of hot-plugged CPUs and memory blocks and brings them online.
+// Resources which are present but offline when the agent starts, for example because they were taken offline
+// from within the guest, are left alone.
+func startHotplugListener() error {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_KOBJECT_UEVENT)
+	if err != nil {
+		return fmt.Errorf("Failed creating uevent socket: %w", err)
+	}
+
+	// Only subscribe to kernel uevents.
+	err = unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: 1})
+	if err != nil {
+		unix.Close(fd)
+		return fmt.Errorf("Failed binding uevent socket: %w", err)
+	}
+
+	go func() {
+		defer unix.Close(fd)
+
+		buf := make([]byte, 4096)
+		for {
+			n, err := unix.Read(fd, buf)
+			if err != nil {
+				if err == unix.EINTR || err == unix.ENOBUFS {
+					continue
+				}
+
+				logger.Error("Failed reading uevent", logger.Ctx{"err": err})
+				return
+			}
+
+			devPath := parseHotplugUevent(buf[:n])
+			if devPath == "" {
+				continue
+			}
+
+			onlined, err := onlineHotplugResource("/sys", devPath)
+			if err != nil {
+				logger.Warn("Failed onlining hot-plugged resource", logger.Ctx{"path": devPath, "err": err})
+				continue
+			}
+
+			if onlined {
+				logger.Info("Onlined hot-plugged resource", logger.Ctx{"path": devPath})
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHotplugUevent(t *testing.T) {
+	uevent := func(fields ...string) []byte {
+		return []byte(strings.Join(fields, "\x00") + "\x00")
+	}
+
+	tests := []struct {
+		name     string
+		msg      []byte
+		expected string
+	}{
+		{
+			name:     "cpu added",
+			msg:      uevent("add@/devices/system/cpu/cpu3", "ACTION=add", "DEVPATH=/devices/system/cpu/cpu3", "SUBSYSTEM=cpu", "SEQNUM=1234"),
+			expected: "/devices/system/cpu/cpu3",
+		},
+		{
+			name:     "memory added",
+			msg:      uevent("add@/devices/system/memory/memory40", "ACTION=add", "DEVPATH=/devices/system/memory/memory40", "SUBSYSTEM=memory", "SEQNUM=1235"),
+			expected: "/devices/system/memory/memory40",
+		},
+		{
+			name: "cpu offlined",
+			msg:  uevent("offline@/devices/system/cpu/cpu3", "ACTION=offline", "DEVPATH=/devices/system/cpu/cpu3", "SUBSYSTEM=cpu"),
+		},
+		{
+			name: "cpu removed",
+			msg:  uevent("remove@/devices/system/cpu/cpu3", "ACTION=remove", "DEVPATH=/devices/system/cpu/cpu3", "SUBSYSTEM=cpu"),
+		},
+		{
+			name: "other subsystem",
+			msg:  uevent("add@/devices/virtual/net/eth1", "ACTION=add", "DEVPATH=/devices/virtual/net/eth1", "SUBSYSTEM=net"),
+		},
+		{
+			name: "subsystem mismatch",
+			msg:  uevent("add@/devices/system/memory/memory40", "ACTION=add", "DEVPATH=/devices/system/memory/memory40", "SUBSYSTEM=cpu"),
+		},
+		{
+			name: "unexpected path",
+			msg:  uevent("add@/devices/system/cpu/cpu3/../../../../etc", "ACTION=add", "DEVPATH=/devices/system/cpu/cpu3/../../../../etc", "SUBSYSTEM=cpu"),
+		},
+		{
+			name: "missing devpath",
+			msg:  uevent("add@/devices/system/cpu/cpu3", "ACTION=add", "SUBSYSTEM=cpu"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, parseHotplugUevent(test.msg))
+		})
+	}
+}
+
+func TestOnlineHotplugResource(t *testing.T) {
+	tests := []struct {
+		name     string
+		devPath  string
+		file     string
+		content  string
+		onlined  bool
+		expected string
+	}{
+		{"offline cpu", "/devices/system/cpu/cpu2", "online", "0\n", true, "1"},
+		{"online cpu", "/devices/system/cpu/cpu1", "online", "1\n", false, "1\n"},
+		{"offline memory", "/devices/system/memory/memory40", "state", "offline\n", true, "online_movable"},
+		{"online memory", "/devices/system/memory/memory32", "state", "online\n", false, "online\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sysfsPath := t.TempDir()
+			path := filepath.Join(sysfsPath, test.devPath, test.file)
+
+			err := os.MkdirAll(filepath.Dir(path), 0755)
+			require.NoError(t, err)
+
+			err = os.WriteFile(path, []byte(test.content), 0644)
+			require.NoError(t, err)
+
+			onlined, err := onlineHotplugResource(sysfsPath, test.devPath)
+			require.NoError(t, err)
+			assert.Equal(t, test.onlined, onlined)
+
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(content))
+		})
+	}
+
+	// Missing resources are reported.
+	_, err := onlineHotplugResource(t.TempDir(), "/devices/system/cpu/cpu9")
+	assert.Error(t, err)
+}
@@ -129,6 +129,12 @@ func (c *cmdAgent) Run(cmd *cobra.Command, args []string) error {
 	// Mount shares from host.
 	c.mountHostShares()
 
+	// Online hot-plugged CPUs and memory.
+	err = startHotplugListener()
+	if err != nil {
+		logger.Warn("Failed starting hotplug listener", logger.Ctx{"err": err})
+	}
+
 	// Done with early setup, tell systemd to continue boot.
 	// Allows a service that needs a file that's generated by the agent to be able to declare After=lxd-agent
 	// and know the file will have been created by the time the service is started.
@@ -124,6 +124,18 @@ func metricsServer(d *Daemon) *http.Server {
 	return &http.Server{Handler: &lxdHttpServer{r: mux, d: d}}
 }
 
+func storageBucketsServer(d *Daemon) *http.Server {
+	/* Setup the web server */
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Wait until the daemon is ready before serving requests.
+		<-d.setupChan
+
+		storageBucketsProxy(d, w, r)
+	})
+
+	return &http.Server{Handler: handler}
+}
+
 type lxdHttpServer struct {
 	r *mux.Router
 	d *Daemon
@@ -36,6 +36,8 @@ var api10Cmd = APIEndpoint{
 var api10 = []APIEndpoint{
 	api10Cmd,
 	api10ResourcesCmd,
+	authGroupCmd,
+	authGroupsCmd,
 	certificateCmd,
 	certificatesCmd,
 	clusterCmd,
@@ -51,7 +53,10 @@ var api10 = []APIEndpoint{
 	instanceCmd,
 	instanceConsoleCmd,
 	instanceExecCmd,
+	instanceExecSessionsCmd,
+	instanceExecSessionCmd,
 	instanceFileCmd,
+	instanceGuestCmd,
 	instanceLogCmd,
 	instanceLogsCmd,
 	instanceMetadataCmd,
@@ -78,8 +83,16 @@ var api10 = []APIEndpoint{
 	networkACLLogCmd,
 	networkForwardCmd,
 	networkForwardsCmd,
+	networkLoadBalancerCmd,
+	networkLoadBalancersCmd,
 	networkPeerCmd,
 	networkPeersCmd,
+	networkPeerInvitationsCmd,
+	networkQoSPolicyCmd,
+	networkQoSPoliciesCmd,
+	networkReservationsCmd,
+	networkReservationCmd,
+	networkAllocationsCmd,
 	networkZoneCmd,
 	networkZonesCmd,
 	networkZoneRecordCmd,
@@ -94,6 +107,10 @@ var api10 = []APIEndpoint{
 	projectsCmd,
 	projectStateCmd,
 	storagePoolCmd,
+	storagePoolBucketsCmd,
+	storagePoolBucketCmd,
+	storagePoolBucketKeysCmd,
+	storagePoolBucketKeyCmd,
 	storagePoolResourcesCmd,
 	storagePoolsCmd,
 	storagePoolVolumesCmd,
@@ -204,6 +221,11 @@ func api10Get(d *Daemon, r *http.Request) response.Response {
 			authMethods = append(authMethods, "candid")
 		}
 
+		oidcIssuer, oidcClientID, _ := config.OIDCServer()
+		if oidcIssuer != "" && oidcClientID != "" {
+			authMethods = append(authMethods, "oidc")
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -691,6 +713,9 @@ func doApi10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 
 	maasChanged := false
 	candidChanged := false
+	oidcChanged := false
+	lokiChanged := false
+	syslogChanged := false
 	rbacChanged := false
 	bgpChanged := false
 	dnsChanged := false
@@ -717,6 +742,32 @@ func doApi10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 			fallthrough
 		case "candid.api.url":
 			candidChanged = true
+		case "oidc.issuer":
+			fallthrough
+		case "oidc.client.id":
+			fallthrough
+		case "oidc.audience":
+			oidcChanged = true
+		case "loki.api.url":
+			fallthrough
+		case "loki.auth.username":
+			fallthrough
+		case "loki.auth.password":
+			fallthrough
+		case "loki.api.ca_cert":
+			fallthrough
+		case "loki.labels":
+			fallthrough
+		case "loki.loglevel":
+			fallthrough
+		case "loki.types":
+			lokiChanged = true
+		case "syslog.address":
+			fallthrough
+		case "syslog.loglevel":
+			fallthrough
+		case "syslog.types":
+			syslogChanged = true
 		case "cluster.images_minimal_replica":
 			autoSyncImages(d.shutdownCtx, d)
 		case "cluster.offline_threshold":
@@ -799,6 +850,14 @@ func doApi10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 		}
 	}
 
+	value, ok = nodeChanged["core.storage_buckets_address"]
+	if ok {
+		err := d.endpoints.StorageBucketsUpdateAddress(value, d.endpoints.NetworkCert())
+		if err != nil {
+			return err
+		}
+	}
+
 	value, ok = nodeChanged["storage.backups_volume"]
 	if ok {
 		err := daemonStorageMove(s, "backups", value)
@@ -832,6 +891,30 @@ func doApi10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 		}
 	}
 
+	if oidcChanged {
+		issuer, clientID, audience := clusterConfig.OIDCServer()
+		err := d.setupOIDC(issuer, clientID, audience)
+		if err != nil {
+			return err
+		}
+	}
+
+	if lokiChanged {
+		apiURL, username, password, caCert, labels, logLevel, types := clusterConfig.LokiServer()
+		err := d.setupLoki(apiURL, username, password, caCert, labels, logLevel, types)
+		if err != nil {
+			return err
+		}
+	}
+
+	if syslogChanged {
+		address, logLevel, types := clusterConfig.SyslogServer()
+		err := d.setupSyslog(address, logLevel, types)
+		if err != nil {
+			return err
+		}
+	}
+
 	if rbacChanged {
 		apiURL, apiKey, apiExpiry, agentURL, agentUsername, agentPrivateKey, agentPublicKey := clusterConfig.RBACServer()
 
@@ -21,6 +21,7 @@ import (
 	"github.com/lxc/lxd/lxd/cluster"
 	clusterRequest "github.com/lxc/lxd/lxd/cluster/request"
 	"github.com/lxc/lxd/lxd/db"
+	dbCluster "github.com/lxc/lxd/lxd/db/cluster"
 	"github.com/lxc/lxd/lxd/instance"
 	"github.com/lxc/lxd/lxd/instance/drivers"
 	"github.com/lxc/lxd/lxd/lifecycle"
@@ -30,6 +31,8 @@ import (
 	"github.com/lxc/lxd/lxd/request"
 	"github.com/lxc/lxd/lxd/response"
 	"github.com/lxc/lxd/lxd/revert"
+	storagePools "github.com/lxc/lxd/lxd/storage"
+	"github.com/lxc/lxd/lxd/task"
 	"github.com/lxc/lxd/lxd/util"
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/api"
@@ -3048,6 +3051,297 @@ func restoreClusterMember(d *Daemon, r *http.Request) response.Response {
 	return operations.OperationResponse(op)
 }
 
+// autoHealClusterTask relocates the instances on remote storage away from cluster members that have been
+// offline for longer than cluster.healing_threshold.
+func autoHealClusterTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		// Only the leader heals the cluster, so that instances aren't relocated more than once.
+		localAddress, err := node.ClusterAddress(d.db)
+		if err != nil {
+			logger.Error("Failed to get current cluster member address", logger.Ctx{"err": err})
+			return
+		}
+
+		leader, err := d.gateway.LeaderAddress()
+		if err != nil {
+			if errors.Is(err, cluster.ErrNodeIsNotClustered) {
+				return // No error if not clustered.
+			}
+
+			logger.Error("Failed to get leader cluster member address", logger.Ctx{"err": err})
+			return
+		}
+
+		if localAddress != leader {
+			logger.Debug("Skipping cluster healing task since we're not leader")
+			return
+		}
+
+		var offlineMembers []db.NodeInfo
+		err = d.cluster.Transaction(func(tx *db.ClusterTx) error {
+			config, err := cluster.ConfigLoad(tx)
+			if err != nil {
+				return err
+			}
+
+			if config.HealingThreshold() <= 0 {
+				return nil // Healing is disabled.
+			}
+
+			members, err := tx.GetNodes()
+			if err != nil {
+				return fmt.Errorf("Failed to get cluster members: %w", err)
+			}
+
+			offlineMembers = healingMembers(members, config.HealingThreshold(), config.OfflineThreshold())
+
+			return nil
+		})
+		if err != nil {
+			logger.Error("Failed to get offline cluster members", logger.Ctx{"err": err})
+			return
+		}
+
+		// Only consider the instances which can be started elsewhere, i.e. those on remote storage.
+		memberInstances := make(map[string][]instance.Instance)
+		for _, member := range offlineMembers {
+			instances, err := healingInstances(d, member.Name)
+			if err != nil {
+				logger.Error("Failed to get instances of offline cluster member", logger.Ctx{"member": member.Name, "err": err})
+				continue
+			}
+
+			if len(instances) > 0 {
+				memberInstances[member.Name] = instances
+			}
+		}
+
+		if len(memberInstances) == 0 {
+			return
+		}
+
+		opRun := func(op *operations.Operation) error {
+			for _, member := range offlineMembers {
+				instances, ok := memberInstances[member.Name]
+				if !ok {
+					continue
+				}
+
+				err := healClusterMember(d, op, member, instances)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+
+		op, err := operations.OperationCreate(d.State(), "", operations.OperationClassTask, db.OperationClusterHeal, nil, nil, opRun, nil, nil, nil)
+		if err != nil {
+			logger.Error("Failed to start cluster healing operation", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Healing cluster")
+		chanRun, err := op.Run()
+		if err != nil {
+			logger.Error("Failed to heal cluster", logger.Ctx{"err": err})
+			return
+		}
+
+		// Wait for the operation so that the next run doesn't overlap with this one.
+		err = <-chanRun
+		if err != nil {
+			logger.Error("Failed to heal cluster", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Done healing cluster")
+	}
+
+	return f, task.Every(time.Minute)
+}
+
+// healingMembers returns the cluster members that have been offline for longer than the healing threshold.
+// Members are never healed before they are considered offline, so the offline threshold is used if it is longer.
+// A healing threshold of zero (or less) disables healing.
+func healingMembers(members []db.NodeInfo, healingThreshold time.Duration, offlineThreshold time.Duration) []db.NodeInfo {
+	if healingThreshold <= 0 {
+		return nil
+	}
+
+	if healingThreshold < offlineThreshold {
+		healingThreshold = offlineThreshold
+	}
+
+	var offlineMembers []db.NodeInfo
+	for _, member := range members {
+		if member.State != db.ClusterMemberStatePending && member.IsOffline(healingThreshold) {
+			offlineMembers = append(offlineMembers, member)
+		}
+	}
+
+	return offlineMembers
+}
+
+// healingInstances returns the instances on the given cluster member which are backed by remote storage.
+func healingInstances(d *Daemon, memberName string) ([]instance.Instance, error) {
+	var dbInstances []db.Instance
+	var err error
+	err = d.cluster.Transaction(func(tx *db.ClusterTx) error {
+		dbInstances, err = tx.GetInstances(db.InstanceFilter{Node: &memberName})
+		if err != nil {
+			return fmt.Errorf("Failed to get instances: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	instances := make([]instance.Instance, 0, len(dbInstances))
+	for _, dbInst := range dbInstances {
+		inst, err := instance.LoadByProjectAndName(d.State(), dbInst.Project, dbInst.Name)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load instance: %w", err)
+		}
+
+		pool, err := storagePools.LoadByInstance(d.State(), inst)
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading storage pool of instance %q in project %q: %w", inst.Name(), inst.Project(), err)
+		}
+
+		if !pool.Driver().Info().Remote {
+			continue
+		}
+
+		instances = append(instances, inst)
+	}
+
+	return instances, nil
+}
+
+// healClusterMember relocates the given instances from an offline cluster member to the least loaded
+// healthy members, restarting those which were running.
+// The member is marked as evacuated so that `lxc cluster restore` can move the instances back once it
+// comes back online.
+func healClusterMember(d *Daemon, op *operations.Operation, member db.NodeInfo, instances []instance.Instance) error {
+	logger.Warn("Healing offline cluster member", logger.Ctx{"member": member.Name, "instances": len(instances)})
+
+	if member.State != db.ClusterMemberStateEvacuated {
+		err := evacuateClusterSetState(d, member.Name, db.ClusterMemberStateEvacuated)
+		if err != nil {
+			return err
+		}
+	}
+
+	metadata := make(map[string]any)
+	healed := make([]string, 0, len(instances))
+
+	for _, inst := range instances {
+		targetNodeName, err := healClusterInstance(d, op, metadata, member, inst)
+		if err != nil {
+			logger.Error("Failed healing instance", logger.Ctx{"member": member.Name, "name": inst.Name(), "project": inst.Project(), "err": err})
+
+			err = d.cluster.UpsertWarning(member.Name, inst.Project(), dbCluster.TypeInstance, inst.ID(), db.WarningInstanceHealingFailure, err.Error())
+			if err != nil {
+				logger.Warn("Failed to create warning", logger.Ctx{"err": err})
+			}
+
+			continue
+		}
+
+		err = d.cluster.UpsertWarning(targetNodeName, inst.Project(), dbCluster.TypeInstance, inst.ID(), db.WarningInstanceHealed, fmt.Sprintf("Relocated from offline cluster member %q", member.Name))
+		if err != nil {
+			logger.Warn("Failed to create warning", logger.Ctx{"err": err})
+		}
+
+		d.State().Events.SendLifecycle(inst.Project(), lifecycle.InstanceHealed.Event(inst, logger.Ctx{"source": member.Name, "target": targetNodeName}))
+		healed = append(healed, api.NewURL().Path(version.APIVersion, "instances", inst.Name()).Project(inst.Project()).String())
+	}
+
+	if len(healed) > 0 {
+		d.State().Events.SendLifecycle(project.Default, lifecycle.ClusterMemberHealed.Event(member.Name, op.Requestor(), logger.Ctx{"instances": healed}))
+	}
+
+	return nil
+}
+
+// healClusterInstance moves an instance from an offline cluster member and starts it again if it was
+// running. Returns the name of the cluster member the instance was moved to.
+func healClusterInstance(d *Daemon, op *operations.Operation, metadata map[string]any, member db.NodeInfo, inst instance.Instance) (string, error) {
+	var targetNode db.NodeInfo
+	err := d.cluster.Transaction(func(tx *db.ClusterTx) error {
+		targetNodeName, err := tx.GetNodeWithLeastInstances([]int{inst.Architecture()}, -1, "", nil)
+		if err != nil {
+			return err
+		}
+
+		if targetNodeName == "" {
+			return fmt.Errorf("No healthy cluster member available")
+		}
+
+		targetNode, err = tx.GetNodeByName(targetNodeName)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	metadata["healing_progress"] = fmt.Sprintf("Moving %q in project %q to %q", inst.Name(), inst.Project(), targetNode.Name)
+	op.UpdateMetadata(metadata)
+
+	// Record where the instance came from so it can be restored later on.
+	wasRunning := inst.LocalConfig()["volatile.last_state.power"] == "RUNNING"
+	if inst.LocalConfig()["volatile.evacuate.origin"] == "" {
+		err = inst.VolatileSet(map[string]string{"volatile.evacuate.origin": member.Name})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	req := api.InstancePost{
+		Name: inst.Name(),
+	}
+
+	err = migrateInstance(d, nil, inst, targetNode.Name, true, req, op)
+	if err != nil {
+		return "", fmt.Errorf("Failed to move instance: %w", err)
+	}
+
+	if !wasRunning {
+		return targetNode.Name, nil
+	}
+
+	// Start it back up on target.
+	dest, err := cluster.Connect(targetNode.Address, d.endpoints.NetworkCert(), d.serverCert(), nil, true)
+	if err != nil {
+		return "", fmt.Errorf("Failed to connect to destination: %w", err)
+	}
+
+	dest = dest.UseProject(inst.Project())
+
+	metadata["healing_progress"] = fmt.Sprintf("Starting %q in project %q", inst.Name(), inst.Project())
+	op.UpdateMetadata(metadata)
+
+	startOp, err := dest.UpdateInstanceState(inst.Name(), api.InstanceStatePut{Action: "start"}, "")
+	if err != nil {
+		return "", err
+	}
+
+	err = startOp.Wait()
+	if err != nil {
+		return "", fmt.Errorf("Failed to start instance: %w", err)
+	}
+
+	return targetNode.Name, nil
+}
+
 // swagger:operation POST /1.0/cluster/groups cluster cluster_groups_post
 //
 // Create a cluster group.
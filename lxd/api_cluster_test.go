@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/pem"
 	"fmt"
@@ -11,10 +12,12 @@ import (
 
 	"github.com/lxc/lxd/client"
 	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/operations"
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/api"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
 )
 
 // A LXD node which is already configured for networking can be converted to a
@@ -530,6 +533,118 @@ func TestCluster_RenameNode(t *testing.T) {
 	require.NoError(t, err)
 }
 
+// Only the members which have been offline for longer than both the healing and offline thresholds are healed.
+func TestCluster_HealingMembers(t *testing.T) {
+	now := time.Now().UTC()
+	members := []db.NodeInfo{
+		{Name: "online", Heartbeat: now},
+		{Name: "offline-1m", Heartbeat: now.Add(-time.Minute)},
+		{Name: "offline-10m", Heartbeat: now.Add(-10 * time.Minute)},
+		{Name: "evacuated", Heartbeat: now.Add(-10 * time.Minute), State: db.ClusterMemberStateEvacuated},
+		{Name: "pending", Heartbeat: now.Add(-10 * time.Minute), State: db.ClusterMemberStatePending},
+	}
+
+	tests := []struct {
+		name             string
+		healingThreshold time.Duration
+		offlineThreshold time.Duration
+		expected         []string
+	}{
+		{
+			name:             "disabled",
+			healingThreshold: 0,
+			offlineThreshold: 20 * time.Second,
+			expected:         nil,
+		},
+		{
+			name:             "negative",
+			healingThreshold: -time.Minute,
+			offlineThreshold: 20 * time.Second,
+			expected:         nil,
+		},
+		{
+			name:             "healing threshold",
+			healingThreshold: 5 * time.Minute,
+			offlineThreshold: 20 * time.Second,
+			expected:         []string{"offline-10m", "evacuated"},
+		},
+		{
+			name:             "shorter than offline threshold",
+			healingThreshold: time.Second,
+			offlineThreshold: 20 * time.Second,
+			expected:         []string{"offline-1m", "offline-10m", "evacuated"},
+		},
+		{
+			name:             "longer offline threshold",
+			healingThreshold: 30 * time.Second,
+			offlineThreshold: 5 * time.Minute,
+			expected:         []string{"offline-10m", "evacuated"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var names []string
+			for _, member := range healingMembers(members, test.healingThreshold, test.offlineThreshold) {
+				names = append(names, member.Name)
+			}
+
+			assert.Equal(t, test.expected, names)
+		})
+	}
+}
+
+type clusterHealingTestSuite struct {
+	lxdTestSuite
+}
+
+// The healing task doesn't do anything on standalone servers, even with healing enabled.
+func (suite *clusterHealingTestSuite) TestAutoHealClusterTask_NotClustered() {
+	err := suite.d.cluster.Transaction(func(tx *db.ClusterTx) error {
+		return tx.UpdateClusterConfig(map[string]string{"cluster.healing_threshold": "30"})
+	})
+	suite.Req.Nil(err)
+
+	f, _ := autoHealClusterTask(suite.d)
+	f(context.Background())
+
+	for _, op := range operations.Clone() {
+		suite.NotEqual(db.OperationClusterHeal, op.Type())
+	}
+}
+
+// Healing a member marks it as evacuated, so its instances can be moved back with a restore.
+func (suite *clusterHealingTestSuite) TestHealClusterMember_Evacuates() {
+	getMember := func() db.NodeInfo {
+		var member db.NodeInfo
+		err := suite.d.cluster.Transaction(func(tx *db.ClusterTx) error {
+			name, err := tx.GetLocalNodeName()
+			if err != nil {
+				return err
+			}
+
+			member, err = tx.GetNodeByName(name)
+			return err
+		})
+		suite.Req.Nil(err)
+
+		return member
+	}
+
+	err := healClusterMember(suite.d, nil, getMember(), nil)
+	suite.Req.Nil(err)
+	suite.Equal(db.ClusterMemberStateEvacuated, getMember().State)
+
+	// Healing an already evacuated member again is fine.
+	err = healClusterMember(suite.d, nil, getMember(), nil)
+	suite.Req.Nil(err)
+	suite.Equal(db.ClusterMemberStateEvacuated, getMember().State)
+}
+
+func TestClusterHealingTestSuite(t *testing.T) {
+	suite.Run(t, new(clusterHealingTestSuite))
+}
+
 // Test helper for cluster-related APIs.
 type clusterFixture struct {
 	t       *testing.T
@@ -45,6 +45,7 @@ var apiInternal = []APIEndpoint{
 	internalClusterAcceptCmd,
 	internalClusterAssignCmd,
 	internalClusterHandoverCmd,
+	internalClusterInstanceLiveMigrateCmd,
 	internalClusterInstanceMovedCmd,
 	internalClusterRaftNodeCmd,
 	internalClusterRebalanceCmd,
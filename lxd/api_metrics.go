@@ -1,7 +1,9 @@
 package main
 
 import (
+	"errors"
 	"net/http"
+	"runtime"
 	"sync"
 	"time"
 
@@ -10,6 +12,8 @@ import (
 	"github.com/lxc/lxd/lxd/instance"
 	"github.com/lxc/lxd/lxd/instance/instancetype"
 	"github.com/lxc/lxd/lxd/metrics"
+	"github.com/lxc/lxd/lxd/operations"
+	"github.com/lxc/lxd/lxd/rbac"
 	"github.com/lxc/lxd/lxd/response"
 	"github.com/lxc/lxd/shared/logger"
 )
@@ -49,6 +53,7 @@ func allowMetrics(d *Daemon, r *http.Request) response.Response {
 // Get metrics
 //
 // Gets metrics of instances.
+// When not filtered by project, the metrics of the LXD daemon itself are also included.
 //
 // ---
 // produces:
@@ -110,6 +115,11 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 	// Prepare response.
 	metricSet := metrics.NewMetricSet(nil)
 
+	// Include the daemon metrics when the requestor can access the whole server.
+	if projectName == "" && (rbac.UserIsAdmin(r) || !metricsAuthenticated(d)) {
+		metricSet.Merge(internalMetrics(d))
+	}
+
 	// Review the cache.
 	metricsCacheLock.Lock()
 	projectMissing := []string{}
@@ -217,3 +227,142 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 
 	return response.SyncResponsePlain(true, metricSet.String())
 }
+
+// metricsAuthenticated returns whether authentication is required to access the metrics.
+func metricsAuthenticated(d *Daemon) bool {
+	isAuthenticated, err := cluster.ConfigGetBool(d.cluster, "core.metrics_authentication")
+	if err != nil {
+		return true
+	}
+
+	return isAuthenticated
+}
+
+// internalMetrics returns the metrics of the LXD daemon itself.
+func internalMetrics(d *Daemon) *metrics.MetricSet {
+	out := metrics.NewMetricSet(nil)
+
+	// Go runtime.
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	out.AddSamples(metrics.GoGoroutines, metrics.Sample{Value: float64(runtime.NumGoroutine())})
+	out.AddSamples(metrics.GoAllocBytes, metrics.Sample{Value: float64(ms.Alloc)})
+	out.AddSamples(metrics.GoAllocBytesTotal, metrics.Sample{Value: float64(ms.TotalAlloc)})
+	out.AddSamples(metrics.GoSysBytes, metrics.Sample{Value: float64(ms.Sys)})
+	out.AddSamples(metrics.GoMallocsTotal, metrics.Sample{Value: float64(ms.Mallocs)})
+	out.AddSamples(metrics.GoFreesTotal, metrics.Sample{Value: float64(ms.Frees)})
+	out.AddSamples(metrics.GoHeapAllocBytes, metrics.Sample{Value: float64(ms.HeapAlloc)})
+	out.AddSamples(metrics.GoHeapSysBytes, metrics.Sample{Value: float64(ms.HeapSys)})
+	out.AddSamples(metrics.GoHeapIdleBytes, metrics.Sample{Value: float64(ms.HeapIdle)})
+	out.AddSamples(metrics.GoHeapInuseBytes, metrics.Sample{Value: float64(ms.HeapInuse)})
+	out.AddSamples(metrics.GoHeapReleasedBytes, metrics.Sample{Value: float64(ms.HeapReleased)})
+	out.AddSamples(metrics.GoHeapObjects, metrics.Sample{Value: float64(ms.HeapObjects)})
+	out.AddSamples(metrics.GoStackInuseBytes, metrics.Sample{Value: float64(ms.StackInuse)})
+	out.AddSamples(metrics.GoStackSysBytes, metrics.Sample{Value: float64(ms.StackSys)})
+	out.AddSamples(metrics.GoNextGCBytes, metrics.Sample{Value: float64(ms.NextGC)})
+	out.AddSamples(metrics.GoGCTotal, metrics.Sample{Value: float64(ms.NumGC)})
+	out.AddSamples(metrics.GoGCPauseSecondsTotal, metrics.Sample{Value: time.Duration(ms.PauseTotalNs).Seconds()})
+
+	// Daemon.
+	out.AddSamples(metrics.UptimeSeconds, metrics.Sample{Value: time.Since(d.startTime).Seconds()})
+
+	// Operations, by class and status.
+	type operationKey struct {
+		class  string
+		status string
+	}
+
+	operationCounts := map[operationKey]int{}
+	for _, op := range operations.Clone() {
+		operationCounts[operationKey{class: op.Class().String(), status: op.Status().String()}]++
+	}
+
+	for key, count := range operationCounts {
+		out.AddSamples(metrics.Operations, metrics.Sample{Value: float64(count), Labels: map[string]string{"class": key.class, "status": key.status}})
+	}
+
+	// API requests.
+	d.apiRequests.AddToMetricSet(out)
+
+	// Event listeners.
+	out.AddSamples(metrics.EventListeners,
+		metrics.Sample{Value: float64(d.events.ListenerCount()), Labels: map[string]string{"type": "api"}},
+		metrics.Sample{Value: float64(d.devlxdEvents.ListenerCount()), Labels: map[string]string{"type": "devlxd"}},
+	)
+
+	// Unresolved warnings of this member, by severity and status.
+	type warningKey struct {
+		severity string
+		status   string
+	}
+
+	warningCounts := map[warningKey]int{}
+	err := d.cluster.Transaction(func(tx *db.ClusterTx) error {
+		localName, err := tx.GetLocalNodeName()
+		if err != nil {
+			return err
+		}
+
+		warnings, err := tx.GetWarnings(db.WarningFilter{Node: &localName})
+		if err != nil {
+			return err
+		}
+
+		for _, w := range warnings {
+			if w.Status == db.WarningStatusResolved {
+				continue
+			}
+
+			warningCounts[warningKey{severity: db.WarningSeverities[w.TypeCode.Severity()], status: db.WarningStatuses[w.Status]}]++
+		}
+
+		return nil
+	})
+	if err != nil {
+		logger.Warn("Failed to get warnings for metrics", logger.Ctx{"err": err})
+	}
+
+	for key, count := range warningCounts {
+		out.AddSamples(metrics.Warnings, metrics.Sample{Value: float64(count), Labels: map[string]string{"severity": key.severity, "status": key.status}})
+	}
+
+	// Raft state, when clustered.
+	role, isLeader, err := d.gateway.RaftRole()
+	if err != nil {
+		if !errors.Is(err, cluster.ErrNodeIsNotClustered) {
+			logger.Warn("Failed to get raft state for metrics", logger.Ctx{"err": err})
+		}
+
+		return out
+	}
+
+	leader := 0.0
+	if isLeader {
+		leader = 1.0
+	}
+
+	out.AddSamples(metrics.RaftLeader, metrics.Sample{Value: leader})
+	out.AddSamples(metrics.RaftRole, metrics.Sample{Value: 1, Labels: map[string]string{"role": role.String()}})
+
+	var raftNodes []db.RaftNode
+	err = d.db.Transaction(func(tx *db.NodeTx) error {
+		raftNodes, err = tx.GetRaftNodes()
+		return err
+	})
+	if err != nil {
+		logger.Warn("Failed to get raft nodes for metrics", logger.Ctx{"err": err})
+		return out
+	}
+
+	roleCounts := map[string]int{}
+	for _, node := range raftNodes {
+		roleCounts[node.Role.String()]++
+	}
+
+	for role, count := range roleCounts {
+		out.AddSamples(metrics.RaftNodes, metrics.Sample{Value: float64(count), Labels: map[string]string{"role": role}})
+	}
+
+	return out
+}
@@ -23,6 +23,7 @@ profile "{{ .name }}" flags=(attach_disconnected,mediate_deleted) {
   /dev/kvm                                  rw,
   /dev/net/tun                              rw,
   /dev/ptmx                                 rw,
+  /dev/sev                                  rw,
   /dev/vfio/**                              rw,
   /dev/vhost-net                            rw,
   /dev/vhost-vsock                          rw,
@@ -37,6 +38,7 @@ profile "{{ .name }}" flags=(attach_disconnected,mediate_deleted) {
   /sys/module/vhost/**                      r,
   /{,usr/}bin/qemu*                         mrix,
   {{ .ovmfPath }}/OVMF_CODE.fd              kr,
+  {{ .ovmfPath }}/OVMF.inteltdx.fd          kr,
   /usr/share/qemu/**                        kr,
   /usr/share/seabios/**                     kr,
   owner @{PROC}/@{pid}/task/@{tid}/comm     rw,
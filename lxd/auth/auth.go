@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/lxc/lxd/lxd/rbac"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+)
+
+// Entitlements that can be granted by authorization groups.
+const (
+	EntitlementCanView            = "can_view"
+	EntitlementCanEdit            = "can_edit"
+	EntitlementCanExec            = "can_exec"
+	EntitlementCanManageSnapshots = "can_manage_snapshots"
+)
+
+// Types of entities that permissions can be granted on.
+const (
+	EntityTypeProject     = "project"
+	EntityTypeInstance    = "instance"
+	EntityTypeNetwork     = "network"
+	EntityTypeStoragePool = "storage_pool"
+)
+
+// entitlements maps each entity type and entitlement to the API permissions it grants.
+var entitlements = map[string]map[string][]string{
+	EntityTypeProject: {
+		EntitlementCanView:            {"view"},
+		EntitlementCanEdit:            {"view", "manage-containers", "manage-images", "manage-networks", "manage-profiles", "manage-storage-volumes"},
+		EntitlementCanExec:            {"view", "operate-containers"},
+		EntitlementCanManageSnapshots: {"view", "manage-snapshots"},
+	},
+	EntityTypeInstance: {
+		EntitlementCanView:            {"view"},
+		EntitlementCanEdit:            {"view", "manage-containers"},
+		EntitlementCanExec:            {"view", "operate-containers"},
+		EntitlementCanManageSnapshots: {"view", "manage-snapshots"},
+	},
+	EntityTypeNetwork: {
+		EntitlementCanView: {"view"},
+		EntitlementCanEdit: {"view", "manage-networks"},
+	},
+	EntityTypeStoragePool: {
+		EntitlementCanView: {"view"},
+		EntitlementCanEdit: {"view", "manage-storage-pools"},
+	},
+}
+
+// ValidatePermission checks that the permission refers to a known entity type and a suitable entitlement.
+// It doesn't check whether the entity exists.
+func ValidatePermission(permission api.AuthPermission) error {
+	grants, ok := entitlements[permission.EntityType]
+	if !ok {
+		return fmt.Errorf("Unknown entity type %q", permission.EntityType)
+	}
+
+	_, ok = grants[permission.Entitlement]
+	if !ok {
+		return fmt.Errorf("Entitlement %q is not valid for entity type %q", permission.Entitlement, permission.EntityType)
+	}
+
+	switch permission.EntityType {
+	case EntityTypeProject:
+		if permission.Project == "" {
+			return fmt.Errorf("Project permissions require a project")
+		}
+
+		if permission.Name != "" {
+			return fmt.Errorf("Project permissions must not have a name")
+		}
+
+	case EntityTypeInstance, EntityTypeNetwork:
+		if permission.Project == "" || permission.Name == "" {
+			return fmt.Errorf("Permissions on entity type %q require a project and a name", permission.EntityType)
+		}
+
+	case EntityTypeStoragePool:
+		if permission.Name == "" {
+			return fmt.Errorf("Storage pool permissions require a name")
+		}
+
+		if permission.Project != "" {
+			return fmt.Errorf("Storage pool permissions must not have a project")
+		}
+	}
+
+	return nil
+}
+
+// ApplyPermissions adds the API permissions granted by the given group permissions to the user access.
+// Invalid permissions are ignored.
+func ApplyPermissions(ua *rbac.UserAccess, permissions []api.AuthPermission) {
+	for _, permission := range permissions {
+		if ValidatePermission(permission) != nil {
+			continue
+		}
+
+		grants := entitlements[permission.EntityType][permission.Entitlement]
+
+		switch permission.EntityType {
+		case EntityTypeProject:
+			if ua.Projects == nil {
+				ua.Projects = map[string][]string{}
+			}
+
+			ua.Projects[permission.Project] = addPermissions(ua.Projects[permission.Project], grants)
+
+		case EntityTypeInstance:
+			if ua.Instances == nil {
+				ua.Instances = map[string]map[string][]string{}
+			}
+
+			if ua.Instances[permission.Project] == nil {
+				ua.Instances[permission.Project] = map[string][]string{}
+			}
+
+			ua.Instances[permission.Project][permission.Name] = addPermissions(ua.Instances[permission.Project][permission.Name], grants)
+
+		case EntityTypeNetwork:
+			if ua.Networks == nil {
+				ua.Networks = map[string]map[string][]string{}
+			}
+
+			if ua.Networks[permission.Project] == nil {
+				ua.Networks[permission.Project] = map[string][]string{}
+			}
+
+			ua.Networks[permission.Project][permission.Name] = addPermissions(ua.Networks[permission.Project][permission.Name], grants)
+
+		case EntityTypeStoragePool:
+			if ua.StoragePools == nil {
+				ua.StoragePools = map[string][]string{}
+			}
+
+			ua.StoragePools[permission.Name] = addPermissions(ua.StoragePools[permission.Name], grants)
+		}
+	}
+}
+
+// addPermissions returns the existing permissions with any missing new permissions appended.
+func addPermissions(existing []string, permissions []string) []string {
+	for _, permission := range permissions {
+		if !shared.StringInSlice(permission, existing) {
+			existing = append(existing, permission)
+		}
+	}
+
+	return existing
+}
@@ -0,0 +1,58 @@
+package auth_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/lxd/lxd/auth"
+	"github.com/lxc/lxd/lxd/rbac"
+	"github.com/lxc/lxd/shared/api"
+)
+
+func TestValidatePermission(t *testing.T) {
+	valid := []api.AuthPermission{
+		{EntityType: "project", Project: "default", Entitlement: "can_view"},
+		{EntityType: "instance", Project: "default", Name: "c1", Entitlement: "can_exec"},
+		{EntityType: "instance", Project: "default", Name: "c1", Entitlement: "can_manage_snapshots"},
+		{EntityType: "network", Project: "default", Name: "lxdbr0", Entitlement: "can_edit"},
+		{EntityType: "storage_pool", Name: "default", Entitlement: "can_edit"},
+	}
+
+	for _, permission := range valid {
+		assert.NoError(t, auth.ValidatePermission(permission), permission)
+	}
+
+	invalid := []api.AuthPermission{
+		{EntityType: "image", Project: "default", Name: "foo", Entitlement: "can_view"},
+		{EntityType: "project", Project: "default", Entitlement: "can_fly"},
+		{EntityType: "project", Entitlement: "can_view"},
+		{EntityType: "project", Project: "default", Name: "c1", Entitlement: "can_view"},
+		{EntityType: "instance", Project: "default", Entitlement: "can_view"},
+		{EntityType: "network", Project: "default", Name: "lxdbr0", Entitlement: "can_exec"},
+		{EntityType: "storage_pool", Project: "default", Name: "default", Entitlement: "can_view"},
+	}
+
+	for _, permission := range invalid {
+		assert.Error(t, auth.ValidatePermission(permission), permission)
+	}
+}
+
+func TestApplyPermissions(t *testing.T) {
+	ua := &rbac.UserAccess{}
+
+	auth.ApplyPermissions(ua, []api.AuthPermission{
+		{EntityType: "project", Project: "p1", Entitlement: "can_view"},
+		{EntityType: "project", Project: "p1", Entitlement: "can_exec"},
+		{EntityType: "instance", Project: "default", Name: "c1", Entitlement: "can_manage_snapshots"},
+		{EntityType: "network", Project: "default", Name: "lxdbr0", Entitlement: "can_view"},
+		{EntityType: "storage_pool", Name: "default", Entitlement: "can_edit"},
+		{EntityType: "storage_pool", Name: "other", Entitlement: "can_exec"},
+	})
+
+	assert.False(t, ua.Admin)
+	assert.Equal(t, map[string][]string{"p1": {"view", "operate-containers"}}, ua.Projects)
+	assert.Equal(t, []string{"view", "manage-snapshots"}, ua.Instances["default"]["c1"])
+	assert.Equal(t, []string{"view"}, ua.Networks["default"]["lxdbr0"])
+	assert.Equal(t, map[string][]string{"default": {"view", "manage-storage-pools"}}, ua.StoragePools)
+}
@@ -0,0 +1,621 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	lxd "github.com/lxc/lxd/client"
+	"github.com/lxc/lxd/lxd/auth"
+	"github.com/lxc/lxd/lxd/cluster"
+	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/lifecycle"
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/lxd/request"
+	"github.com/lxc/lxd/lxd/response"
+	"github.com/lxc/lxd/lxd/util"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/logger"
+	"github.com/lxc/lxd/shared/version"
+)
+
+var authGroupsCmd = APIEndpoint{
+	Path: "auth/groups",
+
+	Get:  APIEndpointAction{Handler: authGroupsGet},
+	Post: APIEndpointAction{Handler: authGroupsPost},
+}
+
+var authGroupCmd = APIEndpoint{
+	Path: "auth/groups/{name}",
+
+	Delete: APIEndpointAction{Handler: authGroupDelete},
+	Get:    APIEndpointAction{Handler: authGroupGet},
+	Put:    APIEndpointAction{Handler: authGroupPut},
+	Patch:  APIEndpointAction{Handler: authGroupPut},
+	Post:   APIEndpointAction{Handler: authGroupPost},
+}
+
+// authGroupValidateName checks the name of an authorization group.
+func authGroupValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("Name is required")
+	}
+
+	if strings.Contains(name, "/") {
+		return fmt.Errorf("Name cannot contain '/'")
+	}
+
+	return nil
+}
+
+// authGroupValidate checks the permissions and identities of an authorization group.
+func authGroupValidate(d *Daemon, req *api.AuthGroupPut) error {
+	projects := map[string]bool{}
+
+	for i, permission := range req.Permissions {
+		err := auth.ValidatePermission(permission)
+		if err != nil {
+			return fmt.Errorf("Invalid permission %d: %w", i, err)
+		}
+
+		if permission.Project != "" {
+			projects[permission.Project] = true
+		}
+	}
+
+	// Check that the referenced projects exist.
+	if len(projects) > 0 {
+		err := d.cluster.Transaction(func(tx *db.ClusterTx) error {
+			for projectName := range projects {
+				_, err := tx.GetProject(projectName)
+				if err != nil {
+					return fmt.Errorf("Failed loading project %q: %w", projectName, err)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, fingerprint := range req.Identities {
+		if fingerprint == "" {
+			return fmt.Errorf("Identities cannot be empty")
+		}
+	}
+
+	return nil
+}
+
+// API endpoints.
+
+// swagger:operation GET /1.0/auth/groups auth auth_groups_get
+//
+// Get the authorization groups
+//
+// Returns a list of authorization groups (URLs).
+//
+// ---
+// produces:
+//   - application/json
+// responses:
+//   "200":
+//     description: API endpoints
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           type: array
+//           description: List of endpoints
+//           items:
+//             type: string
+//           example: |-
+//             [
+//               "/1.0/auth/groups/viewers",
+//               "/1.0/auth/groups/operators"
+//             ]
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/auth/groups?recursion=1 auth auth_groups_get_recursion1
+//
+// Get the authorization groups
+//
+// Returns a list of authorization groups (structs).
+//
+// ---
+// produces:
+//   - application/json
+// responses:
+//   "200":
+//     description: API endpoints
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           type: array
+//           description: List of authorization groups
+//           items:
+//             $ref: "#/definitions/AuthGroup"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func authGroupsGet(d *Daemon, r *http.Request) response.Response {
+	recursion := util.IsRecursionRequest(r)
+
+	groupNames, err := d.cluster.GetAuthGroups()
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	resultString := []string{}
+	resultMap := []api.AuthGroup{}
+	for _, groupName := range groupNames {
+		if !recursion {
+			resultString = append(resultString, api.NewURL().Path(version.APIVersion, "auth", "groups", groupName).String())
+		} else {
+			_, group, err := d.cluster.GetAuthGroup(groupName)
+			if err != nil {
+				continue
+			}
+
+			resultMap = append(resultMap, *group)
+		}
+	}
+
+	if !recursion {
+		return response.SyncResponse(true, resultString)
+	}
+
+	return response.SyncResponse(true, resultMap)
+}
+
+// swagger:operation POST /1.0/auth/groups auth auth_groups_post
+//
+// Add an authorization group
+//
+// Creates a new authorization group.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: body
+//     name: group
+//     description: Group
+//     required: true
+//     schema:
+//       $ref: "#/definitions/AuthGroupsPost"
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func authGroupsPost(d *Daemon, r *http.Request) response.Response {
+	req := api.AuthGroupsPost{}
+
+	// Parse the request into a record.
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if !isClusterNotification(r) {
+		err = authGroupValidateName(req.Name)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+
+		err = authGroupValidate(d, &req.AuthGroupPut)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+
+		_, _, err = d.cluster.GetAuthGroup(req.Name)
+		if err == nil {
+			return response.BadRequest(fmt.Errorf("The authorization group already exists"))
+		}
+
+		_, err = d.cluster.CreateAuthGroup(&req)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		// Notify other nodes so they refresh their permissions.
+		notifier, err := cluster.NewNotifier(d.State(), d.endpoints.NetworkCert(), d.serverCert(), cluster.NotifyAlive)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		err = notifier(func(client lxd.InstanceServer) error {
+			return client.CreateAuthGroup(req)
+		})
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	// Reload the cache.
+	updateCertificateCache(d)
+
+	d.State().Events.SendLifecycle(project.Default, lifecycle.AuthGroupCreated.Event(req.Name, request.CreateRequestor(r), nil))
+
+	return response.SyncResponseLocation(true, nil, api.NewURL().Path(version.APIVersion, "auth", "groups", req.Name).String())
+}
+
+// swagger:operation DELETE /1.0/auth/groups/{name} auth auth_group_delete
+//
+// Delete the authorization group
+//
+// Removes the authorization group. Its members lose the permissions it granted.
+//
+// ---
+// produces:
+//   - application/json
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func authGroupDelete(d *Daemon, r *http.Request) response.Response {
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if !isClusterNotification(r) {
+		id, _, err := d.cluster.GetAuthGroup(name)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		err = d.cluster.DeleteAuthGroup(id)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		// Notify other nodes so they refresh their permissions.
+		notifier, err := cluster.NewNotifier(d.State(), d.endpoints.NetworkCert(), d.serverCert(), cluster.NotifyAlive)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		err = notifier(func(client lxd.InstanceServer) error {
+			return client.DeleteAuthGroup(name)
+		})
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	// Reload the cache.
+	updateCertificateCache(d)
+
+	d.State().Events.SendLifecycle(project.Default, lifecycle.AuthGroupDeleted.Event(name, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/auth/groups/{name} auth auth_group_get
+//
+// Get the authorization group
+//
+// Gets a specific authorization group.
+//
+// ---
+// produces:
+//   - application/json
+// responses:
+//   "200":
+//     description: Authorization group
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           $ref: "#/definitions/AuthGroup"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "404":
+//     $ref: "#/responses/NotFound"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func authGroupGet(d *Daemon, r *http.Request) response.Response {
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	_, group, err := d.cluster.GetAuthGroup(name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, group, group.Writable())
+}
+
+// swagger:operation PATCH /1.0/auth/groups/{name} auth auth_group_patch
+//
+// Partially update the authorization group
+//
+// Updates a subset of the authorization group configuration.
+// Permissions and identities present in the request are added to the existing ones.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: body
+//     name: group
+//     description: Group configuration
+//     required: true
+//     schema:
+//       $ref: "#/definitions/AuthGroupPut"
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "412":
+//     $ref: "#/responses/PreconditionFailed"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/auth/groups/{name} auth auth_group_put
+//
+// Update the authorization group
+//
+// Updates the entire authorization group configuration.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: body
+//     name: group
+//     description: Group configuration
+//     required: true
+//     schema:
+//       $ref: "#/definitions/AuthGroupPut"
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "412":
+//     $ref: "#/responses/PreconditionFailed"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func authGroupPut(d *Daemon, r *http.Request) response.Response {
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.AuthGroupPut{}
+
+	// Decode the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if !isClusterNotification(r) {
+		// Get the existing authorization group.
+		id, group, err := d.cluster.GetAuthGroup(name)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		// Validate the ETag.
+		err = util.EtagCheck(r, group.Writable())
+		if err != nil {
+			return response.PreconditionFailed(err)
+		}
+
+		if r.Method == http.MethodPatch {
+			// If the group is being updated via "patch" method, then keep the existing description unless
+			// a new one is provided and add the new permissions and identities to the existing ones.
+			if req.Description == "" {
+				req.Description = group.Description
+			}
+
+			for _, permission := range group.Permissions {
+				if !authPermissionInSlice(permission, req.Permissions) {
+					req.Permissions = append(req.Permissions, permission)
+				}
+			}
+
+			for _, fingerprint := range group.Identities {
+				if !authIdentityInSlice(fingerprint, req.Identities) {
+					req.Identities = append(req.Identities, fingerprint)
+				}
+			}
+		}
+
+		err = authGroupValidate(d, &req)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+
+		err = d.cluster.UpdateAuthGroup(id, &req)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		// Notify other nodes so they refresh their permissions.
+		notifier, err := cluster.NewNotifier(d.State(), d.endpoints.NetworkCert(), d.serverCert(), cluster.NotifyAlive)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		err = notifier(func(client lxd.InstanceServer) error {
+			return client.UpdateAuthGroup(name, req, "")
+		})
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	// Reload the cache.
+	updateCertificateCache(d)
+
+	d.State().Events.SendLifecycle(project.Default, lifecycle.AuthGroupUpdated.Event(name, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// authPermissionInSlice returns whether the permission is in the list.
+func authPermissionInSlice(permission api.AuthPermission, permissions []api.AuthPermission) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+// authIdentityInSlice returns whether the certificate fingerprint is in the list.
+func authIdentityInSlice(fingerprint string, fingerprints []string) bool {
+	for _, f := range fingerprints {
+		if f == fingerprint {
+			return true
+		}
+	}
+
+	return false
+}
+
+// swagger:operation POST /1.0/auth/groups/{name} auth auth_group_post
+//
+// Rename the authorization group
+//
+// Renames an existing authorization group.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: body
+//     name: group
+//     description: Group rename request
+//     required: true
+//     schema:
+//       $ref: "#/definitions/AuthGroupPost"
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func authGroupPost(d *Daemon, r *http.Request) response.Response {
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.AuthGroupPost{}
+
+	// Parse the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = authGroupValidateName(req.Name)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Get the existing authorization group.
+	id, _, err := d.cluster.GetAuthGroup(name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	_, _, err = d.cluster.GetAuthGroup(req.Name)
+	if err == nil {
+		return response.Conflict(fmt.Errorf("An authorization group with name %q already exists", req.Name))
+	}
+
+	err = d.cluster.RenameAuthGroup(id, req.Name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	d.State().Events.SendLifecycle(project.Default, lifecycle.AuthGroupRenamed.Event(req.Name, request.CreateRequestor(r), logger.Ctx{"old_name": name}))
+
+	return response.SyncResponseLocation(true, nil, api.NewURL().Path(version.APIVersion, "auth", "groups", req.Name).String())
+}
@@ -9,6 +9,7 @@ import (
 
 	"context"
 
+	"github.com/pborman/uuid"
 	"gopkg.in/yaml.v2"
 
 	"github.com/lxc/lxd/lxd/backup"
@@ -33,6 +34,10 @@ import (
 	"github.com/lxc/lxd/shared/units"
 )
 
+// backupFreezeTimeout is the time after which the guest filesystems frozen while capturing the root disk of a
+// running VM for a backup are thawed by the lxd-agent, in case LXD fails to thaw them.
+const backupFreezeTimeout = time.Minute
+
 // Create a new backup.
 func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.Instance, op *operations.Operation) error {
 	l := logger.AddContext(logger.Log, logger.Ctx{"project": sourceInst.Project(), "instance": sourceInst.Name(), "name": args.Name})
@@ -53,6 +58,30 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 		args.OptimizedStorage = false
 	}
 
+	// Backups of running VMs are checkpoints that the next incremental backup can be based on.
+	var vm instance.VM
+	var checkpoint string
+	if sourceInst.Type() == instancetype.VM && sourceInst.IsRunning() {
+		vm = sourceInst.(instance.VM)
+		checkpoint = uuid.New()
+	}
+
+	lastCheckpoint := sourceInst.LocalConfig()["volatile.backup.checkpoint"]
+
+	if args.IncrementalFrom != "" {
+		if vm == nil {
+			return fmt.Errorf("Incremental backups are only supported for running virtual machines")
+		}
+
+		if args.IncrementalFrom != lastCheckpoint {
+			return fmt.Errorf("Incremental backups must be based on the latest backup checkpoint %q", lastCheckpoint)
+		}
+
+		// Only the root disk changes are included.
+		args.InstanceOnly = true
+		args.OptimizedStorage = false
+	}
+
 	// Create the database entry.
 	err = s.Cluster.CreateInstanceBackup(args)
 	if err != nil {
@@ -169,7 +198,7 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 
 	// Write index file.
 	l.Debug("Adding backup index file")
-	err = backupWriteIndex(sourceInst, pool, b.OptimizedStorage(), !b.InstanceOnly(), tarWriter)
+	err = backupWriteIndex(sourceInst, pool, b.OptimizedStorage(), !b.InstanceOnly(), checkpoint, args.IncrementalFrom, tarWriter)
 
 	// Check compression errors.
 	if compressErr != nil {
@@ -181,9 +210,85 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 		return fmt.Errorf("Error writing backup index file: %w", err)
 	}
 
-	err = pool.BackupInstance(sourceInst, tarWriter, b.OptimizedStorage(), !b.InstanceOnly(), nil)
-	if err != nil {
-		return fmt.Errorf("Backup create: %w", err)
+	if args.IncrementalFrom != "" {
+		progress := func(done int64, total int64) {
+			meta := op.Metadata()
+			if meta == nil {
+				meta = make(map[string]any)
+			}
+
+			meta["create_backup_progress"] = fmt.Sprintf("Copying changed blocks: %s/%s", units.GetByteSizeString(done, 2), units.GetByteSizeString(total, 2))
+			op.UpdateMetadata(meta)
+		}
+
+		err = vm.BackupDelta(args.IncrementalFrom, checkpoint, tarWriter, backup.DeltaFileName, progress)
+		if err != nil {
+			return fmt.Errorf("Backup create: %w", err)
+		}
+
+		revert.Add(func() { vm.BackupCheckpointRemove(checkpoint) })
+	} else {
+		var release func() error
+		if vm != nil {
+			// Capture the root disk of the running VM while its filesystems are frozen, so that the backup is
+			// application consistent. The filesystems are thawed before copying the captured disk.
+			consistent := shared.IsTrue(sourceInst.ExpandedConfig()["snapshots.consistent"])
+
+			var thaw func() error
+			if consistent {
+				thaw, err = vm.FreezeFilesystems(backupFreezeTimeout)
+				if err != nil {
+					return fmt.Errorf("Failed freezing guest filesystems: %w", err)
+				}
+
+				defer func() {
+					if thaw != nil {
+						thaw()
+					}
+				}()
+			}
+
+			// Failing to track the writes only prevents incremental backups based on this one.
+			err = vm.BackupCheckpointAdd(checkpoint)
+			if err != nil {
+				l.Warn("Failed adding backup checkpoint", logger.Ctx{"err": err})
+				checkpoint = ""
+			} else {
+				revert.Add(func() { vm.BackupCheckpointRemove(checkpoint) })
+			}
+
+			if consistent {
+				release, err = vm.BackupCapture()
+				if err != nil {
+					return fmt.Errorf("Failed capturing root disk: %w", err)
+				}
+
+				defer func() {
+					if release != nil {
+						release()
+					}
+				}()
+
+				err = thaw()
+				thaw = nil
+				if err != nil {
+					return fmt.Errorf("Failed thawing guest filesystems: %w", err)
+				}
+			}
+		}
+
+		err = pool.BackupInstance(sourceInst, tarWriter, b.OptimizedStorage(), !b.InstanceOnly(), nil)
+		if err != nil {
+			return fmt.Errorf("Backup create: %w", err)
+		}
+
+		if release != nil {
+			err = release()
+			release = nil
+			if err != nil {
+				return fmt.Errorf("Failed merging root disk writes made during backup: %w", err)
+			}
+		}
 	}
 
 	// Close off the tarball file.
@@ -203,6 +308,21 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 		return fmt.Errorf("Error writing tarball: %w", err)
 	}
 
+	// Make the backup the base of the next incremental backup.
+	if checkpoint != "" {
+		err = sourceInst.VolatileSet(map[string]string{"volatile.backup.checkpoint": checkpoint})
+		if err != nil {
+			return fmt.Errorf("Failed recording backup checkpoint: %w", err)
+		}
+
+		if lastCheckpoint != "" {
+			err = vm.BackupCheckpointRemove(lastCheckpoint)
+			if err != nil {
+				l.Warn("Failed removing previous backup checkpoint", logger.Ctx{"checkpoint": lastCheckpoint, "err": err})
+			}
+		}
+	}
+
 	revert.Success()
 	s.Events.SendLifecycle(sourceInst.Project(), lifecycle.InstanceBackupCreated.Event(args.Name, b.Instance(), nil))
 
@@ -210,7 +330,7 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 }
 
 // backupWriteIndex generates an index.yaml file and then writes it to the root of the backup tarball.
-func backupWriteIndex(sourceInst instance.Instance, pool storagePools.Pool, optimized bool, snapshots bool, tarWriter *instancewriter.InstanceTarWriter) error {
+func backupWriteIndex(sourceInst instance.Instance, pool storagePools.Pool, optimized bool, snapshots bool, checkpoint string, incrementalFrom string, tarWriter *instancewriter.InstanceTarWriter) error {
 	// Indicate whether the driver will include a driver-specific optimized header.
 	poolDriverOptimizedHeader := false
 	if optimized {
@@ -230,6 +350,8 @@ func backupWriteIndex(sourceInst instance.Instance, pool storagePools.Pool, opti
 		Type:             backupType,
 		OptimizedStorage: &optimized,
 		OptimizedHeader:  &poolDriverOptimizedHeader,
+		Checkpoint:       checkpoint,
+		IncrementalFrom:  incrementalFrom,
 	}
 
 	if snapshots {
@@ -0,0 +1,182 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// DeltaMagic is the magic string starting an incremental block delta.
+const DeltaMagic = "LXDDELTA"
+
+// DeltaVersion is the version of the incremental block delta format.
+const DeltaVersion = 1
+
+// DeltaFileName is the path of the root disk delta in incremental virtual machine backups.
+const DeltaFileName = "backup/virtual-machine.delta"
+
+// Incremental virtual machine backups store the blocks of the root disk changed since the base backup in a single
+// file, in the following format (all integers being big-endian):
+//
+//   - Header: the DeltaMagic string, the uint32 format version and the uint64 size of the disk.
+//   - Extents: for each changed area of the disk, its uint64 offset, its uint64 length and then its content.
+//   - Trailer: an extent of length 0, with offset 0 and no content.
+//
+// Extents are sorted by offset and don't overlap. Applying a delta resizes the base disk to the recorded size and
+// then writes each extent at its offset.
+
+// deltaHeaderSize is the size of the delta header.
+const deltaHeaderSize = len(DeltaMagic) + 4 + 8
+
+// deltaExtentHeaderSize is the size of the header of each extent.
+const deltaExtentHeaderSize = 8 + 8
+
+// Extent represents an area of a disk.
+type Extent struct {
+	Offset int64
+	Length int64
+}
+
+// DeltaSize returns the size of the delta containing the extents.
+func DeltaSize(extents []Extent) int64 {
+	size := int64(deltaHeaderSize + deltaExtentHeaderSize)
+	for _, extent := range extents {
+		size += deltaExtentHeaderSize + extent.Length
+	}
+
+	return size
+}
+
+// WriteDelta writes a delta of a disk of diskSize bytes to w, containing the extents read from src.
+func WriteDelta(w io.Writer, src io.ReaderAt, diskSize int64, extents []Extent) error {
+	header := make([]byte, deltaHeaderSize)
+	copy(header, DeltaMagic)
+	binary.BigEndian.PutUint32(header[len(DeltaMagic):], DeltaVersion)
+	binary.BigEndian.PutUint64(header[len(DeltaMagic)+4:], uint64(diskSize))
+
+	_, err := w.Write(header)
+	if err != nil {
+		return err
+	}
+
+	var end int64
+	for _, extent := range extents {
+		if extent.Length <= 0 || extent.Offset < end || extent.Offset+extent.Length > diskSize {
+			return fmt.Errorf("Invalid extent at offset %d of length %d", extent.Offset, extent.Length)
+		}
+
+		err = writeDeltaExtentHeader(w, extent)
+		if err != nil {
+			return err
+		}
+
+		n, err := io.Copy(w, io.NewSectionReader(src, extent.Offset, extent.Length))
+		if err != nil {
+			return fmt.Errorf("Failed writing extent at offset %d: %w", extent.Offset, err)
+		}
+
+		if n != extent.Length {
+			return fmt.Errorf("Failed writing extent at offset %d: %w", extent.Offset, io.ErrUnexpectedEOF)
+		}
+
+		end = extent.Offset + extent.Length
+	}
+
+	return writeDeltaExtentHeader(w, Extent{})
+}
+
+// writeDeltaExtentHeader writes the header of an extent.
+func writeDeltaExtentHeader(w io.Writer, extent Extent) error {
+	header := make([]byte, deltaExtentHeaderSize)
+	binary.BigEndian.PutUint64(header, uint64(extent.Offset))
+	binary.BigEndian.PutUint64(header[8:], uint64(extent.Length))
+
+	_, err := w.Write(header)
+	return err
+}
+
+// DeltaReader reads an incremental block delta.
+type DeltaReader struct {
+	r        io.Reader
+	diskSize int64
+}
+
+// NewDeltaReader reads the header of the delta from r.
+func NewDeltaReader(r io.Reader) (*DeltaReader, error) {
+	header := make([]byte, deltaHeaderSize)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading delta header: %w", err)
+	}
+
+	if !bytes.Equal(header[:len(DeltaMagic)], []byte(DeltaMagic)) {
+		return nil, fmt.Errorf("Invalid delta header")
+	}
+
+	version := binary.BigEndian.Uint32(header[len(DeltaMagic):])
+	if version != DeltaVersion {
+		return nil, fmt.Errorf("Unsupported delta version %d", version)
+	}
+
+	diskSize := int64(binary.BigEndian.Uint64(header[len(DeltaMagic)+4:]))
+	if diskSize < 0 {
+		return nil, fmt.Errorf("Invalid delta disk size")
+	}
+
+	return &DeltaReader{r: r, diskSize: diskSize}, nil
+}
+
+// DiskSize returns the size of the disk the delta applies to.
+func (dr *DeltaReader) DiskSize() int64 {
+	return dr.diskSize
+}
+
+// Apply writes the extents of the delta to target, which must already be of the disk size.
+func (dr *DeltaReader) Apply(target io.WriterAt) error {
+	header := make([]byte, deltaExtentHeaderSize)
+	var end int64
+
+	for {
+		_, err := io.ReadFull(dr.r, header)
+		if err != nil {
+			return fmt.Errorf("Failed reading delta extent: %w", err)
+		}
+
+		offset := int64(binary.BigEndian.Uint64(header))
+		length := int64(binary.BigEndian.Uint64(header[8:]))
+
+		if length == 0 {
+			return nil
+		}
+
+		if offset < end || length < 0 || offset+length < offset || offset+length > dr.diskSize {
+			return fmt.Errorf("Invalid delta extent at offset %d of length %d", offset, length)
+		}
+
+		n, err := io.Copy(&offsetWriter{w: target, offset: offset}, io.LimitReader(dr.r, length))
+		if err != nil {
+			return fmt.Errorf("Failed applying delta extent at offset %d: %w", offset, err)
+		}
+
+		if n != length {
+			return fmt.Errorf("Failed applying delta extent at offset %d: %w", offset, io.ErrUnexpectedEOF)
+		}
+
+		end = offset + length
+	}
+}
+
+// offsetWriter writes sequentially to an io.WriterAt, starting at offset.
+type offsetWriter struct {
+	w      io.WriterAt
+	offset int64
+}
+
+// Write writes p at the current offset.
+func (ow *offsetWriter) Write(p []byte) (int, error) {
+	n, err := ow.w.WriteAt(p, ow.offset)
+	ow.offset += int64(n)
+
+	return n, err
+}
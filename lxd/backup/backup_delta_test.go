@@ -0,0 +1,86 @@
+package backup_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/lxd/lxd/backup"
+)
+
+func TestDelta_RoundTrip(t *testing.T) {
+	base := bytes.Repeat([]byte{'a'}, 4096)
+	current := bytes.Repeat([]byte{'b'}, 4096+512)
+	extents := []backup.Extent{
+		{Offset: 0, Length: 512},
+		{Offset: 1024, Length: 1024},
+		{Offset: 4096, Length: 512},
+	}
+
+	buf := &bytes.Buffer{}
+	err := backup.WriteDelta(buf, bytes.NewReader(current), int64(len(current)), extents)
+	require.NoError(t, err)
+	assert.Equal(t, backup.DeltaSize(extents), int64(buf.Len()))
+
+	target, err := os.Create(filepath.Join(t.TempDir(), "disk"))
+	require.NoError(t, err)
+	defer target.Close()
+
+	_, err = target.Write(base)
+	require.NoError(t, err)
+
+	dr, err := backup.NewDeltaReader(buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(current)), dr.DiskSize())
+
+	require.NoError(t, target.Truncate(dr.DiskSize()))
+	require.NoError(t, dr.Apply(target))
+
+	expected := make([]byte, len(current))
+	copy(expected, base)
+	for _, extent := range extents {
+		copy(expected[extent.Offset:], current[extent.Offset:extent.Offset+extent.Length])
+	}
+
+	result, err := os.ReadFile(target.Name())
+	require.NoError(t, err)
+	assert.Equal(t, expected, result)
+}
+
+func TestDelta_InvalidExtents(t *testing.T) {
+	src := bytes.NewReader(make([]byte, 1024))
+
+	tests := [][]backup.Extent{
+		{{Offset: 512, Length: 0}},
+		{{Offset: 512, Length: 1024}},
+		{{Offset: 512, Length: 256}, {Offset: 256, Length: 256}},
+	}
+
+	for _, extents := range tests {
+		err := backup.WriteDelta(&bytes.Buffer{}, src, 1024, extents)
+		assert.Error(t, err, extents)
+	}
+}
+
+func TestDelta_Corrupted(t *testing.T) {
+	_, err := backup.NewDeltaReader(bytes.NewReader([]byte("NOTADELTA-AT-ALL-HERE")))
+	assert.Error(t, err)
+
+	buf := &bytes.Buffer{}
+	err = backup.WriteDelta(buf, bytes.NewReader(make([]byte, 1024)), 1024, []backup.Extent{{Offset: 0, Length: 1024}})
+	require.NoError(t, err)
+
+	// Truncated content.
+	dr, err := backup.NewDeltaReader(bytes.NewReader(buf.Bytes()[:buf.Len()-600]))
+	require.NoError(t, err)
+
+	target, err := os.Create(filepath.Join(t.TempDir(), "disk"))
+	require.NoError(t, err)
+	defer target.Close()
+
+	assert.Error(t, dr.Apply(target))
+}
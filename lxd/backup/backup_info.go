@@ -48,6 +48,8 @@ type Info struct {
 	OptimizedHeader  *bool    `json:"optimized_header,omitempty" yaml:"optimized_header,omitempty"` // Optional field to handle older optimized backups that don't have this field.
 	Type             Type     `json:"type,omitempty" yaml:"type,omitempty"`                         // Type of backup.
 	Config           *Config  `json:"config,omitempty" yaml:"config,omitempty"`                     // Equivalent of backup.yaml but embedded in index for quick retrieval.
+	Checkpoint       string   `json:"checkpoint,omitempty" yaml:"checkpoint,omitempty"`             // Checkpoint later incremental backups can be based on (running virtual machines only).
+	IncrementalFrom  string   `json:"incremental_from,omitempty" yaml:"incremental_from,omitempty"` // Checkpoint of the backup this incremental backup is based on.
 }
 
 // GetInfo extracts backup information from a given ReadSeeker.
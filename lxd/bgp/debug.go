@@ -5,6 +5,9 @@ type DebugInfo struct {
 	Server   DebugInfoServer   `json:"server" yaml:"server"`
 	Prefixes []DebugInfoPrefix `json:"prefixes" yaml:"prefixes"`
 	Peers    []DebugInfoPeer   `json:"peers" yaml:"peers"`
+
+	EVPNSegments []DebugInfoEVPNSegment `json:"evpn_segments" yaml:"evpn_segments"`
+	EVPNRoutes   []DebugInfoEVPNRoute   `json:"evpn_routes" yaml:"evpn_routes"`
 }
 
 // DebugInfoServer exposes the shared listener configuration.
@@ -28,6 +31,23 @@ type DebugInfoPeer struct {
 	ASN      uint32 `json:"asn" yaml:"asn"`
 	Password string `json:"password" yaml:"password"`
 	Count    int    `json:"count" yaml:"count"`
+
+	EVPNOwners []string `json:"evpn_owners" yaml:"evpn_owners"`
+}
+
+// DebugInfoEVPNSegment exposes details on a single EVPN segment.
+type DebugInfoEVPNSegment struct {
+	VNI         uint32   `json:"vni" yaml:"vni"`
+	VTEP        string   `json:"vtep" yaml:"vtep"`
+	RouteTarget string   `json:"route_target" yaml:"route_target"`
+	MACs        []string `json:"macs" yaml:"macs"`
+}
+
+// DebugInfoEVPNRoute exposes details on a single EVPN route learned from a peer.
+type DebugInfoEVPNRoute struct {
+	VNI  uint32 `json:"vni" yaml:"vni"`
+	VTEP string `json:"vtep" yaml:"vtep"`
+	MAC  string `json:"mac" yaml:"mac"`
 }
 
 // Debug returns a dump of the current configuration.
@@ -56,6 +76,7 @@ func (s *Server) debug() DebugInfo {
 		entry.ASN = peer.asn
 		entry.Password = peer.password
 		entry.Count = peer.count
+		entry.EVPNOwners = peer.evpnOwners
 
 		debug.Peers = append(debug.Peers, entry)
 	}
@@ -71,5 +92,34 @@ func (s *Server) debug() DebugInfo {
 		debug.Prefixes = append(debug.Prefixes, entry)
 	}
 
+	// Fill in the EVPN segments.
+	debug.EVPNSegments = []DebugInfoEVPNSegment{}
+	for _, segment := range s.evpnSegments {
+		entry := DebugInfoEVPNSegment{}
+		entry.VNI = segment.vni
+		entry.VTEP = segment.vtep.String()
+		entry.RouteTarget = segment.routeTarget
+		entry.MACs = []string{}
+
+		for _, mac := range s.evpnMACs {
+			if mac.vni == segment.vni {
+				entry.MACs = append(entry.MACs, mac.mac.String())
+			}
+		}
+
+		debug.EVPNSegments = append(debug.EVPNSegments, entry)
+	}
+
+	// Fill in the learned EVPN routes.
+	debug.EVPNRoutes = []DebugInfoEVPNRoute{}
+	for _, learned := range s.evpnRoutes {
+		entry := DebugInfoEVPNRoute{}
+		entry.VNI = learned.route.VNI
+		entry.VTEP = learned.route.VTEP.String()
+		entry.MAC = learned.route.MAC.String()
+
+		debug.EVPNRoutes = append(debug.EVPNRoutes, entry)
+	}
+
 	return debug
 }
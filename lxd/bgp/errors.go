@@ -12,3 +12,6 @@ var ErrPeerNotFound = fmt.Errorf("Peer not found")
 
 // ErrBadRouterID is returned when an invalid router-id is provided.
 var ErrBadRouterID = fmt.Errorf("Invalid router-id (must be IPv4 address")
+
+// ErrEVPNSegmentNotFound is returned when an EVPN segment couldn't be found.
+var ErrEVPNSegmentNotFound = fmt.Errorf("EVPN segment not found")
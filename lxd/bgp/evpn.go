@@ -0,0 +1,611 @@
+package bgp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	bgpAPI "github.com/osrg/gobgp/v3/api"
+	"github.com/pborman/uuid"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// EVPN route target extended community sub-type (RFC4360).
+const evpnSubTypeRouteTarget = 0x02
+
+// EVPN VXLAN encapsulation tunnel type (RFC8365).
+const evpnTunnelTypeVXLAN = 8
+
+// EVPN ingress replication PMSI tunnel type (RFC7432).
+const evpnPMSIIngressReplication = 6
+
+// EVPNRoute represents a route of an EVPN segment learned from a peer.
+type EVPNRoute struct {
+	// VXLAN network identifier of the segment.
+	VNI uint32
+
+	// Address of the remote VXLAN tunnel endpoint.
+	VTEP net.IP
+
+	// MAC address reachable through the remote VTEP, nil for the routes telling that the remote VTEP
+	// is part of the segment and should receive its broadcast, unknown unicast and multicast traffic.
+	MAC net.HardwareAddr
+
+	// Whether the route was withdrawn.
+	Withdraw bool
+}
+
+// EVPNHandler is called with the routes learned from peers for an EVPN segment.
+// It is called with the server locked and so must not call back into the server.
+type EVPNHandler func(route EVPNRoute)
+
+type evpnSegment struct {
+	vni         uint32
+	vtep        net.IP
+	routeTarget string
+	handler     EVPNHandler
+	uuid        string
+}
+
+type evpnMAC struct {
+	vni   uint32
+	mac   net.HardwareAddr
+	owner string
+	uuid  string
+}
+
+type evpnRoute struct {
+	route        EVPNRoute
+	routeTargets []string
+}
+
+// ParseRouteTarget parses a route target in the "ASN:value" format.
+func ParseRouteTarget(routeTarget string) (uint32, uint32, error) {
+	fields := strings.SplitN(routeTarget, ":", 2)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("Invalid route target %q (must be ASN:value)", routeTarget)
+	}
+
+	asn, err := strconv.ParseUint(fields[0], 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Invalid ASN in route target %q: %w", routeTarget, err)
+	}
+
+	// A 4 bytes ASN leaves 2 bytes for the value, a 2 bytes ASN leaves 4 bytes.
+	bitSize := 32
+	if asn > 65535 {
+		bitSize = 16
+	}
+
+	value, err := strconv.ParseUint(fields[1], 10, bitSize)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Invalid value in route target %q: %w", routeTarget, err)
+	}
+
+	return uint32(asn), uint32(value), nil
+}
+
+// AddEVPNSegment adds or updates an EVPN segment, advertising the VTEP and the MAC addresses of the segment
+// and calling the handler with the routes learned from peers for it.
+func (s *Server) AddEVPNSegment(vni uint32, vtep net.IP, routeTarget string, handler EVPNHandler) error {
+	// Locking.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if vtep.To4() == nil {
+		return fmt.Errorf("Invalid VTEP address %q (must be IPv4)", vtep)
+	}
+
+	_, _, err := ParseRouteTarget(routeTarget)
+	if err != nil {
+		return err
+	}
+
+	// Withdraw the routes of the existing segment.
+	err = s.removeEVPNSegment(vni)
+	if err != nil && err != ErrEVPNSegmentNotFound {
+		return err
+	}
+
+	segment := &evpnSegment{
+		vni:         vni,
+		vtep:        vtep,
+		routeTarget: routeTarget,
+		handler:     handler,
+	}
+
+	s.evpnSegments[vni] = segment
+
+	// Advertise the VTEP and the MAC addresses of the segment.
+	segment.uuid, err = s.addEVPNPath(segment, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range s.evpnMACs {
+		if entry.vni != vni {
+			continue
+		}
+
+		entry.uuid, err = s.addEVPNPath(segment, entry.mac)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Replay the routes already learned for the segment.
+	for _, learned := range s.evpnRoutes {
+		if segment.imports(learned) {
+			handler(learned.route)
+		}
+	}
+
+	return nil
+}
+
+// RemoveEVPNSegment removes an EVPN segment, withdrawing its routes. The MAC addresses of the segment are kept
+// and advertised again when the segment is added back.
+func (s *Server) RemoveEVPNSegment(vni uint32) error {
+	// Locking.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.removeEVPNSegment(vni)
+}
+
+func (s *Server) removeEVPNSegment(vni uint32) error {
+	segment, ok := s.evpnSegments[vni]
+	if !ok {
+		return ErrEVPNSegmentNotFound
+	}
+
+	for _, entry := range s.evpnMACs {
+		if entry.vni != vni || entry.uuid == "" {
+			continue
+		}
+
+		err := s.removeEVPNPath(entry.uuid)
+		if err != nil {
+			return err
+		}
+
+		entry.uuid = ""
+	}
+
+	err := s.removeEVPNPath(segment.uuid)
+	if err != nil {
+		return err
+	}
+
+	delete(s.evpnSegments, vni)
+
+	return nil
+}
+
+// AddEVPNMAC advertises a MAC address as reachable through the local VTEP of an EVPN segment.
+// If the segment isn't set up yet, the MAC address is advertised once it is added.
+func (s *Server) AddEVPNMAC(vni uint32, mac net.HardwareAddr, owner string) error {
+	// Locking.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entry := range s.evpnMACs {
+		if entry.vni == vni && entry.owner == owner && bytes.Equal(entry.mac, mac) {
+			return nil
+		}
+	}
+
+	entry := &evpnMAC{
+		vni:   vni,
+		mac:   mac,
+		owner: owner,
+	}
+
+	segment, ok := s.evpnSegments[vni]
+	if ok {
+		var err error
+		entry.uuid, err = s.addEVPNPath(segment, mac)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.evpnMACs = append(s.evpnMACs, entry)
+
+	return nil
+}
+
+// RemoveEVPNMACByOwner withdraws all MAC addresses advertised for the provided owner.
+func (s *Server) RemoveEVPNMACByOwner(owner string) error {
+	// Locking.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make([]*evpnMAC, 0, len(s.evpnMACs))
+	for _, entry := range s.evpnMACs {
+		if entry.owner != owner {
+			entries = append(entries, entry)
+			continue
+		}
+
+		if entry.uuid != "" {
+			err := s.removeEVPNPath(entry.uuid)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	s.evpnMACs = entries
+
+	return nil
+}
+
+// AddEVPNPeer adds a BGP peer exchanging EVPN routes on behalf of the provided owner.
+func (s *Server) AddEVPNPeer(address net.IP, asn uint32, password string, owner string) error {
+	// Locking.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bgpPeer, ok := s.peers[address.String()]
+	if ok && bgpPeer.hasEVPNOwner(owner) {
+		return nil
+	}
+
+	return s.addPeer(address, asn, password, owner)
+}
+
+// RemoveEVPNPeer removes a BGP peer exchanging EVPN routes on behalf of the provided owner.
+func (s *Server) RemoveEVPNPeer(address net.IP, owner string) error {
+	// Locking.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bgpPeer, ok := s.peers[address.String()]
+	if !ok || !bgpPeer.hasEVPNOwner(owner) {
+		return ErrPeerNotFound
+	}
+
+	return s.removePeer(address, owner)
+}
+
+// GetEVPNPeers returns the BGP peers exchanging EVPN routes on behalf of the provided owner, in the
+// "address,asn,password" format.
+func (s *Server) GetEVPNPeers(owner string) []string {
+	// Locking.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	peers := []string{}
+	for _, bgpPeer := range s.peers {
+		if bgpPeer.hasEVPNOwner(owner) {
+			peers = append(peers, fmt.Sprintf("%s,%d,%s", bgpPeer.address.String(), bgpPeer.asn, bgpPeer.password))
+		}
+	}
+
+	return peers
+}
+
+// hasEVPNOwner returns whether the peer exchanges EVPN routes on behalf of the owner.
+func (p peer) hasEVPNOwner(owner string) bool {
+	for _, evpnOwner := range p.evpnOwners {
+		if evpnOwner == owner {
+			return true
+		}
+	}
+
+	return false
+}
+
+// imports returns whether the learned route belongs to the segment.
+func (e *evpnSegment) imports(learned evpnRoute) bool {
+	if learned.route.VNI != e.vni || learned.route.VTEP.Equal(e.vtep) {
+		return false
+	}
+
+	for _, routeTarget := range learned.routeTargets {
+		if routeTarget == e.routeTarget {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Server) addEVPNPath(segment *evpnSegment, mac net.HardwareAddr) (string, error) {
+	if s.bgp == nil {
+		// Generate a dummy UUID.
+		return uuid.New(), nil
+	}
+
+	path, err := evpnPath(segment, mac)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := s.bgp.AddPath(context.Background(), &bgpAPI.AddPathRequest{Path: path})
+	if err != nil {
+		return "", err
+	}
+
+	return string(resp.Uuid), nil
+}
+
+func (s *Server) removeEVPNPath(pathUUID string) error {
+	if s.bgp == nil {
+		return nil
+	}
+
+	err := s.bgp.DeletePath(context.Background(), &bgpAPI.DeletePathRequest{Uuid: []byte(pathUUID)})
+	if err != nil && err.Error() != "can't find a specified path" {
+		return err
+	}
+
+	return nil
+}
+
+// evpnRestore advertises again the EVPN routes on a new BGP instance.
+func (s *Server) evpnRestore() {
+	for _, segment := range s.evpnSegments {
+		segment.uuid, _ = s.addEVPNPath(segment, nil)
+	}
+
+	for _, entry := range s.evpnMACs {
+		segment, ok := s.evpnSegments[entry.vni]
+		if !ok {
+			continue
+		}
+
+		entry.uuid, _ = s.addEVPNPath(segment, entry.mac)
+	}
+}
+
+// evpnWatch watches the best EVPN paths to pass the routes learned from peers to the segment handlers.
+func (s *Server) evpnWatch() error {
+	req := &bgpAPI.WatchEventRequest{
+		Table: &bgpAPI.WatchEventRequest_Table{
+			Filters: []*bgpAPI.WatchEventRequest_Table_Filter{
+				{Type: bgpAPI.WatchEventRequest_Table_Filter_BEST, Init: true},
+			},
+		},
+	}
+
+	return s.bgp.WatchEvent(context.Background(), req, func(resp *bgpAPI.WatchEventResponse) {
+		table := resp.GetTable()
+		if table == nil {
+			return
+		}
+
+		// Locking.
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		for _, path := range table.Paths {
+			family := path.GetFamily()
+			if family.GetAfi() != bgpAPI.Family_AFI_L2VPN || family.GetSafi() != bgpAPI.Family_SAFI_EVPN {
+				continue
+			}
+
+			// Skip the routes advertised locally.
+			if net.ParseIP(path.GetNeighborIp()) == nil {
+				continue
+			}
+
+			learned, err := parseEVPNPath(path)
+			if err != nil {
+				continue
+			}
+
+			s.evpnLearn(learned)
+		}
+	})
+}
+
+// evpnLearn records a learned route and passes it to the handler of its segment.
+func (s *Server) evpnLearn(learned *evpnRoute) {
+	key := fmt.Sprintf("%d/%s/%s", learned.route.VNI, learned.route.VTEP.String(), learned.route.MAC.String())
+
+	if learned.route.Withdraw {
+		existing, ok := s.evpnRoutes[key]
+		if !ok {
+			return
+		}
+
+		delete(s.evpnRoutes, key)
+
+		// Withdrawals may not carry the attributes of the route.
+		learned = &evpnRoute{route: existing.route, routeTargets: existing.routeTargets}
+		learned.route.Withdraw = true
+	} else {
+		s.evpnRoutes[key] = *learned
+	}
+
+	segment, ok := s.evpnSegments[learned.route.VNI]
+	if ok && segment.imports(*learned) {
+		segment.handler(learned.route)
+	}
+}
+
+// evpnPath returns the EVPN path advertising the MAC address through the VTEP of the segment, or the VTEP
+// itself when the MAC address is nil.
+func evpnPath(segment *evpnSegment, mac net.HardwareAddr) (*bgpAPI.Path, error) {
+	family := &bgpAPI.Family{
+		Afi:  bgpAPI.Family_AFI_L2VPN,
+		Safi: bgpAPI.Family_SAFI_EVPN,
+	}
+
+	// The route distinguisher only needs to be unique per VTEP and segment, and only has 2 bytes for the
+	// segment.
+	rd, err := anypb.New(&bgpAPI.RouteDistinguisherIPAddress{
+		Admin:    segment.vtep.String(),
+		Assigned: segment.vni & 0xffff,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var nlri *anypb.Any
+	if mac == nil {
+		nlri, err = anypb.New(&bgpAPI.EVPNInclusiveMulticastEthernetTagRoute{
+			Rd:        rd,
+			IpAddress: segment.vtep.String(),
+		})
+	} else {
+		nlri, err = anypb.New(&bgpAPI.EVPNMACIPAdvertisementRoute{
+			Rd:         rd,
+			Esi:        &bgpAPI.EthernetSegmentIdentifier{Value: make([]byte, 9)},
+			MacAddress: mac.String(),
+			Labels:     []uint32{segment.vni},
+		})
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	asn, value, err := ParseRouteTarget(segment.routeTarget)
+	if err != nil {
+		return nil, err
+	}
+
+	var routeTarget proto.Message
+	if asn > 65535 {
+		routeTarget = &bgpAPI.FourOctetAsSpecificExtended{IsTransitive: true, SubType: evpnSubTypeRouteTarget, Asn: asn, LocalAdmin: value}
+	} else {
+		routeTarget = &bgpAPI.TwoOctetAsSpecificExtended{IsTransitive: true, SubType: evpnSubTypeRouteTarget, Asn: asn, LocalAdmin: value}
+	}
+
+	aRouteTarget, err := anypb.New(routeTarget)
+	if err != nil {
+		return nil, err
+	}
+
+	aEncap, err := anypb.New(&bgpAPI.EncapExtended{TunnelType: evpnTunnelTypeVXLAN})
+	if err != nil {
+		return nil, err
+	}
+
+	aOrigin, _ := anypb.New(&bgpAPI.OriginAttribute{
+		Origin: 0,
+	})
+
+	aNextHop, _ := anypb.New(&bgpAPI.MpReachNLRIAttribute{
+		Family:   family,
+		NextHops: []string{segment.vtep.String()},
+		Nlris:    []*anypb.Any{nlri},
+	})
+
+	aCommunities, _ := anypb.New(&bgpAPI.ExtendedCommunitiesAttribute{
+		Communities: []*anypb.Any{aRouteTarget, aEncap},
+	})
+
+	pattrs := []*anypb.Any{aOrigin, aNextHop, aCommunities}
+
+	// Tell peers to replicate the broadcast traffic of the segment to the VTEP.
+	if mac == nil {
+		aPMSI, _ := anypb.New(&bgpAPI.PmsiTunnelAttribute{
+			Type:  evpnPMSIIngressReplication,
+			Label: segment.vni,
+			Id:    segment.vtep.To4(),
+		})
+
+		pattrs = append(pattrs, aPMSI)
+	}
+
+	return &bgpAPI.Path{
+		Family: family,
+		Nlri:   nlri,
+		Pattrs: pattrs,
+	}, nil
+}
+
+// parseEVPNPath parses the MAC and inclusive multicast routes of an EVPN path.
+func parseEVPNPath(path *bgpAPI.Path) (*evpnRoute, error) {
+	learned := &evpnRoute{
+		route: EVPNRoute{Withdraw: path.GetIsWithdraw()},
+	}
+
+	nlri, err := path.GetNlri().UnmarshalNew()
+	if err != nil {
+		return nil, err
+	}
+
+	var vtep net.IP
+	var pmsiLabel *uint32
+	for _, pattr := range path.GetPattrs() {
+		attr, err := pattr.UnmarshalNew()
+		if err != nil {
+			return nil, err
+		}
+
+		switch v := attr.(type) {
+		case *bgpAPI.MpReachNLRIAttribute:
+			if len(v.NextHops) > 0 {
+				vtep = net.ParseIP(v.NextHops[0])
+			}
+
+		case *bgpAPI.PmsiTunnelAttribute:
+			label := v.Label
+			pmsiLabel = &label
+
+		case *bgpAPI.ExtendedCommunitiesAttribute:
+			for _, community := range v.Communities {
+				value, err := community.UnmarshalNew()
+				if err != nil {
+					return nil, err
+				}
+
+				switch c := value.(type) {
+				case *bgpAPI.TwoOctetAsSpecificExtended:
+					if c.SubType == evpnSubTypeRouteTarget {
+						learned.routeTargets = append(learned.routeTargets, fmt.Sprintf("%d:%d", c.Asn, c.LocalAdmin))
+					}
+
+				case *bgpAPI.FourOctetAsSpecificExtended:
+					if c.SubType == evpnSubTypeRouteTarget {
+						learned.routeTargets = append(learned.routeTargets, fmt.Sprintf("%d:%d", c.Asn, c.LocalAdmin))
+					}
+				}
+			}
+		}
+	}
+
+	switch v := nlri.(type) {
+	case *bgpAPI.EVPNMACIPAdvertisementRoute:
+		if len(v.Labels) == 0 {
+			return nil, fmt.Errorf("Missing VNI in EVPN MAC route")
+		}
+
+		learned.route.VNI = v.Labels[0]
+		learned.route.MAC, err = net.ParseMAC(v.MacAddress)
+		if err != nil {
+			return nil, err
+		}
+
+	case *bgpAPI.EVPNInclusiveMulticastEthernetTagRoute:
+		if pmsiLabel != nil {
+			learned.route.VNI = *pmsiLabel
+		} else {
+			learned.route.VNI = v.EthernetTag
+		}
+
+		if vtep == nil {
+			vtep = net.ParseIP(v.IpAddress)
+		}
+
+	default:
+		return nil, fmt.Errorf("Unsupported EVPN route type %q", path.GetNlri().GetTypeUrl())
+	}
+
+	if vtep == nil {
+		return nil, fmt.Errorf("Missing VTEP in EVPN route")
+	}
+
+	learned.route.VTEP = vtep
+
+	return learned, nil
+}
@@ -0,0 +1,81 @@
+package bgp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRouteTarget(t *testing.T) {
+	tests := []struct {
+		routeTarget string
+		asn         uint32
+		value       uint32
+		valid       bool
+	}{
+		{"65000:100", 65000, 100, true},
+		{"65000:16777215", 65000, 16777215, true},
+		{"4200000000:100", 4200000000, 100, true},
+		{"4200000000:100000", 0, 0, false},
+		{"65000", 0, 0, false},
+		{"foo:100", 0, 0, false},
+		{"65000:bar", 0, 0, false},
+	}
+
+	for _, test := range tests {
+		asn, value, err := ParseRouteTarget(test.routeTarget)
+		if !test.valid {
+			assert.Error(t, err, test.routeTarget)
+			continue
+		}
+
+		assert.NoError(t, err, test.routeTarget)
+		assert.Equal(t, test.asn, asn, test.routeTarget)
+		assert.Equal(t, test.value, value, test.routeTarget)
+	}
+}
+
+func TestEVPNPath(t *testing.T) {
+	segment := &evpnSegment{
+		vni:         100000,
+		vtep:        net.ParseIP("10.0.0.1"),
+		routeTarget: "65000:100000",
+	}
+
+	// Inclusive multicast route.
+	path, err := evpnPath(segment, nil)
+	require.NoError(t, err)
+
+	learned, err := parseEVPNPath(path)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(100000), learned.route.VNI)
+	assert.Equal(t, "10.0.0.1", learned.route.VTEP.String())
+	assert.Nil(t, learned.route.MAC)
+	assert.False(t, learned.route.Withdraw)
+	assert.Equal(t, []string{"65000:100000"}, learned.routeTargets)
+
+	// MAC route.
+	mac, _ := net.ParseMAC("00:16:3e:01:02:03")
+	path, err = evpnPath(segment, mac)
+	require.NoError(t, err)
+
+	path.IsWithdraw = true
+
+	learned, err = parseEVPNPath(path)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(100000), learned.route.VNI)
+	assert.Equal(t, "10.0.0.1", learned.route.VTEP.String())
+	assert.Equal(t, mac, learned.route.MAC)
+	assert.True(t, learned.route.Withdraw)
+
+	// Routes of the local VTEP or of other segments aren't imported.
+	assert.False(t, segment.imports(*learned))
+
+	learned.route.VTEP = net.ParseIP("10.0.0.2")
+	assert.True(t, segment.imports(*learned))
+
+	learned.routeTargets = []string{"65000:1"}
+	assert.False(t, segment.imports(*learned))
+}
@@ -27,6 +27,11 @@ type Server struct {
 	paths    map[string]path
 	peers    map[string]peer
 
+	// EVPN state.
+	evpnSegments map[uint32]*evpnSegment
+	evpnMACs     []*evpnMAC
+	evpnRoutes   map[string]evpnRoute
+
 	mu sync.Mutex
 }
 
@@ -41,14 +46,19 @@ type peer struct {
 	asn      uint32
 	password string
 	count    int
+
+	// Owners of the peer requiring the EVPN routes to be exchanged.
+	evpnOwners []string
 }
 
 // NewServer returns a new server instance.
 func NewServer() *Server {
 	// Setup new struct.
 	s := &Server{
-		paths: map[string]path{},
-		peers: map[string]peer{},
+		paths:        map[string]path{},
+		peers:        map[string]peer{},
+		evpnSegments: map[uint32]*evpnSegment{},
+		evpnRoutes:   map[string]evpnRoute{},
 	}
 	return s
 }
@@ -72,6 +82,12 @@ func (s *Server) setup() {
 			s.addPrefix(path.prefix, path.nexthop, path.owner)
 		}
 	}
+
+	// Insert any EVPN route that's already defined.
+	s.evpnRestore()
+
+	// Pass the EVPN routes learned from peers to the segments.
+	s.evpnWatch()
 }
 
 // Start sets up the BGP listener.
@@ -113,8 +129,8 @@ func (s *Server) start(address string, asn uint32, routerID net.IP) error {
 		RouterId: routerID.String(),
 		Asn:      asn,
 
-		// Always setup for IPv4 and IPv6.
-		Families: []uint32{0, 1},
+		// Always setup for IPv4, IPv6 and EVPN.
+		Families: []uint32{0, 1, 9},
 
 		// Listen address.
 		ListenAddresses: []string{addrHost},
@@ -129,7 +145,7 @@ func (s *Server) start(address string, asn uint32, routerID net.IP) error {
 
 	// Add any existing peers.
 	for _, peer := range s.peers {
-		err := s.addPeer(peer.address, peer.asn, peer.password)
+		err := s.addPeer(peer.address, peer.asn, peer.password, "")
 		if err != nil {
 			return err
 		}
@@ -160,7 +176,7 @@ func (s *Server) stop() error {
 
 	// Remove all the peers (ignore failures).
 	for _, peer := range s.peers {
-		err := s.removePeer(peer.address)
+		err := s.removePeer(peer.address, "")
 		if err != nil {
 			return err
 		}
@@ -391,10 +407,11 @@ func (s *Server) AddPeer(address net.IP, asn uint32, password string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	return s.addPeer(address, asn, password)
+	return s.addPeer(address, asn, password, "")
 }
 
-func (s *Server) addPeer(address net.IP, asn uint32, password string) error {
+// addPeer adds a new BGP peer, exchanging EVPN routes when evpnOwner is set.
+func (s *Server) addPeer(address net.IP, asn uint32, password string, evpnOwner string) error {
 	// Look for an existing peer.
 	bgpPeer, bgpPeerExists := s.peers[address.String()]
 	if bgpPeerExists {
@@ -408,10 +425,61 @@ func (s *Server) addPeer(address net.IP, asn uint32, password string) error {
 
 		// Re-use the existing entry.
 		bgpPeer.count++
+		if evpnOwner != "" {
+			bgpPeer.evpnOwners = append(bgpPeer.evpnOwners, evpnOwner)
+		}
+
 		s.peers[address.String()] = bgpPeer
+
+		// Enable EVPN on the existing peer.
+		if evpnOwner != "" && len(bgpPeer.evpnOwners) == 1 {
+			return s.resetPeer(bgpPeer)
+		}
+
+		return nil
+	}
+
+	// Add the peer.
+	if s.bgp != nil {
+		err := s.bgp.AddPeer(context.Background(), &bgpAPI.AddPeerRequest{Peer: peerConfig(address, asn, password, evpnOwner != "")})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Add the peer to the list.
+	bgpPeer = peer{
+		address:  address,
+		asn:      asn,
+		password: password,
+		count:    1,
+	}
+
+	if evpnOwner != "" {
+		bgpPeer.evpnOwners = []string{evpnOwner}
+	}
+
+	s.peers[address.String()] = bgpPeer
+
+	return nil
+}
+
+// resetPeer re-creates the peer on the BGP server to apply a change of address families.
+func (s *Server) resetPeer(bgpPeer peer) error {
+	if s.bgp == nil {
 		return nil
 	}
 
+	err := s.bgp.DeletePeer(context.Background(), &bgpAPI.DeletePeerRequest{Address: bgpPeer.address.String()})
+	if err != nil {
+		return err
+	}
+
+	return s.bgp.AddPeer(context.Background(), &bgpAPI.AddPeerRequest{Peer: peerConfig(bgpPeer.address, bgpPeer.asn, bgpPeer.password, len(bgpPeer.evpnOwners) > 0)})
+}
+
+// peerConfig returns the BGP server configuration of a peer.
+func peerConfig(address net.IP, asn uint32, password string, evpn bool) *bgpAPI.Peer {
 	// Setup the configuration.
 	n := &bgpAPI.Peer{
 		// Peer information.
@@ -429,12 +497,14 @@ func (s *Server) addPeer(address net.IP, asn uint32, password string) error {
 	}
 
 	// Setup peer for dual-stack.
+	families := []string{"ipv4-unicast", "ipv6-unicast"}
+	if evpn {
+		families = append(families, "l2vpn-evpn")
+	}
+
 	n.AfiSafis = make([]*bgpAPI.AfiSafi, 0)
-	for _, f := range []string{"ipv4-unicast", "ipv6-unicast"} {
-		rf, err := bgpPacket.GetRouteFamily(f)
-		if err != nil {
-			return err
-		}
+	for _, f := range families {
+		rf, _ := bgpPacket.GetRouteFamily(f)
 
 		afi, safi := bgpPacket.RouteFamilyToAfiSafi(rf)
 		family := &bgpAPI.Family{
@@ -452,28 +522,7 @@ func (s *Server) addPeer(address net.IP, asn uint32, password string) error {
 		})
 	}
 
-	// Add the peer.
-	if s.bgp != nil {
-		err := s.bgp.AddPeer(context.Background(), &bgpAPI.AddPeerRequest{Peer: n})
-		if err != nil {
-			return err
-		}
-	}
-
-	// Add the peer to the list.
-	if bgpPeerExists {
-		bgpPeer.count++
-		s.peers[address.String()] = bgpPeer
-	} else {
-		s.peers[address.String()] = peer{
-			address:  address,
-			asn:      asn,
-			password: password,
-			count:    1,
-		}
-	}
-
-	return nil
+	return n
 }
 
 // RemovePeer removes a prefix from the BGP server.
@@ -482,10 +531,11 @@ func (s *Server) RemovePeer(address net.IP) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	return s.removePeer(address)
+	return s.removePeer(address, "")
 }
 
-func (s *Server) removePeer(address net.IP) error {
+// removePeer removes a BGP peer, no longer exchanging EVPN routes for evpnOwner when set.
+func (s *Server) removePeer(address net.IP, evpnOwner string) error {
 	// Find the peer.
 	bgpPeer, bgpPeerExists := s.peers[address.String()]
 	if !bgpPeerExists {
@@ -507,7 +557,24 @@ func (s *Server) removePeer(address net.IP) error {
 	} else {
 		// Decrease refcount.
 		bgpPeer.count--
+
+		if evpnOwner != "" {
+			evpnOwners := []string{}
+			for _, owner := range bgpPeer.evpnOwners {
+				if owner != evpnOwner {
+					evpnOwners = append(evpnOwners, owner)
+				}
+			}
+
+			bgpPeer.evpnOwners = evpnOwners
+		}
+
 		s.peers[address.String()] = bgpPeer
+
+		// Disable EVPN on the remaining peer.
+		if evpnOwner != "" && len(bgpPeer.evpnOwners) == 0 {
+			return s.resetPeer(bgpPeer)
+		}
 	}
 
 	return nil
@@ -35,6 +35,7 @@ import (
 type certificateCache struct {
 	Certificates map[db.CertificateType]map[string]x509.Certificate
 	Projects     map[string][]string
+	Permissions  map[string][]api.AuthPermission
 	Lock         sync.Mutex
 }
 
@@ -188,6 +189,7 @@ func updateCertificateCache(d *Daemon) {
 	var certs []*api.Certificate
 	var dbCerts []db.Certificate
 	var localCerts []db.Certificate
+	var newPermissions map[string][]api.AuthPermission
 	var err error
 	err = d.cluster.Transaction(func(tx *db.ClusterTx) error {
 		dbCerts, err = tx.GetCertificates(db.CertificateFilter{})
@@ -195,6 +197,11 @@ func updateCertificateCache(d *Daemon) {
 			return err
 		}
 
+		newPermissions, err = tx.GetAuthGroupPermissionsByCertificate()
+		if err != nil {
+			return err
+		}
+
 		certs = make([]*api.Certificate, len(dbCerts))
 		for i, c := range dbCerts {
 			certs[i], err = c.ToAPI(tx)
@@ -251,6 +258,7 @@ func updateCertificateCache(d *Daemon) {
 	d.clientCerts.Lock.Lock()
 	d.clientCerts.Certificates = newCerts
 	d.clientCerts.Projects = newProjects
+	d.clientCerts.Permissions = newPermissions
 	d.clientCerts.Lock.Unlock()
 }
 
@@ -5,13 +5,17 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"golang.org/x/crypto/scrypt"
 
 	"github.com/lxc/lxd/lxd/config"
 	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/scriptlet/load"
+	"github.com/lxc/lxd/lxd/syslog"
 	"github.com/lxc/lxd/shared/validate"
 )
 
@@ -93,6 +97,31 @@ func (c *Config) RBACServer() (string, string, int64, string, string, string, st
 		c.m.GetString("rbac.agent.public_key")
 }
 
+// OIDCServer returns all the OpenID Connect settings needed to validate tokens.
+func (c *Config) OIDCServer() (string, string, string) {
+	return c.m.GetString("oidc.issuer"),
+		c.m.GetString("oidc.client.id"),
+		c.m.GetString("oidc.audience")
+}
+
+// LokiServer returns all the Loki settings needed to connect to a server.
+func (c *Config) LokiServer() (string, string, string, string, []string, string, []string) {
+	return c.m.GetString("loki.api.url"),
+		c.m.GetString("loki.auth.username"),
+		c.m.GetString("loki.auth.password"),
+		c.m.GetString("loki.api.ca_cert"),
+		configList(c.m.GetString("loki.labels")),
+		c.m.GetString("loki.loglevel"),
+		configList(c.m.GetString("loki.types"))
+}
+
+// SyslogServer returns all the syslog settings needed to connect to a server.
+func (c *Config) SyslogServer() (string, string, []string) {
+	return c.m.GetString("syslog.address"),
+		c.m.GetString("syslog.loglevel"),
+		configList(c.m.GetString("syslog.types"))
+}
+
 // ProxyHTTPS returns the configured HTTPS proxy, if any.
 func (c *Config) ProxyHTTPS() string {
 	return c.m.GetString("core.proxy_https")
@@ -128,6 +157,14 @@ func (c *Config) OfflineThreshold() time.Duration {
 	return time.Duration(n) * time.Second
 }
 
+// HealingThreshold returns the configured healing threshold, i.e. the
+// number of seconds after which an offline member has its instances
+// relocated to other members. Zero means healing is disabled.
+func (c *Config) HealingThreshold() time.Duration {
+	n := c.m.GetInt64("cluster.healing_threshold")
+	return time.Duration(n) * time.Second
+}
+
 // ImagesMinimalReplica returns the numbers of nodes for cluster images replication
 func (c *Config) ImagesMinimalReplica() int64 {
 	return c.m.GetInt64("cluster.images_minimal_replica")
@@ -157,6 +194,11 @@ func (c *Config) ImagesDefaultArchitecture() string {
 	return c.m.GetString("images.default_architecture")
 }
 
+// InstancesPlacementScriptlet returns the instances placement scriptlet source code.
+func (c *Config) InstancesPlacementScriptlet() string {
+	return c.m.GetString("instances.placement.scriptlet")
+}
+
 // Dump current configuration keys and their values. Keys with values matching
 // their defaults are omitted.
 func (c *Config) Dump() map[string]any {
@@ -248,6 +290,7 @@ func configGet(cluster *db.Cluster) (*Config, error) {
 var ConfigSchema = config.Schema{
 	"backups.compression_algorithm":  {Default: "gzip", Validator: validate.IsCompressionAlgorithm},
 	"cluster.offline_threshold":      {Type: config.Int64, Default: offlineThresholdDefault(), Validator: offlineThresholdValidator},
+	"cluster.healing_threshold":      {Type: config.Int64, Default: "0", Validator: validate.Optional(validate.IsUint32)},
 	"cluster.images_minimal_replica": {Type: config.Int64, Default: "3", Validator: imageMinimalReplicaValidator},
 	"cluster.max_voters":             {Type: config.Int64, Default: "3", Validator: maxVotersValidator},
 	"cluster.max_standby":            {Type: config.Int64, Default: "2", Validator: maxStandByValidator},
@@ -273,8 +316,19 @@ var ConfigSchema = config.Schema{
 	"images.compression_algorithm":   {Default: "gzip", Validator: validate.IsCompressionAlgorithm},
 	"images.default_architecture":    {Validator: validate.Optional(validate.IsArchitecture)},
 	"images.remote_cache_expiry":     {Type: config.Int64, Default: "10"},
+	"instances.placement.scriptlet":  {Validator: load.InstancePlacementValidate},
+	"loki.api.ca_cert":               {},
+	"loki.api.url":                   {Validator: lokiURLValidator},
+	"loki.auth.username":             {},
+	"loki.auth.password":             {},
+	"loki.labels":                    {},
+	"loki.loglevel":                  {Default: "info", Validator: logLevelValidator},
+	"loki.types":                     {Default: "lifecycle,logging", Validator: validate.Optional(validate.IsListOf(eventTypeValidator))},
 	"maas.api.key":                   {},
 	"maas.api.url":                   {},
+	"oidc.audience":                  {},
+	"oidc.client.id":                 {},
+	"oidc.issuer":                    {Validator: oidcIssuerValidator},
 	"rbac.agent.url":                 {},
 	"rbac.agent.username":            {},
 	"rbac.agent.private_key":         {},
@@ -283,6 +337,9 @@ var ConfigSchema = config.Schema{
 	"rbac.api.key":                   {},
 	"rbac.api.url":                   {},
 	"rbac.expiry":                    {Type: config.Int64, Default: "3600"},
+	"syslog.address":                 {Validator: validate.Optional(syslogAddressValidator)},
+	"syslog.loglevel":                {Default: "info", Validator: logLevelValidator},
+	"syslog.types":                   {Default: "lifecycle,logging", Validator: validate.Optional(validate.IsListOf(eventTypeValidator))},
 
 	// OVN networking global keys.
 	"network.ovn.integration_bridge":    {Default: "br-int"},
@@ -310,6 +367,68 @@ func offlineThresholdValidator(value string) error {
 	return nil
 }
 
+func oidcIssuerValidator(value string) error {
+	if value == "" {
+		return nil
+	}
+
+	u, err := url.ParseRequestURI(value)
+	if err != nil {
+		return fmt.Errorf("Invalid issuer URL: %w", err)
+	}
+
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return fmt.Errorf("Issuer URL must use HTTPS or HTTP")
+	}
+
+	return nil
+}
+
+func lokiURLValidator(value string) error {
+	if value == "" {
+		return nil
+	}
+
+	u, err := url.ParseRequestURI(value)
+	if err != nil {
+		return fmt.Errorf("Invalid Loki URL: %w", err)
+	}
+
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return fmt.Errorf("Loki URL must use HTTPS or HTTP")
+	}
+
+	return nil
+}
+
+func syslogAddressValidator(value string) error {
+	_, _, err := syslog.ParseAddress(value)
+	if err != nil {
+		return fmt.Errorf("Invalid syslog address: %w", err)
+	}
+
+	return nil
+}
+
+var logLevelValidator = validate.IsOneOf("debug", "info", "warn", "error")
+
+var eventTypeValidator = validate.IsOneOf("lifecycle", "logging", "operation")
+
+// configList splits a comma separated configuration value.
+func configList(value string) []string {
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		list = append(list, item)
+	}
+
+	return list
+}
+
 func imageMinimalReplicaValidator(value string) error {
 	count, err := strconv.Atoi(value)
 	if err != nil {
@@ -64,6 +64,22 @@ func TestConfigLoad_OfflineThresholdValidator(t *testing.T) {
 
 }
 
+// Healing threshold can't be negative.
+func TestConfigLoad_HealingThresholdValidator(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	config, err := cluster.ConfigLoad(tx)
+	require.NoError(t, err)
+
+	_, err = config.Patch(map[string]any{"cluster.healing_threshold": "-1"})
+	require.Error(t, err)
+
+	_, err = config.Patch(map[string]any{"cluster.healing_threshold": "300"})
+	require.NoError(t, err)
+	assert.Equal(t, float64(300), config.HealingThreshold().Seconds())
+}
+
 // Max number of voters must be odd.
 func TestConfigLoad_MaxVotersValidator(t *testing.T) {
 	tx, cleanup := db.NewTestClusterTx(t)
@@ -351,6 +351,29 @@ func (g *Gateway) IsDqliteNode() bool {
 	return true
 }
 
+// RaftRole returns the role of this member in the raft cluster and whether it's the current leader.
+// Returns ErrNodeIsNotClustered if the server isn't clustered.
+func (g *Gateway) RaftRole() (db.RaftRole, bool, error) {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	if g.memoryDial != nil {
+		return db.RaftSpare, false, ErrNodeIsNotClustered
+	}
+
+	// Members which don't run a dqlite node are spare members.
+	if g.info == nil {
+		return db.RaftSpare, false, nil
+	}
+
+	isLeader, err := g.isLeader()
+	if err != nil {
+		return g.info.Role, false, err
+	}
+
+	return g.info.Role, isLeader, nil
+}
+
 // DialFunc returns a dial function that can be used to connect to one of the
 // dqlite nodes.
 func (g *Gateway) DialFunc() client.DialFunc {
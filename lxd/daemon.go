@@ -30,6 +30,7 @@ import (
 	"gopkg.in/macaroon-bakery.v2/bakery/identchecker"
 	"gopkg.in/macaroon-bakery.v2/httpbakery"
 
+	"github.com/lxc/lxd/lxd/auth"
 	"github.com/lxc/lxd/lxd/bgp"
 	"github.com/lxc/lxd/lxd/cluster"
 	"github.com/lxc/lxd/lxd/daemon"
@@ -43,9 +44,13 @@ import (
 	"github.com/lxc/lxd/lxd/instance"
 	instanceDrivers "github.com/lxc/lxd/lxd/instance/drivers"
 	"github.com/lxc/lxd/lxd/instance/instancetype"
+	"github.com/lxc/lxd/lxd/loki"
 	"github.com/lxc/lxd/lxd/maas"
+	"github.com/lxc/lxd/lxd/metrics"
 	networkZone "github.com/lxc/lxd/lxd/network/zone"
 	"github.com/lxc/lxd/lxd/node"
+	"github.com/lxc/lxd/lxd/oidc"
+	"github.com/lxc/lxd/lxd/project"
 	"github.com/lxc/lxd/lxd/rbac"
 	"github.com/lxc/lxd/lxd/request"
 	"github.com/lxc/lxd/lxd/response"
@@ -54,7 +59,9 @@ import (
 	storagePools "github.com/lxc/lxd/lxd/storage"
 	storageDrivers "github.com/lxc/lxd/lxd/storage/drivers"
 	"github.com/lxc/lxd/lxd/storage/filesystem"
+	"github.com/lxc/lxd/lxd/storage/s3/miniod"
 	"github.com/lxc/lxd/lxd/sys"
+	"github.com/lxc/lxd/lxd/syslog"
 	"github.com/lxc/lxd/lxd/task"
 	"github.com/lxc/lxd/lxd/ucred"
 	"github.com/lxc/lxd/lxd/util"
@@ -93,6 +100,9 @@ type Daemon struct {
 	// Stores startup time of daemon
 	startTime time.Time
 
+	// Statistics of the API requests, exposed as metrics.
+	apiRequests *metrics.APIRequests
+
 	// Whether daemon was started by systemd socket activation.
 	systemdSocketActivated bool
 
@@ -105,6 +115,13 @@ type Daemon struct {
 
 	externalAuth *externalAuth
 
+	// OpenID Connect token verifier.
+	oidcVerifier *oidc.Verifier
+
+	// External log targets receiving the local events.
+	lokiClient   *loki.Client
+	syslogClient *syslog.Client
+
 	// Stores last heartbeat node information to detect node changes.
 	lastNodeList *cluster.APIHeartbeat
 
@@ -185,6 +202,7 @@ func newDaemon(config *DaemonConfig, os *sys.OS) *Daemon {
 	shutdownCtx, shutdownCancel := context.WithCancel(context.Background())
 
 	d := &Daemon{
+		apiRequests:    metrics.NewAPIRequests(),
 		clientCerts:    &certificateCache{},
 		config:         config,
 		devlxdEvents:   devlxdEvents,
@@ -270,6 +288,102 @@ func allowProjectPermission(feature string, permission string) func(d *Daemon, r
 	}
 }
 
+// allowInstancePermission is a wrapper to check access against the instance and its project. The user needs at
+// least one of the permissions. If the request doesn't target a specific instance then having the permission on
+// any instance of the project is enough and the handler is expected to filter the instances.
+func allowInstancePermission(permissions ...string) func(d *Daemon, r *http.Request) response.Response {
+	return func(d *Daemon, r *http.Request) response.Response {
+		// Shortcut for speed
+		if rbac.UserIsAdmin(r) {
+			return response.EmptySyncResponse
+		}
+
+		projectName := projectParam(r)
+
+		instanceName, err := url.PathUnescape(mux.Vars(r)["name"])
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		for _, permission := range permissions {
+			if instanceName == "" && rbac.UserHasAnyInstancePermission(r, projectName, permission) {
+				return response.EmptySyncResponse
+			}
+
+			if instanceName != "" && rbac.UserHasInstancePermission(r, projectName, instanceName, permission) {
+				return response.EmptySyncResponse
+			}
+		}
+
+		return response.Forbidden(nil)
+	}
+}
+
+// allowNetworkPermission is a wrapper to check access against the network and its project. If the request doesn't
+// target a specific network then having the permission on any network of the project is enough and the handler is
+// expected to filter the networks.
+func allowNetworkPermission(permission string) func(d *Daemon, r *http.Request) response.Response {
+	return func(d *Daemon, r *http.Request) response.Response {
+		// Shortcut for speed
+		if rbac.UserIsAdmin(r) {
+			return response.EmptySyncResponse
+		}
+
+		// Network sub-resources (forwards, load balancers and peers) use a different variable name.
+		networkName := mux.Vars(r)["networkName"]
+		if networkName == "" {
+			networkName = mux.Vars(r)["name"]
+		}
+
+		networkName, err := url.PathUnescape(networkName)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		// Validate whether the user has the needed permission on the project.
+		if rbac.UserHasPermission(r, projectParam(r), permission) {
+			return response.EmptySyncResponse
+		}
+
+		// Permissions on individual networks refer to the effective project of the network.
+		projectName, _, err := project.NetworkProject(d.State().Cluster, projectParam(r))
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		if networkName == "" && rbac.UserHasAnyNetworkPermission(r, projectName, permission) {
+			return response.EmptySyncResponse
+		}
+
+		if networkName != "" && rbac.UserHasNetworkPermission(r, projectName, networkName, permission) {
+			return response.EmptySyncResponse
+		}
+
+		return response.Forbidden(nil)
+	}
+}
+
+// allowStoragePoolPermission is a wrapper to check access against the storage pool.
+func allowStoragePoolPermission(permission string) func(d *Daemon, r *http.Request) response.Response {
+	return func(d *Daemon, r *http.Request) response.Response {
+		// Shortcut for speed
+		if rbac.UserIsAdmin(r) {
+			return response.EmptySyncResponse
+		}
+
+		poolName, err := url.PathUnescape(mux.Vars(r)["name"])
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		if !rbac.UserHasStoragePoolPermission(r, poolName, permission) {
+			return response.Forbidden(nil)
+		}
+
+		return response.EmptySyncResponse
+	}
+}
+
 // Convenience function around Authenticate
 func (d *Daemon) checkTrustedClient(r *http.Request) error {
 	trusted, _, _, err := d.Authenticate(nil, r)
@@ -346,6 +460,18 @@ func (d *Daemon) Authenticate(w http.ResponseWriter, r *http.Request) (bool, str
 		return false, "", "", fmt.Errorf("Bad/missing TLS on network query")
 	}
 
+	if d.oidcVerifier != nil && oidc.IsRequest(r) {
+		// Validate OpenID Connect bearer token.
+		username, err := d.oidcVerifier.Auth(r.Context(), r)
+		if err != nil {
+			// Treat bad tokens as unauthenticated so that the client is asked to login again.
+			logger.Debug("Invalid OpenID Connect token", logger.Ctx{"ip": r.RemoteAddr, "err": err})
+			return false, "", "", nil
+		}
+
+		return true, username, "oidc", nil
+	}
+
 	if d.externalAuth != nil && r.Header.Get(httpbakery.BakeryProtocolHeader) != "" {
 		// Validate external authentication.
 		ctx := httpbakery.ContextWithRequest(context.TODO(), r)
@@ -398,6 +524,14 @@ func (d *Daemon) Authenticate(w http.ResponseWriter, r *http.Request) (bool, str
 	return false, "", "", nil
 }
 
+func writeOIDCRequiredResponse(verifier *oidc.Verifier, w http.ResponseWriter) {
+	w.Header().Set("X-LXD-OIDC-issuer", verifier.Issuer())
+	w.Header().Set("X-LXD-OIDC-clientid", verifier.ClientID())
+	w.Header().Set("X-LXD-OIDC-audience", verifier.Audience())
+
+	response.ErrorResponse(http.StatusUnauthorized, "OpenID Connect authentication required").Render(w)
+}
+
 func writeMacaroonsRequiredResponse(b *identchecker.Bakery, r *http.Request, w http.ResponseWriter, derr *bakery.DischargeRequiredError, expiry int64) {
 	ctx := httpbakery.ContextWithRequest(context.TODO(), r)
 	caveats := append(derr.Caveats,
@@ -477,6 +611,10 @@ func (d *Daemon) createCmd(restAPI *mux.Router, version string, c APIEndpoint) {
 	}
 
 	route := restAPI.HandleFunc(uri, func(w http.ResponseWriter, r *http.Request) {
+		// Record the request in the API metrics.
+		w, requestDone := d.apiRequests.Track(w, r.Method, uri)
+		defer requestDone()
+
 		w.Header().Set("Content-Type", "application/json")
 
 		if !(r.RemoteAddr == "@" && version == "internal") {
@@ -537,6 +675,7 @@ func (d *Daemon) createCmd(restAPI *mux.Router, version string, c APIEndpoint) {
 				if protocol == "tls" {
 					d.clientCerts.Lock.Lock()
 					certProjects := d.clientCerts.Projects
+					certPermissions := d.clientCerts.Permissions
 					d.clientCerts.Lock.Unlock()
 
 					// Check if we have restrictions on the key.
@@ -554,11 +693,30 @@ func (d *Daemon) createCmd(restAPI *mux.Router, version string, c APIEndpoint) {
 									"manage-profiles",
 									"manage-storage-volumes",
 									"operate-containers",
+									"manage-snapshots",
 								}
 							}
 						}
 					}
 
+					// Members of authorization groups only get the permissions of their groups.
+					if certPermissions != nil {
+						permissions, ok := certPermissions[username]
+						if ok {
+							if ua.Admin {
+								ua.Admin = false
+								ua.Projects = map[string][]string{}
+							}
+
+							auth.ApplyPermissions(ua, permissions)
+						}
+					}
+
+					return ua, nil
+				}
+
+				// OpenID Connect users have full access.
+				if protocol == "oidc" {
 					return ua, nil
 				}
 
@@ -601,6 +759,10 @@ func (d *Daemon) createCmd(restAPI *mux.Router, version string, c APIEndpoint) {
 		} else if derr, ok := err.(*bakery.DischargeRequiredError); ok {
 			writeMacaroonsRequiredResponse(d.externalAuth.bakery, r, w, derr, d.externalAuth.expiry)
 			return
+		} else if d.oidcVerifier != nil && r.Header.Get(httpbakery.BakeryProtocolHeader) == "" && (r.TLS == nil || len(r.TLS.PeerCertificates) == 0) {
+			// Let clients not using client certificates know where to login.
+			writeOIDCRequiredResponse(d.oidcVerifier, w)
+			return
 		} else {
 			logger.Warn("Rejecting request from untrusted client", logger.Ctx{"ip": r.RemoteAddr})
 			response.Forbidden(nil).Render(w)
@@ -1081,23 +1243,30 @@ func (d *Daemon) init() error {
 		return fmt.Errorf("Failed to fetch metrics address: %w", err)
 	}
 
+	storageBucketsAddress, err := node.StorageBucketsAddress(d.db)
+	if err != nil {
+		return fmt.Errorf("Failed to fetch storage buckets address: %w", err)
+	}
+
 	if os.Getenv("LISTEN_PID") != "" {
 		d.systemdSocketActivated = true
 	}
 
 	/* Setup the web server */
 	config := &endpoints.Config{
-		Dir:                  d.os.VarDir,
-		UnixSocket:           d.UnixSocket(),
-		Cert:                 networkCert,
-		RestServer:           restServer(d),
-		DevLxdServer:         devLxdServer(d),
-		LocalUnixSocketGroup: d.config.Group,
-		NetworkAddress:       address,
-		ClusterAddress:       clusterAddress,
-		DebugAddress:         debugAddress,
-		MetricsAddress:       metricsAddress,
-		MetricsServer:        metricsServer(d),
+		Dir:                   d.os.VarDir,
+		UnixSocket:            d.UnixSocket(),
+		Cert:                  networkCert,
+		RestServer:            restServer(d),
+		DevLxdServer:          devLxdServer(d),
+		LocalUnixSocketGroup:  d.config.Group,
+		NetworkAddress:        address,
+		ClusterAddress:        clusterAddress,
+		DebugAddress:          debugAddress,
+		MetricsAddress:        metricsAddress,
+		MetricsServer:         metricsServer(d),
+		StorageBucketsAddress: storageBucketsAddress,
+		StorageBucketsServer:  storageBucketsServer(d),
 	}
 	d.endpoints, err = endpoints.Up(config)
 	if err != nil {
@@ -1246,6 +1415,10 @@ func (d *Daemon) init() error {
 
 	dnsAddress := ""
 
+	oidcIssuer := ""
+	oidcClientID := ""
+	oidcAudience := ""
+
 	rbacAPIURL := ""
 	rbacAPIKey := ""
 	rbacAgentURL := ""
@@ -1258,6 +1431,18 @@ func (d *Daemon) init() error {
 	maasAPIKey := ""
 	maasMachine := ""
 
+	lokiURL := ""
+	lokiUsername := ""
+	lokiPassword := ""
+	lokiCACert := ""
+	var lokiLabels []string
+	lokiLoglevel := ""
+	var lokiTypes []string
+
+	syslogAddress := ""
+	syslogLoglevel := ""
+	var syslogTypes []string
+
 	logger.Info("Loading daemon configuration")
 	err = d.db.Transaction(func(tx *db.NodeTx) error {
 		config, err := node.ConfigLoad(tx)
@@ -1288,6 +1473,9 @@ func (d *Daemon) init() error {
 		)
 
 		candidAPIURL, candidAPIKey, candidExpiry, candidDomains = config.CandidServer()
+		oidcIssuer, oidcClientID, oidcAudience = config.OIDCServer()
+		lokiURL, lokiUsername, lokiPassword, lokiCACert, lokiLabels, lokiLoglevel, lokiTypes = config.LokiServer()
+		syslogAddress, syslogLoglevel, syslogTypes = config.SyslogServer()
 		maasAPIURL, maasAPIKey = config.MAASController()
 		rbacAPIURL, rbacAPIKey, rbacExpiry, rbacAgentURL, rbacAgentUsername, rbacAgentPrivateKey, rbacAgentPublicKey = config.RBACServer()
 		d.gateway.HeartbeatOfflineThreshold = config.OfflineThreshold()
@@ -1308,6 +1496,30 @@ func (d *Daemon) init() error {
 		}
 	}
 
+	// Setup the external log targets. Failures aren't fatal so that a broken log target can't prevent
+	// the daemon from starting.
+	if lokiURL != "" {
+		err = d.setupLoki(lokiURL, lokiUsername, lokiPassword, lokiCACert, lokiLabels, lokiLoglevel, lokiTypes)
+		if err != nil {
+			logger.Error("Failed to setup Loki client", logger.Ctx{"err": err})
+		}
+	}
+
+	if syslogAddress != "" {
+		err = d.setupSyslog(syslogAddress, syslogLoglevel, syslogTypes)
+		if err != nil {
+			logger.Error("Failed to setup syslog client", logger.Ctx{"err": err})
+		}
+	}
+
+	// Setup OpenID Connect authentication.
+	if oidcIssuer != "" {
+		err = d.setupOIDC(oidcIssuer, oidcClientID, oidcAudience)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Setup Candid authentication.
 	if candidAPIURL != "" {
 		err = d.setupExternalAuthentication(candidAPIURL, candidAPIKey, candidExpiry, candidDomains)
@@ -1479,6 +1691,9 @@ func (d *Daemon) startClusterTasks() {
 	// Remove orphaned operations
 	d.clusterTasks.Add(autoRemoveOrphanedOperationsTask(d))
 
+	// Relocate instances away from offline members
+	d.clusterTasks.Add(autoHealClusterTask(d))
+
 	// Start all background tasks
 	d.clusterTasks.Start(d.shutdownCtx)
 }
@@ -1625,6 +1840,10 @@ func (d *Daemon) Stop(ctx context.Context, sig os.Signal) error {
 		}
 	}
 
+	// Stop the local storage bucket servers so that their volumes can be unmounted.
+	logger.Info("Stopping storage bucket servers")
+	miniod.StopAll()
+
 	// Handle shutdown (unix.SIGPWR) and reload (unix.SIGTERM) signals.
 	if sig == unix.SIGPWR || sig == unix.SIGTERM {
 		if d.cluster != nil {
@@ -1699,6 +1918,10 @@ func (d *Daemon) Stop(ctx context.Context, sig os.Signal) error {
 	trackError(d.tasks.Stop(3*time.Second), "Stop tasks")                // Give tasks a bit of time to cleanup.
 	trackError(d.clusterTasks.Stop(3*time.Second), "Stop cluster tasks") // Give tasks a bit of time to cleanup.
 
+	// Flush the events queued for the external log targets.
+	trackError(d.setupLoki("", "", "", "", nil, "", nil), "Stop Loki client")
+	trackError(d.setupSyslog("", "", nil), "Stop syslog client")
+
 	n := d.numRunningInstances(instances)
 	shouldUnmount := instances != nil && n <= 0
 
@@ -1750,6 +1973,94 @@ func (d *Daemon) Stop(ctx context.Context, sig os.Signal) error {
 	return err
 }
 
+// Setup OpenID Connect authentication.
+func (d *Daemon) setupOIDC(issuer string, clientID string, audience string) error {
+	// Allow disabling OpenID Connect authentication.
+	// It also stays disabled until both the issuer and client ID are set, as they can only be set one at a time.
+	if issuer == "" || clientID == "" {
+		if issuer != "" || clientID != "" {
+			logger.Warn("OpenID Connect authentication is disabled until both oidc.issuer and oidc.client.id are set")
+		}
+
+		d.oidcVerifier = nil
+		return nil
+	}
+
+	httpClient, err := util.HTTPClient("", d.proxy)
+	if err != nil {
+		return err
+	}
+
+	verifier, err := oidc.NewVerifier(issuer, clientID, audience, httpClient)
+	if err != nil {
+		return err
+	}
+
+	d.oidcVerifier = verifier
+
+	return nil
+}
+
+// Setup the Loki client receiving the local events.
+func (d *Daemon) setupLoki(apiURL string, username string, password string, caCert string, labels []string, logLevel string, types []string) error {
+	// Stop any existing client, flushing its queue.
+	if d.lokiClient != nil {
+		d.events.SetSink("loki", nil)
+		d.lokiClient.Stop()
+		d.lokiClient = nil
+	}
+
+	// Allow disabling the Loki client.
+	if apiURL == "" {
+		return nil
+	}
+
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return err
+	}
+
+	httpClient, err := util.HTTPClient(caCert, d.proxy)
+	if err != nil {
+		return err
+	}
+
+	client, err := loki.NewClient(httpClient, u, username, password, labels, logLevel, types)
+	if err != nil {
+		return err
+	}
+
+	d.lokiClient = client
+	d.events.SetSink("loki", client)
+
+	return nil
+}
+
+// Setup the syslog client receiving the local events.
+func (d *Daemon) setupSyslog(address string, logLevel string, types []string) error {
+	// Stop any existing client, flushing its queue.
+	if d.syslogClient != nil {
+		d.events.SetSink("syslog", nil)
+		d.syslogClient.Stop()
+		d.syslogClient = nil
+	}
+
+	// Allow disabling the syslog client.
+	if address == "" {
+		return nil
+	}
+
+	client, err := syslog.NewClient(address, logLevel, types)
+	if err != nil {
+		return err
+	}
+
+	d.syslogClient = client
+	d.events.SetSink("syslog", client)
+
+	return nil
+}
+
 // Setup external authentication
 func (d *Daemon) setupExternalAuthentication(authEndpoint string, authPubkey string, expiry int64, domains string) error {
 	// Parse the list of domains
@@ -2099,7 +2410,7 @@ func (d *Daemon) nodeRefreshTask(heartbeatData *cluster.APIHeartbeat, isLeader b
 		// Refresh cluster certificates cached.
 		updateCertificateCache(d)
 
-		// Refresh forkdns peers.
+		// Refresh forkdns and EVPN peers.
 		err := networkUpdateForkdnsServersTask(d.State(), heartbeatData)
 		if err != nil {
 			stateChangeTaskFailure = true
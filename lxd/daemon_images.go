@@ -4,16 +4,22 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
+	"gopkg.in/yaml.v2"
+
 	"github.com/lxc/lxd/client"
 	"github.com/lxc/lxd/lxd/cluster"
 	"github.com/lxc/lxd/lxd/db"
 	"github.com/lxc/lxd/lxd/lifecycle"
 	"github.com/lxc/lxd/lxd/locking"
+	"github.com/lxc/lxd/lxd/oci"
 	"github.com/lxc/lxd/lxd/operations"
 	"github.com/lxc/lxd/lxd/request"
 	"github.com/lxc/lxd/lxd/response"
@@ -21,8 +27,10 @@ import (
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/api"
 	"github.com/lxc/lxd/shared/cancel"
+	"github.com/lxc/lxd/shared/instancewriter"
 	"github.com/lxc/lxd/shared/ioprogress"
 	"github.com/lxc/lxd/shared/logger"
+	"github.com/lxc/lxd/shared/osarch"
 	"github.com/lxc/lxd/shared/units"
 	"github.com/lxc/lxd/shared/version"
 )
@@ -60,6 +68,8 @@ func (d *Daemon) ImageDownload(r *http.Request, op *operations.Operation, args *
 
 	var remote lxd.ImageServer
 	var info *api.Image
+	var ociRegistry *oci.Registry
+	var ociImage *oci.Image
 
 	// Default protocol is LXD. Copy so that local modifications aren't propgated to args.
 	protocol := args.Protocol
@@ -117,6 +127,35 @@ func (d *Daemon) ImageDownload(r *http.Request, op *operations.Operation, args *
 
 			fp = info.Fingerprint
 		}
+	} else if protocol == "oci" {
+		if args.Type == "virtual-machine" {
+			return nil, fmt.Errorf("OCI images can only be used for containers")
+		}
+
+		httpClient, err := util.HTTPClient(args.Certificate, d.proxy)
+		if err != nil {
+			return nil, err
+		}
+
+		ociRegistry, err = oci.NewRegistry(args.Server, httpClient, version.UserAgent)
+		if err != nil {
+			return nil, err
+		}
+
+		// Resolve the image for the local architecture.
+		ociImage, err = ociRegistry.GetImage(alias, d.os.Architectures[0])
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting remote image info: %w", err)
+		}
+
+		// The fingerprint is only known once the image is built, so look for one previously built from the
+		// same manifest.
+		cachedFingerprint, err := d.cluster.GetImageFingerprintWithProperty("oci.digest", ociImage.Digest)
+		if err == nil {
+			fp = cachedFingerprint
+		} else if err != db.ErrNoSuchObject {
+			return nil, err
+		}
 	}
 
 	// Ensure we are the only ones operating on this image.
@@ -450,6 +489,16 @@ func (d *Daemon) ImageDownload(r *http.Request, op *operations.Operation, args *
 		info.ExpiresAt = time.Unix(imageMeta.ExpiryDate, 0)
 		info.Properties = imageMeta.Properties
 		info.Type = imageType
+	} else if protocol == "oci" {
+		// Build under a temporary name as the fingerprint of the image isn't known yet.
+		destName = filepath.Join(destDir, fmt.Sprintf("lxd_oci_%s", strings.TrimPrefix(ociImage.Digest, "sha256:")))
+
+		info, err = imageBuildOCI(d, args.ProjectName, ociRegistry, ociImage, destName, args.Budget, progress)
+		if err != nil {
+			return nil, err
+		}
+
+		fp = info.Fingerprint
 	} else {
 		return nil, fmt.Errorf("Unsupported protocol: %v", protocol)
 	}
@@ -531,3 +580,192 @@ func (d *Daemon) ImageDownload(r *http.Request, op *operations.Operation, args *
 
 	return info, nil
 }
+
+// imageBuildOCI unpacks the layers of an OCI image and packs the resulting root filesystem, along with the
+// image's runtime configuration, into a unified LXD image at destName.
+func imageBuildOCI(d *Daemon, projectName string, registry *oci.Registry, img *oci.Image, destName string, budget int64, progress func(ioprogress.ProgressData)) (*api.Image, error) {
+	var size int64
+	for _, layer := range img.Manifest.Layers {
+		size += layer.Size
+	}
+
+	if budget > 0 && size > budget {
+		return nil, fmt.Errorf("Remote image with size %d exceeds allowed bugdget of %d", size, budget)
+	}
+
+	// Unpack the layers next to the other images as they can be large.
+	buildDir, err := ioutil.TempDir(shared.VarPath("images"), "lxd_build_oci_")
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.RemoveAll(buildDir)
+
+	rootfs := filepath.Join(buildDir, "rootfs")
+	err = os.Mkdir(rootfs, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, layer := range img.Manifest.Layers {
+		blob, err := registry.GetBlob(img.Reference.Repository, layer.Digest)
+		if err != nil {
+			return nil, err
+		}
+
+		body := &ioprogress.ProgressReader{
+			ReadCloser: blob,
+			Tracker: &ioprogress.ProgressTracker{
+				Length: layer.Size,
+				Handler: func(percent int64, speed int64) {
+					progress(ioprogress.ProgressData{Text: fmt.Sprintf("layer %d/%d: %d%% (%s/s)", i+1, len(img.Manifest.Layers), percent, units.GetByteSizeString(speed, 2))})
+				},
+			},
+		}
+
+		err = oci.UnpackLayer(body, rootfs)
+		if err == nil {
+			// Read up to the end of the layer for its digest to be verified.
+			_, err = io.Copy(io.Discard, body)
+		}
+
+		blob.Close()
+		if err != nil {
+			return nil, fmt.Errorf("Failed unpacking layer %q: %w", layer.Digest, err)
+		}
+	}
+
+	properties, err := oci.ImageProperties(img.Config, rootfs)
+	if err != nil {
+		return nil, err
+	}
+
+	properties["description"] = img.Reference.String()
+	properties["oci.digest"] = img.Digest
+
+	architecture, err := osarch.ArchitectureName(img.Architecture)
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt := time.Now().UTC()
+	if img.Config.Created != "" {
+		created, err := time.Parse(time.RFC3339Nano, img.Config.Created)
+		if err == nil {
+			createdAt = created.UTC()
+		}
+	}
+
+	meta := api.ImageMetadata{
+		Architecture: architecture,
+		CreationDate: createdAt.Unix(),
+		Properties:   properties,
+	}
+
+	data, err := yaml.Marshal(&meta)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ioutil.WriteFile(filepath.Join(buildDir, "metadata.yaml"), data, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := d.cluster.GetProject(projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	compress := p.Config["images.compression_algorithm"]
+	if compress == "" {
+		compress, err = cluster.ConfigGetString(d.cluster, "images.compression_algorithm")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	imageFile, err := os.Create(destName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer imageFile.Close()
+
+	// Setup tar, optional compress and sha256 to happen in one pass.
+	sha256 := sha256.New()
+	imageWriter := shared.NewQuotaWriter(io.MultiWriter(imageFile, sha256), budget)
+
+	var tarOutput io.Writer = imageWriter
+	var tarPipe *io.PipeWriter
+	wg := sync.WaitGroup{}
+	var compressErr error
+	if compress != "none" {
+		var tarReader *io.PipeReader
+		tarReader, tarPipe = io.Pipe()
+		tarOutput = tarPipe
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			compressErr = compressFile(compress, tarReader, imageWriter)
+
+			// If a compression error occurred, close the reader to end the packing.
+			if compressErr != nil {
+				tarReader.CloseWithError(compressErr)
+			}
+		}()
+	}
+
+	tarWriter := instancewriter.NewInstanceTarWriter(tarOutput, nil)
+	offset := len(buildDir) + 1
+
+	err = filepath.Walk(buildDir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path == buildDir {
+			return nil
+		}
+
+		return tarWriter.WriteFile(path[offset:], path, fi, false)
+	})
+	if err == nil {
+		err = tarWriter.Close()
+	}
+
+	// Closing the pipe lets the compression helper know the tarball is complete.
+	if tarPipe != nil {
+		tarPipe.Close()
+		wg.Wait()
+	}
+
+	if compressErr != nil {
+		return nil, compressErr
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed packing image: %w", err)
+	}
+
+	err = imageFile.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := os.Stat(destName)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &api.Image{}
+	info.Fingerprint = fmt.Sprintf("%x", sha256.Sum(nil))
+	info.Size = fi.Size()
+	info.Architecture = architecture
+	info.CreatedAt = createdAt
+	info.Properties = properties
+	info.Type = "container"
+
+	return info, nil
+}
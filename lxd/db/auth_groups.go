@@ -0,0 +1,234 @@
+//go:build linux && cgo && !agent
+// +build linux,cgo,!agent
+
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/lxc/lxd/lxd/db/query"
+	"github.com/lxc/lxd/shared/api"
+)
+
+// GetAuthGroups returns the names of existing authorization groups.
+func (c *Cluster) GetAuthGroups() ([]string, error) {
+	var groupNames []string
+
+	err := c.Transaction(func(tx *ClusterTx) error {
+		var err error
+		groupNames, err = query.SelectStrings(tx.tx, "SELECT name FROM auth_groups ORDER BY name")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return groupNames, nil
+}
+
+// GetAuthGroup returns the authorization group with the given name.
+func (c *Cluster) GetAuthGroup(name string) (int64, *api.AuthGroup, error) {
+	var id int64 = int64(-1)
+	var permissionsJSON string
+
+	group := api.AuthGroup{
+		AuthGroupPost: api.AuthGroupPost{
+			Name: name,
+		},
+	}
+
+	err := c.Transaction(func(tx *ClusterTx) error {
+		err := tx.tx.QueryRow("SELECT id, description, permissions FROM auth_groups WHERE name=? LIMIT 1", name).Scan(&id, &group.Description, &permissionsJSON)
+		if err != nil {
+			return err
+		}
+
+		group.Identities, err = query.SelectStrings(tx.tx, `
+			SELECT certificates.fingerprint
+			FROM auth_groups_identities
+			JOIN certificates ON certificates.id = auth_groups_identities.certificate_id
+			WHERE auth_groups_identities.auth_group_id=?
+			ORDER BY certificates.fingerprint
+		`, id)
+		if err != nil {
+			return fmt.Errorf("Failed loading identities: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return -1, nil, ErrNoSuchObject
+		}
+
+		return -1, nil, err
+	}
+
+	group.Permissions = []api.AuthPermission{}
+	if permissionsJSON != "" {
+		err = json.Unmarshal([]byte(permissionsJSON), &group.Permissions)
+		if err != nil {
+			return -1, nil, fmt.Errorf("Failed unmarshalling permissions: %w", err)
+		}
+	}
+
+	return id, &group, nil
+}
+
+// GetAuthGroupPermissionsByCertificate returns the permissions granted to each certificate fingerprint by the
+// authorization groups it is a member of.
+func (c *ClusterTx) GetAuthGroupPermissionsByCertificate() (map[string][]api.AuthPermission, error) {
+	q := `
+		SELECT certificates.fingerprint, auth_groups.permissions
+		FROM auth_groups_identities
+		JOIN auth_groups ON auth_groups.id = auth_groups_identities.auth_group_id
+		JOIN certificates ON certificates.id = auth_groups_identities.certificate_id
+	`
+
+	certPermissions := map[string][]api.AuthPermission{}
+
+	err := c.QueryScan(q, func(scan func(dest ...any) error) error {
+		var fingerprint string
+		var permissionsJSON string
+
+		err := scan(&fingerprint, &permissionsJSON)
+		if err != nil {
+			return err
+		}
+
+		permissions := []api.AuthPermission{}
+		if permissionsJSON != "" {
+			err = json.Unmarshal([]byte(permissionsJSON), &permissions)
+			if err != nil {
+				return fmt.Errorf("Failed unmarshalling permissions: %w", err)
+			}
+		}
+
+		// Certificates that are members of groups without permissions are still restricted.
+		certPermissions[fingerprint] = append(certPermissions[fingerprint], permissions...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return certPermissions, nil
+}
+
+// CreateAuthGroup creates a new authorization group.
+func (c *Cluster) CreateAuthGroup(info *api.AuthGroupsPost) (int64, error) {
+	var id int64
+
+	permissionsJSON, err := authGroupPermissionsJSON(info.Permissions)
+	if err != nil {
+		return -1, err
+	}
+
+	err = c.Transaction(func(tx *ClusterTx) error {
+		// Insert a new authorization group record.
+		result, err := tx.tx.Exec("INSERT INTO auth_groups (name, description, permissions) VALUES (?, ?, ?)", info.Name, info.Description, permissionsJSON)
+		if err != nil {
+			return err
+		}
+
+		id, err = result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		return authGroupIdentitiesAdd(tx.tx, id, info.Identities)
+	})
+	if err != nil {
+		id = -1
+	}
+
+	return id, err
+}
+
+// authGroupPermissionsJSON returns the JSON representation of the permissions.
+func authGroupPermissionsJSON(permissions []api.AuthPermission) (string, error) {
+	if permissions == nil {
+		permissions = []api.AuthPermission{}
+	}
+
+	permissionsJSON, err := json.Marshal(permissions)
+	if err != nil {
+		return "", fmt.Errorf("Failed marshalling permissions: %w", err)
+	}
+
+	return string(permissionsJSON), nil
+}
+
+// authGroupIdentitiesAdd adds the certificates with the given fingerprints to the authorization group.
+func authGroupIdentitiesAdd(tx *sql.Tx, id int64, fingerprints []string) error {
+	for _, fingerprint := range fingerprints {
+		result, err := tx.Exec(`
+			INSERT OR IGNORE INTO auth_groups_identities (auth_group_id, certificate_id)
+			SELECT ?, id FROM certificates WHERE fingerprint=?
+		`, id, fingerprint)
+		if err != nil {
+			return fmt.Errorf("Failed adding identity %q: %w", fingerprint, err)
+		}
+
+		// Check that the certificate exists (duplicates are ignored).
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if n == 0 {
+			count, err := query.Count(tx, "certificates", "fingerprint=?", fingerprint)
+			if err != nil {
+				return err
+			}
+
+			if count == 0 {
+				return api.StatusErrorf(http.StatusNotFound, "Certificate %q not found", fingerprint)
+			}
+		}
+	}
+
+	return nil
+}
+
+// UpdateAuthGroup updates the authorization group with the given ID.
+func (c *Cluster) UpdateAuthGroup(id int64, config *api.AuthGroupPut) error {
+	permissionsJSON, err := authGroupPermissionsJSON(config.Permissions)
+	if err != nil {
+		return err
+	}
+
+	return c.Transaction(func(tx *ClusterTx) error {
+		_, err := tx.tx.Exec("UPDATE auth_groups SET description=?, permissions=? WHERE id=?", config.Description, permissionsJSON, id)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.tx.Exec("DELETE FROM auth_groups_identities WHERE auth_group_id=?", id)
+		if err != nil {
+			return err
+		}
+
+		return authGroupIdentitiesAdd(tx.tx, id, config.Identities)
+	})
+}
+
+// RenameAuthGroup renames an authorization group.
+func (c *Cluster) RenameAuthGroup(id int64, newName string) error {
+	return c.Transaction(func(tx *ClusterTx) error {
+		_, err := tx.tx.Exec("UPDATE auth_groups SET name=? WHERE id=?", newName, id)
+		return err
+	})
+}
+
+// DeleteAuthGroup deletes the authorization group.
+func (c *Cluster) DeleteAuthGroup(id int64) error {
+	return c.Transaction(func(tx *ClusterTx) error {
+		_, err := tx.tx.Exec("DELETE FROM auth_groups WHERE id=?", id)
+		return err
+	})
+}
@@ -21,6 +21,7 @@ type InstanceBackup struct {
 	InstanceOnly         bool
 	OptimizedStorage     bool
 	CompressionAlgorithm string
+	IncrementalFrom      string
 }
 
 // StoragePoolVolumeBackup is a value object holding all db-related details about a storage volume backup.
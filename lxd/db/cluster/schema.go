@@ -6,6 +6,20 @@ package cluster
 // modify the database schema, please add a new schema update to update.go
 // and the run 'make update-schema'.
 const freshSchema = `
+CREATE TABLE "auth_groups" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	name TEXT NOT NULL,
+	description TEXT NOT NULL,
+	permissions TEXT NOT NULL,
+	UNIQUE (name)
+);
+CREATE TABLE "auth_groups_identities" (
+	auth_group_id INTEGER NOT NULL,
+	certificate_id INTEGER NOT NULL,
+	FOREIGN KEY (auth_group_id) REFERENCES "auth_groups" (id) ON DELETE CASCADE,
+	FOREIGN KEY (certificate_id) REFERENCES certificates (id) ON DELETE CASCADE,
+	UNIQUE (auth_group_id, certificate_id)
+);
 CREATE TABLE certificates (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     fingerprint TEXT NOT NULL,
@@ -258,6 +272,26 @@ CREATE TABLE "networks_forwards_config" (
 	UNIQUE (network_forward_id, key),
 	FOREIGN KEY (network_forward_id) REFERENCES "networks_forwards" (id) ON DELETE CASCADE
 );
+CREATE TABLE "networks_load_balancers" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	network_id INTEGER NOT NULL,
+	node_id INTEGER,
+	listen_address TEXT NOT NULL,
+	description TEXT NOT NULL,
+	backends TEXT NOT NULL,
+	ports TEXT NOT NULL,
+	UNIQUE (network_id, node_id, listen_address),
+	FOREIGN KEY (network_id) REFERENCES "networks" (id) ON DELETE CASCADE,
+	FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE
+);
+CREATE TABLE "networks_load_balancers_config" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	network_load_balancer_id INTEGER NOT NULL,
+	key VARCHAR(255) NOT NULL,
+	value TEXT,
+	UNIQUE (network_load_balancer_id, key),
+	FOREIGN KEY (network_load_balancer_id) REFERENCES "networks_load_balancers" (id) ON DELETE CASCADE
+);
 CREATE TABLE "networks_nodes" (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     network_id INTEGER NOT NULL,
@@ -275,6 +309,7 @@ CREATE TABLE "networks_peers" (
 	target_network_project TEXT NULL,
 	target_network_name TEXT NULL,
 	target_network_id INTEGER NULL,
+	type INTEGER NOT NULL DEFAULT 0,
 	UNIQUE (network_id, name),
 	UNIQUE (network_id, target_network_project, target_network_name),
 	UNIQUE (network_id, target_network_id),
@@ -288,6 +323,33 @@ CREATE TABLE "networks_peers_config" (
 	UNIQUE (network_peer_id, key),
 	FOREIGN KEY (network_peer_id) REFERENCES "networks_peers" (id) ON DELETE CASCADE
 );
+CREATE TABLE "networks_qos" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	project_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	description TEXT NOT NULL,
+	UNIQUE (project_id, name),
+	FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
+);
+CREATE TABLE "networks_qos_config" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	network_qos_id INTEGER NOT NULL,
+	key VARCHAR(255) NOT NULL,
+	value TEXT,
+	UNIQUE (network_qos_id, key),
+	FOREIGN KEY (network_qos_id) REFERENCES "networks_qos" (id) ON DELETE CASCADE
+);
+CREATE TABLE "networks_reservations" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	network_id INTEGER NOT NULL,
+	address TEXT NOT NULL,
+	description TEXT NOT NULL,
+	project_id INTEGER NOT NULL,
+	instance TEXT NOT NULL,
+	UNIQUE (network_id, address),
+	FOREIGN KEY (network_id) REFERENCES "networks" (id) ON DELETE CASCADE,
+	FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
+);
 CREATE UNIQUE INDEX networks_unique_network_id_node_id_key ON "networks_config" (network_id, IFNULL(node_id, -1), key);
 CREATE TABLE "networks_zones" (
 	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
@@ -419,6 +481,38 @@ CREATE TABLE "projects_config" (
     FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE,
     UNIQUE (project_id, key)
 );
+CREATE TABLE "storage_buckets" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	name TEXT NOT NULL,
+	storage_pool_id INTEGER NOT NULL,
+	node_id INTEGER,
+	description TEXT NOT NULL,
+	project_id INTEGER NOT NULL,
+	FOREIGN KEY (storage_pool_id) REFERENCES "storage_pools" (id) ON DELETE CASCADE,
+	FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE,
+	FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
+);
+CREATE TABLE "storage_buckets_config" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	storage_bucket_id INTEGER NOT NULL,
+	key VARCHAR(255) NOT NULL,
+	value TEXT NOT NULL,
+	UNIQUE (storage_bucket_id, key),
+	FOREIGN KEY (storage_bucket_id) REFERENCES "storage_buckets" (id) ON DELETE CASCADE
+);
+CREATE TABLE "storage_buckets_keys" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	storage_bucket_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	description TEXT NOT NULL,
+	role TEXT NOT NULL,
+	access_key TEXT NOT NULL,
+	secret_key TEXT NOT NULL,
+	UNIQUE (storage_bucket_id, name),
+	UNIQUE (storage_bucket_id, access_key),
+	FOREIGN KEY (storage_bucket_id) REFERENCES "storage_buckets" (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX storage_buckets_unique_storage_pool_id_node_id_name ON "storage_buckets" (storage_pool_id, IFNULL(node_id, -1), name);
 CREATE TABLE "storage_pools" (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     name TEXT NOT NULL,
@@ -562,5 +656,5 @@ CREATE TABLE "warnings" (
 );
 CREATE UNIQUE INDEX warnings_unique_node_id_project_id_entity_type_code_entity_id_type_code ON warnings(IFNULL(node_id, -1), IFNULL(project_id, -1), entity_type_code, entity_id, type_code);
 
-INSERT INTO schema (version, updated_at) VALUES (60, strftime("%s"))
+INSERT INTO schema (version, updated_at) VALUES (66, strftime("%s"))
 `
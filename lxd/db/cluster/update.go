@@ -97,6 +97,170 @@ var updates = map[int]schema.Update{
 	58: updateFromV57,
 	59: updateFromV58,
 	60: updateFromV59,
+	61: updateFromV60,
+	62: updateFromV61,
+	63: updateFromV62,
+	64: updateFromV63,
+	65: updateFromV64,
+	66: updateFromV65,
+}
+
+func updateFromV65(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE networks_peers ADD COLUMN type INTEGER NOT NULL DEFAULT 0;")
+	if err != nil {
+		return fmt.Errorf("Failed adding type column to network peers table: %w", err)
+	}
+
+	return nil
+}
+
+func updateFromV64(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+CREATE TABLE "networks_reservations" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	network_id INTEGER NOT NULL,
+	address TEXT NOT NULL,
+	description TEXT NOT NULL,
+	project_id INTEGER NOT NULL,
+	instance TEXT NOT NULL,
+	UNIQUE (network_id, address),
+	FOREIGN KEY (network_id) REFERENCES "networks" (id) ON DELETE CASCADE,
+	FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return fmt.Errorf("Failed creating network reservations table: %w", err)
+	}
+
+	return nil
+}
+
+func updateFromV63(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+CREATE TABLE "networks_qos" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	project_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	description TEXT NOT NULL,
+	UNIQUE (project_id, name),
+	FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
+);
+
+CREATE TABLE "networks_qos_config" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	network_qos_id INTEGER NOT NULL,
+	key VARCHAR(255) NOT NULL,
+	value TEXT,
+	UNIQUE (network_qos_id, key),
+	FOREIGN KEY (network_qos_id) REFERENCES "networks_qos" (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return fmt.Errorf("Failed creating network QoS tables: %w", err)
+	}
+
+	return nil
+}
+
+func updateFromV62(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+CREATE TABLE "auth_groups" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	name TEXT NOT NULL,
+	description TEXT NOT NULL,
+	permissions TEXT NOT NULL,
+	UNIQUE (name)
+);
+
+CREATE TABLE "auth_groups_identities" (
+	auth_group_id INTEGER NOT NULL,
+	certificate_id INTEGER NOT NULL,
+	FOREIGN KEY (auth_group_id) REFERENCES "auth_groups" (id) ON DELETE CASCADE,
+	FOREIGN KEY (certificate_id) REFERENCES certificates (id) ON DELETE CASCADE,
+	UNIQUE (auth_group_id, certificate_id)
+);
+`)
+	if err != nil {
+		return fmt.Errorf("Failed creating auth group tables: %w", err)
+	}
+
+	return nil
+}
+
+func updateFromV61(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+CREATE TABLE "storage_buckets" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	name TEXT NOT NULL,
+	storage_pool_id INTEGER NOT NULL,
+	node_id INTEGER,
+	description TEXT NOT NULL,
+	project_id INTEGER NOT NULL,
+	FOREIGN KEY (storage_pool_id) REFERENCES "storage_pools" (id) ON DELETE CASCADE,
+	FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE,
+	FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX storage_buckets_unique_storage_pool_id_node_id_name ON "storage_buckets" (storage_pool_id, IFNULL(node_id, -1), name);
+
+CREATE TABLE "storage_buckets_config" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	storage_bucket_id INTEGER NOT NULL,
+	key VARCHAR(255) NOT NULL,
+	value TEXT NOT NULL,
+	UNIQUE (storage_bucket_id, key),
+	FOREIGN KEY (storage_bucket_id) REFERENCES "storage_buckets" (id) ON DELETE CASCADE
+);
+
+CREATE TABLE "storage_buckets_keys" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	storage_bucket_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	description TEXT NOT NULL,
+	role TEXT NOT NULL,
+	access_key TEXT NOT NULL,
+	secret_key TEXT NOT NULL,
+	UNIQUE (storage_bucket_id, name),
+	UNIQUE (storage_bucket_id, access_key),
+	FOREIGN KEY (storage_bucket_id) REFERENCES "storage_buckets" (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return fmt.Errorf("Failed creating storage bucket tables: %w", err)
+	}
+
+	return nil
+}
+
+func updateFromV60(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+CREATE TABLE "networks_load_balancers" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	network_id INTEGER NOT NULL,
+	node_id INTEGER,
+	listen_address TEXT NOT NULL,
+	description TEXT NOT NULL,
+	backends TEXT NOT NULL,
+	ports TEXT NOT NULL,
+	UNIQUE (network_id, node_id, listen_address),
+	FOREIGN KEY (network_id) REFERENCES "networks" (id) ON DELETE CASCADE,
+	FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE
+);
+
+CREATE TABLE "networks_load_balancers_config" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	network_load_balancer_id INTEGER NOT NULL,
+	key VARCHAR(255) NOT NULL,
+	value TEXT,
+	UNIQUE (network_load_balancer_id, key),
+	FOREIGN KEY (network_load_balancer_id) REFERENCES "networks_load_balancers" (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return fmt.Errorf("Failed creating network load balancer tables: %w", err)
+	}
+
+	return nil
 }
 
 func updateFromV59(tx *sql.Tx) error {
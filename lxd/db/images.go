@@ -66,6 +66,7 @@ var ImageSourceProtocol = map[int]string{
 	0: "lxd",
 	1: "direct",
 	2: "simplestreams",
+	3: "oci",
 }
 
 // GetLocalImagesFingerprints returns the fingerprints of all local images.
@@ -389,6 +390,34 @@ func (c *Cluster) GetCachedImageSourceFingerprint(server string, protocol string
 	return fingerprints[0], nil
 }
 
+// GetImageFingerprintWithProperty returns the fingerprint of the most recent image, in any project, which
+// has the given property set to the given value.
+func (c *Cluster) GetImageFingerprintWithProperty(key string, value string) (string, error) {
+	q := `
+SELECT images.fingerprint
+  FROM images_properties
+  JOIN images ON images.id = images_properties.image_id
+ WHERE images_properties.key = ? AND images_properties.value = ?
+ ORDER BY images.creation_date DESC
+`
+
+	var fingerprints []string
+	err := c.Transaction(func(tx *ClusterTx) error {
+		var err error
+		fingerprints, err = query.SelectStrings(tx.tx, q, key, value)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(fingerprints) == 0 {
+		return "", ErrNoSuchObject
+	}
+
+	return fingerprints[0], nil
+}
+
 // ImageExists returns whether an image with the given fingerprint exists.
 func (c *Cluster) ImageExists(project string, fingerprint string) (bool, error) {
 	table := "images JOIN projects ON projects.id = images.project_id"
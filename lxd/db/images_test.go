@@ -60,6 +60,26 @@ func TestImageExists(t *testing.T) {
 	assert.True(t, exists)
 }
 
+func TestGetImageFingerprintWithProperty(t *testing.T) {
+	cluster, cleanup := db.NewTestCluster(t)
+	defer cleanup()
+
+	_, err := cluster.GetImageFingerprintWithProperty("oci.digest", "sha256:abc")
+	assert.Equal(t, db.ErrNoSuchObject, err)
+
+	err = cluster.CreateImage(
+		"default", "abc", "x.gz", 16, false, false, "amd64", time.Now(), time.Now(), map[string]string{"oci.digest": "sha256:abc"}, "container")
+	require.NoError(t, err)
+
+	err = cluster.CreateImage(
+		"default", "def", "x.gz", 16, false, false, "amd64", time.Now(), time.Now(), map[string]string{"oci.digest": "sha256:def"}, "container")
+	require.NoError(t, err)
+
+	fingerprint, err := cluster.GetImageFingerprintWithProperty("oci.digest", "sha256:abc")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", fingerprint)
+}
+
 func TestGetImage(t *testing.T) {
 	cluster, cleanup := db.NewTestCluster(t)
 	defer cleanup()
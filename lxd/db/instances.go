@@ -448,9 +448,9 @@ SELECT instances.name, nodes.name, projects.name
 }
 
 // UpdateInstanceNode changes the name of an instance and the cluster member hosting it.
-// It's meant to be used when moving a non-running instance backed by ceph from one cluster node to another.
+// It's meant to be used when moving a non-running instance backed by remote storage from one cluster node to another.
 func (c *ClusterTx) UpdateInstanceNode(project, oldName string, newName string, newNode string, volumeType int) error {
-	// First check that the container to be moved is backed by a ceph
+	// First check that the container to be moved is backed by a remote
 	// volume.
 	poolName, err := c.GetInstancePool(project, oldName)
 	if err != nil {
@@ -467,8 +467,8 @@ func (c *ClusterTx) UpdateInstanceNode(project, oldName string, newName string,
 		return fmt.Errorf("Failed to get instance's storage pool driver: %w", err)
 	}
 
-	if poolDriver != "ceph" {
-		return fmt.Errorf("Instance's storage pool is not of type ceph")
+	if !shared.StringInSlice(poolDriver, StorageRemoteDriverNames()) {
+		return fmt.Errorf("Instance's storage pool is not remote")
 	}
 
 	// Update the name of the container and of its snapshots, and the node
@@ -521,6 +521,28 @@ func (c *ClusterTx) UpdateInstanceNode(project, oldName string, newName string,
 	return nil
 }
 
+// UpdateInstanceLocation changes the cluster member hosting an instance.
+// It's meant to be used when live migrating an instance from one cluster member to another, in which case the
+// storage volume records are managed separately.
+func (c *ClusterTx) UpdateInstanceLocation(project string, name string, newNode string) error {
+	id, err := c.GetInstanceID(project, name)
+	if err != nil {
+		return fmt.Errorf("Failed to get instance's ID: %w", err)
+	}
+
+	node, err := c.GetNodeByName(newNode)
+	if err != nil {
+		return fmt.Errorf("Failed to get new node's info: %w", err)
+	}
+
+	_, err = c.tx.Exec("UPDATE instances SET node_id=? WHERE id=?", node.ID, id)
+	if err != nil {
+		return fmt.Errorf("Failed to update instance's node ID: %w", err)
+	}
+
+	return nil
+}
+
 // GetLocalInstancesInProject retuurns all instances of the given type on the local node within the given project.
 // If projectName is empty then all instances in all projects are returned.
 func (c *ClusterTx) GetLocalInstancesInProject(filter InstanceFilter) ([]Instance, error) {
@@ -12,6 +12,32 @@ import (
 	"github.com/lxc/lxd/shared/api"
 )
 
+// NetworkPeerType indicates the type of network peer.
+type NetworkPeerType int
+
+// Network peer types.
+const (
+	NetworkPeerTypeNetwork NetworkPeerType = iota // Network peer type network (OVN network).
+	NetworkPeerTypeUplink                         // Network peer type uplink (routed subnets of physical uplink).
+)
+
+// NetworkPeerTypes maps the network peer type names to their database values.
+var NetworkPeerTypes = map[string]NetworkPeerType{
+	"network": NetworkPeerTypeNetwork,
+	"uplink":  NetworkPeerTypeUplink,
+}
+
+// networkPeerTypeName returns the name of the network peer type.
+func networkPeerTypeName(peerType NetworkPeerType) string {
+	for name, value := range NetworkPeerTypes {
+		if value == peerType {
+			return name
+		}
+	}
+
+	return ""
+}
+
 // CreateNetworkPeer creates a new Network Peer and returns its ID.
 // If there is a mutual peering on the target network side the both peer entries are upated to link to each other's
 // repspective network ID.
@@ -119,6 +145,43 @@ func (c *Cluster) CreateNetworkPeer(networkID int64, info *api.NetworkPeersPost)
 	return localPeerID, targetPeerNetworkID > -1, err
 }
 
+// CreateNetworkPeerUplink creates a new Network Peer with the routed subnets of the given uplink network and
+// returns its ID. As the uplink network is managed by the server administrator, no mutual peering is required and
+// the peer is linked to the uplink network ID straight away.
+func (c *Cluster) CreateNetworkPeerUplink(networkID int64, uplinkNetworkID int64, info *api.NetworkPeersPost) (int64, error) {
+	var peerID int64
+
+	err := c.Transaction(func(tx *ClusterTx) error {
+		// Insert a new Network uplink peer record.
+		result, err := tx.tx.Exec(`
+		INSERT INTO networks_peers
+		(network_id, name, description, target_network_id, type)
+		VALUES (?, ?, ?, ?, ?)
+		`, networkID, info.Name, info.Description, uplinkNetworkID, NetworkPeerTypeUplink)
+		if err != nil {
+			return err
+		}
+
+		peerID, err = result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		// Save config.
+		err = networkPeerConfigAdd(tx.tx, peerID, info.Config)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	return peerID, nil
+}
+
 // networkPeerConfigAdd inserts Network peer config keys.
 func networkPeerConfigAdd(tx *sql.Tx, peerID int64, config map[string]string) error {
 	stmt, err := tx.Prepare(`
@@ -153,6 +216,8 @@ func (c *Cluster) GetNetworkPeer(networkID int64, peerName string) (int64, *api.
 	// If the peer is not mutually configured, then the local target_network_project and target_network_name
 	// fields will be used to populate TargetProject and TargetNetwork and the Status will be set to
 	// api.NetworkStatusPending.
+	// Uplink peers are linked to the uplink network directly, so the uplink network's project and network names
+	// are used in place of the mutual target peer's ones.
 	q := `
 	SELECT
 		local_peer.id,
@@ -160,8 +225,9 @@ func (c *Cluster) GetNetworkPeer(networkID int64, peerName string) (int64, *api.
 		local_peer.description,
 		IFNULL(local_peer.target_network_project, ""),
 		IFNULL(local_peer.target_network_name, ""),
-		IFNULL(target_peer_network.name, "") AS target_peer_network_name,
-		IFNULL(target_peer_project.name, "") AS target_peer_network_project
+		COALESCE(target_peer_network.name, uplink_network.name, "") AS target_peer_network_name,
+		COALESCE(target_peer_project.name, uplink_project.name, "") AS target_peer_network_project,
+		local_peer.type
 	FROM networks_peers AS local_peer
 	LEFT JOIN networks_peers AS target_peer
 		ON target_peer.network_id = local_peer.target_network_id
@@ -170,6 +236,11 @@ func (c *Cluster) GetNetworkPeer(networkID int64, peerName string) (int64, *api.
 		ON target_peer.network_id = target_peer_network.id
 	LEFT JOIN projects AS target_peer_project
 		ON target_peer_network.project_id = target_peer_project.id
+	LEFT JOIN networks AS uplink_network
+		ON local_peer.type = ?
+		AND uplink_network.id = local_peer.target_network_id
+	LEFT JOIN projects AS uplink_project
+		ON uplink_network.project_id = uplink_project.id
 	WHERE local_peer.network_id = ? AND local_peer.name = ?
 	LIMIT 1
 	`
@@ -179,9 +250,10 @@ func (c *Cluster) GetNetworkPeer(networkID int64, peerName string) (int64, *api.
 	var peer api.NetworkPeer
 	var targetPeerNetworkName string
 	var targetPeerNetworkProject string
+	var peerType NetworkPeerType
 
 	err = c.Transaction(func(tx *ClusterTx) error {
-		err = tx.tx.QueryRow(q, networkID, peerName).Scan(&peerID, &peer.Name, &peer.Description, &peer.TargetProject, &peer.TargetNetwork, &targetPeerNetworkName, &targetPeerNetworkProject)
+		err = tx.tx.QueryRow(q, NetworkPeerTypeUplink, networkID, peerName).Scan(&peerID, &peer.Name, &peer.Description, &peer.TargetProject, &peer.TargetNetwork, &targetPeerNetworkName, &targetPeerNetworkProject, &peerType)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				return api.StatusErrorf(http.StatusNotFound, "Network peer not found")
@@ -202,6 +274,7 @@ func (c *Cluster) GetNetworkPeer(networkID int64, peerName string) (int64, *api.
 	}
 
 	networkPeerPopulatePeerInfo(&peer, targetPeerNetworkProject, targetPeerNetworkName)
+	peer.Type = networkPeerTypeName(peerType)
 
 	return peerID, &peer, nil
 }
@@ -274,6 +347,8 @@ func (c *Cluster) GetNetworkPeers(networkID int64) (map[int64]*api.NetworkPeer,
 	// If the peer is not mutually configured, then the local target_network_project and target_network_name
 	// fields will be used to populate TargetProject and TargetNetwork and the Status will be set to
 	// api.NetworkStatusPending.
+	// Uplink peers are linked to the uplink network directly, so the uplink network's project and network names
+	// are used in place of the mutual target peer's ones.
 	q := `
 	SELECT
 		local_peer.id,
@@ -281,8 +356,9 @@ func (c *Cluster) GetNetworkPeers(networkID int64) (map[int64]*api.NetworkPeer,
 		local_peer.description,
 		IFNULL(local_peer.target_network_project, ""),
 		IFNULL(local_peer.target_network_name, ""),
-		IFNULL(target_peer_network.name, "") AS target_peer_network_name,
-		IFNULL(target_peer_project.name, "") AS target_peer_network_project
+		COALESCE(target_peer_network.name, uplink_network.name, "") AS target_peer_network_name,
+		COALESCE(target_peer_project.name, uplink_project.name, "") AS target_peer_network_project,
+		local_peer.type
 	FROM networks_peers AS local_peer
 	LEFT JOIN networks_peers AS target_peer
 		ON target_peer.network_id = local_peer.target_network_id
@@ -291,6 +367,11 @@ func (c *Cluster) GetNetworkPeers(networkID int64) (map[int64]*api.NetworkPeer,
 		ON target_peer.network_id = target_peer_network.id
 	LEFT JOIN projects AS target_peer_project
 		ON target_peer_network.project_id = target_peer_project.id
+	LEFT JOIN networks AS uplink_network
+		ON local_peer.type = ?
+		AND uplink_network.id = local_peer.target_network_id
+	LEFT JOIN projects AS uplink_project
+		ON uplink_network.project_id = uplink_project.id
 	WHERE local_peer.network_id = ?
 	`
 
@@ -303,18 +384,20 @@ func (c *Cluster) GetNetworkPeers(networkID int64) (map[int64]*api.NetworkPeer,
 			var peer api.NetworkPeer
 			var targetPeerNetworkName string
 			var targetPeerNetworkProject string
+			var peerType NetworkPeerType
 
-			err := scan(&peerID, &peer.Name, &peer.Description, &peer.TargetProject, &peer.TargetNetwork, &targetPeerNetworkName, &targetPeerNetworkProject)
+			err := scan(&peerID, &peer.Name, &peer.Description, &peer.TargetProject, &peer.TargetNetwork, &targetPeerNetworkName, &targetPeerNetworkProject, &peerType)
 			if err != nil {
 				return err
 			}
 
 			networkPeerPopulatePeerInfo(&peer, targetPeerNetworkProject, targetPeerNetworkName)
+			peer.Type = networkPeerTypeName(peerType)
 
 			peers[peerID] = &peer
 
 			return nil
-		}, networkID)
+		}, NetworkPeerTypeUplink, networkID)
 		if err != nil {
 			return err
 		}
@@ -370,6 +453,66 @@ func (c *Cluster) GetNetworkPeerNames(networkID int64) (map[int64]string, error)
 	return peers, nil
 }
 
+// GetNetworkPeerInvitations returns the pending Network Peers of other networks that target the given project and
+// network name, and for which no mutual peering exists yet.
+func (c *Cluster) GetNetworkPeerInvitations(projectName string, networkName string) ([]api.NetworkPeerInvitation, error) {
+	q := `
+	SELECT
+		networks_peers.name,
+		networks_peers.description,
+		projects.name,
+		networks.name
+	FROM networks_peers
+	JOIN networks ON networks.id = networks_peers.network_id
+	JOIN projects ON projects.id = networks.project_id
+	WHERE networks_peers.target_network_project = ?
+		AND networks_peers.target_network_name = ?
+		AND networks_peers.target_network_id IS NULL
+	ORDER BY projects.name, networks.name, networks_peers.name
+	`
+
+	invitations := []api.NetworkPeerInvitation{}
+
+	err := c.Transaction(func(tx *ClusterTx) error {
+		return tx.QueryScan(q, func(scan func(dest ...any) error) error {
+			var invitation api.NetworkPeerInvitation
+
+			err := scan(&invitation.Name, &invitation.Description, &invitation.SourceProject, &invitation.SourceNetwork)
+			if err != nil {
+				return err
+			}
+
+			invitations = append(invitations, invitation)
+
+			return nil
+		}, projectName, networkName)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+// GetNetworkPeersUplinkCount returns the number of uplink Network Peers (in any project) that peer with the given
+// uplink network ID.
+func (c *Cluster) GetNetworkPeersUplinkCount(uplinkNetworkID int64) (int, error) {
+	var count int
+
+	err := c.Transaction(func(tx *ClusterTx) error {
+		return tx.tx.QueryRow(`
+		SELECT COUNT(*)
+		FROM networks_peers
+		WHERE type = ? AND target_network_id = ?
+		`, NetworkPeerTypeUplink, uplinkNetworkID).Scan(&count)
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	return count, nil
+}
+
 // UpdateNetworkPeer updates an existing Network Peer.
 func (c *Cluster) UpdateNetworkPeer(networkID int64, peerID int64, info *api.NetworkPeerPut) error {
 	var err error
@@ -0,0 +1,125 @@
+//go:build linux && cgo && !agent
+// +build linux,cgo,!agent
+
+package db_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/shared/api"
+)
+
+// A peering invitation from a network in another project is listed on the target network until the peering is
+// accepted by creating the mutual peer.
+func TestNetworkPeerInvitation(t *testing.T) {
+	cluster, cleanup := db.NewTestCluster(t)
+	defer cleanup()
+
+	err := cluster.Transaction(func(tx *db.ClusterTx) error {
+		_, err := tx.CreateProject(db.Project{Name: "tenant1", Config: map[string]string{"features.networks": "true"}})
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.CreateProject(db.Project{Name: "tenant2", Config: map[string]string{"features.networks": "true"}})
+		return err
+	})
+	require.NoError(t, err)
+
+	net1ID, err := cluster.CreateNetwork("tenant1", "net1", "", db.NetworkTypeOVN, nil)
+	require.NoError(t, err)
+
+	net2ID, err := cluster.CreateNetwork("tenant2", "net2", "", db.NetworkTypeOVN, nil)
+	require.NoError(t, err)
+
+	// Invite net2 to peer with net1.
+	_, mutual, err := cluster.CreateNetworkPeer(net1ID, &api.NetworkPeersPost{
+		Name:          "to-net2",
+		TargetProject: "tenant2",
+		TargetNetwork: "net2",
+		NetworkPeerPut: api.NetworkPeerPut{
+			Description: "Invitation from tenant1",
+		},
+	})
+	require.NoError(t, err)
+	assert.False(t, mutual)
+
+	_, peer, err := cluster.GetNetworkPeer(net1ID, "to-net2")
+	require.NoError(t, err)
+	assert.Equal(t, api.NetworkStatusPending, peer.Status)
+	assert.Equal(t, "network", peer.Type)
+
+	invitations, err := cluster.GetNetworkPeerInvitations("tenant2", "net2")
+	require.NoError(t, err)
+	assert.Equal(t, []api.NetworkPeerInvitation{{
+		Name:          "to-net2",
+		Description:   "Invitation from tenant1",
+		SourceProject: "tenant1",
+		SourceNetwork: "net1",
+	}}, invitations)
+
+	// Accept the invitation.
+	_, mutual, err = cluster.CreateNetworkPeer(net2ID, &api.NetworkPeersPost{
+		Name:          "to-net1",
+		TargetProject: "tenant1",
+		TargetNetwork: "net1",
+	})
+	require.NoError(t, err)
+	assert.True(t, mutual)
+
+	for networkID, peerName := range map[int64]string{net1ID: "to-net2", net2ID: "to-net1"} {
+		_, peer, err := cluster.GetNetworkPeer(networkID, peerName)
+		require.NoError(t, err)
+		assert.Equal(t, api.NetworkStatusCreated, peer.Status)
+	}
+
+	invitations, err = cluster.GetNetworkPeerInvitations("tenant2", "net2")
+	require.NoError(t, err)
+	assert.Len(t, invitations, 0)
+}
+
+// An uplink peer is linked to the uplink network straight away.
+func TestNetworkPeerUplink(t *testing.T) {
+	cluster, cleanup := db.NewTestCluster(t)
+	defer cleanup()
+
+	uplinkID, err := cluster.CreateNetwork(project.Default, "uplink", "", db.NetworkTypePhysical, nil)
+	require.NoError(t, err)
+
+	netID, err := cluster.CreateNetwork(project.Default, "ovn1", "", db.NetworkTypeOVN, map[string]string{"network": "uplink"})
+	require.NoError(t, err)
+
+	_, err = cluster.CreateNetworkPeerUplink(netID, uplinkID, &api.NetworkPeersPost{
+		Name: "routed",
+		NetworkPeerPut: api.NetworkPeerPut{
+			Config: map[string]string{"user.foo": "bar"},
+		},
+	})
+	require.NoError(t, err)
+
+	peers, err := cluster.GetNetworkPeers(netID)
+	require.NoError(t, err)
+	require.Len(t, peers, 1)
+
+	for _, peer := range peers {
+		assert.Equal(t, "routed", peer.Name)
+		assert.Equal(t, "uplink", peer.Type)
+		assert.Equal(t, api.NetworkStatusCreated, peer.Status)
+		assert.Equal(t, project.Default, peer.TargetProject)
+		assert.Equal(t, "uplink", peer.TargetNetwork)
+		assert.Equal(t, map[string]string{"user.foo": "bar"}, peer.Config)
+	}
+
+	count, err := cluster.GetNetworkPeersUplinkCount(uplinkID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	targetNetIDs, err := cluster.GetNetworkPeersTargetNetworkIDs(project.Default, db.NetworkTypeOVN)
+	require.NoError(t, err)
+	assert.Equal(t, map[db.NetworkPeer]int64{{NetworkName: "ovn1", PeerName: "routed"}: uplinkID}, targetNetIDs)
+}
@@ -0,0 +1,224 @@
+//go:build linux && cgo && !agent
+// +build linux,cgo,!agent
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lxc/lxd/lxd/db/query"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/version"
+)
+
+// GetNetworkQoSPolicies returns the names of existing network QoS policies.
+func (c *Cluster) GetNetworkQoSPolicies(project string) ([]string, error) {
+	q := `SELECT name FROM networks_qos
+		WHERE project_id = (SELECT id FROM projects WHERE name = ? LIMIT 1)
+		ORDER BY id
+	`
+
+	var names []string
+
+	err := c.Transaction(func(tx *ClusterTx) error {
+		return tx.QueryScan(q, func(scan func(dest ...any) error) error {
+			var name string
+
+			err := scan(&name)
+			if err != nil {
+				return err
+			}
+
+			names = append(names, name)
+
+			return nil
+		}, project)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// GetNetworkQoSPolicy returns the network QoS policy with the given name in the given project.
+func (c *Cluster) GetNetworkQoSPolicy(projectName string, name string) (int64, *api.NetworkQoSPolicy, error) {
+	var id int64 = int64(-1)
+
+	policy := api.NetworkQoSPolicy{
+		NetworkQoSPolicyPost: api.NetworkQoSPolicyPost{
+			Name: name,
+		},
+	}
+
+	q := `
+		SELECT id, description
+		FROM networks_qos
+		WHERE project_id = (SELECT id FROM projects WHERE name = ? LIMIT 1) AND name=?
+		LIMIT 1
+	`
+
+	err := c.Transaction(func(tx *ClusterTx) error {
+		err := tx.tx.QueryRow(q, projectName, name).Scan(&id, &policy.Description)
+		if err != nil {
+			return err
+		}
+
+		err = networkQoSPolicyConfig(tx, id, &policy)
+		if err != nil {
+			return fmt.Errorf("Failed loading config: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return -1, nil, ErrNoSuchObject
+		}
+
+		return -1, nil, err
+	}
+
+	return id, &policy, nil
+}
+
+// networkQoSPolicyConfig populates the config map of the network QoS policy with the given ID.
+func networkQoSPolicyConfig(tx *ClusterTx, id int64, policy *api.NetworkQoSPolicy) error {
+	q := `
+		SELECT key, value
+		FROM networks_qos_config
+		WHERE network_qos_id=?
+	`
+
+	policy.Config = make(map[string]string)
+	return tx.QueryScan(q, func(scan func(dest ...any) error) error {
+		var key, value string
+
+		err := scan(&key, &value)
+		if err != nil {
+			return err
+		}
+
+		_, found := policy.Config[key]
+		if found {
+			return fmt.Errorf("Duplicate config row found for key %q for network QoS policy ID %d", key, id)
+		}
+
+		policy.Config[key] = value
+
+		return nil
+	}, id)
+}
+
+// CreateNetworkQoSPolicy creates a new network QoS policy.
+func (c *Cluster) CreateNetworkQoSPolicy(projectName string, info *api.NetworkQoSPoliciesPost) (int64, error) {
+	var id int64
+
+	err := c.Transaction(func(tx *ClusterTx) error {
+		// Insert a new network QoS policy record.
+		result, err := tx.tx.Exec(`
+			INSERT INTO networks_qos (project_id, name, description)
+			VALUES ((SELECT id FROM projects WHERE name = ? LIMIT 1), ?, ?)
+		`, projectName, info.Name, info.Description)
+		if err != nil {
+			return err
+		}
+
+		id, err = result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		err = networkQoSPolicyConfigAdd(tx.tx, id, info.Config)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		id = -1
+	}
+
+	return id, err
+}
+
+// networkQoSPolicyConfigAdd inserts network QoS policy config keys.
+func networkQoSPolicyConfigAdd(tx *sql.Tx, id int64, config map[string]string) error {
+	sql := "INSERT INTO networks_qos_config (network_qos_id, key, value) VALUES(?, ?, ?)"
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	for k, v := range config {
+		if v == "" {
+			continue
+		}
+
+		_, err = stmt.Exec(id, k, v)
+		if err != nil {
+			return fmt.Errorf("Failed inserting config: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// UpdateNetworkQoSPolicy updates the network QoS policy with the given ID.
+func (c *Cluster) UpdateNetworkQoSPolicy(id int64, config *api.NetworkQoSPolicyPut) error {
+	return c.Transaction(func(tx *ClusterTx) error {
+		_, err := tx.tx.Exec("UPDATE networks_qos SET description=? WHERE id=?", config.Description, id)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.tx.Exec("DELETE FROM networks_qos_config WHERE network_qos_id=?", id)
+		if err != nil {
+			return err
+		}
+
+		err = networkQoSPolicyConfigAdd(tx.tx, id, config.Config)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// RenameNetworkQoSPolicy renames a network QoS policy.
+func (c *Cluster) RenameNetworkQoSPolicy(id int64, newName string) error {
+	return c.Transaction(func(tx *ClusterTx) error {
+		_, err := tx.tx.Exec("UPDATE networks_qos SET name=? WHERE id=?", newName, id)
+		return err
+	})
+}
+
+// DeleteNetworkQoSPolicy deletes the network QoS policy.
+func (c *Cluster) DeleteNetworkQoSPolicy(id int64) error {
+	return c.Transaction(func(tx *ClusterTx) error {
+		_, err := tx.tx.Exec("DELETE FROM networks_qos WHERE id=?", id)
+		return err
+	})
+}
+
+// GetNetworkQoSPolicyURIs returns the URIs for the network QoS policies with the given project.
+func (c *ClusterTx) GetNetworkQoSPolicyURIs(projectID int, project string) ([]string, error) {
+	sql := `SELECT networks_qos.name from networks_qos WHERE networks_qos.project_id = ?`
+
+	names, err := query.SelectStrings(c.tx, sql, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get URIs for network QoS policy: %w", err)
+	}
+
+	uris := make([]string, len(names))
+	for i := range names {
+		uris[i] = api.NewURL().Path(version.APIVersion, "network-qos", names[i]).Project(project).String()
+	}
+
+	return uris, nil
+}
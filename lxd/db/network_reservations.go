@@ -0,0 +1,164 @@
+//go:build linux && cgo && !agent
+// +build linux,cgo,!agent
+
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/lxc/lxd/shared/api"
+)
+
+// CreateNetworkReservation creates a new Network address reservation.
+// The reservation is associated to the given project, which is the project of the instance it is reserved for.
+func (c *Cluster) CreateNetworkReservation(networkID int64, projectName string, info *api.NetworkReservationsPost) (int64, error) {
+	var reservationID int64
+
+	err := c.Transaction(func(tx *ClusterTx) error {
+		// Insert a new Network reservation record.
+		result, err := tx.tx.Exec(`
+		INSERT INTO networks_reservations
+		(network_id, address, description, project_id, instance)
+		VALUES (?, ?, ?, (SELECT id FROM projects WHERE name = ?), ?)
+		`, networkID, info.Address, info.Description, projectName, info.Instance)
+		if err != nil {
+			return err
+		}
+
+		reservationID, err = result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	return reservationID, nil
+}
+
+// UpdateNetworkReservation updates an existing Network address reservation.
+func (c *Cluster) UpdateNetworkReservation(networkID int64, reservationID int64, info *api.NetworkReservationPut) error {
+	return c.Transaction(func(tx *ClusterTx) error {
+		// Update existing Network reservation record.
+		res, err := tx.tx.Exec(`
+		UPDATE networks_reservations
+		SET description = ?, instance = ?
+		WHERE network_id = ? and id = ?
+		`, info.Description, info.Instance, networkID, reservationID)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected <= 0 {
+			return api.StatusErrorf(http.StatusNotFound, "Network reservation not found")
+		}
+
+		return nil
+	})
+}
+
+// DeleteNetworkReservation deletes an existing Network address reservation.
+func (c *Cluster) DeleteNetworkReservation(networkID int64, reservationID int64) error {
+	return c.Transaction(func(tx *ClusterTx) error {
+		// Delete existing Network reservation record.
+		res, err := tx.tx.Exec(`
+			DELETE FROM networks_reservations
+			WHERE network_id = ? and id = ?
+		`, networkID, reservationID)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected <= 0 {
+			return api.StatusErrorf(http.StatusNotFound, "Network reservation not found")
+		}
+
+		return nil
+	})
+}
+
+// GetNetworkReservation returns the Network address reservation ID and info for the given network ID and address.
+func (c *Cluster) GetNetworkReservation(networkID int64, address string) (int64, *api.NetworkReservation, error) {
+	q := `
+	SELECT
+		networks_reservations.id,
+		networks_reservations.address,
+		networks_reservations.description,
+		projects.name,
+		networks_reservations.instance
+	FROM networks_reservations
+	JOIN projects ON projects.id = networks_reservations.project_id
+	WHERE networks_reservations.network_id = ? AND networks_reservations.address = ?
+	`
+
+	var reservationID int64 = int64(-1)
+	var reservation api.NetworkReservation
+
+	err := c.Transaction(func(tx *ClusterTx) error {
+		err := tx.tx.QueryRow(q, networkID, address).Scan(&reservationID, &reservation.Address, &reservation.Description, &reservation.Project, &reservation.Instance)
+		if errors.Is(err, sql.ErrNoRows) {
+			return api.StatusErrorf(http.StatusNotFound, "Network reservation not found")
+		}
+
+		return err
+	})
+	if err != nil {
+		return -1, nil, err
+	}
+
+	return reservationID, &reservation, nil
+}
+
+// GetNetworkReservations returns the Network address reservations for the given network ID keyed on
+// reservation ID.
+func (c *Cluster) GetNetworkReservations(networkID int64) (map[int64]*api.NetworkReservation, error) {
+	q := `
+	SELECT
+		networks_reservations.id,
+		networks_reservations.address,
+		networks_reservations.description,
+		projects.name,
+		networks_reservations.instance
+	FROM networks_reservations
+	JOIN projects ON projects.id = networks_reservations.project_id
+	WHERE networks_reservations.network_id = ?
+	`
+
+	reservations := make(map[int64]*api.NetworkReservation)
+
+	err := c.Transaction(func(tx *ClusterTx) error {
+		return tx.QueryScan(q, func(scan func(dest ...any) error) error {
+			var reservationID int64 = int64(-1)
+			var reservation api.NetworkReservation
+
+			err := scan(&reservationID, &reservation.Address, &reservation.Description, &reservation.Project, &reservation.Instance)
+			if err != nil {
+				return err
+			}
+
+			reservations[reservationID] = &reservation
+
+			return nil
+		}, networkID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
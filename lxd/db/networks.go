@@ -473,6 +473,42 @@ func (c *ClusterTx) NetworkNodes(networkID int64) (map[int64]NetworkNode, error)
 	return netNodes, nil
 }
 
+// GetNetworkNodeConfigValues returns the values of a member specific config key of the given network, keyed by
+// the ID of the node they are set on.
+func (c *ClusterTx) GetNetworkNodeConfigValues(networkID int64, key string) (map[int64]string, error) {
+	type nodeValue struct {
+		nodeID int64
+		value  string
+	}
+
+	values := []nodeValue{}
+	dest := func(i int) []any {
+		values = append(values, nodeValue{})
+		return []any{&values[i].nodeID, &values[i].value}
+	}
+
+	stmt, err := c.tx.Prepare(`
+		SELECT node_id, value FROM networks_config
+		WHERE network_id = ? AND key = ? AND node_id IS NOT NULL
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	err = query.SelectObjects(stmt, dest, networkID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeValues := make(map[int64]string, len(values))
+	for _, value := range values {
+		nodeValues[value.nodeID] = value.value
+	}
+
+	return nodeValues, nil
+}
+
 // GetNetworkURIs returns the URIs for the networks with the given project.
 func (c *ClusterTx) GetNetworkURIs(projectID int, project string) ([]string, error) {
 	sql := `SELECT networks.name from networks WHERE networks.project_id = ?`
@@ -925,5 +961,6 @@ var NodeSpecificNetworkConfig = []string{
 	"bgp.ipv4.nexthop",
 	"bgp.ipv6.nexthop",
 	"bridge.external_interfaces",
+	"evpn.local",
 	"parent",
 }
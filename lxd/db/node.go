@@ -1071,24 +1071,22 @@ func (c *ClusterTx) GetNodeOfflineThreshold() (time.Duration, error) {
 	return threshold, nil
 }
 
-// GetNodeWithLeastInstances returns the name of the non-offline node with with
-// the least number of containers (either already created or being created with
-// an operation). If archs is not empty, then return only nodes with an
-// architecture in that list.
-func (c *ClusterTx) GetNodeWithLeastInstances(archs []int, defaultArch int, group string, allowedGroups []string) (string, error) {
+// GetCandidateMembers returns the non-offline, non-evacuated cluster members that are suitable for hosting a
+// new instance. If archs is not empty, then return only members with an architecture (or personality) in that
+// list. If group is not empty, then return only members of that cluster group. If allowedGroups is not nil, then
+// return only members of at least one of those cluster groups.
+func (c *ClusterTx) GetCandidateMembers(archs []int, group string, allowedGroups []string) ([]NodeInfo, error) {
 	threshold, err := c.GetNodeOfflineThreshold()
 	if err != nil {
-		return "", fmt.Errorf("Failed to get offline threshold: %w", err)
+		return nil, fmt.Errorf("Failed to get offline threshold: %w", err)
 	}
 
 	nodes, err := c.GetNodes()
 	if err != nil {
-		return "", fmt.Errorf("Failed to get current cluster members: %w", err)
+		return nil, fmt.Errorf("Failed to get current cluster members: %w", err)
 	}
 
-	name := ""
-	containers := -1
-	isDefaultArchChosen := false
+	candidates := make([]NodeInfo, 0, len(nodes))
 	for _, node := range nodes {
 		// Skip evacuated members.
 		if node.State == ClusterMemberStateEvacuated || node.IsOffline(threshold) {
@@ -1125,46 +1123,93 @@ func (c *ClusterTx) GetNodeWithLeastInstances(archs []int, defaultArch int, grou
 			}
 		}
 
-		// Get member personalities too.
-		personalities, err := osarch.ArchitecturePersonalities(node.Architecture)
-		if err != nil {
-			return "", err
-		}
-
-		supported := []int{node.Architecture}
-		supported = append(supported, personalities...)
+		// Skip if the member doesn't support any of the requested architectures.
+		if len(archs) > 0 {
+			supported, err := nodeSupportedArchitectures(node)
+			if err != nil {
+				return nil, err
+			}
 
-		match := false
-		isDefaultArch := false
-		for _, entry := range supported {
-			if shared.IntInSlice(entry, archs) {
-				match = true
+			match := false
+			for _, entry := range supported {
+				if shared.IntInSlice(entry, archs) {
+					match = true
+					break
+				}
 			}
-			if entry == defaultArch {
-				isDefaultArch = true
+
+			if !match {
+				continue
 			}
 		}
-		if len(archs) > 0 && !match {
-			continue
+
+		candidates = append(candidates, node)
+	}
+
+	return candidates, nil
+}
+
+// nodeSupportedArchitectures returns the architecture of the member along with its personalities.
+func nodeSupportedArchitectures(node NodeInfo) ([]int, error) {
+	personalities, err := osarch.ArchitecturePersonalities(node.Architecture)
+	if err != nil {
+		return nil, err
+	}
+
+	supported := []int{node.Architecture}
+	supported = append(supported, personalities...)
+
+	return supported, nil
+}
+
+// GetNodeInstanceCount returns the number of instances on the node with the given ID, including the instances
+// currently being created with an operation.
+func (c *ClusterTx) GetNodeInstanceCount(id int64) (int, error) {
+	// Fetch the number of instances already created on this node.
+	created, err := query.Count(c.tx, "instances", "node_id=?", id)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to get instances count: %w", err)
+	}
+
+	// Fetch the number of instances currently being created on this node.
+	pending, err := query.Count(
+		c.tx, "operations", "node_id=? AND type=?", id, OperationInstanceCreate)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to get pending instances count: %w", err)
+	}
+
+	return created + pending, nil
+}
+
+// GetNodeWithLeastInstances returns the name of the non-offline node with with
+// the least number of containers (either already created or being created with
+// an operation). If archs is not empty, then return only nodes with an
+// architecture in that list.
+func (c *ClusterTx) GetNodeWithLeastInstances(archs []int, defaultArch int, group string, allowedGroups []string) (string, error) {
+	candidates, err := c.GetCandidateMembers(archs, group, allowedGroups)
+	if err != nil {
+		return "", err
+	}
+
+	name := ""
+	containers := -1
+	isDefaultArchChosen := false
+	for _, node := range candidates {
+		supported, err := nodeSupportedArchitectures(node)
+		if err != nil {
+			return "", err
 		}
+
+		isDefaultArch := shared.IntInSlice(defaultArch, supported)
 		if !isDefaultArch && isDefaultArchChosen {
 			continue
 		}
 
-		// Fetch the number of instances already created on this node.
-		created, err := query.Count(c.tx, "instances", "node_id=?", node.ID)
+		count, err := c.GetNodeInstanceCount(node.ID)
 		if err != nil {
-			return "", fmt.Errorf("Failed to get instances count: %w", err)
-		}
-
-		// Fetch the number of instances currently being created on this node.
-		pending, err := query.Count(
-			c.tx, "operations", "node_id=? AND type=?", node.ID, OperationInstanceCreate)
-		if err != nil {
-			return "", fmt.Errorf("Failed to get pending instances count: %w", err)
+			return "", err
 		}
 
-		count := created + pending
 		if containers == -1 || count < containers || (isDefaultArch == true && isDefaultArchChosen == false) {
 			containers = count
 			name = node.Name
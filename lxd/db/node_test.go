@@ -394,6 +394,51 @@ INSERT INTO instances (id, node_id, name, architecture, type, project_id, descri
 	assert.Equal(t, "none", name)
 }
 
+// Only members that are online, can be auto-targeted and support one of the
+// requested architectures are candidates.
+func TestGetCandidateMembers(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	localArch, err := osarch.ArchitectureGetLocalID()
+	require.NoError(t, err)
+
+	testArch := osarch.ARCH_64BIT_S390_BIG_ENDIAN
+	if localArch == testArch {
+		testArch = osarch.ARCH_64BIT_INTEL_X86
+	}
+
+	_, err = tx.CreateNodeWithArch("buzz", "1.2.3.4:666", testArch)
+	require.NoError(t, err)
+
+	id, err := tx.CreateNode("fizz", "5.6.7.8:666")
+	require.NoError(t, err)
+
+	err = tx.UpdateNodeConfig(id, map[string]string{"scheduler.instance": "manual"})
+	require.NoError(t, err)
+
+	names := func(members []db.NodeInfo) []string {
+		result := []string{}
+		for _, member := range members {
+			result = append(result, member.Name)
+		}
+
+		return result
+	}
+
+	members, err := tx.GetCandidateMembers(nil, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"none", "buzz"}, names(members))
+
+	members, err = tx.GetCandidateMembers([]int{testArch}, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"buzz"}, names(members))
+
+	members, err = tx.GetCandidateMembers(nil, "", []string{"foo"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, names(members))
+}
+
 func TestUpdateNodeFailureDomain(t *testing.T) {
 	tx, cleanup := db.NewTestClusterTx(t)
 	defer cleanup()
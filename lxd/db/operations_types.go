@@ -67,6 +67,9 @@ const (
 	OperationClusterMemberRestore
 	OperationCertificateAddToken
 	OperationRemoveOrphanedOperations
+	OperationClusterHeal
+	OperationSnapshotReplicate
+	OperationVolumeSnapshotReplicate
 )
 
 // Description return a human-readable description of the operation type.
@@ -182,6 +185,12 @@ func (t OperationType) Description() string {
 		return "Restoring cluster member"
 	case OperationRemoveOrphanedOperations:
 		return "Remove orphaned operations"
+	case OperationClusterHeal:
+		return "Healing cluster"
+	case OperationSnapshotReplicate:
+		return "Replicating instance snapshots"
+	case OperationVolumeSnapshotReplicate:
+		return "Replicating storage volume snapshots"
 	default:
 		return "Executing operation"
 	}
@@ -222,6 +231,8 @@ func (t OperationType) Permission() string {
 		return "operate-containers"
 	case OperationSnapshotDelete:
 		return "operate-containers"
+	case OperationSnapshotReplicate:
+		return "operate-containers"
 
 	case OperationInstanceCreate:
 		return "manage-containers"
@@ -253,6 +264,8 @@ func (t OperationType) Permission() string {
 
 	case OperationCustomVolumeSnapshotsExpire:
 		return "operate-volumes"
+	case OperationVolumeSnapshotReplicate:
+		return "operate-volumes"
 	case OperationCustomVolumeBackupCreate:
 		return "manage-storage-volumes"
 	case OperationCustomVolumeBackupRemove:
@@ -228,12 +228,18 @@ func (c *ClusterTx) GetProjectUsedBy(project Project) ([]string, error) {
 		return nil, err
 	}
 
+	networkQoSPolicies, err := c.GetNetworkQoSPolicyURIs(project.ID, project.Name)
+	if err != nil {
+		return nil, err
+	}
+
 	usedBy := instances
 	usedBy = append(usedBy, images...)
 	usedBy = append(usedBy, profiles...)
 	usedBy = append(usedBy, volumes...)
 	usedBy = append(usedBy, networks...)
 	usedBy = append(usedBy, networkACLs...)
+	usedBy = append(usedBy, networkQoSPolicies...)
 
 	return usedBy, nil
 }
@@ -57,6 +57,12 @@ const (
 	WarningInstanceTypeNotOperational
 	//WarningStoragePoolUnvailable represents a storage pool that cannot be initialized on the local server.
 	WarningStoragePoolUnvailable
+	// WarningInstanceHealed represents an instance that was relocated from an offline cluster member
+	WarningInstanceHealed
+	// WarningInstanceHealingFailure represents the failure to relocate an instance from an offline cluster member
+	WarningInstanceHealingFailure
+	// WarningSnapshotReplicationFailure represents the failure to replicate an instance or custom volume to its replication target
+	WarningSnapshotReplicationFailure
 )
 
 // WarningTypeNames associates a warning code to its name.
@@ -86,6 +92,9 @@ var WarningTypeNames = map[WarningType]string{
 	WarningInstanceAutostartFailure:               "Failed to autostart instance",
 	WarningInstanceTypeNotOperational:             "Instance type not operational",
 	WarningStoragePoolUnvailable:                  "Storage pool unavailable",
+	WarningInstanceHealed:                         "Instance relocated from offline cluster member",
+	WarningInstanceHealingFailure:                 "Failed to relocate instance from offline cluster member",
+	WarningSnapshotReplicationFailure:             "Failed to replicate snapshots",
 }
 
 // Severity returns the severity of the warning type.
@@ -141,6 +150,12 @@ func (t WarningType) Severity() WarningSeverity {
 		return WarningSeverityLow
 	case WarningStoragePoolUnvailable:
 		return WarningSeverityHigh
+	case WarningInstanceHealed:
+		return WarningSeverityModerate
+	case WarningInstanceHealingFailure:
+		return WarningSeverityHigh
+	case WarningSnapshotReplicationFailure:
+		return WarningSeverityHigh
 	}
 
 	return WarningSeverityLow
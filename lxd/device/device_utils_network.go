@@ -21,11 +21,13 @@ import (
 	"github.com/lxc/lxd/lxd/instance/instancetype"
 	"github.com/lxc/lxd/lxd/ip"
 	"github.com/lxc/lxd/lxd/network"
+	"github.com/lxc/lxd/lxd/network/acl"
+	"github.com/lxc/lxd/lxd/network/qos"
+	"github.com/lxc/lxd/lxd/project"
 	"github.com/lxc/lxd/lxd/revert"
 	"github.com/lxc/lxd/lxd/state"
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/logger"
-	"github.com/lxc/lxd/shared/units"
 	"github.com/lxc/lxd/shared/validate"
 )
 
@@ -419,82 +421,21 @@ func networkNICRouteDelete(routeDev string, routes ...string) {
 	}
 }
 
-// networkSetupHostVethLimits applies any network rate limits to the veth device specified in the config.
-func networkSetupHostVethLimits(m deviceConfig.Device) error {
-	var err error
-
-	veth := m["host_name"]
+// networkSetupHostVethLimits applies the QoS policy or network rate limits of the NIC to its host side veth
+// device. The netConfig argument is the config of the network the NIC is linked to, if any.
+func networkSetupHostVethLimits(d *deviceCommon, netConfig map[string]string) error {
+	veth := d.config["host_name"]
 
 	if veth == "" || !network.InterfaceExists(veth) {
 		return fmt.Errorf("Unknown or missing host side veth device %q", veth)
 	}
 
-	// Apply max limit
-	if m["limits.max"] != "" {
-		m["limits.ingress"] = m["limits.max"]
-		m["limits.egress"] = m["limits.max"]
-	}
-
-	// Parse the values
-	var ingressInt int64
-	if m["limits.ingress"] != "" {
-		ingressInt, err = units.ParseBitSizeString(m["limits.ingress"])
-		if err != nil {
-			return err
-		}
-	}
-
-	var egressInt int64
-	if m["limits.egress"] != "" {
-		egressInt, err = units.ParseBitSizeString(m["limits.egress"])
-		if err != nil {
-			return err
-		}
-	}
-
-	// Clean any existing entry
-	qdisc := &ip.Qdisc{Dev: veth, Root: true}
-	qdisc.Delete()
-	qdisc = &ip.Qdisc{Dev: veth, Ingress: true}
-	qdisc.Delete()
-
-	// Apply new limits
-	if m["limits.ingress"] != "" {
-		qdiscHTB := &ip.QdiscHTB{Qdisc: ip.Qdisc{Dev: veth, Handle: "1:0", Root: true}, Default: "10"}
-		err := qdiscHTB.Add()
-		if err != nil {
-			return fmt.Errorf("Failed to create root tc qdisc: %s", err)
-		}
-
-		classHTB := &ip.ClassHTB{Class: ip.Class{Dev: veth, Parent: "1:0", Classid: "1:10"}, Rate: fmt.Sprintf("%dbit", ingressInt)}
-		err = classHTB.Add()
-		if err != nil {
-			return fmt.Errorf("Failed to create limit tc class: %s", err)
-		}
-
-		filter := &ip.U32Filter{Filter: ip.Filter{Dev: veth, Parent: "1:0", Protocol: "all", Flowid: "1:1"}, Value: "0", Mask: "0"}
-		err = filter.Add()
-		if err != nil {
-			return fmt.Errorf("Failed to create tc filter: %s", err)
-		}
-	}
-
-	if m["limits.egress"] != "" {
-		qdisc = &ip.Qdisc{Dev: veth, Handle: "ffff:0", Ingress: true}
-		err := qdisc.Add()
-		if err != nil {
-			return fmt.Errorf("Failed to create ingress tc qdisc: %s", err)
-		}
-
-		police := &ip.ActionPolice{Rate: fmt.Sprintf("%dbit", egressInt), Burst: "1024k", Mtu: "64kb", Drop: true}
-		filter := &ip.U32Filter{Filter: ip.Filter{Dev: veth, Parent: "ffff:0", Protocol: "all"}, Value: "0", Mask: "0", Actions: []ip.Action{police}}
-		err = filter.Add()
-		if err != nil {
-			return fmt.Errorf("Failed to create ingress tc filter: %s", err)
-		}
+	limits, err := nicQoSLimits(d, netConfig)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return qos.HostInterfaceSetup(veth, limits, false)
 }
 
 // networkValidGateway validates the gateway value.
@@ -560,6 +501,29 @@ func bgpAddPrefix(d *deviceCommon, n network.Network, config map[string]string)
 		}
 	}
 
+	// Advertise the MAC address of the NIC on EVPN bridges.
+	if n.Type() == "bridge" && n.Config()["bridge.mode"] == "evpn" {
+		hwaddr := config["hwaddr"]
+		if hwaddr == "" {
+			hwaddr = d.volatileGet()["hwaddr"]
+		}
+
+		mac, err := net.ParseMAC(hwaddr)
+		if err != nil {
+			return fmt.Errorf("Failed parsing MAC address %q: %w", hwaddr, err)
+		}
+
+		vni, err := strconv.ParseUint(n.Config()["evpn.vni"], 10, 32)
+		if err != nil {
+			return fmt.Errorf("Failed parsing evpn.vni: %w", err)
+		}
+
+		err = d.state.BGP.AddEVPNMAC(uint32(vni), mac, bgpOwner)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -570,7 +534,13 @@ func bgpRemovePrefix(d *deviceCommon, config map[string]string) error {
 	}
 
 	// Load the network configuration.
-	err := d.state.BGP.RemovePrefixByOwner(fmt.Sprintf("instance_%d_%s", d.inst.ID(), d.name))
+	bgpOwner := fmt.Sprintf("instance_%d_%s", d.inst.ID(), d.name)
+	err := d.state.BGP.RemovePrefixByOwner(bgpOwner)
+	if err != nil {
+		return err
+	}
+
+	err = d.state.BGP.RemoveEVPNMACByOwner(bgpOwner)
 	if err != nil {
 		return err
 	}
@@ -578,6 +548,91 @@ func bgpRemovePrefix(d *deviceCommon, config map[string]string) error {
 	return nil
 }
 
+// nicACLKeys lists the security ACL settings of NICs not linked to a network.
+var nicACLKeys = []string{
+	"security.acls",
+	"security.acls.default.ingress.action",
+	"security.acls.default.egress.action",
+	"security.acls.default.ingress.logged",
+	"security.acls.default.egress.logged",
+}
+
+// nicACLValidate checks that the security ACLs of a NIC not linked to a network exist in the instance's network
+// project.
+func nicACLValidate(d *deviceCommon, instConf instance.ConfigReader) error {
+	if d.config["security.acls"] == "" {
+		return nil
+	}
+
+	networkProjectName, _, err := project.NetworkProject(d.state.Cluster, instConf.Project())
+	if err != nil {
+		return fmt.Errorf("Failed loading network project name: %w", err)
+	}
+
+	return acl.Exists(d.state, networkProjectName, shared.SplitNTrimSpace(d.config["security.acls"], ",", -1, true)...)
+}
+
+// nicACLSetup applies the security ACLs of a NIC not linked to a network to the firewall, or removes the ACL rules
+// if it has none. The volatile config of the NIC identifies its host side interfaces.
+func nicACLSetup(d *deviceCommon, nicVolatile map[string]string) error {
+	if d.config["security.acls"] == "" {
+		return d.state.Firewall.InstanceClearACLRules(d.inst.Project(), d.inst.Name(), d.name)
+	}
+
+	networkProjectName, _, err := project.NetworkProject(d.state.Cluster, d.inst.Project())
+	if err != nil {
+		return fmt.Errorf("Failed loading network project name: %w", err)
+	}
+
+	err = acl.FirewallApplyNICACLRules(d.state, networkProjectName, d.inst.Project(), d.inst.Name(), d.name, d.config, nicVolatile)
+	if err != nil {
+		return fmt.Errorf("Failed applying security ACLs: %w", err)
+	}
+
+	return nil
+}
+
+// nicQoSValidate checks that the QoS policy of a NIC exists in the instance's network project and that the NIC
+// doesn't also set its own limits.
+func nicQoSValidate(d *deviceCommon, instConf instance.ConfigReader) error {
+	if d.config["qos"] == "" {
+		return nil
+	}
+
+	if qos.HasNICLimits(d.config) {
+		return fmt.Errorf("The %q setting cannot be used together with the limits settings", "qos")
+	}
+
+	networkProjectName, _, err := project.NetworkProject(d.state.Cluster, instConf.Project())
+	if err != nil {
+		return fmt.Errorf("Failed loading network project name: %w", err)
+	}
+
+	return qos.Exists(d.state, networkProjectName, d.config["qos"])
+}
+
+// nicQoSLimits returns the limits applied to a NIC, either from the QoS policy it uses directly or through its
+// network, or from its own limits settings. The netConfig argument is the config of the network the NIC is linked
+// to, if any.
+func nicQoSLimits(d *deviceCommon, netConfig map[string]string) (*qos.Limits, error) {
+	policyName := qos.NICPolicyName(d.config, netConfig)
+	if policyName == "" {
+		return qos.NICLimits(d.config)
+	}
+
+	networkProjectName, _, err := project.NetworkProject(d.state.Cluster, d.inst.Project())
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading network project name: %w", err)
+	}
+
+	policy, err := qos.LoadByName(d.state, networkProjectName, policyName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading network QoS policy %q: %w", policyName, err)
+	}
+
+	return policy.Limits()
+}
+
 // networkSRIOVParentVFInfo returns info about an SR-IOV virtual function from the parent NIC using the ip tool.
 func networkSRIOVParentVFInfo(vfParent string, vfID int) (ip.VirtFuncInfo, error) {
 	link := &ip.Link{Name: vfParent}
@@ -1002,3 +1057,39 @@ func networkVLANListExpand(rawVLANValues []string) ([]int, error) {
 
 	return networkVLANList, nil
 }
+
+// nicReservationsApply sets the static addresses of a NIC from the addresses reserved for its instance on the
+// network, unless the NIC has its own, and checks that its static addresses aren't reserved for something else.
+// When validating a profile or a new instance the instance isn't known, so only the reservations that aren't for a
+// specific instance are checked.
+func nicReservationsApply(d *deviceCommon, n network.Network) error {
+	reservations, err := d.state.Cluster.GetNetworkReservations(n.ID())
+	if err != nil {
+		return fmt.Errorf("Failed loading address reservations of network %q: %w", n.Name(), err)
+	}
+
+	if d.inst != nil {
+		reservedIPv4, reservedIPv6 := network.ReservedAddresses(reservations, d.inst.Project(), d.inst.Name())
+		if d.config["ipv4.address"] == "" && reservedIPv4 != nil {
+			d.config["ipv4.address"] = reservedIPv4.String()
+		}
+
+		if d.config["ipv6.address"] == "" && reservedIPv6 != nil {
+			d.config["ipv6.address"] = reservedIPv6.String()
+		}
+	}
+
+	for _, reservation := range reservations {
+		if reservation.Instance != "" && (d.inst == nil || (reservation.Project == d.inst.Project() && reservation.Instance == d.inst.Name())) {
+			continue
+		}
+
+		for _, key := range []string{"ipv4.address", "ipv6.address"} {
+			if net.ParseIP(reservation.Address).Equal(net.ParseIP(d.config[key])) {
+				return fmt.Errorf("Device IP address %q is reserved on network %q", d.config[key], n.Name())
+			}
+		}
+	}
+
+	return nil
+}
@@ -73,6 +73,7 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader) error {
 		"limits.ingress",
 		"limits.egress",
 		"limits.max",
+		"qos",
 		"ipv4.address",
 		"ipv6.address",
 		"ipv4.routes",
@@ -99,6 +100,12 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader) error {
 			return fmt.Errorf("Specified network must be of type bridge")
 		}
 
+		// Use the addresses reserved for the instance on the network.
+		err := nicReservationsApply(&d.deviceCommon, n)
+		if err != nil {
+			return err
+		}
+
 		netConfig := n.Config()
 
 		if d.config["ipv4.address"] != "" {
@@ -440,6 +447,11 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader) error {
 		return err
 	}
 
+	err = nicQoSValidate(&d.deviceCommon, instConf)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -456,6 +468,15 @@ func (d *nicBridged) validateEnvironment() error {
 	return nil
 }
 
+// networkConfig returns the config of the managed network the NIC is linked to, if any.
+func (d *nicBridged) networkConfig() map[string]string {
+	if d.network == nil {
+		return nil
+	}
+
+	return d.network.Config()
+}
+
 // UpdatableFields returns a list of fields that can be updated without triggering a device remove & add.
 func (d *nicBridged) UpdatableFields(oldDevice Type) []string {
 	// Check old and new device types match.
@@ -464,7 +485,7 @@ func (d *nicBridged) UpdatableFields(oldDevice Type) []string {
 		return []string{}
 	}
 
-	return []string{"limits.ingress", "limits.egress", "limits.max", "ipv4.routes", "ipv6.routes", "ipv4.routes.external", "ipv6.routes.external", "ipv4.address", "ipv6.address", "security.mac_filtering", "security.ipv4_filtering", "security.ipv6_filtering"}
+	return []string{"limits.ingress", "limits.egress", "limits.max", "qos", "ipv4.routes", "ipv6.routes", "ipv4.routes.external", "ipv6.routes.external", "ipv4.address", "ipv6.address", "security.mac_filtering", "security.ipv4_filtering", "security.ipv6_filtering"}
 }
 
 // Add is run when a device is added to a non-snapshot instance whether or not the instance is running.
@@ -544,7 +565,7 @@ func (d *nicBridged) Start() (*deviceConfig.RunConfig, error) {
 	}
 
 	// Apply host-side limits.
-	err = networkSetupHostVethLimits(d.config)
+	err = networkSetupHostVethLimits(&d.deviceCommon, d.networkConfig())
 	if err != nil {
 		return nil, err
 	}
@@ -614,11 +635,16 @@ func (d *nicBridged) Start() (*deviceConfig.RunConfig, error) {
 				return nil, fmt.Errorf("Failed loading network forwards: %w", err)
 			}
 
-			// If br_netfilter is enabled and bridge has forwards, we enable hairpin mode on NIC's
-			// bridge port in case any of the forwards target this NIC and the instance attempts to
-			// connect to the forward's listener. Without hairpin mode on the target of the forward
-			// will not be able to connect to the listener.
-			if len(listenAddresses) > 0 {
+			lbListenAddresses, err := d.state.Cluster.GetNetworkLoadBalancerListenAddresses(d.network.ID(), true)
+			if err != nil {
+				return nil, fmt.Errorf("Failed loading network load balancers: %w", err)
+			}
+
+			// If br_netfilter is enabled and bridge has forwards or load balancers, we enable hairpin mode
+			// on NIC's bridge port in case any of the forwards or load balancers target this NIC and the
+			// instance attempts to connect to the listener. Without hairpin mode on the target will not
+			// be able to connect to the listener.
+			if len(listenAddresses) > 0 || len(lbListenAddresses) > 0 {
 				link := &ip.Link{Name: saveData["host_name"]}
 				err = link.BridgeLinkSetHairpin(true)
 				if err != nil {
@@ -724,7 +750,7 @@ func (d *nicBridged) Update(oldDevices deviceConfig.Devices, isRunning bool) err
 		}
 
 		// Apply host-side limits.
-		err = networkSetupHostVethLimits(d.config)
+		err = networkSetupHostVethLimits(&d.deviceCommon, d.networkConfig())
 		if err != nil {
 			return err
 		}
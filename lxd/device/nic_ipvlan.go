@@ -46,6 +46,8 @@ func (d *nicIPVLAN) validateConfig(instConf instance.ConfigReader) error {
 		"gvrp",
 	}
 
+	optionalFields = append(optionalFields, nicACLKeys...)
+
 	rules := nicValidationRules(requiredFields, optionalFields, instConf)
 	rules["gvrp"] = validate.Optional(validate.IsBool)
 	rules["ipv4.address"] = func(value string) error {
@@ -133,6 +135,17 @@ func (d *nicIPVLAN) validateConfig(instConf instance.ConfigReader) error {
 		return fmt.Errorf("host_table option cannot be used in l2 mode")
 	}
 
+	// In l2 mode the traffic doesn't go through the host's firewall.
+	if d.config["mode"] == ipvlanModeL2 && d.config["security.acls"] != "" {
+		return fmt.Errorf("security.acls option cannot be used in l2 mode")
+	}
+
+	// Check Security ACLs exist.
+	err = nicACLValidate(&d.deviceCommon, instConf)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -357,6 +370,14 @@ func (d *nicIPVLAN) setupParentSysctls(parentName string) error {
 
 // postStart is run after the instance is started.
 func (d *nicIPVLAN) postStart() error {
+	// Apply firewall rules for the security ACLs.
+	if d.config["security.acls"] != "" {
+		err := nicACLSetup(&d.deviceCommon, d.volatileGet())
+		if err != nil {
+			return err
+		}
+	}
+
 	if d.config["ipv4.address"] != "" {
 		// Add static routes to instance IPs to custom routing tables if specified.
 		// This is in addition to the static route added by liblxc to the main routing table.
@@ -476,6 +497,12 @@ func (d *nicIPVLAN) postStop() error {
 		}
 	}
 
+	// Remove security ACL rules.
+	err := d.state.Firewall.InstanceClearACLRules(d.inst.Project(), d.inst.Name(), d.name)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
 	// This will delete the parent interface if we created it for VLAN parent.
 	if shared.IsTrue(v["last_state.created"]) {
 		parentName := network.GetHostDevice(d.config["parent"], d.config["vlan"])
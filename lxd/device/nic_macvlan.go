@@ -9,6 +9,7 @@ import (
 	"github.com/lxc/lxd/lxd/instance/instancetype"
 	"github.com/lxc/lxd/lxd/ip"
 	"github.com/lxc/lxd/lxd/network"
+	"github.com/lxc/lxd/lxd/network/qos"
 	"github.com/lxc/lxd/lxd/project"
 	"github.com/lxc/lxd/lxd/revert"
 	"github.com/lxc/lxd/shared"
@@ -49,13 +50,17 @@ func (d *nicMACVLAN) validateConfig(instConf instance.ConfigReader) error {
 		"maas.subnet.ipv6",
 		"boot.priority",
 		"gvrp",
+		"qos",
 	}
 
+	optionalFields = append(optionalFields, nicACLKeys...)
+
 	// Check that if network proeperty is set that conflicting keys are not present.
 	if d.config["network"] != "" {
 		requiredFields = append(requiredFields, "network")
 
-		bannedKeys := []string{"nictype", "parent", "mtu", "vlan", "maas.subnet.ipv4", "maas.subnet.ipv6", "gvrp"}
+		// Security ACLs are only applied to NICs not linked to a network.
+		bannedKeys := append([]string{"nictype", "parent", "mtu", "vlan", "maas.subnet.ipv4", "maas.subnet.ipv6", "gvrp"}, nicACLKeys...)
 		for _, bannedKey := range bannedKeys {
 			if d.config[bannedKey] != "" {
 				return fmt.Errorf("Cannot use %q property in conjunction with %q property", bannedKey, "network")
@@ -100,6 +105,22 @@ func (d *nicMACVLAN) validateConfig(instConf instance.ConfigReader) error {
 		return err
 	}
 
+	// Only the traffic of macvtap devices passes through the host, so QoS policies require a VM.
+	if d.config["qos"] != "" && instConf.Type() != instancetype.VM {
+		return fmt.Errorf("The %q setting is only supported for virtual machines", "qos")
+	}
+
+	err = nicQoSValidate(&d.deviceCommon, instConf)
+	if err != nil {
+		return err
+	}
+
+	// Check Security ACLs exist.
+	err = nicACLValidate(&d.deviceCommon, instConf)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -153,6 +174,9 @@ func (d *nicMACVLAN) Start() (*deviceConfig.RunConfig, error) {
 	// Record the temporary device name used for deletion later.
 	saveData["host_name"] = network.RandomDevName("mac")
 
+	// Record the actual parent on which the security ACLs are applied.
+	saveData["last_state.parent"] = actualParentName
+
 	// Create VLAN parent device if needed.
 	statusDev, err := networkCreateVlanDeviceIfNeeded(d.state, d.config["parent"], actualParentName, d.config["vlan"], shared.IsTrue(d.config["gvrp"]))
 	if err != nil {
@@ -225,6 +249,29 @@ func (d *nicMACVLAN) Start() (*deviceConfig.RunConfig, error) {
 		if err != nil {
 			return nil, fmt.Errorf("Failed to bring up interface %s: %w", saveData["host_name"], err)
 		}
+
+		// Apply the QoS policy. The host side macvtap device transmits the traffic sent by the VM.
+		if d.config["qos"] != "" {
+			limits, err := nicQoSLimits(&d.deviceCommon, nil)
+			if err != nil {
+				return nil, err
+			}
+
+			err = qos.HostInterfaceSetup(saveData["host_name"], limits, true)
+			if err != nil {
+				return nil, fmt.Errorf("Failed applying QoS policy to %q: %w", saveData["host_name"], err)
+			}
+		}
+	}
+
+	// Apply firewall rules for the security ACLs.
+	if d.config["security.acls"] != "" {
+		err = nicACLSetup(&d.deviceCommon, saveData)
+		if err != nil {
+			return nil, err
+		}
+
+		revert.Add(func() { d.state.Firewall.InstanceClearACLRules(d.inst.Project(), d.inst.Name(), d.name) })
 	}
 
 	err = d.volatileSet(saveData)
@@ -273,11 +320,18 @@ func (d *nicMACVLAN) postStop() error {
 		"last_state.hwaddr":  "",
 		"last_state.mtu":     "",
 		"last_state.created": "",
+		"last_state.parent":  "",
 	})
 
 	errs := []error{}
 	v := d.volatileGet()
 
+	// Remove security ACL rules, before the parent they are applied on may be deleted.
+	err := d.state.Firewall.InstanceClearACLRules(d.inst.Project(), d.inst.Name(), d.name)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
 	// Delete the detached device.
 	if v["host_name"] != "" && shared.PathExists(fmt.Sprintf("/sys/class/net/%s", v["host_name"])) {
 		err := network.InterfaceRemove(v["host_name"])
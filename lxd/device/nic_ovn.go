@@ -56,7 +56,7 @@ func (d *nicOVN) UpdatableFields(oldDevice Type) []string {
 		return []string{}
 	}
 
-	return []string{"security.acls"}
+	return []string{"security.acls", "qos"}
 }
 
 // getIntegrationBridgeName returns the OVS integration bridge to use.
@@ -97,6 +97,7 @@ func (d *nicOVN) validateConfig(instConf instance.ConfigReader) error {
 		"security.acls.default.ingress.logged",
 		"security.acls.default.egress.logged",
 		"acceleration",
+		"qos",
 	}
 
 	// The NIC's network may be a non-default project, so lookup project and get network's project name.
@@ -132,6 +133,12 @@ func (d *nicOVN) validateConfig(instConf instance.ConfigReader) error {
 	}
 
 	d.network = ovnNet // Stored loaded network for use by other functions.
+
+	// Use the addresses reserved for the instance on the network.
+	err = nicReservationsApply(&d.deviceCommon, n)
+	if err != nil {
+		return err
+	}
 	netConfig := d.network.Config()
 
 	if d.config["ipv4.address"] != "" {
@@ -219,6 +226,12 @@ func (d *nicOVN) validateConfig(instConf instance.ConfigReader) error {
 		}
 	}
 
+	// Check QoS policy exists.
+	err = nicQoSValidate(&d.deviceCommon, instConf)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -429,8 +442,8 @@ func (d *nicOVN) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 		}
 	}
 
-	// Apply any changes needed when assigned ACLs change.
-	if d.config["security.acls"] != oldConfig["security.acls"] {
+	// Apply any changes needed when assigned ACLs or QoS policy change.
+	if d.config["security.acls"] != oldConfig["security.acls"] || d.config["qos"] != oldConfig["qos"] {
 		// Work out which ACLs have been removed and remove logical port from those groups.
 		oldACLs := shared.SplitNTrimSpace(oldConfig["security.acls"], ",", -1, true)
 		newACLs := shared.SplitNTrimSpace(d.config["security.acls"], ",", -1, true)
@@ -441,7 +454,7 @@ func (d *nicOVN) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 			}
 		}
 
-		// Setup the logical port with new ACLs if running.
+		// Setup the logical port with new ACLs and QoS rules if running.
 		if isRunning {
 			// Load uplink network config.
 			uplinkNetworkName := d.network.Config()["network"]
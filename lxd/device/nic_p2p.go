@@ -34,6 +34,7 @@ func (d *nicP2P) validateConfig(instConf instance.ConfigReader) error {
 		"limits.ingress",
 		"limits.egress",
 		"limits.max",
+		"qos",
 		"ipv4.routes",
 		"ipv6.routes",
 		"boot.priority",
@@ -43,6 +44,11 @@ func (d *nicP2P) validateConfig(instConf instance.ConfigReader) error {
 		return err
 	}
 
+	err = nicQoSValidate(&d.deviceCommon, instConf)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -63,7 +69,7 @@ func (d *nicP2P) UpdatableFields(oldDevice Type) []string {
 		return []string{}
 	}
 
-	return []string{"limits.ingress", "limits.egress", "limits.max", "ipv4.routes", "ipv6.routes"}
+	return []string{"limits.ingress", "limits.egress", "limits.max", "qos", "ipv4.routes", "ipv6.routes"}
 }
 
 // Start is run when the device is added to a running instance or instance is starting up.
@@ -114,7 +120,7 @@ func (d *nicP2P) Start() (*deviceConfig.RunConfig, error) {
 	}
 
 	// Apply host-side limits.
-	err = networkSetupHostVethLimits(d.config)
+	err = networkSetupHostVethLimits(&d.deviceCommon, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -173,7 +179,7 @@ func (d *nicP2P) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 	}
 
 	// Apply host-side limits.
-	err = networkSetupHostVethLimits(d.config)
+	err = networkSetupHostVethLimits(&d.deviceCommon, nil)
 	if err != nil {
 		return err
 	}
@@ -43,7 +43,7 @@ func (d *nicRouted) UpdatableFields(oldDevice Type) []string {
 		return []string{}
 	}
 
-	return []string{"limits.ingress", "limits.egress", "limits.max"}
+	return append([]string{"limits.ingress", "limits.egress", "limits.max", "qos"}, nicACLKeys...)
 }
 
 // validateConfig checks the supplied config for correctness.
@@ -68,6 +68,7 @@ func (d *nicRouted) validateConfig(instConf instance.ConfigReader) error {
 		"limits.ingress",
 		"limits.egress",
 		"limits.max",
+		"qos",
 		"ipv4.gateway",
 		"ipv6.gateway",
 		"ipv4.routes",
@@ -79,6 +80,8 @@ func (d *nicRouted) validateConfig(instConf instance.ConfigReader) error {
 		"gvrp",
 	}
 
+	optionalFields = append(optionalFields, nicACLKeys...)
+
 	rules := nicValidationRules(requiredFields, optionalFields, instConf)
 	rules["ipv4.address"] = validate.Optional(validate.IsNetworkAddressV4List)
 	rules["ipv6.address"] = validate.Optional(validate.IsNetworkAddressV6List)
@@ -119,6 +122,18 @@ func (d *nicRouted) validateConfig(instConf instance.ConfigReader) error {
 		return fmt.Errorf("The vlan setting can only be used when combined with a parent interface")
 	}
 
+	// Check Security ACLs exist.
+	err = nicACLValidate(&d.deviceCommon, instConf)
+	if err != nil {
+		return err
+	}
+
+	// Check QoS policy exists.
+	err = nicQoSValidate(&d.deviceCommon, instConf)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -332,7 +347,7 @@ func (d *nicRouted) Start() (*deviceConfig.RunConfig, error) {
 	networkVethFillFromVolatile(d.config, saveData)
 
 	// Apply host-side limits.
-	err = networkSetupHostVethLimits(d.config)
+	err = networkSetupHostVethLimits(&d.deviceCommon, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -355,6 +370,16 @@ func (d *nicRouted) Start() (*deviceConfig.RunConfig, error) {
 		return nil, fmt.Errorf("Error setting up reverse path filter: %w", err)
 	}
 
+	// Apply firewall rules for the security ACLs.
+	if d.config["security.acls"] != "" {
+		err = nicACLSetup(&d.deviceCommon, saveData)
+		if err != nil {
+			return nil, err
+		}
+
+		revert.Add(func() { d.state.Firewall.InstanceClearACLRules(d.inst.Project(), d.inst.Name(), d.name) })
+	}
+
 	// Perform host-side address configuration.
 	for _, keyPrefix := range []string{"ipv4", "ipv6"} {
 		subnetSize := 32
@@ -560,10 +585,23 @@ func (d *nicRouted) Update(oldDevices deviceConfig.Devices, isRunning bool) erro
 		networkVethFillFromVolatile(d.config, v)
 
 		// Apply host-side limits.
-		err = networkSetupHostVethLimits(d.config)
+		err = networkSetupHostVethLimits(&d.deviceCommon, nil)
 		if err != nil {
 			return err
 		}
+
+		// Apply firewall rules for the security ACLs if changed.
+		oldConfig := oldDevices[d.name]
+		for _, key := range nicACLKeys {
+			if d.config[key] != oldConfig[key] {
+				err = nicACLSetup(&d.deviceCommon, v)
+				if err != nil {
+					return err
+				}
+
+				break
+			}
+		}
 	}
 
 	return nil
@@ -632,6 +670,12 @@ func (d *nicRouted) postStop() error {
 		errs = append(errs, err)
 	}
 
+	// Remove security ACL rules.
+	err = d.state.Firewall.InstanceClearACLRules(d.inst.Project(), d.inst.Name(), d.name)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
 	if len(errs) > 0 {
 		return fmt.Errorf("%v", errs)
 	}
@@ -19,6 +19,11 @@ import (
 
 const staticAllocationDeviceSeparator = "."
 
+// reservationFilePrefix is the prefix of the dhcp-host files of reserved addresses. The static allocation files of
+// instance devices either start with the instance name, which can't start with a dot, or contain an underscore
+// after the project name, so the reservation files can't conflict with them.
+const reservationFilePrefix = "."
+
 // DHCPAllocation represents an IP allocation from dnsmasq.
 type DHCPAllocation struct {
 	IP             net.IP
@@ -60,6 +65,29 @@ func UpdateStaticEntry(network string, projectName string, instanceName string,
 	return nil
 }
 
+// UpdateReservationEntry writes a dhcp-host line for a reserved address that isn't used by any instance.
+// The line doesn't match any client MAC address, but prevents dnsmasq from allocating the address dynamically.
+func UpdateReservationEntry(network string, address net.IP) error {
+	if address == nil {
+		return fmt.Errorf("Invalid reserved address")
+	}
+
+	// Use a host name without dots or colons so that the line is parsed like the other static allocations.
+	hostName := "lxd-reserved-" + strings.NewReplacer(".", "-", ":", "-").Replace(address.String())
+
+	line := fmt.Sprintf("%s,%s", hostName, address.String())
+	if address.To4() == nil {
+		line = fmt.Sprintf("%s,[%s]", hostName, address.String())
+	}
+
+	err := ioutil.WriteFile(shared.VarPath("networks", network, "dnsmasq.hosts", reservationFilePrefix+hostName), []byte(line+"\n"), 0644)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // RemoveStaticEntry removes a single dhcp-host line for a network/instance combination.
 func RemoveStaticEntry(network string, projectName string, instanceName string, deviceName string) error {
 	deviceStaticFileName := StaticAllocationFileName(projectName, instanceName, deviceName)
@@ -66,6 +66,14 @@ type Config struct {
 
 	// HTTP server handling requests for the LXD metrics API.
 	MetricsServer *http.Server
+
+	// StorageBucketsAddress sets the address for the storage buckets endpoint.
+	//
+	// It can be updated after the endpoints are up using StorageBucketsUpdateAddress().
+	StorageBucketsAddress string
+
+	// HTTP server handling requests for the S3 API of the local storage buckets.
+	StorageBucketsServer *http.Server
 }
 
 // Up brings up all applicable LXD endpoints and starts accepting HTTP
@@ -164,12 +172,13 @@ func (e *Endpoints) up(config *Config) error {
 	defer e.mu.Unlock()
 
 	e.servers = map[kind]*http.Server{
-		devlxd:  config.DevLxdServer,
-		local:   config.RestServer,
-		network: config.RestServer,
-		cluster: config.RestServer,
-		pprof:   pprofCreateServer(),
-		metrics: config.MetricsServer,
+		devlxd:         config.DevLxdServer,
+		local:          config.RestServer,
+		network:        config.RestServer,
+		cluster:        config.RestServer,
+		pprof:          pprofCreateServer(),
+		metrics:        config.MetricsServer,
+		storageBuckets: config.StorageBucketsServer,
 	}
 	e.cert = config.Cert
 	e.inherited = map[kind]bool{}
@@ -298,6 +307,16 @@ func (e *Endpoints) up(config *Config) error {
 		e.serve(metrics)
 	}
 
+	if config.StorageBucketsAddress != "" {
+		e.listeners[storageBuckets], err = storageBucketsCreateListener(config.StorageBucketsAddress, e.cert)
+		if err != nil {
+			return err
+		}
+
+		logger.Infof("Starting storage buckets handler:")
+		e.serve(storageBuckets)
+	}
+
 	logger.Infof("Starting /dev/lxd handler:")
 	e.serve(devlxd)
 
@@ -358,6 +377,14 @@ func (e *Endpoints) Down() error {
 		}
 	}
 
+	if e.listeners[storageBuckets] != nil {
+		logger.Infof("Stopping storage buckets handler:")
+		err := e.closeListener(storageBuckets)
+		if err != nil {
+			return err
+		}
+	}
+
 	if e.tomb != nil {
 		e.tomb.Kill(nil)
 		e.tomb.Wait()
@@ -441,14 +468,16 @@ const (
 	pprof
 	cluster
 	metrics
+	storageBuckets
 )
 
 // Human-readable descriptions of the various kinds of endpoints.
 var descriptions = map[kind]string{
-	local:   "Unix socket",
-	devlxd:  "devlxd socket",
-	network: "TCP socket",
-	pprof:   "pprof socket",
-	cluster: "cluster socket",
-	metrics: "metrics socket",
+	local:          "Unix socket",
+	devlxd:         "devlxd socket",
+	network:        "TCP socket",
+	pprof:          "pprof socket",
+	cluster:        "cluster socket",
+	metrics:        "metrics socket",
+	storageBuckets: "storage buckets socket",
 }
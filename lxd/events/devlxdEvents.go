@@ -62,6 +62,14 @@ func (s *DevLXDServer) AddListener(instanceID int, connection *websocket.Conn, m
 	return listener, nil
 }
 
+// ListenerCount returns the number of connected listeners.
+func (s *DevLXDServer) ListenerCount() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return len(s.listeners)
+}
+
 // Send broadcasts a custom event.
 func (s *DevLXDServer) Send(instanceID int, eventType string, eventMessage any) error {
 	encodedMessage, err := json.Marshal(eventMessage)
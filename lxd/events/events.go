@@ -37,6 +37,7 @@ type Server struct {
 	serverCommon
 
 	listeners map[string]*Listener
+	sinks     map[string]Sink
 	notify    NotifyFunc
 	location  string
 }
@@ -49,6 +50,7 @@ func NewServer(debug bool, verbose bool, notify NotifyFunc) *Server {
 			verbose: verbose,
 		},
 		listeners: map[string]*Listener{},
+		sinks:     map[string]Sink{},
 		notify:    notify,
 	}
 
@@ -102,6 +104,14 @@ func (s *Server) AddListener(projectName string, allProjects bool, connection *w
 	return listener, nil
 }
 
+// ListenerCount returns the number of connected listeners.
+func (s *Server) ListenerCount() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return len(s.listeners)
+}
+
 // SendLifecycle broadcasts a lifecycle event.
 func (s *Server) SendLifecycle(projectName string, event api.EventLifecycle) {
 	s.Send(projectName, "lifecycle", event)
@@ -174,6 +184,13 @@ func (s *Server) broadcast(event api.Event, eventSource EventSource) error {
 		s.notify(event)
 	}
 
+	// Forward locally produced events to the external log targets.
+	if eventSource == EventSourceLocal {
+		for _, sink := range s.sinks {
+			sink.HandleEvent(event)
+		}
+	}
+
 	listeners := s.listeners
 	for _, listener := range listeners {
 		// If the event is project specific, check if the listener is requesting events from that project.
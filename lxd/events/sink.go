@@ -0,0 +1,137 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lxc/lxd/shared/api"
+)
+
+// Sink is implemented by the clients which forward locally generated events to an external log target.
+// HandleEvent is called with the event server lock held, so it must not block.
+type Sink interface {
+	HandleEvent(event api.Event)
+}
+
+// SetSink adds the named event sink, replacing any sink already registered with that name.
+// Passing a nil sink removes it.
+func (s *Server) SetSink(name string, sink Sink) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if sink == nil {
+		delete(s.sinks, name)
+		return
+	}
+
+	s.sinks[name] = sink
+}
+
+// Record is the flattened representation of an event used by the event sinks.
+type Record struct {
+	// Level of the record (logrus level names).
+	Level string
+
+	// Short description of the event.
+	Message string
+
+	// Additional fields of the event.
+	Context map[string]string
+}
+
+// NewRecord flattens an event into a Record.
+func NewRecord(event api.Event) (*Record, error) {
+	record := Record{
+		Level:   "info",
+		Context: map[string]string{},
+	}
+
+	switch event.Type {
+	case "logging":
+		logEntry := api.EventLogging{}
+		err := json.Unmarshal(event.Metadata, &logEntry)
+		if err != nil {
+			return nil, err
+		}
+
+		record.Level = logEntry.Level
+		record.Message = logEntry.Message
+		for k, v := range logEntry.Context {
+			record.Context[k] = v
+		}
+
+	case "lifecycle":
+		lifecycleEvent := api.EventLifecycle{}
+		err := json.Unmarshal(event.Metadata, &lifecycleEvent)
+		if err != nil {
+			return nil, err
+		}
+
+		record.Message = lifecycleEvent.Action
+		record.Context["source"] = lifecycleEvent.Source
+
+		if lifecycleEvent.Requestor != nil {
+			record.Context["requestor-username"] = lifecycleEvent.Requestor.Username
+			record.Context["requestor-protocol"] = lifecycleEvent.Requestor.Protocol
+			record.Context["requestor-address"] = lifecycleEvent.Requestor.Address
+		}
+
+		for k, v := range lifecycleEvent.Context {
+			_, ok := record.Context[k]
+			if ok {
+				continue
+			}
+
+			record.Context[k] = fmt.Sprintf("%v", v)
+		}
+
+	case "operation":
+		op := api.Operation{}
+		err := json.Unmarshal(event.Metadata, &op)
+		if err != nil {
+			return nil, err
+		}
+
+		record.Message = op.Description
+		record.Context["id"] = op.ID
+		record.Context["class"] = op.Class
+		record.Context["status"] = op.Status
+
+		if op.Err != "" {
+			record.Level = "error"
+			record.Context["err"] = op.Err
+		}
+
+	default:
+		return nil, fmt.Errorf("Unsupported event type %q", event.Type)
+	}
+
+	return &record, nil
+}
+
+// String returns the record in logfmt format, with the context fields sorted by name.
+func (r Record) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("msg=")
+	sb.WriteString(strconv.Quote(r.Message))
+
+	keys := make([]string, 0, len(r.Context))
+	for k := range r.Context {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		sb.WriteString(" ")
+		sb.WriteString(k)
+		sb.WriteString("=")
+		sb.WriteString(strconv.Quote(r.Context[k]))
+	}
+
+	return sb.String()
+}
@@ -47,3 +47,17 @@ type AddressForward struct {
 	ListenPorts   []uint64
 	TargetPorts   []uint64
 }
+
+// LoadBalancerTarget represents a backend target of a NAT load balancer.
+type LoadBalancerTarget struct {
+	Address net.IP
+	Ports   []uint64
+}
+
+// LoadBalancer represents a NAT load balancer that spreads connections across multiple backend targets.
+type LoadBalancer struct {
+	ListenAddress net.IP
+	Protocol      string
+	ListenPorts   []uint64
+	Targets       []LoadBalancerTarget
+}
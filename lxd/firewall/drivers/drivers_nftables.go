@@ -366,6 +366,7 @@ func (d Nftables) NetworkClear(networkName string, _ bool, _ []uint) error {
 		"fwd", "pstrt", "in", "out", // Chains used for network operation rules.
 		"aclin", "aclout", "aclfwd", "acl", // Chains used by ACL rules.
 		"fwdprert", "fwdout", "fwdpstrt", // Chains used by Address Forward rules.
+		"lbprert", "lbout", "lbpstrt", // Chains used by Load Balancer rules.
 	}
 
 	// Remove chains created by network rules.
@@ -661,14 +662,208 @@ func (d Nftables) InstanceClearRPFilter(projectName string, instanceName string,
 	return nil
 }
 
+// InstanceSetupACLRules applies ACL rules to an instance NIC that isn't connected to a network.
+// If hostName is set, the NIC's traffic is matched by its host side interface, otherwise by its addresses.
+func (d Nftables) InstanceSetupACLRules(projectName string, instanceName string, deviceName string, hostName string, IPv4Nets []*net.IPNet, IPv6Nets []*net.IPNet, rules []ACLRule) error {
+	deviceLabel := d.instanceDeviceLabel(projectName, instanceName, deviceName)
+
+	config, err := d.instanceACLRulesConfig(deviceLabel, hostName, IPv4Nets, IPv6Nets, rules)
+	if err != nil {
+		return err
+	}
+
+	_, err = shared.RunCommand("nft", config)
+	if err != nil {
+		return fmt.Errorf("Failed adding ACL rules for instance device %q: %w", deviceLabel, err)
+	}
+
+	return nil
+}
+
+// instanceACLRulesConfig renders the nftables config applying ACL rules to the instance NIC with deviceLabel.
+func (d Nftables) instanceACLRulesConfig(deviceLabel string, hostName string, IPv4Nets []*net.IPNet, IPv6Nets []*net.IPNet, rules []ACLRule) (string, error) {
+	nftIngressRules, nftEgressRules, err := d.instanceACLRulesToNftRules(rules)
+	if err != nil {
+		return "", err
+	}
+
+	tplFields := map[string]any{
+		"namespace":      nftablesNamespace,
+		"chainSeparator": nftablesChainSeparator,
+		"deviceLabel":    deviceLabel,
+		"hostName":       hostName,
+		"family":         "inet",
+		"ingressRules":   nftIngressRules,
+		"egressRules":    nftEgressRules,
+	}
+
+	if hostName == "" {
+		for ipFamily, ipNets := range map[string][]*net.IPNet{"ipv4Nets": IPv4Nets, "ipv6Nets": IPv6Nets} {
+			if len(ipNets) == 0 {
+				continue
+			}
+
+			nets := make([]string, 0, len(ipNets))
+			for _, ipNet := range ipNets {
+				nets = append(nets, ipNet.String())
+			}
+
+			tplFields[ipFamily] = fmt.Sprintf("{%s}", strings.Join(nets, ", "))
+		}
+	}
+
+	config := &strings.Builder{}
+	err = nftablesInstanceACLRules.Execute(config, tplFields)
+	if err != nil {
+		return "", fmt.Errorf("Failed running %q template: %w", nftablesInstanceACLRules.Name(), err)
+	}
+
+	return config.String(), nil
+}
+
+// InstanceSetupParentACLRules applies ACL rules to an instance NIC whose traffic doesn't go through the IP stack of
+// the LXD host, matching it by its MAC address on its parent interface.
+func (d Nftables) InstanceSetupParentACLRules(projectName string, instanceName string, deviceName string, parentName string, hwAddr string, rules []ACLRule) error {
+	deviceLabel := d.instanceDeviceLabel(projectName, instanceName, deviceName)
+
+	config, err := d.instanceParentACLRulesConfig(deviceLabel, parentName, hwAddr, rules)
+	if err != nil {
+		return err
+	}
+
+	_, err = shared.RunCommand("nft", config)
+	if err != nil {
+		return fmt.Errorf("Failed adding ACL rules for instance device %q: %w", deviceLabel, err)
+	}
+
+	return nil
+}
+
+// instanceParentACLRulesConfig renders the nftables config applying ACL rules to the instance NIC with deviceLabel
+// on its parent interface.
+func (d Nftables) instanceParentACLRulesConfig(deviceLabel string, parentName string, hwAddr string, rules []ACLRule) (string, error) {
+	mac, err := net.ParseMAC(hwAddr)
+	if err != nil {
+		return "", fmt.Errorf("Failed parsing MAC address %q: %w", hwAddr, err)
+	}
+
+	// Rejecting traffic isn't possible on the egress hook of the netdev family, so it is dropped instead.
+	netdevRules := make([]ACLRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Action == "reject" {
+			rule.Action = "drop"
+		}
+
+		netdevRules = append(netdevRules, rule)
+	}
+
+	nftIngressRules, nftEgressRules, err := d.instanceACLRulesToNftRules(netdevRules)
+	if err != nil {
+		return "", err
+	}
+
+	tplFields := map[string]any{
+		"namespace":      nftablesNamespace,
+		"chainSeparator": nftablesChainSeparator,
+		"deviceLabel":    deviceLabel,
+		"parentName":     parentName,
+		"hwAddr":         mac.String(),
+		"family":         "netdev",
+		"ingressRules":   nftIngressRules,
+		"egressRules":    nftEgressRules,
+	}
+
+	config := &strings.Builder{}
+	err = nftablesInstanceParentACLRules.Execute(config, tplFields)
+	if err != nil {
+		return "", fmt.Errorf("Failed running %q template: %w", nftablesInstanceParentACLRules.Name(), err)
+	}
+
+	return config.String(), nil
+}
+
+// instanceACLRulesToNftRules converts the ACL rules of an instance NIC into nftables rules for its ingress and
+// egress chains respectively.
+func (d Nftables) instanceACLRulesToNftRules(rules []ACLRule) ([]string, []string, error) {
+	var ingressRules []ACLRule
+	var egressRules []ACLRule
+	for _, rule := range rules {
+		if rule.Direction == "ingress" {
+			ingressRules = append(ingressRules, rule)
+		} else {
+			egressRules = append(egressRules, rule)
+		}
+	}
+
+	// The rules are split in per-direction chains and so don't need to match on an interface.
+	nftIngressRules, err := d.aclRulesToNftRules("", ingressRules)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nftEgressRules, err := d.aclRulesToNftRules("", egressRules)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return nftIngressRules, nftEgressRules, nil
+}
+
+// InstanceClearACLRules removes the ACL rules of an instance NIC.
+func (d Nftables) InstanceClearACLRules(projectName string, instanceName string, deviceName string) error {
+	deviceLabel := d.instanceDeviceLabel(projectName, instanceName, deviceName)
+
+	// Remove the base chains first as they jump to the rule chains.
+	err := d.removeChains([]string{"inet"}, deviceLabel, "aclin", "aclout", "aclfwd", "acling", "aclegr")
+	if err != nil {
+		return fmt.Errorf("Failed clearing ACL rules for instance device %q: %w", deviceLabel, err)
+	}
+
+	err = d.removeChains([]string{"netdev"}, deviceLabel, "aclpin", "aclpout", "acling", "aclegr")
+	if err != nil {
+		return fmt.Errorf("Failed clearing parent ACL rules for instance device %q: %w", deviceLabel, err)
+	}
+
+	return nil
+}
+
 // NetworkApplyACLRules applies ACL rules to the existing firewall chains.
 func (d Nftables) NetworkApplyACLRules(networkName string, rules []ACLRule) error {
+	nftRules, err := d.aclRulesToNftRules(networkName, rules)
+	if err != nil {
+		return err
+	}
+
+	tplFields := map[string]any{
+		"namespace":      nftablesNamespace,
+		"chainSeparator": nftablesChainSeparator,
+		"networkName":    networkName,
+		"family":         "inet",
+		"rules":          nftRules,
+	}
+	config := &strings.Builder{}
+	err = nftablesNetACLRules.Execute(config, tplFields)
+	if err != nil {
+		return fmt.Errorf("Failed running %q template: %w", nftablesNetACLRules.Name(), err)
+	}
+
+	_, err = shared.RunCommand("nft", config.String())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// aclRulesToNftRules converts ACL rules into nftables rules.
+// If networkName is empty, the rules don't match on the network's interface.
+func (d Nftables) aclRulesToNftRules(networkName string, rules []ACLRule) ([]string, error) {
 	nftRules := make([]string, 0)
 	for _, rule := range rules {
 		// First try generating rules with IPv4 or IP agnostic criteria.
 		nftRule, partial, err := d.aclRuleCriteriaToRules(networkName, 4, &rule)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		if nftRule != "" {
@@ -680,48 +875,32 @@ func (d Nftables) NetworkApplyACLRules(networkName string, rules []ACLRule) erro
 			// fill in the remaining parts using IPv6 criteria.
 			nftRule, _, err = d.aclRuleCriteriaToRules(networkName, 6, &rule)
 			if err != nil {
-				return err
+				return nil, err
 			}
 
 			if nftRule == "" {
-				return fmt.Errorf("Invalid empty rule generated")
+				return nil, fmt.Errorf("Invalid empty rule generated")
 			}
 
 			nftRules = append(nftRules, nftRule)
 		} else if nftRule == "" {
-			return fmt.Errorf("Invalid empty rule generated")
+			return nil, fmt.Errorf("Invalid empty rule generated")
 		}
 	}
 
-	tplFields := map[string]any{
-		"namespace":      nftablesNamespace,
-		"chainSeparator": nftablesChainSeparator,
-		"networkName":    networkName,
-		"family":         "inet",
-		"rules":          nftRules,
-	}
-	config := &strings.Builder{}
-	err := nftablesNetACLRules.Execute(config, tplFields)
-	if err != nil {
-		return fmt.Errorf("Failed running %q template: %w", nftablesNetACLRules.Name(), err)
-	}
-
-	_, err = shared.RunCommand("nft", config.String())
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return nftRules, nil
 }
 
 // aclRuleCriteriaToRules converts an ACL rule into 1 or more nftables rules.
 func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, rule *ACLRule) (string, bool, error) {
 	var args []string
 
-	if rule.Direction == "ingress" {
-		args = append(args, "oifname", networkName) // Coming from host into network's interface.
-	} else {
-		args = append(args, "iifname", networkName) // Coming from network's interface into host.
+	if networkName != "" {
+		if rule.Direction == "ingress" {
+			args = append(args, "oifname", networkName) // Coming from host into network's interface.
+		} else {
+			args = append(args, "iifname", networkName) // Coming from network's interface into host.
+		}
 	}
 
 	// Add subject filters.
@@ -1052,3 +1231,98 @@ func (d Nftables) NetworkApplyForwards(networkName string, rules []AddressForwar
 
 	return nil
 }
+
+// NetworkApplyLoadBalancers apply network load balancer rules to firewall.
+func (d Nftables) NetworkApplyLoadBalancers(networkName string, rules []LoadBalancer) error {
+	var dnatRules []map[string]any
+	var snatRules []map[string]any
+
+	for ruleIndex, rule := range rules {
+		if rule.ListenAddress == nil {
+			return fmt.Errorf("Invalid rule %d, listen address is required", ruleIndex)
+		}
+
+		if rule.Protocol == "" || len(rule.ListenPorts) < 1 {
+			return fmt.Errorf("Invalid rule %d, protocol and listen port(s) are required", ruleIndex)
+		}
+
+		if len(rule.Targets) < 1 {
+			return fmt.Errorf("Invalid rule %d, at least one target is required", ruleIndex)
+		}
+
+		ipFamily := "ip"
+		if rule.ListenAddress.To4() == nil {
+			ipFamily = "ip6"
+		}
+
+		listenAddressStr := rule.ListenAddress.String()
+
+		for i := range rule.ListenPorts {
+			targetMap := make([]string, 0, len(rule.Targets))
+
+			for targetIndex, target := range rule.Targets {
+				if target.Address == nil {
+					return fmt.Errorf("Invalid rule %d, target %d address is required", ruleIndex, targetIndex)
+				}
+
+				if (target.Address.To4() == nil) != (ipFamily == "ip6") {
+					return fmt.Errorf("Invalid rule %d, target %d address family doesn't match listen address", ruleIndex, targetIndex)
+				}
+
+				targetPort, err := loadBalancerTargetPort(rule.ListenPorts, target.Ports, i)
+				if err != nil {
+					return fmt.Errorf("Invalid rule %d, target %d: %w", ruleIndex, targetIndex, err)
+				}
+
+				targetAddressStr := target.Address.String()
+				targetMap = append(targetMap, fmt.Sprintf("%d : %s . %d", targetIndex, targetAddressStr, targetPort))
+
+				snatRules = append(snatRules, map[string]any{
+					"ipFamily":   ipFamily,
+					"protocol":   rule.Protocol,
+					"targetHost": targetAddressStr,
+					"targetPort": targetPort,
+				})
+			}
+
+			dnatRules = append(dnatRules, map[string]any{
+				"ipFamily":      ipFamily,
+				"protocol":      rule.Protocol,
+				"listenAddress": listenAddressStr,
+				"listenPort":    rule.ListenPorts[i],
+				"targetCount":   len(targetMap),
+				"targetMap":     strings.Join(targetMap, ", "),
+			})
+		}
+	}
+
+	tplFields := map[string]any{
+		"namespace":      nftablesNamespace,
+		"chainSeparator": nftablesChainSeparator,
+		"family":         "inet",
+		"label":          networkName,
+		"dnatRules":      dnatRules,
+		"snatRules":      snatRules,
+	}
+
+	// Apply rules or remove chains if no rules generated.
+	if len(dnatRules) > 0 {
+		config := &strings.Builder{}
+		err := nftablesNetLoadBalancerNAT.Execute(config, tplFields)
+		if err != nil {
+			return fmt.Errorf("Failed running %q template: %w", nftablesNetLoadBalancerNAT.Name(), err)
+		}
+
+		_, err = shared.RunCommand("nft", config.String())
+		if err != nil {
+			return err
+		}
+	} else {
+		err := d.removeChains([]string{"inet"}, networkName, "lbprert", "lbout", "lbpstrt")
+		if err != nil {
+			return fmt.Errorf("Failed clearing nftables load balancer rules for network %q: %w", networkName, err)
+		}
+	}
+
+	return nil
+}
@@ -109,6 +109,39 @@ table {{.family}} {{.namespace}} {
 }
 `))
 
+var nftablesNetLoadBalancerNAT = template.Must(template.New("nftablesNetLoadBalancerNAT").Parse(`
+add table {{.family}} {{.namespace}}
+add chain {{.family}} {{.namespace}} lbprert{{.chainSeparator}}{{.label}} {type nat hook prerouting priority -100; policy accept;}
+add chain {{.family}} {{.namespace}} lbout{{.chainSeparator}}{{.label}} {type nat hook output priority -100; policy accept;}
+add chain {{.family}} {{.namespace}} lbpstrt{{.chainSeparator}}{{.label}} {type nat hook postrouting priority 100; policy accept;}
+flush chain {{.family}} {{.namespace}} lbprert{{.chainSeparator}}{{.label}}
+flush chain {{.family}} {{.namespace}} lbout{{.chainSeparator}}{{.label}}
+flush chain {{.family}} {{.namespace}} lbpstrt{{.chainSeparator}}{{.label}}
+
+table {{.family}} {{.namespace}} {
+	chain lbprert{{.chainSeparator}}{{.label}} {
+		type nat hook prerouting priority -100; policy accept;
+		{{- range .dnatRules}}
+		{{.ipFamily}} daddr {{.listenAddress}} {{.protocol}} dport {{.listenPort}} dnat {{.ipFamily}} to numgen random mod {{.targetCount}} map { {{.targetMap}} }
+		{{- end}}
+	}
+
+	chain lbout{{.chainSeparator}}{{.label}} {
+		type nat hook output priority -100; policy accept;
+		{{- range .dnatRules}}
+		{{.ipFamily}} daddr {{.listenAddress}} {{.protocol}} dport {{.listenPort}} dnat {{.ipFamily}} to numgen random mod {{.targetCount}} map { {{.targetMap}} }
+		{{- end}}
+	}
+
+	chain lbpstrt{{.chainSeparator}}{{.label}} {
+		type nat hook postrouting priority 100; policy accept;
+		{{- range .snatRules}}
+		{{.ipFamily}} saddr {{.targetHost}} {{.ipFamily}} daddr {{.targetHost}} {{.protocol}} dport {{.targetPort}} masquerade
+		{{- end}}
+	}
+}
+`))
+
 var nftablesNetACLSetup = template.Must(template.New("nftablesNetACLSetup").Parse(`
 add table {{.family}} {{.namespace}}
 add chain {{.family}} {{.namespace}} acl{{.chainSeparator}}{{.networkName}}
@@ -174,6 +207,133 @@ table {{.family}} {{.namespace}} {
 }
 `))
 
+// nftablesInstanceACLRules defines the chains applying the ACL rules of an instance NIC that isn't connected to a
+// network. When a host name is provided, the NIC is matched by its host side interface, through which its traffic is
+// forwarded or exchanged with the LXD host. Otherwise it is matched by its addresses, as for IPVLAN NICs in l3s mode
+// whose traffic goes through the input and output hooks of the LXD host.
+var nftablesInstanceACLRules = template.Must(template.New("nftablesInstanceACLRules").Parse(`
+add table {{.family}} {{.namespace}}
+add chain {{.family}} {{.namespace}} acling{{.chainSeparator}}{{.deviceLabel}}
+add chain {{.family}} {{.namespace}} aclegr{{.chainSeparator}}{{.deviceLabel}}
+add chain {{.family}} {{.namespace}} aclin{{.chainSeparator}}{{.deviceLabel}} {type filter hook input priority filter; policy accept;}
+add chain {{.family}} {{.namespace}} aclout{{.chainSeparator}}{{.deviceLabel}} {type filter hook output priority filter; policy accept;}
+add chain {{.family}} {{.namespace}} aclfwd{{.chainSeparator}}{{.deviceLabel}} {type filter hook forward priority filter; policy accept;}
+flush chain {{.family}} {{.namespace}} acling{{.chainSeparator}}{{.deviceLabel}}
+flush chain {{.family}} {{.namespace}} aclegr{{.chainSeparator}}{{.deviceLabel}}
+flush chain {{.family}} {{.namespace}} aclin{{.chainSeparator}}{{.deviceLabel}}
+flush chain {{.family}} {{.namespace}} aclout{{.chainSeparator}}{{.deviceLabel}}
+flush chain {{.family}} {{.namespace}} aclfwd{{.chainSeparator}}{{.deviceLabel}}
+
+table {{.family}} {{.namespace}} {
+	chain aclin{{.chainSeparator}}{{.deviceLabel}} {
+		{{- if .hostName}}
+		# Allow core ICMPv4 and ICMPv6 from instance to LXD host.
+		iifname "{{.hostName}}" icmp type {3, 11, 12} accept
+		iifname "{{.hostName}}" icmpv6 type {1, 2, 3, 4, 133, 135, 136, 143} accept
+
+		iifname "{{.hostName}}" jump aclegr{{.chainSeparator}}{{.deviceLabel}}
+		{{- end}}
+		{{- if .ipv4Nets}}
+		ip daddr {{.ipv4Nets}} icmp type {3, 11, 12} accept
+		ip daddr {{.ipv4Nets}} jump acling{{.chainSeparator}}{{.deviceLabel}}
+		{{- end}}
+		{{- if .ipv6Nets}}
+		ip6 daddr {{.ipv6Nets}} icmpv6 type {1, 2, 3, 4} accept
+		ip6 daddr {{.ipv6Nets}} jump acling{{.chainSeparator}}{{.deviceLabel}}
+		{{- end}}
+	}
+
+	chain aclout{{.chainSeparator}}{{.deviceLabel}} {
+		{{- if .hostName}}
+		# Allow core ICMPv4 and ICMPv6 from LXD host to instance.
+		oifname "{{.hostName}}" icmp type {3, 11, 12} accept
+		oifname "{{.hostName}}" icmpv6 type {1, 2, 3, 4, 135, 136, 143} accept
+
+		oifname "{{.hostName}}" jump acling{{.chainSeparator}}{{.deviceLabel}}
+		{{- end}}
+		{{- if .ipv4Nets}}
+		ip saddr {{.ipv4Nets}} icmp type {3, 11, 12} accept
+		ip saddr {{.ipv4Nets}} jump aclegr{{.chainSeparator}}{{.deviceLabel}}
+		{{- end}}
+		{{- if .ipv6Nets}}
+		ip6 saddr {{.ipv6Nets}} icmpv6 type {1, 2, 3, 4} accept
+		ip6 saddr {{.ipv6Nets}} jump aclegr{{.chainSeparator}}{{.deviceLabel}}
+		{{- end}}
+	}
+
+	chain aclfwd{{.chainSeparator}}{{.deviceLabel}} {
+		{{- if .hostName}}
+		iifname "{{.hostName}}" jump aclegr{{.chainSeparator}}{{.deviceLabel}}
+		oifname "{{.hostName}}" jump acling{{.chainSeparator}}{{.deviceLabel}}
+		{{- end}}
+	}
+
+	chain acling{{.chainSeparator}}{{.deviceLabel}} {
+		ct state established,related accept
+
+		{{- range .ingressRules}}
+		{{.}}
+		{{- end}}
+	}
+
+	chain aclegr{{.chainSeparator}}{{.deviceLabel}} {
+		ct state established,related accept
+
+		{{- range .egressRules}}
+		{{.}}
+		{{- end}}
+	}
+}
+`))
+
+// nftablesInstanceParentACLRules defines the chains applying the ACL rules of an instance NIC whose traffic doesn't
+// go through the IP stack of the LXD host, such as macvlan NICs. The NIC is matched by its MAC address on the ingress
+// and egress hooks of its parent interface. Connection tracking isn't available at these hooks, so the ARP, core
+// ICMP, neighbor discovery and DHCP traffic of the NIC is allowed before the (stateless) ACL rules.
+var nftablesInstanceParentACLRules = template.Must(template.New("nftablesInstanceParentACLRules").Parse(`
+add table {{.family}} {{.namespace}}
+add chain {{.family}} {{.namespace}} acling{{.chainSeparator}}{{.deviceLabel}}
+add chain {{.family}} {{.namespace}} aclegr{{.chainSeparator}}{{.deviceLabel}}
+add chain {{.family}} {{.namespace}} aclpin{{.chainSeparator}}{{.deviceLabel}} {type filter hook ingress device "{{.parentName}}" priority filter; policy accept;}
+add chain {{.family}} {{.namespace}} aclpout{{.chainSeparator}}{{.deviceLabel}} {type filter hook egress device "{{.parentName}}" priority filter; policy accept;}
+flush chain {{.family}} {{.namespace}} acling{{.chainSeparator}}{{.deviceLabel}}
+flush chain {{.family}} {{.namespace}} aclegr{{.chainSeparator}}{{.deviceLabel}}
+flush chain {{.family}} {{.namespace}} aclpin{{.chainSeparator}}{{.deviceLabel}}
+flush chain {{.family}} {{.namespace}} aclpout{{.chainSeparator}}{{.deviceLabel}}
+
+table {{.family}} {{.namespace}} {
+	chain aclpin{{.chainSeparator}}{{.deviceLabel}} {
+		ether daddr {{.hwAddr}} ether type arp accept
+		ether daddr {{.hwAddr}} icmp type {3, 11, 12} accept
+		ether daddr {{.hwAddr}} icmpv6 type {1, 2, 3, 4, 133, 134, 135, 136, 143} accept
+		ether daddr {{.hwAddr}} udp sport 67 udp dport 68 accept
+		ether daddr {{.hwAddr}} udp sport 547 udp dport 546 accept
+		ether daddr {{.hwAddr}} jump acling{{.chainSeparator}}{{.deviceLabel}}
+	}
+
+	chain aclpout{{.chainSeparator}}{{.deviceLabel}} {
+		ether saddr {{.hwAddr}} ether type arp accept
+		ether saddr {{.hwAddr}} icmp type {3, 11, 12} accept
+		ether saddr {{.hwAddr}} icmpv6 type {1, 2, 3, 4, 133, 135, 136, 143} accept
+		ether saddr {{.hwAddr}} udp sport 68 udp dport 67 accept
+		ether saddr {{.hwAddr}} udp sport 546 udp dport 547 accept
+		ether saddr {{.hwAddr}} jump aclegr{{.chainSeparator}}{{.deviceLabel}}
+	}
+
+	chain acling{{.chainSeparator}}{{.deviceLabel}} {
+		{{- range .ingressRules}}
+		{{.}}
+		{{- end}}
+	}
+
+	chain aclegr{{.chainSeparator}}{{.deviceLabel}} {
+		{{- range .egressRules}}
+		{{.}}
+		{{- end}}
+	}
+}
+`))
+
 // nftablesInstanceBridgeFilter defines the rules needed for MAC, IPv4 and IPv6 bridge security filtering.
 // To prevent instances from using IPs that are different from their assigned IPs we use ARP and NDP filtering
 // to prevent neighbour advertisements that are not allowed. However in order for DHCPv4 & DHCPv6 to work back to
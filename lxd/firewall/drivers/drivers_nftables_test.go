@@ -0,0 +1,201 @@
+package drivers
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNftables_instanceACLRulesConfig(t *testing.T) {
+	mustParseCIDR := func(value string) *net.IPNet {
+		_, ipNet, err := net.ParseCIDR(value)
+		require.NoError(t, err)
+
+		return ipNet
+	}
+
+	rules := []ACLRule{
+		{Direction: "ingress", Action: "allow", Protocol: "tcp", DestinationPort: "22,80-81"},
+		{Direction: "ingress", Action: "allow", Source: "192.0.2.0/24,2001:db8::/32", Protocol: "udp", DestinationPort: "53"},
+		{Direction: "ingress", Action: "allow", Protocol: "icmp4", ICMPType: "8"},
+		{Direction: "ingress", Action: "drop", Log: true, LogName: "lxd_c1_eth0_in"},
+		{Direction: "egress", Action: "reject", Destination: "10.0.0.0/8", Log: true},
+		{Direction: "egress", Action: "allow", Protocol: "icmp6", ICMPType: "128", ICMPCode: "0"},
+	}
+
+	// The rule chains are the same whichever way the NIC's traffic is matched.
+	ruleChains := []string{
+		"add chain inet lxd acling.c1.eth0\nadd chain inet lxd aclegr.c1.eth0\n",
+		"add chain inet lxd aclin.c1.eth0 {type filter hook input priority filter; policy accept;}\n",
+		"add chain inet lxd aclout.c1.eth0 {type filter hook output priority filter; policy accept;}\n",
+		"add chain inet lxd aclfwd.c1.eth0 {type filter hook forward priority filter; policy accept;}\n",
+		"flush chain inet lxd acling.c1.eth0\n",
+		"flush chain inet lxd aclegr.c1.eth0\n",
+	}
+
+	ruleLines := []string{
+		"\t\tct state established,related accept\n\t\tmeta l4proto tcp th dport {22,80-81} accept\n",
+		"\t\tip saddr {192.0.2.0/24} meta l4proto udp th dport {53} accept\n\t\tip6 saddr {2001:db8::/32} meta l4proto udp th dport {53} accept\n",
+		"\t\tip protocol icmp icmp type 8 accept\n\t\tlog prefix \"lxd_c1_eth0_in \" drop\n\t}\n",
+		"\t\tct state established,related accept\n\t\tip daddr {10.0.0.0/8} log reject\n\t\tip6 nexthdr icmpv6 icmpv6 type 128 icmpv6 code 0 accept\n\t}\n",
+	}
+
+	tests := []struct {
+		name     string
+		hostName string
+		ipv4Nets []*net.IPNet
+		ipv6Nets []*net.IPNet
+		rules    []ACLRule
+		expected []string
+		excluded []string
+	}{
+		{
+			name:     "routed",
+			hostName: "veth1234abcd",
+			ipv4Nets: []*net.IPNet{mustParseCIDR("192.0.2.10/32")},
+			ipv6Nets: []*net.IPNet{mustParseCIDR("2001:db8::10/128")},
+			rules:    rules,
+			expected: append([]string{
+				"\t\tiifname \"veth1234abcd\" icmp type {3, 11, 12} accept\n\t\tiifname \"veth1234abcd\" icmpv6 type {1, 2, 3, 4, 133, 135, 136, 143} accept\n\n\t\tiifname \"veth1234abcd\" jump aclegr.c1.eth0\n",
+				"\t\toifname \"veth1234abcd\" icmp type {3, 11, 12} accept\n\t\toifname \"veth1234abcd\" icmpv6 type {1, 2, 3, 4, 135, 136, 143} accept\n\n\t\toifname \"veth1234abcd\" jump acling.c1.eth0\n",
+				"\tchain aclfwd.c1.eth0 {\n\t\tiifname \"veth1234abcd\" jump aclegr.c1.eth0\n\t\toifname \"veth1234abcd\" jump acling.c1.eth0\n\t}\n",
+			}, ruleLines...),
+			excluded: []string{"daddr {192.0.2.10/32}", "saddr {2001:db8::10/128}"},
+		},
+		{
+			name:     "ipvlan",
+			ipv4Nets: []*net.IPNet{mustParseCIDR("192.0.2.10/32"), mustParseCIDR("192.0.2.11/32")},
+			ipv6Nets: []*net.IPNet{mustParseCIDR("2001:db8::10/128")},
+			rules:    rules,
+			expected: append([]string{
+				"\t\tip daddr {192.0.2.10/32, 192.0.2.11/32} icmp type {3, 11, 12} accept\n\t\tip daddr {192.0.2.10/32, 192.0.2.11/32} jump acling.c1.eth0\n",
+				"\t\tip6 daddr {2001:db8::10/128} icmpv6 type {1, 2, 3, 4} accept\n\t\tip6 daddr {2001:db8::10/128} jump acling.c1.eth0\n",
+				"\t\tip saddr {192.0.2.10/32, 192.0.2.11/32} icmp type {3, 11, 12} accept\n\t\tip saddr {192.0.2.10/32, 192.0.2.11/32} jump aclegr.c1.eth0\n",
+				"\t\tip6 saddr {2001:db8::10/128} icmpv6 type {1, 2, 3, 4} accept\n\t\tip6 saddr {2001:db8::10/128} jump aclegr.c1.eth0\n",
+				"\tchain aclfwd.c1.eth0 {\n\t}\n",
+			}, ruleLines...),
+			excluded: []string{"iifname", "oifname"},
+		},
+		{
+			name:     "ipvlan-ipv4-no-rules",
+			ipv4Nets: []*net.IPNet{mustParseCIDR("192.0.2.10/32")},
+			expected: []string{
+				"\t\tip daddr {192.0.2.10/32} jump acling.c1.eth0\n",
+				"\t\tip saddr {192.0.2.10/32} jump aclegr.c1.eth0\n",
+				"\tchain acling.c1.eth0 {\n\t\tct state established,related accept\n\t}\n",
+				"\tchain aclegr.c1.eth0 {\n\t\tct state established,related accept\n\t}\n",
+			},
+			excluded: []string{"ip6 ", "icmpv6"},
+		},
+	}
+
+	d := Nftables{}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config, err := d.instanceACLRulesConfig("c1.eth0", test.hostName, test.ipv4Nets, test.ipv6Nets, test.rules)
+			require.NoError(t, err)
+
+			for _, expected := range ruleChains {
+				assert.Contains(t, config, expected)
+			}
+
+			for _, expected := range test.expected {
+				assert.Contains(t, config, expected)
+			}
+
+			for _, excluded := range test.excluded {
+				assert.NotContains(t, config, excluded)
+			}
+		})
+	}
+
+	// Invalid rules aren't rendered.
+	_, err := d.instanceACLRulesConfig("c1.eth0", "veth1234abcd", nil, nil, []ACLRule{
+		{Direction: "egress", Action: "allow", Destination: "2001:db8::/32", Protocol: "icmp4"},
+	})
+	assert.ErrorContains(t, err, `Invalid use of "icmp4" protocol`)
+}
+
+func TestNftables_instanceParentACLRulesConfig(t *testing.T) {
+	rules := []ACLRule{
+		{Direction: "ingress", Action: "allow", Protocol: "tcp", DestinationPort: "22"},
+		{Direction: "ingress", Action: "reject", Log: true, LogName: "mac1234abcd-ingress"},
+		{Direction: "egress", Action: "reject", Destination: "10.0.0.0/8"},
+		{Direction: "egress", Action: "allow"},
+	}
+
+	d := Nftables{}
+
+	config, err := d.instanceParentACLRulesConfig("c1.eth0", "eth0.100", "00:16:3E:AA:BB:CC", rules)
+	require.NoError(t, err)
+
+	// The NIC is matched by its MAC address on the hooks of its parent.
+	for _, expected := range []string{
+		"add table netdev lxd\n",
+		"add chain netdev lxd aclpin.c1.eth0 {type filter hook ingress device \"eth0.100\" priority filter; policy accept;}\n",
+		"add chain netdev lxd aclpout.c1.eth0 {type filter hook egress device \"eth0.100\" priority filter; policy accept;}\n",
+		"\t\tether daddr 00:16:3e:aa:bb:cc ether type arp accept\n",
+		"\t\tether daddr 00:16:3e:aa:bb:cc udp sport 67 udp dport 68 accept\n",
+		"\t\tether daddr 00:16:3e:aa:bb:cc jump acling.c1.eth0\n",
+		"\t\tether saddr 00:16:3e:aa:bb:cc ether type arp accept\n",
+		"\t\tether saddr 00:16:3e:aa:bb:cc udp sport 68 udp dport 67 accept\n",
+		"\t\tether saddr 00:16:3e:aa:bb:cc jump aclegr.c1.eth0\n",
+		"\tchain acling.c1.eth0 {\n\t\tmeta l4proto tcp th dport {22} accept\n\t\tlog prefix \"mac1234abcd-ingress \" drop\n\t}\n",
+		"\tchain aclegr.c1.eth0 {\n\t\tip daddr {10.0.0.0/8} drop\n\t\taccept\n\t}\n",
+	} {
+		assert.Contains(t, config, expected)
+	}
+
+	// Connection tracking and rejecting aren't available on the netdev hooks.
+	assert.NotContains(t, config, "ct state")
+	assert.NotContains(t, config, "reject")
+
+	// The MAC address is validated.
+	_, err = d.instanceParentACLRulesConfig("c1.eth0", "eth0", "foo", rules)
+	assert.Error(t, err)
+}
+
+func TestNftables_aclRulesToNftRules(t *testing.T) {
+	tests := []struct {
+		name        string
+		networkName string
+		rule        ACLRule
+		expected    []string
+	}{
+		{
+			name:        "network ingress",
+			networkName: "lxdbr0",
+			rule:        ACLRule{Direction: "ingress", Action: "allow", Protocol: "tcp", DestinationPort: "22"},
+			expected:    []string{"oifname lxdbr0 meta l4proto tcp th dport {22} accept"},
+		},
+		{
+			name:        "network egress",
+			networkName: "lxdbr0",
+			rule:        ACLRule{Direction: "egress", Action: "drop", Destination: "10.0.0.0/8"},
+			expected:    []string{"iifname lxdbr0 ip daddr {10.0.0.0/8} drop"},
+		},
+		{
+			name:     "instance ingress",
+			rule:     ACLRule{Direction: "ingress", Action: "allow", Protocol: "tcp", DestinationPort: "22"},
+			expected: []string{"meta l4proto tcp th dport {22} accept"},
+		},
+		{
+			name:     "instance mixed families with logging",
+			rule:     ACLRule{Direction: "egress", Action: "reject", Destination: "10.0.0.0/8,fd00::/8", Log: true, LogName: "c1"},
+			expected: []string{`ip daddr {10.0.0.0/8} log prefix "c1 " reject`, `ip6 daddr {fd00::/8} log prefix "c1 " reject`},
+		},
+	}
+
+	d := Nftables{}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			nftRules, err := d.aclRulesToNftRules(test.networkName, []ACLRule{test.rule})
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, nftRules)
+		})
+	}
+}
@@ -110,6 +110,26 @@ func getOptimisedDNATRanges(forward *AddressForward) map[[2]uint64][2]uint64 {
 	return snatRules
 }
 
+// loadBalancerTargetPort returns the target port that should be used for the listen port at the specified index.
+// If no target ports are specified then the listen port is used. If a single target port is specified then it is
+// used for all listen ports. Otherwise the target port at the same index as the listen port is used.
+func loadBalancerTargetPort(listenPorts []uint64, targetPorts []uint64, listenPortIndex int) (uint64, error) {
+	if listenPortIndex < 0 || listenPortIndex >= len(listenPorts) {
+		return 0, fmt.Errorf("Invalid listen port index %d", listenPortIndex)
+	}
+
+	switch len(targetPorts) {
+	case 0:
+		return listenPorts[listenPortIndex], nil
+	case 1:
+		return targetPorts[0], nil
+	case len(listenPorts):
+		return targetPorts[listenPortIndex], nil
+	}
+
+	return 0, fmt.Errorf("Mismatch between listen port(s) and target port(s) count")
+}
+
 // subnetMask returns the subnet mask of the given network as a string. Both IPv4 and IPv6 are handled.
 func subnetMask(ipNet *net.IPNet) string {
 	if ipNet.IP.To4() != nil {
@@ -143,3 +143,60 @@ func Test_getOptimisedSNATRanges(t *testing.T) {
 		assert.Equal(t, tt.expected, actual)
 	}
 }
+
+func Test_loadBalancerTargetPort(t *testing.T) {
+	tests := []struct {
+		name        string
+		listenPorts []uint64
+		targetPorts []uint64
+		index       int
+		expected    uint64
+		expectErr   bool
+	}{
+		{
+			name:        "No target ports",
+			listenPorts: []uint64{80, 81},
+			index:       1,
+			expected:    81,
+		},
+		{
+			name:        "Single target port",
+			listenPorts: []uint64{80, 81},
+			targetPorts: []uint64{8080},
+			index:       1,
+			expected:    8080,
+		},
+		{
+			name:        "Equal number of target ports",
+			listenPorts: []uint64{80, 81},
+			targetPorts: []uint64{8080, 8081},
+			index:       1,
+			expected:    8081,
+		},
+		{
+			name:        "Mismatched number of target ports",
+			listenPorts: []uint64{80, 81, 82},
+			targetPorts: []uint64{8080, 8081},
+			index:       0,
+			expectErr:   true,
+		},
+		{
+			name:        "Out of range index",
+			listenPorts: []uint64{80},
+			index:       1,
+			expectErr:   true,
+		},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+		port, err := loadBalancerTargetPort(tt.listenPorts, tt.targetPorts, tt.index)
+		if tt.expectErr {
+			assert.Error(t, err)
+			continue
+		}
+
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, port)
+	}
+}
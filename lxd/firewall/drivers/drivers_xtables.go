@@ -163,6 +163,12 @@ func (d Xtables) networkForwardIPTablesComment(networkName string) string {
 	return fmt.Sprintf("LXD network-forward %s", networkName)
 }
 
+// networkLoadBalancerIPTablesComment returns the iptables comment that is added to each network load balancer
+// related rule.
+func (d Xtables) networkLoadBalancerIPTablesComment(networkName string) string {
+	return fmt.Sprintf("LXD network-load-balancer %s", networkName)
+}
+
 // networkSetupNICFilteringChain creates the NIC filtering chain if it doesn't exist, and adds the jump rules to
 // the INPUT and FORWARD filter chains. Must be called after networkSetupForwardingPolicy so that the rules are
 // prepended before the default fowarding policy rules.
@@ -752,10 +758,11 @@ func (d Xtables) NetworkClear(networkName string, delete bool, ipVersions []uint
 	comments := []string{
 		d.networkIPTablesComment(networkName),
 		d.networkForwardIPTablesComment(networkName),
+		d.networkLoadBalancerIPTablesComment(networkName),
 	}
 
 	for _, ipVersion := range ipVersions {
-		// Clear any rules associated to the network and network address forwards.
+		// Clear any rules associated to the network, network address forwards and load balancers.
 		err := d.iptablesClear(ipVersion, comments, "filter", "mangle", "nat")
 		if err != nil {
 			return err
@@ -1577,3 +1584,109 @@ func (d Xtables) NetworkApplyForwards(networkName string, rules []AddressForward
 
 	return nil
 }
+
+// InstanceSetupACLRules returns an error as ACL rules on instance NICs are only supported with nftables.
+func (d Xtables) InstanceSetupACLRules(projectName string, instanceName string, deviceName string, hostName string, IPv4Nets []*net.IPNet, IPv6Nets []*net.IPNet, rules []ACLRule) error {
+	return fmt.Errorf("Network ACLs on instance NICs require the nftables firewall driver")
+}
+
+// InstanceSetupParentACLRules returns an error as ACL rules on instance NICs are only supported with nftables.
+func (d Xtables) InstanceSetupParentACLRules(projectName string, instanceName string, deviceName string, parentName string, hwAddr string, rules []ACLRule) error {
+	return fmt.Errorf("Network ACLs on instance NICs require the nftables firewall driver")
+}
+
+// InstanceClearACLRules does nothing as ACL rules on instance NICs are only supported with nftables.
+func (d Xtables) InstanceClearACLRules(projectName string, instanceName string, deviceName string) error {
+	return nil
+}
+
+// NetworkApplyLoadBalancers apply network load balancer rules to firewall.
+func (d Xtables) NetworkApplyLoadBalancers(networkName string, rules []LoadBalancer) error {
+	comment := d.networkLoadBalancerIPTablesComment(networkName)
+
+	// Clear any load balancer rules associated to the network.
+	for _, ipVersion := range []uint{4, 6} {
+		err := d.iptablesClear(ipVersion, []string{comment}, "nat")
+		if err != nil {
+			return err
+		}
+	}
+
+	for ruleIndex, rule := range rules {
+		if rule.ListenAddress == nil {
+			return fmt.Errorf("Invalid rule %d, listen address is required", ruleIndex)
+		}
+
+		if rule.Protocol == "" || len(rule.ListenPorts) < 1 {
+			return fmt.Errorf("Invalid rule %d, protocol and listen port(s) are required", ruleIndex)
+		}
+
+		targetsLen := len(rule.Targets)
+		if targetsLen < 1 {
+			return fmt.Errorf("Invalid rule %d, at least one target is required", ruleIndex)
+		}
+
+		ipVersion := uint(4)
+		if rule.ListenAddress.To4() == nil {
+			ipVersion = 6
+		}
+
+		listenAddressStr := rule.ListenAddress.String()
+
+		for i := range rule.ListenPorts {
+			listenPortStr := fmt.Sprintf("%d", rule.ListenPorts[i])
+
+			// Rules are prepended, so add them in reverse order so that the first target is evaluated first.
+			// Each target's rule matches with a probability of 1/(remaining targets) so that connections
+			// are evenly spread, with the last target matching any remaining connections.
+			for targetIndex := targetsLen - 1; targetIndex >= 0; targetIndex-- {
+				target := rule.Targets[targetIndex]
+				if target.Address == nil {
+					return fmt.Errorf("Invalid rule %d, target %d address is required", ruleIndex, targetIndex)
+				}
+
+				if (target.Address.To4() == nil) != (ipVersion == 6) {
+					return fmt.Errorf("Invalid rule %d, target %d address family doesn't match listen address", ruleIndex, targetIndex)
+				}
+
+				targetPort, err := loadBalancerTargetPort(rule.ListenPorts, target.Ports, i)
+				if err != nil {
+					return fmt.Errorf("Invalid rule %d, target %d: %w", ruleIndex, targetIndex, err)
+				}
+
+				targetAddressStr := target.Address.String()
+
+				// Format the destination host/port as appropriate.
+				targetDest := fmt.Sprintf("%s:%d", targetAddressStr, targetPort)
+				if ipVersion == 6 {
+					targetDest = fmt.Sprintf("[%s]:%d", targetAddressStr, targetPort)
+				}
+
+				var statisticArgs []string
+				if targetIndex < targetsLen-1 {
+					statisticArgs = []string{"-m", "statistic", "--mode", "random", "--probability", fmt.Sprintf("%.5f", 1/float64(targetsLen-targetIndex))}
+				}
+
+				for _, chain := range []string{"PREROUTING", "OUTPUT"} {
+					args := []string{"-p", rule.Protocol, "--destination", listenAddressStr, "--dport", listenPortStr}
+					args = append(args, statisticArgs...)
+					args = append(args, "-j", "DNAT", "--to-destination", targetDest)
+
+					err = d.iptablesPrepend(ipVersion, comment, "nat", chain, args...)
+					if err != nil {
+						return err
+					}
+				}
+
+				// instance <-> instance.
+				// Requires instance's bridge port has hairpin mode enabled when br_netfilter is loaded.
+				err = d.iptablesPrepend(ipVersion, comment, "nat", "POSTROUTING", "-p", rule.Protocol, "--source", targetAddressStr, "--destination", targetAddressStr, "--dport", fmt.Sprintf("%d", targetPort), "-j", "MASQUERADE")
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -12,9 +12,12 @@ import (
 
 	"github.com/lxc/lxd/lxd/cluster"
 	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/device"
+	deviceConfig "github.com/lxc/lxd/lxd/device/config"
 	"github.com/lxc/lxd/lxd/instance"
 	"github.com/lxc/lxd/lxd/instance/instancetype"
 	"github.com/lxc/lxd/lxd/instance/operationlock"
+	"github.com/lxc/lxd/lxd/oci"
 	"github.com/lxc/lxd/lxd/operations"
 	"github.com/lxc/lxd/lxd/project"
 	"github.com/lxc/lxd/lxd/revert"
@@ -23,6 +26,7 @@ import (
 	"github.com/lxc/lxd/lxd/task"
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/logger"
+	"github.com/lxc/lxd/shared/units"
 )
 
 // Helper functions
@@ -142,6 +146,14 @@ func instanceCreateFromImage(d *Daemon, r *http.Request, args db.InstanceArgs, h
 		for k, v := range img.Properties {
 			args.Config[fmt.Sprintf("image.%s", k)] = v
 		}
+
+		// Run images built from OCI images the way their config specifies, unless overridden.
+		for k, v := range oci.InstanceConfig(img.Properties) {
+			_, found := args.Config[k]
+			if !found {
+				args.Config[k] = v
+			}
+		}
 	}
 
 	// Set the BaseImage field (regardless of previous value).
@@ -258,40 +270,7 @@ func instanceCreateAsCopy(s *state.State, opts instanceCreateAsCopyOpts, op *ope
 		}
 
 		for _, srcSnap := range snapshots {
-			snapLocalDevices := srcSnap.LocalDevices().Clone()
-
-			// Load snap root disk from expanded devices (in case it doesn't have its own root disk).
-			snapExpandedRootDiskDevKey, snapExpandedRootDiskDev, err := shared.GetRootDiskDevice(srcSnap.ExpandedDevices().CloneNative())
-			if err == nil {
-				// If the expanded devices has a root disk, but its pool doesn't match our new
-				// parent instance's pool, then either modify the device if it is local or add a
-				// new one to local devices if its coming from the profiles.
-				if snapExpandedRootDiskDev["pool"] != instRootDiskDevice["pool"] {
-					if localRootDiskDev, found := snapLocalDevices[snapExpandedRootDiskDevKey]; found {
-						// Modify exist local device's pool.
-						localRootDiskDev["pool"] = instRootDiskDevice["pool"]
-						snapLocalDevices[snapExpandedRootDiskDevKey] = localRootDiskDev
-					} else {
-						// Add a new local device using parent instance's pool.
-						snapLocalDevices[instRootDiskDeviceKey] = map[string]string{
-							"type": "disk",
-							"path": "/",
-							"pool": instRootDiskDevice["pool"],
-						}
-					}
-				}
-			} else if errors.Is(err, shared.ErrNoRootDisk) {
-				// If no root disk defined in either local devices or profiles, then add one to the
-				// snapshot local devices using the same device name from the parent instance.
-				snapLocalDevices[instRootDiskDeviceKey] = map[string]string{
-					"type": "disk",
-					"path": "/",
-					"pool": instRootDiskDevice["pool"],
-				}
-			} else {
-				// Snapshot has multiple root disk devices, we can't automatically fix this so
-				// leave alone so we don't prevent copy.
-			}
+			snapLocalDevices := snapshotLocalDevicesWithPool(srcSnap, instRootDiskDeviceKey, instRootDiskDevice["pool"])
 
 			fields := strings.SplitN(srcSnap.Name(), shared.SnapshotDelimiter, 2)
 			newSnapName := fmt.Sprintf("%s/%s", inst.Name(), fields[1])
@@ -358,6 +337,71 @@ func instanceCreateAsCopy(s *state.State, opts instanceCreateAsCopyOpts, op *ope
 	return inst, nil
 }
 
+// snapshotLocalDevicesWithPool returns a copy of the snapshot's local devices with its root disk on the pool.
+// If the snapshot's root disk comes from its profiles, or it has none, a local root disk is added using the
+// parent instance's root disk device name rootDevKey.
+func snapshotLocalDevicesWithPool(snap instance.Instance, rootDevKey string, pool string) deviceConfig.Devices {
+	snapLocalDevices := snap.LocalDevices().Clone()
+
+	// Load snap root disk from expanded devices (in case it doesn't have its own root disk).
+	snapExpandedRootDiskDevKey, snapExpandedRootDiskDev, err := shared.GetRootDiskDevice(snap.ExpandedDevices().CloneNative())
+	if err == nil {
+		// If the expanded devices has a root disk, but its pool doesn't match our new
+		// parent instance's pool, then either modify the device if it is local or add a
+		// new one to local devices if its coming from the profiles.
+		if snapExpandedRootDiskDev["pool"] != pool {
+			if localRootDiskDev, found := snapLocalDevices[snapExpandedRootDiskDevKey]; found {
+				// Modify exist local device's pool.
+				localRootDiskDev["pool"] = pool
+				snapLocalDevices[snapExpandedRootDiskDevKey] = localRootDiskDev
+			} else {
+				// Add a new local device using parent instance's pool.
+				snapLocalDevices[rootDevKey] = map[string]string{
+					"type": "disk",
+					"path": "/",
+					"pool": pool,
+				}
+			}
+		}
+	} else if errors.Is(err, shared.ErrNoRootDisk) {
+		// If no root disk defined in either local devices or profiles, then add one to the
+		// snapshot local devices using the same device name from the parent instance.
+		snapLocalDevices[rootDevKey] = map[string]string{
+			"type": "disk",
+			"path": "/",
+			"pool": pool,
+		}
+	} else {
+		// Snapshot has multiple root disk devices, we can't automatically fix this so
+		// leave alone so we don't prevent copy.
+	}
+
+	return snapLocalDevices
+}
+
+// instanceMoveDiskLive mirrors a disk device of the running VM to the volume at diskPath on the pool and then
+// switches the VM over to it. The progress is reported through the operation's metadata.
+func instanceMoveDiskLive(vm instance.VM, devName string, pool storagePools.Pool, diskPath string, op *operations.Operation) error {
+	target := deviceConfig.MountEntryItem{DevPath: diskPath}
+	if pool.Driver().Info().DirectIO {
+		target.Opts = append(target.Opts, device.DiskDirectIO)
+	}
+
+	return vm.MoveDiskLive(devName, target, func(done int64, total int64) {
+		if op == nil || total <= 0 {
+			return
+		}
+
+		meta := op.Metadata()
+		if meta == nil {
+			meta = make(map[string]any)
+		}
+
+		meta["move_progress"] = fmt.Sprintf("%s: %d%% (%s/%s)", devName, done*100/total, units.GetByteSizeString(done, 2), units.GetByteSizeString(total, 2))
+		op.UpdateMetadata(meta)
+	})
+}
+
 // Load all instances of this nodes under the given project.
 func instanceLoadNodeProjectAll(s *state.State, project string, instanceType instancetype.Type) ([]instance.Instance, error) {
 	// Get all the container arguments
@@ -495,6 +539,13 @@ func autoCreateContainerSnapshots(ctx context.Context, d *Daemon, instances []in
 			err = c.Snapshot(snapshotName, expiry, false)
 			if err != nil {
 				logger.Error("Error creating snapshots", logger.Ctx{"err": err, "container": c})
+				ch <- nil
+				return
+			}
+
+			// Queue pushing the new snapshot to the replication target if configured.
+			if c.ExpandedConfig()["snapshots.replicate.target"] != "" {
+				instanceReplicateQueue(d.State(), c, snapshotName)
 			}
 
 			ch <- nil
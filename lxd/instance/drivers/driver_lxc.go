@@ -1010,6 +1010,37 @@ func (d *lxc) initLXC(config bool) error {
 		}
 	}
 
+	// Setup init for containers running an application image.
+	if d.expandedConfig["oci.entrypoint"] != "" {
+		err = lxcSetConfigItem(cc, "lxc.init.cmd", d.expandedConfig["oci.entrypoint"])
+		if err != nil {
+			return err
+		}
+
+		initKeys := map[string]string{
+			"oci.cwd": "lxc.init.cwd",
+			"oci.uid": "lxc.init.uid",
+			"oci.gid": "lxc.init.gid",
+		}
+
+		for key, lxcKey := range initKeys {
+			if d.expandedConfig[key] == "" {
+				continue
+			}
+
+			err = lxcSetConfigItem(cc, lxcKey, d.expandedConfig[key])
+			if err != nil {
+				return err
+			}
+		}
+
+		// Applications expect SIGTERM rather than SIGPWR to shut down cleanly.
+		err = lxcSetConfigItem(cc, "lxc.signal.halt", "SIGTERM")
+		if err != nil {
+			return err
+		}
+	}
+
 	// Setup NVIDIA runtime
 	if shared.IsTrue(d.expandedConfig["nvidia.runtime"]) {
 		hookDir := os.Getenv("LXD_LXC_HOOK")
@@ -88,6 +88,18 @@ const qemuNetDevIDPrefix = "lxd_"
 // qemuBlockDevIDPrefix used as part of the name given QEMU blockdevs generated from user added devices.
 const qemuBlockDevIDPrefix = "lxd_"
 
+// qemuMemoryHotplugSlots is the number of memory slots available for hot-plugging memory.
+const qemuMemoryHotplugSlots = 8
+
+// qemuMemoryHotplugBlockSize is the alignment of hot-plugged memory, matching the Linux memory block size.
+const qemuMemoryHotplugBlockSize = 128 * 1024 * 1024
+
+// qemuCPUIDPrefix used as part of the name given QEMU vCPUs that are hot-plugged.
+const qemuCPUIDPrefix = "lxd_cpu"
+
+// qemuMemoryIDPrefix used as part of the name given QEMU memory devices and backends that are hot-plugged.
+const qemuMemoryIDPrefix = "lxd_memory"
+
 // qemuSparseUSBPorts is the amount of sparse USB ports for VMs.
 // 4 are reserved, and the other 4 can be used for any USB device.
 const qemuSparseUSBPorts = 8
@@ -319,6 +331,9 @@ type qemu struct {
 	// Do not use these variables directly, instead use their associated get functions so they
 	// will be initialised on demand.
 	architectureName string
+
+	// Set while starting the VM from a live migration stream rather than a state file.
+	migrationReceive *instance.LiveMigrateReceiveArgs
 }
 
 // getAgentClient returns the current agent client handle. To avoid TLS setup each time this
@@ -617,6 +632,12 @@ func (d *qemu) onStop(target string) error {
 		return err
 	}
 
+	// Remove the instance volume left behind on the source pool by a live move to another pool.
+	err = d.moveCleanup()
+	if err != nil {
+		d.logger.Error("Failed removing instance volume from previous storage pool", logger.Ctx{"err": err})
+	}
+
 	// Unload the apparmor profile
 	err = apparmor.InstanceUnload(d.state.OS, d)
 	if err != nil {
@@ -945,6 +966,18 @@ func (d *qemu) validateStartup(stateful bool) error {
 		return fmt.Errorf("Stateful start requires migration.stateful to be set to true")
 	}
 
+	// Check that the guest memory encryption can be used on this host.
+	err = qemuValidateMemoryEncryption(d.expandedConfig, d.architecture, resources.GetCPUMemoryEncryption())
+	if err != nil {
+		return err
+	}
+
+	// Check that the host NUMA nodes the instance is placed on can be used.
+	err = d.validateNUMANodes()
+	if err != nil {
+		return err
+	}
+
 	// The "size.state" of the instance root disk device must be larger than the instance memory.
 	// Otherwise, there will not be enough disk space to write the instance state to disk during any subsequent stops.
 	// (Only check when migration.stateful is true, otherwise the memory won't be dumped when this instance stops).
@@ -1039,6 +1072,12 @@ func (d *qemu) Start(stateful bool) error {
 		}
 	}
 
+	// Remove the instance volume left behind on the source pool by a live move, in case it failed on stop.
+	err = d.moveCleanup()
+	if err != nil {
+		d.logger.Warn("Failed removing instance volume from previous storage pool", logger.Ctx{"err": err})
+	}
+
 	// Mount the instance's config volume.
 	mountInfo, err := d.mount()
 	if err != nil {
@@ -1064,6 +1103,11 @@ func (d *qemu) Start(stateful bool) error {
 		volatileSet["volatile.uuid"] = instUUID
 	}
 
+	// The root disk is about to diverge from the last backup, without the writes being tracked yet.
+	if d.localConfig["volatile.backup.checkpoint"] != "" {
+		volatileSet["volatile.backup.checkpoint"] = ""
+	}
+
 	// Apply any volatile changes that need to be made.
 	err = d.VolatileSet(volatileSet)
 	if err != nil {
@@ -1486,6 +1530,13 @@ func (d *qemu) Start(stateful bool) error {
 		}
 	}
 
+	// Bind the vCPUs to their host NUMA node.
+	err = d.setNUMAAffinity(pids)
+	if err != nil {
+		op.Done(err)
+		return err
+	}
+
 	// Run monitor hooks from devices.
 	for _, monHook := range monHooks {
 		err = monHook(monitor)
@@ -1507,7 +1558,12 @@ func (d *qemu) Start(stateful bool) error {
 
 	// Restore the state.
 	if stateful {
-		err := d.restoreState(monitor)
+		if d.migrationReceive != nil {
+			err = d.migrateReceiveState(monitor)
+		} else {
+			err = d.restoreState(monitor)
+		}
+
 		if err != nil {
 			op.Done(err)
 			return err
@@ -1789,7 +1845,11 @@ func (d *qemu) deviceDetachBlockDevice(deviceName string, rawConfig deviceConfig
 
 	escapedDeviceName := filesystem.PathNameEncode(deviceName)
 	deviceID := fmt.Sprintf("%s%s", qemuDeviceIDPrefix, escapedDeviceName)
-	blockDevName := fmt.Sprintf("%s%s", qemuBlockDevIDPrefix, escapedDeviceName)
+
+	blockDevName, err := d.blockNodeName(monitor, deviceName)
+	if err != nil {
+		return err
+	}
 
 	err = monitor.RemoveFDFromFDSet(blockDevName)
 	if err != nil {
@@ -2466,13 +2526,32 @@ func (d *qemu) generateQemuConfigFile(mountInfo *storagePools.MountInfo, busName
 	var sb *strings.Builder = &strings.Builder{}
 	var monHooks []monitorHook
 
+	// Intel TDX guests boot from a single firmware volume rather than the usual firmware drives.
+	encryption := qemuMemoryEncryption(d.expandedConfig)
+	tdxFirmware := ""
+	if encryption == "tdx" {
+		var err error
+
+		tdxFirmware, err = d.tdxFirmwarePath()
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
 	err := qemuBase.Execute(sb, map[string]any{
-		"architecture": d.architectureName,
+		"architecture":     d.architectureName,
+		"memoryEncryption": encryption,
+		"firmware":         tdxFirmware,
 	})
 	if err != nil {
 		return "", nil, err
 	}
 
+	err = d.addMemoryEncryptionConfig(sb, encryption)
+	if err != nil {
+		return "", nil, err
+	}
+
 	cpuCount, err := d.addCPUMemoryConfig(sb)
 	if err != nil {
 		return "", nil, err
@@ -2490,7 +2569,7 @@ func (d *qemu) generateQemuConfigFile(mountInfo *storagePools.MountInfo, busName
 	// Allow disabling the UEFI firmware.
 	if shared.StringInSlice("-bios", rawOptions) || shared.StringInSlice("-kernel", rawOptions) {
 		d.logger.Warn("Starting VM without default firmware (-bios or -kernel in raw.qemu)")
-	} else {
+	} else if tdxFirmware == "" {
 		err = qemuDriveFirmware.Execute(sb, map[string]any{
 			"architecture": d.architectureName,
 			"roPath":       filepath.Join(d.ovmfPath(), "OVMF_CODE.fd"),
@@ -2813,14 +2892,56 @@ func (d *qemu) addCPUMemoryConfig(sb *strings.Builder) (int, error) {
 	}
 
 	cpuCount, err := strconv.Atoi(cpus)
-	hostNodes := []uint64{}
+	hostNodes := [][]uint64{}
+	placedNodes := []qemuNUMANode{}
 	if err == nil {
 		// If not pinning, default to exposing cores.
 		ctx["cpuCount"] = cpuCount
 		ctx["cpuSockets"] = 1
 		ctx["cpuCores"] = cpuCount
 		ctx["cpuThreads"] = 1
-		hostNodes = []uint64{0}
+		hostNodes = [][]uint64{{0}}
+
+		// Lay out the explicitly placed NUMA nodes, if any.
+		placedNodes, err = qemuNUMANodes(d.expandedConfig, cpuCount)
+		if err != nil {
+			return -1, err
+		}
+
+		if len(placedNodes) > 0 {
+			hostNodes = [][]uint64{}
+			numa := []map[string]uint64{}
+			numaIDs := []uint64{}
+			for i, node := range placedNodes {
+				hostNodes = append(hostNodes, node.memoryNodes)
+
+				numaIDs = append(numaIDs, uint64(i))
+				for _, vcpu := range node.vcpus {
+					numa = append(numa, map[string]uint64{
+						"node":   uint64(i),
+						"socket": 0,
+						"core":   vcpu,
+						"thread": 0,
+					})
+				}
+			}
+
+			ctx["cpuNumaNodes"] = numaIDs
+			ctx["cpuNumaMapping"] = numa
+			ctx["cpuNumaHostNodes"] = hostNodes
+		}
+
+		// Allow hot-plugging vCPUs up to the number of host CPUs.
+		if sb != nil && qemuCanHotplug(d.architecture, d.expandedConfig) {
+			cpuMaxCount := cpuCount
+			hostCPUs, err := resources.GetCPU()
+			if err == nil && int(hostCPUs.Total) > cpuMaxCount {
+				cpuMaxCount = int(hostCPUs.Total)
+			}
+
+			ctx["cpuMaxCount"] = cpuMaxCount
+			ctx["cpuCores"] = cpuMaxCount
+		}
 	} else {
 		// Expand to a set of CPU identifiers and get the pinning map.
 		nrSockets, nrCores, nrThreads, vcpus, numaNodes, err := d.cpuTopology(cpus)
@@ -2849,7 +2970,7 @@ func (d *qemu) addCPUMemoryConfig(sb *strings.Builder) (int, error) {
 		numaIDs := []uint64{}
 		numaNode := uint64(0)
 		for hostNode, entry := range numaNodes {
-			hostNodes = append(hostNodes, hostNode)
+			hostNodes = append(hostNodes, []uint64{hostNode})
 
 			numaIDs = append(numaIDs, numaNode)
 			for _, vcpu := range entry {
@@ -2902,10 +3023,24 @@ func (d *qemu) addCPUMemoryConfig(sb *strings.Builder) (int, error) {
 	ctx["memory"] = nodeMemory
 
 	if sb != nil {
-		err = qemuMemory.Execute(sb, map[string]any{
+		memCtx := map[string]any{
 			"architecture": d.architectureName,
 			"memSizeBytes": memSizeBytes,
-		})
+		}
+
+		// Allow hot-plugging memory up to the amount of host memory.
+		if qemuCanHotplug(d.architecture, d.expandedConfig) {
+			memMaxSizeBytes := memSizeBytes
+			hostMemSizeBytes, err := shared.DeviceTotalMemory()
+			if err == nil && hostMemSizeBytes/1024/1024 > memMaxSizeBytes {
+				memMaxSizeBytes = hostMemSizeBytes / 1024 / 1024
+			}
+
+			memCtx["memSlots"] = qemuMemoryHotplugSlots
+			memCtx["memMaxSizeBytes"] = memMaxSizeBytes
+		}
+
+		err = qemuMemory.Execute(sb, memCtx)
 
 		if err != nil {
 			return -1, err
@@ -2921,6 +3056,29 @@ func (d *qemu) addCPUMemoryConfig(sb *strings.Builder) (int, error) {
 	return ctx["cpuCount"].(int), nil
 }
 
+// qemuCanHotplug returns whether vCPUs and memory can be hot-plugged into a VM with the given architecture and
+// configuration. The hotplug ceilings depend on the host, so hotplug is disabled when the VM state may be
+// restored on a host with a different number of CPUs or amount of memory.
+func qemuCanHotplug(architecture int, config map[string]string) bool {
+	if architecture != osarch.ARCH_64BIT_INTEL_X86 {
+		return false
+	}
+
+	if shared.IsTrue(config["migration.stateful"]) {
+		return false
+	}
+
+	if qemuMemoryEncryption(config) != "" {
+		return false
+	}
+
+	if qemuHasNUMANodes(config) {
+		return false
+	}
+
+	return true
+}
+
 // addFileDescriptor adds a file path to the list of files to open and pass file descriptor to qemu.
 // Returns the file descriptor number that qemu will receive.
 func (d *qemu) addFileDescriptor(fdFiles *[]*os.File, file *os.File) int {
@@ -3034,8 +3192,10 @@ func (d *qemu) addDriveDirConfig(sb *strings.Builder, bus *qemuBus, fdFiles *[]*
 	})
 }
 
-// addDriveConfig adds the qemu config required for adding a supplementary drive.
-func (d *qemu) addDriveConfig(bootIndexes map[string]int, driveConf deviceConfig.MountEntryItem) (monitorHook, error) {
+// driveBlockDev returns the QMP block device node definition of a drive, without its filename, along with the
+// media type it should be exposed as. The srcDevPath is the path of a local drive's source used for probing the
+// I/O modes to use, and is empty for remote drives.
+func (d *qemu) driveBlockDev(nodeName string, srcDevPath string, driveConf deviceConfig.MountEntryItem) (map[string]any, string, error) {
 	aioMode := "native" // Use native kernel async IO and O_DIRECT by default.
 	cacheMode := "none" // Bypass host cache, use O_DIRECT semantics by default.
 	media := "disk"
@@ -3053,39 +3213,10 @@ func (d *qemu) addDriveConfig(bootIndexes map[string]int, driveConf deviceConfig
 
 	var isBlockDev bool
 
-	// Handle local disk devices.
-	if !strings.HasPrefix(driveConf.DevPath, "rbd:") {
-		srcDevPath := driveConf.DevPath // This should not be used for passing to QEMU, only for probing.
-
-		// Detect if existing file descriptor format is being supplied.
-		if strings.HasPrefix(driveConf.DevPath, fmt.Sprintf("%s:", device.DiskFileDescriptorMountPrefix)) {
-			// Expect devPath in format "fd:<fdNum>:<devPath>".
-			devPathParts := strings.SplitN(driveConf.DevPath, ":", 3)
-			if len(devPathParts) != 3 || !strings.HasPrefix(driveConf.DevPath, fmt.Sprintf("%s:", device.DiskFileDescriptorMountPrefix)) {
-				return nil, fmt.Errorf("Unexpected devPath file descriptor format %q", driveConf.DevPath)
-			}
-
-			// Map the file descriptor to the file descriptor path it will be in the QEMU process.
-			fd, err := strconv.Atoi(devPathParts[1])
-			if err != nil {
-				return nil, fmt.Errorf("Invalid file descriptor %q: %w", devPathParts[1], err)
-			}
-
-			// Extract original dev path for additional probing below.
-			srcDevPath = devPathParts[2]
-			if srcDevPath == "" {
-				return nil, fmt.Errorf("Device source path is empty")
-			}
-
-			driveConf.DevPath = fmt.Sprintf("/proc/self/fd/%d", fd)
-		} else if driveConf.TargetPath != "/" {
-			// Only the root disk device is allowed to pass local devices to us without using an FD.
-			return nil, fmt.Errorf("Invalid device path format %q", driveConf.DevPath)
-		}
-
+	if srcDevPath != "" {
 		srcDevPathInfo, err := os.Stat(srcDevPath)
 		if err != nil {
-			return nil, fmt.Errorf("Invalid source path %q: %w", srcDevPath, err)
+			return nil, "", fmt.Errorf("Invalid source path %q: %w", srcDevPath, err)
 		}
 
 		isBlockDev = shared.IsBlockdev(srcDevPathInfo.Mode())
@@ -3095,7 +3226,7 @@ func (d *qemu) addDriveConfig(bootIndexes map[string]int, driveConf deviceConfig
 			// Disk dev path is a file, check what the backing filesystem is.
 			fsType, err := filesystem.Detect(srcDevPath)
 			if err != nil {
-				return nil, fmt.Errorf("Failed detecting filesystem type of %q: %w", srcDevPath, err)
+				return nil, "", fmt.Errorf("Failed detecting filesystem type of %q: %w", srcDevPath, err)
 			}
 
 			// If backing FS is ZFS or BTRFS, avoid using direct I/O and use host page cache only.
@@ -3133,8 +3264,6 @@ func (d *qemu) addDriveConfig(bootIndexes map[string]int, driveConf deviceConfig
 		directCache = false
 	}
 
-	escapedDeviceName := filesystem.PathNameEncode(driveConf.DevName)
-
 	blockDev := map[string]any{
 		"aio": aioMode,
 		"cache": map[string]any{
@@ -3143,7 +3272,7 @@ func (d *qemu) addDriveConfig(bootIndexes map[string]int, driveConf deviceConfig
 		},
 		"discard":   "unmap", // Forward as an unmap request. This is the same as `discard=on` in the qemu config file.
 		"driver":    "file",
-		"node-name": fmt.Sprintf("%s%s", qemuBlockDevIDPrefix, escapedDeviceName), // Node names may only be 31 characters long.
+		"node-name": nodeName, // Node names may only be 31 characters long.
 		"read-only": false,
 	}
 
@@ -3153,9 +3282,7 @@ func (d *qemu) addDriveConfig(bootIndexes map[string]int, driveConf deviceConfig
 		blockDev["driver"] = "host_device"
 	}
 
-	readonly := shared.StringInSlice("ro", driveConf.Opts)
-
-	if readonly {
+	if shared.StringInSlice("ro", driveConf.Opts) {
 		blockDev["read-only"] = true
 	}
 
@@ -3163,6 +3290,53 @@ func (d *qemu) addDriveConfig(bootIndexes map[string]int, driveConf deviceConfig
 		blockDev["locking"] = "off"
 	}
 
+	return blockDev, media, nil
+}
+
+// addDriveConfig adds the qemu config required for adding a supplementary drive.
+func (d *qemu) addDriveConfig(bootIndexes map[string]int, driveConf deviceConfig.MountEntryItem) (monitorHook, error) {
+	var srcDevPath string // This should not be used for passing to QEMU, only for probing.
+
+	// Handle local disk devices.
+	if !strings.HasPrefix(driveConf.DevPath, "rbd:") {
+		srcDevPath = driveConf.DevPath
+
+		// Detect if existing file descriptor format is being supplied.
+		if strings.HasPrefix(driveConf.DevPath, fmt.Sprintf("%s:", device.DiskFileDescriptorMountPrefix)) {
+			// Expect devPath in format "fd:<fdNum>:<devPath>".
+			devPathParts := strings.SplitN(driveConf.DevPath, ":", 3)
+			if len(devPathParts) != 3 || !strings.HasPrefix(driveConf.DevPath, fmt.Sprintf("%s:", device.DiskFileDescriptorMountPrefix)) {
+				return nil, fmt.Errorf("Unexpected devPath file descriptor format %q", driveConf.DevPath)
+			}
+
+			// Map the file descriptor to the file descriptor path it will be in the QEMU process.
+			fd, err := strconv.Atoi(devPathParts[1])
+			if err != nil {
+				return nil, fmt.Errorf("Invalid file descriptor %q: %w", devPathParts[1], err)
+			}
+
+			// Extract original dev path for additional probing below.
+			srcDevPath = devPathParts[2]
+			if srcDevPath == "" {
+				return nil, fmt.Errorf("Device source path is empty")
+			}
+
+			driveConf.DevPath = fmt.Sprintf("/proc/self/fd/%d", fd)
+		} else if driveConf.TargetPath != "/" {
+			// Only the root disk device is allowed to pass local devices to us without using an FD.
+			return nil, fmt.Errorf("Invalid device path format %q", driveConf.DevPath)
+		}
+	}
+
+	escapedDeviceName := filesystem.PathNameEncode(driveConf.DevName)
+
+	blockDev, media, err := d.driveBlockDev(fmt.Sprintf("%s%s", qemuBlockDevIDPrefix, escapedDeviceName), srcDevPath, driveConf)
+	if err != nil {
+		return nil, err
+	}
+
+	readonly := shared.StringInSlice("ro", driveConf.Opts)
+
 	device := map[string]string{
 		"id":      fmt.Sprintf("%s%s", qemuDeviceIDPrefix, escapedDeviceName),
 		"drive":   blockDev["node-name"].(string),
@@ -3854,6 +4028,30 @@ func (d *qemu) Snapshot(name string, expiry time.Time, stateful bool) error {
 
 		// Remove the state from the main volume.
 		defer os.Remove(d.StatePath())
+	} else if d.consistentSnapshots() {
+		// Freeze the guest filesystems so that the snapshot is application consistent.
+		thaw, err := d.FreezeFilesystems(qemuFreezeTimeout)
+		if err != nil {
+			return fmt.Errorf("Failed freezing guest filesystems: %w", err)
+		}
+
+		err = d.snapshotCommon(d, name, expiry, stateful)
+		thawErr := thaw()
+		if err != nil {
+			return err
+		}
+
+		if thawErr != nil {
+			// The guest filesystems may have been thawed before the snapshot was taken.
+			snap, err := instance.LoadByProjectAndName(d.state, d.Project(), d.Name()+shared.SnapshotDelimiter+name)
+			if err == nil {
+				snap.Delete(true)
+			}
+
+			return fmt.Errorf("Failed thawing guest filesystems: %w", thawErr)
+		}
+
+		return nil
 	}
 
 	return d.snapshotCommon(d, name, expiry, stateful)
@@ -3962,6 +4160,13 @@ func (d *qemu) Restore(source instance.Instance, stateful bool) error {
 	}
 	d.stateful = stateful
 
+	// The root disk doesn't match the last backup anymore.
+	err = d.VolatileSet(map[string]string{"volatile.backup.checkpoint": ""})
+	if err != nil {
+		op.Done(err)
+		return err
+	}
+
 	// Restart the instance.
 	if wasRunning || stateful {
 		d.logger.Debug("Starting instance after snapshot restore")
@@ -4358,6 +4563,7 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 		// Only certain keys can be changed on a running VM.
 		liveUpdateKeys := []string{
 			"cluster.evacuate",
+			"limits.cpu",
 			"limits.memory",
 			"security.agent.metrics",
 		}
@@ -4409,7 +4615,12 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 		for _, key := range changedConfig {
 			value := d.expandedConfig[key]
 
-			if key == "limits.memory" {
+			if key == "limits.cpu" {
+				err = d.updateCPULimit(oldExpandedConfig[key], value)
+				if err != nil {
+					return fmt.Errorf("Failed updating CPU limit: %w", err)
+				}
+			} else if key == "limits.memory" {
 				err = d.updateMemoryLimit(value)
 				if err != nil {
 					if err != nil {
@@ -4528,16 +4739,168 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 	return nil
 }
 
-// updateMemoryLimit live updates the VM's memory limit by reszing the balloon device.
-func (d *qemu) updateMemoryLimit(newLimit string) error {
+// updateCPULimit live updates the VM's vCPU count by hot-plugging or unplugging vCPUs.
+// Only the vCPUs hot-plugged since boot can be unplugged, and the guest needs to release them first.
+func (d *qemu) updateCPULimit(oldLimit string, newLimit string) error {
+	// Default to a single core.
+	if oldLimit == "" {
+		oldLimit = "1"
+	}
+
 	if newLimit == "" {
+		newLimit = "1"
+	}
+
+	_, err := strconv.Atoi(oldLimit)
+	if err != nil {
+		return fmt.Errorf("Cannot change the CPU limit of a VM using CPU pinning when it is running")
+	}
+
+	newCount, err := strconv.Atoi(newLimit)
+	if err != nil {
+		return fmt.Errorf("Cannot enable CPU pinning when VM is running")
+	}
+
+	if d.architecture != osarch.ARCH_64BIT_INTEL_X86 {
+		return fmt.Errorf("CPU hotplug isn't supported on this architecture")
+	}
+
+	if shared.IsTrue(d.expandedConfig["migration.stateful"]) {
+		return fmt.Errorf("CPU hotplug isn't supported when migration.stateful is enabled")
+	}
+
+	if qemuMemoryEncryption(d.expandedConfig) != "" {
+		return fmt.Errorf("CPU hotplug isn't supported when the guest memory is encrypted")
+	}
+
+	if qemuHasNUMANodes(d.expandedConfig) {
+		return fmt.Errorf("CPU hotplug isn't supported when limits.cpu.nodes or limits.memory.nodes is set")
+	}
+
+	// Connect to the monitor.
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return err // The VM isn't running as no monitor socket available.
+	}
+
+	cpus, err := monitor.QueryHotpluggableCPUs()
+	if err != nil {
+		return err
+	}
+
+	// Order the slots by topology so that vCPUs are plugged in order and unplugged in reverse order.
+	cpuProp := func(cpu qmp.HotpluggableCPU, key string) float64 {
+		value, _ := cpu.Props[key].(float64)
+		return value
+	}
+
+	sort.SliceStable(cpus, func(i, j int) bool {
+		for _, key := range []string{"socket-id", "core-id", "thread-id"} {
+			if cpuProp(cpus[i], key) != cpuProp(cpus[j], key) {
+				return cpuProp(cpus[i], key) < cpuProp(cpus[j], key)
+			}
+		}
+
+		return false
+	})
+
+	countPlugged := func(cpus []qmp.HotpluggableCPU) int {
+		count := 0
+		for _, cpu := range cpus {
+			if cpu.QOMPath != "" {
+				count += cpu.VCPUsCount
+			}
+		}
+
+		return count
+	}
+
+	curCount := countPlugged(cpus)
+	if curCount == newCount {
 		return nil
 	}
 
-	if shared.IsTrue(d.expandedConfig["limits.memory.hugepages"]) {
-		return fmt.Errorf("Cannot live update memory limit when using huge pages")
+	if newCount > curCount {
+		for i, cpu := range cpus {
+			if curCount >= newCount {
+				break
+			}
+
+			if cpu.QOMPath != "" {
+				continue
+			}
+
+			err = monitor.AddCPU(fmt.Sprintf("%s%d", qemuCPUIDPrefix, i), cpu)
+			if err != nil {
+				return err
+			}
+
+			curCount += cpu.VCPUsCount
+		}
+
+		if curCount < newCount {
+			return fmt.Errorf("Cannot increase CPU count beyond %d when VM is running", curCount)
+		}
+
+		// Move all vCPU threads, including the new ones, into a new core scheduling domain.
+		pids, err := monitor.GetCPUs()
+		if err != nil {
+			return err
+		}
+
+		err = d.setCoreSched(pids)
+		if err != nil {
+			return fmt.Errorf("Failed to allocate new core scheduling domain for vCPU threads: %w", err)
+		}
+
+		return nil
+	}
+
+	// Unplug the hot-plugged vCPUs, newest first. The vCPUs present at boot can't be unplugged.
+	remaining := curCount
+	for i := len(cpus) - 1; i >= 0 && remaining > newCount; i-- {
+		id := filepath.Base(cpus[i].QOMPath)
+		if !strings.HasPrefix(id, qemuCPUIDPrefix) {
+			continue
+		}
+
+		err = monitor.RemoveDevice(id)
+		if err != nil {
+			return err
+		}
+
+		remaining -= cpus[i].VCPUsCount
+	}
+
+	if remaining > newCount {
+		return fmt.Errorf("Cannot decrease CPU count below boot time count when VM is running (Boot time count %d, new count %d)", remaining, newCount)
 	}
 
+	// Wait for the guest to release the vCPUs.
+	for i := 0; i < 20; i++ {
+		cpus, err = monitor.QueryHotpluggableCPUs()
+		if err != nil {
+			return err
+		}
+
+		if countPlugged(cpus) <= newCount {
+			return nil
+		}
+
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	return fmt.Errorf("Failed unplugging CPUs as the guest didn't release them")
+}
+
+// updateMemoryLimit live updates the VM's memory limit by hot-plugging memory and resizing the balloon device.
+func (d *qemu) updateMemoryLimit(newLimit string) error {
+	if newLimit == "" {
+		return nil
+	}
+
+	hugepages := shared.IsTrue(d.expandedConfig["limits.memory.hugepages"])
+
 	// Check new size string is valid and convert to bytes.
 	newSizeBytes, err := units.ParseByteSizeString(newLimit)
 	if err != nil {
@@ -4557,6 +4920,70 @@ func (d *qemu) updateMemoryLimit(newLimit string) error {
 	}
 	baseSizeMB := baseSizeBytes / 1024 / 1024
 
+	memDevs, err := monitor.QueryMemoryDevices()
+	if err != nil {
+		return err
+	}
+
+	// Add up the memory hot-plugged since boot.
+	dimms := []qmp.MemoryDevice{}
+	plugSizeBytes := baseSizeBytes
+	for _, memDev := range memDevs {
+		if memDev.Type == "dimm" && strings.HasPrefix(memDev.Data.ID, qemuMemoryIDPrefix) {
+			dimms = append(dimms, memDev)
+			plugSizeBytes += memDev.Data.Size
+		}
+	}
+
+	if newSizeBytes > plugSizeBytes {
+		if d.architecture != osarch.ARCH_64BIT_INTEL_X86 {
+			return fmt.Errorf("Cannot increase memory size beyond boot time size when VM is running (Boot time size %dMiB, new size %dMiB)", baseSizeMB, newSizeMB)
+		}
+
+		if shared.IsTrue(d.expandedConfig["migration.stateful"]) {
+			return fmt.Errorf("Memory hotplug isn't supported when migration.stateful is enabled")
+		}
+
+		if qemuMemoryEncryption(d.expandedConfig) != "" {
+			return fmt.Errorf("Memory hotplug isn't supported when the guest memory is encrypted")
+		}
+
+		if qemuHasNUMANodes(d.expandedConfig) {
+			return fmt.Errorf("Memory hotplug isn't supported when limits.cpu.nodes or limits.memory.nodes is set")
+		}
+
+		// Hot-plug the missing memory, rounded up to the memory block size of the guest.
+		sizeBytes := newSizeBytes - plugSizeBytes
+		if sizeBytes%qemuMemoryHotplugBlockSize != 0 {
+			if hugepages {
+				return fmt.Errorf("Memory hot-plugged when using huge pages must be a multiple of %dMiB", qemuMemoryHotplugBlockSize/1024/1024)
+			}
+
+			sizeBytes += qemuMemoryHotplugBlockSize - sizeBytes%qemuMemoryHotplugBlockSize
+		}
+
+		err = d.hotplugMemory(monitor, dimms, sizeBytes)
+		if err != nil {
+			return err
+		}
+	} else {
+		// Unplug the hot-plugged memory, newest first, as long as the remaining memory covers the new size.
+		for i := len(dimms) - 1; i >= 0; i-- {
+			if plugSizeBytes-dimms[i].Data.Size < newSizeBytes {
+				continue
+			}
+
+			err = d.unplugMemory(monitor, dimms[i])
+			if err != nil {
+				// Fallback to the balloon device if the guest can't release the memory.
+				d.logger.Warn("Failed unplugging memory", logger.Ctx{"device": dimms[i].Data.ID, "err": err})
+				break
+			}
+
+			plugSizeBytes -= dimms[i].Data.Size
+		}
+	}
+
 	curSizeBytes, err := monitor.GetMemoryBalloonSizeBytes()
 	if err != nil {
 		return err
@@ -4565,8 +4992,8 @@ func (d *qemu) updateMemoryLimit(newLimit string) error {
 
 	if curSizeMB == newSizeMB {
 		return nil
-	} else if baseSizeMB < newSizeMB {
-		return fmt.Errorf("Cannot increase memory size beyond boot time size when VM is running (Boot time size %dMiB, new size %dMiB)", baseSizeMB, newSizeMB)
+	} else if hugepages {
+		return fmt.Errorf("Cannot resize the memory balloon when using huge pages (Current size %dMiB, new size %dMiB)", curSizeMB, newSizeMB)
 	}
 
 	// Set effective memory size.
@@ -4601,6 +5028,94 @@ func (d *qemu) updateMemoryLimit(newLimit string) error {
 	return fmt.Errorf("Failed setting memory to %dMiB (currently %dMiB) as it was taking too long", newSizeMB, curSizeMB)
 }
 
+// hotplugMemory hot-plugs a memory device of the given size, backed the same way as the boot time memory.
+func (d *qemu) hotplugMemory(monitor *qmp.Monitor, dimms []qmp.MemoryDevice, sizeBytes int64) error {
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Pick the next free device index.
+	index := 0
+	for _, dimm := range dimms {
+		i, err := strconv.Atoi(strings.TrimPrefix(dimm.Data.ID, qemuMemoryIDPrefix))
+		if err == nil && i >= index {
+			index = i + 1
+		}
+	}
+
+	deviceID := fmt.Sprintf("%s%d", qemuMemoryIDPrefix, index)
+	backendID := fmt.Sprintf("mem-%s", deviceID)
+
+	backend := map[string]any{
+		"qom-type": "memory-backend-memfd",
+		"id":       backendID,
+		"size":     sizeBytes,
+		"share":    true,
+	}
+
+	if shared.IsTrue(d.expandedConfig["limits.memory.hugepages"]) {
+		hugetlb, err := util.HugepagesPath()
+		if err != nil {
+			return err
+		}
+
+		backend["qom-type"] = "memory-backend-file"
+		backend["mem-path"] = hugetlb
+		backend["prealloc"] = true
+		backend["discard-data"] = true
+	}
+
+	err := monitor.AddObject(backend)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() { monitor.RemoveObject(backendID) })
+
+	err = monitor.AddDevice(map[string]string{
+		"driver": "pc-dimm",
+		"id":     deviceID,
+		"memdev": backendID,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed adding memory device: %w", err)
+	}
+
+	revert.Success()
+	return nil
+}
+
+// unplugMemory unplugs a hot-plugged memory device, which requires the guest to release the memory first.
+func (d *qemu) unplugMemory(monitor *qmp.Monitor, dimm qmp.MemoryDevice) error {
+	err := monitor.RemoveDevice(dimm.Data.ID)
+	if err != nil {
+		return err
+	}
+
+	// Wait for the guest to release the memory before removing its backend.
+	for i := 0; i < 20; i++ {
+		memDevs, err := monitor.QueryMemoryDevices()
+		if err != nil {
+			return err
+		}
+
+		found := false
+		for _, memDev := range memDevs {
+			if memDev.Data.ID == dimm.Data.ID {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return monitor.RemoveObject(filepath.Base(dimm.Data.Memdev))
+		}
+
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	return fmt.Errorf("The guest didn't release the memory")
+}
+
 func (d *qemu) updateDevices(removeDevices deviceConfig.Devices, addDevices deviceConfig.Devices, updateDevices deviceConfig.Devices, oldExpandedDevices deviceConfig.Devices, instanceRunning bool, userRequested bool) error {
 	revert := revert.New()
 	defer revert.Fail()
@@ -4883,6 +5398,12 @@ func (d *qemu) Delete(force bool) error {
 				return err
 			}
 
+			// Remove the instance volume left behind on the source pool by a live move.
+			err = d.moveCleanup()
+			if err != nil {
+				return err
+			}
+
 			// Remove the storage volume, snapshot volumes and database records.
 			err = pool.DeleteInstance(d, nil)
 			if err != nil {
@@ -5665,6 +6186,18 @@ func (d *qemu) renderState(statusCode api.StatusCode) (*api.InstanceState, error
 		}
 	}
 
+	if d.isRunningStatusCode(statusCode) {
+		status.MemoryEncryption, err = d.memoryEncryptionState()
+		if err != nil {
+			d.logger.Warn("Error getting guest memory encryption state", logger.Ctx{"err": err})
+		}
+
+		status.NUMANodes, err = d.numaNodesState()
+		if err != nil {
+			d.logger.Warn("Error getting NUMA nodes state", logger.Ctx{"err": err})
+		}
+	}
+
 	status.Pid = int64(pid)
 	status.Status = statusCode.String()
 	status.StatusCode = statusCode
@@ -5726,6 +6259,22 @@ func (d *qemu) agentGetState() (*api.InstanceState, error) {
 	return status, nil
 }
 
+// GuestInfo returns the information reported by the guest through the agent.
+func (d *qemu) GuestInfo() (*api.InstanceGuest, error) {
+	client, err := d.getAgentClient()
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := lxd.ConnectLXDHTTP(nil, client)
+	if err != nil {
+		return nil, fmt.Errorf("Failed connecting to agent: %w", err)
+	}
+	defer agent.Disconnect()
+
+	return agent.GetInstanceGuest("")
+}
+
 // IsRunning returns whether or not the instance is running.
 func (d *qemu) IsRunning() bool {
 	return d.isRunningStatusCode(d.statusCode())
@@ -0,0 +1,299 @@
+package drivers
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/lxd/lxd/backup"
+	"github.com/lxc/lxd/lxd/instance/drivers/nbd"
+	"github.com/lxc/lxd/lxd/instance/drivers/qmp"
+	"github.com/lxc/lxd/lxd/revert"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/instancewriter"
+	"github.com/lxc/lxd/shared/logger"
+)
+
+// qemuBackupBitmapPrefix is the prefix of the dirty bitmaps tracking the root disk writes since a backup checkpoint.
+const qemuBackupBitmapPrefix = "lxd_backup_"
+
+// qemuBackupNBDNodeName is the node name of the NBD client receiving the root disk blocks of an incremental backup.
+const qemuBackupNBDNodeName = "lxd_backup_nbd"
+
+// qemuBackupJobID is the ID of the block job copying the root disk blocks of an incremental backup.
+const qemuBackupJobID = "lxd_backup"
+
+// BackupCapture captures the current content of the root disk of the running VM for a full backup. Writes are
+// redirected to an overlay from then on, the same way as during live migration, so that the root volume is left
+// unchanged while being copied. The returned function merges the overlay back into the root disk.
+func (d *qemu) BackupCapture() (func() error, error) {
+	if !d.IsRunning() {
+		return nil, fmt.Errorf("The instance isn't running")
+	}
+
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return nil, err
+	}
+
+	rootNodeName, err := d.rootDiskNodeName(monitor)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.migrateOverlayAdd(monitor, rootNodeName)
+	if err != nil {
+		return nil, err
+	}
+
+	release := func() error {
+		return d.migrateOverlayCommit(monitor)
+	}
+
+	return release, nil
+}
+
+// BackupCheckpointAdd starts tracking the root disk writes of the running VM, for later incremental backups based
+// on the backup identified by checkpoint. Dirty bitmaps can't be stored in raw disk images, so tracking stops
+// when the VM stops or its root disk is switched over to another volume.
+func (d *qemu) BackupCheckpointAdd(checkpoint string) error {
+	if !d.IsRunning() {
+		return fmt.Errorf("The instance isn't running")
+	}
+
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return err
+	}
+
+	rootNodeName, err := d.rootDiskNodeName(monitor)
+	if err != nil {
+		return err
+	}
+
+	return monitor.BlockDirtyBitmapAdd(rootNodeName, qemuBackupBitmapPrefix+checkpoint)
+}
+
+// BackupCheckpointRemove stops tracking the root disk writes since the backup checkpoint, if still tracked.
+func (d *qemu) BackupCheckpointRemove(checkpoint string) error {
+	if !d.IsRunning() {
+		return nil
+	}
+
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return err
+	}
+
+	rootNodeName, err := d.rootDiskNodeName(monitor)
+	if err != nil {
+		return err
+	}
+
+	node, err := monitor.QueryBlockNode(rootNodeName)
+	if err != nil {
+		return err
+	}
+
+	for _, bitmap := range node.DirtyBitmaps {
+		if bitmap.Name == qemuBackupBitmapPrefix+checkpoint {
+			return monitor.BlockDirtyBitmapRemove(rootNodeName, bitmap.Name)
+		}
+	}
+
+	return nil
+}
+
+// BackupDelta writes the root disk blocks of the running VM written since the base checkpoint to the tarball, as
+// an incremental block delta named name. The blocks are captured as they were when this was called, from which
+// point the root disk writes are tracked for the new checkpoint. The progress function, if not nil, is called with
+// the amount of bytes of the disk processed so far and the total.
+func (d *qemu) BackupDelta(baseCheckpoint string, checkpoint string, tarWriter *instancewriter.InstanceTarWriter, name string, progress func(done int64, total int64)) error {
+	if !d.IsRunning() {
+		return fmt.Errorf("The instance isn't running")
+	}
+
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return err
+	}
+
+	rootNodeName, err := d.rootDiskNodeName(monitor)
+	if err != nil {
+		return err
+	}
+
+	node, err := monitor.QueryBlockNode(rootNodeName)
+	if err != nil {
+		return err
+	}
+
+	tracked := false
+	for _, bitmap := range node.DirtyBitmaps {
+		if bitmap.Name == qemuBackupBitmapPrefix+baseCheckpoint {
+			tracked = true
+			break
+		}
+	}
+
+	if !tracked {
+		return fmt.Errorf("Root disk writes since backup checkpoint %q aren't tracked anymore as the instance was restarted or its root disk moved, a full backup is required", baseCheckpoint)
+	}
+
+	diskSize := node.Image.VirtualSize
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Receive the changed blocks in a sparse file.
+	f, err := os.CreateTemp(shared.VarPath("backups"), fmt.Sprintf("%s_delta_", backup.WorkingDirPrefix))
+	if err != nil {
+		return fmt.Errorf("Failed creating incremental backup file: %w", err)
+	}
+
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	err = f.Truncate(diskSize)
+	if err != nil {
+		return fmt.Errorf("Failed creating incremental backup file: %w", err)
+	}
+
+	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("Failed creating NBD socket pair: %w", err)
+	}
+
+	qemuFile := os.NewFile(uintptr(fds[0]), "backup-nbd-qemu")
+	serverFile := os.NewFile(uintptr(fds[1]), "backup-nbd-server")
+
+	serverConn, err := net.FileConn(serverFile)
+	serverFile.Close()
+	if err != nil {
+		qemuFile.Close()
+		return fmt.Errorf("Failed creating NBD server connection: %w", err)
+	}
+
+	defer serverConn.Close()
+
+	server := nbd.NewServer(f, diskSize)
+	go func() {
+		err := server.Serve(serverConn)
+		if err != nil {
+			d.logger.Debug("Incremental backup NBD server stopped", logger.Ctx{"err": err})
+		}
+	}()
+
+	err = monitor.SendFile(qemuBackupNBDNodeName, qemuFile)
+	qemuFile.Close()
+	if err != nil {
+		return err
+	}
+
+	blockDev := map[string]any{
+		"driver":    "nbd",
+		"node-name": qemuBackupNBDNodeName,
+		"server": map[string]any{
+			"type": "fd",
+			"str":  qemuBackupNBDNodeName,
+		},
+	}
+
+	err = monitor.AddBlockDevice(blockDev, nil)
+	if err != nil {
+		return err
+	}
+
+	defer monitor.RemoveBlockDevice(qemuBackupNBDNodeName)
+
+	// Freeze the guest filesystems until the backup job captured the root disk, so that it's application consistent.
+	var thaw func() error
+	if d.consistentSnapshots() {
+		thaw, err = d.FreezeFilesystems(qemuFreezeTimeout)
+		if err != nil {
+			return fmt.Errorf("Failed freezing guest filesystems: %w", err)
+		}
+	}
+
+	err = monitor.BlockDevBackupIncremental(qemuBackupJobID, rootNodeName, qemuBackupNBDNodeName, qemuBackupBitmapPrefix+baseCheckpoint, qemuBackupBitmapPrefix+checkpoint)
+	if err != nil {
+		if thaw != nil {
+			thaw()
+		}
+
+		return err
+	}
+
+	revert.Add(func() { monitor.BlockDirtyBitmapRemove(rootNodeName, qemuBackupBitmapPrefix+checkpoint) })
+	revert.Add(func() {
+		monitor.BlockJobCancel(qemuBackupJobID)
+		monitor.BlockJobWait(qemuBackupJobID, false)
+	})
+
+	if thaw != nil {
+		err = thaw()
+		if err != nil {
+			return fmt.Errorf("Failed thawing guest filesystems: %w", err)
+		}
+	}
+
+	for progress != nil {
+		jobs, err := monitor.QueryBlockJobs()
+		if err != nil {
+			return err
+		}
+
+		var job *qmp.BlockJob
+		for i := range jobs {
+			if jobs[i].Device == qemuBackupJobID {
+				job = &jobs[i]
+				break
+			}
+		}
+
+		if job == nil || job.Status == "concluded" {
+			break
+		}
+
+		progress(job.Offset, job.Len)
+		time.Sleep(time.Second)
+	}
+
+	err = monitor.BlockJobWait(qemuBackupJobID, false)
+	if err != nil {
+		return err
+	}
+
+	written := server.Written()
+	extents := make([]backup.Extent, 0, len(written))
+	for _, extent := range written {
+		extents = append(extents, backup.Extent{Offset: extent.Offset, Length: extent.Length})
+	}
+
+	d.logger.Debug("Writing incremental backup delta", logger.Ctx{"extents": len(extents), "size": backup.DeltaSize(extents)})
+
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		pipeWriter.CloseWithError(backup.WriteDelta(pipeWriter, f, diskSize, extents))
+	}()
+
+	fi := instancewriter.FileInfo{
+		FileName:    name,
+		FileSize:    backup.DeltaSize(extents),
+		FileMode:    0600,
+		FileModTime: time.Now(),
+	}
+
+	err = tarWriter.WriteFileFromReader(pipeReader, &fi)
+	pipeReader.Close()
+	if err != nil {
+		return fmt.Errorf("Error adding incremental backup delta to tarball: %w", err)
+	}
+
+	revert.Success()
+	return nil
+}
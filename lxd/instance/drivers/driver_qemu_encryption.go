@@ -0,0 +1,156 @@
+package drivers
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/lxc/lxd/lxd/instance/drivers/qmp"
+	"github.com/lxc/lxd/lxd/resources"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/osarch"
+)
+
+// AMD SEV guest policy bits.
+const (
+	qemuSEVPolicyNoDebug      = 1 << 0
+	qemuSEVPolicyNoKeySharing = 1 << 1
+	qemuSEVPolicyES           = 1 << 2
+)
+
+// qemuTDXFirmware is the name of the firmware used by Intel TDX guests, which can't use split firmware volumes.
+const qemuTDXFirmware = "OVMF.inteltdx.fd"
+
+// qemuMemoryEncryptionNames maps the guest memory encryption technologies to their names.
+var qemuMemoryEncryptionNames = map[string]string{
+	"sev":    "AMD SEV",
+	"sev-es": "AMD SEV-ES",
+	"tdx":    "Intel TDX",
+}
+
+// qemuMemoryEncryption returns the guest memory encryption technology enabled in the config ("sev", "sev-es" or
+// "tdx"), or an empty string if the guest memory isn't encrypted.
+func qemuMemoryEncryption(config map[string]string) string {
+	if shared.IsTrue(config["security.tdx"]) {
+		return "tdx"
+	}
+
+	if shared.IsTrue(config["security.sev"]) {
+		if shared.IsTrue(config["security.sev.policy.es"]) {
+			return "sev-es"
+		}
+
+		return "sev"
+	}
+
+	return ""
+}
+
+// qemuSEVPolicy returns the AMD SEV guest policy for the memory encryption technology. Debugging and sharing
+// keys with other guests are never allowed.
+func qemuSEVPolicy(encryption string) uint32 {
+	policy := uint32(qemuSEVPolicyNoDebug | qemuSEVPolicyNoKeySharing)
+	if encryption == "sev-es" {
+		policy |= qemuSEVPolicyES
+	}
+
+	return policy
+}
+
+// qemuValidateMemoryEncryption checks that the guest memory encryption enabled in the config can be used on a host
+// of the given architecture, whose KVM supports the hostEncryption technologies.
+func qemuValidateMemoryEncryption(config map[string]string, architecture int, hostEncryption []string) error {
+	encryption := qemuMemoryEncryption(config)
+	if encryption == "" {
+		return nil
+	}
+
+	name := qemuMemoryEncryptionNames[encryption]
+
+	if architecture != osarch.ARCH_64BIT_INTEL_X86 {
+		return fmt.Errorf("%s is only supported on x86_64", name)
+	}
+
+	if !shared.StringInSlice(encryption, hostEncryption) {
+		return fmt.Errorf("%s isn't supported by the host", name)
+	}
+
+	// The private memory of TDX guests can't be backed by huge pages.
+	if encryption == "tdx" && shared.IsTrue(config["limits.memory.hugepages"]) {
+		return fmt.Errorf("%s can't be used with limits.memory.hugepages", name)
+	}
+
+	return nil
+}
+
+// addMemoryEncryptionConfig adds the qemu config required for encrypting the guest memory, if enabled.
+func (d *qemu) addMemoryEncryptionConfig(sb *strings.Builder, encryption string) error {
+	if encryption == "" {
+		return nil
+	}
+
+	ctx := map[string]any{
+		"memoryEncryption": encryption,
+	}
+
+	if encryption != "tdx" {
+		sev, err := resources.GetSEVCapabilities()
+		if err != nil {
+			return fmt.Errorf("Failed getting AMD SEV capabilities: %w", err)
+		}
+
+		ctx["cbitpos"] = sev.CBitPos
+		ctx["reducedPhysBits"] = sev.ReducedPhysBits
+		ctx["policy"] = qemuSEVPolicy(encryption)
+	}
+
+	return qemuConfidentialGuest.Execute(sb, ctx)
+}
+
+// tdxFirmwarePath returns the path of the firmware used by Intel TDX guests.
+func (d *qemu) tdxFirmwarePath() (string, error) {
+	path := filepath.Join(d.ovmfPath(), qemuTDXFirmware)
+	if !shared.PathExists(path) {
+		return "", fmt.Errorf("Required Intel TDX firmware missing %q", path)
+	}
+
+	return path, nil
+}
+
+// memoryEncryptionState returns the guest memory encryption state of the running VM, including the launch
+// measurement of AMD SEV guests used for remote attestation. Intel TDX guests are attested from the inside.
+func (d *qemu) memoryEncryptionState() (*api.InstanceStateMemoryEncryption, error) {
+	encryption := qemuMemoryEncryption(d.expandedConfig)
+	if encryption == "" {
+		return nil, nil
+	}
+
+	state := &api.InstanceStateMemoryEncryption{Type: encryption}
+	if encryption == "tdx" {
+		return state, nil
+	}
+
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return nil, err
+	}
+
+	sev, err := monitor.QuerySEV()
+	if err != nil {
+		return nil, err
+	}
+
+	if !sev.Enabled {
+		return nil, fmt.Errorf("AMD SEV isn't enabled for the running VM")
+	}
+
+	state.Policy = sev.Policy
+
+	state.Measurement, err = monitor.QuerySEVLaunchMeasure()
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
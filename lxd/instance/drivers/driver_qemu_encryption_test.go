@@ -0,0 +1,118 @@
+package drivers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/lxd/shared/osarch"
+)
+
+func TestQemuMemoryEncryption(t *testing.T) {
+	tests := []struct {
+		config     map[string]string
+		encryption string
+		policy     uint32
+	}{
+		{map[string]string{}, "", 0x3},
+		{map[string]string{"security.sev": "false", "security.sev.policy.es": "true"}, "", 0x3},
+		{map[string]string{"security.sev": "true"}, "sev", 0x3},
+		{map[string]string{"security.sev": "true", "security.sev.policy.es": "true"}, "sev-es", 0x7},
+		{map[string]string{"security.tdx": "true"}, "tdx", 0x3},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			encryption := qemuMemoryEncryption(test.config)
+			assert.Equal(t, test.encryption, encryption)
+			assert.Equal(t, test.policy, qemuSEVPolicy(encryption))
+		})
+	}
+}
+
+func TestQemuValidateMemoryEncryption(t *testing.T) {
+	tests := []struct {
+		config         map[string]string
+		architecture   int
+		hostEncryption []string
+		err            string
+	}{
+		{map[string]string{}, osarch.ARCH_64BIT_ARMV8_LITTLE_ENDIAN, []string{}, ""},
+		{map[string]string{"security.sev": "true"}, osarch.ARCH_64BIT_INTEL_X86, []string{"sev"}, ""},
+		{map[string]string{"security.sev": "true"}, osarch.ARCH_64BIT_ARMV8_LITTLE_ENDIAN, []string{"sev"}, "AMD SEV is only supported on x86_64"},
+		{map[string]string{"security.sev": "true"}, osarch.ARCH_64BIT_INTEL_X86, []string{"tdx"}, "AMD SEV isn't supported by the host"},
+		{map[string]string{"security.sev": "true", "security.sev.policy.es": "true"}, osarch.ARCH_64BIT_INTEL_X86, []string{"sev"}, "AMD SEV-ES isn't supported by the host"},
+		{map[string]string{"security.sev": "true", "security.sev.policy.es": "true"}, osarch.ARCH_64BIT_INTEL_X86, []string{"sev", "sev-es"}, ""},
+		{map[string]string{"security.sev": "true", "limits.memory.hugepages": "true"}, osarch.ARCH_64BIT_INTEL_X86, []string{"sev"}, ""},
+		{map[string]string{"security.tdx": "true"}, osarch.ARCH_64BIT_INTEL_X86, []string{"tdx"}, ""},
+		{map[string]string{"security.tdx": "true", "limits.memory.hugepages": "true"}, osarch.ARCH_64BIT_INTEL_X86, []string{"tdx"}, "Intel TDX can't be used with limits.memory.hugepages"},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			err := qemuValidateMemoryEncryption(test.config, test.architecture, test.hostEncryption)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestQemuConfidentialGuestConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     map[string]any
+		machine string
+		object  string
+	}{
+		{
+			name:    "sev",
+			ctx:     map[string]any{"memoryEncryption": "sev", "cbitpos": uint32(47), "reducedPhysBits": uint32(1), "policy": qemuSEVPolicy("sev")},
+			machine: "usb = \"off\"\nconfidential-guest-support = \"sev0\"\n",
+			object:  "[object \"sev0\"]\nqom-type = \"sev-guest\"\ncbitpos = \"47\"\nreduced-phys-bits = \"1\"\npolicy = \"3\"\n",
+		},
+		{
+			name:    "sev-es",
+			ctx:     map[string]any{"memoryEncryption": "sev-es", "cbitpos": uint32(51), "reducedPhysBits": uint32(1), "policy": qemuSEVPolicy("sev-es")},
+			machine: "usb = \"off\"\nconfidential-guest-support = \"sev0\"\n",
+			object:  "[object \"sev0\"]\nqom-type = \"sev-guest\"\ncbitpos = \"51\"\nreduced-phys-bits = \"1\"\npolicy = \"7\"\n",
+		},
+		{
+			name:    "tdx",
+			ctx:     map[string]any{"memoryEncryption": "tdx", "firmware": "/usr/share/OVMF/OVMF.inteltdx.fd"},
+			machine: "usb = \"off\"\nconfidential-guest-support = \"tdx0\"\nkernel-irqchip = \"split\"\nsmm = \"off\"\nfirmware = \"/usr/share/OVMF/OVMF.inteltdx.fd\"\n",
+			object:  "[object \"tdx0\"]\nqom-type = \"tdx-guest\"\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			baseCtx := map[string]any{
+				"architecture":     "x86_64",
+				"memoryEncryption": test.ctx["memoryEncryption"],
+				"firmware":         test.ctx["firmware"],
+			}
+
+			sb := &strings.Builder{}
+			err := qemuBase.Execute(sb, baseCtx)
+			require.NoError(t, err)
+			assert.Contains(t, sb.String(), test.machine)
+
+			sb = &strings.Builder{}
+			err = qemuConfidentialGuest.Execute(sb, test.ctx)
+			require.NoError(t, err)
+			assert.Contains(t, sb.String(), test.object)
+		})
+	}
+
+	// Guests without memory encryption aren't tied to a confidential guest object.
+	sb := &strings.Builder{}
+	err := qemuBase.Execute(sb, map[string]any{"architecture": "x86_64"})
+	require.NoError(t, err)
+	assert.NotContains(t, sb.String(), "confidential-guest-support")
+}
@@ -0,0 +1,59 @@
+package drivers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lxc/lxd/client"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/logger"
+)
+
+// qemuFreezeTimeout is the time after which the lxd-agent thaws the guest filesystems frozen for a snapshot, in
+// case LXD fails to thaw them.
+const qemuFreezeTimeout = 60 * time.Second
+
+// FreezeFilesystems freezes the guest filesystems through the lxd-agent, after running the guest freeze hooks, so
+// that the disks are in an application consistent state. The returned function thaws them and fails if the
+// lxd-agent had already thawed them because timeout expired.
+func (d *qemu) FreezeFilesystems(timeout time.Duration) (func() error, error) {
+	client, err := d.getAgentClient()
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := lxd.ConnectLXDHTTP(nil, client)
+	if err != nil {
+		d.logger.Error("Failed to connect to lxd-agent", logger.Ctx{"err": err})
+		return nil, fmt.Errorf("Failed to connect to lxd-agent")
+	}
+
+	req := shared.Jmap{"timeout": int(timeout / time.Second)}
+
+	_, _, err = agent.RawQuery("POST", "/1.0/freeze", req, "")
+	if err != nil {
+		agent.Disconnect()
+		return nil, err
+	}
+
+	d.logger.Debug("Froze guest filesystems")
+
+	thaw := func() error {
+		defer agent.Disconnect()
+
+		_, _, err := agent.RawQuery("DELETE", "/1.0/freeze", nil, "")
+		if err != nil {
+			return err
+		}
+
+		d.logger.Debug("Thawed guest filesystems")
+		return nil
+	}
+
+	return thaw, nil
+}
+
+// consistentSnapshots returns whether the guest filesystems need to be frozen while snapshotting the running VM.
+func (d *qemu) consistentSnapshots() bool {
+	return shared.IsTrue(d.expandedConfig["snapshots.consistent"]) && d.IsRunning()
+}
@@ -0,0 +1,470 @@
+package drivers
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/lxd/lxd/instance"
+	"github.com/lxc/lxd/lxd/instance/drivers/qmp"
+	"github.com/lxc/lxd/lxd/revert"
+	storagePools "github.com/lxc/lxd/lxd/storage"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/logger"
+)
+
+// qemuMigrationOverlayNodeName is the node name of the overlay capturing root disk writes during live migration.
+const qemuMigrationOverlayNodeName = "lxd_migration_overlay"
+
+// qemuMigrationNBDNodeName is the node name of the NBD client connected to the live migration target's root disk.
+const qemuMigrationNBDNodeName = "lxd_migration_nbd"
+
+// qemuMigrationNBDExportName is the name of the NBD export of the live migration target's root disk. It doesn't
+// depend on the root disk node name, which differs between source and target once a disk has been moved.
+const qemuMigrationNBDExportName = "lxd_migration_root"
+
+// qemuMigrationMirrorJobID is the ID of the block job mirroring the overlay to the live migration target.
+const qemuMigrationMirrorJobID = "lxd_migration_mirror"
+
+// qemuMigrationCommitJobID is the ID of the block job merging the overlay back into the root disk.
+const qemuMigrationCommitJobID = "lxd_migration_commit"
+
+// migrateOverlayPath returns the path of the qcow2 overlay file used during live migration.
+func (d *qemu) migrateOverlayPath() string {
+	return filepath.Join(d.LogPath(), "migration.qcow2")
+}
+
+// migrateNBDPath returns the path of the NBD server socket used to receive the root disk during live migration.
+func (d *qemu) migrateNBDPath() string {
+	return filepath.Join(d.LogPath(), "migration.nbd")
+}
+
+// rootDiskNodeName returns the QEMU block node name of the root disk.
+func (d *qemu) rootDiskNodeName(monitor *qmp.Monitor) (string, error) {
+	rootDiskName, _, err := d.getRootDiskDevice()
+	if err != nil {
+		return "", fmt.Errorf("Failed getting root disk: %w", err)
+	}
+
+	return d.blockNodeName(monitor, rootDiskName)
+}
+
+// MigrateSendLive live migrates the running VM to the target.
+// Any writes made to the root disk while the volume is being transferred are captured in an overlay and then
+// mirrored to the target's root disk over the disk connection, after which the VM state is sent and the VM is
+// left paused. On failure the overlay is merged back into the root disk and the VM keeps running.
+func (d *qemu) MigrateSendLive(args instance.LiveMigrateSendArgs) error {
+	d.logger.Debug("Live migration send started")
+	defer d.logger.Debug("Live migration send finished")
+
+	// The encrypted guest memory can't be read by QEMU.
+	if qemuMemoryEncryption(d.expandedConfig) != "" {
+		return fmt.Errorf("Live migration isn't supported when the guest memory is encrypted")
+	}
+
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return err
+	}
+
+	rootNodeName, err := d.rootDiskNodeName(monitor)
+	if err != nil {
+		return err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	var diskDone <-chan struct{}
+
+	if args.DiskConn != nil {
+		// Capture writes in an overlay so the root volume is unchanged while being transferred.
+		err = d.migrateOverlayAdd(monitor, rootNodeName)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() {
+			err := d.migrateOverlayCommit(monitor)
+			if err != nil {
+				d.logger.Error("Failed merging migration overlay into root disk", logger.Ctx{"err": err})
+			}
+		})
+	}
+
+	err = args.TransferVolume()
+	if err != nil {
+		return err
+	}
+
+	err = args.TargetReady()
+	if err != nil {
+		return err
+	}
+
+	if args.DiskConn != nil {
+		// Connect to the target's NBD server over the disk connection.
+		fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, 0)
+		if err != nil {
+			return fmt.Errorf("Failed creating NBD socket pair: %w", err)
+		}
+
+		qemuFile := os.NewFile(uintptr(fds[0]), "migration-nbd-qemu")
+		proxyFile := os.NewFile(uintptr(fds[1]), "migration-nbd-proxy")
+
+		proxyConn, err := net.FileConn(proxyFile)
+		proxyFile.Close()
+		if err != nil {
+			qemuFile.Close()
+			return fmt.Errorf("Failed creating NBD proxy connection: %w", err)
+		}
+
+		revert.Add(func() { proxyConn.Close() })
+
+		diskDone = migrateDiskProxy(proxyConn.(*net.UnixConn), args.DiskConn)
+
+		err = monitor.SendFile(qemuMigrationNBDNodeName, qemuFile)
+		qemuFile.Close()
+		if err != nil {
+			return err
+		}
+
+		blockDev := map[string]any{
+			"driver":    "nbd",
+			"node-name": qemuMigrationNBDNodeName,
+			"export":    qemuMigrationNBDExportName,
+			"server": map[string]any{
+				"type": "fd",
+				"str":  qemuMigrationNBDNodeName,
+			},
+		}
+
+		err = monitor.AddBlockDevice(blockDev, nil)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() { monitor.RemoveBlockDevice(qemuMigrationNBDNodeName) })
+
+		// Mirror the writes captured in the overlay to the target, and keep doing so synchronously once ready.
+		err = monitor.BlockDevMirror(qemuMigrationMirrorJobID, qemuMigrationOverlayNodeName, qemuMigrationNBDNodeName, "top")
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() {
+			monitor.BlockJobCancel(qemuMigrationMirrorJobID)
+			monitor.BlockJobWait(qemuMigrationMirrorJobID, false)
+		})
+
+		err = monitor.BlockJobWait(qemuMigrationMirrorJobID, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Stream the VM state to the target.
+	pipeRead, pipeWrite, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+
+	stateDone := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(args.StateConn, pipeRead)
+		pipeRead.Close()
+		args.StateConn.Close()
+		stateDone <- err
+	}()
+
+	err = monitor.SendFile("migration", pipeWrite)
+	pipeWrite.Close()
+	if err != nil {
+		return err
+	}
+
+	// On failure QEMU keeps the VM running, otherwise it is left paused.
+	err = monitor.Migrate("fd:migration")
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() { monitor.Start() })
+
+	err = <-stateDone
+	if err != nil {
+		return fmt.Errorf("Failed sending VM state: %w", err)
+	}
+
+	if args.DiskConn != nil {
+		// The VM is paused so the mirror is in sync. Complete it without switching over to the target and
+		// disconnect, which lets the target know the root disk has been received.
+		err = monitor.BlockJobCancel(qemuMigrationMirrorJobID)
+		if err != nil {
+			return err
+		}
+
+		err = monitor.BlockJobWait(qemuMigrationMirrorJobID, false)
+		if err != nil {
+			return err
+		}
+
+		err = monitor.RemoveBlockDevice(qemuMigrationNBDNodeName)
+		if err != nil {
+			return err
+		}
+
+		<-diskDone
+	}
+
+	err = args.TargetResumed()
+	if err != nil {
+		return err
+	}
+
+	if args.DiskConn != nil {
+		// The VM is stopped by the caller, so the overlay can be discarded.
+		os.Remove(d.migrateOverlayPath())
+	}
+
+	revert.Success()
+	return nil
+}
+
+// MigrateReceiveLive starts the VM from the state received from a live migration source.
+func (d *qemu) MigrateReceiveLive(args instance.LiveMigrateReceiveArgs) error {
+	// Although the instance technically isn't considered stateful, we set this to allow starting from the
+	// migration stream.
+	d.stateful = true
+	d.migrationReceive = &args
+	defer func() { d.migrationReceive = nil }()
+
+	return d.Start(true)
+}
+
+// migrateReceiveState receives the root disk writes and the VM state from a live migration source.
+func (d *qemu) migrateReceiveState(monitor *qmp.Monitor) error {
+	args := d.migrationReceive
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	var diskDone <-chan struct{}
+
+	if args.DiskConn != nil {
+		rootNodeName, err := d.rootDiskNodeName(monitor)
+		if err != nil {
+			return err
+		}
+
+		// Export the root disk so the source can mirror the writes made during the volume transfer.
+		socketPath := d.migrateNBDPath()
+		os.Remove(socketPath)
+
+		err = monitor.NBDServerStart(socketPath)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() {
+			monitor.NBDServerStop()
+			os.Remove(socketPath)
+		})
+
+		err = monitor.BlockExportAddNBD(qemuMigrationNBDExportName, rootNodeName)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() { monitor.BlockExportDel(qemuMigrationNBDExportName) })
+
+		conn, err := net.Dial("unix", socketPath)
+		if err != nil {
+			return fmt.Errorf("Failed connecting to NBD server: %w", err)
+		}
+
+		revert.Add(func() { conn.Close() })
+
+		diskDone = migrateDiskProxy(conn.(*net.UnixConn), args.DiskConn)
+	}
+
+	err := args.Ready()
+	if err != nil {
+		return err
+	}
+
+	// Receive the VM state.
+	pipeRead, pipeWrite, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		io.Copy(pipeWrite, args.StateConn)
+		pipeWrite.Close()
+	}()
+
+	err = monitor.SendFile("migration", pipeRead)
+	pipeRead.Close()
+	if err != nil {
+		return err
+	}
+
+	err = monitor.MigrateIncoming("fd:migration")
+	if err != nil {
+		return err
+	}
+
+	if args.DiskConn != nil {
+		// Wait for the source to disconnect once the mirror has completed.
+		<-diskDone
+
+		err = monitor.BlockExportDel(qemuMigrationNBDExportName)
+		if err != nil {
+			return err
+		}
+
+		err = monitor.NBDServerStop()
+		if err != nil {
+			return err
+		}
+
+		os.Remove(d.migrateNBDPath())
+	}
+
+	revert.Success()
+	return nil
+}
+
+// migrateOverlayAdd adds a qcow2 overlay on top of the root disk, redirecting all further writes to it.
+func (d *qemu) migrateOverlayAdd(monitor *qmp.Monitor, rootNodeName string) error {
+	pool, err := d.getStoragePool()
+	if err != nil {
+		return err
+	}
+
+	sizeBytes, err := storagePools.InstanceDiskBlockSize(pool, d, nil)
+	if err != nil {
+		return fmt.Errorf("Failed getting root disk size: %w", err)
+	}
+
+	overlayPath := d.migrateOverlayPath()
+	os.Remove(overlayPath)
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	_, err = shared.RunCommand("qemu-img", "create", "-f", "qcow2", overlayPath, fmt.Sprintf("%d", sizeBytes))
+	if err != nil {
+		return fmt.Errorf("Failed creating migration overlay: %w", err)
+	}
+
+	revert.Add(func() { os.Remove(overlayPath) })
+
+	// QEMU is running unprivileged, so pass it an open file descriptor.
+	f, err := os.OpenFile(overlayPath, unix.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("Failed opening migration overlay: %w", err)
+	}
+
+	defer f.Close()
+
+	info, err := monitor.SendFileWithFDSet(qemuMigrationOverlayNodeName, f, false)
+	if err != nil {
+		return fmt.Errorf("Failed sending file descriptor of migration overlay: %w", err)
+	}
+
+	revert.Add(func() { monitor.RemoveFDFromFDSet(qemuMigrationOverlayNodeName) })
+
+	blockDev := map[string]any{
+		"driver":    "qcow2",
+		"node-name": qemuMigrationOverlayNodeName,
+		"file": map[string]any{
+			"driver":   "file",
+			"filename": fmt.Sprintf("/dev/fdset/%d", info.ID),
+		},
+	}
+
+	err = monitor.AddBlockDevice(blockDev, nil)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() { monitor.RemoveBlockDevice(qemuMigrationOverlayNodeName) })
+
+	err = monitor.BlockDevSnapshot(rootNodeName, qemuMigrationOverlayNodeName)
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// migrateOverlayCommit merges the writes captured in the migration overlay back into the root disk and removes
+// the overlay.
+func (d *qemu) migrateOverlayCommit(monitor *qmp.Monitor) error {
+	err := monitor.BlockCommit(qemuMigrationCommitJobID, qemuMigrationOverlayNodeName)
+	if err != nil {
+		return err
+	}
+
+	err = monitor.BlockJobWait(qemuMigrationCommitJobID, true)
+	if err != nil {
+		return err
+	}
+
+	err = monitor.BlockJobComplete(qemuMigrationCommitJobID)
+	if err != nil {
+		return err
+	}
+
+	err = monitor.BlockJobWait(qemuMigrationCommitJobID, false)
+	if err != nil {
+		return err
+	}
+
+	err = monitor.RemoveBlockDevice(qemuMigrationOverlayNodeName)
+	if err != nil {
+		return err
+	}
+
+	monitor.RemoveFDFromFDSet(qemuMigrationOverlayNodeName)
+	os.Remove(d.migrateOverlayPath())
+
+	return nil
+}
+
+// migrateDiskProxy proxies the NBD connection over the migration disk connection in both directions.
+// The returned channel is closed once both directions have finished.
+func migrateDiskProxy(conn *net.UnixConn, diskConn io.ReadWriteCloser) <-chan struct{} {
+	done := make(chan struct{})
+
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		io.Copy(diskConn, conn)
+		diskConn.Close()
+	}()
+
+	go func() {
+		defer wg.Done()
+		io.Copy(conn, diskConn)
+		conn.CloseWrite()
+	}()
+
+	go func() {
+		wg.Wait()
+		conn.Close()
+		close(done)
+	}()
+
+	return done
+}
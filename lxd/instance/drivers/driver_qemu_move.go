@@ -0,0 +1,217 @@
+package drivers
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	deviceConfig "github.com/lxc/lxd/lxd/device/config"
+	"github.com/lxc/lxd/lxd/instance/drivers/qmp"
+	"github.com/lxc/lxd/lxd/revert"
+	storagePools "github.com/lxc/lxd/lxd/storage"
+	"github.com/lxc/lxd/lxd/storage/filesystem"
+	"github.com/lxc/lxd/shared/logger"
+)
+
+// qemuMoveNodeSuffix is appended to the block node name of a disk moved to another volume while running, as the
+// name of the node being replaced can't be reused. Moving the disk again switches back to the initial name.
+const qemuMoveNodeSuffix = "_mv"
+
+// qemuMoveJobID is the ID of the block job mirroring a disk to another volume.
+const qemuMoveJobID = "lxd_move_mirror"
+
+// qemuBlockNodeName returns the name of the block node used by the disk device, given the block node names of the
+// guest devices indexed by device ID. Defaults to the name the node is initially given.
+func qemuBlockNodeName(nodeNames map[string]string, deviceName string) string {
+	escapedDeviceName := filesystem.PathNameEncode(deviceName)
+
+	nodeName := nodeNames[fmt.Sprintf("%s%s", qemuDeviceIDPrefix, escapedDeviceName)]
+	if nodeName == "" {
+		nodeName = fmt.Sprintf("%s%s", qemuBlockDevIDPrefix, escapedDeviceName)
+	}
+
+	return nodeName
+}
+
+// qemuMoveTargetNodeName returns the name of the block node for the target volume when moving the disk device
+// currently using the block node nodeName.
+func qemuMoveTargetNodeName(deviceName string, nodeName string) string {
+	initialNodeName := fmt.Sprintf("%s%s", qemuBlockDevIDPrefix, filesystem.PathNameEncode(deviceName))
+	if nodeName == initialNodeName {
+		return initialNodeName + qemuMoveNodeSuffix
+	}
+
+	return initialNodeName
+}
+
+// blockNodeName returns the name of the block node currently used by the disk device of the running VM.
+func (d *qemu) blockNodeName(monitor *qmp.Monitor, deviceName string) (string, error) {
+	nodeNames, err := monitor.GetBlockNodeNames()
+	if err != nil {
+		return "", err
+	}
+
+	return qemuBlockNodeName(nodeNames, deviceName), nil
+}
+
+// MoveDiskLive mirrors the disk device of the running VM to the volume at target.DevPath and then switches the VM
+// over to it, using the target.Opts mount options. The progress function, if not nil, is called with the amount of
+// bytes mirrored so far and the total.
+func (d *qemu) MoveDiskLive(deviceName string, target deviceConfig.MountEntryItem, progress func(done int64, total int64)) error {
+	if !d.IsRunning() {
+		return fmt.Errorf("The instance isn't running")
+	}
+
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return err
+	}
+
+	nodeName, err := d.blockNodeName(monitor, deviceName)
+	if err != nil {
+		return err
+	}
+
+	target.DevName = deviceName
+
+	// Node names can't be reused until the source node is removed, so the target node gets the other name of the
+	// disk. The disk is looked up by its current node name from then on.
+	return d.moveDiskNode(monitor, nodeName, qemuMoveTargetNodeName(deviceName, nodeName), target, progress)
+}
+
+// moveDiskNode adds a block node named targetNodeName for the target volume, mirrors the block node nodeName to it
+// and then switches the disk over to it, removing the source block node.
+func (d *qemu) moveDiskNode(monitor *qmp.Monitor, nodeName string, targetNodeName string, target deviceConfig.MountEntryItem, progress func(done int64, total int64)) error {
+	revert := revert.New()
+	defer revert.Fail()
+
+	f, err := os.OpenFile(target.DevPath, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("Failed opening %q for disk device %q: %w", target.DevPath, target.DevName, err)
+	}
+
+	defer f.Close()
+
+	info, err := monitor.SendFileWithFDSet(targetNodeName, f, false)
+	if err != nil {
+		return fmt.Errorf("Failed sending file descriptor of %q for disk device %q: %w", target.DevPath, target.DevName, err)
+	}
+
+	revert.Add(func() { monitor.RemoveFDFromFDSet(targetNodeName) })
+
+	blockDev, _, err := d.driveBlockDev(targetNodeName, target.DevPath, target)
+	if err != nil {
+		return err
+	}
+
+	blockDev["filename"] = fmt.Sprintf("/dev/fdset/%d", info.ID)
+
+	err = monitor.AddBlockDevice(blockDev, nil)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() { monitor.RemoveBlockDevice(targetNodeName) })
+
+	err = monitor.BlockDevMirror(qemuMoveJobID, nodeName, targetNodeName, "full")
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		monitor.BlockJobCancel(qemuMoveJobID)
+		monitor.BlockJobWait(qemuMoveJobID, false)
+	})
+
+	// Report progress until the target is in sync, after which guest writes are mirrored synchronously.
+	for progress != nil {
+		jobs, err := monitor.QueryBlockJobs()
+		if err != nil {
+			return err
+		}
+
+		var job *qmp.BlockJob
+		for i := range jobs {
+			if jobs[i].Device == qemuMoveJobID {
+				job = &jobs[i]
+				break
+			}
+		}
+
+		if job == nil || job.Ready || job.Status == "concluded" {
+			break
+		}
+
+		progress(job.Offset, job.Len)
+		time.Sleep(time.Second)
+	}
+
+	err = monitor.BlockJobWait(qemuMoveJobID, true)
+	if err != nil {
+		return err
+	}
+
+	// Switch the disk over to the target node.
+	err = monitor.BlockJobComplete(qemuMoveJobID)
+	if err != nil {
+		return err
+	}
+
+	err = monitor.BlockJobWait(qemuMoveJobID, false)
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+
+	// The source node isn't used anymore.
+	err = monitor.RemoveBlockDevice(nodeName)
+	if err != nil {
+		d.logger.Warn("Failed removing block node after disk move", logger.Ctx{"device": target.DevName, "node": nodeName, "err": err})
+	}
+
+	err = monitor.RemoveFDFromFDSet(nodeName)
+	if err != nil {
+		d.logger.Warn("Failed removing file descriptor after disk move", logger.Ctx{"device": target.DevName, "node": nodeName, "err": err})
+	}
+
+	return nil
+}
+
+// moveCleanup removes the instance volume left behind on the pool recorded in volatile.move.source_pool by a
+// live move of the root disk to another pool. This can only be done once the VM is stopped as the config
+// volume is in use while running.
+func (d *qemu) moveCleanup() error {
+	poolName := d.localConfig["volatile.move.source_pool"]
+	if poolName == "" {
+		return nil
+	}
+
+	pool, err := storagePools.LoadByName(d.state, poolName)
+	if err != nil {
+		return fmt.Errorf("Failed loading storage pool %q: %w", poolName, err)
+	}
+
+	_, err = pool.UnmountInstance(d, nil)
+	if err != nil {
+		return fmt.Errorf("Failed unmounting instance volume on storage pool %q: %w", poolName, err)
+	}
+
+	err = pool.DeleteInstance(d, nil)
+	if err != nil {
+		return fmt.Errorf("Failed deleting instance volume on storage pool %q: %w", poolName, err)
+	}
+
+	// Deleting the volume removes the instance symlinks, so point them back to the current pool.
+	currentPool, err := d.getStoragePool()
+	if err != nil {
+		return err
+	}
+
+	err = currentPool.EnsureInstanceSymlinks(d)
+	if err != nil {
+		return err
+	}
+
+	return d.VolatileSet(map[string]string{"volatile.move.source_pool": ""})
+}
@@ -0,0 +1,39 @@
+package drivers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQemuBlockNodeName(t *testing.T) {
+	tests := []struct {
+		nodeNames  map[string]string
+		deviceName string
+		expected   string
+	}{
+		{map[string]string{}, "root", "lxd_root"},
+		{map[string]string{"dev-lxd_root": "lxd_root"}, "root", "lxd_root"},
+		{map[string]string{"dev-lxd_root": "lxd_root_mv", "dev-lxd_data": "lxd_data"}, "root", "lxd_root_mv"},
+		{map[string]string{"dev-lxd_root": "lxd_root_mv"}, "data", "lxd_data"},
+		{map[string]string{"dev-lxd_my--disk": "lxd_my--disk_mv"}, "my-disk", "lxd_my--disk_mv"},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			assert.Equal(t, test.expected, qemuBlockNodeName(test.nodeNames, test.deviceName))
+		})
+	}
+}
+
+func TestQemuMoveTargetNodeName(t *testing.T) {
+	// Moving a disk alternates between two node names, so that the target node never clashes with the source.
+	nodeName := "lxd_root"
+	for _, expected := range []string{"lxd_root_mv", "lxd_root", "lxd_root_mv"} {
+		nodeName = qemuMoveTargetNodeName("root", nodeName)
+		assert.Equal(t, expected, nodeName)
+	}
+
+	assert.Equal(t, "lxd_my--disk_mv", qemuMoveTargetNodeName("my-disk", "lxd_my--disk"))
+}
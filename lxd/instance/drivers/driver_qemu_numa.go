@@ -0,0 +1,307 @@
+package drivers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/lxd/lxd/resources"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/osarch"
+	"github.com/lxc/lxd/shared/units"
+)
+
+// qemuNUMANode represents a virtual NUMA node of a VM and its placement on the host.
+type qemuNUMANode struct {
+	// Host NUMA node whose CPUs run the vCPUs of the node, -1 if the vCPUs can run on any host CPU.
+	cpuNode int64
+
+	// Host NUMA nodes the memory of the node is allocated from.
+	memoryNodes []uint64
+
+	// vCPUs of the node.
+	vcpus []uint64
+}
+
+// qemuParseNUMANodes parses a list of host NUMA nodes, such as "0,2-3".
+func qemuParseNUMANodes(key string, value string) ([]uint64, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	ids, err := resources.ParseCpuset(value)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid %s: %w", key, err)
+	}
+
+	nodes := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		if id < 0 || shared.Uint64InSlice(uint64(id), nodes) {
+			return nil, fmt.Errorf("Invalid %s: NUMA node %d listed more than once", key, id)
+		}
+
+		nodes = append(nodes, uint64(id))
+	}
+
+	return nodes, nil
+}
+
+// qemuHasNUMANodes returns whether the config places the VM on specific host NUMA nodes.
+func qemuHasNUMANodes(config map[string]string) bool {
+	return config["limits.cpu.nodes"] != "" || config["limits.memory.nodes"] != ""
+}
+
+// qemuNUMANodes returns the virtual NUMA nodes of a VM with cpuCount vCPUs, as set by limits.cpu.nodes and
+// limits.memory.nodes. With limits.cpu.nodes, there is a virtual node per listed host node, over which the vCPUs
+// are evenly spread and whose memory is allocated from the same host node, or from the host node at the same
+// position in limits.memory.nodes. With only limits.memory.nodes, there is a single virtual node whose memory is
+// allocated from the listed host nodes. Returns nil if neither is set.
+func qemuNUMANodes(config map[string]string, cpuCount int) ([]qemuNUMANode, error) {
+	cpuNodes, err := qemuParseNUMANodes("limits.cpu.nodes", config["limits.cpu.nodes"])
+	if err != nil {
+		return nil, err
+	}
+
+	memoryNodes, err := qemuParseNUMANodes("limits.memory.nodes", config["limits.memory.nodes"])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cpuNodes) == 0 {
+		if len(memoryNodes) == 0 {
+			return nil, nil
+		}
+
+		vcpus := make([]uint64, 0, cpuCount)
+		for vcpu := 0; vcpu < cpuCount; vcpu++ {
+			vcpus = append(vcpus, uint64(vcpu))
+		}
+
+		return []qemuNUMANode{{cpuNode: -1, memoryNodes: memoryNodes, vcpus: vcpus}}, nil
+	}
+
+	if len(memoryNodes) > 0 && len(memoryNodes) != len(cpuNodes) {
+		return nil, fmt.Errorf("limits.memory.nodes must list as many NUMA nodes as limits.cpu.nodes")
+	}
+
+	if cpuCount < len(cpuNodes) {
+		return nil, fmt.Errorf("limits.cpu must be at least the number of NUMA nodes in limits.cpu.nodes")
+	}
+
+	nodes := make([]qemuNUMANode, 0, len(cpuNodes))
+	vcpu := uint64(0)
+	for i, cpuNode := range cpuNodes {
+		node := qemuNUMANode{cpuNode: int64(cpuNode), memoryNodes: []uint64{cpuNode}}
+		if len(memoryNodes) > 0 {
+			node.memoryNodes = []uint64{memoryNodes[i]}
+		}
+
+		// Spread the vCPUs evenly, the first nodes getting the remaining ones.
+		nodeCPUs := cpuCount / len(cpuNodes)
+		if i < cpuCount%len(cpuNodes) {
+			nodeCPUs++
+		}
+
+		for j := 0; j < nodeCPUs; j++ {
+			node.vcpus = append(node.vcpus, vcpu)
+			vcpu++
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	return nodes, nil
+}
+
+// qemuNUMANodeCPUs returns the IDs of the online host CPUs of the host NUMA node.
+func qemuNUMANodeCPUs(cpu *api.ResourcesCPU, node uint64) []int64 {
+	ids := []int64{}
+	for _, socket := range cpu.Sockets {
+		for _, core := range socket.Cores {
+			for _, thread := range core.Threads {
+				if thread.NUMANode == node && thread.Online {
+					ids = append(ids, thread.ID)
+				}
+			}
+		}
+	}
+
+	return ids
+}
+
+// qemuValidateNUMANodes checks that the host NUMA nodes used by the virtual NUMA nodes exist and have CPUs, and
+// when backed by huge pages, that there are enough free huge pages on them for the nodeMemory bytes of each node.
+func qemuValidateNUMANodes(nodes []qemuNUMANode, nodeMemory int64, hugepages bool, cpu *api.ResourcesCPU, memory *api.ResourcesMemory) error {
+	// Track the free memory of each host node, which is the only node on hosts without NUMA.
+	free := map[uint64]int64{}
+	if len(memory.Nodes) == 0 {
+		free[0] = int64(memory.HugepagesTotal - memory.HugepagesUsed)
+	}
+
+	for _, hostNode := range memory.Nodes {
+		free[hostNode.NUMANode] = int64(hostNode.HugepagesTotal - hostNode.HugepagesUsed)
+	}
+
+	for i, node := range nodes {
+		if node.cpuNode >= 0 && len(qemuNUMANodeCPUs(cpu, uint64(node.cpuNode))) == 0 {
+			return fmt.Errorf("Host NUMA node %d has no online CPUs", node.cpuNode)
+		}
+
+		remaining := nodeMemory
+		for _, memoryNode := range node.memoryNodes {
+			nodeFree, ok := free[memoryNode]
+			if !ok {
+				return fmt.Errorf("Host NUMA node %d doesn't exist", memoryNode)
+			}
+
+			if nodeFree > remaining {
+				nodeFree = remaining
+			}
+
+			free[memoryNode] -= nodeFree
+			remaining -= nodeFree
+		}
+
+		if hugepages && remaining > 0 {
+			return fmt.Errorf("Not enough free huge pages on host NUMA nodes %s for virtual NUMA node %d (%s missing)", qemuNUMANodeList(node.memoryNodes), i, units.GetByteSizeStringIEC(remaining, 2))
+		}
+	}
+
+	return nil
+}
+
+// qemuNUMANodeList returns the comma separated list of NUMA nodes.
+func qemuNUMANodeList(nodes []uint64) string {
+	ids := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		ids = append(ids, strconv.FormatUint(node, 10))
+	}
+
+	return strings.Join(ids, ",")
+}
+
+// numaNodes returns the virtual NUMA nodes of the VM and the amount of memory of each of them, in bytes.
+func (d *qemu) numaNodes() ([]qemuNUMANode, int64, error) {
+	if !qemuHasNUMANodes(d.expandedConfig) {
+		return nil, 0, nil
+	}
+
+	// Default to a single core.
+	cpuLimit := d.expandedConfig["limits.cpu"]
+	if cpuLimit == "" {
+		cpuLimit = "1"
+	}
+
+	cpuCount, err := strconv.Atoi(cpuLimit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("limits.cpu.nodes and limits.memory.nodes can't be used with CPU pinning")
+	}
+
+	nodes, err := qemuNUMANodes(d.expandedConfig, cpuCount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	memSize := d.expandedConfig["limits.memory"]
+	if memSize == "" {
+		memSize = qemuDefaultMemSize // Default if no memory limit specified.
+	}
+
+	memSizeBytes, err := units.ParseByteSizeString(memSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("limits.memory invalid: %w", err)
+	}
+
+	// Memory is split evenly in whole MiB between the nodes.
+	return nodes, memSizeBytes / 1024 / 1024 / int64(len(nodes)) * 1024 * 1024, nil
+}
+
+// validateNUMANodes checks that the host NUMA nodes the VM is placed on can be used.
+func (d *qemu) validateNUMANodes() error {
+	nodes, nodeMemory, err := d.numaNodes()
+	if err != nil || nodes == nil {
+		return err
+	}
+
+	if d.architecture != osarch.ARCH_64BIT_INTEL_X86 {
+		return fmt.Errorf("limits.cpu.nodes and limits.memory.nodes are only supported on x86_64")
+	}
+
+	cpu, err := resources.GetCPU()
+	if err != nil {
+		return fmt.Errorf("Failed getting host CPUs: %w", err)
+	}
+
+	memory, err := resources.GetMemory()
+	if err != nil {
+		return fmt.Errorf("Failed getting host memory: %w", err)
+	}
+
+	return qemuValidateNUMANodes(nodes, nodeMemory, shared.IsTrue(d.expandedConfig["limits.memory.hugepages"]), cpu, memory)
+}
+
+// setNUMAAffinity binds the vCPU threads to the CPUs of the host NUMA node of their virtual NUMA node.
+func (d *qemu) setNUMAAffinity(pids []int) error {
+	nodes, _, err := d.numaNodes()
+	if err != nil || nodes == nil {
+		return err
+	}
+
+	cpu, err := resources.GetCPU()
+	if err != nil {
+		return fmt.Errorf("Failed getting host CPUs: %w", err)
+	}
+
+	for _, node := range nodes {
+		if node.cpuNode < 0 {
+			continue
+		}
+
+		set := unix.CPUSet{}
+		for _, id := range qemuNUMANodeCPUs(cpu, uint64(node.cpuNode)) {
+			set.Set(int(id))
+		}
+
+		for _, vcpu := range node.vcpus {
+			if vcpu >= uint64(len(pids)) {
+				return fmt.Errorf("QEMU has less vCPUs than configured")
+			}
+
+			err = unix.SchedSetaffinity(pids[vcpu], &set)
+			if err != nil {
+				return fmt.Errorf("Failed binding vCPU %d to host NUMA node %d: %w", vcpu, node.cpuNode, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// numaNodesState returns the virtual NUMA nodes of the VM and their placement on the host.
+func (d *qemu) numaNodesState() ([]api.InstanceStateNUMANode, error) {
+	nodes, nodeMemory, err := d.numaNodes()
+	if err != nil || nodes == nil {
+		return nil, err
+	}
+
+	state := make([]api.InstanceStateNUMANode, 0, len(nodes))
+	for i, node := range nodes {
+		nodeState := api.InstanceStateNUMANode{
+			ID:              uint64(i),
+			CPUs:            node.vcpus,
+			Memory:          nodeMemory,
+			HostMemoryNodes: node.memoryNodes,
+		}
+
+		if node.cpuNode >= 0 {
+			nodeState.HostCPUNodes = []uint64{uint64(node.cpuNode)}
+		}
+
+		state = append(state, nodeState)
+	}
+
+	return state, nil
+}
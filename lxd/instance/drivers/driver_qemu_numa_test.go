@@ -0,0 +1,178 @@
+package drivers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/lxd/shared/api"
+)
+
+func TestQemuNUMANodes(t *testing.T) {
+	tests := []struct {
+		config   map[string]string
+		cpuCount int
+		nodes    []qemuNUMANode
+		err      string
+	}{
+		{map[string]string{}, 4, nil, ""},
+		{map[string]string{"limits.memory.nodes": "1"}, 2, []qemuNUMANode{{cpuNode: -1, memoryNodes: []uint64{1}, vcpus: []uint64{0, 1}}}, ""},
+		{map[string]string{"limits.memory.nodes": "0-1"}, 1, []qemuNUMANode{{cpuNode: -1, memoryNodes: []uint64{0, 1}, vcpus: []uint64{0}}}, ""},
+		{map[string]string{"limits.cpu.nodes": "0,1"}, 4, []qemuNUMANode{
+			{cpuNode: 0, memoryNodes: []uint64{0}, vcpus: []uint64{0, 1}},
+			{cpuNode: 1, memoryNodes: []uint64{1}, vcpus: []uint64{2, 3}},
+		}, ""},
+		{map[string]string{"limits.cpu.nodes": "1-3"}, 5, []qemuNUMANode{
+			{cpuNode: 1, memoryNodes: []uint64{1}, vcpus: []uint64{0, 1}},
+			{cpuNode: 2, memoryNodes: []uint64{2}, vcpus: []uint64{2, 3}},
+			{cpuNode: 3, memoryNodes: []uint64{3}, vcpus: []uint64{4}},
+		}, ""},
+		{map[string]string{"limits.cpu.nodes": "0,1", "limits.memory.nodes": "2,3"}, 2, []qemuNUMANode{
+			{cpuNode: 0, memoryNodes: []uint64{2}, vcpus: []uint64{0}},
+			{cpuNode: 1, memoryNodes: []uint64{3}, vcpus: []uint64{1}},
+		}, ""},
+		{map[string]string{"limits.cpu.nodes": "0,1", "limits.memory.nodes": "2"}, 2, nil, "limits.memory.nodes must list as many NUMA nodes as limits.cpu.nodes"},
+		{map[string]string{"limits.cpu.nodes": "0,1"}, 1, nil, "limits.cpu must be at least the number of NUMA nodes in limits.cpu.nodes"},
+		{map[string]string{"limits.cpu.nodes": "0,0-1"}, 2, nil, "Invalid limits.cpu.nodes: NUMA node 0 listed more than once"},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			nodes, err := qemuNUMANodes(test.config, test.cpuCount)
+			if test.err == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, test.nodes, nodes)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestQemuValidateNUMANodes(t *testing.T) {
+	cpu := &api.ResourcesCPU{
+		Sockets: []api.ResourcesCPUSocket{
+			{Cores: []api.ResourcesCPUCore{
+				{Threads: []api.ResourcesCPUThread{{ID: 0, NUMANode: 0, Online: true}, {ID: 1, NUMANode: 0, Online: true}}},
+				{Threads: []api.ResourcesCPUThread{{ID: 2, NUMANode: 1, Online: true}, {ID: 3, NUMANode: 1, Online: true}}},
+				{Threads: []api.ResourcesCPUThread{{ID: 4, NUMANode: 2, Online: false}}},
+			}},
+		},
+	}
+
+	gib := uint64(1024 * 1024 * 1024)
+	memory := &api.ResourcesMemory{
+		Nodes: []api.ResourcesMemoryNode{
+			{NUMANode: 0, HugepagesTotal: 4 * gib, HugepagesUsed: 1 * gib},
+			{NUMANode: 1, HugepagesTotal: 2 * gib},
+			{NUMANode: 2},
+		},
+	}
+
+	tests := []struct {
+		nodes      []qemuNUMANode
+		nodeMemory int64
+		hugepages  bool
+		err        string
+	}{
+		{[]qemuNUMANode{{cpuNode: 0, memoryNodes: []uint64{0}}, {cpuNode: 1, memoryNodes: []uint64{1}}}, int64(2 * gib), true, ""},
+		{[]qemuNUMANode{{cpuNode: 0, memoryNodes: []uint64{0}}, {cpuNode: 1, memoryNodes: []uint64{1}}}, int64(3 * gib), true, "Not enough free huge pages on host NUMA nodes 1 for virtual NUMA node 1 (1.00GiB missing)"},
+		{[]qemuNUMANode{{cpuNode: 0, memoryNodes: []uint64{0}}, {cpuNode: 1, memoryNodes: []uint64{1}}}, int64(3 * gib), false, ""},
+		{[]qemuNUMANode{{cpuNode: 0, memoryNodes: []uint64{0}}, {cpuNode: 1, memoryNodes: []uint64{0}}}, int64(2 * gib), true, "Not enough free huge pages on host NUMA nodes 0 for virtual NUMA node 1 (1.00GiB missing)"},
+		{[]qemuNUMANode{{cpuNode: -1, memoryNodes: []uint64{0, 1}}}, int64(5 * gib), true, ""},
+		{[]qemuNUMANode{{cpuNode: 2, memoryNodes: []uint64{2}}}, int64(gib), false, "Host NUMA node 2 has no online CPUs"},
+		{[]qemuNUMANode{{cpuNode: -1, memoryNodes: []uint64{3}}}, int64(gib), false, "Host NUMA node 3 doesn't exist"},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			err := qemuValidateNUMANodes(test.nodes, test.nodeMemory, test.hugepages, cpu, memory)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestQemuNUMAConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      map[string]any
+		stanzas  []string
+		excluded []string
+	}{
+		{
+			name: "numa",
+			ctx: map[string]any{
+				"cpuNumaHostNodes": [][]uint64{{0}, {1}},
+				"cpuNumaMapping": []map[string]uint64{
+					{"node": 0, "socket": 0, "core": 0, "thread": 0},
+					{"node": 1, "socket": 0, "core": 1, "thread": 0},
+				},
+				"hugepages": "/dev/hugepages",
+			},
+			stanzas: []string{
+				"[object \"mem0\"]\nqom-type = \"memory-backend-file\"\nmem-path = \"/dev/hugepages\"\n",
+				"size = \"1024M\"\npolicy = \"bind\"\nhost-nodes.0 = \"0\"\n\n[numa]\ntype = \"node\"\nnodeid = \"0\"\nmemdev = \"mem0\"\n",
+				"[object \"mem1\"]\nqom-type = \"memory-backend-file\"\nmem-path = \"/dev/hugepages\"\n",
+				"size = \"1024M\"\npolicy = \"bind\"\nhost-nodes.0 = \"1\"\n\n[numa]\ntype = \"node\"\nnodeid = \"1\"\nmemdev = \"mem1\"\n",
+				"[numa]\ntype = \"cpu\"\nnode-id = \"0\"\nsocket-id = \"0\"\ncore-id = \"0\"\nthread-id = \"0\"\n",
+				"[numa]\ntype = \"cpu\"\nnode-id = \"1\"\nsocket-id = \"0\"\ncore-id = \"1\"\nthread-id = \"0\"\n",
+			},
+		},
+		{
+			name: "numa-memory-nodes",
+			ctx: map[string]any{
+				"cpuNumaHostNodes": [][]uint64{{0, 1}},
+				"cpuNumaMapping": []map[string]uint64{
+					{"node": 0, "socket": 0, "core": 0, "thread": 0},
+					{"node": 0, "socket": 0, "core": 1, "thread": 0},
+				},
+				"hugepages": "",
+			},
+			stanzas: []string{
+				"qom-type = \"memory-backend-memfd\"\nsize = \"1024M\"\npolicy = \"bind\"\nhost-nodes.0 = \"0\"\nhost-nodes.1 = \"1\"\n",
+				"[numa]\ntype = \"node\"\nnodeid = \"0\"\nmemdev = \"mem0\"\n",
+				"[numa]\ntype = \"cpu\"\nnode-id = \"0\"\nsocket-id = \"0\"\ncore-id = \"0\"\nthread-id = \"0\"\n",
+				"[numa]\ntype = \"cpu\"\nnode-id = \"0\"\nsocket-id = \"0\"\ncore-id = \"1\"\nthread-id = \"0\"\n",
+			},
+			excluded: []string{"[object \"mem1\"]", "mem-path"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sb := &strings.Builder{}
+
+			ctx := map[string]any{
+				"architecture":        "x86_64",
+				"qemuMemObjectFormat": "indexed",
+				"cpuCount":            2,
+				"cpuSockets":          1,
+				"cpuCores":            2,
+				"cpuThreads":          1,
+				"memory":              int64(1024),
+			}
+
+			for k, v := range test.ctx {
+				ctx[k] = v
+			}
+
+			err := qemuCPU.Execute(sb, ctx)
+			require.NoError(t, err)
+
+			for _, stanza := range test.stanzas {
+				assert.Contains(t, sb.String(), stanza)
+			}
+
+			for _, stanza := range test.excluded {
+				assert.NotContains(t, sb.String(), stanza)
+			}
+		})
+	}
+}
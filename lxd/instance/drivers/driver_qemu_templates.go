@@ -25,6 +25,15 @@ type = "s390-ccw-virtio"
 {{end -}}
 accel = "kvm"
 usb = "off"
+{{- if eq .memoryEncryption "sev" "sev-es"}}
+confidential-guest-support = "sev0"
+{{- end}}
+{{- if eq .memoryEncryption "tdx"}}
+confidential-guest-support = "tdx0"
+kernel-irqchip = "split"
+smm = "off"
+firmware = "{{.firmware}}"
+{{- end}}
 
 {{if eq .architecture "x86_64" -}}
 [global]
@@ -46,10 +55,28 @@ strict = "on"
 backend = "pty"
 `))
 
+var qemuConfidentialGuest = template.Must(template.New("qemuConfidentialGuest").Parse(`
+# Memory encryption
+{{- if eq .memoryEncryption "tdx"}}
+[object "tdx0"]
+qom-type = "tdx-guest"
+{{- else}}
+[object "sev0"]
+qom-type = "sev-guest"
+cbitpos = "{{.cbitpos}}"
+reduced-phys-bits = "{{.reducedPhysBits}}"
+policy = "{{.policy}}"
+{{- end}}
+`))
+
 var qemuMemory = template.Must(template.New("qemuMemory").Parse(`
 # Memory
 [memory]
 size = "{{.memSizeBytes}}M"
+{{- if .memMaxSizeBytes}}
+slots = "{{.memSlots}}"
+maxmem = "{{.memMaxSizeBytes}}M"
+{{- end}}
 `))
 
 var qemuSerial = template.Must(template.New("qemuSerial").Parse(`
@@ -238,6 +265,9 @@ var qemuCPU = template.Must(template.New("qemuCPU").Parse(`
 # CPU
 [smp-opts]
 cpus = "{{.cpuCount}}"
+{{- if .cpuMaxCount}}
+maxcpus = "{{.cpuMaxCount}}"
+{{- end}}
 sockets = "{{.cpuSockets}}"
 cores = "{{.cpuCores}}"
 threads = "{{.cpuThreads}}"
@@ -260,9 +290,13 @@ qom-type = "memory-backend-memfd"
 size = "{{$memory}}M"
 policy = "bind"
 {{- if eq $.qemuMemObjectFormat "indexed"}}
-host-nodes.0 = "{{$element}}"
+{{- range $nodeIndex, $node := $element}}
+host-nodes.{{$nodeIndex}} = "{{$node}}"
+{{- end}}
 {{- else}}
-host-nodes = "{{$element}}"
+{{- range $element}}
+host-nodes = "{{.}}"
+{{- end}}
 {{- end}}
 
 [numa]
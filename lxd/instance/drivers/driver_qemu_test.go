@@ -0,0 +1,101 @@
+package drivers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/lxd/shared/osarch"
+)
+
+func TestQemuCanHotplug(t *testing.T) {
+	tests := []struct {
+		architecture int
+		config       map[string]string
+		expected     bool
+	}{
+		{osarch.ARCH_64BIT_INTEL_X86, map[string]string{}, true},
+		{osarch.ARCH_64BIT_INTEL_X86, map[string]string{"migration.stateful": "false"}, true},
+		{osarch.ARCH_64BIT_ARMV8_LITTLE_ENDIAN, map[string]string{}, false},
+		{osarch.ARCH_64BIT_INTEL_X86, map[string]string{"migration.stateful": "true"}, false},
+		{osarch.ARCH_64BIT_INTEL_X86, map[string]string{"security.sev": "true"}, false},
+		{osarch.ARCH_64BIT_INTEL_X86, map[string]string{"limits.cpu.nodes": "0"}, false},
+		{osarch.ARCH_64BIT_INTEL_X86, map[string]string{"limits.memory.nodes": "0"}, false},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			assert.Equal(t, test.expected, qemuCanHotplug(test.architecture, test.config))
+		})
+	}
+}
+
+func TestQemuHotplugConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		cpuCtx map[string]any
+		memCtx map[string]any
+		memory string
+		cpu    string
+		absent []string
+	}{
+		{
+			name:   "hotplug",
+			cpuCtx: map[string]any{"cpuMaxCount": 8, "cpuCores": 8},
+			memCtx: map[string]any{"memSlots": qemuMemoryHotplugSlots, "memMaxSizeBytes": int64(16384)},
+			memory: fmt.Sprintf("[memory]\nsize = \"1024M\"\nslots = \"%d\"\nmaxmem = \"16384M\"\n", qemuMemoryHotplugSlots),
+			cpu:    "[smp-opts]\ncpus = \"2\"\nmaxcpus = \"8\"\nsockets = \"1\"\ncores = \"8\"\nthreads = \"1\"\n",
+		},
+		{
+			name:   "no-hotplug",
+			memory: "[memory]\nsize = \"1024M\"\n",
+			cpu:    "[smp-opts]\ncpus = \"2\"\nsockets = \"1\"\ncores = \"2\"\nthreads = \"1\"\n",
+			absent: []string{"slots =", "maxmem =", "maxcpus ="},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			memCtx := map[string]any{
+				"architecture": "x86_64",
+				"memSizeBytes": int64(1024),
+			}
+
+			for k, v := range test.memCtx {
+				memCtx[k] = v
+			}
+
+			cpuCtx := map[string]any{
+				"architecture":        "x86_64",
+				"qemuMemObjectFormat": "indexed",
+				"cpuCount":            2,
+				"cpuSockets":          1,
+				"cpuCores":            2,
+				"cpuThreads":          1,
+				"cpuNumaHostNodes":    [][]uint64{{0}},
+				"memory":              int64(1024),
+				"hugepages":           "",
+			}
+
+			for k, v := range test.cpuCtx {
+				cpuCtx[k] = v
+			}
+
+			sb := &strings.Builder{}
+			err := qemuMemory.Execute(sb, memCtx)
+			require.NoError(t, err)
+			assert.Contains(t, sb.String(), test.memory)
+
+			err = qemuCPU.Execute(sb, cpuCtx)
+			require.NoError(t, err)
+			assert.Contains(t, sb.String(), test.cpu)
+
+			for _, key := range test.absent {
+				assert.NotContains(t, sb.String(), key)
+			}
+		})
+	}
+}
@@ -0,0 +1,347 @@
+// Package nbd implements a minimal NBD server, used as the target of QEMU block jobs.
+package nbd
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Handshake and option haggling magic values.
+const (
+	nbdMagic         = 0x4e42444d41474943 // "NBDMAGIC"
+	nbdOptMagic      = 0x49484156454f5054 // "IHAVEOPT"
+	nbdOptReplyMagic = 0x3e889045565a9
+)
+
+// Handshake flags.
+const (
+	nbdFlagFixedNewstyle = 1 << 0
+	nbdFlagNoZeroes      = 1 << 1
+)
+
+// Options.
+const (
+	nbdOptExportName = 1
+	nbdOptAbort      = 2
+	nbdOptInfo       = 6
+	nbdOptGo         = 7
+)
+
+// Option replies.
+const (
+	nbdRepAck      = 1
+	nbdRepInfo     = 3
+	nbdRepErrUnsup = 1<<31 + 1
+
+	nbdInfoExport = 0
+)
+
+// Transmission flags.
+const (
+	nbdFlagHasFlags  = 1 << 0
+	nbdFlagSendFlush = 1 << 2
+)
+
+// Transmission requests and replies.
+const (
+	nbdRequestMagic     = 0x25609513
+	nbdSimpleReplyMagic = 0x67446698
+
+	nbdCmdRead  = 0
+	nbdCmdWrite = 1
+	nbdCmdDisc  = 2
+	nbdCmdFlush = 3
+
+	nbdEIO    = 5
+	nbdEINVAL = 22
+)
+
+// maxRequestLength is the maximum length of read and write requests.
+const maxRequestLength = 32 * 1024 * 1024
+
+// Backend is the storage of an export.
+type Backend interface {
+	io.ReaderAt
+	io.WriterAt
+}
+
+// Extent represents an area of an export.
+type Extent struct {
+	Offset int64
+	Length int64
+}
+
+// Server serves a single writable export of a fixed size, backed by a Backend. It records the areas written by
+// clients so that only those need to be read back. Zeroing and trimming aren't advertised, so clients always
+// write the data.
+type Server struct {
+	backend Backend
+	size    int64
+
+	mu      sync.Mutex
+	written []Extent
+}
+
+// NewServer returns a new server exporting size bytes of backend.
+func NewServer(backend Backend, size int64) *Server {
+	return &Server{backend: backend, size: size}
+}
+
+// Written returns the areas written so far, sorted by offset and merged.
+func (s *Server) Written() []Extent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	extents := make([]Extent, len(s.written))
+	copy(extents, s.written)
+
+	sort.Slice(extents, func(i int, j int) bool { return extents[i].Offset < extents[j].Offset })
+
+	merged := []Extent{}
+	for _, extent := range extents {
+		last := len(merged) - 1
+		if last >= 0 && extent.Offset <= merged[last].Offset+merged[last].Length {
+			end := extent.Offset + extent.Length
+			if end > merged[last].Offset+merged[last].Length {
+				merged[last].Length = end - merged[last].Offset
+			}
+
+			continue
+		}
+
+		merged = append(merged, extent)
+	}
+
+	return merged
+}
+
+// Serve handles a client connection until it disconnects.
+func (s *Server) Serve(conn io.ReadWriter) error {
+	noZeroes, err := s.handshake(conn)
+	if err != nil {
+		return err
+	}
+
+	for {
+		done, err := s.negotiate(conn, noZeroes)
+		if err != nil {
+			return err
+		}
+
+		if done {
+			break
+		}
+	}
+
+	return s.transmit(conn)
+}
+
+// handshake performs the fixed newstyle handshake, returning whether the client asked for the export name reply
+// to be sent without padding.
+func (s *Server) handshake(conn io.ReadWriter) (bool, error) {
+	buf := make([]byte, 18)
+	binary.BigEndian.PutUint64(buf, nbdMagic)
+	binary.BigEndian.PutUint64(buf[8:], nbdOptMagic)
+	binary.BigEndian.PutUint16(buf[16:], nbdFlagFixedNewstyle|nbdFlagNoZeroes)
+
+	_, err := conn.Write(buf)
+	if err != nil {
+		return false, fmt.Errorf("Failed sending NBD handshake: %w", err)
+	}
+
+	_, err = io.ReadFull(conn, buf[:4])
+	if err != nil {
+		return false, fmt.Errorf("Failed receiving NBD client flags: %w", err)
+	}
+
+	clientFlags := binary.BigEndian.Uint32(buf)
+	if clientFlags&nbdFlagFixedNewstyle == 0 {
+		return false, fmt.Errorf("NBD client doesn't support the fixed newstyle handshake")
+	}
+
+	return clientFlags&nbdFlagNoZeroes != 0, nil
+}
+
+// negotiate handles an option sent by the client, returning true once the client entered the transmission phase.
+func (s *Server) negotiate(conn io.ReadWriter, noZeroes bool) (bool, error) {
+	header := make([]byte, 16)
+	_, err := io.ReadFull(conn, header)
+	if err != nil {
+		return false, fmt.Errorf("Failed receiving NBD option: %w", err)
+	}
+
+	if binary.BigEndian.Uint64(header) != nbdOptMagic {
+		return false, fmt.Errorf("Invalid NBD option magic")
+	}
+
+	option := binary.BigEndian.Uint32(header[8:])
+	length := binary.BigEndian.Uint32(header[12:])
+	if length > 64*1024 {
+		return false, fmt.Errorf("NBD option too long")
+	}
+
+	// The export name and requested information are ignored as there is a single export.
+	_, err = io.CopyN(io.Discard, conn, int64(length))
+	if err != nil {
+		return false, fmt.Errorf("Failed receiving NBD option: %w", err)
+	}
+
+	flags := uint16(nbdFlagHasFlags | nbdFlagSendFlush)
+
+	switch option {
+	case nbdOptExportName:
+		reply := make([]byte, 10, 134)
+		binary.BigEndian.PutUint64(reply, uint64(s.size))
+		binary.BigEndian.PutUint16(reply[8:], flags)
+
+		if !noZeroes {
+			reply = reply[:134]
+		}
+
+		_, err = conn.Write(reply)
+		if err != nil {
+			return false, fmt.Errorf("Failed sending NBD export information: %w", err)
+		}
+
+		return true, nil
+	case nbdOptInfo, nbdOptGo:
+		info := make([]byte, 12)
+		binary.BigEndian.PutUint16(info, nbdInfoExport)
+		binary.BigEndian.PutUint64(info[2:], uint64(s.size))
+		binary.BigEndian.PutUint16(info[10:], flags)
+
+		err = s.optionReply(conn, option, nbdRepInfo, info)
+		if err != nil {
+			return false, err
+		}
+
+		err = s.optionReply(conn, option, nbdRepAck, nil)
+		if err != nil {
+			return false, err
+		}
+
+		return option == nbdOptGo, nil
+	case nbdOptAbort:
+		s.optionReply(conn, option, nbdRepAck, nil)
+		return false, fmt.Errorf("NBD client aborted negotiation")
+	default:
+		return false, s.optionReply(conn, option, nbdRepErrUnsup, nil)
+	}
+}
+
+// optionReply sends a reply to an option.
+func (s *Server) optionReply(conn io.Writer, option uint32, replyType uint32, data []byte) error {
+	reply := make([]byte, 20+len(data))
+	binary.BigEndian.PutUint64(reply, nbdOptReplyMagic)
+	binary.BigEndian.PutUint32(reply[8:], option)
+	binary.BigEndian.PutUint32(reply[12:], replyType)
+	binary.BigEndian.PutUint32(reply[16:], uint32(len(data)))
+	copy(reply[20:], data)
+
+	_, err := conn.Write(reply)
+	if err != nil {
+		return fmt.Errorf("Failed sending NBD option reply: %w", err)
+	}
+
+	return nil
+}
+
+// transmit handles the client requests until it disconnects.
+func (s *Server) transmit(conn io.ReadWriter) error {
+	header := make([]byte, 28)
+	data := []byte{}
+
+	for {
+		_, err := io.ReadFull(conn, header)
+		if err != nil {
+			return fmt.Errorf("Failed receiving NBD request: %w", err)
+		}
+
+		if binary.BigEndian.Uint32(header) != nbdRequestMagic {
+			return fmt.Errorf("Invalid NBD request magic")
+		}
+
+		cmd := binary.BigEndian.Uint16(header[6:])
+		handle := header[8:16]
+		offset := int64(binary.BigEndian.Uint64(header[16:]))
+		length := int64(binary.BigEndian.Uint32(header[24:]))
+
+		if cmd == nbdCmdDisc {
+			return nil
+		}
+
+		valid := length <= maxRequestLength && offset >= 0 && offset+length <= s.size
+
+		if cmd == nbdCmdRead || cmd == nbdCmdWrite {
+			if length > maxRequestLength {
+				return fmt.Errorf("NBD request too long")
+			}
+
+			if int64(cap(data)) < length {
+				data = make([]byte, length)
+			}
+
+			data = data[:length]
+		}
+
+		var errno uint32
+
+		switch cmd {
+		case nbdCmdRead:
+			if !valid {
+				errno = nbdEINVAL
+			} else {
+				_, err = s.backend.ReadAt(data, offset)
+				if err != nil {
+					errno = nbdEIO
+				}
+			}
+
+		case nbdCmdWrite:
+			_, err = io.ReadFull(conn, data)
+			if err != nil {
+				return fmt.Errorf("Failed receiving NBD write: %w", err)
+			}
+
+			if !valid {
+				errno = nbdEINVAL
+			} else {
+				_, err = s.backend.WriteAt(data, offset)
+				if err != nil {
+					errno = nbdEIO
+				} else if length > 0 {
+					s.mu.Lock()
+					last := len(s.written) - 1
+					if last >= 0 && s.written[last].Offset+s.written[last].Length == offset {
+						s.written[last].Length += length
+					} else {
+						s.written = append(s.written, Extent{Offset: offset, Length: length})
+					}
+
+					s.mu.Unlock()
+				}
+			}
+
+		case nbdCmdFlush:
+		default:
+			errno = nbdEINVAL
+		}
+
+		reply := make([]byte, 16)
+		binary.BigEndian.PutUint32(reply, nbdSimpleReplyMagic)
+		binary.BigEndian.PutUint32(reply[4:], errno)
+		copy(reply[8:], handle)
+
+		if cmd == nbdCmdRead && errno == 0 {
+			reply = append(reply, data...)
+		}
+
+		_, err = conn.Write(reply)
+		if err != nil {
+			return fmt.Errorf("Failed sending NBD reply: %w", err)
+		}
+	}
+}
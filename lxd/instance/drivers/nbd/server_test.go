@@ -0,0 +1,161 @@
+package nbd_test
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/lxd/lxd/instance/drivers/nbd"
+)
+
+// testClient is a minimal NBD client.
+type testClient struct {
+	t    *testing.T
+	conn net.Conn
+}
+
+func (c *testClient) negotiate(option uint32) {
+	buf := make([]byte, 18)
+	_, err := io.ReadFull(c.conn, buf)
+	require.NoError(c.t, err)
+	assert.Equal(c.t, "NBDMAGICIHAVEOPT", string(buf[:16]))
+
+	// Ask for the fixed newstyle handshake with no padding.
+	_, err = c.conn.Write([]byte{0, 0, 0, 3})
+	require.NoError(c.t, err)
+
+	name := "disk"
+	req := make([]byte, 16)
+	copy(req, "IHAVEOPT")
+	binary.BigEndian.PutUint32(req[8:], option)
+
+	if option == 1 {
+		binary.BigEndian.PutUint32(req[12:], uint32(len(name)))
+		req = append(req, name...)
+	} else {
+		data := make([]byte, 4, 4+len(name)+2)
+		binary.BigEndian.PutUint32(data, uint32(len(name)))
+		data = append(data, name...)
+		data = append(data, 0, 0)
+
+		binary.BigEndian.PutUint32(req[12:], uint32(len(data)))
+		req = append(req, data...)
+	}
+
+	_, err = c.conn.Write(req)
+	require.NoError(c.t, err)
+}
+
+func (c *testClient) request(cmd uint16, offset int64, length int, data []byte) (uint32, []byte) {
+	req := make([]byte, 28)
+	binary.BigEndian.PutUint32(req, 0x25609513)
+	binary.BigEndian.PutUint16(req[6:], cmd)
+	copy(req[8:], "handle00")
+	binary.BigEndian.PutUint64(req[16:], uint64(offset))
+	binary.BigEndian.PutUint32(req[24:], uint32(length))
+
+	_, err := c.conn.Write(append(req, data...))
+	require.NoError(c.t, err)
+
+	reply := make([]byte, 16)
+	_, err = io.ReadFull(c.conn, reply)
+	require.NoError(c.t, err)
+	assert.Equal(c.t, uint32(0x67446698), binary.BigEndian.Uint32(reply))
+	assert.Equal(c.t, "handle00", string(reply[8:]))
+
+	errno := binary.BigEndian.Uint32(reply[4:])
+	if cmd != 0 || errno != 0 {
+		return errno, nil
+	}
+
+	content := make([]byte, length)
+	_, err = io.ReadFull(c.conn, content)
+	require.NoError(c.t, err)
+
+	return errno, content
+}
+
+func newTestServer(t *testing.T, size int64) (*nbd.Server, *testClient, chan error) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "export"))
+	require.NoError(t, err)
+	t.Cleanup(func() { f.Close() })
+
+	require.NoError(t, f.Truncate(size))
+
+	server := nbd.NewServer(f, size)
+	serverConn, clientConn := net.Pipe()
+	t.Cleanup(func() { clientConn.Close() })
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Serve(serverConn)
+		serverConn.Close()
+	}()
+
+	return server, &testClient{t: t, conn: clientConn}, done
+}
+
+func TestServer_Go(t *testing.T) {
+	server, client, done := newTestServer(t, 8192)
+	client.negotiate(7)
+
+	// NBD_REP_INFO with the export size and flags.
+	reply := make([]byte, 32)
+	_, err := io.ReadFull(client.conn, reply)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(3), binary.BigEndian.Uint32(reply[12:]))
+	assert.Equal(t, uint64(8192), binary.BigEndian.Uint64(reply[22:]))
+
+	// NBD_REP_ACK.
+	reply = make([]byte, 20)
+	_, err = io.ReadFull(client.conn, reply)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), binary.BigEndian.Uint32(reply[12:]))
+
+	errno, _ := client.request(1, 4096, 4, []byte("data"))
+	assert.Equal(t, uint32(0), errno)
+
+	errno, _ = client.request(1, 0, 512, make([]byte, 512))
+	assert.Equal(t, uint32(0), errno)
+
+	errno, _ = client.request(1, 512, 512, make([]byte, 512))
+	assert.Equal(t, uint32(0), errno)
+
+	errno, _ = client.request(1, 8190, 4, []byte("over"))
+	assert.Equal(t, uint32(22), errno)
+
+	errno, content := client.request(0, 4096, 4, nil)
+	assert.Equal(t, uint32(0), errno)
+	assert.Equal(t, "data", string(content))
+
+	errno, _ = client.request(3, 0, 0, nil)
+	assert.Equal(t, uint32(0), errno)
+
+	assert.Equal(t, []nbd.Extent{{Offset: 0, Length: 1024}, {Offset: 4096, Length: 4}}, server.Written())
+
+	req := make([]byte, 28)
+	binary.BigEndian.PutUint32(req, 0x25609513)
+	binary.BigEndian.PutUint16(req[6:], 2)
+	_, err = client.conn.Write(req)
+	require.NoError(t, err)
+	assert.NoError(t, <-done)
+}
+
+func TestServer_ExportName(t *testing.T) {
+	_, client, _ := newTestServer(t, 4096)
+	client.negotiate(1)
+
+	reply := make([]byte, 10)
+	_, err := io.ReadFull(client.conn, reply)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4096), binary.BigEndian.Uint64(reply))
+
+	errno, _ := client.request(1, 0, 4, []byte("data"))
+	assert.Equal(t, uint32(0), errno)
+}
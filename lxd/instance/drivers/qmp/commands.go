@@ -201,8 +201,9 @@ func (m *Monitor) Migrate(uri string) error {
 	}
 
 	// Wait until it completes or fails.
+	// Poll frequently as the time taken to notice completion adds to the downtime of live migrations.
 	for {
-		time.Sleep(1 * time.Second)
+		time.Sleep(100 * time.Millisecond)
 
 		// Prepare the response.
 		var resp struct {
@@ -238,8 +239,9 @@ func (m *Monitor) MigrateIncoming(uri string) error {
 	}
 
 	// Wait until it completes or fails.
+	// Poll frequently as the time taken to notice completion adds to the downtime of live migrations.
 	for {
-		time.Sleep(1 * time.Second)
+		time.Sleep(100 * time.Millisecond)
 
 		// Preapre the response.
 		var resp struct {
@@ -581,3 +583,460 @@ func (m *Monitor) GetBlockStats() (map[string]BlockStats, error) {
 
 	return out, nil
 }
+
+// GetBlockNodeNames returns the names of the block device nodes used by the guest devices, indexed by device ID.
+func (m *Monitor) GetBlockNodeNames() (map[string]string, error) {
+	var resp struct {
+		Return []struct {
+			QDev     string `json:"qdev"`
+			Inserted struct {
+				NodeName string `json:"node-name"`
+			} `json:"inserted"`
+		} `json:"return"`
+	}
+
+	err := m.run("query-block", nil, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("Failed querying block devices: %w", err)
+	}
+
+	out := make(map[string]string)
+
+	for _, res := range resp.Return {
+		if res.QDev != "" && res.Inserted.NodeName != "" {
+			out[res.QDev] = res.Inserted.NodeName
+		}
+	}
+
+	return out, nil
+}
+
+// BlockDevSnapshot takes a snapshot of a block device node, with the overlay node becoming the active layer.
+// Writes are redirected to the overlay node from then on, leaving the snapshotted node unchanged.
+func (m *Monitor) BlockDevSnapshot(nodeName string, overlayNodeName string) error {
+	args := map[string]string{
+		"node":    nodeName,
+		"overlay": overlayNodeName,
+	}
+
+	err := m.run("blockdev-snapshot", args, nil)
+	if err != nil {
+		return fmt.Errorf("Failed taking block device snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// BlockDevMirror starts a block job mirroring a block device node to the target node. The sync mode is either
+// "full" to copy the whole disk, "top" to only copy its active layer or "none" to only mirror new writes.
+// Once the job is ready, guest writes are only completed after they have been mirrored to the target.
+func (m *Monitor) BlockDevMirror(jobID string, nodeName string, targetNodeName string, sync string) error {
+	args := map[string]any{
+		"job-id":       jobID,
+		"device":       nodeName,
+		"target":       targetNodeName,
+		"sync":         sync,
+		"copy-mode":    "write-blocking",
+		"auto-dismiss": false,
+	}
+
+	err := m.run("blockdev-mirror", args, nil)
+	if err != nil {
+		return fmt.Errorf("Failed starting block device mirror: %w", err)
+	}
+
+	return nil
+}
+
+// BlockCommit starts a block job committing the active layer of a block device node into its backing node.
+func (m *Monitor) BlockCommit(jobID string, nodeName string) error {
+	args := map[string]any{
+		"job-id":       jobID,
+		"device":       nodeName,
+		"auto-dismiss": false,
+	}
+
+	err := m.run("block-commit", args, nil)
+	if err != nil {
+		return fmt.Errorf("Failed starting block commit: %w", err)
+	}
+
+	return nil
+}
+
+// BlockJobCancel cancels a block job.
+// Cancelling a ready mirror job completes it without switching the device over to the target.
+func (m *Monitor) BlockJobCancel(jobID string) error {
+	args := map[string]string{"device": jobID}
+
+	err := m.run("block-job-cancel", args, nil)
+	if err != nil {
+		return fmt.Errorf("Failed cancelling block job: %w", err)
+	}
+
+	return nil
+}
+
+// BlockJobComplete completes a ready block job, switching the device over to the job's target.
+func (m *Monitor) BlockJobComplete(jobID string) error {
+	args := map[string]string{"device": jobID}
+
+	err := m.run("block-job-complete", args, nil)
+	if err != nil {
+		return fmt.Errorf("Failed completing block job: %w", err)
+	}
+
+	return nil
+}
+
+// BlockJob represents a block job.
+type BlockJob struct {
+	Device string `json:"device"`
+	Type   string `json:"type"`
+	Len    int64  `json:"len"`
+	Offset int64  `json:"offset"`
+	Ready  bool   `json:"ready"`
+	Status string `json:"status"`
+	Error  string `json:"error"`
+}
+
+// QueryBlockJobs returns the block jobs.
+func (m *Monitor) QueryBlockJobs() ([]BlockJob, error) {
+	var resp struct {
+		Return []BlockJob `json:"return"`
+	}
+
+	err := m.run("query-block-jobs", nil, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("Failed querying block jobs: %w", err)
+	}
+
+	return resp.Return, nil
+}
+
+// BlockJobWait waits for a block job to be ready, or to have concluded if ready is false.
+// A concluded job is dismissed and its error, if any, is returned.
+func (m *Monitor) BlockJobWait(jobID string, ready bool) error {
+	for {
+		jobs, err := m.QueryBlockJobs()
+		if err != nil {
+			return err
+		}
+
+		var job *BlockJob
+		for i := range jobs {
+			if jobs[i].Device == jobID {
+				job = &jobs[i]
+				break
+			}
+		}
+
+		if job == nil {
+			return fmt.Errorf("Block job %q not found", jobID)
+		}
+
+		if job.Status == "concluded" {
+			err := m.run("job-dismiss", map[string]string{"id": jobID}, nil)
+			if err != nil {
+				return fmt.Errorf("Failed dismissing block job: %w", err)
+			}
+
+			if job.Error != "" {
+				return fmt.Errorf("Block job %q failed: %s", jobID, job.Error)
+			}
+
+			if ready {
+				return fmt.Errorf("Block job %q concluded before being ready", jobID)
+			}
+
+			return nil
+		}
+
+		if ready && job.Ready {
+			return nil
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// NBDServerStart starts the NBD server, listening on a unix socket.
+func (m *Monitor) NBDServerStart(path string) error {
+	args := map[string]any{
+		"addr": map[string]any{
+			"type": "unix",
+			"data": map[string]string{"path": path},
+		},
+	}
+
+	err := m.run("nbd-server-start", args, nil)
+	if err != nil {
+		return fmt.Errorf("Failed starting NBD server: %w", err)
+	}
+
+	return nil
+}
+
+// NBDServerStop stops the NBD server.
+func (m *Monitor) NBDServerStop() error {
+	err := m.run("nbd-server-stop", nil, nil)
+	if err != nil {
+		return fmt.Errorf("Failed stopping NBD server: %w", err)
+	}
+
+	return nil
+}
+
+// BlockExportAddNBD exports a writable block device node over the NBD server, using exportName as both export ID
+// and export name.
+func (m *Monitor) BlockExportAddNBD(exportName string, nodeName string) error {
+	args := map[string]any{
+		"type":      "nbd",
+		"id":        exportName,
+		"name":      exportName,
+		"node-name": nodeName,
+		"writable":  true,
+	}
+
+	err := m.run("block-export-add", args, nil)
+	if err != nil {
+		return fmt.Errorf("Failed adding NBD block export: %w", err)
+	}
+
+	return nil
+}
+
+// BlockExportDel removes a block export.
+func (m *Monitor) BlockExportDel(id string) error {
+	err := m.run("block-export-del", map[string]string{"id": id}, nil)
+	if err != nil {
+		return fmt.Errorf("Failed removing block export: %w", err)
+	}
+
+	return nil
+}
+
+// BlockDirtyBitmapAdd adds a dirty bitmap to a block device node, tracking the areas written from then on.
+func (m *Monitor) BlockDirtyBitmapAdd(nodeName string, name string) error {
+	args := map[string]string{
+		"node": nodeName,
+		"name": name,
+	}
+
+	err := m.run("block-dirty-bitmap-add", args, nil)
+	if err != nil {
+		return fmt.Errorf("Failed adding dirty bitmap: %w", err)
+	}
+
+	return nil
+}
+
+// BlockDirtyBitmapRemove removes a dirty bitmap from a block device node.
+func (m *Monitor) BlockDirtyBitmapRemove(nodeName string, name string) error {
+	args := map[string]string{
+		"node": nodeName,
+		"name": name,
+	}
+
+	err := m.run("block-dirty-bitmap-remove", args, nil)
+	if err != nil {
+		return fmt.Errorf("Failed removing dirty bitmap: %w", err)
+	}
+
+	return nil
+}
+
+// BlockNode represents a block device node.
+type BlockNode struct {
+	NodeName string `json:"node-name"`
+	Image    struct {
+		VirtualSize int64 `json:"virtual-size"`
+	} `json:"image"`
+	DirtyBitmaps []struct {
+		Name string `json:"name"`
+	} `json:"dirty-bitmaps"`
+}
+
+// QueryBlockNode returns the block device node.
+func (m *Monitor) QueryBlockNode(nodeName string) (*BlockNode, error) {
+	var resp struct {
+		Return []BlockNode `json:"return"`
+	}
+
+	err := m.run("query-named-block-nodes", nil, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("Failed querying block nodes: %w", err)
+	}
+
+	for _, node := range resp.Return {
+		if node.NodeName == nodeName {
+			return &node, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Block node %q not found", nodeName)
+}
+
+// BlockDevBackupIncremental starts a block job copying the areas of a block device node recorded in a dirty bitmap
+// to the target node, as they were when the job started. A new dirty bitmap tracking the areas written from then
+// on is atomically added to the node. The existing dirty bitmap is left unchanged.
+func (m *Monitor) BlockDevBackupIncremental(jobID string, nodeName string, targetNodeName string, bitmap string, newBitmap string) error {
+	args := map[string]any{
+		"actions": []map[string]any{
+			{
+				"type": "block-dirty-bitmap-add",
+				"data": map[string]any{
+					"node": nodeName,
+					"name": newBitmap,
+				},
+			},
+			{
+				"type": "blockdev-backup",
+				"data": map[string]any{
+					"job-id":       jobID,
+					"device":       nodeName,
+					"target":       targetNodeName,
+					"sync":         "bitmap",
+					"bitmap":       bitmap,
+					"bitmap-mode":  "never",
+					"auto-dismiss": false,
+				},
+			},
+		},
+	}
+
+	err := m.run("transaction", args, nil)
+	if err != nil {
+		return fmt.Errorf("Failed starting incremental block device backup: %w", err)
+	}
+
+	return nil
+}
+
+// HotpluggableCPU represents a vCPU slot which can be hot-plugged.
+type HotpluggableCPU struct {
+	Type       string         `json:"type"`
+	VCPUsCount int            `json:"vcpus-count"`
+	QOMPath    string         `json:"qom-path"`
+	Props      map[string]any `json:"props"`
+}
+
+// QueryHotpluggableCPUs returns the vCPU slots of the VM. Slots which are in use have a QOM path.
+func (m *Monitor) QueryHotpluggableCPUs() ([]HotpluggableCPU, error) {
+	var resp struct {
+		Return []HotpluggableCPU `json:"return"`
+	}
+
+	err := m.run("query-hotpluggable-cpus", nil, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("Failed querying hotpluggable CPUs: %w", err)
+	}
+
+	return resp.Return, nil
+}
+
+// AddCPU hot-plugs a vCPU into a free slot.
+func (m *Monitor) AddCPU(id string, cpu HotpluggableCPU) error {
+	args := map[string]any{
+		"driver": cpu.Type,
+		"id":     id,
+	}
+
+	for key, value := range cpu.Props {
+		args[key] = value
+	}
+
+	err := m.run("device_add", args, nil)
+	if err != nil {
+		return fmt.Errorf("Failed adding CPU: %w", err)
+	}
+
+	return nil
+}
+
+// MemoryDevice represents a memory device plugged into the VM.
+type MemoryDevice struct {
+	Type string `json:"type"`
+	Data struct {
+		ID           string `json:"id"`
+		Size         int64  `json:"size"`
+		Memdev       string `json:"memdev"`
+		Hotplugged   bool   `json:"hotplugged"`
+		Hotpluggable bool   `json:"hotpluggable"`
+	} `json:"data"`
+}
+
+// QueryMemoryDevices returns the memory devices plugged into the VM.
+func (m *Monitor) QueryMemoryDevices() ([]MemoryDevice, error) {
+	var resp struct {
+		Return []MemoryDevice `json:"return"`
+	}
+
+	err := m.run("query-memory-devices", nil, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("Failed querying memory devices: %w", err)
+	}
+
+	return resp.Return, nil
+}
+
+// AddObject adds a QOM object, such as a memory backend.
+func (m *Monitor) AddObject(args map[string]any) error {
+	err := m.run("object-add", args, nil)
+	if err != nil {
+		return fmt.Errorf("Failed adding object: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveObject removes a QOM object.
+func (m *Monitor) RemoveObject(id string) error {
+	err := m.run("object-del", map[string]string{"id": id}, nil)
+	if err != nil {
+		return fmt.Errorf("Failed removing object: %w", err)
+	}
+
+	return nil
+}
+
+// SEVInfo represents the AMD SEV state of the guest.
+type SEVInfo struct {
+	Enabled  bool   `json:"enabled"`
+	APIMajor int    `json:"api-major"`
+	APIMinor int    `json:"api-minor"`
+	BuildID  int    `json:"build-id"`
+	Policy   uint32 `json:"policy"`
+	State    string `json:"state"`
+	Handle   uint32 `json:"handle"`
+}
+
+// QuerySEV returns the AMD SEV state of the guest.
+func (m *Monitor) QuerySEV() (*SEVInfo, error) {
+	var resp struct {
+		Return SEVInfo `json:"return"`
+	}
+
+	err := m.run("query-sev", nil, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("Failed querying SEV state: %w", err)
+	}
+
+	return &resp.Return, nil
+}
+
+// QuerySEVLaunchMeasure returns the base64 encoded launch measurement of the AMD SEV guest.
+func (m *Monitor) QuerySEVLaunchMeasure() (string, error) {
+	var resp struct {
+		Return struct {
+			Data string `json:"data"`
+		} `json:"return"`
+	}
+
+	err := m.run("query-sev-launch-measure", nil, &resp)
+	if err != nil {
+		return "", fmt.Errorf("Failed querying SEV launch measurement: %w", err)
+	}
+
+	return resp.Return.Data, nil
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/lxc/lxd/lxd/operations"
 	"github.com/lxc/lxd/shared/api"
 	"github.com/lxc/lxd/shared/idmap"
+	"github.com/lxc/lxd/shared/instancewriter"
 )
 
 // HookStart hook used when instance has started.
@@ -169,6 +170,55 @@ type Container interface {
 	IdmappedStorage(path string) idmap.IdmapStorageType
 }
 
+// VM interface is for VM specific functions.
+type VM interface {
+	Instance
+
+	MigrateSendLive(args LiveMigrateSendArgs) error
+	MigrateReceiveLive(args LiveMigrateReceiveArgs) error
+	MoveDiskLive(deviceName string, target deviceConfig.MountEntryItem, progress func(done int64, total int64)) error
+
+	BackupCapture() (func() error, error)
+	BackupCheckpointAdd(checkpoint string) error
+	BackupCheckpointRemove(checkpoint string) error
+	BackupDelta(baseCheckpoint string, checkpoint string, tarWriter *instancewriter.InstanceTarWriter, name string, progress func(done int64, total int64)) error
+
+	FreezeFilesystems(timeout time.Duration) (func() error, error)
+
+	GuestInfo() (*api.InstanceGuest, error)
+}
+
+// LiveMigrateSendArgs arguments for live migrating a running VM to a target.
+type LiveMigrateSendArgs struct {
+	// Connection used to send the VM's state to the target.
+	StateConn io.ReadWriteCloser
+
+	// Connection used to mirror root disk writes to the target, nil if the root disk is on shared storage.
+	DiskConn io.ReadWriteCloser
+
+	// Called once root disk writes have been redirected to a temporary overlay, to transfer the root volume
+	// to the target while the VM keeps running. Not called if DiskConn is nil.
+	TransferVolume func() error
+
+	// Waits for the target to be ready to receive the root disk writes and the VM's state.
+	TargetReady func() error
+
+	// Waits for the target to have resumed the VM.
+	TargetResumed func() error
+}
+
+// LiveMigrateReceiveArgs arguments for receiving a running VM from a source.
+type LiveMigrateReceiveArgs struct {
+	// Connection used to receive the VM's state from the source.
+	StateConn io.ReadWriteCloser
+
+	// Connection used to receive the root disk writes from the source, nil if the root disk is on shared storage.
+	DiskConn io.ReadWriteCloser
+
+	// Notifies the source that the target is ready to receive the root disk writes and the VM's state.
+	Ready func() error
+}
+
 // CriuMigrationArgs arguments for CRIU migration.
 type CriuMigrationArgs struct {
 	Cmd          uint
@@ -22,11 +22,13 @@ import (
 	deviceConfig "github.com/lxc/lxd/lxd/device/config"
 	"github.com/lxc/lxd/lxd/instance/instancetype"
 	"github.com/lxc/lxd/lxd/instance/operationlock"
+	"github.com/lxc/lxd/lxd/oci"
 	"github.com/lxc/lxd/lxd/project"
 	"github.com/lxc/lxd/lxd/revert"
 	"github.com/lxc/lxd/lxd/seccomp"
 	"github.com/lxc/lxd/lxd/state"
 	"github.com/lxc/lxd/lxd/sys"
+	"github.com/lxc/lxd/lxd/util"
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/api"
 	"github.com/lxc/lxd/shared/idmap"
@@ -207,6 +209,25 @@ func ValidConfig(sysOS *sys.OS, config map[string]string, expanded bool, instanc
 		return fmt.Errorf("nvidia.runtime is incompatible with privileged containers")
 	}
 
+	if shared.IsTrue(config["security.sev"]) && shared.IsTrue(config["security.tdx"]) {
+		return fmt.Errorf("security.sev and security.tdx are mutually exclusive")
+	}
+
+	if expanded && shared.IsTrue(config["security.sev.policy.es"]) && shared.IsFalseOrEmpty(config["security.sev"]) {
+		return fmt.Errorf("security.sev.policy.es requires security.sev to be enabled")
+	}
+
+	if (shared.IsTrue(config["security.sev"]) || shared.IsTrue(config["security.tdx"])) && shared.IsTrue(config["migration.stateful"]) {
+		return fmt.Errorf("Guest memory encryption is incompatible with migration.stateful")
+	}
+
+	if expanded && (config["limits.cpu.nodes"] != "" || config["limits.memory.nodes"] != "") && config["limits.cpu"] != "" {
+		_, err := strconv.Atoi(config["limits.cpu"])
+		if err != nil {
+			return fmt.Errorf("limits.cpu.nodes and limits.memory.nodes can't be used with CPU pinning")
+		}
+	}
+
 	return nil
 }
 
@@ -775,6 +796,21 @@ func SuitableArchitectures(s *state.State, project string, req api.InstancesPost
 				return nil, nil
 			}
 
+			if req.Source.Protocol == "oci" {
+				// Remote OCI registry.
+				httpClient, err := util.HTTPClient(req.Source.Certificate, s.Proxy)
+				if err != nil {
+					return nil, err
+				}
+
+				registry, err := oci.NewRegistry(req.Source.Server, httpClient, version.UserAgent)
+				if err != nil {
+					return nil, err
+				}
+
+				return registry.GetArchitectures(hash)
+			}
+
 			var remote lxd.ImageServer
 			if shared.StringInSlice(req.Source.Protocol, []string{"", "lxd"}) {
 				// Remote LXD image server.
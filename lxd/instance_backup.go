@@ -290,6 +290,10 @@ func instanceBackupsPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Backup names may not contain slashes"))
 	}
 
+	if req.IncrementalFrom != "" && inst.Type() != instancetype.VM {
+		return response.BadRequest(fmt.Errorf("Incremental backups are only supported for virtual machines"))
+	}
+
 	fullName := name + shared.SnapshotDelimiter + req.Name
 	instanceOnly := req.InstanceOnly || req.ContainerOnly
 
@@ -302,6 +306,7 @@ func instanceBackupsPost(d *Daemon, r *http.Request) response.Response {
 			InstanceOnly:         instanceOnly,
 			OptimizedStorage:     req.OptimizedStorage,
 			CompressionAlgorithm: req.CompressionAlgorithm,
+			IncrementalFrom:      req.IncrementalFrom,
 		}
 
 		err := backupCreate(d.State(), args, inst, op)
@@ -154,55 +154,16 @@ func (s *execWs) Do(op *operations.Operation) error {
 	var stderr *os.File
 
 	if s.req.Interactive {
-		if s.instance.Type() == instancetype.Container {
-			// For containers, we setup a PTY on the LXD server.
-			ttys = make([]*os.File, 1)
-			ptys = make([]*os.File, 1)
-
-			var rootUID, rootGID int64
-			var devptsFd *os.File
-
-			c := s.instance.(instance.Container)
-			idmapset, err := c.CurrentIdmap()
-			if err != nil {
-				return err
-			}
-
-			if idmapset != nil {
-				rootUID, rootGID = idmapset.ShiftIntoNs(0, 0)
-			}
-
-			devptsFd, _ = c.DevptsFd()
-
-			if devptsFd != nil && s.s.OS.NativeTerminals {
-				ptys[0], ttys[0], err = shared.OpenPtyInDevpts(int(devptsFd.Fd()), rootUID, rootGID)
-				devptsFd.Close()
-				devptsFd = nil
-			} else {
-				ptys[0], ttys[0], err = shared.OpenPty(rootUID, rootGID)
-			}
-			if err != nil {
-				return err
-			}
+		ttys, ptys, err = execInteractiveFiles(s.s, s.instance, s.req.Width, s.req.Height)
+		if err != nil {
+			return err
+		}
 
+		if s.instance.Type() == instancetype.Container {
 			stdin = ttys[0]
 			stdout = ttys[0]
 			stderr = ttys[0]
-
-			if s.req.Width > 0 && s.req.Height > 0 {
-				shared.SetSize(int(ptys[0].Fd()), s.req.Width, s.req.Height)
-			}
 		} else {
-			// For VMs we rely on the lxd-agent PTY running inside the VM guest.
-			ttys = make([]*os.File, 2)
-			ptys = make([]*os.File, 2)
-			for i := 0; i < len(ttys); i++ {
-				ptys[i], ttys[i], err = os.Pipe()
-				if err != nil {
-					return err
-				}
-			}
-
 			stdin = ptys[execWSStdin]
 			stdout = ttys[execWSStdout]
 		}
@@ -449,6 +410,61 @@ func (s *execWs) Do(op *operations.Operation) error {
 	return finisher(exitStatus, err)
 }
 
+// execInteractiveFiles returns the terminal files of an interactive command. For containers, these are a PTY set up
+// on the LXD server. For VMs, these are pipes to relay the lxd-agent PTY running inside the VM guest, indexed by the
+// websocket number reading from (stdin) or writing to (stdout) them.
+func execInteractiveFiles(s *state.State, inst instance.Instance, width int, height int) ([]*os.File, []*os.File, error) {
+	var err error
+
+	if inst.Type() == instancetype.Container {
+		ttys := make([]*os.File, 1)
+		ptys := make([]*os.File, 1)
+
+		var rootUID, rootGID int64
+		var devptsFd *os.File
+
+		c := inst.(instance.Container)
+		idmapset, err := c.CurrentIdmap()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if idmapset != nil {
+			rootUID, rootGID = idmapset.ShiftIntoNs(0, 0)
+		}
+
+		devptsFd, _ = c.DevptsFd()
+
+		if devptsFd != nil && s.OS.NativeTerminals {
+			ptys[0], ttys[0], err = shared.OpenPtyInDevpts(int(devptsFd.Fd()), rootUID, rootGID)
+			devptsFd.Close()
+			devptsFd = nil
+		} else {
+			ptys[0], ttys[0], err = shared.OpenPty(rootUID, rootGID)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if width > 0 && height > 0 {
+			shared.SetSize(int(ptys[0].Fd()), width, height)
+		}
+
+		return ttys, ptys, nil
+	}
+
+	ttys := make([]*os.File, 2)
+	ptys := make([]*os.File, 2)
+	for i := 0; i < len(ttys); i++ {
+		ptys[i], ttys[i], err = os.Pipe()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return ttys, ptys, nil
+}
+
 // swagger:operation POST /1.0/instances/{name}/exec instances instance_exec_post
 //
 // Run a command
@@ -462,6 +478,9 @@ func (s *execWs) Do(op *operations.Operation) error {
 // An additional "control" socket is always added on top which can be used for out of band communication with LXD.
 // This allows sending signals and window sizing information through.
 //
+// In detachable mode, the command runs in an exec session which survives the client disconnecting and which can be
+// attached to again, the operation metadata containing the session ID.
+//
 // ---
 // consumes:
 //   - application/json
@@ -600,6 +619,19 @@ func instanceExecPost(d *Daemon, r *http.Request) response.Response {
 		post.Environment["LANG"] = "C.UTF-8"
 	}
 
+	if post.Detachable {
+		if !post.Interactive || !post.WaitForWS {
+			return response.BadRequest(fmt.Errorf("Detachable exec sessions require interactive mode and waiting for the websockets"))
+		}
+
+		session, err := execSessionStart(d.State(), inst, post)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		return execSessionAttach(d.State(), r, projectName, session, api.InstanceExecSessionPost{})
+	}
+
 	if post.WaitForWS {
 		ws := &execWs{}
 		ws.s = d.State()
@@ -0,0 +1,983 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/pborman/uuid"
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/lxd/lxd/cluster"
+	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/instance"
+	"github.com/lxc/lxd/lxd/instance/instancetype"
+	"github.com/lxc/lxd/lxd/operations"
+	"github.com/lxc/lxd/lxd/response"
+	"github.com/lxc/lxd/lxd/state"
+	"github.com/lxc/lxd/lxd/util"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/logger"
+	"github.com/lxc/lxd/shared/version"
+)
+
+// execSessionBufferSize is the amount of the most recent output of an exec session that is kept to be replayed to
+// the clients attaching to it.
+const execSessionBufferSize = 1024 * 1024
+
+// execSessionExpiry is how long an exec session is kept once its command exited, so that a client can still attach
+// to it to get its output and exit status.
+const execSessionExpiry = time.Hour
+
+// execSessionWriteTimeout is how long sending output to the attached client may block before the client is
+// considered gone and detached, so that an unreachable client doesn't block the command.
+const execSessionWriteTimeout = 10 * time.Second
+
+// execSessions contains the detachable exec sessions of the instances running on this member, indexed by ID.
+var execSessions = map[string]*execSession{}
+var execSessionsLock sync.Mutex
+
+// execRingBuffer keeps the most recent data written to it, up to its size.
+type execRingBuffer struct {
+	data []byte
+	pos  int
+	full bool
+}
+
+func newExecRingBuffer(size int) *execRingBuffer {
+	return &execRingBuffer{data: make([]byte, size)}
+}
+
+// Write adds p to the buffer, overwriting the oldest data once the buffer is full.
+func (b *execRingBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+
+	if len(p) >= len(b.data) {
+		copy(b.data, p[len(p)-len(b.data):])
+		b.pos = 0
+		b.full = true
+
+		return n, nil
+	}
+
+	written := copy(b.data[b.pos:], p)
+	if written < len(p) {
+		copy(b.data, p[written:])
+	}
+
+	if b.pos+len(p) >= len(b.data) {
+		b.full = true
+	}
+
+	b.pos = (b.pos + len(p)) % len(b.data)
+
+	return n, nil
+}
+
+// Bytes returns a copy of the data in the buffer, oldest first.
+func (b *execRingBuffer) Bytes() []byte {
+	if !b.full {
+		return append([]byte{}, b.data[:b.pos]...)
+	}
+
+	return append(append([]byte{}, b.data[b.pos:]...), b.data[:b.pos]...)
+}
+
+// execSession is an interactive command whose lifetime isn't tied to the client that started it. Its output is kept
+// in a ring buffer while no client is attached, and replayed to the next client attaching to it.
+type execSession struct {
+	id        string
+	instance  instance.Instance
+	req       api.InstanceExecPost
+	createdAt time.Time
+
+	cmd      instance.Cmd
+	input    *os.File
+	resizeFd int
+
+	// Closed once the command exited and all of its output was read.
+	done chan struct{}
+
+	// How long the session is kept once its command exited.
+	expiry time.Duration
+
+	mu       sync.Mutex
+	output   *execRingBuffer
+	attached bool
+	client   *websocket.Conn
+	exitCode int
+}
+
+// execSessionStart starts an interactive command in a new exec session.
+func execSessionStart(s *state.State, inst instance.Instance, req api.InstanceExecPost) (*execSession, error) {
+	ttys, ptys, err := execInteractiveFiles(s, inst, req.Width, req.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	closeFiles := func() {
+		for _, tty := range ttys {
+			tty.Close()
+		}
+
+		for _, pty := range ptys {
+			pty.Close()
+		}
+	}
+
+	session := &execSession{
+		id:        uuid.New(),
+		instance:  inst,
+		req:       req,
+		createdAt: time.Now(),
+		resizeFd:  -1,
+		done:      make(chan struct{}),
+		expiry:    execSessionExpiry,
+		output:    newExecRingBuffer(execSessionBufferSize),
+		exitCode:  -1,
+	}
+
+	var stdin, stdout, stderr, output *os.File
+	if inst.Type() == instancetype.Container {
+		stdin = ttys[0]
+		stdout = ttys[0]
+		stderr = ttys[0]
+		session.input = ptys[0]
+		session.resizeFd = int(ptys[0].Fd())
+		output = ptys[0]
+	} else {
+		stdin = ptys[execWSStdin]
+		stdout = ttys[execWSStdout]
+		session.input = ttys[execWSStdin]
+		output = ptys[execWSStdout]
+	}
+
+	// The instance drivers run the command as a regular one, the session being handled here.
+	req.Detachable = false
+
+	session.cmd, err = inst.Exec(req, stdin, stdout, stderr)
+	if err != nil {
+		closeFiles()
+		return nil, err
+	}
+
+	l := logger.AddContext(logger.Log, logger.Ctx{"project": inst.Project(), "instance": inst.Name(), "PID": session.cmd.PID(), "session": session.id})
+	l.Debug("Exec session started")
+
+	exited := make(chan struct{})
+	outputDone := make(chan struct{})
+
+	go func() {
+		defer close(outputDone)
+
+		var ch <-chan []byte
+		if inst.Type() == instancetype.Container {
+			// For containers, the command runs on the local LXD managed PTY, which needs the special
+			// handling of shared.ExecReaderToChannel.
+			ch = shared.ExecReaderToChannel(output, -1, exited, int(output.Fd()))
+		} else {
+			ch = shared.ReaderToChannel(output, -1)
+		}
+
+		for buf := range ch {
+			session.writeOutput(buf)
+		}
+	}()
+
+	go func() {
+		exitStatus, err := session.cmd.Wait()
+		if err != nil {
+			l.Warn("Failed waiting for exec session command", logger.Ctx{"err": err})
+		}
+
+		close(exited)
+
+		for _, tty := range ttys {
+			tty.Close()
+		}
+
+		<-outputDone
+
+		for _, pty := range ptys {
+			pty.Close()
+		}
+
+		session.exit(exitStatus)
+		l.Debug("Exec session command stopped", logger.Ctx{"exitStatus": exitStatus})
+	}()
+
+	execSessionsLock.Lock()
+	execSessions[session.id] = session
+	execSessionsLock.Unlock()
+
+	return session, nil
+}
+
+// execSessionLoad returns the exec session of the instance with the given ID.
+func execSessionLoad(projectName string, instanceName string, id string) (*execSession, error) {
+	execSessionsLock.Lock()
+	defer execSessionsLock.Unlock()
+
+	session, ok := execSessions[id]
+	if !ok || session.instance.Project() != projectName || session.instance.Name() != instanceName {
+		return nil, api.StatusErrorf(http.StatusNotFound, "Exec session not found")
+	}
+
+	return session, nil
+}
+
+// execSessionList returns the exec sessions of the instance, oldest first.
+func execSessionList(projectName string, instanceName string) []*execSession {
+	execSessionsLock.Lock()
+	defer execSessionsLock.Unlock()
+
+	sessions := []*execSession{}
+	for _, session := range execSessions {
+		if session.instance.Project() == projectName && session.instance.Name() == instanceName {
+			sessions = append(sessions, session)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].createdAt.Before(sessions[j].createdAt)
+	})
+
+	return sessions
+}
+
+// execSessionRemove forgets about the exec session.
+func execSessionRemove(session *execSession) {
+	execSessionsLock.Lock()
+	delete(execSessions, session.id)
+	execSessionsLock.Unlock()
+}
+
+// exit records the exit status of the command and schedules the removal of the session once it expired.
+func (s *execSession) exit(exitCode int) {
+	s.mu.Lock()
+	s.exitCode = exitCode
+	s.mu.Unlock()
+
+	close(s.done)
+
+	time.AfterFunc(s.expiry, func() { execSessionRemove(s) })
+}
+
+// exited returns whether the command of the session exited.
+func (s *execSession) exited() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// render returns the API representation of the session.
+func (s *execSession) render() *api.InstanceExecSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := "Running"
+	if s.exited() {
+		status = "Exited"
+	}
+
+	return &api.InstanceExecSession{
+		ID:        s.id,
+		Command:   s.req.Command,
+		User:      s.req.User,
+		Group:     s.req.Group,
+		Cwd:       s.req.Cwd,
+		Status:    status,
+		Attached:  s.attached,
+		Return:    s.exitCode,
+		CreatedAt: s.createdAt,
+	}
+}
+
+// writeOutput keeps the command output in the buffer and sends it to the attached client, if any. The client is
+// detached if sending fails.
+func (s *execSession) writeOutput(buf []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.output.Write(buf)
+
+	if s.client == nil {
+		return
+	}
+
+	s.client.SetWriteDeadline(time.Now().Add(execSessionWriteTimeout))
+
+	err := s.client.WriteMessage(websocket.BinaryMessage, buf)
+	if err != nil {
+		logger.Debug("Failed sending exec session output, detaching client", logger.Ctx{"session": s.id, "err": err})
+		s.client.Close()
+		s.client = nil
+	}
+}
+
+// reserve marks the session as attached, failing if a client is already attached to it.
+func (s *execSession) reserve() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.attached {
+		return api.StatusErrorf(http.StatusConflict, "A client is already attached to the exec session")
+	}
+
+	s.attached = true
+
+	return nil
+}
+
+// attach replays the buffered output to the client websocket and then sends it the output as it comes.
+func (s *execSession) attach(conn *websocket.Conn) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buf := s.output.Bytes()
+	if len(buf) > 0 {
+		conn.SetWriteDeadline(time.Now().Add(execSessionWriteTimeout))
+
+		err := conn.WriteMessage(websocket.BinaryMessage, buf)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.client = conn
+
+	return nil
+}
+
+// detach stops sending the output to the attached client.
+func (s *execSession) detach() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.client = nil
+	s.attached = false
+}
+
+// execSessionAttachWs handles the websockets of a client attached to an exec session.
+type execSessionAttachWs struct {
+	session *execSession
+	req     api.InstanceExecSessionPost
+
+	conns                 map[int]*websocket.Conn
+	connsLock             sync.Mutex
+	requiredConnectedCtx  context.Context
+	requiredConnectedDone func()
+	controlConnectedCtx   context.Context
+	controlConnectedDone  func()
+	fds                   map[int]string
+}
+
+func (s *execSessionAttachWs) Metadata() any {
+	fds := shared.Jmap{}
+	for fd, secret := range s.fds {
+		if fd == execWSControl {
+			fds["control"] = secret
+		} else {
+			fds[strconv.Itoa(fd)] = secret
+		}
+	}
+
+	return shared.Jmap{
+		"fds":         fds,
+		"session":     s.session.id,
+		"command":     s.session.req.Command,
+		"interactive": true,
+	}
+}
+
+func (s *execSessionAttachWs) Connect(op *operations.Operation, r *http.Request, w http.ResponseWriter) error {
+	secret := r.FormValue("secret")
+	if secret == "" {
+		return fmt.Errorf("missing secret")
+	}
+
+	for fd, fdSecret := range s.fds {
+		if secret != fdSecret {
+			continue
+		}
+
+		conn, err := shared.WebsocketUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return err
+		}
+
+		s.connsLock.Lock()
+		defer s.connsLock.Unlock()
+
+		if s.conns[fd] != nil {
+			return fmt.Errorf("Websocket number already connected")
+		}
+
+		s.conns[fd] = conn
+
+		// Only the terminal websocket is required, the control one is optional.
+		if fd == execWSControl {
+			s.controlConnectedDone()
+		} else {
+			s.requiredConnectedDone()
+		}
+
+		return nil
+	}
+
+	/* If we didn't find the right secret, the user provided a bad one,
+	 * which 403, not 404, since this operation actually exists */
+	return os.ErrPermission
+}
+
+func (s *execSessionAttachWs) Do(op *operations.Operation) error {
+	session := s.session
+
+	// Once this function ends ensure that the client is detached and its websockets are closed.
+	defer func() {
+		session.detach()
+		s.controlConnectedDone()
+
+		s.connsLock.Lock()
+		for i := range s.conns {
+			if s.conns[i] != nil {
+				s.conns[i].Close()
+			}
+		}
+		s.connsLock.Unlock()
+	}()
+
+	select {
+	case <-s.requiredConnectedCtx.Done():
+		break
+	case <-time.After(time.Second * 5):
+		return fmt.Errorf("Timed out waiting for websockets to connect")
+	}
+
+	s.connsLock.Lock()
+	conn := s.conns[0]
+	s.connsLock.Unlock()
+
+	err := session.attach(conn)
+	if err != nil {
+		return fmt.Errorf("Failed attaching to exec session: %w", err)
+	}
+
+	l := logger.AddContext(logger.Log, logger.Ctx{"project": session.instance.Project(), "instance": session.instance.Name(), "session": session.id})
+	l.Debug("Client attached to exec session")
+
+	if s.req.Width > 0 && s.req.Height > 0 && !session.exited() {
+		err := session.cmd.WindowResize(session.resizeFd, s.req.Width, s.req.Height)
+		if err != nil {
+			l.Debug("Failed to set window size", logger.Ctx{"err": err, "width": s.req.Width, "height": s.req.Height})
+		}
+	}
+
+	detached := make(chan struct{})
+	var detachOnce sync.Once
+	detach := func() {
+		detachOnce.Do(func() { close(detached) })
+	}
+
+	// Relay the client input to the command. Contrary to regular exec, the client going away detaches it from
+	// the session rather than killing the command.
+	go func() {
+		defer detach()
+
+		for {
+			mt, r, err := conn.NextReader()
+			if err != nil || mt == websocket.CloseMessage {
+				return
+			}
+
+			// Clients send an empty text message as a barrier when their input ends, the command input is
+			// kept open for the next clients.
+			if mt == websocket.TextMessage {
+				continue
+			}
+
+			buf, err := ioutil.ReadAll(r)
+			if err != nil {
+				return
+			}
+
+			_, err = session.input.Write(buf)
+			if err != nil {
+				l.Debug("Failed writing exec session input", logger.Ctx{"err": err})
+			}
+		}
+	}()
+
+	// Handle the control messages, closing the control websocket detaches the client too.
+	go func() {
+		<-s.controlConnectedCtx.Done()
+
+		s.connsLock.Lock()
+		control := s.conns[execWSControl]
+		s.connsLock.Unlock()
+
+		if control == nil {
+			return
+		}
+
+		defer detach()
+
+		for {
+			_, buf, err := control.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			command := api.InstanceExecControl{}
+
+			err = json.Unmarshal(buf, &command)
+			if err != nil {
+				l.Debug("Failed to unmarshal control socket command", logger.Ctx{"err": err})
+				continue
+			}
+
+			if command.Command == "window-resize" {
+				winchWidth, err := strconv.Atoi(command.Args["width"])
+				if err != nil {
+					l.Debug("Unable to extract window width", logger.Ctx{"err": err})
+					continue
+				}
+
+				winchHeight, err := strconv.Atoi(command.Args["height"])
+				if err != nil {
+					l.Debug("Unable to extract window height", logger.Ctx{"err": err})
+					continue
+				}
+
+				err = session.cmd.WindowResize(session.resizeFd, winchWidth, winchHeight)
+				if err != nil {
+					l.Debug("Failed to set window size", logger.Ctx{"err": err, "width": winchWidth, "height": winchHeight})
+					continue
+				}
+			} else if command.Command == "signal" {
+				err := session.cmd.Signal(unix.Signal(command.Signal))
+				if err != nil {
+					l.Debug("Failed forwarding signal", logger.Ctx{"err": err, "signal": command.Signal})
+					continue
+				}
+			}
+		}
+	}()
+
+	select {
+	case <-session.done:
+	case <-detached:
+		l.Debug("Client detached from exec session")
+		return nil
+	}
+
+	// The command exited, send the client the barrier ending its output and the exit status. The session isn't
+	// needed anymore once its exit status was collected.
+	session.detach()
+	conn.WriteMessage(websocket.TextMessage, []byte{})
+	execSessionRemove(session)
+
+	session.mu.Lock()
+	exitCode := session.exitCode
+	session.mu.Unlock()
+
+	return op.ExtendMetadata(shared.Jmap{"return": exitCode})
+}
+
+// execSessionAttach returns a websocket operation attaching the client to the exec session.
+func execSessionAttach(s *state.State, r *http.Request, projectName string, session *execSession, req api.InstanceExecSessionPost) response.Response {
+	err := session.reserve()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	ws := &execSessionAttachWs{
+		session: session,
+		req:     req,
+		conns:   map[int]*websocket.Conn{execWSControl: nil, 0: nil},
+		fds:     map[int]string{},
+	}
+
+	ws.requiredConnectedCtx, ws.requiredConnectedDone = context.WithCancel(context.Background())
+	ws.controlConnectedCtx, ws.controlConnectedDone = context.WithCancel(context.Background())
+
+	for i := range ws.conns {
+		ws.fds[i], err = shared.RandomCryptoString()
+		if err != nil {
+			session.detach()
+			return response.InternalError(err)
+		}
+	}
+
+	resources := map[string][]string{}
+	resources["instances"] = []string{session.instance.Name()}
+
+	if session.instance.Type() == instancetype.Container {
+		resources["containers"] = resources["instances"]
+	}
+
+	op, err := operations.OperationCreate(s, projectName, operations.OperationClassWebsocket, db.OperationCommandExec, resources, ws.Metadata(), ws.Do, nil, ws.Connect, r)
+	if err != nil {
+		session.detach()
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// swagger:operation GET /1.0/instances/{name}/exec-sessions instances instance_exec_sessions_get
+//
+// Get the exec sessions
+//
+// Returns a list of detachable exec sessions (URLs).
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     description: API endpoints
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           type: array
+//           description: List of endpoints
+//           items:
+//             type: string
+//           example: |-
+//             [
+//               "/1.0/instances/foo/exec-sessions/0d9e5d1c-7d7b-4f6e-9a48-4b36dbd0a3b6"
+//             ]
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/instances/{name}/exec-sessions?recursion=1 instances instance_exec_sessions_get_recursion1
+//
+// Get the exec sessions
+//
+// Returns a list of detachable exec sessions (structs).
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     description: API endpoints
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           type: array
+//           description: List of exec sessions
+//           items:
+//             $ref: "#/definitions/InstanceExecSession"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func instanceExecSessionsGet(d *Daemon, r *http.Request) response.Response {
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := projectParam(r)
+	name := mux.Vars(r)["name"]
+
+	// Handle requests targeted to an instance on a different node.
+	resp, err := forwardedResponseIfInstanceIsRemote(d, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	recursion := util.IsRecursionRequest(r)
+
+	resultString := []string{}
+	resultMap := []*api.InstanceExecSession{}
+
+	for _, session := range execSessionList(projectName, name) {
+		if !recursion {
+			resultString = append(resultString, fmt.Sprintf("/%s/instances/%s/exec-sessions/%s", version.APIVersion, name, session.id))
+		} else {
+			resultMap = append(resultMap, session.render())
+		}
+	}
+
+	if !recursion {
+		return response.SyncResponse(true, resultString)
+	}
+
+	return response.SyncResponse(true, resultMap)
+}
+
+// swagger:operation GET /1.0/instances/{name}/exec-sessions/{session} instances instance_exec_session_get
+//
+// Get the exec session
+//
+// Gets a specific detachable exec session.
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     description: Exec session
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           $ref: "#/definitions/InstanceExecSession"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "404":
+//     $ref: "#/responses/NotFound"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func instanceExecSessionGet(d *Daemon, r *http.Request) response.Response {
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := projectParam(r)
+	name := mux.Vars(r)["name"]
+
+	// Handle requests targeted to an instance on a different node.
+	resp, err := forwardedResponseIfInstanceIsRemote(d, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	session, err := execSessionLoad(projectName, name, mux.Vars(r)["session"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, session.render())
+}
+
+// swagger:operation POST /1.0/instances/{name}/exec-sessions/{session} instances instance_exec_session_post
+//
+// Attach to the exec session
+//
+// Attaches to a detachable exec session.
+//
+// The returned operation metadata will contain 2 websockets, a bi-directional one for the terminal and the
+// "control" one, the same as for an interactive command. The output kept by the session is sent first.
+//
+// Disconnecting detaches from the session, leaving the command running. Once the command exits, the operation
+// metadata contains its exit status.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: body
+//     name: session
+//     description: Attach request
+//     schema:
+//       $ref: "#/definitions/InstanceExecSessionPost"
+// responses:
+//   "202":
+//     $ref: "#/responses/Operation"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "404":
+//     $ref: "#/responses/NotFound"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func instanceExecSessionPost(d *Daemon, r *http.Request) response.Response {
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := projectParam(r)
+	name := mux.Vars(r)["name"]
+	sessionID := mux.Vars(r)["session"]
+
+	req := api.InstanceExecSessionPost{}
+
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Forward the request if the instance is remote.
+	client, err := cluster.ConnectIfInstanceIsRemote(d.cluster, projectName, name, d.endpoints.NetworkCert(), d.serverCert(), r, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if client != nil {
+		url := fmt.Sprintf("/1.0/instances/%s/exec-sessions/%s?project=%s", name, sessionID, projectName)
+		resp, _, err := client.RawQuery("POST", url, req, "")
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		opAPI, err := resp.MetadataAsOperation()
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		return operations.ForwardedOperationResponse(projectName, opAPI)
+	}
+
+	session, err := execSessionLoad(projectName, name, sessionID)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return execSessionAttach(d.State(), r, projectName, session, req)
+}
+
+// swagger:operation DELETE /1.0/instances/{name}/exec-sessions/{session} instances instance_exec_session_delete
+//
+// Delete the exec session
+//
+// Kills the command of the detachable exec session if still running and removes the session.
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "404":
+//     $ref: "#/responses/NotFound"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func instanceExecSessionDelete(d *Daemon, r *http.Request) response.Response {
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := projectParam(r)
+	name := mux.Vars(r)["name"]
+
+	// Handle requests targeted to an instance on a different node.
+	resp, err := forwardedResponseIfInstanceIsRemote(d, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	session, err := execSessionLoad(projectName, name, mux.Vars(r)["session"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if !session.exited() {
+		err := session.cmd.Signal(unix.SIGKILL)
+		if err != nil {
+			return response.SmartError(fmt.Errorf("Failed killing exec session command: %w", err))
+		}
+	}
+
+	execSessionRemove(session)
+
+	return response.EmptySyncResponse
+}
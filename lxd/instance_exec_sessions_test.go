@@ -0,0 +1,343 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/lxd/lxd/instance"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+)
+
+func TestExecRingBuffer(t *testing.T) {
+	b := newExecRingBuffer(8)
+	assert.Equal(t, []byte{}, b.Bytes())
+
+	// Partially filled.
+	n, err := b.Write([]byte("abc"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []byte("abc"), b.Bytes())
+
+	// Filled exactly.
+	b.Write([]byte("defgh"))
+	assert.Equal(t, []byte("abcdefgh"), b.Bytes())
+
+	// Wrapping around.
+	b.Write([]byte("ijk"))
+	assert.Equal(t, []byte("defghijk"), b.Bytes())
+
+	// Write larger than the buffer.
+	n, err = b.Write([]byte("0123456789"))
+	assert.NoError(t, err)
+	assert.Equal(t, 10, n)
+	assert.Equal(t, []byte("23456789"), b.Bytes())
+
+	b.Write([]byte("x"))
+	assert.Equal(t, []byte("3456789x"), b.Bytes())
+}
+
+func TestExecSessionReserve(t *testing.T) {
+	session := execSessionTestNew(t, time.Hour)
+
+	require.NoError(t, session.reserve())
+
+	// Only one client may be attached at a time.
+	err := session.reserve()
+	assert.True(t, api.StatusErrorCheck(err, http.StatusConflict))
+	assert.True(t, session.render().Attached)
+
+	session.detach()
+	assert.False(t, session.render().Attached)
+	assert.NoError(t, session.reserve())
+}
+
+func TestExecSessionReplay(t *testing.T) {
+	session := execSessionTestNew(t, time.Hour)
+
+	// Output produced before any client attached is kept.
+	session.writeOutput([]byte("one "))
+
+	client := execSessionTestAttach(t, session)
+	assert.Equal(t, "one ", client.read(t))
+
+	// The attached client gets the output as it comes.
+	session.writeOutput([]byte("two "))
+	assert.Equal(t, "two ", client.read(t))
+
+	// The client going away detaches it, the command keeps running.
+	client.close(t)
+	assert.False(t, session.render().Attached)
+	assert.False(t, session.exited())
+
+	session.writeOutput([]byte("three"))
+
+	// The next client gets all the output kept by the session.
+	client = execSessionTestAttach(t, session)
+	assert.Equal(t, "one two three", client.read(t))
+	client.close(t)
+}
+
+func TestExecSessionWriteFailure(t *testing.T) {
+	session := execSessionTestNew(t, time.Hour)
+
+	client := execSessionTestAttach(t, session)
+	client.server.fail()
+
+	// Failing to send the output detaches the client, the output being kept for the next one.
+	session.writeOutput([]byte("lost"))
+
+	select {
+	case err := <-client.done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Client wasn't detached")
+	}
+
+	assert.False(t, session.render().Attached)
+
+	client = execSessionTestAttach(t, session)
+	assert.Equal(t, "lost", client.read(t))
+	client.close(t)
+}
+
+func TestExecSessionExit(t *testing.T) {
+	session := execSessionTestNew(t, 100*time.Millisecond)
+
+	info := session.render()
+	assert.Equal(t, "Running", info.Status)
+	assert.Equal(t, -1, info.Return)
+
+	session.exit(3)
+
+	info = session.render()
+	assert.Equal(t, "Exited", info.Status)
+	assert.Equal(t, 3, info.Return)
+	assert.True(t, session.exited())
+
+	// The exited session can still be attached to until it expires.
+	_, err := execSessionLoad("default", "c1", session.id)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		_, err := execSessionLoad("default", "c1", session.id)
+		return api.StatusErrorCheck(err, http.StatusNotFound)
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// execSessionTestInstance implements the parts of instance.Instance used by exec sessions.
+type execSessionTestInstance struct {
+	instance.Instance
+}
+
+func (inst *execSessionTestInstance) Project() string {
+	return "default"
+}
+
+func (inst *execSessionTestInstance) Name() string {
+	return "c1"
+}
+
+// execSessionTestNew returns a running exec session without a command, which is removed once the test ends.
+func execSessionTestNew(t *testing.T, expiry time.Duration) *execSession {
+	input, output, err := os.Pipe()
+	require.NoError(t, err)
+
+	session := &execSession{
+		id:        "session-" + t.Name(),
+		instance:  &execSessionTestInstance{},
+		createdAt: time.Now(),
+		input:     output,
+		resizeFd:  -1,
+		done:      make(chan struct{}),
+		expiry:    expiry,
+		output:    newExecRingBuffer(execSessionBufferSize),
+		exitCode:  -1,
+	}
+
+	execSessionsLock.Lock()
+	execSessions[session.id] = session
+	execSessionsLock.Unlock()
+
+	t.Cleanup(func() {
+		execSessionRemove(session)
+		input.Close()
+		output.Close()
+	})
+
+	return session
+}
+
+// execSessionTestClient is a client attached to an exec session through an in-memory websocket.
+type execSessionTestClient struct {
+	conn   *websocket.Conn
+	server *execSessionTestConn
+
+	// Receives the output sent to the client.
+	output chan []byte
+
+	// Receives the result of the attach operation once the client is detached.
+	done chan error
+}
+
+// execSessionTestAttach attaches a new client to the session.
+func execSessionTestAttach(t *testing.T, session *execSession) *execSessionTestClient {
+	require.NoError(t, session.reserve())
+
+	serverConn, clientConn := net.Pipe()
+	client := &execSessionTestClient{
+		server: &execSessionTestConn{Conn: serverConn, failed: make(chan struct{})},
+		output: make(chan []byte, 16),
+		done:   make(chan error, 1),
+	}
+
+	upgraded := make(chan *websocket.Conn, 1)
+	go func() {
+		defer close(upgraded)
+
+		r, err := http.ReadRequest(bufio.NewReader(client.server))
+		if err != nil {
+			return
+		}
+
+		conn, err := shared.WebsocketUpgrader.Upgrade(&execSessionTestResponseWriter{conn: client.server}, r, nil)
+		if err != nil {
+			return
+		}
+
+		upgraded <- conn
+	}()
+
+	u, err := url.Parse("ws://lxd/1.0/operations/exec/websocket")
+	require.NoError(t, err)
+
+	client.conn, _, err = websocket.NewClient(clientConn, u, nil, 1024, 1024)
+	require.NoError(t, err)
+
+	server := <-upgraded
+	require.NotNil(t, server)
+
+	t.Cleanup(func() {
+		server.Close()
+		client.conn.Close()
+	})
+
+	// Read the output as it comes as writes to the pipe block until they're read.
+	go func() {
+		defer close(client.output)
+
+		for {
+			mt, buf, err := client.conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			if mt == websocket.BinaryMessage {
+				client.output <- buf
+			}
+		}
+	}()
+
+	ws := &execSessionAttachWs{
+		session: session,
+		conns:   map[int]*websocket.Conn{execWSControl: nil, 0: server},
+	}
+
+	ws.requiredConnectedCtx, ws.requiredConnectedDone = context.WithCancel(context.Background())
+	ws.controlConnectedCtx, ws.controlConnectedDone = context.WithCancel(context.Background())
+	ws.requiredConnectedDone()
+
+	go func() { client.done <- ws.Do(nil) }()
+
+	// Wait for the session to send its output to the client.
+	require.Eventually(t, func() bool {
+		session.mu.Lock()
+		defer session.mu.Unlock()
+
+		return session.client != nil
+	}, 5*time.Second, time.Millisecond)
+
+	return client
+}
+
+// read returns the next output sent to the client.
+func (c *execSessionTestClient) read(t *testing.T) string {
+	select {
+	case buf, ok := <-c.output:
+		require.True(t, ok, "Client websocket closed")
+		return string(buf)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for exec session output")
+	}
+
+	return ""
+}
+
+// close disconnects the client and waits for it to be detached.
+func (c *execSessionTestClient) close(t *testing.T) {
+	c.conn.Close()
+
+	select {
+	case err := <-c.done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the client to be detached")
+	}
+}
+
+// execSessionTestConn is the server side of an in-memory connection whose writes can be made to fail.
+type execSessionTestConn struct {
+	net.Conn
+
+	failed chan struct{}
+}
+
+// fail makes the next writes fail, as for an unreachable client.
+func (c *execSessionTestConn) fail() {
+	close(c.failed)
+}
+
+func (c *execSessionTestConn) Write(buf []byte) (int, error) {
+	select {
+	case <-c.failed:
+		return 0, os.ErrDeadlineExceeded
+	default:
+	}
+
+	return c.Conn.Write(buf)
+}
+
+// execSessionTestResponseWriter is a http.Hijacker handing over its connection to the websocket upgrader.
+type execSessionTestResponseWriter struct {
+	conn   net.Conn
+	header http.Header
+}
+
+func (w *execSessionTestResponseWriter) Header() http.Header {
+	if w.header == nil {
+		w.header = http.Header{}
+	}
+
+	return w.header
+}
+
+func (w *execSessionTestResponseWriter) Write(buf []byte) (int, error) {
+	return w.conn.Write(buf)
+}
+
+func (w *execSessionTestResponseWriter) WriteHeader(statusCode int) {
+}
+
+func (w *execSessionTestResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.conn, bufio.NewReadWriter(bufio.NewReader(w.conn), bufio.NewWriter(w.conn)), nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lxc/lxd/lxd/instance"
+	"github.com/lxc/lxd/lxd/instance/instancetype"
+	"github.com/lxc/lxd/lxd/response"
+)
+
+// swagger:operation GET /1.0/instances/{name}/guest instances instance_guest_get
+//
+// Get the guest information
+//
+// Gets the information reported by the guest of a running virtual machine through its agent,
+// including the operating system, processes, logged-in users, filesystems and installed packages.
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+// responses:
+//   "200":
+//     description: Guest information
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           $ref: "#/definitions/InstanceGuest"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func instanceGuestGet(d *Daemon, r *http.Request) response.Response {
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := projectParam(r)
+	name := mux.Vars(r)["name"]
+
+	// Handle requests targeted to an instance on a different node.
+	resp, err := forwardedResponseIfInstanceIsRemote(d, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	inst, err := instance.LoadByProjectAndName(d.State(), projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if inst.Type() != instancetype.VM {
+		return response.BadRequest(fmt.Errorf("Guest information is only available for virtual machines"))
+	}
+
+	if !inst.IsRunning() {
+		return response.BadRequest(fmt.Errorf("The instance isn't running"))
+	}
+
+	vm, ok := inst.(instance.VM)
+	if !ok {
+		return response.InternalError(fmt.Errorf("Instance doesn't implement the virtual machine interface"))
+	}
+
+	guest, err := vm.GuestInfo()
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed getting guest information: %w", err))
+	}
+
+	return response.SyncResponse(true, guest)
+}
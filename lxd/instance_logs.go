@@ -26,8 +26,8 @@ var instanceLogCmd = APIEndpoint{
 		{Name: "vmLog", Path: "virtual-machines/{name}/logs/{file}"},
 	},
 
-	Delete: APIEndpointAction{Handler: instanceLogDelete, AccessHandler: allowProjectPermission("containers", "operate-containers")},
-	Get:    APIEndpointAction{Handler: instanceLogGet, AccessHandler: allowProjectPermission("containers", "view")},
+	Delete: APIEndpointAction{Handler: instanceLogDelete, AccessHandler: allowInstancePermission("operate-containers")},
+	Get:    APIEndpointAction{Handler: instanceLogGet, AccessHandler: allowInstancePermission("view")},
 }
 
 var instanceLogsCmd = APIEndpoint{
@@ -38,7 +38,7 @@ var instanceLogsCmd = APIEndpoint{
 		{Name: "vmLogs", Path: "virtual-machines/{name}/logs"},
 	},
 
-	Get: APIEndpointAction{Handler: instanceLogsGet, AccessHandler: allowProjectPermission("containers", "view")},
+	Get: APIEndpointAction{Handler: instanceLogsGet, AccessHandler: allowInstancePermission("view")},
 }
 
 // swagger:operation GET /1.0/instances/{name}/logs instances instance_logs_get
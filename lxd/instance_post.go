@@ -6,12 +6,15 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"os"
+	"strings"
 
 	"github.com/gorilla/mux"
 
 	"github.com/lxc/lxd/client"
 	"github.com/lxc/lxd/lxd/cluster"
 	"github.com/lxc/lxd/lxd/db"
+	deviceConfig "github.com/lxc/lxd/lxd/device/config"
 	"github.com/lxc/lxd/lxd/instance"
 	"github.com/lxc/lxd/lxd/instance/instancetype"
 	"github.com/lxc/lxd/lxd/migration"
@@ -19,9 +22,13 @@ import (
 	"github.com/lxc/lxd/lxd/project"
 	"github.com/lxc/lxd/lxd/rbac"
 	"github.com/lxc/lxd/lxd/response"
+	"github.com/lxc/lxd/lxd/revert"
+	"github.com/lxc/lxd/lxd/state"
 	storagePools "github.com/lxc/lxd/lxd/storage"
+	storageDrivers "github.com/lxc/lxd/lxd/storage/drivers"
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/version"
 )
 
 var internalClusterInstanceMovedCmd = APIEndpoint{
@@ -30,6 +37,12 @@ var internalClusterInstanceMovedCmd = APIEndpoint{
 	Post: APIEndpointAction{Handler: internalClusterInstanceMovedPost},
 }
 
+var internalClusterInstanceLiveMigrateCmd = APIEndpoint{
+	Path: "cluster/instance-live-migrate/{name}",
+
+	Post: APIEndpointAction{Handler: internalClusterInstanceLiveMigratePost},
+}
+
 // swagger:operation POST /1.0/instances/{name} instances instance_post
 //
 // Rename or move/migrate an instance
@@ -363,6 +376,11 @@ func instancePostPoolMigration(d *Daemon, inst instance.Instance, newName string
 		return fmt.Errorf("Instance snapshots cannot be moved between pools")
 	}
 
+	// Running VMs keep running while their root disk is mirrored to the new pool.
+	if inst.Type() == instancetype.VM && inst.IsRunning() && stateful && newName == inst.Name() {
+		return instancePostPoolMigrateLive(d, inst, instanceOnly, newPool, op)
+	}
+
 	statefulStart := false
 	if inst.IsRunning() {
 		if stateful {
@@ -451,6 +469,169 @@ func instancePostPoolMigration(d *Daemon, inst instance.Instance, newName string
 	return nil
 }
 
+// Move a running VM to another pool by mirroring its root disk to a copy of its volume on the new pool.
+// The volume left behind on the source pool is removed once the VM is stopped, as its config volume is in use.
+func instancePostPoolMigrateLive(d *Daemon, inst instance.Instance, instanceOnly bool, newPool string, op *operations.Operation) error {
+	s := d.State()
+
+	vm, ok := inst.(instance.VM)
+	if !ok {
+		return fmt.Errorf("Instance is not a VM")
+	}
+
+	if inst.LocalConfig()["volatile.move.source_pool"] != "" {
+		return api.StatusErrorf(http.StatusConflict, "The instance must be stopped before being moved again to remove its volume from storage pool %q", inst.LocalConfig()["volatile.move.source_pool"])
+	}
+
+	srcPool, err := storagePools.LoadByInstance(s, inst)
+	if err != nil {
+		return err
+	}
+
+	targetPool, err := storagePools.LoadByName(s, newPool)
+	if err != nil {
+		return err
+	}
+
+	if srcPool.Name() == targetPool.Name() {
+		return api.StatusErrorf(http.StatusBadRequest, "The instance is already on storage pool %q", newPool)
+	}
+
+	snapshots, err := inst.Snapshots()
+	if err != nil {
+		return err
+	}
+
+	// Load source root disk from expanded devices (in case instance doesn't have its own root disk).
+	rootDevKey, rootDev, err := shared.GetRootDiskDevice(inst.ExpandedDevices().CloneNative())
+	if err != nil {
+		return err
+	}
+
+	// Copy device config from instance, and update the root disk device with the new pool name.
+	localDevices := inst.LocalDevices().Clone()
+	rootDev["pool"] = newPool
+	localDevices[rootDevKey] = rootDev
+
+	// Load the instance as it will be once moved to the new pool.
+	var dbInst *db.Instance
+	err = s.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		dbInst, err = instance.LoadInstanceDatabaseObject(tx, inst.Project(), inst.Name())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	args := db.InstanceToArgs(dbInst)
+	args.Devices = localDevices
+
+	targetInst, err := instance.Load(s, args, nil)
+	if err != nil {
+		return err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Copying the volume points the instance symlinks to the new pool.
+	revert.Add(func() { srcPool.EnsureInstanceSymlinks(inst) })
+
+	// Copy the volume to the new pool. The copy of the root disk is inconsistent as the VM keeps writing to it,
+	// which is addressed by mirroring the root disk to it below.
+	err = targetPool.CreateInstanceFromCopy(targetInst, inst, !instanceOnly, true, op)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		if !instanceOnly {
+			for _, snap := range snapshots {
+				targetPool.DeleteInstanceSnapshot(snap, op)
+			}
+		}
+
+		targetPool.DeleteInstance(targetInst, op)
+	})
+
+	// The target volume remains mounted until the VM is stopped.
+	mountInfo, err := targetPool.MountInstance(targetInst, op)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() { targetPool.UnmountInstance(targetInst, op) })
+
+	err = instanceMoveDiskLive(vm, rootDevKey, targetPool, mountInfo.DiskPath, op)
+	if err != nil {
+		return err
+	}
+
+	// The VM is now using the new pool, so there's no going back.
+	revert.Success()
+
+	if instanceOnly {
+		for _, snap := range snapshots {
+			err = snap.Delete(true)
+			if err != nil {
+				return err
+			}
+		}
+
+		snapshots = nil
+	}
+
+	// Record the new pool of the root disk and the pool the instance volume is left on until the VM is stopped.
+	err = s.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		devices, err := db.APIToDevices(localDevices.CloneNative())
+		if err != nil {
+			return err
+		}
+
+		err = tx.UpdateDevice("instance", inst.ID(), devices)
+		if err != nil {
+			return err
+		}
+
+		for _, snap := range snapshots {
+			devices, err := db.APIToDevices(snapshotLocalDevicesWithPool(snap, rootDevKey, newPool).CloneNative())
+			if err != nil {
+				return err
+			}
+
+			err = tx.UpdateDevice("instance_snapshot", snap.ID(), devices)
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.UpdateInstanceConfig(inst.ID(), map[string]string{"volatile.move.source_pool": srcPool.Name()})
+	})
+	if err != nil {
+		return fmt.Errorf("Failed updating instance storage pool: %w", err)
+	}
+
+	// Snapshots aren't in use, so their volumes can be removed from the source pool straight away.
+	for _, snap := range snapshots {
+		err = srcPool.DeleteInstanceSnapshot(snap, op)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = targetPool.EnsureInstanceSymlinks(targetInst)
+	if err != nil {
+		return err
+	}
+
+	inst, err = instance.LoadByProjectAndName(s, inst.Project(), inst.Name())
+	if err != nil {
+		return err
+	}
+
+	return inst.UpdateBackupFile()
+}
+
 // Move an instance to another project.
 func instancePostProjectMigration(d *Daemon, inst instance.Instance, newName string, newProject string, instanceOnly bool, stateful bool, op *operations.Operation) error {
 	localConfig := inst.LocalConfig()
@@ -711,10 +892,10 @@ func instancePostClusteringMigrate(d *Daemon, r *http.Request, inst instance.Ins
 	return run, nil
 }
 
-// Special case migrating a container backed response.Responseby ceph across two cluster nodes.
-func instancePostClusteringMigrateWithCeph(d *Daemon, r *http.Request, inst instance.Instance, pool storagePools.Pool, newName string, sourceNodeOffline bool, newNode string, stateful bool) (func(op *operations.Operation) error, error) {
-	if pool.Driver().Info().Name != "ceph" {
-		return nil, fmt.Errorf("Source instance's storage pool is not of type ceph")
+// Special case migrating an instance backed by remote storage (ceph or lvmcluster) across two cluster nodes.
+func instancePostClusteringMigrateWithRemoteStorage(d *Daemon, r *http.Request, inst instance.Instance, pool storagePools.Pool, newName string, sourceNodeOffline bool, newNode string, stateful bool) (func(op *operations.Operation) error, error) {
+	if !pool.Driver().Info().Remote {
+		return nil, fmt.Errorf("Source instance's storage pool is not remote")
 	}
 
 	var err error
@@ -784,13 +965,13 @@ func instancePostClusteringMigrateWithCeph(d *Daemon, r *http.Request, inst inst
 			return err
 		}
 
-		// Trigger a rename in the Ceph driver.
+		// Trigger a rename in the storage driver.
 		args := migration.VolumeSourceArgs{
 			Data: project.Instance(inst.Project(), newName), // Indicate new storage volume name.
 		}
 		err = pool.MigrateInstance(inst, nil, &args, op)
 		if err != nil {
-			return fmt.Errorf("Failed to migrate ceph RBD volume: %w", err)
+			return fmt.Errorf("Failed to migrate remote storage volume: %w", err)
 		}
 
 		// Re-link the database entries against the new node name.
@@ -870,9 +1051,218 @@ func instancePostClusteringMigrateWithCeph(d *Daemon, r *http.Request, inst inst
 	return run, nil
 }
 
+// Live migrate a running VM to another cluster member.
+//
+// Unlike the other cluster moves, the instance record is kept and only its volumes are transferred, allowing the
+// VM to be started on the target from the migration stream before the source is stopped.
+func instancePostClusteringMigrateLive(d *Daemon, r *http.Request, inst instance.Instance, pool storagePools.Pool, newNode string) (func(op *operations.Operation) error, error) {
+	var targetAddress string
+
+	err := d.cluster.Transaction(func(tx *db.ClusterTx) error {
+		node, err := tx.GetNodeByName(newNode)
+		if err != nil {
+			return fmt.Errorf("Failed to get new node address: %w", err)
+		}
+
+		targetAddress = node.Address
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	run := func(op *operations.Operation) error {
+		// Connect to the destination host, i.e. the node to migrate the instance to.
+		target, err := cluster.Connect(targetAddress, d.endpoints.NetworkCert(), d.serverCert(), r, true)
+		if err != nil {
+			return fmt.Errorf("Failed to connect to destination server %q: %w", targetAddress, err)
+		}
+		target = target.UseProject(inst.Project())
+
+		// Setup the migration sink on the target member.
+		url := api.NewURL().Project(inst.Project()).Path("internal", "cluster", "instance-live-migrate", inst.Name())
+		targetOp, _, err := target.RawOperation("POST", url.String(), nil, "")
+		if err != nil {
+			return fmt.Errorf("Failed preparing target member: %w", err)
+		}
+
+		websockets := map[string]string{}
+		for name, secret := range targetOp.Get().Metadata {
+			value, ok := secret.(string)
+			if ok {
+				websockets[name] = value
+			}
+		}
+
+		ws, err := newMigrationSource(inst, true, false)
+		if err != nil {
+			return err
+		}
+
+		ws.clusterMove = true
+
+		targetOpURL := fmt.Sprintf("https://%s/%s/operations/%s", targetAddress, version.APIVersion, targetOp.Get().ID)
+		err = ws.ConnectTarget(string(d.endpoints.NetworkPublicKey()), targetOpURL, websockets)
+		if err != nil {
+			return fmt.Errorf("Failed connecting to target member: %w", err)
+		}
+
+		err = ws.Do(d.State(), op)
+		if err != nil {
+			return fmt.Errorf("Failed live migrating instance %q: %w", inst.Name(), err)
+		}
+
+		err = targetOp.Wait()
+		if err != nil {
+			return fmt.Errorf("Failed receiving instance %q on target member: %w", inst.Name(), err)
+		}
+
+		// Keep the volatile keys set by the instance started on the target, as stopping the instance on the
+		// source clears some of them.
+		targetInst, err := instance.LoadByProjectAndName(d.State(), inst.Project(), inst.Name())
+		if err != nil {
+			return err
+		}
+
+		volatileConfig := map[string]string{}
+		for k, v := range targetInst.LocalConfig() {
+			if strings.HasPrefix(k, shared.ConfigVolatilePrefix) {
+				volatileConfig[k] = v
+			}
+		}
+
+		// The migrated instance is paused on the source.
+		err = inst.Stop(false)
+		if err != nil {
+			return fmt.Errorf("Failed stopping instance %q on source member: %w", inst.Name(), err)
+		}
+
+		stoppedInst, err := instance.LoadByProjectAndName(d.State(), inst.Project(), inst.Name())
+		if err != nil {
+			return err
+		}
+
+		changes := map[string]string{}
+		for k := range stoppedInst.LocalConfig() {
+			if strings.HasPrefix(k, shared.ConfigVolatilePrefix) {
+				changes[k] = ""
+			}
+		}
+
+		for k, v := range volatileConfig {
+			changes[k] = v
+		}
+
+		// Re-link the instance record against the new node and restore its volatile keys.
+		err = d.cluster.Transaction(func(tx *db.ClusterTx) error {
+			err := tx.UpdateInstanceLocation(inst.Project(), inst.Name(), newNode)
+			if err != nil {
+				return fmt.Errorf("Failed updating cluster member to %q for instance %q: %w", newNode, inst.Name(), err)
+			}
+
+			return tx.UpdateInstanceConfig(inst.ID(), changes)
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to relink instance database data: %w", err)
+		}
+
+		// Remove the volumes left on the source member.
+		if !pool.Driver().Info().LiveMigrationShared {
+			err = instanceDeleteLocalVolumes(d.State(), inst, op)
+			if err != nil {
+				return fmt.Errorf("Failed deleting instance %q volumes on source member: %w", inst.Name(), err)
+			}
+		}
+
+		return nil
+	}
+
+	return run, nil
+}
+
+// Receive a running VM being live migrated from another cluster member.
+func internalClusterInstanceLiveMigratePost(d *Daemon, r *http.Request) response.Response {
+	projectName := projectParam(r)
+	instanceName := mux.Vars(r)["name"]
+
+	inst, err := instance.LoadByProjectAndName(d.State(), projectName, instanceName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading instance on target node: %w", err))
+	}
+
+	if inst.Type() != instancetype.VM {
+		return response.BadRequest(fmt.Errorf("Only virtual machines can be live migrated between cluster members"))
+	}
+
+	sink, err := newMigrationSink(&MigrationSinkArgs{
+		Instance:    inst,
+		Push:        true,
+		Live:        true,
+		ClusterMove: true,
+	})
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	run := func(op *operations.Operation) error {
+		revert := revert.New()
+		defer revert.Fail()
+
+		err := sink.Do(d.State(), revert, op)
+		if err != nil {
+			return fmt.Errorf("Error transferring instance data: %w", err)
+		}
+
+		revert.Success()
+		return nil
+	}
+
+	resources := map[string][]string{}
+	resources["instances"] = []string{instanceName}
+
+	op, err := operations.OperationCreate(d.State(), projectName, operations.OperationClassWebsocket, db.OperationInstanceMigrate, resources, sink.Metadata(), run, nil, sink.Connect, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// Delete the volumes of an instance and its snapshots on the local member, keeping the database records of the
+// instance and its snapshots. Used to cleanup after live migrating an instance between cluster members.
+func instanceDeleteLocalVolumes(s *state.State, inst instance.Instance, op *operations.Operation) error {
+	pool, err := storagePools.LoadByInstance(s, inst)
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := inst.Snapshots()
+	if err != nil {
+		return err
+	}
+
+	for _, snap := range snapshots {
+		err = pool.DeleteInstanceSnapshot(snap, op)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = pool.DeleteInstance(inst, op)
+	if err != nil {
+		return err
+	}
+
+	os.RemoveAll(inst.LogPath())
+	os.RemoveAll(inst.DevicesPath())
+
+	return nil
+}
+
 // Notification that an instance was moved.
 //
-// At the moment it's used for ceph-based instances, where the target node needs
+// At the moment it's used for instances on remote storage, where the target node needs
 // to create the appropriate mount points.
 func internalClusterInstanceMovedPost(d *Daemon, r *http.Request) response.Response {
 	projectName := projectParam(r)
@@ -906,19 +1296,124 @@ func instancePostCreateInstanceMountPoint(d *Daemon, inst instance.Instance) err
 	return nil
 }
 
+// instanceClusterMoveIsLive returns whether moving an instance to another cluster member should be done as a
+// live migration. Running VMs are live migrated if requested, provided their root volume can be transferred
+// or used by the target while the VM keeps running.
+func instanceClusterMoveIsLive(inst instance.Instance, req api.InstancePost, poolInfo storageDrivers.Info, sourceNodeOffline bool) bool {
+	if inst.Type() != instancetype.VM {
+		return false
+	}
+
+	if !req.Live {
+		return false
+	}
+
+	if !inst.IsRunning() {
+		return false
+	}
+
+	if sourceNodeOffline {
+		return false
+	}
+
+	if inst.IsEphemeral() {
+		return false
+	}
+
+	if !shared.IsTrue(inst.ExpandedConfig()["migration.stateful"]) {
+		return false
+	}
+
+	// Renaming isn't supported as the instance record is kept.
+	if req.Name != "" && req.Name != inst.Name() {
+		return false
+	}
+
+	if poolInfo.Remote && !poolInfo.LiveMigrationShared {
+		return false
+	}
+
+	return true
+}
+
+// instanceLiveMigrateCheckDisks checks that the disks of an instance being live migrated to another cluster
+// member are available on the target. Only the root disk is transferred, so custom volumes on local pools
+// can't be attached.
+func instanceLiveMigrateCheckDisks(s *state.State, inst instance.Instance) error {
+	remotePools := map[string]bool{}
+
+	for _, dev := range inst.ExpandedDevices() {
+		if dev["type"] != "disk" || dev["pool"] == "" || shared.IsRootDiskDevice(dev) {
+			continue
+		}
+
+		_, ok := remotePools[dev["pool"]]
+		if ok {
+			continue
+		}
+
+		pool, err := storagePools.LoadByName(s, dev["pool"])
+		if err != nil {
+			return fmt.Errorf("Failed loading storage pool %q: %w", dev["pool"], err)
+		}
+
+		remotePools[dev["pool"]] = pool.Driver().Info().Remote
+	}
+
+	return liveMigrateCheckDisks(inst.ExpandedDevices(), remotePools)
+}
+
+// liveMigrateCheckDisks returns an error if any of the devices is a custom volume disk on a pool which isn't
+// listed as remote in remotePools.
+func liveMigrateCheckDisks(devices deviceConfig.Devices, remotePools map[string]bool) error {
+	localDisks := []string{}
+
+	for _, entry := range devices.Sorted() {
+		dev := entry.Config
+		if dev["type"] != "disk" || dev["pool"] == "" || shared.IsRootDiskDevice(dev) {
+			continue
+		}
+
+		if !remotePools[dev["pool"]] {
+			localDisks = append(localDisks, entry.Name)
+		}
+	}
+
+	if len(localDisks) > 0 {
+		return api.StatusErrorf(http.StatusBadRequest, "Live migration isn't supported with custom volumes on local storage pools attached (%s)", strings.Join(localDisks, ", "))
+	}
+
+	return nil
+}
+
 func migrateInstance(d *Daemon, r *http.Request, inst instance.Instance, targetNode string, sourceNodeOffline bool, req api.InstancePost, op *operations.Operation) error {
 	// If target isn't the same as the instance's location.
 	if targetNode == inst.Location() {
 		return fmt.Errorf("Target must be different than instance's current location")
 	}
 
-	// Check if we are migrating a ceph-based instance.
+	// Check if we are migrating an instance on remote storage.
 	pool, err := storagePools.LoadByInstance(d.State(), inst)
 	if err != nil {
 		return fmt.Errorf("Failed loading instance storage pool: %w", err)
 	}
-	if pool.Driver().Info().Name == "ceph" {
-		f, err := instancePostClusteringMigrateWithCeph(d, r, inst, pool, req.Name, sourceNodeOffline, targetNode, req.Live)
+
+	if instanceClusterMoveIsLive(inst, req, pool.Driver().Info(), sourceNodeOffline) {
+		err = instanceLiveMigrateCheckDisks(d.State(), inst)
+		if err != nil {
+			return err
+		}
+
+		f, err := instancePostClusteringMigrateLive(d, r, inst, pool, targetNode)
+		if err != nil {
+			return err
+		}
+
+		return f(op)
+	}
+
+	if pool.Driver().Info().Remote {
+		f, err := instancePostClusteringMigrateWithRemoteStorage(d, r, inst, pool, req.Name, sourceNodeOffline, targetNode, req.Live)
 		if err != nil {
 			return err
 		}
@@ -926,8 +1421,8 @@ func migrateInstance(d *Daemon, r *http.Request, inst instance.Instance, targetN
 		return f(op)
 	}
 
-	// If this is not a ceph-based instance, make sure that the source node is online, and we didn't get here
-	// only to handle the case where the instance is ceph-based.
+	// If this is not an instance on remote storage, make sure that the source node is online, and we didn't get
+	// here only to handle the case where the instance is on remote storage.
 	if sourceNodeOffline {
 		err := fmt.Errorf("The cluster member hosting the instance is offline")
 		return err
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	deviceConfig "github.com/lxc/lxd/lxd/device/config"
+	"github.com/lxc/lxd/lxd/instance"
+	"github.com/lxc/lxd/lxd/instance/instancetype"
+	storageDrivers "github.com/lxc/lxd/lxd/storage/drivers"
+	"github.com/lxc/lxd/shared/api"
+)
+
+// liveMigrateTestInstance implements the parts of instance.Instance used to decide on live migrations.
+type liveMigrateTestInstance struct {
+	instance.Instance
+
+	instType  instancetype.Type
+	name      string
+	running   bool
+	ephemeral bool
+	config    map[string]string
+}
+
+func (inst *liveMigrateTestInstance) Type() instancetype.Type {
+	return inst.instType
+}
+
+func (inst *liveMigrateTestInstance) Name() string {
+	return inst.name
+}
+
+func (inst *liveMigrateTestInstance) IsRunning() bool {
+	return inst.running
+}
+
+func (inst *liveMigrateTestInstance) IsEphemeral() bool {
+	return inst.ephemeral
+}
+
+func (inst *liveMigrateTestInstance) ExpandedConfig() map[string]string {
+	return inst.config
+}
+
+func TestInstanceClusterMoveIsLive(t *testing.T) {
+	newInstance := func() *liveMigrateTestInstance {
+		return &liveMigrateTestInstance{
+			instType: instancetype.VM,
+			name:     "v1",
+			running:  true,
+			config:   map[string]string{"migration.stateful": "true"},
+		}
+	}
+
+	tests := []struct {
+		name              string
+		modify            func(inst *liveMigrateTestInstance, req *api.InstancePost, poolInfo *storageDrivers.Info)
+		sourceNodeOffline bool
+		expected          bool
+	}{
+		{
+			name:     "running stateful VM",
+			modify:   func(inst *liveMigrateTestInstance, req *api.InstancePost, poolInfo *storageDrivers.Info) {},
+			expected: true,
+		},
+		{
+			name: "same name",
+			modify: func(inst *liveMigrateTestInstance, req *api.InstancePost, poolInfo *storageDrivers.Info) {
+				req.Name = "v1"
+			},
+			expected: true,
+		},
+		{
+			name: "shared remote pool",
+			modify: func(inst *liveMigrateTestInstance, req *api.InstancePost, poolInfo *storageDrivers.Info) {
+				poolInfo.Remote = true
+				poolInfo.LiveMigrationShared = true
+			},
+			expected: true,
+		},
+		{
+			name: "container",
+			modify: func(inst *liveMigrateTestInstance, req *api.InstancePost, poolInfo *storageDrivers.Info) {
+				inst.instType = instancetype.Container
+			},
+		},
+		{
+			name: "not requested",
+			modify: func(inst *liveMigrateTestInstance, req *api.InstancePost, poolInfo *storageDrivers.Info) {
+				req.Live = false
+			},
+		},
+		{
+			name: "stopped",
+			modify: func(inst *liveMigrateTestInstance, req *api.InstancePost, poolInfo *storageDrivers.Info) {
+				inst.running = false
+			},
+		},
+		{
+			name:              "source offline",
+			modify:            func(inst *liveMigrateTestInstance, req *api.InstancePost, poolInfo *storageDrivers.Info) {},
+			sourceNodeOffline: true,
+		},
+		{
+			name: "ephemeral",
+			modify: func(inst *liveMigrateTestInstance, req *api.InstancePost, poolInfo *storageDrivers.Info) {
+				inst.ephemeral = true
+			},
+		},
+		{
+			name: "not stateful",
+			modify: func(inst *liveMigrateTestInstance, req *api.InstancePost, poolInfo *storageDrivers.Info) {
+				inst.config = map[string]string{}
+			},
+		},
+		{
+			name: "rename",
+			modify: func(inst *liveMigrateTestInstance, req *api.InstancePost, poolInfo *storageDrivers.Info) {
+				req.Name = "v2"
+			},
+		},
+		{
+			name: "unshared remote pool",
+			modify: func(inst *liveMigrateTestInstance, req *api.InstancePost, poolInfo *storageDrivers.Info) {
+				poolInfo.Remote = true
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inst := newInstance()
+			req := api.InstancePost{Live: true}
+			poolInfo := storageDrivers.Info{}
+
+			test.modify(inst, &req, &poolInfo)
+
+			assert.Equal(t, test.expected, instanceClusterMoveIsLive(inst, req, poolInfo, test.sourceNodeOffline))
+		})
+	}
+}
+
+func TestLiveMigrateCheckDisks(t *testing.T) {
+	remotePools := map[string]bool{
+		"local":  false,
+		"remote": true,
+	}
+
+	root := deviceConfig.Device{"type": "disk", "path": "/", "pool": "local"}
+
+	tests := []struct {
+		name    string
+		devices deviceConfig.Devices
+		err     string
+	}{
+		{
+			name:    "root disk only",
+			devices: deviceConfig.Devices{"root": root},
+		},
+		{
+			name: "host path and NIC",
+			devices: deviceConfig.Devices{
+				"root": root,
+				"data": {"type": "disk", "path": "/mnt", "source": "/srv/data"},
+				"eth0": {"type": "nic", "network": "lxdbr0"},
+			},
+		},
+		{
+			name: "custom volume on remote pool",
+			devices: deviceConfig.Devices{
+				"root": root,
+				"data": {"type": "disk", "pool": "remote", "source": "vol1"},
+			},
+		},
+		{
+			name: "custom volumes on local pool",
+			devices: deviceConfig.Devices{
+				"root":  root,
+				"data2": {"type": "disk", "pool": "local", "source": "vol2", "path": "/mnt"},
+				"data1": {"type": "disk", "pool": "local", "source": "vol1"},
+				"data3": {"type": "disk", "pool": "remote", "source": "vol3"},
+			},
+			err: "Live migration isn't supported with custom volumes on local storage pools attached (data1, data2)",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := liveMigrateCheckDisks(test.devices, remotePools)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
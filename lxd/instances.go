@@ -31,7 +31,7 @@ var instancesCmd = APIEndpoint{
 		{Name: "vms", Path: "virtual-machines"},
 	},
 
-	Get:  APIEndpointAction{Handler: instancesGet, AccessHandler: allowProjectPermission("containers", "view")},
+	Get:  APIEndpointAction{Handler: instancesGet, AccessHandler: allowInstancePermission("view")},
 	Post: APIEndpointAction{Handler: instancesPost, AccessHandler: allowProjectPermission("containers", "manage-containers")},
 	Put:  APIEndpointAction{Handler: instancesPut, AccessHandler: allowProjectPermission("containers", "operate-containers")},
 }
@@ -44,11 +44,11 @@ var instanceCmd = APIEndpoint{
 		{Name: "vm", Path: "virtual-machines/{name}"},
 	},
 
-	Get:    APIEndpointAction{Handler: instanceGet, AccessHandler: allowProjectPermission("containers", "view")},
-	Put:    APIEndpointAction{Handler: instancePut, AccessHandler: allowProjectPermission("containers", "manage-containers")},
-	Delete: APIEndpointAction{Handler: instanceDelete, AccessHandler: allowProjectPermission("containers", "manage-containers")},
-	Post:   APIEndpointAction{Handler: instancePost, AccessHandler: allowProjectPermission("containers", "manage-containers")},
-	Patch:  APIEndpointAction{Handler: instancePatch, AccessHandler: allowProjectPermission("containers", "manage-containers")},
+	Get:    APIEndpointAction{Handler: instanceGet, AccessHandler: allowInstancePermission("view")},
+	Put:    APIEndpointAction{Handler: instancePut, AccessHandler: allowInstancePermission("manage-containers")},
+	Delete: APIEndpointAction{Handler: instanceDelete, AccessHandler: allowInstancePermission("manage-containers")},
+	Post:   APIEndpointAction{Handler: instancePost, AccessHandler: allowInstancePermission("manage-containers")},
+	Patch:  APIEndpointAction{Handler: instancePatch, AccessHandler: allowInstancePermission("manage-containers")},
 }
 
 var instanceStateCmd = APIEndpoint{
@@ -59,8 +59,18 @@ var instanceStateCmd = APIEndpoint{
 		{Name: "vmState", Path: "virtual-machines/{name}/state"},
 	},
 
-	Get: APIEndpointAction{Handler: instanceState, AccessHandler: allowProjectPermission("containers", "view")},
-	Put: APIEndpointAction{Handler: instanceStatePut, AccessHandler: allowProjectPermission("containers", "operate-containers")},
+	Get: APIEndpointAction{Handler: instanceState, AccessHandler: allowInstancePermission("view")},
+	Put: APIEndpointAction{Handler: instanceStatePut, AccessHandler: allowInstancePermission("operate-containers")},
+}
+
+var instanceGuestCmd = APIEndpoint{
+	Name: "instanceGuest",
+	Path: "instances/{name}/guest",
+	Aliases: []APIEndpointAlias{
+		{Name: "vmGuest", Path: "virtual-machines/{name}/guest"},
+	},
+
+	Get: APIEndpointAction{Handler: instanceGuestGet, AccessHandler: allowInstancePermission("view")},
 }
 
 var instanceSFTPCmd = APIEndpoint{
@@ -71,7 +81,7 @@ var instanceSFTPCmd = APIEndpoint{
 		{Name: "vmFile", Path: "virtual-machines/{name}/files"},
 	},
 
-	Get: APIEndpointAction{Handler: instanceSFTPHandler, AccessHandler: allowProjectPermission("containers", "operate-containers")},
+	Get: APIEndpointAction{Handler: instanceSFTPHandler, AccessHandler: allowInstancePermission("operate-containers")},
 }
 
 var instanceFileCmd = APIEndpoint{
@@ -82,9 +92,9 @@ var instanceFileCmd = APIEndpoint{
 		{Name: "vmFile", Path: "virtual-machines/{name}/files"},
 	},
 
-	Get:    APIEndpointAction{Handler: instanceFileHandler, AccessHandler: allowProjectPermission("containers", "operate-containers")},
-	Post:   APIEndpointAction{Handler: instanceFileHandler, AccessHandler: allowProjectPermission("containers", "operate-containers")},
-	Delete: APIEndpointAction{Handler: instanceFileHandler, AccessHandler: allowProjectPermission("containers", "operate-containers")},
+	Get:    APIEndpointAction{Handler: instanceFileHandler, AccessHandler: allowInstancePermission("operate-containers")},
+	Post:   APIEndpointAction{Handler: instanceFileHandler, AccessHandler: allowInstancePermission("operate-containers")},
+	Delete: APIEndpointAction{Handler: instanceFileHandler, AccessHandler: allowInstancePermission("operate-containers")},
 }
 
 var instanceSnapshotsCmd = APIEndpoint{
@@ -95,8 +105,8 @@ var instanceSnapshotsCmd = APIEndpoint{
 		{Name: "vmSnapshots", Path: "virtual-machines/{name}/snapshots"},
 	},
 
-	Get:  APIEndpointAction{Handler: instanceSnapshotsGet, AccessHandler: allowProjectPermission("containers", "view")},
-	Post: APIEndpointAction{Handler: instanceSnapshotsPost, AccessHandler: allowProjectPermission("containers", "operate-containers")},
+	Get:  APIEndpointAction{Handler: instanceSnapshotsGet, AccessHandler: allowInstancePermission("view")},
+	Post: APIEndpointAction{Handler: instanceSnapshotsPost, AccessHandler: allowInstancePermission("operate-containers", "manage-snapshots")},
 }
 
 var instanceSnapshotCmd = APIEndpoint{
@@ -107,11 +117,11 @@ var instanceSnapshotCmd = APIEndpoint{
 		{Name: "vmSnapshot", Path: "virtual-machines/{name}/snapshots/{snapshotName}"},
 	},
 
-	Get:    APIEndpointAction{Handler: instanceSnapshotHandler, AccessHandler: allowProjectPermission("containers", "operate-containers")},
-	Post:   APIEndpointAction{Handler: instanceSnapshotHandler, AccessHandler: allowProjectPermission("containers", "operate-containers")},
-	Delete: APIEndpointAction{Handler: instanceSnapshotHandler, AccessHandler: allowProjectPermission("containers", "operate-containers")},
-	Patch:  APIEndpointAction{Handler: instanceSnapshotHandler, AccessHandler: allowProjectPermission("containers", "operate-containers")},
-	Put:    APIEndpointAction{Handler: instanceSnapshotHandler, AccessHandler: allowProjectPermission("containers", "operate-containers")},
+	Get:    APIEndpointAction{Handler: instanceSnapshotHandler, AccessHandler: allowInstancePermission("operate-containers", "manage-snapshots")},
+	Post:   APIEndpointAction{Handler: instanceSnapshotHandler, AccessHandler: allowInstancePermission("operate-containers", "manage-snapshots")},
+	Delete: APIEndpointAction{Handler: instanceSnapshotHandler, AccessHandler: allowInstancePermission("operate-containers", "manage-snapshots")},
+	Patch:  APIEndpointAction{Handler: instanceSnapshotHandler, AccessHandler: allowInstancePermission("operate-containers", "manage-snapshots")},
+	Put:    APIEndpointAction{Handler: instanceSnapshotHandler, AccessHandler: allowInstancePermission("operate-containers", "manage-snapshots")},
 }
 
 var instanceConsoleCmd = APIEndpoint{
@@ -122,9 +132,9 @@ var instanceConsoleCmd = APIEndpoint{
 		{Name: "vmConsole", Path: "virtual-machines/{name}/console"},
 	},
 
-	Get:    APIEndpointAction{Handler: instanceConsoleLogGet, AccessHandler: allowProjectPermission("containers", "view")},
-	Post:   APIEndpointAction{Handler: instanceConsolePost, AccessHandler: allowProjectPermission("containers", "operate-containers")},
-	Delete: APIEndpointAction{Handler: instanceConsoleLogDelete, AccessHandler: allowProjectPermission("containers", "operate-containers")},
+	Get:    APIEndpointAction{Handler: instanceConsoleLogGet, AccessHandler: allowInstancePermission("view")},
+	Post:   APIEndpointAction{Handler: instanceConsolePost, AccessHandler: allowInstancePermission("operate-containers")},
+	Delete: APIEndpointAction{Handler: instanceConsoleLogDelete, AccessHandler: allowInstancePermission("operate-containers")},
 }
 
 var instanceExecCmd = APIEndpoint{
@@ -135,7 +145,31 @@ var instanceExecCmd = APIEndpoint{
 		{Name: "vmExec", Path: "virtual-machines/{name}/exec"},
 	},
 
-	Post: APIEndpointAction{Handler: instanceExecPost, AccessHandler: allowProjectPermission("containers", "operate-containers")},
+	Post: APIEndpointAction{Handler: instanceExecPost, AccessHandler: allowInstancePermission("operate-containers")},
+}
+
+var instanceExecSessionsCmd = APIEndpoint{
+	Name: "instanceExecSessions",
+	Path: "instances/{name}/exec-sessions",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerExecSessions", Path: "containers/{name}/exec-sessions"},
+		{Name: "vmExecSessions", Path: "virtual-machines/{name}/exec-sessions"},
+	},
+
+	Get: APIEndpointAction{Handler: instanceExecSessionsGet, AccessHandler: allowInstancePermission("view")},
+}
+
+var instanceExecSessionCmd = APIEndpoint{
+	Name: "instanceExecSession",
+	Path: "instances/{name}/exec-sessions/{session}",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerExecSession", Path: "containers/{name}/exec-sessions/{session}"},
+		{Name: "vmExecSession", Path: "virtual-machines/{name}/exec-sessions/{session}"},
+	},
+
+	Get:    APIEndpointAction{Handler: instanceExecSessionGet, AccessHandler: allowInstancePermission("view")},
+	Post:   APIEndpointAction{Handler: instanceExecSessionPost, AccessHandler: allowInstancePermission("operate-containers")},
+	Delete: APIEndpointAction{Handler: instanceExecSessionDelete, AccessHandler: allowInstancePermission("operate-containers")},
 }
 
 var instanceMetadataCmd = APIEndpoint{
@@ -146,9 +180,9 @@ var instanceMetadataCmd = APIEndpoint{
 		{Name: "vmMetadata", Path: "virtual-machines/{name}/metadata"},
 	},
 
-	Get:   APIEndpointAction{Handler: instanceMetadataGet, AccessHandler: allowProjectPermission("containers", "view")},
-	Patch: APIEndpointAction{Handler: instanceMetadataPatch, AccessHandler: allowProjectPermission("containers", "manage-containers")},
-	Put:   APIEndpointAction{Handler: instanceMetadataPut, AccessHandler: allowProjectPermission("containers", "manage-containers")},
+	Get:   APIEndpointAction{Handler: instanceMetadataGet, AccessHandler: allowInstancePermission("view")},
+	Patch: APIEndpointAction{Handler: instanceMetadataPatch, AccessHandler: allowInstancePermission("manage-containers")},
+	Put:   APIEndpointAction{Handler: instanceMetadataPut, AccessHandler: allowInstancePermission("manage-containers")},
 }
 
 var instanceMetadataTemplatesCmd = APIEndpoint{
@@ -159,9 +193,9 @@ var instanceMetadataTemplatesCmd = APIEndpoint{
 		{Name: "vmMetadataTemplates", Path: "virtual-machines/{name}/metadata/templates"},
 	},
 
-	Get:    APIEndpointAction{Handler: instanceMetadataTemplatesGet, AccessHandler: allowProjectPermission("containers", "view")},
-	Post:   APIEndpointAction{Handler: instanceMetadataTemplatesPost, AccessHandler: allowProjectPermission("containers", "manage-containers")},
-	Delete: APIEndpointAction{Handler: instanceMetadataTemplatesDelete, AccessHandler: allowProjectPermission("containers", "manage-containers")},
+	Get:    APIEndpointAction{Handler: instanceMetadataTemplatesGet, AccessHandler: allowInstancePermission("view")},
+	Post:   APIEndpointAction{Handler: instanceMetadataTemplatesPost, AccessHandler: allowInstancePermission("manage-containers")},
+	Delete: APIEndpointAction{Handler: instanceMetadataTemplatesDelete, AccessHandler: allowInstancePermission("manage-containers")},
 }
 
 var instanceBackupsCmd = APIEndpoint{
@@ -172,8 +206,8 @@ var instanceBackupsCmd = APIEndpoint{
 		{Name: "vmBackups", Path: "virtual-machines/{name}/backups"},
 	},
 
-	Get:  APIEndpointAction{Handler: instanceBackupsGet, AccessHandler: allowProjectPermission("containers", "view")},
-	Post: APIEndpointAction{Handler: instanceBackupsPost, AccessHandler: allowProjectPermission("containers", "operate-containers")},
+	Get:  APIEndpointAction{Handler: instanceBackupsGet, AccessHandler: allowInstancePermission("view")},
+	Post: APIEndpointAction{Handler: instanceBackupsPost, AccessHandler: allowInstancePermission("operate-containers")},
 }
 
 var instanceBackupCmd = APIEndpoint{
@@ -184,9 +218,9 @@ var instanceBackupCmd = APIEndpoint{
 		{Name: "vmBackup", Path: "virtual-machines/{name}/backups/{backupName}"},
 	},
 
-	Get:    APIEndpointAction{Handler: instanceBackupGet, AccessHandler: allowProjectPermission("containers", "view")},
-	Post:   APIEndpointAction{Handler: instanceBackupPost, AccessHandler: allowProjectPermission("containers", "operate-containers")},
-	Delete: APIEndpointAction{Handler: instanceBackupDelete, AccessHandler: allowProjectPermission("containers", "operate-containers")},
+	Get:    APIEndpointAction{Handler: instanceBackupGet, AccessHandler: allowInstancePermission("view")},
+	Post:   APIEndpointAction{Handler: instanceBackupPost, AccessHandler: allowInstancePermission("operate-containers")},
+	Delete: APIEndpointAction{Handler: instanceBackupDelete, AccessHandler: allowInstancePermission("operate-containers")},
 }
 
 var instanceBackupExportCmd = APIEndpoint{
@@ -197,7 +231,7 @@ var instanceBackupExportCmd = APIEndpoint{
 		{Name: "vmBackupExport", Path: "virtual-machines/{name}/backups/{backupName}/export"},
 	},
 
-	Get: APIEndpointAction{Handler: instanceBackupExportGet, AccessHandler: allowProjectPermission("containers", "view")},
+	Get: APIEndpointAction{Handler: instanceBackupExportGet, AccessHandler: allowInstancePermission("view")},
 }
 
 type instanceAutostartList []instance.Instance
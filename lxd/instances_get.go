@@ -281,7 +281,7 @@ func doInstancesGet(d *Daemon, r *http.Request) (any, error) {
 			}
 
 			for _, project := range projects {
-				if !rbac.UserHasPermission(r, project.Name, "view") {
+				if !rbac.UserHasAnyInstancePermission(r, project.Name, "view") {
 					continue
 				}
 
@@ -296,6 +296,20 @@ func doInstancesGet(d *Daemon, r *http.Request) (any, error) {
 			return err
 		}
 
+		// Only keep the instances the user is allowed to see.
+		if !rbac.UserIsAdmin(r) {
+			for address, projectsInstances := range nodesProjectsInstances {
+				allowed := make([][2]string, 0, len(projectsInstances))
+				for _, projectInstance := range projectsInstances {
+					if rbac.UserHasInstancePermission(r, projectInstance[0], projectInstance[1], "view") {
+						allowed = append(allowed, projectInstance)
+					}
+				}
+
+				nodesProjectsInstances[address] = allowed
+			}
+		}
+
 		projectInstanceToNodeName, err = tx.GetProjectInstanceToNodeMap(filteredProjects, db.InstanceTypeFilter(instanceType))
 		if err != nil {
 			return err
@@ -396,6 +410,11 @@ func doInstancesGet(d *Daemon, r *http.Request) (any, error) {
 					}
 
 					for _, c := range cs {
+						// Remote members return all their instances, only keep the ones the user is allowed to see.
+						if !rbac.UserHasInstancePermission(r, c.Project, c.Name, "view") {
+							continue
+						}
+
 						resultListAppend([2]string{c.Name, c.Project}, c, nil)
 					}
 
@@ -412,6 +431,11 @@ func doInstancesGet(d *Daemon, r *http.Request) (any, error) {
 				}
 
 				for _, c := range cs {
+					// Remote members return all their instances, only keep the ones the user is allowed to see.
+					if !rbac.UserHasInstancePermission(r, c.Project, c.Name, "view") {
+						continue
+					}
+
 					resultFullListAppend([2]string{c.Name, c.Project}, c, nil)
 				}
 			}(address, projectsInstances)
@@ -28,6 +28,7 @@ import (
 	"github.com/lxc/lxd/lxd/request"
 	"github.com/lxc/lxd/lxd/response"
 	"github.com/lxc/lxd/lxd/revert"
+	"github.com/lxc/lxd/lxd/scriptlet"
 	storagePools "github.com/lxc/lxd/lxd/storage"
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/api"
@@ -673,6 +674,60 @@ func createFromBackup(d *Daemon, r *http.Request, projectName string, data io.Re
 		"snapshots": bInfo.Snapshots,
 	})
 
+	// Incremental backups are applied to the existing instance restored from the backup they are based on.
+	if bInfo.IncrementalFrom != "" {
+		inst, err := instance.LoadByProjectAndName(d.State(), bInfo.Project, bInfo.Name)
+		if err != nil {
+			return response.SmartError(fmt.Errorf("Failed loading instance %q to apply the incremental backup to: %w", bInfo.Name, err))
+		}
+
+		if inst.Type() != instancetype.VM {
+			return response.BadRequest(fmt.Errorf("Incremental backups can only be applied to virtual machines"))
+		}
+
+		if inst.LocalConfig()["volatile.backup.checkpoint"] != bInfo.IncrementalFrom {
+			return response.BadRequest(fmt.Errorf("Instance %q isn't at backup checkpoint %q that the incremental backup is based on", bInfo.Name, bInfo.IncrementalFrom))
+		}
+
+		run := func(op *operations.Operation) error {
+			defer backupFile.Close()
+
+			instOp, err := operationlock.Create(inst.Project(), inst.Name(), operationlock.ActionRestore, false, false)
+			if err != nil {
+				return err
+			}
+
+			defer instOp.Done(err)
+
+			if inst.IsRunning() {
+				return fmt.Errorf("Instance must be stopped to apply an incremental backup")
+			}
+
+			pool, err := storagePools.LoadByInstance(d.State(), inst)
+			if err != nil {
+				return err
+			}
+
+			err = pool.RestoreInstanceBackupDelta(inst, backupFile, op)
+			if err != nil {
+				return fmt.Errorf("Failed applying incremental backup: %w", err)
+			}
+
+			return inst.VolatileSet(map[string]string{"volatile.backup.checkpoint": bInfo.Checkpoint})
+		}
+
+		resources := map[string][]string{}
+		resources["instances"] = []string{bInfo.Name}
+
+		op, err := operations.OperationCreate(d.State(), bInfo.Project, operations.OperationClassTask, db.OperationBackupRestore, resources, nil, run, nil, nil, r)
+		if err != nil {
+			return response.InternalError(err)
+		}
+
+		revert.Success()
+		return operations.OperationResponse(op)
+	}
+
 	// Check storage pool exists.
 	_, _, _, err = d.State().Cluster.GetStoragePoolInAnyState(bInfo.Pool)
 	if response.IsNotFoundError(err) {
@@ -750,6 +805,14 @@ func createFromBackup(d *Daemon, r *http.Request, projectName string, data io.Re
 			}
 		}
 
+		// Record the checkpoint the restored root disk matches, replacing the one of the source instance.
+		if inst.LocalConfig()["volatile.backup.checkpoint"] != bInfo.Checkpoint {
+			err = inst.VolatileSet(map[string]string{"volatile.backup.checkpoint": bInfo.Checkpoint})
+			if err != nil {
+				return fmt.Errorf("Failed recording backup checkpoint: %w", err)
+			}
+		}
+
 		runRevert.Success()
 		return nil
 	}
@@ -865,6 +928,7 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 		// no-op, since GetNodeWithLeastInstances() will return an empty
 		// string.
 		// If the target is a cluster group, find a suitable node.
+		// The instance placement scriptlet, if set, can override the choice.
 		group := ""
 
 		if strings.HasPrefix(targetNode, "@") {
@@ -921,16 +985,19 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 			return response.BadRequest(err)
 		}
 
+		var candidateMembers []db.NodeInfo
+		var placementScriptlet string
+
 		err = d.cluster.Transaction(func(tx *db.ClusterTx) error {
+			config, err := cluster.ConfigLoad(tx)
+			if err != nil {
+				return err
+			}
+
 			defaultArch := ""
 			if targetProject.Config["images.default_architecture"] != "" {
 				defaultArch = targetProject.Config["images.default_architecture"]
 			} else {
-				config, err := cluster.ConfigLoad(tx)
-				if err != nil {
-					return err
-				}
-
 				defaultArch = config.ImagesDefaultArchitecture()
 			}
 
@@ -942,14 +1009,49 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 				}
 			}
 
-			var err error
 			targetNode, err = tx.GetNodeWithLeastInstances(architectures, defaultArchID, group, allowedGroups)
-			return err
+			if err != nil {
+				return err
+			}
+
+			// Load the candidate members for the placement scriptlet.
+			placementScriptlet = config.InstancesPlacementScriptlet()
+			if placementScriptlet != "" {
+				candidateMembers, err = tx.GetCandidateMembers(architectures, group, allowedGroups)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
 		})
 		if err != nil {
 			return response.SmartError(err)
 		}
 
+		// Let the placement scriptlet pick the member, falling back to the one with the least instances.
+		if placementScriptlet != "" && len(candidateMembers) > 0 {
+			leaderAddress, err := d.gateway.LeaderAddress()
+			if err != nil {
+				return response.InternalError(err)
+			}
+
+			placementReq := &scriptlet.InstancePlacement{
+				InstancesPost: req,
+				Project:       targetProjectName,
+				Reason:        scriptlet.InstancePlacementReasonNew,
+			}
+
+			targetMember, err := scriptlet.InstancePlacementRun(r.Context(), logger.Log, d.State(), placementScriptlet, placementReq, candidateMembers, leaderAddress)
+			if err != nil {
+				return response.BadRequest(fmt.Errorf("Failed instance placement scriptlet: %w", err))
+			}
+
+			if targetMember != nil {
+				targetNode = targetMember.Name
+			}
+		}
+
 		if targetNode == "" {
 			return response.BadRequest(fmt.Errorf("No suitable cluster member could be found"))
 		}
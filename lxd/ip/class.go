@@ -14,7 +14,8 @@ type Class struct {
 // ClassHTB represents htb qdisc class object
 type ClassHTB struct {
 	Class
-	Rate string
+	Rate  string
+	Burst string
 }
 
 // Add adds class to a node
@@ -29,6 +30,10 @@ func (class *ClassHTB) Add() error {
 		cmd = append(cmd, "rate", class.Rate)
 	}
 
+	if class.Burst != "" {
+		cmd = append(cmd, "burst", class.Burst)
+	}
+
 	_, err := shared.RunCommand("tc", cmd...)
 	if err != nil {
 		return err
@@ -19,7 +19,7 @@ type ActionPolice struct {
 
 // AddAction generates a part of command specific for 'police' action
 func (a *ActionPolice) AddAction() []string {
-	result := []string{"police"}
+	result := []string{"action", "police"}
 	if a.Rate != "" {
 		result = append(result, "rate", a.Rate)
 	}
@@ -38,11 +38,58 @@ func (a *ActionPolice) AddAction() []string {
 	return result
 }
 
+// ActionSkbedit represents an action of 'skbedit' type
+type ActionSkbedit struct {
+	Priority string
+}
+
+// AddAction generates a part of command specific for 'skbedit' action
+func (a *ActionSkbedit) AddAction() []string {
+	result := []string{"action", "skbedit"}
+	if a.Priority != "" {
+		result = append(result, "priority", a.Priority)
+	}
+
+	return append(result, "pipe")
+}
+
+// ActionPedit represents an action of 'pedit' type using extended header field names
+type ActionPedit struct {
+	Munge []string
+}
+
+// AddAction generates a part of command specific for 'pedit' action
+func (a *ActionPedit) AddAction() []string {
+	result := []string{"action", "pedit", "ex", "munge"}
+	result = append(result, a.Munge...)
+
+	return append(result, "pipe")
+}
+
+// ActionCsum represents an action of 'csum' type
+type ActionCsum struct {
+	Update string
+}
+
+// AddAction generates a part of command specific for 'csum' action
+func (a *ActionCsum) AddAction() []string {
+	return []string{"action", "csum", a.Update, "pipe"}
+}
+
+// ActionDrop represents an action of 'drop' type
+type ActionDrop struct{}
+
+// AddAction generates a part of command specific for 'drop' action
+func (a *ActionDrop) AddAction() []string {
+	return []string{"action", "drop"}
+}
+
 // Filter represents filter object
 type Filter struct {
 	Dev      string
 	Parent   string
 	Protocol string
+	Prio     string
 	Flowid   string
 }
 
@@ -62,6 +109,10 @@ func (u32 *U32Filter) Add() error {
 	}
 
 	cmd = append(cmd, "protocol", u32.Protocol)
+	if u32.Prio != "" {
+		cmd = append(cmd, "prio", u32.Prio)
+	}
+
 	cmd = append(cmd, "u32", "match", "u32", u32.Value, u32.Mask)
 
 	for _, action := range u32.Actions {
@@ -79,3 +130,69 @@ func (u32 *U32Filter) Add() error {
 	}
 	return nil
 }
+
+// FlowerFilter represents flow based traffic control filter
+type FlowerFilter struct {
+	Filter
+	IPProto  string
+	DstPort  string
+	ICMPType string
+	ARPOp    string
+	ARPSIP   string
+	ARPTIP   string
+	Actions  []Action
+}
+
+// Add adds flow based traffic control filter to a node
+func (flower *FlowerFilter) Add() error {
+	cmd := []string{"filter", "add", "dev", flower.Dev}
+	if flower.Parent != "" {
+		cmd = append(cmd, "parent", flower.Parent)
+	}
+
+	cmd = append(cmd, "protocol", flower.Protocol)
+	if flower.Prio != "" {
+		cmd = append(cmd, "prio", flower.Prio)
+	}
+
+	cmd = append(cmd, "flower")
+
+	if flower.IPProto != "" {
+		cmd = append(cmd, "ip_proto", flower.IPProto)
+	}
+
+	if flower.DstPort != "" {
+		cmd = append(cmd, "dst_port", flower.DstPort)
+	}
+
+	if flower.ICMPType != "" {
+		cmd = append(cmd, "type", flower.ICMPType)
+	}
+
+	if flower.ARPOp != "" {
+		cmd = append(cmd, "arp_op", flower.ARPOp)
+	}
+
+	if flower.ARPSIP != "" {
+		cmd = append(cmd, "arp_sip", flower.ARPSIP)
+	}
+
+	if flower.ARPTIP != "" {
+		cmd = append(cmd, "arp_tip", flower.ARPTIP)
+	}
+
+	for _, action := range flower.Actions {
+		actionCmd := action.AddAction()
+		cmd = append(cmd, actionCmd...)
+	}
+
+	if flower.Flowid != "" {
+		cmd = append(cmd, "flowid", flower.Flowid)
+	}
+
+	_, err := shared.RunCommand("tc", cmd...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -11,6 +11,8 @@ type Vxlan struct {
 	DstPort string
 	TTL     string
 	FanMap  string
+
+	NoLearning bool
 }
 
 // additionalArgs generates vxlan specific arguments
@@ -38,6 +40,9 @@ func (vxlan *Vxlan) additionalArgs() []string {
 	if vxlan.FanMap != "" {
 		args = append(args, "fan-map", vxlan.FanMap)
 	}
+	if vxlan.NoLearning {
+		args = append(args, "nolearning")
+	}
 	return args
 }
 
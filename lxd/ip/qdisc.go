@@ -77,3 +77,20 @@ func (qdisc *QdiscHTB) Add() error {
 	}
 	return nil
 }
+
+// QdiscClsact represents the classifier action qdisc object, allowing filters on the ingress and egress of a device
+type QdiscClsact struct {
+	Qdisc
+}
+
+// Add adds qdisc to a node
+func (qdisc *QdiscClsact) Add() error {
+	cmd := qdisc.mainCmd()
+	cmd = append(cmd, "clsact")
+
+	_, err := shared.RunCommand("tc", cmd...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package lifecycle
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/lxc/lxd/shared/api"
+)
+
+// AuthGroupAction represents a lifecycle event action for authorization groups.
+type AuthGroupAction string
+
+// All supported lifecycle events for authorization groups.
+const (
+	AuthGroupCreated = AuthGroupAction("created")
+	AuthGroupDeleted = AuthGroupAction("deleted")
+	AuthGroupUpdated = AuthGroupAction("updated")
+	AuthGroupRenamed = AuthGroupAction("renamed")
+)
+
+// Event creates the lifecycle event for an action on an authorization group.
+func (a AuthGroupAction) Event(name string, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	eventType := fmt.Sprintf("auth-group-%s", a)
+
+	u := fmt.Sprintf("/1.0/auth/groups/%s", url.PathEscape(name))
+
+	return api.EventLifecycle{
+		Action:    eventType,
+		Source:    u,
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
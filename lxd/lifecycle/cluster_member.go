@@ -16,6 +16,7 @@ const (
 	ClusterMemberRemoved = ClusterMemberAction("removed")
 	ClusterMemberUpdated = ClusterMemberAction("updated")
 	ClusterMemberRenamed = ClusterMemberAction("renamed")
+	ClusterMemberHealed  = ClusterMemberAction("healed")
 )
 
 // Event creates the lifecycle event for an action on a cluster member.
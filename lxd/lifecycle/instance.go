@@ -38,6 +38,7 @@ const (
 	InstanceFileRetrieved    = InstanceAction("file-retrieved")
 	InstanceFilePushed       = InstanceAction("file-pushed")
 	InstanceFileDeleted      = InstanceAction("file-deleted")
+	InstanceHealed           = InstanceAction("healed")
 )
 
 // Event creates the lifecycle event for an action on an instance.
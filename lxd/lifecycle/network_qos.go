@@ -0,0 +1,43 @@
+package lifecycle
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/shared/api"
+)
+
+// Internal copy of the network QoS policy interface.
+type networkQoSPolicy interface {
+	Info() *api.NetworkQoSPolicy
+	Project() string
+}
+
+// NetworkQoSPolicyAction represents a lifecycle event action for network QoS policies.
+type NetworkQoSPolicyAction string
+
+// All supported lifecycle events for network QoS policies.
+const (
+	NetworkQoSPolicyCreated = NetworkQoSPolicyAction("created")
+	NetworkQoSPolicyDeleted = NetworkQoSPolicyAction("deleted")
+	NetworkQoSPolicyUpdated = NetworkQoSPolicyAction("updated")
+	NetworkQoSPolicyRenamed = NetworkQoSPolicyAction("renamed")
+)
+
+// Event creates the lifecycle event for an action on a network QoS policy.
+func (a NetworkQoSPolicyAction) Event(n networkQoSPolicy, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	eventType := fmt.Sprintf("network-qos-%s", a)
+
+	u := fmt.Sprintf("/1.0/network-qos/%s", url.PathEscape(n.Info().Name))
+	if n.Project() != project.Default {
+		u = fmt.Sprintf("%s?project=%s", u, url.QueryEscape(n.Project()))
+	}
+
+	return api.EventLifecycle{
+		Action:    eventType,
+		Source:    u,
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
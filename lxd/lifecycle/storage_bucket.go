@@ -0,0 +1,63 @@
+package lifecycle
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/shared/api"
+)
+
+// StorageBucketAction represents a lifecycle event action for storage buckets.
+type StorageBucketAction string
+
+// All supported lifecycle events for storage buckets.
+const (
+	StorageBucketCreated = StorageBucketAction("created")
+	StorageBucketDeleted = StorageBucketAction("deleted")
+	StorageBucketUpdated = StorageBucketAction("updated")
+)
+
+// Event creates the lifecycle event for an action on a storage bucket.
+func (a StorageBucketAction) Event(poolName string, projectName string, bucketName string, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	eventType := fmt.Sprintf("storage-bucket-%s", a)
+	u := fmt.Sprintf("/1.0/storage-pools/%s/buckets/%s", url.PathEscape(poolName), url.PathEscape(bucketName))
+
+	if projectName != project.Default {
+		u = fmt.Sprintf("%s?project=%s", u, url.QueryEscape(projectName))
+	}
+
+	return api.EventLifecycle{
+		Action:    eventType,
+		Source:    u,
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
+
+// StorageBucketKeyAction represents a lifecycle event action for storage bucket keys.
+type StorageBucketKeyAction string
+
+// All supported lifecycle events for storage bucket keys.
+const (
+	StorageBucketKeyCreated = StorageBucketKeyAction("created")
+	StorageBucketKeyDeleted = StorageBucketKeyAction("deleted")
+	StorageBucketKeyUpdated = StorageBucketKeyAction("updated")
+)
+
+// Event creates the lifecycle event for an action on a storage bucket key.
+func (a StorageBucketKeyAction) Event(poolName string, projectName string, bucketName string, keyName string, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	eventType := fmt.Sprintf("storage-bucket-key-%s", a)
+	u := fmt.Sprintf("/1.0/storage-pools/%s/buckets/%s/keys/%s", url.PathEscape(poolName), url.PathEscape(bucketName), url.PathEscape(keyName))
+
+	if projectName != project.Default {
+		u = fmt.Sprintf("%s?project=%s", u, url.QueryEscape(projectName))
+	}
+
+	return api.EventLifecycle{
+		Action:    eventType,
+		Source:    u,
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
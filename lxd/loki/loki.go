@@ -0,0 +1,378 @@
+package loki
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/lxc/lxd/lxd/events"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/logger"
+)
+
+// Push API path of Loki.
+const pushPath = "/loki/api/v1/push"
+
+const (
+	// Number of events which can be queued while the Loki server is slow or unreachable.
+	bufferSize = 1024
+
+	// Maximum number of entries sent in a single request.
+	maxBatchSize = 256
+
+	// Maximum time an entry is held before its batch is sent.
+	maxBatchWait = time.Second
+
+	// Retry settings for failed requests.
+	minBackoff = 500 * time.Millisecond
+	maxBackoff = time.Minute
+	maxRetries = 10
+
+	// Timeout of a single request.
+	timeout = 10 * time.Second
+)
+
+// entry is a single log line along with its labels.
+type entry struct {
+	labels    map[string]string
+	timestamp time.Time
+	line      string
+}
+
+// Client sends events to a Loki server.
+type Client struct {
+	client   *http.Client
+	url      *url.URL
+	username string
+	password string
+	labels   []string
+	logLevel logrus.Level
+	types    []string
+	instance string
+
+	entries chan entry
+	dropped uint64
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+// NewClient returns a Client sending events of the given types to the Loki server at the given URL.
+// Logging events are only sent if their level is at least logLevel. The labels are the names of the
+// event context fields which are added as labels to the log entries.
+func NewClient(client *http.Client, u *url.URL, username string, password string, labels []string, logLevel string, types []string) (*Client, error) {
+	level, err := logrus.ParseLevel(logLevel)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid log level %q: %w", logLevel, err)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	pushURL := *u
+	pushURL.Path = strings.TrimSuffix(pushURL.Path, "/") + pushPath
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c := &Client{
+		client:   client,
+		url:      &pushURL,
+		username: username,
+		password: password,
+		labels:   labels,
+		logLevel: level,
+		types:    types,
+		instance: hostname,
+		entries:  make(chan entry, bufferSize),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	c.wg.Add(1)
+	go c.run()
+
+	return c, nil
+}
+
+// Stop stops the client, flushing the entries which are already queued.
+func (c *Client) Stop() {
+	c.once.Do(func() {
+		c.cancel()
+		c.wg.Wait()
+	})
+}
+
+// HandleEvent queues an event to be sent to Loki.
+// It's called with the event server lock held, so it never blocks nor logs. If the queue is full, the event is
+// dropped and counted, and the dropped events are reported by the sending goroutine.
+func (c *Client) HandleEvent(event api.Event) {
+	if !shared.StringInSlice(event.Type, c.types) {
+		return
+	}
+
+	record, err := events.NewRecord(event)
+	if err != nil {
+		return
+	}
+
+	if event.Type == "logging" {
+		level, err := logrus.ParseLevel(record.Level)
+		if err != nil || level > c.logLevel {
+			return
+		}
+	}
+
+	labels := map[string]string{
+		"app":      "lxd",
+		"type":     event.Type,
+		"instance": c.instance,
+	}
+
+	if event.Location != "" {
+		labels["location"] = event.Location
+	}
+
+	if event.Project != "" {
+		labels["project"] = event.Project
+	}
+
+	if event.Type == "logging" {
+		labels["level"] = record.Level
+	}
+
+	for _, label := range c.labels {
+		value, ok := record.Context[label]
+		if ok && value != "" {
+			labels[label] = value
+		}
+	}
+
+	e := entry{
+		labels:    labels,
+		timestamp: event.Timestamp,
+		line:      record.String(),
+	}
+
+	select {
+	case c.entries <- e:
+	case <-c.ctx.Done():
+	default:
+		atomic.AddUint64(&c.dropped, 1)
+	}
+}
+
+// run batches the queued entries and sends them.
+func (c *Client) run() {
+	defer c.wg.Done()
+
+	batch := []entry{}
+	ticker := time.NewTicker(maxBatchWait)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			// Flush whatever is left, without retrying.
+			for {
+				select {
+				case e := <-c.entries:
+					batch = append(batch, e)
+					continue
+				default:
+				}
+
+				break
+			}
+
+			if len(batch) > 0 {
+				_ = c.send(context.Background(), batch)
+			}
+
+			return
+
+		case e := <-c.entries:
+			batch = append(batch, e)
+			if len(batch) < maxBatchSize {
+				continue
+			}
+
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+
+		c.sendWithRetries(batch)
+		c.reportDropped()
+		batch = []entry{}
+	}
+}
+
+// reportDropped logs and resets the number of events dropped as the queue was full.
+func (c *Client) reportDropped() {
+	dropped := atomic.SwapUint64(&c.dropped, 0)
+	if dropped > 0 {
+		logger.Warn("Dropped events as the Loki event queue was full", logger.Ctx{"url": c.url.String(), "dropped": dropped})
+	}
+}
+
+// sendWithRetries sends a batch, retrying with an exponential backoff on failures which may be temporary.
+// While it's retrying, new events accumulate in the queue.
+func (c *Client) sendWithRetries(batch []entry) {
+	backoff := minBackoff
+
+	for i := 0; i < maxRetries; i++ {
+		err := c.send(c.ctx, batch)
+		if err == nil {
+			return
+		}
+
+		if !isRetryable(err) {
+			logger.Warn("Failed to send events to Loki", logger.Ctx{"url": c.url.String(), "err": err})
+			return
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+
+	logger.Warn("Giving up sending events to Loki", logger.Ctx{"url": c.url.String(), "entries": len(batch), "retries": maxRetries})
+}
+
+// statusError is returned when Loki rejects a request.
+type statusError struct {
+	code    int
+	message string
+}
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("Server returned HTTP status %d: %s", e.code, e.message)
+}
+
+// isRetryable returns whether a request which failed with the given error should be retried.
+func isRetryable(err error) bool {
+	statusErr, ok := err.(statusError)
+	if !ok {
+		return true // Network errors.
+	}
+
+	return statusErr.code == http.StatusTooManyRequests || statusErr.code >= 500
+}
+
+// send sends a batch of entries in a single request.
+func (c *Client) send(ctx context.Context, batch []entry) error {
+	body, err := encodeBatch(batch)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "LXD")
+
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return statusError{code: resp.StatusCode, message: strings.TrimSpace(string(message))}
+	}
+
+	return nil
+}
+
+// stream is a set of log lines sharing the same labels, as expected by the Loki push API.
+type stream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// encodeBatch encodes a batch of entries as a Loki push request, grouping them by labels.
+func encodeBatch(batch []entry) ([]byte, error) {
+	streams := map[string]*stream{}
+	keys := []string{}
+
+	for _, e := range batch {
+		key := labelsKey(e.labels)
+
+		s, ok := streams[key]
+		if !ok {
+			s = &stream{Stream: e.labels}
+			streams[key] = s
+			keys = append(keys, key)
+		}
+
+		s.Values = append(s.Values, [2]string{strconv.FormatInt(e.timestamp.UnixNano(), 10), e.line})
+	}
+
+	req := struct {
+		Streams []*stream `json:"streams"`
+	}{}
+
+	for _, key := range keys {
+		req.Streams = append(req.Streams, streams[key])
+	}
+
+	return json.Marshal(req)
+}
+
+// labelsKey returns a string uniquely identifying a set of labels.
+func labelsKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(strconv.Quote(k))
+		sb.WriteString("=")
+		sb.WriteString(strconv.Quote(labels[k]))
+		sb.WriteString(",")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,213 @@
+package loki
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/lxd/lxd/events"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/logger"
+)
+
+type pushRequest struct {
+	Streams []struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	} `json:"streams"`
+}
+
+// lokiServer is a local stand-in for the Loki push API.
+type lokiServer struct {
+	mu       sync.Mutex
+	requests []pushRequest
+	failures int // Number of requests to fail before accepting them.
+}
+
+func (s *lokiServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path != pushPath {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	username, password, _ := r.BasicAuth()
+	if username != "user" || password != "pass" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	req := pushRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.requests = append(s.requests, req)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// lines returns the received log lines by stream type.
+func (s *lokiServer) lines() map[string][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lines := map[string][]string{}
+	for _, req := range s.requests {
+		for _, stream := range req.Streams {
+			for _, value := range stream.Values {
+				lines[stream.Stream["type"]] = append(lines[stream.Stream["type"]], value[1])
+			}
+		}
+	}
+
+	return lines
+}
+
+func newEvent(t *testing.T, eventType string, metadata any) api.Event {
+	data, err := json.Marshal(metadata)
+	require.NoError(t, err)
+
+	return api.Event{
+		Type:      eventType,
+		Timestamp: time.Now(),
+		Metadata:  data,
+		Project:   "default",
+		Location:  "node1",
+	}
+}
+
+func newTestClient(t *testing.T, server *httptest.Server) *Client {
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	client, err := NewClient(server.Client(), u, "user", "pass", []string{"name"}, "warn", []string{"lifecycle", "logging"})
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestClient(t *testing.T) {
+	server := &lokiServer{}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	client := newTestClient(t, httpServer)
+
+	client.HandleEvent(newEvent(t, "lifecycle", api.EventLifecycle{
+		Action:    "instance-started",
+		Source:    "/1.0/instances/c1",
+		Context:   map[string]any{"name": "c1"},
+		Requestor: &api.EventLifecycleRequestor{Username: "alice", Protocol: "tls", Address: "10.0.0.1"},
+	}))
+
+	client.HandleEvent(newEvent(t, "logging", api.EventLogging{Message: "Something happened", Level: "info"}))
+	client.HandleEvent(newEvent(t, "logging", api.EventLogging{Message: "Something failed", Level: "error", Context: map[string]string{"err": "boom"}}))
+	client.HandleEvent(newEvent(t, "operation", api.Operation{ID: "1234", Description: "Creating instance"}))
+
+	client.Stop()
+
+	lines := server.lines()
+	assert.Equal(t, []string{`msg="instance-started" name="c1" requestor-address="10.0.0.1" requestor-protocol="tls" requestor-username="alice" source="/1.0/instances/c1"`}, lines["lifecycle"])
+	assert.Equal(t, []string{`msg="Something failed" err="boom"`}, lines["logging"])
+	assert.Empty(t, lines["operation"])
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	for _, req := range server.requests {
+		for _, stream := range req.Streams {
+			assert.Equal(t, "lxd", stream.Stream["app"])
+			assert.Equal(t, "node1", stream.Stream["location"])
+			assert.Equal(t, "default", stream.Stream["project"])
+
+			if stream.Stream["type"] == "lifecycle" {
+				assert.Equal(t, "c1", stream.Stream["name"])
+			}
+		}
+	}
+}
+
+func TestClient_Retry(t *testing.T) {
+	server := &lokiServer{failures: 2}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	client := newTestClient(t, httpServer)
+	defer client.Stop()
+
+	client.HandleEvent(newEvent(t, "lifecycle", api.EventLifecycle{Action: "instance-created", Source: "/1.0/instances/c1"}))
+
+	assert.Eventually(t, func() bool {
+		return len(server.lines()["lifecycle"]) == 1
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+func TestClient_Full(t *testing.T) {
+	// A server which doesn't answer until unblocked, so that the client is stuck sending its first batch.
+	blocked := make(chan struct{})
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-blocked
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	defer httpServer.Close()
+
+	client := newTestClient(t, httpServer)
+	defer client.Stop()
+
+	// Route the events and the daemon logs through an event server, as the daemon does, so that logging
+	// while handling an event would deadlock on the event server lock.
+	server := events.NewServer(false, false, nil)
+	server.SetSink("loki", client)
+
+	err := logger.InitLogger("", "", false, false, events.NewEventHandler())
+	require.NoError(t, err)
+
+	events.LoggingServer = server
+	defer func() { events.LoggingServer = nil }()
+
+	// Queuing events never blocks, extra events are dropped and counted.
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < bufferSize+maxBatchSize+10; i++ {
+			server.SendLifecycle("default", api.EventLifecycle{Action: "instance-created"})
+			logger.Warn("Something happened")
+		}
+
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		close(blocked)
+		t.Fatal("HandleEvent blocked")
+	}
+
+	assert.NotZero(t, atomic.LoadUint64(&client.dropped))
+
+	// The dropped events are reported and the counter reset once the server answers.
+	close(blocked)
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadUint64(&client.dropped) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
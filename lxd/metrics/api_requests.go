@@ -0,0 +1,171 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// apiRequestKey identifies a set of API requests.
+type apiRequestKey struct {
+	method   string
+	endpoint string
+}
+
+// apiRequestStats holds the statistics of completed API requests.
+type apiRequestStats struct {
+	count    uint64
+	duration time.Duration
+}
+
+// APIRequests tracks the number, duration and status code of the API requests handled by the daemon.
+type APIRequests struct {
+	lock      sync.Mutex
+	completed map[apiRequestKey]map[int]*apiRequestStats
+	ongoing   map[apiRequestKey]int
+}
+
+// NewAPIRequests returns a new APIRequests tracker.
+func NewAPIRequests() *APIRequests {
+	return &APIRequests{
+		completed: map[apiRequestKey]map[int]*apiRequestStats{},
+		ongoing:   map[apiRequestKey]int{},
+	}
+}
+
+// Track records the start of an API request for the given endpoint.
+// It returns a ResponseWriter recording the status code of the response, which must be used to handle the
+// request, and a function to call once the request has been handled.
+func (a *APIRequests) Track(w http.ResponseWriter, method string, endpoint string) (http.ResponseWriter, func()) {
+	key := apiRequestKey{method: method, endpoint: endpoint}
+	recorder := &statusRecorder{ResponseWriter: w}
+	start := time.Now()
+
+	a.lock.Lock()
+	a.ongoing[key]++
+	a.lock.Unlock()
+
+	done := func() {
+		duration := time.Since(start)
+
+		a.lock.Lock()
+		defer a.lock.Unlock()
+
+		a.ongoing[key]--
+		if a.ongoing[key] <= 0 {
+			delete(a.ongoing, key)
+		}
+
+		_, ok := a.completed[key]
+		if !ok {
+			a.completed[key] = map[int]*apiRequestStats{}
+		}
+
+		stats, ok := a.completed[key][recorder.Status()]
+		if !ok {
+			stats = &apiRequestStats{}
+			a.completed[key][recorder.Status()] = stats
+		}
+
+		stats.count++
+		stats.duration += duration
+	}
+
+	return recorder, done
+}
+
+// AddToMetricSet adds the API request metrics to the given MetricSet.
+func (a *APIRequests) AddToMetricSet(set *MetricSet) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	for key, codes := range a.completed {
+		for code, stats := range codes {
+			labels := map[string]string{"method": key.method, "endpoint": key.endpoint, "code": strconv.Itoa(code)}
+
+			set.AddSamples(APIRequestsTotal, Sample{Value: float64(stats.count), Labels: labels})
+			set.AddSamples(APIRequestDurationSecondsTotal, Sample{Value: stats.duration.Seconds(), Labels: copyLabels(labels)})
+		}
+	}
+
+	for key, count := range a.ongoing {
+		set.AddSamples(APIRequestsOngoing, Sample{Value: float64(count), Labels: map[string]string{"method": key.method, "endpoint": key.endpoint}})
+	}
+}
+
+// copyLabels returns a copy of labels, as AddSamples modifies the labels of the samples it's given.
+func copyLabels(labels map[string]string) map[string]string {
+	out := make(map[string]string, len(labels))
+	for k, v := range labels {
+		out[k] = v
+	}
+
+	return out
+}
+
+// statusRecorder is a ResponseWriter recording the status code of the response.
+type statusRecorder struct {
+	http.ResponseWriter
+
+	lock   sync.Mutex
+	status int
+}
+
+// WriteHeader records the status code and writes it.
+func (w *statusRecorder) WriteHeader(code int) {
+	w.setStatus(code)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write writes the data, recording an implicit 200 status code if no status code was written before.
+func (w *statusRecorder) Write(data []byte) (int, error) {
+	w.setStatus(http.StatusOK)
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush sends any buffered data to the client.
+func (w *statusRecorder) Flush() {
+	flusher, ok := w.ResponseWriter.(http.Flusher)
+	if ok {
+		w.setStatus(http.StatusOK)
+		flusher.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection, which is recorded as a protocol switch.
+func (w *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("ResponseWriter doesn't support hijacking")
+	}
+
+	w.setStatus(http.StatusSwitchingProtocols)
+
+	return hijacker.Hijack()
+}
+
+// Status returns the recorded status code.
+func (w *statusRecorder) Status() int {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.status == 0 {
+		return http.StatusOK
+	}
+
+	return w.status
+}
+
+// setStatus records the status code unless one was recorded already.
+func (w *statusRecorder) setStatus(code int) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.status == 0 {
+		w.status = code
+	}
+}
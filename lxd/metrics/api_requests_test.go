@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRequests(t *testing.T) {
+	requests := NewAPIRequests()
+
+	handle := func(method string, endpoint string, code int) {
+		w, done := requests.Track(httptest.NewRecorder(), method, endpoint)
+		defer done()
+
+		if code != 0 {
+			w.WriteHeader(code)
+		}
+
+		_, _ = w.Write([]byte("{}"))
+	}
+
+	handle("GET", "/1.0/instances", 0)
+	handle("GET", "/1.0/instances", http.StatusOK)
+	handle("GET", "/1.0/instances", http.StatusNotFound)
+	handle("POST", "/1.0/instances", http.StatusAccepted)
+
+	// A request which is still being handled.
+	_, done := requests.Track(httptest.NewRecorder(), "GET", "/1.0/events")
+
+	set := NewMetricSet(nil)
+	requests.AddToMetricSet(set)
+	out := set.String()
+
+	assert.Contains(t, out, "# TYPE lxd_api_requests_total counter\n")
+	assert.Contains(t, out, `lxd_api_requests_total{code="200",endpoint="/1.0/instances",method="GET"} 2`)
+	assert.Contains(t, out, `lxd_api_requests_total{code="404",endpoint="/1.0/instances",method="GET"} 1`)
+	assert.Contains(t, out, `lxd_api_requests_total{code="202",endpoint="/1.0/instances",method="POST"} 1`)
+	assert.Contains(t, out, `lxd_api_request_duration_seconds_total{code="200",endpoint="/1.0/instances",method="GET"} `)
+	assert.Contains(t, out, "# TYPE lxd_api_requests_ongoing gauge\n")
+	assert.Contains(t, out, `lxd_api_requests_ongoing{endpoint="/1.0/events",method="GET"} 1`)
+
+	done()
+
+	set = NewMetricSet(nil)
+	requests.AddToMetricSet(set)
+	out = set.String()
+
+	assert.False(t, strings.Contains(out, "lxd_api_requests_ongoing{"))
+	assert.Contains(t, out, `lxd_api_requests_total{code="200",endpoint="/1.0/events",method="GET"} 1`)
+}
@@ -71,11 +71,12 @@ func (m *MetricSet) String() string {
 		metricTypeName := ""
 
 		// ProcsTotal is a gauge according to the OpenMetrics spec as its value can decrease.
+		// Metrics which aren't counters are gauges.
 		if metricType == ProcsTotal {
 			metricTypeName = "gauge"
 		} else if strings.HasSuffix(MetricNames[metricType], "_total") {
 			metricTypeName = "counter"
-		} else if strings.HasSuffix(MetricNames[metricType], "_bytes") {
+		} else {
 			metricTypeName = "gauge"
 		}
 
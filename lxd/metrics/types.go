@@ -88,84 +88,192 @@ const (
 	NetworkTransmitPacketsTotal
 	// ProcsTotal represents the number of running processes
 	ProcsTotal
+	// GoGoroutines represents the number of goroutines
+	GoGoroutines
+	// GoAllocBytes represents the number of bytes allocated and still in use
+	GoAllocBytes
+	// GoAllocBytesTotal represents the total number of bytes allocated, even if freed
+	GoAllocBytesTotal
+	// GoSysBytes represents the number of bytes obtained from the system
+	GoSysBytes
+	// GoMallocsTotal represents the total number of mallocs
+	GoMallocsTotal
+	// GoFreesTotal represents the total number of frees
+	GoFreesTotal
+	// GoHeapAllocBytes represents the number of heap bytes allocated and still in use
+	GoHeapAllocBytes
+	// GoHeapSysBytes represents the number of heap bytes obtained from the system
+	GoHeapSysBytes
+	// GoHeapIdleBytes represents the number of heap bytes waiting to be used
+	GoHeapIdleBytes
+	// GoHeapInuseBytes represents the number of heap bytes that are in use
+	GoHeapInuseBytes
+	// GoHeapReleasedBytes represents the number of heap bytes released to the OS
+	GoHeapReleasedBytes
+	// GoHeapObjects represents the number of allocated objects
+	GoHeapObjects
+	// GoStackInuseBytes represents the number of bytes in use by the stack allocator
+	GoStackInuseBytes
+	// GoStackSysBytes represents the number of bytes obtained from the system for the stack allocator
+	GoStackSysBytes
+	// GoNextGCBytes represents the heap size at which the next garbage collection takes place
+	GoNextGCBytes
+	// GoGCTotal represents the number of completed garbage collection cycles
+	GoGCTotal
+	// GoGCPauseSecondsTotal represents the time spent in garbage collection pauses
+	GoGCPauseSecondsTotal
+	// UptimeSeconds represents the daemon uptime
+	UptimeSeconds
+	// Operations represents the number of operations
+	Operations
+	// APIRequestsTotal represents the number of completed API requests
+	APIRequestsTotal
+	// APIRequestDurationSecondsTotal represents the time spent handling API requests
+	APIRequestDurationSecondsTotal
+	// APIRequestsOngoing represents the number of API requests being handled
+	APIRequestsOngoing
+	// EventListeners represents the number of event listeners
+	EventListeners
+	// Warnings represents the number of unresolved warnings
+	Warnings
+	// RaftLeader represents whether the member is the raft leader
+	RaftLeader
+	// RaftRole represents the raft role of the member
+	RaftRole
+	// RaftNodes represents the number of raft nodes
+	RaftNodes
 )
 
 // MetricNames associates a metric type to its name.
 var MetricNames = map[MetricType]string{
-	CPUSecondsTotal:             "lxd_cpu_seconds_total",
-	DiskReadBytesTotal:          "lxd_disk_read_bytes_total",
-	DiskReadsCompletedTotal:     "lxd_disk_reads_completed_total",
-	DiskWrittenBytesTotal:       "lxd_disk_written_bytes_total",
-	DiskWritesCompletedTotal:    "lxd_disk_writes_completed_total",
-	FilesystemAvailBytes:        "lxd_filesystem_avail_bytes",
-	FilesystemFreeBytes:         "lxd_filesystem_free_bytes",
-	FilesystemSizeBytes:         "lxd_filesystem_size_bytes",
-	MemoryActiveAnonBytes:       "lxd_memory_Active_anon_bytes",
-	MemoryActiveFileBytes:       "lxd_memory_Active_file_bytes",
-	MemoryActiveBytes:           "lxd_memory_Active_bytes",
-	MemoryCachedBytes:           "lxd_memory_Cached_bytes",
-	MemoryDirtyBytes:            "lxd_memory_Dirty_bytes",
-	MemoryHugePagesFreeBytes:    "lxd_memory_HugepagesFree_bytes",
-	MemoryHugePagesTotalBytes:   "lxd_memory_HugepagesTotal_bytes",
-	MemoryInactiveAnonBytes:     "lxd_memory_Inactive_anon_bytes",
-	MemoryInactiveFileBytes:     "lxd_memory_Inactive_file_bytes",
-	MemoryInactiveBytes:         "lxd_memory_Inactive_bytes",
-	MemoryMappedBytes:           "lxd_memory_Mapped_bytes",
-	MemoryMemAvailableBytes:     "lxd_memory_MemAvailable_bytes",
-	MemoryMemFreeBytes:          "lxd_memory_MemFree_bytes",
-	MemoryMemTotalBytes:         "lxd_memory_MemTotal_bytes",
-	MemoryRSSBytes:              "lxd_memory_RSS_bytes",
-	MemoryShmemBytes:            "lxd_memory_Shmem_bytes",
-	MemorySwapBytes:             "lxd_memory_Swap_bytes",
-	MemoryUnevictableBytes:      "lxd_memory_Unevictable_bytes",
-	MemoryWritebackBytes:        "lxd_memory_Writeback_bytes",
-	NetworkReceiveBytesTotal:    "lxd_network_receive_bytes_total",
-	NetworkReceiveDropTotal:     "lxd_network_receive_drop_total",
-	NetworkReceiveErrsTotal:     "lxd_network_receive_errs_total",
-	NetworkReceivePacketsTotal:  "lxd_network_receive_packets_total",
-	NetworkTransmitBytesTotal:   "lxd_network_transmit_bytes_total",
-	NetworkTransmitDropTotal:    "lxd_network_transmit_drop_total",
-	NetworkTransmitErrsTotal:    "lxd_network_transmit_errs_total",
-	NetworkTransmitPacketsTotal: "lxd_network_transmit_packets_total",
-	ProcsTotal:                  "lxd_procs_total",
+	CPUSecondsTotal:                "lxd_cpu_seconds_total",
+	DiskReadBytesTotal:             "lxd_disk_read_bytes_total",
+	DiskReadsCompletedTotal:        "lxd_disk_reads_completed_total",
+	DiskWrittenBytesTotal:          "lxd_disk_written_bytes_total",
+	DiskWritesCompletedTotal:       "lxd_disk_writes_completed_total",
+	FilesystemAvailBytes:           "lxd_filesystem_avail_bytes",
+	FilesystemFreeBytes:            "lxd_filesystem_free_bytes",
+	FilesystemSizeBytes:            "lxd_filesystem_size_bytes",
+	MemoryActiveAnonBytes:          "lxd_memory_Active_anon_bytes",
+	MemoryActiveFileBytes:          "lxd_memory_Active_file_bytes",
+	MemoryActiveBytes:              "lxd_memory_Active_bytes",
+	MemoryCachedBytes:              "lxd_memory_Cached_bytes",
+	MemoryDirtyBytes:               "lxd_memory_Dirty_bytes",
+	MemoryHugePagesFreeBytes:       "lxd_memory_HugepagesFree_bytes",
+	MemoryHugePagesTotalBytes:      "lxd_memory_HugepagesTotal_bytes",
+	MemoryInactiveAnonBytes:        "lxd_memory_Inactive_anon_bytes",
+	MemoryInactiveFileBytes:        "lxd_memory_Inactive_file_bytes",
+	MemoryInactiveBytes:            "lxd_memory_Inactive_bytes",
+	MemoryMappedBytes:              "lxd_memory_Mapped_bytes",
+	MemoryMemAvailableBytes:        "lxd_memory_MemAvailable_bytes",
+	MemoryMemFreeBytes:             "lxd_memory_MemFree_bytes",
+	MemoryMemTotalBytes:            "lxd_memory_MemTotal_bytes",
+	MemoryRSSBytes:                 "lxd_memory_RSS_bytes",
+	MemoryShmemBytes:               "lxd_memory_Shmem_bytes",
+	MemorySwapBytes:                "lxd_memory_Swap_bytes",
+	MemoryUnevictableBytes:         "lxd_memory_Unevictable_bytes",
+	MemoryWritebackBytes:           "lxd_memory_Writeback_bytes",
+	NetworkReceiveBytesTotal:       "lxd_network_receive_bytes_total",
+	NetworkReceiveDropTotal:        "lxd_network_receive_drop_total",
+	NetworkReceiveErrsTotal:        "lxd_network_receive_errs_total",
+	NetworkReceivePacketsTotal:     "lxd_network_receive_packets_total",
+	NetworkTransmitBytesTotal:      "lxd_network_transmit_bytes_total",
+	NetworkTransmitDropTotal:       "lxd_network_transmit_drop_total",
+	NetworkTransmitErrsTotal:       "lxd_network_transmit_errs_total",
+	NetworkTransmitPacketsTotal:    "lxd_network_transmit_packets_total",
+	ProcsTotal:                     "lxd_procs_total",
+	GoGoroutines:                   "lxd_go_goroutines",
+	GoAllocBytes:                   "lxd_go_alloc_bytes",
+	GoAllocBytesTotal:              "lxd_go_alloc_bytes_total",
+	GoSysBytes:                     "lxd_go_sys_bytes",
+	GoMallocsTotal:                 "lxd_go_mallocs_total",
+	GoFreesTotal:                   "lxd_go_frees_total",
+	GoHeapAllocBytes:               "lxd_go_heap_alloc_bytes",
+	GoHeapSysBytes:                 "lxd_go_heap_sys_bytes",
+	GoHeapIdleBytes:                "lxd_go_heap_idle_bytes",
+	GoHeapInuseBytes:               "lxd_go_heap_inuse_bytes",
+	GoHeapReleasedBytes:            "lxd_go_heap_released_bytes",
+	GoHeapObjects:                  "lxd_go_heap_objects",
+	GoStackInuseBytes:              "lxd_go_stack_inuse_bytes",
+	GoStackSysBytes:                "lxd_go_stack_sys_bytes",
+	GoNextGCBytes:                  "lxd_go_next_gc_bytes",
+	GoGCTotal:                      "lxd_go_gc_total",
+	GoGCPauseSecondsTotal:          "lxd_go_gc_pause_seconds_total",
+	UptimeSeconds:                  "lxd_uptime_seconds",
+	Operations:                     "lxd_operations",
+	APIRequestsTotal:               "lxd_api_requests_total",
+	APIRequestDurationSecondsTotal: "lxd_api_request_duration_seconds_total",
+	APIRequestsOngoing:             "lxd_api_requests_ongoing",
+	EventListeners:                 "lxd_event_listeners",
+	Warnings:                       "lxd_warnings",
+	RaftLeader:                     "lxd_raft_leader",
+	RaftRole:                       "lxd_raft_role",
+	RaftNodes:                      "lxd_raft_nodes",
 }
 
 // MetricHeaders represents the metric headers which contain help messages as specified by OpenMetrics.
 var MetricHeaders = map[MetricType]string{
-	CPUSecondsTotal:             "# HELP lxd_cpu_seconds_total The total number of CPU seconds used in milliseconds.",
-	DiskReadBytesTotal:          "# HELP lxd_disk_read_bytes_total The total number of bytes read.",
-	DiskReadsCompletedTotal:     "# HELP lxd_disk_reads_completed_total The total number of completed reads.",
-	DiskWrittenBytesTotal:       "# HELP lxd_disk_written_bytes_total The total number of bytes written.",
-	DiskWritesCompletedTotal:    "# HELP lxd_disk_writes_completed_total The total number of completed writes.",
-	FilesystemAvailBytes:        "# HELP lxd_filesystem_avail_bytes The number of available space in bytes.",
-	FilesystemFreeBytes:         "# HELP lxd_filesystem_free_bytes The number of free space in bytes.",
-	FilesystemSizeBytes:         "# HELP lxd_filesystem_size_bytes The size of the filesystem in bytes.",
-	MemoryActiveAnonBytes:       "# HELP lxd_memory_Active_anon_bytes The amount of anonymous memory on active LRU list.",
-	MemoryActiveFileBytes:       "# HELP lxd_memory_Active_file_bytes The amount of file-backed memory on active LRU list.",
-	MemoryActiveBytes:           "# HELP lxd_memory_Active_bytes The amount of memory on active LRU list.",
-	MemoryCachedBytes:           "# HELP lxd_memory_Cached_bytes The amount of cached memory.",
-	MemoryDirtyBytes:            "# HELP lxd_memory_Dirty_bytes The amount of memory waiting to get written back to the disk.",
-	MemoryHugePagesFreeBytes:    "# HELP lxd_memory_HugepagesFree_bytes The amount of free memory for hugetlb.",
-	MemoryHugePagesTotalBytes:   "# HELP lxd_memory_HugepagesTotal_bytes The amount of used memory for hugetlb.",
-	MemoryInactiveAnonBytes:     "# HELP lxd_memory_Inactive_anon_bytes The amount of file-backed memory on inactive LRU list.",
-	MemoryInactiveFileBytes:     "# HELP lxd_memory_Inactive_file_bytes The amount of file-backed memory on inactive LRU list.",
-	MemoryInactiveBytes:         "# HELP lxd_memory_Inactive_bytes The amount of memory on inactive LRU list.",
-	MemoryMappedBytes:           "# HELP lxd_memory_Mapped_bytes The amount of mapped memory.",
-	MemoryMemAvailableBytes:     "# HELP lxd_memory_MemAvailable_bytes The amount of available memory.",
-	MemoryMemFreeBytes:          "# HELP lxd_memory_MemFree_bytes The amount of free memory.",
-	MemoryMemTotalBytes:         "# HELP lxd_memory_MemTotal_bytes The amount of used memory.",
-	MemoryRSSBytes:              "# HELP lxd_memory_RSS_bytes The amount of anonymous and swap cache memory.",
-	MemoryShmemBytes:            "# HELP lxd_memory_Shmem_bytes The amount of cached filesystem data that is swap-backed.",
-	MemorySwapBytes:             "# HELP lxd_memory_Swap_bytes The amount of used swap memory.",
-	MemoryUnevictableBytes:      "# HELP lxd_memory_Unevictable_bytes The amount of unevictable memory.",
-	MemoryWritebackBytes:        "# HELP lxd_memory_Writeback_bytes The amount of memory queued for syncing to disk.",
-	NetworkReceiveBytesTotal:    "# HELP lxd_network_receive_bytes_total The amount of received bytes on a given interface.",
-	NetworkReceiveDropTotal:     "# HELP lxd_network_receive_drop_total The amount of received dropped bytes on a given interface.",
-	NetworkReceiveErrsTotal:     "# HELP lxd_network_receive_errs_total The amount of received errors on a given interface.",
-	NetworkReceivePacketsTotal:  "# HELP lxd_network_receive_packets_total The amount of received packets on a given interface.",
-	NetworkTransmitBytesTotal:   "# HELP lxd_network_transmit_bytes_total The amount of transmitted bytes on a given interface.",
-	NetworkTransmitDropTotal:    "# HELP lxd_network_transmit_drop_total The amount of transmitted dropped bytes on a given interface.",
-	NetworkTransmitErrsTotal:    "# HELP lxd_network_transmit_errs_total The amount of transmitted errors on a given interface.",
-	NetworkTransmitPacketsTotal: "# HELP lxd_network_transmit_packets_total The amount of transmitted packets on a given interface.",
-	ProcsTotal:                  "# HELP lxd_procs_total The number of running processes.",
+	CPUSecondsTotal:                "# HELP lxd_cpu_seconds_total The total number of CPU seconds used in milliseconds.",
+	DiskReadBytesTotal:             "# HELP lxd_disk_read_bytes_total The total number of bytes read.",
+	DiskReadsCompletedTotal:        "# HELP lxd_disk_reads_completed_total The total number of completed reads.",
+	DiskWrittenBytesTotal:          "# HELP lxd_disk_written_bytes_total The total number of bytes written.",
+	DiskWritesCompletedTotal:       "# HELP lxd_disk_writes_completed_total The total number of completed writes.",
+	FilesystemAvailBytes:           "# HELP lxd_filesystem_avail_bytes The number of available space in bytes.",
+	FilesystemFreeBytes:            "# HELP lxd_filesystem_free_bytes The number of free space in bytes.",
+	FilesystemSizeBytes:            "# HELP lxd_filesystem_size_bytes The size of the filesystem in bytes.",
+	MemoryActiveAnonBytes:          "# HELP lxd_memory_Active_anon_bytes The amount of anonymous memory on active LRU list.",
+	MemoryActiveFileBytes:          "# HELP lxd_memory_Active_file_bytes The amount of file-backed memory on active LRU list.",
+	MemoryActiveBytes:              "# HELP lxd_memory_Active_bytes The amount of memory on active LRU list.",
+	MemoryCachedBytes:              "# HELP lxd_memory_Cached_bytes The amount of cached memory.",
+	MemoryDirtyBytes:               "# HELP lxd_memory_Dirty_bytes The amount of memory waiting to get written back to the disk.",
+	MemoryHugePagesFreeBytes:       "# HELP lxd_memory_HugepagesFree_bytes The amount of free memory for hugetlb.",
+	MemoryHugePagesTotalBytes:      "# HELP lxd_memory_HugepagesTotal_bytes The amount of used memory for hugetlb.",
+	MemoryInactiveAnonBytes:        "# HELP lxd_memory_Inactive_anon_bytes The amount of file-backed memory on inactive LRU list.",
+	MemoryInactiveFileBytes:        "# HELP lxd_memory_Inactive_file_bytes The amount of file-backed memory on inactive LRU list.",
+	MemoryInactiveBytes:            "# HELP lxd_memory_Inactive_bytes The amount of memory on inactive LRU list.",
+	MemoryMappedBytes:              "# HELP lxd_memory_Mapped_bytes The amount of mapped memory.",
+	MemoryMemAvailableBytes:        "# HELP lxd_memory_MemAvailable_bytes The amount of available memory.",
+	MemoryMemFreeBytes:             "# HELP lxd_memory_MemFree_bytes The amount of free memory.",
+	MemoryMemTotalBytes:            "# HELP lxd_memory_MemTotal_bytes The amount of used memory.",
+	MemoryRSSBytes:                 "# HELP lxd_memory_RSS_bytes The amount of anonymous and swap cache memory.",
+	MemoryShmemBytes:               "# HELP lxd_memory_Shmem_bytes The amount of cached filesystem data that is swap-backed.",
+	MemorySwapBytes:                "# HELP lxd_memory_Swap_bytes The amount of used swap memory.",
+	MemoryUnevictableBytes:         "# HELP lxd_memory_Unevictable_bytes The amount of unevictable memory.",
+	MemoryWritebackBytes:           "# HELP lxd_memory_Writeback_bytes The amount of memory queued for syncing to disk.",
+	NetworkReceiveBytesTotal:       "# HELP lxd_network_receive_bytes_total The amount of received bytes on a given interface.",
+	NetworkReceiveDropTotal:        "# HELP lxd_network_receive_drop_total The amount of received dropped bytes on a given interface.",
+	NetworkReceiveErrsTotal:        "# HELP lxd_network_receive_errs_total The amount of received errors on a given interface.",
+	NetworkReceivePacketsTotal:     "# HELP lxd_network_receive_packets_total The amount of received packets on a given interface.",
+	NetworkTransmitBytesTotal:      "# HELP lxd_network_transmit_bytes_total The amount of transmitted bytes on a given interface.",
+	NetworkTransmitDropTotal:       "# HELP lxd_network_transmit_drop_total The amount of transmitted dropped bytes on a given interface.",
+	NetworkTransmitErrsTotal:       "# HELP lxd_network_transmit_errs_total The amount of transmitted errors on a given interface.",
+	NetworkTransmitPacketsTotal:    "# HELP lxd_network_transmit_packets_total The amount of transmitted packets on a given interface.",
+	ProcsTotal:                     "# HELP lxd_procs_total The number of running processes.",
+	GoGoroutines:                   "# HELP lxd_go_goroutines The number of goroutines that currently exist.",
+	GoAllocBytes:                   "# HELP lxd_go_alloc_bytes The number of bytes allocated and still in use.",
+	GoAllocBytesTotal:              "# HELP lxd_go_alloc_bytes_total The total number of bytes allocated, even if freed.",
+	GoSysBytes:                     "# HELP lxd_go_sys_bytes The number of bytes obtained from the system.",
+	GoMallocsTotal:                 "# HELP lxd_go_mallocs_total The total number of mallocs.",
+	GoFreesTotal:                   "# HELP lxd_go_frees_total The total number of frees.",
+	GoHeapAllocBytes:               "# HELP lxd_go_heap_alloc_bytes The number of heap bytes allocated and still in use.",
+	GoHeapSysBytes:                 "# HELP lxd_go_heap_sys_bytes The number of heap bytes obtained from the system.",
+	GoHeapIdleBytes:                "# HELP lxd_go_heap_idle_bytes The number of heap bytes waiting to be used.",
+	GoHeapInuseBytes:               "# HELP lxd_go_heap_inuse_bytes The number of heap bytes that are in use.",
+	GoHeapReleasedBytes:            "# HELP lxd_go_heap_released_bytes The number of heap bytes released to the OS.",
+	GoHeapObjects:                  "# HELP lxd_go_heap_objects The number of allocated objects.",
+	GoStackInuseBytes:              "# HELP lxd_go_stack_inuse_bytes The number of bytes in use by the stack allocator.",
+	GoStackSysBytes:                "# HELP lxd_go_stack_sys_bytes The number of bytes obtained from the system for the stack allocator.",
+	GoNextGCBytes:                  "# HELP lxd_go_next_gc_bytes The number of heap bytes when the next garbage collection will take place.",
+	GoGCTotal:                      "# HELP lxd_go_gc_total The total number of completed garbage collection cycles.",
+	GoGCPauseSecondsTotal:          "# HELP lxd_go_gc_pause_seconds_total The total number of seconds spent in garbage collection pauses.",
+	UptimeSeconds:                  "# HELP lxd_uptime_seconds The daemon uptime in seconds.",
+	Operations:                     "# HELP lxd_operations The number of operations by class and status.",
+	APIRequestsTotal:               "# HELP lxd_api_requests_total The total number of completed API requests by method, endpoint and status code.",
+	APIRequestDurationSecondsTotal: "# HELP lxd_api_request_duration_seconds_total The total number of seconds spent handling completed API requests by method, endpoint and status code.",
+	APIRequestsOngoing:             "# HELP lxd_api_requests_ongoing The number of API requests being handled by method and endpoint.",
+	EventListeners:                 "# HELP lxd_event_listeners The number of connected event listeners by type.",
+	Warnings:                       "# HELP lxd_warnings The number of unresolved warnings of this member by severity and status.",
+	RaftLeader:                     "# HELP lxd_raft_leader Whether the member is the leader of the raft cluster (1) or not (0).",
+	RaftRole:                       "# HELP lxd_raft_role The role of the member in the raft cluster.",
+	RaftNodes:                      "# HELP lxd_raft_nodes The number of members of the raft cluster by role.",
 }
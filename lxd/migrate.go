@@ -34,6 +34,7 @@ type migrationFields struct {
 	controlConn   *websocket.Conn
 	controlLock   sync.Mutex
 
+	// Used for the CRIU checkpoint of containers and the QEMU state stream of VMs.
 	criuSecret string
 	criuConn   *websocket.Conn
 
@@ -136,6 +137,10 @@ type migrationSourceWs struct {
 	migrationFields
 
 	allConnected chan struct{}
+
+	// Set when moving a running VM to another cluster member, in which case the instance record is shared
+	// with the target and the caller is responsible for stopping the instance once migrated.
+	clusterMove bool
 }
 
 func (s *migrationSourceWs) Metadata() any {
@@ -280,6 +285,7 @@ type migrationSink struct {
 	allConnected chan struct{}
 	push         bool
 	refresh      bool
+	clusterMove  bool
 }
 
 type MigrationSinkArgs struct {
@@ -296,6 +302,7 @@ type MigrationSinkArgs struct {
 	Live         bool
 	Refresh      bool
 	Snapshots    []*migration.Snapshot
+	ClusterMove  bool // Live migration of an existing instance record from another cluster member.
 
 	// Storage specific fields
 	VolumeOnly bool
@@ -365,6 +372,10 @@ func (s *migrationSink) Connect(op *operations.Operation, r *http.Request, w htt
 		return nil
 	}
 
+	if s.src.instance != nil && s.src.instance.Type() == instancetype.VM && s.dest.live && s.dest.criuConn == nil {
+		return nil
+	}
+
 	if s.dest.controlConn == nil {
 		return nil
 	}
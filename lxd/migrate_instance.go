@@ -57,11 +57,12 @@ func newMigrationSource(inst instance.Instance, stateful bool, instanceOnly bool
 			if err != nil {
 				return nil, fmt.Errorf("Unable to perform container live migration. CRIU isn't installed on the source server")
 			}
+		}
 
-			ret.criuSecret, err = shared.RandomCryptoString()
-			if err != nil {
-				return nil, err
-			}
+		// For VMs this is used to stream the VM state if the target supports live migration.
+		ret.criuSecret, err = shared.RandomCryptoString()
+		if err != nil {
+			return nil, err
 		}
 	}
 
@@ -432,6 +433,13 @@ func (s *migrationSourceWs) Do(state *state.State, migrateOp *operations.Operati
 	offerHeader.SnapshotNames = snapshotNames
 	offerHeader.Snapshots = snapshots
 
+	// Offer to live migrate running VMs by streaming their state if the state connection is available.
+	// Targets that don't support this respond with a different type, in which case the VM is statefully
+	// stopped and its state file transferred along with the volume instead.
+	if s.instance.Type() == instancetype.VM && s.live && s.criuConn != nil {
+		offerHeader.Criu = migration.CRIUType_VM_QEMU.Enum()
+	}
+
 	// For VMs, send block device size hint in offer header so that target can create the volume the same size.
 	if s.instance.Type() == instancetype.VM {
 		blockSize, err := storagePools.InstanceDiskBlockSize(pool, s.instance, migrateOp)
@@ -505,7 +513,9 @@ func (s *migrationSourceWs) Do(state *state.State, migrateOp *operations.Operati
 		volSourceArgs.MultiSync = s.live || (respHeader.Criu != nil && *respHeader.Criu == migration.CRIUType_NONE)
 	}
 
-	if s.instance.Type() == instancetype.VM && s.live {
+	vmLive := s.instance.Type() == instancetype.VM && s.live && respHeader.GetCriu() == migration.CRIUType_VM_QEMU
+
+	if s.instance.Type() == instancetype.VM && s.live && !vmLive {
 		err = s.instance.Stop(true)
 		if err != nil {
 			return abort(fmt.Errorf("Failed statefully stopping instance: %w", err))
@@ -518,6 +528,23 @@ func (s *migrationSourceWs) Do(state *state.State, migrateOp *operations.Operati
 	volSourceArgs.TrackProgress = true
 	volSourceArgs.Refresh = respHeader.GetRefresh()
 
+	if vmLive {
+		err = s.migrateVMLive(pool, volSourceArgs, migrateOp)
+		if err != nil {
+			return abort(err)
+		}
+
+		// When moving within a cluster the caller stops the instance once the instance record is updated.
+		if !s.clusterMove {
+			err = s.instance.Stop(false)
+			if err != nil {
+				return fmt.Errorf("Failed stopping instance after live migration: %w", err)
+			}
+		}
+
+		return nil
+	}
+
 	err = pool.MigrateInstance(s.instance, &shared.WebsocketIO{Conn: s.fsConn}, volSourceArgs, migrateOp)
 	if err != nil {
 		return abort(err)
@@ -737,14 +764,74 @@ func (s *migrationSourceWs) Do(state *state.State, migrateOp *operations.Operati
 	return nil
 }
 
+// migrateVMLive transfers the VM's volume and then streams its state to the target while it keeps running.
+// Once done the VM is left paused.
+func (s *migrationSourceWs) migrateVMLive(pool storagePools.Pool, volSourceArgs *migration.VolumeSourceArgs, migrateOp *operations.Operation) error {
+	vm, ok := s.instance.(instance.VM)
+	if !ok {
+		return fmt.Errorf("Instance doesn't support live migration")
+	}
+
+	// When moving within a cluster the volume on shared storage is used by the target as is.
+	sharedStorage := s.clusterMove && pool.Driver().Info().LiveMigrationShared
+
+	// The root disk is unchanged while being sent as the VM's writes are captured in an overlay, so there is
+	// no need to pause the VM during the transfer.
+	volSourceArgs.AllowInconsistent = true
+
+	args := instance.LiveMigrateSendArgs{
+		StateConn: &shared.WebsocketIO{Conn: s.criuConn},
+		TransferVolume: func() error {
+			if sharedStorage {
+				return nil
+			}
+
+			return pool.MigrateInstance(s.instance, &shared.WebsocketIO{Conn: s.fsConn}, volSourceArgs, migrateOp)
+		},
+		TargetReady: func() error {
+			msg := migration.MigrationControl{}
+			err := migration.ProtoRecv(s.criuConn, &msg)
+			if err != nil {
+				return fmt.Errorf("Failed waiting for target to be ready: %w", err)
+			}
+
+			if !msg.GetSuccess() {
+				return fmt.Errorf(msg.GetMessage())
+			}
+
+			return nil
+		},
+		TargetResumed: func() error {
+			msg := migration.MigrationControl{}
+			err := s.recv(&msg)
+			if err != nil {
+				return fmt.Errorf("Failed waiting for target to resume: %w", err)
+			}
+
+			if !msg.GetSuccess() {
+				return fmt.Errorf(msg.GetMessage())
+			}
+
+			return nil
+		},
+	}
+
+	if !sharedStorage {
+		args.DiskConn = &shared.WebsocketIO{Conn: s.fsConn}
+	}
+
+	return vm.MigrateSendLive(args)
+}
+
 func newMigrationSink(args *MigrationSinkArgs) (*migrationSink, error) {
 	sink := migrationSink{
-		src:     migrationFields{instance: args.Instance, instanceOnly: args.InstanceOnly},
-		dest:    migrationFields{instanceOnly: args.InstanceOnly},
-		url:     args.Url,
-		dialer:  args.Dialer,
-		push:    args.Push,
-		refresh: args.Refresh,
+		src:         migrationFields{instance: args.Instance, instanceOnly: args.InstanceOnly},
+		dest:        migrationFields{instanceOnly: args.InstanceOnly},
+		url:         args.Url,
+		dialer:      args.Dialer,
+		push:        args.Push,
+		refresh:     args.Refresh,
+		clusterMove: args.ClusterMove,
 	}
 
 	if sink.push {
@@ -825,6 +912,16 @@ func (c *migrationSink) Do(state *state.State, revert *revert.Reverter, migrateO
 		}
 		defer c.src.disconnect()
 
+		// The source doesn't wait for the VM state connection as older targets don't use it, so connect it
+		// before the filesystem connection to ensure it is available once the source starts.
+		if c.src.live && c.src.instance.Type() == instancetype.VM && c.src.criuSecret != "" {
+			c.src.criuConn, err = c.connectWithSecret(c.src.criuSecret)
+			if err != nil {
+				c.src.sendControl(err)
+				return err
+			}
+		}
+
 		c.src.fsConn, err = c.connectWithSecret(c.src.fsSecret)
 		if err != nil {
 			c.src.sendControl(err)
@@ -876,6 +973,18 @@ func (c *migrationSink) Do(state *state.State, revert *revert.Reverter, migrateO
 		}
 	}
 
+	var criuConn *websocket.Conn
+	if c.push {
+		criuConn = c.dest.criuConn
+	} else {
+		criuConn = c.src.criuConn
+	}
+
+	// Live migrate VMs by streaming their state if offered by the source.
+	if live && c.src.instance.Type() == instancetype.VM && offerHeader.GetCriu() == migration.CRIUType_VM_QEMU && criuConn != nil {
+		criuType = migration.CRIUType_VM_QEMU.Enum()
+	}
+
 	// The function that will be executed to receive the sender's migration data.
 	var myTarget func(conn *websocket.Conn, op *operations.Operation, args MigrationSinkArgs) error
 
@@ -927,6 +1036,27 @@ func (c *migrationSink) Do(state *state.State, revert *revert.Reverter, migrateO
 
 		// A zero length Snapshots slice indicates volume only migration in
 		// VolumeTargetArgs. So if VolumeOnly was requested, do not populate them.
+		// When moving within a cluster the instance and snapshot records already exist, so only the volumes
+		// are created, or merely made available on this member if on shared storage.
+		if c.clusterMove {
+			if pool.Driver().Info().LiveMigrationShared {
+				return pool.ImportInstance(args.Instance, nil, op)
+			}
+
+			for _, snap := range args.Snapshots {
+				volTargetArgs.Snapshots = append(volTargetArgs.Snapshots, *snap.Name)
+			}
+
+			err = pool.CreateInstanceFromMigration(args.Instance, &shared.WebsocketIO{Conn: conn}, volTargetArgs, op)
+			if err != nil {
+				return err
+			}
+
+			revert.Add(func() { instanceDeleteLocalVolumes(state, args.Instance, op) })
+
+			return nil
+		}
+
 		if !args.VolumeOnly {
 			volTargetArgs.Snapshots = make([]string, 0, len(args.Snapshots))
 			for _, snap := range args.Snapshots {
@@ -1120,13 +1250,6 @@ func (c *migrationSink) Do(state *state.State, revert *revert.Reverter, migrateO
 
 			defer os.RemoveAll(imagesDir)
 
-			var criuConn *websocket.Conn
-			if c.push {
-				criuConn = c.dest.criuConn
-			} else {
-				criuConn = c.src.criuConn
-			}
-
 			sync := &migration.MigrationSync{
 				FinalPreDump: proto.Bool(false),
 			}
@@ -1197,7 +1320,37 @@ func (c *migrationSink) Do(state *state.State, revert *revert.Reverter, migrateO
 				}
 			}
 
-			if c.src.instance.Type() == instancetype.VM {
+			if c.src.instance.Type() == instancetype.VM && respHeader.GetCriu() == migration.CRIUType_VM_QEMU {
+				vm, ok := c.src.instance.(instance.VM)
+				if !ok {
+					restore <- fmt.Errorf("Instance doesn't support live migration")
+					return
+				}
+
+				args := instance.LiveMigrateReceiveArgs{
+					StateConn: &shared.WebsocketIO{Conn: criuConn},
+					Ready: func() error {
+						return migration.ProtoSend(criuConn, &migration.MigrationControl{Success: proto.Bool(true)})
+					},
+				}
+
+				if !c.clusterMove || !pool.Driver().Info().LiveMigrationShared {
+					var fsConn *websocket.Conn
+					if c.push {
+						fsConn = c.dest.fsConn
+					} else {
+						fsConn = c.src.fsConn
+					}
+
+					args.DiskConn = &shared.WebsocketIO{Conn: fsConn}
+				}
+
+				err = vm.MigrateReceiveLive(args)
+				if err != nil {
+					restore <- err
+					return
+				}
+			} else if c.src.instance.Type() == instancetype.VM {
 				err = c.src.instance.Migrate(nil)
 				if err != nil {
 					restore <- err
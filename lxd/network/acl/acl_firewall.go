@@ -2,6 +2,7 @@ package acl
 
 import (
 	"fmt"
+	"net"
 
 	firewallDrivers "github.com/lxc/lxd/lxd/firewall/drivers"
 	"github.com/lxc/lxd/lxd/state"
@@ -10,8 +11,60 @@ import (
 	"github.com/lxc/lxd/shared/logger"
 )
 
+// FirewallNICTypes lists the types of NICs not connected to a network whose ACLs are applied to the firewall.
+var FirewallNICTypes = []string{"routed", "ipvlan", "macvlan"}
+
 // FirewallApplyACLRules applies ACL rules to network firewall.
 func FirewallApplyACLRules(s *state.State, logger logger.Logger, aclProjectName string, aclNet NetworkACLUsage) error {
+	rules, err := firewallACLRules(s, aclProjectName, aclNet.Name, aclNet.Config)
+	if err != nil {
+		return fmt.Errorf("Failed converting ACL rules for network %q: %w", aclNet.Name, err)
+	}
+
+	return s.Firewall.NetworkApplyACLRules(aclNet.Name, rules)
+}
+
+// FirewallApplyNICACLRules applies the ACL rules of a routed, ipvlan or macvlan instance NIC to the firewall.
+// Routed NICs are matched by their host side interface, IPVLAN NICs by their addresses and MACVLAN NICs by their
+// MAC address on the parent interface recorded in their volatile config.
+func FirewallApplyNICACLRules(s *state.State, aclProjectName string, instProjectName string, instName string, nicName string, nicConfig map[string]string, nicVolatile map[string]string) error {
+	hostName := nicVolatile["host_name"]
+
+	// The host side interface name is short enough to be used as log prefix.
+	rules, err := firewallACLRules(s, aclProjectName, hostName, nicConfig)
+	if err != nil {
+		return fmt.Errorf("Failed converting ACL rules for instance NIC %q: %w", nicName, err)
+	}
+
+	switch nicConfig["nictype"] {
+	case "macvlan":
+		// The MAC address is only recorded in the volatile config when it was generated.
+		hwAddr := nicConfig["hwaddr"]
+		if hwAddr == "" {
+			hwAddr = nicVolatile["hwaddr"]
+		}
+
+		return s.Firewall.InstanceSetupParentACLRules(instProjectName, instName, nicName, nicVolatile["last_state.parent"], hwAddr, rules)
+	case "ipvlan":
+		var ipv4Nets []*net.IPNet
+		for _, addr := range shared.SplitNTrimSpace(nicConfig["ipv4.address"], ",", -1, true) {
+			ipv4Nets = append(ipv4Nets, &net.IPNet{IP: net.ParseIP(addr), Mask: net.CIDRMask(32, 32)})
+		}
+
+		var ipv6Nets []*net.IPNet
+		for _, addr := range shared.SplitNTrimSpace(nicConfig["ipv6.address"], ",", -1, true) {
+			ipv6Nets = append(ipv6Nets, &net.IPNet{IP: net.ParseIP(addr), Mask: net.CIDRMask(128, 128)})
+		}
+
+		return s.Firewall.InstanceSetupACLRules(instProjectName, instName, nicName, "", ipv4Nets, ipv6Nets, rules)
+	}
+
+	return s.Firewall.InstanceSetupACLRules(instProjectName, instName, nicName, hostName, nil, nil, rules)
+}
+
+// firewallACLRules converts the ACLs specified in the security.acls setting of a network or NIC config into
+// firewall ACL rules, followed by the default rules for each direction.
+func firewallACLRules(s *state.State, aclProjectName string, logPrefix string, config map[string]string) ([]firewallDrivers.ACLRule, error) {
 	var dropRules []firewallDrivers.ACLRule
 	var rejectRules []firewallDrivers.ACLRule
 	var allowRules []firewallDrivers.ACLRule
@@ -56,23 +109,21 @@ func FirewallApplyACLRules(s *state.State, logger logger.Logger, aclProjectName
 		return nil
 	}
 
-	logPrefix := aclNet.Name
-
-	// Load ACLs specified by network.
-	for _, aclName := range shared.SplitNTrimSpace(aclNet.Config["security.acls"], ",", -1, true) {
+	// Load ACLs specified by network or NIC.
+	for _, aclName := range shared.SplitNTrimSpace(config["security.acls"], ",", -1, true) {
 		_, aclInfo, err := s.Cluster.GetNetworkACL(aclProjectName, aclName)
 		if err != nil {
-			return fmt.Errorf("Failed loading ACL %q for network %q: %w", aclName, aclNet.Name, err)
+			return nil, fmt.Errorf("Failed loading ACL %q: %w", aclName, err)
 		}
 
 		err = convertACLRules("ingress", logPrefix, aclInfo.Ingress...)
 		if err != nil {
-			return fmt.Errorf("Failed converting ACL %q ingress rules for network %q: %w", aclInfo.Name, aclNet.Name, err)
+			return nil, fmt.Errorf("Failed converting ACL %q ingress rules: %w", aclInfo.Name, err)
 		}
 
 		err = convertACLRules("egress", logPrefix, aclInfo.Egress...)
 		if err != nil {
-			return fmt.Errorf("Failed converting ACL %q egress rules for network %q: %w", aclInfo.Name, aclNet.Name, err)
+			return nil, fmt.Errorf("Failed converting ACL %q egress rules: %w", aclInfo.Name, err)
 		}
 	}
 
@@ -81,9 +132,9 @@ func FirewallApplyACLRules(s *state.State, logger logger.Logger, aclProjectName
 	rules = append(rules, rejectRules...)
 	rules = append(rules, allowRules...)
 
-	// Add the automatic default ACL rule for the network.
-	egressAction, egressLogged := firewallACLDefaults(aclNet.Config, "egress")
-	ingressAction, ingressLogged := firewallACLDefaults(aclNet.Config, "ingress")
+	// Add the automatic default ACL rule for the network or NIC.
+	egressAction, egressLogged := firewallACLDefaults(config, "egress")
+	ingressAction, ingressLogged := firewallACLDefaults(config, "ingress")
 
 	rules = append(rules, firewallDrivers.ACLRule{
 		Direction: "egress",
@@ -99,12 +150,12 @@ func FirewallApplyACLRules(s *state.State, logger logger.Logger, aclProjectName
 		LogName:   fmt.Sprintf("%s-ingress", logPrefix),
 	})
 
-	return s.Firewall.NetworkApplyACLRules(aclNet.Name, rules)
+	return rules, nil
 }
 
 // firewallACLDefaults returns the action and logging mode to use for the specified direction's default rule.
 // If the security.acls.default.{in,e}gress.action or security.acls.default.{in,e}gress.logged settings are not
-// specified in the network config, then it returns "reject" and false respectively.
+// specified in the network or NIC config, then it returns "reject" and false respectively.
 func firewallACLDefaults(netConfig map[string]string, direction string) (string, bool) {
 	defaults := map[string]string{
 		fmt.Sprintf("security.acls.default.%s.action", direction): "reject",
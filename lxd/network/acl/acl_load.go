@@ -2,6 +2,8 @@ package acl
 
 import (
 	"fmt"
+	"net"
+	"strings"
 
 	"github.com/lxc/lxd/lxd/db"
 	deviceConfig "github.com/lxc/lxd/lxd/device/config"
@@ -75,8 +77,9 @@ func Exists(s *state.State, projectName string, name ...string) error {
 	return nil
 }
 
-// UsedBy finds all networks, profiles and instance NICs that use any of the specified ACLs and executes usageFunc
-// once for each resource using one or more of the ACLs with info about the resource and matched ACLs being used.
+// UsedBy finds all networks, network peers, profiles and instance NICs that use any of the specified ACLs and
+// executes usageFunc once for each resource using one or more of the ACLs with info about the resource and matched
+// ACLs being used.
 func UsedBy(s *state.State, aclProjectName string, usageFunc func(matchedACLNames []string, usageType any, nicName string, nicConfig map[string]string) error, matchACLNames ...string) error {
 	if len(matchACLNames) <= 0 {
 		return nil
@@ -89,7 +92,7 @@ func UsedBy(s *state.State, aclProjectName string, usageFunc func(matchedACLName
 	}
 
 	for _, networkName := range networkNames {
-		_, network, _, err := s.Cluster.GetNetworkInAnyState(aclProjectName, networkName)
+		networkID, network, _, err := s.Cluster.GetNetworkInAnyState(aclProjectName, networkName)
 		if err != nil {
 			return fmt.Errorf("Failed to get network config for %q: %w", networkName, err)
 		}
@@ -109,6 +112,34 @@ func UsedBy(s *state.State, aclProjectName string, usageFunc func(matchedACLName
 				return err
 			}
 		}
+
+		// Find the network's peers using the ACLs to control the routes they leak.
+		if network.Type != "ovn" {
+			continue
+		}
+
+		peers, err := s.Cluster.GetNetworkPeers(networkID)
+		if err != nil {
+			return fmt.Errorf("Failed loading peers for network %q: %w", networkName, err)
+		}
+
+		for _, peer := range peers {
+			peerACLNames := shared.SplitNTrimSpace(peer.Config["security.acls"], ",", -1, true)
+			matchedACLNames := []string{}
+			for _, peerACLName := range peerACLNames {
+				if shared.StringInSlice(peerACLName, matchACLNames) {
+					matchedACLNames = append(matchedACLNames, peerACLName)
+				}
+			}
+
+			if len(matchedACLNames) > 0 {
+				// Call usageFunc with a list of matched ACLs and info about the network peer.
+				err := usageFunc(matchedACLNames, db.NetworkPeer{NetworkName: network.Name, PeerName: peer.Name}, "", nil)
+				if err != nil {
+					return err
+				}
+			}
+		}
 	}
 
 	// Look for profiles. Next cheapest to do.
@@ -232,8 +263,8 @@ func UsedBy(s *state.State, aclProjectName string, usageFunc func(matchedACLName
 func isInUseByDevice(d deviceConfig.Device, matchACLNames ...string) []string {
 	matchedACLNames := []string{}
 
-	// Only NICs linked to managed networks can use network ACLs.
-	if d["type"] != "nic" || d["network"] == "" {
+	// Only NICs linked to managed networks or whose ACLs are applied to the firewall can use network ACLs.
+	if d["type"] != "nic" || (d["network"] == "" && !shared.StringInSlice(d["nictype"], FirewallNICTypes)) {
 		return matchedACLNames
 	}
 
@@ -262,6 +293,11 @@ func NetworkUsage(s *state.State, aclProjectName string, aclNames []string, aclN
 	err := UsedBy(s, aclProjectName, func(matchedACLNames []string, usageType any, _ string, nicConfig map[string]string) error {
 		switch u := usageType.(type) {
 		case db.Instance, db.Profile:
+			// NICs not linked to a network have their ACLs applied directly (see NICUsage).
+			if nicConfig["network"] == "" {
+				return nil
+			}
+
 			networkID, network, _, err := s.Cluster.GetNetworkInAnyState(aclProjectName, nicConfig["network"])
 			if err != nil {
 				return fmt.Errorf("Failed to load network %q: %w", nicConfig["network"], err)
@@ -295,6 +331,8 @@ func NetworkUsage(s *state.State, aclProjectName string, aclNames []string, aclN
 			}
 		case *api.NetworkACL:
 			return nil // Nothing to do for ACL rules referencing us.
+		case db.NetworkPeer:
+			return nil // Network peers only use the ACL rules to control the routes they leak.
 		default:
 			return fmt.Errorf("Unrecognised usage type %T", u)
 		}
@@ -307,3 +345,109 @@ func NetworkUsage(s *state.State, aclProjectName string, aclNames []string, aclN
 
 	return nil
 }
+
+// IngressAllowedSubnets returns the destination subnets of the enabled ingress allow rules in the specified ACLs.
+// Single IP destinations are returned as host subnets, IP ranges and named subjects other than @internal are
+// ignored. Returns true if any of the rules allows all internal destinations (either because it has no destination
+// or because it uses the @internal subject), in which case the returned subnets should not be used as a filter.
+func IngressAllowedSubnets(s *state.State, aclProjectName string, aclNames []string) ([]net.IPNet, bool, error) {
+	var subnets []net.IPNet
+
+	for _, aclName := range aclNames {
+		_, aclInfo, err := s.Cluster.GetNetworkACL(aclProjectName, aclName)
+		if err != nil {
+			return nil, false, fmt.Errorf("Failed loading network ACL %q: %w", aclName, err)
+		}
+
+		for _, rule := range aclInfo.Ingress {
+			if rule.State != "enabled" || rule.Action != "allow" {
+				continue
+			}
+
+			if rule.Destination == "" {
+				return nil, true, nil
+			}
+
+			for _, subject := range shared.SplitNTrimSpace(rule.Destination, ",", -1, false) {
+				if shared.StringInSlice(subject, ruleSubjectInternalAliases) {
+					return nil, true, nil
+				}
+
+				if strings.Contains(subject, "/") {
+					_, subnet, err := net.ParseCIDR(subject)
+					if err == nil {
+						subnets = append(subnets, *subnet)
+					}
+
+					continue
+				}
+
+				ip := net.ParseIP(subject)
+				if ip == nil {
+					continue // IP ranges and named subjects can't be used as routes.
+				}
+
+				bits := 128
+				if ip.To4() != nil {
+					ip = ip.To4()
+					bits = 32
+				}
+
+				subnets = append(subnets, net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			}
+		}
+	}
+
+	return subnets, false, nil
+}
+
+// NICACLUsage info about a running instance NIC not linked to a network and what ACL it uses.
+type NICACLUsage struct {
+	InstanceProject string
+	InstanceName    string
+	Node            string
+	Name            string
+	Config          map[string]string
+	Volatile        map[string]string
+}
+
+// NICUsage returns the running instance NICs not linked to a network that are using any of the specified ACLs.
+func NICUsage(s *state.State, aclProjectName string, aclNames []string) ([]NICACLUsage, error) {
+	aclNICs := []NICACLUsage{}
+
+	err := UsedBy(s, aclProjectName, func(_ []string, usageType any, nicName string, nicConfig map[string]string) error {
+		inst, ok := usageType.(db.Instance)
+		if !ok || nicConfig["network"] != "" {
+			return nil
+		}
+
+		volatilePrefix := fmt.Sprintf("volatile.%s.", nicName)
+		nicVolatile := map[string]string{}
+		for key, value := range inst.Config {
+			if strings.HasPrefix(key, volatilePrefix) {
+				nicVolatile[strings.TrimPrefix(key, volatilePrefix)] = value
+			}
+		}
+
+		// The host name is only set in the volatile config of the NIC while it is running.
+		if nicVolatile["host_name"] == "" {
+			return nil
+		}
+
+		aclNICs = append(aclNICs, NICACLUsage{
+			InstanceProject: inst.Project,
+			InstanceName:    inst.Name,
+			Node:            inst.Node,
+			Name:            nicName,
+			Config:          nicConfig,
+			Volatile:        nicVolatile,
+		})
+
+		return nil
+	}, aclNames...)
+	if err != nil {
+		return nil, err
+	}
+
+	return aclNICs, nil
+}
@@ -72,6 +72,11 @@ func OVNIntSwitchRouterPortName(networkID int64) openvswitch.OVNSwitchPort {
 	return openvswitch.OVNSwitchPort(fmt.Sprintf("%s-lsp-router", OVNIntSwitchName(networkID)))
 }
 
+// OVNIntSwitchInstancePortName returns OVN logical internal switch port name of an instance NIC for a Network ID.
+func OVNIntSwitchInstancePortName(networkID int64, instanceUUID string, deviceName string) openvswitch.OVNSwitchPort {
+	return openvswitch.OVNSwitchPort(fmt.Sprintf("%s-instance-%s-%s", OVNNetworkPrefix(networkID), instanceUUID, deviceName))
+}
+
 // OVNEnsureACLs ensures that the requested aclNames exist as OVN port groups (creates & applies ACL rules if not),
 // If reapplyRules is true then the current ACL rules in the database are applied to the existing port groups
 // rather than just new ones. Any ACLs referenced in the requested ACLs rules are also created as empty OVN port
@@ -822,6 +827,11 @@ func OVNPortGroupDeleteIfUnused(s *state.State, l logger.Logger, client *openvsw
 				return nil
 			}
 
+			// NICs not linked to a network don't use OVN.
+			if nicConfig["network"] == "" {
+				return nil
+			}
+
 			netID, network, _, err := s.Cluster.GetNetworkInAnyState(aclProjectName, nicConfig["network"])
 			if err != nil {
 				return fmt.Errorf("Failed to load network %q: %w", nicConfig["network"], err)
@@ -878,6 +888,11 @@ func OVNPortGroupDeleteIfUnused(s *state.State, l logger.Logger, client *openvsw
 				return nil
 			}
 
+			// NICs not linked to a network don't use OVN.
+			if nicConfig["network"] == "" {
+				return nil
+			}
+
 			netID, network, _, err := s.Cluster.GetNetworkInAnyState(aclProjectName, nicConfig["network"])
 			if err != nil {
 				return fmt.Errorf("Failed to load network %q: %w", nicConfig["network"], err)
@@ -904,6 +919,8 @@ func OVNPortGroupDeleteIfUnused(s *state.State, l logger.Logger, client *openvsw
 					aclUsedACLS[matchedACLName] = append(aclUsedACLS[matchedACLName], u.Name)
 				}
 			}
+		case db.NetworkPeer:
+			return nil // Network peers don't use the ACL port groups.
 		default:
 			return fmt.Errorf("Unrecognised usage type %T", u)
 		}
@@ -150,6 +150,13 @@ func (d *common) usedBy(firstOnly bool) ([]string, error) {
 				uri += fmt.Sprintf("?project=%s", d.projectName)
 			}
 
+			usedBy = append(usedBy, uri)
+		case db.NetworkPeer:
+			uri := fmt.Sprintf("/%s/networks/%s/peers/%s", version.APIVersion, u.NetworkName, u.PeerName)
+			if d.projectName != project.Default {
+				uri += fmt.Sprintf("?project=%s", d.projectName)
+			}
+
 			usedBy = append(usedBy, uri)
 		default:
 			return fmt.Errorf("Unrecognised usage type %T", u)
@@ -628,6 +635,35 @@ func (d *common) Update(config *api.NetworkACLPut, clientType request.ClientType
 		}
 	}
 
+	// Get a list of running instance NICs not linked to a network that are using this ACL.
+	aclNICs, err := NICUsage(d.state, d.projectName, []string{d.info.Name})
+	if err != nil {
+		return fmt.Errorf("Failed getting ACL instance NIC usage: %w", err)
+	}
+
+	if len(aclNICs) > 0 {
+		var localNode string
+		err = d.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
+			localNode, err = tx.GetLocalNodeName()
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("Failed getting local cluster member name: %w", err)
+		}
+
+		// Apply ACL changes to instance NICs on this member.
+		for _, aclNIC := range aclNICs {
+			if aclNIC.Node != localNode {
+				continue
+			}
+
+			err = FirewallApplyNICACLRules(d.state, d.projectName, aclNIC.InstanceProject, aclNIC.InstanceName, aclNIC.Name, aclNIC.Config, aclNIC.Volatile)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	// If there are affected OVN networks, then apply the changes, but only if the request type is normal.
 	// This way we won't apply the same changes multiple times for each LXD cluster member.
 	if len(aclOVNNets) > 0 && clientType == request.ClientTypeNormal {
@@ -662,8 +698,8 @@ func (d *common) Update(config *api.NetworkACLPut, clientType request.ClientType
 		}
 	}
 
-	// Apply ACL changes to non-OVN networks on cluster members.
-	if clientType == request.ClientTypeNormal && len(aclNets) > 0 {
+	// Apply ACL changes to non-OVN networks and instance NICs on cluster members.
+	if clientType == request.ClientTypeNormal && (len(aclNets) > 0 || len(aclNICs) > 0) {
 		// Notify all other nodes to update the network if no target specified.
 		notifier, err := cluster.NewNotifier(d.state, d.state.Endpoints.NetworkCert(), d.state.ServerCert(), cluster.NotifyAll)
 		if err != nil {
@@ -20,6 +20,7 @@ import (
 
 	"github.com/lxc/lxd/client"
 	"github.com/lxc/lxd/lxd/apparmor"
+	"github.com/lxc/lxd/lxd/bgp"
 	"github.com/lxc/lxd/lxd/cluster"
 	"github.com/lxc/lxd/lxd/cluster/request"
 	"github.com/lxc/lxd/lxd/daemon"
@@ -34,6 +35,7 @@ import (
 	"github.com/lxc/lxd/lxd/ip"
 	"github.com/lxc/lxd/lxd/network/acl"
 	"github.com/lxc/lxd/lxd/network/openvswitch"
+	"github.com/lxc/lxd/lxd/network/qos"
 	"github.com/lxc/lxd/lxd/node"
 	"github.com/lxc/lxd/lxd/project"
 	"github.com/lxc/lxd/lxd/revert"
@@ -74,6 +76,8 @@ func (n *bridge) DBType() db.NetworkType {
 func (n *bridge) Info() Info {
 	info := n.common.Info()
 	info.AddressForwards = true
+	info.LoadBalancers = true
+	info.AddressReservations = true
 
 	return info
 }
@@ -92,6 +96,11 @@ func (n *bridge) checkClusterWideMACSafe(config map[string]string) error {
 		return fmt.Errorf(`Cannot use static "bridge.hwaddr" MAC address in fan mode`)
 	}
 
+	// EVPN mode connects the bridges of all nodes to the same network segment.
+	if config["bridge.mode"] == "evpn" {
+		return fmt.Errorf(`Cannot use static "bridge.hwaddr" MAC address in evpn mode`)
+	}
+
 	// We can't be sure that multiple clustered nodes aren't connected to the same network segment so don't
 	// use a static MAC address for the bridge interface to avoid introducing a MAC conflict.
 	if config["bridge.external_interfaces"] != "" && config["ipv4.address"] == "none" && config["ipv6.address"] == "none" {
@@ -217,7 +226,16 @@ func (n *bridge) Validate(config map[string]string) error {
 		}),
 		"bridge.hwaddr": validate.Optional(validate.IsNetworkMAC),
 		"bridge.mtu":    validate.Optional(validate.IsNetworkMTU),
-		"bridge.mode":   validate.Optional(validate.IsOneOf("standard", "fan")),
+		"bridge.mode":   validate.Optional(validate.IsOneOf("standard", "fan", "evpn")),
+
+		"evpn.vni":       validate.Optional(validate.IsInRange(1, 16777215)),
+		"evpn.local":     validate.Optional(validate.IsNetworkAddressV4),
+		"evpn.port":      validate.Optional(validate.IsNetworkPort),
+		"evpn.interface": validate.Optional(validate.IsInterfaceName),
+		"evpn.route_target": validate.Optional(func(value string) error {
+			_, _, err := bgp.ParseRouteTarget(value)
+			return err
+		}),
 
 		"fan.overlay_subnet": validate.Optional(validate.IsNetworkV4),
 		"fan.underlay_subnet": validate.Optional(func(value string) error {
@@ -280,6 +298,7 @@ func (n *bridge) Validate(config map[string]string) error {
 		"security.acls.default.egress.action":  validate.Optional(validate.IsOneOf(acl.ValidActions...)),
 		"security.acls.default.ingress.logged": validate.Optional(validate.IsBool),
 		"security.acls.default.egress.logged":  validate.Optional(validate.IsBool),
+		"qos":                                  validate.IsAny,
 	}
 
 	// Add dynamic validation rules.
@@ -344,6 +363,21 @@ func (n *bridge) Validate(config map[string]string) error {
 		return fmt.Errorf("Network name too long to use with the FAN (must be 11 characters or less)")
 	}
 
+	// Validate network name and configuration when used in EVPN mode.
+	if bridgeMode == "evpn" {
+		if len(n.name) > 10 {
+			return fmt.Errorf("Network name too long to use with EVPN (must be 10 characters or less)")
+		}
+
+		if config["bridge.driver"] == "openvswitch" {
+			return fmt.Errorf("EVPN mode can't be used with the openvswitch bridge driver")
+		}
+
+		if config["evpn.vni"] == "" {
+			return fmt.Errorf(`"evpn.vni" must be set when in 'evpn' mode`)
+		}
+	}
+
 	for k, v := range config {
 		key := k
 		// Bridge mode checks
@@ -359,6 +393,10 @@ func (n *bridge) Validate(config map[string]string) error {
 			return fmt.Errorf("FAN configuration may only be set when in 'fan' mode")
 		}
 
+		if bridgeMode != "evpn" && strings.HasPrefix(key, "evpn.") && v != "" {
+			return fmt.Errorf("EVPN configuration may only be set when in 'evpn' mode")
+		}
+
 		// MTU checks
 		if key == "bridge.mtu" && v != "" {
 			mtu, err := strconv.ParseInt(v, 10, 64)
@@ -475,6 +513,14 @@ func (n *bridge) Validate(config map[string]string) error {
 		}
 	}
 
+	// Check QoS policy exists.
+	if config["qos"] != "" {
+		err = qos.Exists(n.state, n.Project(), config["qos"])
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -673,6 +719,8 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		} else {
 			mtu = "1450"
 		}
+	} else if n.config["bridge.mode"] == "evpn" {
+		mtu = "1450"
 	}
 
 	// Attempt to add a dummy device to the bridge to force the MTU.
@@ -1474,6 +1522,29 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		}
 	}
 
+	// Clear the EVPN segment if no longer in EVPN mode or using a different VNI.
+	if oldConfig != nil && oldConfig["bridge.mode"] == "evpn" && (n.config["bridge.mode"] != "evpn" || n.config["evpn.vni"] != oldConfig["evpn.vni"]) {
+		err = n.evpnClear(oldConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Configure the EVPN segment.
+	if n.config["bridge.mode"] == "evpn" {
+		err = n.evpnSetup(mtu)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() { n.evpnClear(n.config) })
+
+		err = bridgeLink.SetUp()
+		if err != nil {
+			return err
+		}
+	}
+
 	// Generate and load apparmor profiles.
 	err = apparmor.NetworkLoad(n.state.OS, n)
 	if err != nil {
@@ -1669,6 +1740,12 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		return err
 	}
 
+	// Setup network load balancers.
+	err = n.loadBalancerSetupFirewall()
+	if err != nil {
+		return err
+	}
+
 	// Setup BGP.
 	err = n.bgpSetup(oldConfig)
 	if err != nil {
@@ -1693,6 +1770,14 @@ func (n *bridge) Stop() error {
 		return err
 	}
 
+	// Clear the EVPN segment.
+	if n.config["bridge.mode"] == "evpn" {
+		err = n.evpnClear(n.config)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Destroy the bridge interface
 	if n.config["bridge.driver"] == "openvswitch" {
 		ovs := openvswitch.NewOVS()
@@ -1850,10 +1935,69 @@ func (n *bridge) Update(newNetwork api.NetworkPut, targetNode string, clientType
 		}
 	}
 
+	// Apply the new QoS policy to the running instance NICs that inherit it.
+	if shared.StringInSlice("qos", changedKeys) {
+		err = n.applyInstanceNICQoS()
+		if err != nil {
+			return err
+		}
+	}
+
 	revert.Success()
 	return nil
 }
 
+// applyInstanceNICQoS applies the network's QoS policy to the running instance NICs on this member that don't set
+// their own QoS policy or limits.
+func (n *bridge) applyInstanceNICQoS() error {
+	var err error
+	var localNode string
+	err = n.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		localNode, err = tx.GetLocalNodeName()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed getting local cluster member name: %w", err)
+	}
+
+	var limits *qos.Limits
+	if n.config["qos"] != "" {
+		policy, err := qos.LoadByName(n.state, n.Project(), n.config["qos"])
+		if err != nil {
+			return fmt.Errorf("Failed loading network QoS policy %q: %w", n.config["qos"], err)
+		}
+
+		limits, err = policy.Limits()
+		if err != nil {
+			return err
+		}
+	} else {
+		// No policy, clear the limits of the NICs.
+		limits, err = qos.ParseLimits(nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return usedByInstanceDevices(n.state, n.Project(), n.name, func(inst db.Instance, nicName string, nicConfig map[string]string) error {
+		if inst.Node != localNode || nicConfig["network"] == "" || nicConfig["qos"] != "" || qos.HasNICLimits(nicConfig) {
+			return nil
+		}
+
+		hostName := inst.Config[fmt.Sprintf("volatile.%s.host_name", nicName)]
+		if hostName == "" || !InterfaceExists(hostName) {
+			return nil // Not running.
+		}
+
+		err := qos.HostInterfaceSetup(hostName, limits, false)
+		if err != nil {
+			return fmt.Errorf("Failed applying QoS policy to instance %q NIC %q: %w", inst.Name, nicName, err)
+		}
+
+		return nil
+	})
+}
+
 func (n *bridge) spawnForkDNS(listenAddress string) error {
 	// Setup the dnsmasq domain
 	dnsDomain := n.config["dns.domain"]
@@ -1900,9 +2044,17 @@ func (n *bridge) spawnForkDNS(listenAddress string) error {
 	return nil
 }
 
-// HandleHeartbeat refreshes forkdns servers. Retrieves the IPv4 address of each cluster node (excluding ourselves)
-// for this network. It then updates the forkdns server list file if there are changes.
+// HandleHeartbeat refreshes the EVPN peers and forkdns servers. Retrieves the IPv4 address of each cluster node
+// (excluding ourselves) for this network. It then updates the forkdns server list file if there are changes.
 func (n *bridge) HandleHeartbeat(heartbeatData *cluster.APIHeartbeat) error {
+	// Refresh the EVPN peers in case cluster members were added or removed.
+	if n.config["bridge.mode"] == "evpn" && n.isRunning() {
+		err := n.evpnSetupPeers()
+		if err != nil {
+			return fmt.Errorf("Failed refreshing EVPN peers: %w", err)
+		}
+	}
+
 	// Make sure forkdns has been setup.
 	if !shared.PathExists(shared.VarPath("networks", n.name, "forkdns.pid")) {
 		return nil
@@ -2460,6 +2612,7 @@ func (n *bridge) getExternalSubnetInUse() ([]externalSubnetUsage, error) {
 	var err error
 	var projectNetworks map[string]map[int64]api.Network
 	var projectNetworksForwardsOnUplink map[string]map[int64][]string
+	var projectNetworksLoadBalancersOnUplink map[string]map[int64][]string
 
 	err = n.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
 		// Get all managed networks across all projects.
@@ -2474,6 +2627,12 @@ func (n *bridge) getExternalSubnetInUse() ([]externalSubnetUsage, error) {
 			return fmt.Errorf("Failed loading network forward listen addresses: %w", err)
 		}
 
+		// Get all network load balancer listen addresses for load balancers assigned to this specific cluster member.
+		projectNetworksLoadBalancersOnUplink, err = tx.GetProjectNetworkLoadBalancerListenAddressesOnMember()
+		if err != nil {
+			return fmt.Errorf("Failed loading network load balancer listen addresses: %w", err)
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -2521,6 +2680,28 @@ func (n *bridge) getExternalSubnetInUse() ([]externalSubnetUsage, error) {
 		}
 	}
 
+	// Add load balancer listen addresses to this list.
+	for projectName, networks := range projectNetworksLoadBalancersOnUplink {
+		for networkID, listenAddresses := range networks {
+			for _, listenAddress := range listenAddresses {
+				// Convert listen address to subnet.
+				listenAddressNet, err := ParseIPToNet(listenAddress)
+				if err != nil {
+					return nil, fmt.Errorf("Invalid existing load balancer listen address %q", listenAddress)
+				}
+
+				// Create an externalSubnetUsage for the listen address by using the network ID
+				// of the listen address to retrieve the already loaded network name from the
+				// projectNetworks map.
+				externalSubnets = append(externalSubnets, externalSubnetUsage{
+					subnet:         *listenAddressNet,
+					networkProject: projectName,
+					networkName:    projectNetworks[projectName][networkID].Name,
+				})
+			}
+		}
+	}
+
 	return externalSubnets, nil
 }
 
@@ -2534,6 +2715,12 @@ func (n *bridge) ForwardCreate(forward api.NetworkForwardsPost, clientType reque
 		return api.StatusErrorf(http.StatusConflict, "A forward for that listen address already exists")
 	}
 
+	// Check if there is an existing load balancer using the same listen address.
+	_, _, err = n.state.Cluster.GetNetworkLoadBalancer(n.ID(), memberSpecific, forward.ListenAddress)
+	if err == nil {
+		return api.StatusErrorf(http.StatusConflict, "A load balancer for that listen address already exists")
+	}
+
 	// Convert listen address to subnet so we can check its valid and can be used.
 	listenAddressNet, err := ParseIPToNet(forward.ListenAddress)
 	if err != nil {
@@ -2590,93 +2777,115 @@ func (n *bridge) ForwardCreate(forward api.NetworkForwardsPost, clientType reque
 	}
 
 	// Check if hairpin mode needs to be enabled on active NIC bridge ports.
-	if n.config["bridge.driver"] != "openvswitch" {
-		brNetfilterEnabled := false
-		for _, ipVersion := range []uint{4, 6} {
-			if BridgeNetfilterEnabled(ipVersion) == nil {
-				brNetfilterEnabled = true
-				break
-			}
+	err = n.hairpinModeSetup()
+	if err != nil {
+		return err
+	}
+
+	// Refresh exported BGP prefixes on local member.
+	err = n.forwardBGPSetupPrefixes()
+	if err != nil {
+		return fmt.Errorf("Failed applying BGP prefixes for address forwards: %w", err)
+	}
+
+	revert.Success()
+	return nil
+}
+
+// hairpinModeSetup enables hairpin mode on active NIC bridge ports when the first address forward or load
+// balancer is added to the network.
+func (n *bridge) hairpinModeSetup() error {
+	if n.config["bridge.driver"] == "openvswitch" {
+		return nil
+	}
+
+	brNetfilterEnabled := false
+	for _, ipVersion := range []uint{4, 6} {
+		if BridgeNetfilterEnabled(ipVersion) == nil {
+			brNetfilterEnabled = true
+			break
 		}
+	}
 
-		// If br_netfilter is enabled and bridge has forwards, we enable hairpin mode on each NIC's bridge
-		// port in case any of the forwards target the NIC and the instance attempts to connect to the
-		// forward's listener. Without hairpin mode on the target of the forward will not be able to
-		// connect to the listener.
-		if brNetfilterEnabled {
-			listenAddresses, err := n.state.Cluster.GetNetworkForwardListenAddresses(n.ID(), true)
-			if err != nil {
-				return fmt.Errorf("Failed loading network forwards: %w", err)
-			}
+	// If br_netfilter is enabled and bridge has forwards or load balancers, we enable hairpin mode on each
+	// NIC's bridge port in case any of them target the NIC and the instance attempts to connect to the
+	// listener. Without hairpin mode on the target will not be able to connect to the listener.
+	if !brNetfilterEnabled {
+		return nil
+	}
 
-			// If we are the first forward on this bridge, enable hairpin mode on active NIC ports.
-			if len(listenAddresses) <= 1 {
-				var localNode string
+	fwdListenAddresses, err := n.state.Cluster.GetNetworkForwardListenAddresses(n.ID(), true)
+	if err != nil {
+		return fmt.Errorf("Failed loading network forwards: %w", err)
+	}
 
-				err = n.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
-					localNode, err = tx.GetLocalNodeName()
-					if err != nil {
-						return fmt.Errorf("Failed to get local member name: %w", err)
-					}
+	lbListenAddresses, err := n.state.Cluster.GetNetworkLoadBalancerListenAddresses(n.ID(), true)
+	if err != nil {
+		return fmt.Errorf("Failed loading network load balancers: %w", err)
+	}
 
-					return err
-				})
-				if err != nil {
-					return err
-				}
+	// Only the first forward or load balancer on this bridge needs to enable hairpin mode on active NIC
+	// ports, as NICs started afterwards enable it themselves.
+	if len(fwdListenAddresses)+len(lbListenAddresses) > 1 {
+		return nil
+	}
 
-				filter := db.InstanceFilter{
-					Node: &localNode,
-				}
+	var localNode string
 
-				err = n.state.Cluster.InstanceList(&filter, func(inst db.Instance, p db.Project, profiles []api.Profile) error {
-					// Get the instance's effective network project name.
-					instNetworkProject := project.NetworkProjectFromRecord(&p)
+	err = n.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		localNode, err = tx.GetLocalNodeName()
+		if err != nil {
+			return fmt.Errorf("Failed to get local member name: %w", err)
+		}
 
-					if instNetworkProject != project.Default {
-						return nil // Managed bridge networks can only exist in default project.
-					}
-					devices := db.ExpandInstanceDevices(deviceConfig.NewDevices(db.DevicesToAPI(inst.Devices)), profiles)
+		return err
+	})
+	if err != nil {
+		return err
+	}
 
-					// Iterate through each of the instance's devices, looking for bridged NICs
-					// that are linked to this network.
-					for devName, devConfig := range devices {
-						if devConfig["type"] != "nic" {
-							continue
-						}
+	filter := db.InstanceFilter{
+		Node: &localNode,
+	}
 
-						// Check whether the NIC device references our network..
-						if !NICUsesNetwork(devConfig, &api.Network{Name: n.Name()}) {
-							continue
-						}
+	err = n.state.Cluster.InstanceList(&filter, func(inst db.Instance, p db.Project, profiles []api.Profile) error {
+		// Get the instance's effective network project name.
+		instNetworkProject := project.NetworkProjectFromRecord(&p)
 
-						hostName := inst.Config[fmt.Sprintf("volatile.%s.host_name", devName)]
-						if InterfaceExists(hostName) {
-							link := &ip.Link{Name: hostName}
-							err = link.BridgeLinkSetHairpin(true)
-							if err != nil {
-								return fmt.Errorf("Error enabling hairpin mode on bridge port %q: %w", link.Name, err)
-							}
-							n.logger.Debug("Enabled hairpin mode on NIC bridge port", logger.Ctx{"inst": inst.Name, "project": inst.Project, "device": devName, "dev": link.Name})
-						}
-					}
+		if instNetworkProject != project.Default {
+			return nil // Managed bridge networks can only exist in default project.
+		}
+		devices := db.ExpandInstanceDevices(deviceConfig.NewDevices(db.DevicesToAPI(inst.Devices)), profiles)
 
-					return nil
-				})
+		// Iterate through each of the instance's devices, looking for bridged NICs
+		// that are linked to this network.
+		for devName, devConfig := range devices {
+			if devConfig["type"] != "nic" {
+				continue
+			}
+
+			// Check whether the NIC device references our network..
+			if !NICUsesNetwork(devConfig, &api.Network{Name: n.Name()}) {
+				continue
+			}
+
+			hostName := inst.Config[fmt.Sprintf("volatile.%s.host_name", devName)]
+			if InterfaceExists(hostName) {
+				link := &ip.Link{Name: hostName}
+				err = link.BridgeLinkSetHairpin(true)
 				if err != nil {
-					return err
+					return fmt.Errorf("Error enabling hairpin mode on bridge port %q: %w", link.Name, err)
 				}
+				n.logger.Debug("Enabled hairpin mode on NIC bridge port", logger.Ctx{"inst": inst.Name, "project": inst.Project, "device": devName, "dev": link.Name})
 			}
 		}
-	}
 
-	// Refresh exported BGP prefixes on local member.
-	err = n.forwardBGPSetupPrefixes()
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("Failed applying BGP prefixes for address forwards: %w", err)
+		return err
 	}
 
-	revert.Success()
 	return nil
 }
 
@@ -2841,73 +3050,465 @@ func (n *bridge) forwardSetupFirewall() error {
 	return nil
 }
 
-// Leases returns a list of leases for the bridged network. It will reach out to other cluster members as needed.
-// The projectName passed here refers to the initial project from the API request which may differ from the network's project.
-func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]api.NetworkLease, error) {
-	leases := []api.NetworkLease{}
-	projectMacs := []string{}
-
-	// Get all static leases.
-	if clientType == request.ClientTypeNormal {
-		// Get the downstream networks.
-		if n.project == project.Default {
-			var err error
+// loadBalancerConvertToFirewallLoadBalancers converts load balancers into format compatible with the firewall package.
+func (n *bridge) loadBalancerConvertToFirewallLoadBalancers(listenAddress net.IP, portMaps []*loadBalancerPortMap) []firewallDrivers.LoadBalancer {
+	var lbs []firewallDrivers.LoadBalancer
 
-			// Load all the networks.
-			var projectNetworks map[string]map[int64]api.Network
-			err = n.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
-				projectNetworks, err = tx.GetCreatedNetworks()
-				return err
+	for _, portMap := range portMaps {
+		targets := make([]firewallDrivers.LoadBalancerTarget, 0, len(portMap.targets))
+		for _, target := range portMap.targets {
+			targets = append(targets, firewallDrivers.LoadBalancerTarget{
+				Address: target.address,
+				Ports:   target.ports,
 			})
-			if err != nil {
-				return nil, err
-			}
-
-			// Look for networks using the current network as an uplink.
-			for projectName, networks := range projectNetworks {
-				for _, network := range networks {
-					if network.Config["network"] != n.name {
-						continue
-					}
-
-					// Found a network, add leases.
-					for _, k := range []string{"volatile.network.ipv4.address", "volatile.network.ipv6.address"} {
-						v := network.Config[k]
-						if v != "" {
-							leases = append(leases, api.NetworkLease{
-								Hostname: fmt.Sprintf("%s-%s.uplink", projectName, network.Name),
-								Address:  v,
-								Type:     "uplink",
-							})
-						}
-					}
-				}
-			}
 		}
 
-		// Get all the instances.
-		instances, err := instance.LoadByProject(n.state, projectName)
-		if err != nil {
-			return nil, err
-		}
+		lbs = append(lbs, firewallDrivers.LoadBalancer{
+			ListenAddress: listenAddress,
+			Protocol:      portMap.protocol,
+			ListenPorts:   portMap.listenPorts,
+			Targets:       targets,
+		})
+	}
 
-		for _, inst := range instances {
-			// Go through all its devices (including profiles).
-			for k, dev := range inst.ExpandedDevices() {
-				// Skip uninteresting entries.
-				if dev["type"] != "nic" {
-					continue
-				}
+	return lbs
+}
 
-				nicType, err := nictype.NICType(n.state, inst.Project(), dev)
-				if err != nil || nicType != "bridged" {
-					continue
-				}
+// LoadBalancerCreate creates a network load balancer.
+func (n *bridge) LoadBalancerCreate(loadBalancer api.NetworkLoadBalancersPost, clientType request.ClientType) error {
+	memberSpecific := true // bridge supports per-member load balancers.
 
-				// Temporarily populate parent from network setting if used.
-				if dev["network"] != "" {
-					dev["parent"] = dev["network"]
-				}
+	// Check if there is an existing load balancer using the same listen address.
+	_, _, err := n.state.Cluster.GetNetworkLoadBalancer(n.ID(), memberSpecific, loadBalancer.ListenAddress)
+	if err == nil {
+		return api.StatusErrorf(http.StatusConflict, "A load balancer for that listen address already exists")
+	}
+
+	// Check if there is an existing forward using the same listen address.
+	_, _, err = n.state.Cluster.GetNetworkForward(n.ID(), memberSpecific, loadBalancer.ListenAddress)
+	if err == nil {
+		return api.StatusErrorf(http.StatusConflict, "A forward for that listen address already exists")
+	}
+
+	// Convert listen address to subnet so we can check its valid and can be used.
+	listenAddressNet, err := ParseIPToNet(loadBalancer.ListenAddress)
+	if err != nil {
+		return fmt.Errorf("Failed parsing load balancer listen address %q: %w", loadBalancer.ListenAddress, err)
+	}
+
+	_, err = n.loadBalancerValidate(listenAddressNet.IP, &loadBalancer.NetworkLoadBalancerPut)
+	if err != nil {
+		return err
+	}
+
+	externalSubnetsInUse, err := n.getExternalSubnetInUse()
+	if err != nil {
+		return err
+	}
+
+	// Check the listen address subnet doesn't fall within any existing network external subnets.
+	for _, externalSubnetUser := range externalSubnetsInUse {
+		// Skip our own network's SNAT address (as it can be used for NICs in the network).
+		if externalSubnetUser.networkSNAT && externalSubnetUser.networkProject == n.project && externalSubnetUser.networkName == n.name {
+			continue
+		}
+
+		// Skip our own network (but not NIC devices on our own network).
+		if externalSubnetUser.networkProject == n.project && externalSubnetUser.networkName == n.name && externalSubnetUser.instanceDevice == "" {
+			continue
+		}
+
+		if SubnetContains(&externalSubnetUser.subnet, listenAddressNet) || SubnetContains(listenAddressNet, &externalSubnetUser.subnet) {
+			// This error is purposefully vague so that it doesn't reveal any names of
+			// resources potentially outside of the network.
+			return fmt.Errorf("Load balancer listen address %q overlaps with another network or NIC", listenAddressNet.String())
+		}
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Create load balancer DB record.
+	loadBalancerID, err := n.state.Cluster.CreateNetworkLoadBalancer(n.ID(), memberSpecific, &loadBalancer)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		n.state.Cluster.DeleteNetworkLoadBalancer(n.ID(), loadBalancerID)
+		n.loadBalancerSetupFirewall()
+		n.loadBalancerBGPSetupPrefixes()
+	})
+
+	err = n.loadBalancerSetupFirewall()
+	if err != nil {
+		return err
+	}
+
+	// Check if hairpin mode needs to be enabled on active NIC bridge ports.
+	err = n.hairpinModeSetup()
+	if err != nil {
+		return err
+	}
+
+	// Refresh exported BGP prefixes on local member.
+	err = n.loadBalancerBGPSetupPrefixes()
+	if err != nil {
+		return fmt.Errorf("Failed applying BGP prefixes for load balancers: %w", err)
+	}
+
+	revert.Success()
+	return nil
+}
+
+// LoadBalancerUpdate updates a network load balancer.
+func (n *bridge) LoadBalancerUpdate(listenAddress string, req api.NetworkLoadBalancerPut, clientType request.ClientType) error {
+	memberSpecific := true // bridge supports per-member load balancers.
+	curLoadBalancerID, curLoadBalancer, err := n.state.Cluster.GetNetworkLoadBalancer(n.ID(), memberSpecific, listenAddress)
+	if err != nil {
+		return err
+	}
+
+	_, err = n.loadBalancerValidate(net.ParseIP(curLoadBalancer.ListenAddress), &req)
+	if err != nil {
+		return err
+	}
+
+	curLoadBalancerEtagHash, err := util.EtagHash(curLoadBalancer.Etag())
+	if err != nil {
+		return err
+	}
+
+	newLoadBalancer := api.NetworkLoadBalancer{
+		ListenAddress:          curLoadBalancer.ListenAddress,
+		NetworkLoadBalancerPut: req,
+	}
+
+	newLoadBalancerEtagHash, err := util.EtagHash(newLoadBalancer.Etag())
+	if err != nil {
+		return err
+	}
+
+	if curLoadBalancerEtagHash == newLoadBalancerEtagHash {
+		return nil // Nothing has changed.
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	err = n.state.Cluster.UpdateNetworkLoadBalancer(n.ID(), curLoadBalancerID, &newLoadBalancer.NetworkLoadBalancerPut)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		n.state.Cluster.UpdateNetworkLoadBalancer(n.ID(), curLoadBalancerID, &curLoadBalancer.NetworkLoadBalancerPut)
+		n.loadBalancerSetupFirewall()
+		n.loadBalancerBGPSetupPrefixes()
+	})
+
+	err = n.loadBalancerSetupFirewall()
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// LoadBalancerDelete deletes a network load balancer.
+func (n *bridge) LoadBalancerDelete(listenAddress string, clientType request.ClientType) error {
+	memberSpecific := true // bridge supports per-member load balancers.
+	loadBalancerID, loadBalancer, err := n.state.Cluster.GetNetworkLoadBalancer(n.ID(), memberSpecific, listenAddress)
+	if err != nil {
+		return err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	err = n.state.Cluster.DeleteNetworkLoadBalancer(n.ID(), loadBalancerID)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		newLoadBalancer := api.NetworkLoadBalancersPost{
+			NetworkLoadBalancerPut: loadBalancer.NetworkLoadBalancerPut,
+			ListenAddress:          loadBalancer.ListenAddress,
+		}
+		n.state.Cluster.CreateNetworkLoadBalancer(n.ID(), memberSpecific, &newLoadBalancer)
+		n.loadBalancerSetupFirewall()
+		n.loadBalancerBGPSetupPrefixes()
+	})
+
+	err = n.loadBalancerSetupFirewall()
+	if err != nil {
+		return err
+	}
+
+	// Refresh exported BGP prefixes on local member.
+	err = n.loadBalancerBGPSetupPrefixes()
+	if err != nil {
+		return fmt.Errorf("Failed applying BGP prefixes for load balancers: %w", err)
+	}
+
+	revert.Success()
+	return nil
+}
+
+// loadBalancerSetupFirewall applies all network load balancers defined for this network and this member.
+func (n *bridge) loadBalancerSetupFirewall() error {
+	memberSpecific := true // Get all load balancers for this cluster member.
+	loadBalancers, err := n.state.Cluster.GetNetworkLoadBalancers(n.ID(), memberSpecific)
+	if err != nil {
+		return fmt.Errorf("Failed loading network load balancers: %w", err)
+	}
+
+	var fwLoadBalancers []firewallDrivers.LoadBalancer
+
+	for _, loadBalancer := range loadBalancers {
+		// Convert listen address to subnet so we can check its valid and can be used.
+		listenAddressNet, err := ParseIPToNet(loadBalancer.ListenAddress)
+		if err != nil {
+			return fmt.Errorf("Failed parsing load balancer listen address %q: %w", loadBalancer.ListenAddress, err)
+		}
+
+		portMaps, err := n.loadBalancerValidate(listenAddressNet.IP, &loadBalancer.NetworkLoadBalancerPut)
+		if err != nil {
+			return fmt.Errorf("Failed validating firewall load balancer for listen address %q: %w", loadBalancer.ListenAddress, err)
+		}
+
+		fwLoadBalancers = append(fwLoadBalancers, n.loadBalancerConvertToFirewallLoadBalancers(listenAddressNet.IP, portMaps)...)
+	}
+
+	err = n.state.Firewall.NetworkApplyLoadBalancers(n.name, fwLoadBalancers)
+	if err != nil {
+		return fmt.Errorf("Failed applying firewall load balancers: %w", err)
+	}
+
+	return nil
+}
+
+// ReservationCreate creates a network address reservation.
+func (n *bridge) ReservationCreate(projectName string, reservation api.NetworkReservationsPost, clientType request.ClientType) error {
+	revert := revert.New()
+	defer revert.Fail()
+
+	if clientType == request.ClientTypeNormal {
+		// Check if there is an existing reservation for the same address.
+		_, _, err := n.state.Cluster.GetNetworkReservation(n.ID(), reservation.Address)
+		if err == nil {
+			return api.StatusErrorf(http.StatusConflict, "A reservation for that address already exists")
+		}
+
+		err = n.reservationValidate(net.ParseIP(reservation.Address), projectName, &reservation.NetworkReservationPut)
+		if err != nil {
+			return err
+		}
+
+		// Create reservation DB record.
+		reservationID, err := n.state.Cluster.CreateNetworkReservation(n.ID(), projectName, &reservation)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() {
+			n.state.Cluster.DeleteNetworkReservation(n.ID(), reservationID)
+			UpdateDNSMasqStatic(n.state, n.name)
+		})
+
+		// Notify all other members to refresh their static DHCP allocations.
+		notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return err
+		}
+
+		err = notifier(func(client lxd.InstanceServer) error {
+			return client.UseProject(n.project).CreateNetworkReservation(n.name, reservation)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Refresh static DHCP allocations on local member.
+	err := UpdateDNSMasqStatic(n.state, n.name)
+	if err != nil {
+		return fmt.Errorf("Failed applying address reservations: %w", err)
+	}
+
+	revert.Success()
+	return nil
+}
+
+// ReservationUpdate updates a network address reservation.
+func (n *bridge) ReservationUpdate(address string, req api.NetworkReservationPut, clientType request.ClientType) error {
+	revert := revert.New()
+	defer revert.Fail()
+
+	if clientType == request.ClientTypeNormal {
+		curReservationID, curReservation, err := n.state.Cluster.GetNetworkReservation(n.ID(), address)
+		if err != nil {
+			return err
+		}
+
+		err = n.reservationValidate(net.ParseIP(curReservation.Address), curReservation.Project, &req)
+		if err != nil {
+			return err
+		}
+
+		err = n.state.Cluster.UpdateNetworkReservation(n.ID(), curReservationID, &req)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() {
+			n.state.Cluster.UpdateNetworkReservation(n.ID(), curReservationID, &curReservation.NetworkReservationPut)
+			UpdateDNSMasqStatic(n.state, n.name)
+		})
+
+		// Notify all other members to refresh their static DHCP allocations.
+		notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return err
+		}
+
+		err = notifier(func(client lxd.InstanceServer) error {
+			return client.UseProject(n.project).UpdateNetworkReservation(n.name, address, req, "")
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Refresh static DHCP allocations on local member.
+	err := UpdateDNSMasqStatic(n.state, n.name)
+	if err != nil {
+		return fmt.Errorf("Failed applying address reservations: %w", err)
+	}
+
+	revert.Success()
+	return nil
+}
+
+// ReservationDelete deletes a network address reservation.
+func (n *bridge) ReservationDelete(address string, clientType request.ClientType) error {
+	revert := revert.New()
+	defer revert.Fail()
+
+	if clientType == request.ClientTypeNormal {
+		reservationID, reservation, err := n.state.Cluster.GetNetworkReservation(n.ID(), address)
+		if err != nil {
+			return err
+		}
+
+		err = n.state.Cluster.DeleteNetworkReservation(n.ID(), reservationID)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() {
+			newReservation := api.NetworkReservationsPost{
+				NetworkReservationPut: reservation.NetworkReservationPut,
+				Address:               reservation.Address,
+			}
+
+			n.state.Cluster.CreateNetworkReservation(n.ID(), reservation.Project, &newReservation)
+			UpdateDNSMasqStatic(n.state, n.name)
+		})
+
+		// Notify all other members to refresh their static DHCP allocations.
+		notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return err
+		}
+
+		err = notifier(func(client lxd.InstanceServer) error {
+			return client.UseProject(n.project).DeleteNetworkReservation(n.name, address)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Refresh static DHCP allocations on local member.
+	err := UpdateDNSMasqStatic(n.state, n.name)
+	if err != nil {
+		return fmt.Errorf("Failed applying address reservations: %w", err)
+	}
+
+	revert.Success()
+	return nil
+}
+
+// Leases returns a list of leases for the bridged network. It will reach out to other cluster members as needed.
+// The projectName passed here refers to the initial project from the API request which may differ from the network's project.
+func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]api.NetworkLease, error) {
+	leases := []api.NetworkLease{}
+	projectMacs := []string{}
+
+	// Get all static leases.
+	if clientType == request.ClientTypeNormal {
+		// Get the downstream networks.
+		if n.project == project.Default {
+			var err error
+
+			// Load all the networks.
+			var projectNetworks map[string]map[int64]api.Network
+			err = n.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
+				projectNetworks, err = tx.GetCreatedNetworks()
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			// Look for networks using the current network as an uplink.
+			for projectName, networks := range projectNetworks {
+				for _, network := range networks {
+					if network.Config["network"] != n.name {
+						continue
+					}
+
+					// Found a network, add leases.
+					for _, k := range []string{"volatile.network.ipv4.address", "volatile.network.ipv6.address"} {
+						v := network.Config[k]
+						if v != "" {
+							leases = append(leases, api.NetworkLease{
+								Hostname: fmt.Sprintf("%s-%s.uplink", projectName, network.Name),
+								Address:  v,
+								Type:     "uplink",
+							})
+						}
+					}
+				}
+			}
+		}
+
+		// Get all the instances.
+		instances, err := instance.LoadByProject(n.state, projectName)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, inst := range instances {
+			// Go through all its devices (including profiles).
+			for k, dev := range inst.ExpandedDevices() {
+				// Skip uninteresting entries.
+				if dev["type"] != "nic" {
+					continue
+				}
+
+				nicType, err := nictype.NICType(n.state, inst.Project(), dev)
+				if err != nil || nicType != "bridged" {
+					continue
+				}
+
+				// Temporarily populate parent from network setting if used.
+				if dev["network"] != "" {
+					dev["parent"] = dev["network"]
+				}
 
 				if dev["parent"] != n.name {
 					continue
@@ -3070,3 +3671,313 @@ func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]ap
 func (n *bridge) UsesDNSMasq() bool {
 	return n.config["bridge.mode"] == "fan" || !shared.StringInSlice(n.config["ipv4.address"], []string{"", "none"}) || !shared.StringInSlice(n.config["ipv6.address"], []string{"", "none"})
 }
+
+// evpnSetup creates the VXLAN interface of the EVPN segment, advertises the segment over BGP and sets up the BGP
+// peers to learn the VTEPs and MAC addresses of the other members of the segment from.
+func (n *bridge) evpnSetup(mtu string) error {
+	vni, err := strconv.ParseUint(n.config["evpn.vni"], 10, 32)
+	if err != nil {
+		return fmt.Errorf("Failed parsing evpn.vni: %w", err)
+	}
+
+	vtep, err := n.evpnLocalAddress()
+	if err != nil {
+		return err
+	}
+
+	asn, _, err := n.evpnMembers()
+	if err != nil {
+		return err
+	}
+
+	routeTarget := n.config["evpn.route_target"]
+	if routeTarget == "" {
+		routeTarget = fmt.Sprintf("%d:%d", asn, vni)
+
+		_, _, err = bgp.ParseRouteTarget(routeTarget)
+		if err != nil {
+			return fmt.Errorf(`Failed generating the default route target, "evpn.route_target" must be set: %w`, err)
+		}
+	}
+
+	port := n.config["evpn.port"]
+	if port == "" {
+		port = "4789"
+	}
+
+	// Create the VXLAN interface, its forwarding database is populated from the BGP routes.
+	devName := fmt.Sprintf("%s-evpn", n.name)
+	vxlan := &ip.Vxlan{
+		Link:       ip.Link{Name: devName},
+		VxlanID:    n.config["evpn.vni"],
+		DevName:    n.config["evpn.interface"],
+		Local:      vtep.String(),
+		DstPort:    port,
+		NoLearning: true,
+	}
+
+	err = vxlan.Add()
+	if err != nil {
+		return err
+	}
+
+	err = AttachInterface(n.name, devName)
+	if err != nil {
+		return err
+	}
+
+	err = vxlan.SetMTU(mtu)
+	if err != nil {
+		return err
+	}
+
+	err = n.evpnSetupFilters(devName)
+	if err != nil {
+		return fmt.Errorf("Failed setting up EVPN traffic filters: %w", err)
+	}
+
+	err = vxlan.SetUp()
+	if err != nil {
+		return err
+	}
+
+	// Advertise the segment and apply the routes of the other VTEPs to the forwarding database.
+	err = n.state.BGP.AddEVPNSegment(uint32(vni), vtep, routeTarget, func(route bgp.EVPNRoute) {
+		hwaddr := "00:00:00:00:00:00"
+		if route.MAC != nil {
+			hwaddr = route.MAC.String()
+		}
+
+		var err error
+		if route.Withdraw {
+			err = VxlanFDBDelete(devName, hwaddr, route.VTEP.String())
+		} else {
+			err = VxlanFDBAdd(devName, hwaddr, route.VTEP.String())
+		}
+
+		if err != nil {
+			n.logger.Warn("Failed applying EVPN route", logger.Ctx{"hwaddr": hwaddr, "vtep": route.VTEP.String(), "withdraw": route.Withdraw, "err": err})
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("Failed adding EVPN segment: %w", err)
+	}
+
+	err = n.evpnSetupPeers()
+	if err != nil {
+		return fmt.Errorf("Failed setting up EVPN peers: %w", err)
+	}
+
+	return nil
+}
+
+// evpnSetupFilters keeps the DHCP, router advertisement and gateway ARP traffic from crossing the VXLAN interface,
+// so that each member acts as the DHCP server and gateway of its own instances.
+func (n *bridge) evpnSetupFilters(devName string) error {
+	qdisc := &ip.QdiscClsact{Qdisc: ip.Qdisc{Dev: devName}}
+	err := qdisc.Add()
+	if err != nil {
+		return err
+	}
+
+	filters := []ip.FlowerFilter{
+		{Filter: ip.Filter{Protocol: "ip"}, IPProto: "udp", DstPort: "67"},
+		{Filter: ip.Filter{Protocol: "ip"}, IPProto: "udp", DstPort: "68"},
+		{Filter: ip.Filter{Protocol: "ipv6"}, IPProto: "udp", DstPort: "546"},
+		{Filter: ip.Filter{Protocol: "ipv6"}, IPProto: "udp", DstPort: "547"},
+		{Filter: ip.Filter{Protocol: "ipv6"}, IPProto: "icmpv6", ICMPType: "133"},
+		{Filter: ip.Filter{Protocol: "ipv6"}, IPProto: "icmpv6", ICMPType: "134"},
+	}
+
+	gateway, _, err := net.ParseCIDR(n.config["ipv4.address"])
+	if err == nil {
+		filters = append(filters,
+			ip.FlowerFilter{Filter: ip.Filter{Protocol: "arp"}, ARPOp: "request", ARPTIP: gateway.String()},
+			ip.FlowerFilter{Filter: ip.Filter{Protocol: "arp"}, ARPOp: "reply", ARPSIP: gateway.String()},
+		)
+	}
+
+	// Filter both the traffic received from and sent to the other VTEPs.
+	for _, parent := range []string{"ffff:fff2", "ffff:fff3"} {
+		for _, filter := range filters {
+			filter.Dev = devName
+			filter.Parent = parent
+			filter.Actions = []ip.Action{&ip.ActionDrop{}}
+
+			err = filter.Add()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// evpnSetupPeers updates the list of BGP peers exchanging the EVPN routes of the network, made of the other
+// cluster members and of the BGP peers of the network.
+func (n *bridge) evpnSetupPeers() error {
+	asn, memberVTEPs, err := n.evpnMembers()
+	if err != nil {
+		return err
+	}
+
+	newPeers := []string{}
+	for _, vtep := range memberVTEPs {
+		newPeers = append(newPeers, fmt.Sprintf("%s,%d,", vtep, asn))
+	}
+
+	for _, peer := range n.bgpGetPeers(n.config) {
+		fields := strings.SplitN(peer, ",", 3)
+		newPeers = append(newPeers, fmt.Sprintf("%s,%s,%s", net.ParseIP(fields[0]).String(), fields[1], fields[2]))
+	}
+
+	bgpOwner := fmt.Sprintf("network_%d", n.id)
+	oldPeers := n.state.BGP.GetEVPNPeers(bgpOwner)
+
+	// Remove old peers.
+	for _, peer := range oldPeers {
+		if shared.StringInSlice(peer, newPeers) {
+			continue
+		}
+
+		fields := strings.SplitN(peer, ",", 3)
+		err := n.state.BGP.RemoveEVPNPeer(net.ParseIP(fields[0]), bgpOwner)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Add new peers.
+	for _, peer := range newPeers {
+		if shared.StringInSlice(peer, oldPeers) {
+			continue
+		}
+
+		fields := strings.SplitN(peer, ",", 3)
+		peerASN, err := strconv.ParseUint(fields[1], 10, 32)
+		if err != nil {
+			return err
+		}
+
+		err = n.state.BGP.AddEVPNPeer(net.ParseIP(fields[0]), uint32(peerASN), fields[2], bgpOwner)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// evpnClear withdraws the EVPN segment and removes its BGP peers.
+func (n *bridge) evpnClear(config map[string]string) error {
+	vni, err := strconv.ParseUint(config["evpn.vni"], 10, 32)
+	if err == nil {
+		err = n.state.BGP.RemoveEVPNSegment(uint32(vni))
+		if err != nil && err != bgp.ErrEVPNSegmentNotFound {
+			return err
+		}
+	}
+
+	bgpOwner := fmt.Sprintf("network_%d", n.id)
+	for _, peer := range n.state.BGP.GetEVPNPeers(bgpOwner) {
+		fields := strings.SplitN(peer, ",", 3)
+		err := n.state.BGP.RemoveEVPNPeer(net.ParseIP(fields[0]), bgpOwner)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// evpnMembers returns the BGP ASN of the cluster and the VTEP addresses of the other cluster members.
+// The VTEP address of a member is its "evpn.local" setting or else the IPv4 address of its cluster address.
+func (n *bridge) evpnMembers() (uint32, []string, error) {
+	var asn int64
+	vteps := []string{}
+
+	localNodeID := n.state.Cluster.GetNodeID()
+	err := n.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		config, err := cluster.ConfigLoad(tx)
+		if err != nil {
+			return err
+		}
+
+		asn = config.BGPASN()
+
+		nodes, err := tx.GetNodes()
+		if err != nil {
+			return err
+		}
+
+		localAddresses, err := tx.GetNetworkNodeConfigValues(n.id, "evpn.local")
+		if err != nil {
+			return err
+		}
+
+		for _, node := range nodes {
+			if node.ID == localNodeID {
+				continue
+			}
+
+			vtep := net.ParseIP(localAddresses[node.ID])
+			if vtep == nil {
+				vtep = evpnAddressHost(node.Address)
+			}
+
+			if vtep == nil {
+				n.logger.Warn("Skipping cluster member without EVPN address", logger.Ctx{"member": node.Name})
+				continue
+			}
+
+			vteps = append(vteps, vtep.String())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if asn == 0 {
+		return 0, nil, fmt.Errorf(`EVPN mode requires "core.bgp_asn" to be set`)
+	}
+
+	return uint32(asn), vteps, nil
+}
+
+// evpnLocalAddress returns the local VTEP address, from "evpn.local" or else the IPv4 address of the cluster
+// address.
+func (n *bridge) evpnLocalAddress() (net.IP, error) {
+	if n.config["evpn.local"] != "" {
+		return net.ParseIP(n.config["evpn.local"]), nil
+	}
+
+	clusterAddress, err := node.ClusterAddress(n.state.Node)
+	if err != nil {
+		return nil, err
+	}
+
+	vtep := evpnAddressHost(clusterAddress)
+	if vtep == nil {
+		return nil, fmt.Errorf(`"evpn.local" must be set when the cluster address isn't an IPv4 address`)
+	}
+
+	return vtep, nil
+}
+
+// evpnAddressHost returns the IPv4 host of a listen address, or nil if it isn't a specific IPv4 address.
+func evpnAddressHost(address string) net.IP {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+
+	vtep := net.ParseIP(host)
+	if vtep == nil || vtep.To4() == nil || vtep.IsUnspecified() {
+		return nil
+	}
+
+	return vtep.To4()
+}
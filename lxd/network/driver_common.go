@@ -3,6 +3,7 @@ package network
 import (
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	"github.com/lxc/lxd/lxd/cluster/request"
 	"github.com/lxc/lxd/lxd/db"
 	dbCluster "github.com/lxc/lxd/lxd/db/cluster"
+	"github.com/lxc/lxd/lxd/instance"
 	"github.com/lxc/lxd/lxd/network/acl"
 	"github.com/lxc/lxd/lxd/project"
 	"github.com/lxc/lxd/lxd/resources"
@@ -25,10 +27,12 @@ import (
 
 // Info represents information about a network driver.
 type Info struct {
-	Projects           bool // Indicates if driver can be used in network enabled projects.
-	NodeSpecificConfig bool // Whether driver has cluster node specific config as a prerequisite for creation.
-	AddressForwards    bool // Indicates if driver supports address forwards.
-	Peering            bool // Indicates if the driver supports network peering.
+	Projects            bool // Indicates if driver can be used in network enabled projects.
+	NodeSpecificConfig  bool // Whether driver has cluster node specific config as a prerequisite for creation.
+	AddressForwards     bool // Indicates if driver supports address forwards.
+	LoadBalancers       bool // Indicates if driver supports load balancers.
+	Peering             bool // Indicates if the driver supports network peering.
+	AddressReservations bool // Indicates if driver supports address reservations.
 }
 
 // forwardPortMap represents a mapping of listen port(s) to target port(s) for a protocol/target address pair.
@@ -39,6 +43,19 @@ type forwardPortMap struct {
 	protocol      string
 }
 
+// loadBalancerTarget represents a load balancer backend target address and its target port(s).
+type loadBalancerTarget struct {
+	address net.IP
+	ports   []uint64
+}
+
+// loadBalancerPortMap represents a mapping of listen port(s) to one or more backend targets for a protocol.
+type loadBalancerPortMap struct {
+	listenPorts []uint64
+	protocol    string
+	targets     []loadBalancerTarget
+}
+
 // externalSubnetUsage represents usage of a subnet by a network or NIC.
 type externalSubnetUsage struct {
 	subnet          net.IPNet
@@ -206,6 +223,7 @@ func (n *common) Info() Info {
 		Projects:           false,
 		NodeSpecificConfig: true,
 		AddressForwards:    false,
+		LoadBalancers:      false,
 	}
 }
 
@@ -549,6 +567,11 @@ func (n *common) bgpSetup(oldConfig map[string]string) error {
 		return fmt.Errorf("Failed applying BGP prefixes for address forwards: %w", err)
 	}
 
+	err = n.loadBalancerBGPSetupPrefixes()
+	if err != nil {
+		return fmt.Errorf("Failed applying BGP prefixes for load balancers: %w", err)
+	}
+
 	return nil
 }
 
@@ -572,6 +595,12 @@ func (n *common) bgpClear(config map[string]string) error {
 		return err
 	}
 
+	// Clear existing load balancer prefixes for network.
+	err = n.state.BGP.RemovePrefixByOwner(fmt.Sprintf("network_%d_load_balancer", n.id))
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -908,33 +937,319 @@ func (n *common) ForwardDelete(listenAddress string, clientType request.ClientTy
 	return ErrNotImplemented
 }
 
+// loadBalancerValidate validates the load balancer request.
+func (n *common) loadBalancerValidate(listenAddress net.IP, lb *api.NetworkLoadBalancerPut) ([]*loadBalancerPortMap, error) {
+	if listenAddress == nil {
+		return nil, fmt.Errorf("Invalid listen address")
+	}
+
+	listenIsIP4 := listenAddress.To4() != nil
+
+	// For checking target addresses are within network's subnet.
+	netIPKey := "ipv4.address"
+	if !listenIsIP4 {
+		netIPKey = "ipv6.address"
+	}
+
+	netIPAddress := n.config[netIPKey]
+
+	var err error
+	var netSubnet *net.IPNet
+	if netIPAddress != "" {
+		_, netSubnet, err = net.ParseCIDR(n.config[netIPKey])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Look for any unknown config fields.
+	for k := range lb.Config {
+		// User keys are not validated.
+		if shared.IsUserConfig(k) {
+			continue
+		}
+
+		return nil, fmt.Errorf("Invalid option %q", k)
+	}
+
+	// Validate backends.
+	backendsMap := make(map[string]loadBalancerTarget, len(lb.Backends))
+	for backendSpecID, backendSpec := range lb.Backends {
+		if backendSpec.Name == "" {
+			return nil, fmt.Errorf("Missing name in backend specification %d", backendSpecID)
+		}
+
+		_, found := backendsMap[backendSpec.Name]
+		if found {
+			return nil, fmt.Errorf("Duplicate name %q in backend specification %d", backendSpec.Name, backendSpecID)
+		}
+
+		targetAddress := net.ParseIP(backendSpec.TargetAddress)
+		if targetAddress == nil {
+			return nil, fmt.Errorf("Invalid target address in backend specification %d", backendSpecID)
+		}
+
+		targetIsIP4 := targetAddress.To4() != nil
+		if listenIsIP4 != targetIsIP4 {
+			return nil, fmt.Errorf("Cannot mix IP versions in listen address and backend specification %d target address", backendSpecID)
+		}
+
+		// Check target address is within network's subnet.
+		if netSubnet != nil && !SubnetContainsIP(netSubnet, targetAddress) {
+			return nil, fmt.Errorf("Target address is not within the network subnet in backend specification %d", backendSpecID)
+		}
+
+		target := loadBalancerTarget{
+			address: targetAddress,
+		}
+
+		// Check valid target port(s) supplied.
+		for _, pr := range shared.SplitNTrimSpace(backendSpec.TargetPort, ",", -1, true) {
+			portFirst, portRange, err := ParsePortRange(pr)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid target port in backend specification %d", backendSpecID)
+			}
+
+			for i := int64(0); i < portRange; i++ {
+				target.ports = append(target.ports, uint64(portFirst+i))
+			}
+		}
+
+		backendsMap[backendSpec.Name] = target
+	}
+
+	// Validate port rules.
+	validPortProcols := []string{"tcp", "udp"}
+
+	// Used to ensure that each listen port is only used once.
+	listenPorts := map[string]map[int64]struct{}{
+		"tcp": make(map[int64]struct{}),
+		"udp": make(map[int64]struct{}),
+	}
+
+	portMaps := make([]*loadBalancerPortMap, 0, len(lb.Ports))
+
+	for portSpecID, portSpec := range lb.Ports {
+		if !shared.StringInSlice(portSpec.Protocol, validPortProcols) {
+			return nil, fmt.Errorf("Invalid port protocol in port specification %d, protocol must be one of: %s", portSpecID, strings.Join(validPortProcols, ", "))
+		}
+
+		// Check valid listen port(s) supplied.
+		listenPortRanges := shared.SplitNTrimSpace(portSpec.ListenPort, ",", -1, true)
+		if len(listenPortRanges) <= 0 {
+			return nil, fmt.Errorf("Missing listen port in port specification %d", portSpecID)
+		}
+
+		portMap := loadBalancerPortMap{
+			listenPorts: make([]uint64, 0),
+			protocol:    portSpec.Protocol,
+		}
+
+		for _, pr := range listenPortRanges {
+			portFirst, portRange, err := ParsePortRange(pr)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid listen port in port specification %d: %w", portSpecID, err)
+			}
+
+			for i := int64(0); i < portRange; i++ {
+				port := portFirst + i
+				if _, found := listenPorts[portSpec.Protocol][port]; found {
+					return nil, fmt.Errorf("Duplicate listen port %d for protocol %q in port specification %d", port, portSpec.Protocol, portSpecID)
+				}
+
+				listenPorts[portSpec.Protocol][port] = struct{}{}
+				portMap.listenPorts = append(portMap.listenPorts, uint64(port))
+			}
+		}
+
+		// Check valid target backend(s) supplied.
+		if len(portSpec.TargetBackend) <= 0 {
+			return nil, fmt.Errorf("Missing target backend in port specification %d", portSpecID)
+		}
+
+		for _, backendName := range portSpec.TargetBackend {
+			target, found := backendsMap[backendName]
+			if !found {
+				return nil, fmt.Errorf("Invalid target backend name %q in port specification %d", backendName, portSpecID)
+			}
+
+			// Only check if the target port count matches the listen port count if the target ports
+			// don't equal 0 or 1, because we allow many-to-one type mapping.
+			targetPortsLen := len(target.ports)
+			if targetPortsLen > 1 && len(portMap.listenPorts) != targetPortsLen {
+				return nil, fmt.Errorf("Mismatch of listen port(s) and target port(s) count for backend %q in port specification %d", backendName, portSpecID)
+			}
+
+			portMap.targets = append(portMap.targets, target)
+		}
+
+		portMaps = append(portMaps, &portMap)
+	}
+
+	return portMaps, err
+}
+
+// reservationValidate validates the address reservation request for the given project.
+func (n *common) reservationValidate(address net.IP, projectName string, req *api.NetworkReservationPut) error {
+	if address == nil {
+		return fmt.Errorf("Invalid address")
+	}
+
+	isIP4 := address.To4() != nil
+
+	// Check the address is within the network's subnet.
+	netIPKey := "ipv4.address"
+	if !isIP4 {
+		netIPKey = "ipv6.address"
+	}
+
+	if validate.IsOneOf("none", "")(n.config[netIPKey]) == nil {
+		return fmt.Errorf("Network doesn't have a subnet for the address family of %q", address.String())
+	}
+
+	netIP, netSubnet, err := net.ParseCIDR(n.config[netIPKey])
+	if err != nil {
+		return err
+	}
+
+	if !SubnetContainsIP(netSubnet, address) {
+		return fmt.Errorf("Address %q is not within the network subnet", address.String())
+	}
+
+	if address.Equal(netIP) || address.Equal(netSubnet.IP) {
+		return fmt.Errorf("Address %q is used by the network itself", address.String())
+	}
+
+	if req.Instance != "" {
+		err = instance.ValidName(req.Instance, false)
+		if err != nil {
+			return fmt.Errorf("Invalid instance name %q: %w", req.Instance, err)
+		}
+
+		// Addresses reserved for an instance are assigned to its NICs through DHCP.
+		if isIP4 && shared.IsFalse(n.config["ipv4.dhcp"]) {
+			return fmt.Errorf(`Cannot reserve an IPv4 address for an instance when "ipv4.dhcp" is disabled`)
+		}
+
+		if !isIP4 && (shared.IsFalse(n.config["ipv6.dhcp"]) || shared.IsFalseOrEmpty(n.config["ipv6.dhcp.stateful"])) {
+			return fmt.Errorf(`Cannot reserve an IPv6 address for an instance when "ipv6.dhcp" or "ipv6.dhcp.stateful" are disabled`)
+		}
+	}
+
+	// Check the address isn't used by any forward or load balancer.
+	fwdListenAddresses, err := n.state.Cluster.GetNetworkForwardListenAddresses(n.ID(), false)
+	if err != nil {
+		return fmt.Errorf("Failed loading network forwards: %w", err)
+	}
+
+	lbListenAddresses, err := n.state.Cluster.GetNetworkLoadBalancerListenAddresses(n.ID(), false)
+	if err != nil {
+		return fmt.Errorf("Failed loading network load balancers: %w", err)
+	}
+
+	for _, listenAddresses := range []map[int64]string{fwdListenAddresses, lbListenAddresses} {
+		for _, listenAddress := range listenAddresses {
+			if address.Equal(net.ParseIP(listenAddress)) {
+				return api.StatusErrorf(http.StatusConflict, "Address %q is already used by a forward or load balancer", address.String())
+			}
+		}
+	}
+
+	// Check the address isn't statically assigned to another instance NIC.
+	return usedByInstanceDevices(n.state, n.project, n.name, func(inst db.Instance, nicName string, nicConfig map[string]string) error {
+		if inst.Project == projectName && inst.Name == req.Instance {
+			return nil
+		}
+
+		for _, key := range []string{"ipv4.address", "ipv6.address"} {
+			if address.Equal(net.ParseIP(nicConfig[key])) {
+				return api.StatusErrorf(http.StatusConflict, "Address %q is already used by another instance", address.String())
+			}
+		}
+
+		return nil
+	})
+}
+
+// ReservationCreate returns ErrNotImplemented for drivers that do not support address reservations.
+func (n *common) ReservationCreate(projectName string, reservation api.NetworkReservationsPost, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
+// ReservationUpdate returns ErrNotImplemented for drivers that do not support address reservations.
+func (n *common) ReservationUpdate(address string, newReservation api.NetworkReservationPut, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
+// ReservationDelete returns ErrNotImplemented for drivers that do not support address reservations.
+func (n *common) ReservationDelete(address string, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
+// LoadBalancerCreate returns ErrNotImplemented for drivers that do not support load balancers.
+func (n *common) LoadBalancerCreate(loadBalancer api.NetworkLoadBalancersPost, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
+// LoadBalancerUpdate returns ErrNotImplemented for drivers that do not support load balancers.
+func (n *common) LoadBalancerUpdate(listenAddress string, newLoadBalancer api.NetworkLoadBalancerPut, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
+// LoadBalancerDelete returns ErrNotImplemented for drivers that do not support load balancers.
+func (n *common) LoadBalancerDelete(listenAddress string, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
 // forwardBGPSetupPrefixes exports external forward addresses as prefixes.
 func (n *common) forwardBGPSetupPrefixes() error {
-	// Retrieve network forwards before clearing existing prefixes, and separate them by IP family.
+	// Retrieve network forwards before clearing existing prefixes.
 	fwdListenAddresses, err := n.state.Cluster.GetNetworkForwardListenAddresses(n.ID(), true)
 	if err != nil {
 		return fmt.Errorf("Failed loading network forwards: %w", err)
 	}
 
-	fwdListenAddressesByFamily := map[uint][]string{
+	// Use forward specific owner string (different from the network prefixes) so that these can be reapplied
+	// independently of the network's own prefixes.
+	bgpOwner := fmt.Sprintf("network_%d_forward", n.id)
+
+	return n.listenAddressBGPSetupPrefixes(bgpOwner, fwdListenAddresses)
+}
+
+// loadBalancerBGPSetupPrefixes exports external load balancer addresses as prefixes.
+func (n *common) loadBalancerBGPSetupPrefixes() error {
+	// Retrieve network load balancers before clearing existing prefixes.
+	lbListenAddresses, err := n.state.Cluster.GetNetworkLoadBalancerListenAddresses(n.ID(), true)
+	if err != nil {
+		return fmt.Errorf("Failed loading network load balancers: %w", err)
+	}
+
+	// Use load balancer specific owner string (different from the network prefixes) so that these can be
+	// reapplied independently of the network's own prefixes.
+	bgpOwner := fmt.Sprintf("network_%d_load_balancer", n.id)
+
+	return n.listenAddressBGPSetupPrefixes(bgpOwner, lbListenAddresses)
+}
+
+// listenAddressBGPSetupPrefixes replaces the prefixes exported for the specified owner with the external
+// listen addresses provided.
+func (n *common) listenAddressBGPSetupPrefixes(bgpOwner string, listenAddresses map[int64]string) error {
+	// Separate listen addresses by IP family.
+	listenAddressesByFamily := map[uint][]string{
 		4: make([]string, 0),
 		6: make([]string, 0),
 	}
 
-	for _, fwdListenAddress := range fwdListenAddresses {
-		if strings.Contains(fwdListenAddress, ":") {
-			fwdListenAddressesByFamily[6] = append(fwdListenAddressesByFamily[6], fwdListenAddress)
+	for _, listenAddress := range listenAddresses {
+		if strings.Contains(listenAddress, ":") {
+			listenAddressesByFamily[6] = append(listenAddressesByFamily[6], listenAddress)
 		} else {
-			fwdListenAddressesByFamily[4] = append(fwdListenAddressesByFamily[4], fwdListenAddress)
+			listenAddressesByFamily[4] = append(listenAddressesByFamily[4], listenAddress)
 		}
 	}
 
-	// Use forward specific owner string (different from the network prefixes) so that these can be reapplied
-	// independently of the network's own prefixes.
-	bgpOwner := fmt.Sprintf("network_%d_forward", n.id)
-
-	// Clear existing address forward prefixes for network.
-	err = n.state.BGP.RemovePrefixByOwner(bgpOwner)
+	// Clear existing prefixes for owner.
+	err := n.state.BGP.RemovePrefixByOwner(bgpOwner)
 	if err != nil {
 		return err
 	}
@@ -950,16 +1265,16 @@ func (n *common) forwardBGPSetupPrefixes() error {
 			routeSubnetSize = 32
 		}
 
-		// Export external forward listen addresses.
-		for _, fwdListenAddress := range fwdListenAddressesByFamily[ipVersion] {
-			fwdListenAddr := net.ParseIP(fwdListenAddress)
+		// Export external listen addresses.
+		for _, listenAddress := range listenAddressesByFamily[ipVersion] {
+			listenAddr := net.ParseIP(listenAddress)
 
-			// Don't export internal address forwards (those inside the NAT enabled network's subnet).
-			if natEnabled && netSubnet != nil && netSubnet.Contains(fwdListenAddr) {
+			// Don't export internal addresses (those inside the NAT enabled network's subnet).
+			if natEnabled && netSubnet != nil && netSubnet.Contains(listenAddr) {
 				continue
 			}
 
-			_, ipRouteSubnet, err := net.ParseCIDR(fmt.Sprintf("%s/%d", fwdListenAddr.String(), routeSubnetSize))
+			_, ipRouteSubnet, err := net.ParseCIDR(fmt.Sprintf("%s/%d", listenAddr.String(), routeSubnetSize))
 			if err != nil {
 				return err
 			}
@@ -979,7 +1294,7 @@ func (n *common) Leases(projectName string, clientType request.ClientType) ([]ap
 	return nil, ErrNotImplemented
 }
 
-// PeerCrete returns ErrNotImplemented for drivers that do not support forwards.
+// PeerCreate returns ErrNotImplemented for drivers that do not support forwards.
 func (n *common) PeerCreate(forward api.NetworkPeersPost) error {
 	return ErrNotImplemented
 }
@@ -1006,11 +1321,20 @@ func (n *common) peerValidate(peerName string, peer *api.NetworkPeerPut) error {
 	}
 
 	// Look for any unknown config fields.
-	for k := range peer.Config {
+	for k, v := range peer.Config {
 		if k == "target_address" {
 			continue
 		}
 
+		if k == "security.acls" {
+			err = acl.Exists(n.state, n.Project(), shared.SplitNTrimSpace(v, ",", -1, true)...)
+			if err != nil {
+				return fmt.Errorf("Invalid option %q: %w", k, err)
+			}
+
+			continue
+		}
+
 		// User keys are not validated.
 		if shared.IsUserConfig(k) {
 			continue
@@ -1,6 +1,7 @@
 package network
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -23,6 +24,7 @@ import (
 	"github.com/lxc/lxd/lxd/locking"
 	"github.com/lxc/lxd/lxd/network/acl"
 	"github.com/lxc/lxd/lxd/network/openvswitch"
+	"github.com/lxc/lxd/lxd/network/qos"
 	"github.com/lxc/lxd/lxd/project"
 	"github.com/lxc/lxd/lxd/revert"
 	"github.com/lxc/lxd/lxd/util"
@@ -99,7 +101,9 @@ func (n *ovn) Info() Info {
 	info.Projects = true
 	info.NodeSpecificConfig = false
 	info.AddressForwards = true
+	info.LoadBalancers = true
 	info.Peering = true
+	info.AddressReservations = true
 
 	return info
 }
@@ -254,6 +258,7 @@ func (n *ovn) getExternalSubnetInUse(uplinkNetworkName string) ([]externalSubnet
 	var err error
 	var projectNetworks map[string]map[int64]api.Network
 	var projectNetworksForwardsOnUplink map[string]map[int64][]string
+	var projectNetworksLoadBalancersOnUplink map[string]map[int64][]string
 
 	err = n.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
 		// Get all managed networks across all projects.
@@ -268,6 +273,12 @@ func (n *ovn) getExternalSubnetInUse(uplinkNetworkName string) ([]externalSubnet
 			return fmt.Errorf("Failed loading network forward listen addresses: %w", err)
 		}
 
+		// Get all network load balancer listen addresses for all networks (of any type) connected to our uplink.
+		projectNetworksLoadBalancersOnUplink, err = tx.GetProjectNetworkLoadBalancerListenAddressesByUplink(uplinkNetworkName)
+		if err != nil {
+			return fmt.Errorf("Failed loading network load balancer listen addresses: %w", err)
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -315,6 +326,28 @@ func (n *ovn) getExternalSubnetInUse(uplinkNetworkName string) ([]externalSubnet
 		}
 	}
 
+	// Add load balancer listen addresses to this list.
+	for projectName, networks := range projectNetworksLoadBalancersOnUplink {
+		for networkID, listenAddresses := range networks {
+			for _, listenAddress := range listenAddresses {
+				// Convert listen address to subnet.
+				listenAddressNet, err := ParseIPToNet(listenAddress)
+				if err != nil {
+					return nil, fmt.Errorf("Invalid existing load balancer listen address %q", listenAddress)
+				}
+
+				// Create an externalSubnetUsage for the listen address by using the network ID
+				// of the listen address to retrieve the already loaded network name from the
+				// projectNetworks map.
+				externalSubnets = append(externalSubnets, externalSubnetUsage{
+					subnet:         *listenAddressNet,
+					networkProject: projectName,
+					networkName:    projectNetworks[projectName][networkID].Name,
+				})
+			}
+		}
+	}
+
 	return externalSubnets, nil
 }
 
@@ -355,6 +388,7 @@ func (n *ovn) Validate(config map[string]string) error {
 		"security.acls.default.egress.action":  validate.Optional(validate.IsOneOf(acl.ValidActions...)),
 		"security.acls.default.ingress.logged": validate.Optional(validate.IsBool),
 		"security.acls.default.egress.logged":  validate.Optional(validate.IsBool),
+		"qos":                                  validate.IsAny,
 
 		// Volatile keys populated automatically as needed.
 		ovnVolatileUplinkIPv4: validate.Optional(validate.IsNetworkAddressV4),
@@ -567,6 +601,27 @@ func (n *ovn) Validate(config map[string]string) error {
 		}
 	}
 
+	// Check any existing network load balancer backend addresses are suitable for this network's subnet.
+	loadBalancers, err := n.state.Cluster.GetNetworkLoadBalancers(n.ID(), memberSpecific)
+	if err != nil {
+		return fmt.Errorf("Failed loading network load balancers: %w", err)
+	}
+
+	for _, loadBalancer := range loadBalancers {
+		for _, backend := range loadBalancer.Backends {
+			targetIP := net.ParseIP(backend.TargetAddress)
+
+			netSubnet := netSubnets["ipv4.address"]
+			if targetIP.To4() == nil {
+				netSubnet = netSubnets["ipv6.address"]
+			}
+
+			if !SubnetContainsIP(netSubnet, targetIP) {
+				return api.StatusErrorf(http.StatusBadRequest, "Network load balancer for %q has a backend target address %q that is not within the network subnet", loadBalancer.ListenAddress, targetIP.String())
+			}
+		}
+	}
+
 	// Check Security ACLs exist.
 	if config["security.acls"] != "" {
 		err = acl.Exists(n.state, n.project, shared.SplitNTrimSpace(config["security.acls"], ",", -1, true)...)
@@ -575,6 +630,14 @@ func (n *ovn) Validate(config map[string]string) error {
 		}
 	}
 
+	// Check QoS policy exists.
+	if config["qos"] != "" {
+		err = qos.Exists(n.state, n.project, config["qos"])
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -785,11 +848,6 @@ func (n *ovn) getIntSwitchRouterPortName() openvswitch.OVNSwitchPort {
 	return acl.OVNIntSwitchRouterPortName(n.id)
 }
 
-// getIntSwitchInstancePortPrefix returns OVN logical internal switch instance port name prefix.
-func (n *ovn) getIntSwitchInstancePortPrefix() string {
-	return fmt.Sprintf("%s-instance", n.getNetworkPrefix())
-}
-
 // getLoadBalancerName returns OVN load balancer name to use for a listen address.
 func (n *ovn) getLoadBalancerName(listenAddress string) openvswitch.OVNLoadBalancer {
 	return openvswitch.OVNLoadBalancer(fmt.Sprintf("%s-lb-%s", n.getNetworkPrefix(), listenAddress))
@@ -2033,17 +2091,8 @@ func (n *ovn) setup(update bool) error {
 		revert.Add(func() { client.LogicalSwitchDelete(n.getIntSwitchName()) })
 	}
 
-	var excludeIPV4 []shared.IPRange
-	if routerIntPortIPv4 != nil {
-		excludeIPV4 = []shared.IPRange{{Start: routerIntPortIPv4}}
-	}
-
 	// Setup IP allocation config on logical switch.
-	err = client.LogicalSwitchSetIPAllocation(n.getIntSwitchName(), &openvswitch.OVNIPAllocationOpts{
-		PrefixIPv4:  routerIntPortIPv4Net,
-		PrefixIPv6:  routerIntPortIPv6Net,
-		ExcludeIPv4: excludeIPV4,
-	})
+	err = n.logicalSwitchSetIPAllocation(client, routerIntPortIPv4, routerIntPortIPv4Net, routerIntPortIPv6Net)
 	if err != nil {
 		return fmt.Errorf("Failed setting IP allocation settings on internal switch: %w", err)
 	}
@@ -2510,6 +2559,39 @@ func (n *ovn) Delete(clientType request.ClientType) error {
 		if err != nil {
 			return fmt.Errorf("Failed deleting network forwards: %w", err)
 		}
+
+		// Delete any network load balancers.
+		lbListenAddresses, err := n.state.Cluster.GetNetworkLoadBalancerListenAddresses(n.ID(), memberSpecific)
+		if err != nil {
+			return fmt.Errorf("Failed loading network load balancers: %w", err)
+		}
+
+		loadBalancers = make([]openvswitch.OVNLoadBalancer, 0, len(lbListenAddresses))
+		for _, listenAddress := range lbListenAddresses {
+			loadBalancers = append(loadBalancers, n.getLoadBalancerName(listenAddress))
+		}
+
+		err = client.LoadBalancerDelete(loadBalancers...)
+		if err != nil {
+			return fmt.Errorf("Failed deleting network load balancers: %w", err)
+		}
+
+		// Delete the uplink routes address set if no other network is peered with the uplink.
+		peers, err := n.state.Cluster.GetNetworkPeers(n.ID())
+		if err != nil {
+			return fmt.Errorf("Failed loading network peers: %w", err)
+		}
+
+		for _, peer := range peers {
+			if peer.Type != "uplink" {
+				continue
+			}
+
+			err = n.peerUplinkDelete()
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	return n.common.delete(clientType)
@@ -2711,6 +2793,20 @@ func (n *ovn) Update(newNetwork api.NetworkPut, targetNode string, clientType re
 		return nil // Nothing changed.
 	}
 
+	// Uplink peers are tied to the uplink network, so the uplink can't be changed while they exist.
+	if shared.StringInSlice("network", changedKeys) {
+		peers, err := n.state.Cluster.GetNetworkPeers(n.ID())
+		if err != nil {
+			return fmt.Errorf("Failed loading network peers: %w", err)
+		}
+
+		for _, peer := range peers {
+			if peer.Type == "uplink" {
+				return api.StatusErrorf(http.StatusBadRequest, "Cannot change the uplink network while uplink peer %q exists", peer.Name)
+			}
+		}
+	}
+
 	// If the network as a whole has not had any previous creation attempts, or the node itself is still
 	// pending, then don't apply the new settings to the node, just to the database record (ready for the
 	// actual global create request to be initiated).
@@ -2806,6 +2902,7 @@ func (n *ovn) Update(newNetwork api.NetworkPut, targetNode string, clientType re
 		}
 
 		aclConfigChanged := len(addedACLs) > 0 || len(removedACLs) > 0 || len(changedDefaultRuleKeys) > 0
+		qosChanged := shared.StringInSlice("qos", changedKeys)
 
 		var localNICRoutes []net.IPNet
 
@@ -2899,6 +2996,19 @@ func (n *ovn) Update(newNetwork api.NetworkPut, targetNode string, clientType re
 				}
 			}
 
+			// Apply the network's QoS policy to the NICs that don't set their own.
+			if qosChanged && nicConfig["qos"] == "" {
+				qosRules, err := n.instanceDevicePortQoSRules(nicConfig)
+				if err != nil {
+					return err
+				}
+
+				err = client.LogicalSwitchPortSetQoSRules(n.getIntSwitchName(), instancePortName, qosRules...)
+				if err != nil {
+					return fmt.Errorf("Failed applying QoS rules for instance NIC: %w", err)
+				}
+			}
+
 			// Add NIC routes to list.
 			localNICRoutes = append(localNICRoutes, n.instanceNICGetRoutes(nicConfig)...)
 
@@ -2987,7 +3097,7 @@ func (n *ovn) Update(newNetwork api.NetworkPut, targetNode string, clientType re
 
 // getInstanceDevicePortName returns the switch port name to use for an instance device.
 func (n *ovn) getInstanceDevicePortName(instanceUUID string, deviceName string) openvswitch.OVNSwitchPort {
-	return openvswitch.OVNSwitchPort(fmt.Sprintf("%s-%s-%s", n.getIntSwitchInstancePortPrefix(), instanceUUID, deviceName))
+	return acl.OVNIntSwitchInstancePortName(n.id, instanceUUID, deviceName)
 }
 
 // instanceDevicePortRoutesParse parses the instance NIC device config for internal routes and external routes.
@@ -3118,6 +3228,27 @@ func (n *ovn) InstanceDevicePortValidateExternalRoutes(deviceInstance instance.I
 	return nil
 }
 
+// instanceDevicePortQoSRules returns the OVN QoS rules of the QoS policy that applies to an instance NIC, either
+// set on the NIC or inherited from the network.
+func (n *ovn) instanceDevicePortQoSRules(deviceConfig map[string]string) ([]openvswitch.OVNQoSRule, error) {
+	policyName := qos.NICPolicyName(deviceConfig, n.config)
+	if policyName == "" {
+		return nil, nil
+	}
+
+	policy, err := qos.LoadByName(n.state, n.project, policyName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading network QoS policy %q: %w", policyName, err)
+	}
+
+	limits, err := policy.Limits()
+	if err != nil {
+		return nil, err
+	}
+
+	return limits.OVNRules(), nil
+}
+
 // InstanceDevicePortAdd sets up an instance device port to the internal logical switch and returns the port name.
 // Accepts a list of ACLs being removed from the NIC device (if called as part of a NIC update).
 func (n *ovn) InstanceDevicePortSetup(opts *OVNInstanceNICSetupOpts, securityACLsRemove []string) (openvswitch.OVNSwitchPort, error) {
@@ -3243,6 +3374,19 @@ func (n *ovn) InstanceDevicePortSetup(opts *OVNInstanceNICSetupOpts, securityACL
 
 	revert.Add(func() { client.LogicalSwitchPortDelete(instancePortName) })
 
+	// Apply the QoS policy of the NIC or of the network, or clear any left over rules.
+	qosRules, err := n.instanceDevicePortQoSRules(opts.DeviceConfig)
+	if err != nil {
+		return "", err
+	}
+
+	err = client.LogicalSwitchPortSetQoSRules(n.getIntSwitchName(), instancePortName, qosRules...)
+	if err != nil {
+		return "", fmt.Errorf("Failed applying QoS rules for %q: %w", instancePortName, err)
+	}
+
+	revert.Add(func() { _ = client.LogicalSwitchPortSetQoSRules(n.getIntSwitchName(), instancePortName) })
+
 	// Add DNS records for port's IPs, and retrieve the IP addresses used.
 	dnsName := fmt.Sprintf("%s.%s", opts.DNSName, n.getDomainName())
 	var dnsUUID openvswitch.OVNDNSUUID
@@ -3393,12 +3537,18 @@ func (n *ovn) InstanceDevicePortSetup(opts *OVNInstanceNICSetupOpts, securityACL
 
 		// Add routes to peer routers, and security policies for each peer port on local router.
 		err = n.forPeers(func(targetOVNNet *ovn) error {
+			// Only add the routes that the peering is allowed to leak.
+			leakedPrefixes, err := n.peerLeakedRoutes(targetOVNNet, routePrefixes)
+			if err != nil {
+				return err
+			}
+
 			targetRouterName := targetOVNNet.getRouterName()
 			targetRouterPort := targetOVNNet.getLogicalRouterPeerPortName(n.ID())
-			targetRouterRoutes := make([]openvswitch.OVNRouterRoute, 0, len(routes))
-			for _, route := range routes {
+			targetRouterRoutes := make([]openvswitch.OVNRouterRoute, 0, len(leakedPrefixes))
+			for _, prefix := range leakedPrefixes {
 				nexthop := routerIntPortIPv4
-				if route.Prefix.IP.To4() == nil {
+				if prefix.IP.To4() == nil {
 					nexthop = routerIntPortIPv6
 				}
 
@@ -3407,7 +3557,7 @@ func (n *ovn) InstanceDevicePortSetup(opts *OVNInstanceNICSetupOpts, securityACL
 				}
 
 				targetRouterRoutes = append(targetRouterRoutes, openvswitch.OVNRouterRoute{
-					Prefix:  route.Prefix,
+					Prefix:  prefix,
 					NextHop: nexthop,
 					Port:    targetRouterPort,
 				})
@@ -3418,7 +3568,7 @@ func (n *ovn) InstanceDevicePortSetup(opts *OVNInstanceNICSetupOpts, securityACL
 				return fmt.Errorf("Failed adding static routes to peer network %q in project %q: %w", targetOVNNet.Name(), targetOVNNet.Project(), err)
 			}
 
-			revert.Add(func() { client.LogicalRouterRouteDelete(targetRouterName, routePrefixes...) })
+			revert.Add(func() { client.LogicalRouterRouteDelete(targetRouterName, leakedPrefixes...) })
 
 			return nil
 		})
@@ -3613,6 +3763,12 @@ func (n *ovn) InstanceDevicePortDelete(ovsExternalOVNPort openvswitch.OVNSwitchP
 		return err
 	}
 
+	// Remove the QoS rules of the port.
+	err = client.LogicalSwitchPortSetQoSRules(n.getIntSwitchName(), instancePortName)
+	if err != nil {
+		return fmt.Errorf("Failed removing QoS rules for %q: %w", instancePortName, err)
+	}
+
 	// Cleanup logical switch port and associated config.
 	err = client.LogicalSwitchPortCleanup(instancePortName, n.getIntSwitchName(), acl.OVNIntSwitchPortGroupName(n.ID()), dnsUUID)
 	if err != nil {
@@ -3664,10 +3820,15 @@ func (n *ovn) InstanceDevicePortDelete(ovsExternalOVNPort openvswitch.OVNSwitchP
 			return fmt.Errorf("Failed deleting switch address set entries: %w", err)
 		}
 
-		// Delete routes from peer routers.
+		// Delete routes from peer routers, including the narrower routes leaked in their place.
 		err = n.forPeers(func(targetOVNNet *ovn) error {
+			leakedRoutes, err := n.peerLeakedRoutes(targetOVNNet, removeRoutes)
+			if err != nil {
+				return err
+			}
+
 			targetRouterName := targetOVNNet.getRouterName()
-			err = client.LogicalRouterRouteDelete(targetRouterName, removeRoutes...)
+			err = client.LogicalRouterRouteDelete(targetRouterName, append(removeRoutes, leakedRoutes...)...)
 			if err != nil {
 				return fmt.Errorf("Failed deleting static routes from peer network %q in project %q: %w", targetOVNNet.Name(), targetOVNNet.Project(), err)
 			}
@@ -3880,6 +4041,14 @@ func (n *ovn) handleDependencyChange(uplinkName string, uplinkConfig map[string]
 		}
 	}
 
+	// Update the uplink routes address set if the uplink's routed subnets have changed and we are peered with it.
+	if shared.StringInSlice("ipv4.routes", changedKeys) || shared.StringInSlice("ipv6.routes", changedKeys) {
+		err := n.peerUplinkRefresh(uplinkName, uplinkConfig)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Add or remove the instance NIC l2proxy DNAT_AND_SNAT rules if uplink's ovn.ingress_mode has changed.
 	if shared.StringInSlice("ovn.ingress_mode", changedKeys) {
 		n.logger.Debug("Applying ingress mode changes from uplink network to instance NICs", logger.Ctx{"uplink": uplinkName})
@@ -3970,7 +4139,11 @@ func (n *ovn) forwardFlattenVIPs(listenAddress net.IP, defaultTargetAddress net.
 	if defaultTargetAddress != nil {
 		vips = append(vips, openvswitch.OVNLoadBalancerVIP{
 			ListenAddress: listenAddress,
-			TargetAddress: defaultTargetAddress,
+			Targets: []openvswitch.OVNLoadBalancerTarget{
+				{
+					Address: defaultTargetAddress,
+				},
+			},
 		})
 	}
 
@@ -3992,9 +4165,13 @@ func (n *ovn) forwardFlattenVIPs(listenAddress net.IP, defaultTargetAddress net.
 			vips = append(vips, openvswitch.OVNLoadBalancerVIP{
 				ListenAddress: listenAddress,
 				Protocol:      portMap.protocol,
-				TargetAddress: portMap.targetAddress,
 				ListenPort:    lp,
-				TargetPort:    targetPort,
+				Targets: []openvswitch.OVNLoadBalancerTarget{
+					{
+						Address: portMap.targetAddress,
+						Port:    targetPort,
+					},
+				},
 			})
 		}
 	}
@@ -4016,6 +4193,12 @@ func (n *ovn) ForwardCreate(forward api.NetworkForwardsPost, clientType request.
 			return api.StatusErrorf(http.StatusConflict, "A forward for that listen address already exists")
 		}
 
+		// Check if there is an existing load balancer using the same listen address.
+		_, _, err = n.state.Cluster.GetNetworkLoadBalancer(n.ID(), memberSpecific, forward.ListenAddress)
+		if err == nil {
+			return api.StatusErrorf(http.StatusConflict, "A load balancer for that listen address already exists")
+		}
+
 		// Convert listen address to subnet so we can check its valid and can be used.
 		listenAddressNet, err := ParseIPToNet(forward.ListenAddress)
 		if err != nil {
@@ -4258,152 +4441,626 @@ func (n *ovn) ForwardDelete(listenAddress string, clientType request.ClientType)
 	return nil
 }
 
-// Leases returns a list of leases for the OVN network. Those are directly extracted from the OVN database.
-func (n *ovn) Leases(projectName string, clientType request.ClientType) ([]api.NetworkLease, error) {
-	leases := []api.NetworkLease{}
-
-	// Get all the instances.
-	instances, err := instance.LoadByProject(n.state, projectName)
-	if err != nil {
-		return nil, err
-	}
-
-	for _, inst := range instances {
-		// Get the instance UUID.
-		instanceUUID := inst.LocalConfig()["volatile.uuid"]
-		if instanceUUID == "" {
-			continue
-		}
-
-		// Get the instances IPs.
-		for devName, dev := range inst.ExpandedDevices() {
-			if dev["type"] != "nic" || dev["network"] != n.name {
-				continue
-			}
-
-			devIPs, err := n.InstanceDevicePortDynamicIPs(instanceUUID, devName)
-			if err != nil {
-				return nil, err
-			}
+// loadBalancerFlattenVIPs flattens load balancers into format compatible with OVN load balancers.
+func (n *ovn) loadBalancerFlattenVIPs(listenAddress net.IP, portMaps []*loadBalancerPortMap) []openvswitch.OVNLoadBalancerVIP {
+	var vips []openvswitch.OVNLoadBalancerVIP
 
-			// Fill in the hwaddr from volatile.
-			if dev["hwaddr"] == "" {
-				dev["hwaddr"] = inst.LocalConfig()[fmt.Sprintf("volatile.%s.hwaddr", devName)]
+	for _, portMap := range portMaps {
+		for i, lp := range portMap.listenPorts {
+			vip := openvswitch.OVNLoadBalancerVIP{
+				ListenAddress: listenAddress,
+				Protocol:      portMap.protocol,
+				ListenPort:    lp,
 			}
 
-			// Add the leases.
-			for _, ip := range devIPs {
-				leaseType := "dynamic"
-				if dev["ipv4.addres"] == ip.String() || dev["ipv6.address"] == ip.String() {
-					leaseType = "static"
+			for _, target := range portMap.targets {
+				targetPortsLen := len(target.ports)
+				targetPort := lp // Default to using same port as listen port for target port.
+
+				if targetPortsLen == 1 {
+					// If a single target port is specified, forward all listen ports to it.
+					targetPort = target.ports[0]
+				} else if targetPortsLen > 1 {
+					// If more than 1 target port specified, use listen port index to get the
+					// target port to use.
+					targetPort = target.ports[i]
 				}
 
-				leases = append(leases, api.NetworkLease{
-					Hostname: inst.Name(),
-					Address:  ip.String(),
-					Hwaddr:   dev["hwaddr"],
-					Type:     leaseType,
-					Location: inst.Location(),
+				vip.Targets = append(vip.Targets, openvswitch.OVNLoadBalancerTarget{
+					Address: target.address,
+					Port:    targetPort,
 				})
 			}
+
+			vips = append(vips, vip)
 		}
 	}
 
-	return leases, nil
+	return vips
 }
 
-// PeerCreate creates a network peering.
-func (n *ovn) PeerCreate(peer api.NetworkPeersPost) error {
+// LoadBalancerCreate creates a network load balancer.
+func (n *ovn) LoadBalancerCreate(loadBalancer api.NetworkLoadBalancersPost, clientType request.ClientType) error {
 	revert := revert.New()
 	defer revert.Fail()
 
-	// Perform create-time validation.
-
-	// Default to network's project if target project not specified.
-	if peer.TargetProject == "" {
-		peer.TargetProject = n.Project()
-	}
-
-	// Target network name is required.
-	if peer.TargetNetwork == "" {
-		return api.StatusErrorf(http.StatusBadRequest, "Target network is required")
-	}
-
-	// Check if there is an existing peer using the same name, or whether there is already a peering (in any
-	// state) to the target network.
-	peers, err := n.state.Cluster.GetNetworkPeers(n.ID())
-	if err == nil {
-	}
+	if clientType == request.ClientTypeNormal {
+		memberSpecific := false // OVN doesn't support per-member load balancers.
 
-	for _, existingPeer := range peers {
-		if peer.Name == existingPeer.Name {
-			return api.StatusErrorf(http.StatusConflict, "A peer for that name already exists")
+		// Check if there is an existing load balancer using the same listen address.
+		_, _, err := n.state.Cluster.GetNetworkLoadBalancer(n.ID(), memberSpecific, loadBalancer.ListenAddress)
+		if err == nil {
+			return api.StatusErrorf(http.StatusConflict, "A load balancer for that listen address already exists")
 		}
 
-		if peer.TargetProject == existingPeer.TargetProject && peer.TargetNetwork == existingPeer.TargetNetwork {
-			return api.StatusErrorf(http.StatusConflict, "A peer for that target network already exists")
+		// Check if there is an existing forward using the same listen address.
+		_, _, err = n.state.Cluster.GetNetworkForward(n.ID(), memberSpecific, loadBalancer.ListenAddress)
+		if err == nil {
+			return api.StatusErrorf(http.StatusConflict, "A forward for that listen address already exists")
 		}
-	}
-
-	// Perform general (create and update) validation.
-	err = n.peerValidate(peer.Name, &peer.NetworkPeerPut)
-	if err != nil {
-		return err
-	}
-
-	// Create peer DB record.
-	peerID, mutualExists, err := n.state.Cluster.CreateNetworkPeer(n.ID(), &peer)
-	if err != nil {
-		return err
-	}
-
-	revert.Add(func() {
-		n.state.Cluster.DeleteNetworkPeer(n.ID(), peerID)
-	})
 
-	if mutualExists {
-		// Load peering to get mutual peering info.
-		_, peerInfo, err := n.state.Cluster.GetNetworkPeer(n.ID(), peer.Name)
+		// Convert listen address to subnet so we can check its valid and can be used.
+		listenAddressNet, err := ParseIPToNet(loadBalancer.ListenAddress)
 		if err != nil {
-			return err
+			return fmt.Errorf("Failed parsing %q: %w", loadBalancer.ListenAddress, err)
 		}
 
-		if peerInfo.Status != api.NetworkStatusCreated {
-			return fmt.Errorf("Only peerings in %q state can be setup", api.NetworkStatusCreated)
+		portMaps, err := n.loadBalancerValidate(listenAddressNet.IP, &loadBalancer.NetworkLoadBalancerPut)
+		if err != nil {
+			return err
 		}
 
-		client, err := openvswitch.NewOVN(n.state)
+		// Load the project to get uplink network restrictions.
+		p, err := n.state.Cluster.GetProject(n.project)
 		if err != nil {
-			return fmt.Errorf("Failed to get OVN client: %w", err)
+			return fmt.Errorf("Failed to load network restrictions from project %q: %w", n.project, err)
 		}
 
-		// Apply router security policies.
-		// Should have been done during network setup, but ensure its done here anyway.
-		err = n.logicalRouterPolicySetup(client)
+		// Get uplink routes.
+		_, uplink, _, err := n.state.Cluster.GetNetworkInAnyState(project.Default, n.config["network"])
 		if err != nil {
-			return fmt.Errorf("Failed applying local router security policy: %w", err)
+			return fmt.Errorf("Failed to load uplink network %q: %w", n.config["network"], err)
 		}
 
-		activeLocalNICPorts, err := client.LogicalSwitchPorts(n.getIntSwitchName())
+		uplinkRoutes, err := n.uplinkRoutes(uplink)
 		if err != nil {
-			return fmt.Errorf("Failed getting active NIC ports: %w", err)
+			return err
 		}
 
-		var localNICRoutes []net.IPNet
-
-		// Get routes on instance NICs connected to local network to be added as routes to target network.
-		err = usedByInstanceDevices(n.state, n.Project(), n.Name(), func(inst db.Instance, nicName string, nicConfig map[string]string) error {
-			instancePortName := n.getInstanceDevicePortName(inst.Config["volatile.uuid"], nicName)
-			if _, found := activeLocalNICPorts[instancePortName]; !found {
-				return nil // Don't add config for instance NICs that aren't started.
-			}
-
-			localNICRoutes = append(localNICRoutes, n.instanceNICGetRoutes(nicConfig)...)
-
-			return nil
-		})
+		// Get project restricted routes.
+		projectRestrictedSubnets, err := n.projectRestrictedSubnets(p, n.config["network"])
 		if err != nil {
-			return fmt.Errorf("Failed getting instance NIC routes on local network: %w", err)
+			return err
+		}
+
+		externalSubnetsInUse, err := n.getExternalSubnetInUse(n.config["network"])
+		if err != nil {
+			return err
+		}
+
+		// Check the listen address subnet is allowed within both the uplink's external routes and any
+		// project restricted subnets.
+		err = n.validateExternalSubnet(uplinkRoutes, projectRestrictedSubnets, listenAddressNet)
+		if err != nil {
+			return err
+		}
+
+		// Check the listen address subnet doesn't fall within any existing OVN network external subnets.
+		for _, externalSubnetUser := range externalSubnetsInUse {
+			// Skip our own network's SNAT address (as it can be used for NICs in the network).
+			if externalSubnetUser.networkSNAT && externalSubnetUser.networkProject == n.project && externalSubnetUser.networkName == n.name {
+				continue
+			}
+
+			// Skip our own network (but not NIC devices on our own network).
+			if externalSubnetUser.networkProject == n.project && externalSubnetUser.networkName == n.name && externalSubnetUser.instanceDevice == "" {
+				continue
+			}
+
+			if SubnetContains(&externalSubnetUser.subnet, listenAddressNet) || SubnetContains(listenAddressNet, &externalSubnetUser.subnet) {
+				// This error is purposefully vague so that it doesn't reveal any names of
+				// resources potentially outside of the network's project.
+				return fmt.Errorf("Load balancer listen address %q overlaps with another OVN network or NIC", listenAddressNet.String())
+			}
+		}
+
+		client, err := openvswitch.NewOVN(n.state)
+		if err != nil {
+			return fmt.Errorf("Failed to get OVN client: %w", err)
+		}
+
+		// Create load balancer DB record.
+		loadBalancerID, err := n.state.Cluster.CreateNetworkLoadBalancer(n.ID(), memberSpecific, &loadBalancer)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() {
+			n.state.Cluster.DeleteNetworkLoadBalancer(n.ID(), loadBalancerID)
+			client.LoadBalancerDelete(n.getLoadBalancerName(loadBalancer.ListenAddress))
+			n.loadBalancerBGPSetupPrefixes()
+		})
+
+		vips := n.loadBalancerFlattenVIPs(net.ParseIP(loadBalancer.ListenAddress), portMaps)
+
+		err = client.LoadBalancerApply(n.getLoadBalancerName(loadBalancer.ListenAddress), []openvswitch.OVNRouter{n.getRouterName()}, []openvswitch.OVNSwitch{n.getIntSwitchName()}, vips...)
+		if err != nil {
+			return fmt.Errorf("Failed applying OVN load balancer: %w", err)
+		}
+
+		// Notify all other members to refresh their BGP prefixes.
+		notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return err
+		}
+
+		err = notifier(func(client lxd.InstanceServer) error {
+			return client.UseProject(n.project).CreateNetworkLoadBalancer(n.name, loadBalancer)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Refresh exported BGP prefixes on local member.
+	err := n.loadBalancerBGPSetupPrefixes()
+	if err != nil {
+		return fmt.Errorf("Failed applying BGP prefixes for load balancers: %w", err)
+	}
+
+	revert.Success()
+	return nil
+}
+
+// LoadBalancerUpdate updates a network load balancer.
+func (n *ovn) LoadBalancerUpdate(listenAddress string, req api.NetworkLoadBalancerPut, clientType request.ClientType) error {
+	revert := revert.New()
+	defer revert.Fail()
+
+	if clientType == request.ClientTypeNormal {
+		memberSpecific := false // OVN doesn't support per-member load balancers.
+		curLoadBalancerID, curLoadBalancer, err := n.state.Cluster.GetNetworkLoadBalancer(n.ID(), memberSpecific, listenAddress)
+		if err != nil {
+			return err
+		}
+
+		portMaps, err := n.loadBalancerValidate(net.ParseIP(curLoadBalancer.ListenAddress), &req)
+		if err != nil {
+			return err
+		}
+
+		curLoadBalancerEtagHash, err := util.EtagHash(curLoadBalancer.Etag())
+		if err != nil {
+			return err
+		}
+
+		newLoadBalancer := api.NetworkLoadBalancer{
+			ListenAddress:          curLoadBalancer.ListenAddress,
+			NetworkLoadBalancerPut: req,
+		}
+
+		newLoadBalancerEtagHash, err := util.EtagHash(newLoadBalancer.Etag())
+		if err != nil {
+			return err
+		}
+
+		if curLoadBalancerEtagHash == newLoadBalancerEtagHash {
+			return nil // Nothing has changed.
+		}
+
+		client, err := openvswitch.NewOVN(n.state)
+		if err != nil {
+			return fmt.Errorf("Failed to get OVN client: %w", err)
+		}
+
+		vips := n.loadBalancerFlattenVIPs(net.ParseIP(newLoadBalancer.ListenAddress), portMaps)
+		err = client.LoadBalancerApply(n.getLoadBalancerName(newLoadBalancer.ListenAddress), []openvswitch.OVNRouter{n.getRouterName()}, []openvswitch.OVNSwitch{n.getIntSwitchName()}, vips...)
+		if err != nil {
+			return fmt.Errorf("Failed applying OVN load balancer: %w", err)
+		}
+
+		revert.Add(func() {
+			// Apply old settings to OVN on failure.
+			curPortMaps, err := n.loadBalancerValidate(net.ParseIP(curLoadBalancer.ListenAddress), &curLoadBalancer.NetworkLoadBalancerPut)
+			if err == nil {
+				vips := n.loadBalancerFlattenVIPs(net.ParseIP(curLoadBalancer.ListenAddress), curPortMaps)
+				client.LoadBalancerApply(n.getLoadBalancerName(curLoadBalancer.ListenAddress), []openvswitch.OVNRouter{n.getRouterName()}, []openvswitch.OVNSwitch{n.getIntSwitchName()}, vips...)
+			}
+
+			n.loadBalancerBGPSetupPrefixes()
+		})
+
+		err = n.state.Cluster.UpdateNetworkLoadBalancer(n.ID(), curLoadBalancerID, &newLoadBalancer.NetworkLoadBalancerPut)
+		if err != nil {
+			return err
+		}
+
+		// Notify all other members to refresh their BGP prefixes.
+		notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return err
+		}
+
+		err = notifier(func(client lxd.InstanceServer) error {
+			return client.UseProject(n.project).UpdateNetworkLoadBalancer(n.name, curLoadBalancer.ListenAddress, req, "")
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Refresh exported BGP prefixes on local member.
+	err := n.loadBalancerBGPSetupPrefixes()
+	if err != nil {
+		return fmt.Errorf("Failed applying BGP prefixes for load balancers: %w", err)
+	}
+
+	revert.Success()
+	return nil
+}
+
+// LoadBalancerDelete deletes a network load balancer.
+func (n *ovn) LoadBalancerDelete(listenAddress string, clientType request.ClientType) error {
+	if clientType == request.ClientTypeNormal {
+		memberSpecific := false // OVN doesn't support per-member load balancers.
+		loadBalancerID, loadBalancer, err := n.state.Cluster.GetNetworkLoadBalancer(n.ID(), memberSpecific, listenAddress)
+		if err != nil {
+			return err
+		}
+
+		client, err := openvswitch.NewOVN(n.state)
+		if err != nil {
+			return fmt.Errorf("Failed to get OVN client: %w", err)
+		}
+
+		err = client.LoadBalancerDelete(n.getLoadBalancerName(loadBalancer.ListenAddress))
+		if err != nil {
+			return fmt.Errorf("Failed deleting OVN load balancer: %w", err)
+		}
+
+		err = n.state.Cluster.DeleteNetworkLoadBalancer(n.ID(), loadBalancerID)
+		if err != nil {
+			return err
+		}
+
+		// Notify all other members to refresh their BGP prefixes.
+		notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return err
+		}
+
+		err = notifier(func(client lxd.InstanceServer) error {
+			return client.UseProject(n.project).DeleteNetworkLoadBalancer(n.name, loadBalancer.ListenAddress)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Refresh exported BGP prefixes on local member.
+	err := n.loadBalancerBGPSetupPrefixes()
+	if err != nil {
+		return fmt.Errorf("Failed applying BGP prefixes for load balancers: %w", err)
+	}
+
+	return nil
+}
+
+// logicalSwitchSetIPAllocation sets the IP allocation config on the internal logical switch. The router's internal
+// port IPv4 address and the reserved IPv4 addresses are excluded from dynamic allocation.
+func (n *ovn) logicalSwitchSetIPAllocation(client *openvswitch.OVN, routerIntPortIPv4 net.IP, routerIntPortIPv4Net *net.IPNet, routerIntPortIPv6Net *net.IPNet) error {
+	var excludeIPV4 []shared.IPRange
+	if routerIntPortIPv4 != nil {
+		excludeIPV4 = []shared.IPRange{{Start: routerIntPortIPv4}}
+	}
+
+	reservations, err := n.state.Cluster.GetNetworkReservations(n.ID())
+	if err != nil {
+		return fmt.Errorf("Failed loading address reservations: %w", err)
+	}
+
+	// Sort the reserved addresses so that the exclusion list is stable.
+	reservedIPv4s := make([]net.IP, 0, len(reservations))
+	for _, reservation := range reservations {
+		ip := net.ParseIP(reservation.Address)
+		if routerIntPortIPv4Net != nil && ip != nil && ip.To4() != nil && routerIntPortIPv4Net.Contains(ip) {
+			reservedIPv4s = append(reservedIPv4s, ip.To4())
+		}
+	}
+
+	sort.Slice(reservedIPv4s, func(i, j int) bool {
+		return bytes.Compare(reservedIPv4s[i], reservedIPv4s[j]) < 0
+	})
+
+	for _, ip := range reservedIPv4s {
+		excludeIPV4 = append(excludeIPV4, shared.IPRange{Start: ip})
+	}
+
+	return client.LogicalSwitchSetIPAllocation(n.getIntSwitchName(), &openvswitch.OVNIPAllocationOpts{
+		PrefixIPv4:  routerIntPortIPv4Net,
+		PrefixIPv6:  routerIntPortIPv6Net,
+		ExcludeIPv4: excludeIPV4,
+	})
+}
+
+// reservationsApply refreshes the addresses excluded from dynamic allocation on the internal logical switch.
+func (n *ovn) reservationsApply() error {
+	var err error
+	var routerIntPortIPv4 net.IP
+	var routerIntPortIPv4Net, routerIntPortIPv6Net *net.IPNet
+
+	if validate.IsOneOf("none", "")(n.getRouterIntPortIPv4Net()) != nil {
+		routerIntPortIPv4, routerIntPortIPv4Net, err = net.ParseCIDR(n.getRouterIntPortIPv4Net())
+		if err != nil {
+			return fmt.Errorf("Failed parsing router's internal port IPv4 Net: %w", err)
+		}
+	}
+
+	if validate.IsOneOf("none", "")(n.getRouterIntPortIPv6Net()) != nil {
+		_, routerIntPortIPv6Net, err = net.ParseCIDR(n.getRouterIntPortIPv6Net())
+		if err != nil {
+			return fmt.Errorf("Failed parsing router's internal port IPv6 Net: %w", err)
+		}
+	}
+
+	client, err := openvswitch.NewOVN(n.state)
+	if err != nil {
+		return fmt.Errorf("Failed to get OVN client: %w", err)
+	}
+
+	err = n.logicalSwitchSetIPAllocation(client, routerIntPortIPv4, routerIntPortIPv4Net, routerIntPortIPv6Net)
+	if err != nil {
+		return fmt.Errorf("Failed setting IP allocation settings on internal switch: %w", err)
+	}
+
+	return nil
+}
+
+// ReservationCreate creates a network address reservation.
+func (n *ovn) ReservationCreate(projectName string, reservation api.NetworkReservationsPost, clientType request.ClientType) error {
+	// OVN doesn't need any per-member setup for reservations.
+	if clientType != request.ClientTypeNormal {
+		return nil
+	}
+
+	// Check if there is an existing reservation for the same address.
+	_, _, err := n.state.Cluster.GetNetworkReservation(n.ID(), reservation.Address)
+	if err == nil {
+		return api.StatusErrorf(http.StatusConflict, "A reservation for that address already exists")
+	}
+
+	err = n.reservationValidate(net.ParseIP(reservation.Address), projectName, &reservation.NetworkReservationPut)
+	if err != nil {
+		return err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Create reservation DB record.
+	reservationID, err := n.state.Cluster.CreateNetworkReservation(n.ID(), projectName, &reservation)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		n.state.Cluster.DeleteNetworkReservation(n.ID(), reservationID)
+		n.reservationsApply()
+	})
+
+	err = n.reservationsApply()
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// ReservationUpdate updates a network address reservation.
+func (n *ovn) ReservationUpdate(address string, req api.NetworkReservationPut, clientType request.ClientType) error {
+	if clientType != request.ClientTypeNormal {
+		return nil
+	}
+
+	curReservationID, curReservation, err := n.state.Cluster.GetNetworkReservation(n.ID(), address)
+	if err != nil {
+		return err
+	}
+
+	err = n.reservationValidate(net.ParseIP(curReservation.Address), curReservation.Project, &req)
+	if err != nil {
+		return err
+	}
+
+	// The reserved address doesn't change, so there is nothing to apply in OVN.
+	return n.state.Cluster.UpdateNetworkReservation(n.ID(), curReservationID, &req)
+}
+
+// ReservationDelete deletes a network address reservation.
+func (n *ovn) ReservationDelete(address string, clientType request.ClientType) error {
+	if clientType != request.ClientTypeNormal {
+		return nil
+	}
+
+	reservationID, reservation, err := n.state.Cluster.GetNetworkReservation(n.ID(), address)
+	if err != nil {
+		return err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	err = n.state.Cluster.DeleteNetworkReservation(n.ID(), reservationID)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		newReservation := api.NetworkReservationsPost{
+			NetworkReservationPut: reservation.NetworkReservationPut,
+			Address:               reservation.Address,
+		}
+
+		n.state.Cluster.CreateNetworkReservation(n.ID(), reservation.Project, &newReservation)
+		n.reservationsApply()
+	})
+
+	err = n.reservationsApply()
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// Leases returns a list of leases for the OVN network. Those are directly extracted from the OVN database.
+func (n *ovn) Leases(projectName string, clientType request.ClientType) ([]api.NetworkLease, error) {
+	leases := []api.NetworkLease{}
+
+	// Get all the instances.
+	instances, err := instance.LoadByProject(n.state, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, inst := range instances {
+		// Get the instance UUID.
+		instanceUUID := inst.LocalConfig()["volatile.uuid"]
+		if instanceUUID == "" {
+			continue
+		}
+
+		// Get the instances IPs.
+		for devName, dev := range inst.ExpandedDevices() {
+			if dev["type"] != "nic" || dev["network"] != n.name {
+				continue
+			}
+
+			devIPs, err := n.InstanceDevicePortDynamicIPs(instanceUUID, devName)
+			if err != nil {
+				return nil, err
+			}
+
+			// Fill in the hwaddr from volatile.
+			if dev["hwaddr"] == "" {
+				dev["hwaddr"] = inst.LocalConfig()[fmt.Sprintf("volatile.%s.hwaddr", devName)]
+			}
+
+			// Add the leases.
+			for _, ip := range devIPs {
+				leaseType := "dynamic"
+				if dev["ipv4.addres"] == ip.String() || dev["ipv6.address"] == ip.String() {
+					leaseType = "static"
+				}
+
+				leases = append(leases, api.NetworkLease{
+					Hostname: inst.Name(),
+					Address:  ip.String(),
+					Hwaddr:   dev["hwaddr"],
+					Type:     leaseType,
+					Location: inst.Location(),
+				})
+			}
+		}
+	}
+
+	return leases, nil
+}
+
+// PeerCreate creates a network peering.
+func (n *ovn) PeerCreate(peer api.NetworkPeersPost) error {
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Perform create-time validation.
+
+	// Default to a peering with another network.
+	if peer.Type == "" {
+		peer.Type = "network"
+	}
+
+	peerType, found := db.NetworkPeerTypes[peer.Type]
+	if !found {
+		return api.StatusErrorf(http.StatusBadRequest, "Invalid peer type %q", peer.Type)
+	}
+
+	if peerType == db.NetworkPeerTypeUplink {
+		// Default to the network's uplink if not specified.
+		if peer.TargetProject == "" {
+			peer.TargetProject = project.Default
+		}
+
+		if peer.TargetNetwork == "" {
+			peer.TargetNetwork = n.config["network"]
+		}
+
+		if peer.TargetProject != project.Default || peer.TargetNetwork != n.config["network"] {
+			return api.StatusErrorf(http.StatusBadRequest, "Uplink peers can only target the network's uplink %q", n.config["network"])
+		}
+
+		if peer.Config["security.acls"] != "" {
+			return api.StatusErrorf(http.StatusBadRequest, "Uplink peers don't support %q", "security.acls")
+		}
+	}
+
+	// Default to network's project if target project not specified.
+	if peer.TargetProject == "" {
+		peer.TargetProject = n.Project()
+	}
+
+	// Target network name is required.
+	if peer.TargetNetwork == "" {
+		return api.StatusErrorf(http.StatusBadRequest, "Target network is required")
+	}
+
+	// Check if there is an existing peer using the same name, or whether there is already a peering (in any
+	// state) to the target network.
+	peers, err := n.state.Cluster.GetNetworkPeers(n.ID())
+	if err != nil {
+		return err
+	}
+
+	for _, existingPeer := range peers {
+		if peer.Name == existingPeer.Name {
+			return api.StatusErrorf(http.StatusConflict, "A peer for that name already exists")
+		}
+
+		if peer.TargetProject == existingPeer.TargetProject && peer.TargetNetwork == existingPeer.TargetNetwork {
+			return api.StatusErrorf(http.StatusConflict, "A peer for that target network already exists")
+		}
+	}
+
+	// Perform general (create and update) validation.
+	err = n.peerValidate(peer.Name, &peer.NetworkPeerPut)
+	if err != nil {
+		return err
+	}
+
+	if peerType == db.NetworkPeerTypeUplink {
+		return n.peerUplinkCreate(peer)
+	}
+
+	// Create peer DB record.
+	peerID, mutualExists, err := n.state.Cluster.CreateNetworkPeer(n.ID(), &peer)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		n.state.Cluster.DeleteNetworkPeer(n.ID(), peerID)
+	})
+
+	if mutualExists {
+		// Load peering to get mutual peering info.
+		_, peerInfo, err := n.state.Cluster.GetNetworkPeer(n.ID(), peer.Name)
+		if err != nil {
+			return err
+		}
+
+		if peerInfo.Status != api.NetworkStatusCreated {
+			return fmt.Errorf("Only peerings in %q state can be setup", api.NetworkStatusCreated)
 		}
 
 		targetNet, err := LoadByName(n.state, peer.TargetProject, peer.TargetNetwork)
@@ -4416,20 +5073,130 @@ func (n *ovn) PeerCreate(peer api.NetworkPeersPost) error {
 			return fmt.Errorf("Target network is not ovn interface type")
 		}
 
-		opts, err := n.peerGetLocalOpts(localNICRoutes)
+		client, err := openvswitch.NewOVN(n.state)
+		if err != nil {
+			return fmt.Errorf("Failed to get OVN client: %w", err)
+		}
+
+		err = n.peerApply(client, targetOVNNet)
 		if err != nil {
 			return err
 		}
+	}
+
+	revert.Success()
+	return nil
+}
+
+// peerApply applies the network peering configuration between the local network and the target network.
+func (n *ovn) peerApply(client *openvswitch.OVN, targetOVNNet *ovn) error {
+	// Apply router security policies.
+	// Should have been done during network setup, but ensure its done here anyway.
+	err := n.logicalRouterPolicySetup(client)
+	if err != nil {
+		return fmt.Errorf("Failed applying local router security policy: %w", err)
+	}
+
+	activeLocalNICPorts, err := client.LogicalSwitchPorts(n.getIntSwitchName())
+	if err != nil {
+		return fmt.Errorf("Failed getting active NIC ports: %w", err)
+	}
+
+	var localNICRoutes []net.IPNet
+
+	// Get routes on instance NICs connected to local network to be added as routes to target network.
+	err = usedByInstanceDevices(n.state, n.Project(), n.Name(), func(inst db.Instance, nicName string, nicConfig map[string]string) error {
+		instancePortName := n.getInstanceDevicePortName(inst.Config["volatile.uuid"], nicName)
+		if _, found := activeLocalNICPorts[instancePortName]; !found {
+			return nil // Don't add config for instance NICs that aren't started.
+		}
+
+		localNICRoutes = append(localNICRoutes, n.instanceNICGetRoutes(nicConfig)...)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed getting instance NIC routes on local network: %w", err)
+	}
+
+	opts, err := n.peerGetLocalOpts(localNICRoutes)
+	if err != nil {
+		return err
+	}
+
+	// Ensure local subnets and all active NIC routes are present in internal switch's address set.
+	err = client.AddressSetAdd(acl.OVNIntSwitchPortGroupAddressSetPrefix(n.ID()), opts.TargetRouterRoutes...)
+	if err != nil {
+		return fmt.Errorf("Failed adding active NIC routes to switch address set: %w", err)
+	}
+
+	err = n.peerSetup(client, targetOVNNet, *opts)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// peerUplinkCreate creates a peering with the network's uplink. The uplink's routed subnets are kept in an address
+// set shared by all of the uplink's peers so that they can be referenced in ACL rules.
+func (n *ovn) peerUplinkCreate(peer api.NetworkPeersPost) error {
+	revert := revert.New()
+	defer revert.Fail()
+
+	uplinkNet, err := LoadByName(n.state, project.Default, n.config["network"])
+	if err != nil {
+		return fmt.Errorf("Failed loading uplink network: %w", err)
+	}
+
+	if uplinkNet.Type() != "physical" {
+		return api.StatusErrorf(http.StatusBadRequest, "Uplink peers require a physical uplink network")
+	}
+
+	uplinkRoutes, err := n.peerUplinkRoutes(uplinkNet.Config())
+	if err != nil {
+		return err
+	}
+
+	uplinkPeers, err := n.state.Cluster.GetNetworkPeersUplinkCount(uplinkNet.ID())
+	if err != nil {
+		return err
+	}
+
+	peerID, err := n.state.Cluster.CreateNetworkPeerUplink(n.ID(), uplinkNet.ID(), &peer)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		n.state.Cluster.DeleteNetworkPeer(n.ID(), peerID)
+	})
+
+	client, err := openvswitch.NewOVN(n.state)
+	if err != nil {
+		return fmt.Errorf("Failed to get OVN client: %w", err)
+	}
+
+	addrSetPrefix := acl.OVNIntSwitchPortGroupAddressSetPrefix(uplinkNet.ID())
+
+	// Create the uplink routes address set if this is the first peer of the uplink.
+	if uplinkPeers <= 0 {
+		// Remove any address set left behind by a previous peering.
+		err = client.AddressSetDelete(addrSetPrefix)
+		if err != nil {
+			return fmt.Errorf("Failed deleting uplink address set: %w", err)
+		}
 
-		// Ensure local subnets and all active NIC routes are present in internal switch's address set.
-		err = client.AddressSetAdd(acl.OVNIntSwitchPortGroupAddressSetPrefix(n.ID()), opts.TargetRouterRoutes...)
+		err = client.AddressSetCreate(addrSetPrefix, uplinkRoutes...)
 		if err != nil {
-			return fmt.Errorf("Failed adding active NIC routes to switch address set: %w", err)
+			return fmt.Errorf("Failed creating uplink address set: %w", err)
 		}
 
-		err = n.peerSetup(client, targetOVNNet, *opts)
+		revert.Add(func() { client.AddressSetDelete(addrSetPrefix) })
+	} else {
+		err = client.AddressSetReplace(addrSetPrefix, uplinkRoutes...)
 		if err != nil {
-			return err
+			return fmt.Errorf("Failed updating uplink address set: %w", err)
 		}
 	}
 
@@ -4437,6 +5204,96 @@ func (n *ovn) PeerCreate(peer api.NetworkPeersPost) error {
 	return nil
 }
 
+// peerUplinkRefresh updates the uplink routes address set if the network has an uplink peer.
+func (n *ovn) peerUplinkRefresh(uplinkName string, uplinkConfig map[string]string) error {
+	peers, err := n.state.Cluster.GetNetworkPeers(n.ID())
+	if err != nil {
+		return fmt.Errorf("Failed loading network peers: %w", err)
+	}
+
+	for _, peer := range peers {
+		if peer.Type != "uplink" {
+			continue
+		}
+
+		n.logger.Debug("Applying routes changes from uplink network to uplink peer", logger.Ctx{"uplink": uplinkName, "peer": peer.Name})
+
+		uplinkRoutes, err := n.peerUplinkRoutes(uplinkConfig)
+		if err != nil {
+			return err
+		}
+
+		uplinkNetID, _, _, err := n.state.Cluster.GetNetworkInAnyState(project.Default, uplinkName)
+		if err != nil {
+			return fmt.Errorf("Failed loading uplink network: %w", err)
+		}
+
+		client, err := openvswitch.NewOVN(n.state)
+		if err != nil {
+			return fmt.Errorf("Failed to get OVN client: %w", err)
+		}
+
+		err = client.AddressSetReplace(acl.OVNIntSwitchPortGroupAddressSetPrefix(uplinkNetID), uplinkRoutes...)
+		if err != nil {
+			return fmt.Errorf("Failed updating uplink address set: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// peerUplinkRoutes returns the routed subnets of the uplink network.
+func (n *ovn) peerUplinkRoutes(uplinkConfig map[string]string) ([]net.IPNet, error) {
+	var routes []net.IPNet
+
+	for _, key := range []string{"ipv4.routes", "ipv6.routes"} {
+		for _, route := range shared.SplitNTrimSpace(uplinkConfig[key], ",", -1, true) {
+			_, subnet, err := net.ParseCIDR(route)
+			if err != nil {
+				return nil, fmt.Errorf("Failed parsing uplink %q: %w", key, err)
+			}
+
+			routes = append(routes, *subnet)
+		}
+	}
+
+	return routes, nil
+}
+
+// peerLeakedRoutes returns the routes that the local network's peering with the target network is allowed to leak
+// to the target network. If the peering has security ACLs set, only the parts of the routes covered by the
+// destinations of their ingress allow rules are leaked, otherwise all routes are leaked.
+func (n *ovn) peerLeakedRoutes(targetOVNNet *ovn, routes []net.IPNet) ([]net.IPNet, error) {
+	peers, err := n.state.Cluster.GetNetworkPeers(n.ID())
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading network peers: %w", err)
+	}
+
+	for _, peer := range peers {
+		if peer.Type != "network" || peer.TargetProject != targetOVNNet.Project() || peer.TargetNetwork != targetOVNNet.Name() {
+			continue
+		}
+
+		aclNames := shared.SplitNTrimSpace(peer.Config["security.acls"], ",", -1, true)
+		if len(aclNames) <= 0 {
+			break
+		}
+
+		allowed, allowAll, err := acl.IngressAllowedSubnets(n.state, n.Project(), aclNames)
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting allowed subnets for peer %q: %w", peer.Name, err)
+		}
+
+		if allowAll {
+			break
+		}
+
+		return subnetsIntersect(routes, allowed), nil
+	}
+
+	return routes, nil
+}
+
 // peerGetLocalOpts returns peering options prefilled with local router and local NIC routes config.
 // It can then be modified with the target peering network options.
 func (n *ovn) peerGetLocalOpts(localNICRoutes []net.IPNet) (*openvswitch.OVNRouterPeering, error) {
@@ -4560,6 +5417,18 @@ func (n *ovn) peerSetup(client *openvswitch.OVN, targetOVNNet *ovn, opts openvsw
 		return fmt.Errorf("Failed applying target router security policy: %w", err)
 	}
 
+	// Only leak the routes allowed by each side of the peering to the other side.
+	// This is done after populating the address sets as they must contain all of the routes of each network.
+	opts.TargetRouterRoutes, err = n.peerLeakedRoutes(targetOVNNet, opts.TargetRouterRoutes)
+	if err != nil {
+		return err
+	}
+
+	opts.LocalRouterRoutes, err = targetOVNNet.peerLeakedRoutes(n, opts.LocalRouterRoutes)
+	if err != nil {
+		return err
+	}
+
 	err = client.LogicalRouterPeeringApply(opts)
 	if err != nil {
 		return fmt.Errorf("Failed applying OVN network peering: %w", err)
@@ -4583,6 +5452,10 @@ func (n *ovn) PeerUpdate(peerName string, req api.NetworkPeerPut) error {
 		return err
 	}
 
+	if curPeer.Type == "uplink" && req.Config["security.acls"] != "" {
+		return api.StatusErrorf(http.StatusBadRequest, "Uplink peers don't support %q", "security.acls")
+	}
+
 	curPeerEtagHash, err := util.EtagHash(curPeer.Etag())
 	if err != nil {
 		return err
@@ -4607,10 +5480,42 @@ func (n *ovn) PeerUpdate(peerName string, req api.NetworkPeerPut) error {
 		return err
 	}
 
+	revert.Add(func() {
+		n.state.Cluster.UpdateNetworkPeer(n.ID(), curPeerID, &curPeer.NetworkPeerPut)
+	})
+
+	// Re-apply the peering if the ACLs controlling which routes are leaked have changed.
+	if curPeer.Status == api.NetworkStatusCreated && curPeer.Config["security.acls"] != req.Config["security.acls"] {
+		err = n.peerRefresh(curPeer)
+		if err != nil {
+			return err
+		}
+	}
+
 	revert.Success()
 	return nil
 }
 
+// peerRefresh re-applies an existing network peering.
+func (n *ovn) peerRefresh(peer *api.NetworkPeer) error {
+	targetNet, err := LoadByName(n.state, peer.TargetProject, peer.TargetNetwork)
+	if err != nil {
+		return fmt.Errorf("Failed loading target network: %w", err)
+	}
+
+	targetOVNNet, ok := targetNet.(*ovn)
+	if !ok {
+		return fmt.Errorf("Target network is not ovn interface type")
+	}
+
+	client, err := openvswitch.NewOVN(n.state)
+	if err != nil {
+		return fmt.Errorf("Failed to get OVN client: %w", err)
+	}
+
+	return n.peerApply(client, targetOVNNet)
+}
+
 // PeerDelete deletes a network peering.
 func (n *ovn) PeerDelete(peerName string) error {
 	peerID, peer, err := n.state.Cluster.GetNetworkPeer(n.ID(), peerName)
@@ -4627,7 +5532,12 @@ func (n *ovn) PeerDelete(peerName string) error {
 		return fmt.Errorf("Cannot delete a Peer that is in use")
 	}
 
-	if peer.Status == api.NetworkStatusCreated {
+	if peer.Type == "uplink" {
+		err = n.peerUplinkDelete()
+		if err != nil {
+			return err
+		}
+	} else if peer.Status == api.NetworkStatusCreated {
 		targetNet, err := LoadByName(n.state, peer.TargetProject, peer.TargetNetwork)
 		if err != nil {
 			return fmt.Errorf("Failed loading target network: %w", err)
@@ -4674,7 +5584,37 @@ func (n *ovn) PeerDelete(peerName string) error {
 	return nil
 }
 
+// peerUplinkDelete deletes the uplink routes address set if the network's uplink peer is the last one using it.
+func (n *ovn) peerUplinkDelete() error {
+	uplinkNetID, _, _, err := n.state.Cluster.GetNetworkInAnyState(project.Default, n.config["network"])
+	if err != nil {
+		return fmt.Errorf("Failed loading uplink network: %w", err)
+	}
+
+	uplinkPeers, err := n.state.Cluster.GetNetworkPeersUplinkCount(uplinkNetID)
+	if err != nil {
+		return err
+	}
+
+	if uplinkPeers > 1 {
+		return nil // Address set still used by other peers.
+	}
+
+	client, err := openvswitch.NewOVN(n.state)
+	if err != nil {
+		return fmt.Errorf("Failed to get OVN client: %w", err)
+	}
+
+	err = client.AddressSetDelete(acl.OVNIntSwitchPortGroupAddressSetPrefix(uplinkNetID))
+	if err != nil {
+		return fmt.Errorf("Failed deleting uplink address set: %w", err)
+	}
+
+	return nil
+}
+
 // forPeers runs f for each target peer network that this network is connected to.
+// Uplink peers are skipped as they are not connected to another OVN network.
 func (n *ovn) forPeers(f func(targetOVNNet *ovn) error) error {
 	peers, err := n.state.Cluster.GetNetworkPeers(n.ID())
 	if err != nil {
@@ -4682,7 +5622,7 @@ func (n *ovn) forPeers(f func(targetOVNNet *ovn) error) error {
 	}
 
 	for _, peer := range peers {
-		if peer.Status != api.NetworkStatusCreated {
+		if peer.Status != api.NetworkStatusCreated || peer.Type == "uplink" {
 			continue
 		}
 
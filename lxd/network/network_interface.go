@@ -63,6 +63,16 @@ type Network interface {
 	ForwardUpdate(listenAddress string, newForward api.NetworkForwardPut, clientType request.ClientType) error
 	ForwardDelete(listenAddress string, clientType request.ClientType) error
 
+	// Load Balancers.
+	LoadBalancerCreate(loadBalancer api.NetworkLoadBalancersPost, clientType request.ClientType) error
+	LoadBalancerUpdate(listenAddress string, newLoadBalancer api.NetworkLoadBalancerPut, clientType request.ClientType) error
+	LoadBalancerDelete(listenAddress string, clientType request.ClientType) error
+
+	// Address reservations.
+	ReservationCreate(projectName string, reservation api.NetworkReservationsPost, clientType request.ClientType) error
+	ReservationUpdate(address string, newReservation api.NetworkReservationPut, clientType request.ClientType) error
+	ReservationDelete(address string, clientType request.ClientType) error
+
 	// Peerings.
 	PeerCreate(forward api.NetworkPeersPost) error
 	PeerUpdate(peerName string, newPeer api.NetworkPeerPut) error
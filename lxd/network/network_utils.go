@@ -23,6 +23,7 @@ import (
 	"github.com/lxc/lxd/lxd/instance"
 	"github.com/lxc/lxd/lxd/instance/instancetype"
 	"github.com/lxc/lxd/lxd/ip"
+	"github.com/lxc/lxd/lxd/network/acl"
 	"github.com/lxc/lxd/lxd/project"
 	"github.com/lxc/lxd/lxd/state"
 	"github.com/lxc/lxd/lxd/util"
@@ -198,6 +199,43 @@ func UsedBy(s *state.State, networkProjectName string, networkID int64, networkN
 	return usedBy, nil
 }
 
+// UpdatePeersUsingACL re-applies the network peerings that use the specified ACL to control the routes they leak.
+// This should be called after the ACL rules have been changed.
+func UpdatePeersUsingACL(s *state.State, aclProjectName string, aclName string) error {
+	return acl.UsedBy(s, aclProjectName, func(_ []string, usageType any, _ string, _ map[string]string) error {
+		peerUsage, ok := usageType.(db.NetworkPeer)
+		if !ok {
+			return nil
+		}
+
+		n, err := LoadByName(s, aclProjectName, peerUsage.NetworkName)
+		if err != nil {
+			return fmt.Errorf("Failed loading network %q: %w", peerUsage.NetworkName, err)
+		}
+
+		ovnNet, ok := n.(*ovn)
+		if !ok {
+			return nil
+		}
+
+		_, peer, err := s.Cluster.GetNetworkPeer(n.ID(), peerUsage.PeerName)
+		if err != nil {
+			return fmt.Errorf("Failed loading network peer %q: %w", peerUsage.PeerName, err)
+		}
+
+		if peer.Status != api.NetworkStatusCreated {
+			return nil
+		}
+
+		err = ovnNet.peerRefresh(peer)
+		if err != nil {
+			return fmt.Errorf("Failed updating network peer %q of network %q: %w", peer.Name, n.Name(), err)
+		}
+
+		return nil
+	}, aclName)
+}
+
 // usedByProfileDevices indicates if network is referenced by a profile's NIC devices.
 // Checks if the device's parent or network properties match the network name.
 func usedByProfileDevices(s *state.State, profile db.Profile, networkProjectName string, networkName string) (bool, error) {
@@ -340,6 +378,21 @@ func UpdateDNSMasqStatic(s *state.State, networkName string) error {
 		networks = []string{networkName}
 	}
 
+	// Get the address reservations of the networks.
+	reservations := make(map[string]map[int64]*api.NetworkReservation, len(networks))
+	for _, network := range networks {
+		// Pass project.Default here, as currently dnsmasq (bridged) networks do not support projects.
+		networkID, _, _, err := s.Cluster.GetNetworkInAnyState(project.Default, network)
+		if err != nil {
+			return fmt.Errorf("Failed loading network %q: %w", network, err)
+		}
+
+		reservations[network], err = s.Cluster.GetNetworkReservations(networkID)
+		if err != nil {
+			return fmt.Errorf("Failed loading address reservations of network %q: %w", network, err)
+		}
+	}
+
 	// Get all the instances.
 	insts, err := instance.LoadNodeAll(s, instancetype.Any)
 	if err != nil {
@@ -377,6 +430,16 @@ func UpdateDNSMasqStatic(s *state.State, networkName string) error {
 				continue
 			}
 
+			// Use the addresses reserved for the instance when no static address is set.
+			reservedIPv4, reservedIPv6 := ReservedAddresses(reservations[d["parent"]], inst.Project(), inst.Name())
+			if d["ipv4.address"] == "" && reservedIPv4 != nil {
+				d["ipv4.address"] = reservedIPv4.String()
+			}
+
+			if d["ipv6.address"] == "" && reservedIPv6 != nil {
+				d["ipv6.address"] = reservedIPv6.String()
+			}
+
 			// Add the new host entries.
 			_, ok := entries[d["parent"]]
 			if !ok {
@@ -483,6 +546,26 @@ func UpdateDNSMasqStatic(s *state.State, networkName string) error {
 			}
 		}
 
+		// Pin the reserved addresses that aren't used by an instance so they aren't dynamically allocated.
+		for _, reservation := range reservations[network] {
+			used := false
+			for _, entry := range entries {
+				if entry[3] == reservation.Address || entry[4] == reservation.Address {
+					used = true
+					break
+				}
+			}
+
+			if used {
+				continue
+			}
+
+			err := dnsmasq.UpdateReservationEntry(network, net.ParseIP(reservation.Address))
+			if err != nil {
+				return err
+			}
+		}
+
 		// Signal dnsmasq.
 		err = dnsmasq.Kill(network, true)
 		if err != nil {
@@ -493,6 +576,31 @@ func UpdateDNSMasqStatic(s *state.State, networkName string) error {
 	return nil
 }
 
+// ReservedAddresses returns the IPv4 and IPv6 addresses reserved for an instance among the address reservations
+// of a network.
+func ReservedAddresses(reservations map[int64]*api.NetworkReservation, projectName string, instanceName string) (net.IP, net.IP) {
+	var reservedIPv4, reservedIPv6 net.IP
+
+	for _, reservation := range reservations {
+		if reservation.Instance == "" || reservation.Project != projectName || reservation.Instance != instanceName {
+			continue
+		}
+
+		ip := net.ParseIP(reservation.Address)
+		if ip == nil {
+			continue
+		}
+
+		if ip.To4() != nil {
+			reservedIPv4 = ip
+		} else {
+			reservedIPv6 = ip
+		}
+	}
+
+	return reservedIPv4, reservedIPv6
+}
+
 // ForkdnsServersList reads the server list file and returns the list as a slice.
 func ForkdnsServersList(networkName string) ([]string, error) {
 	servers := []string{}
@@ -1150,6 +1258,36 @@ func SubnetParseAppend(subnets []*net.IPNet, parseSubnet ...string) ([]*net.IPNe
 	return subnets, nil
 }
 
+// subnetsIntersect returns the parts of the subnets that are also covered by the allowed subnets.
+// Subnets that are inside an allowed subnet are returned as is, and allowed subnets that are inside a subnet are
+// returned instead of the wider subnet. Duplicate entries are only returned once.
+func subnetsIntersect(subnets []net.IPNet, allowed []net.IPNet) []net.IPNet {
+	seen := make(map[string]struct{})
+	intersect := make([]net.IPNet, 0, len(subnets))
+
+	add := func(subnet net.IPNet) {
+		_, found := seen[subnet.String()]
+		if found {
+			return
+		}
+
+		seen[subnet.String()] = struct{}{}
+		intersect = append(intersect, subnet)
+	}
+
+	for i := range subnets {
+		for j := range allowed {
+			if SubnetContains(&allowed[j], &subnets[i]) {
+				add(subnets[i])
+			} else if SubnetContains(&subnets[i], &allowed[j]) {
+				add(allowed[j])
+			}
+		}
+	}
+
+	return intersect
+}
+
 // InterfaceBindWait waits for network interface to appear after being bound to a driver.
 func InterfaceBindWait(ifName string) error {
 	for i := 0; i < 10; i++ {
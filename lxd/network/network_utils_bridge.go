@@ -92,3 +92,29 @@ func DetachInterface(bridgeName string, devName string) error {
 
 	return nil
 }
+
+// VxlanFDBAdd adds a forwarding database entry to a VXLAN interface sending the traffic for the MAC address to
+// the remote VTEP. Using the all zeros MAC address adds the remote VTEP to the flood list of the interface.
+func VxlanFDBAdd(devName string, hwaddr string, vtep string) error {
+	action := "replace"
+	if hwaddr == "00:00:00:00:00:00" {
+		action = "append"
+	}
+
+	_, err := shared.RunCommand("bridge", "fdb", action, hwaddr, "dev", devName, "dst", vtep)
+	if err != nil {
+		return fmt.Errorf("Failed adding forwarding database entry for %q on %q: %w", hwaddr, devName, err)
+	}
+
+	return nil
+}
+
+// VxlanFDBDelete removes the forwarding database entry of a VXLAN interface for the MAC address and remote VTEP.
+func VxlanFDBDelete(devName string, hwaddr string, vtep string) error {
+	_, err := shared.RunCommand("bridge", "fdb", "del", hwaddr, "dev", devName, "dst", vtep)
+	if err != nil {
+		return fmt.Errorf("Failed removing forwarding database entry for %q on %q: %w", hwaddr, devName, err)
+	}
+
+	return nil
+}
@@ -137,3 +137,32 @@ func Example_ipRangesOverlap() {
 	// Range1: 10.1.1.8-10.1.1.9, Range2: 10.1.1.4, overlapped: false
 
 }
+
+func Example_subnetsIntersect() {
+	parse := func(cidrs ...string) []net.IPNet {
+		subnets := make([]net.IPNet, 0, len(cidrs))
+		for _, cidr := range cidrs {
+			_, subnet, _ := net.ParseCIDR(cidr)
+			subnets = append(subnets, *subnet)
+		}
+
+		return subnets
+	}
+
+	subnets := parse("10.0.0.0/24", "10.1.0.0/24", "192.0.2.0/24", "fd42::/64")
+	allowed := parse("10.0.0.10/32", "10.0.0.20/32", "10.0.0.0/8", "fd42::/48")
+
+	for _, subnet := range subnetsIntersect(subnets, allowed) {
+		fmt.Println(subnet.String())
+	}
+
+	fmt.Println(len(subnetsIntersect(subnets, nil)))
+
+	// Output:
+	// 10.0.0.10/32
+	// 10.0.0.20/32
+	// 10.0.0.0/24
+	// 10.1.0.0/24
+	// fd42::/64
+	// 0
+}
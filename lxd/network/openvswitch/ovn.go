@@ -129,13 +129,27 @@ type OVNACLRule struct {
 	LogName   string // Log label name (requires Log be true).
 }
 
+// OVNQoSRule represents a QoS rule that can be added to a logical switch port.
+type OVNQoSRule struct {
+	Direction string // Either "from-lport" or "to-lport".
+	Priority  int    // Priority (between 0 and 32767, inclusive). Higher values take precedence.
+	Rate      int64  // Rate limit in kbps, 0 for unlimited.
+	Burst     int64  // Burst size in kilobits, 0 for the default.
+	DSCP      int    // DSCP marking, -1 to leave the traffic unmarked.
+}
+
+// OVNLoadBalancerTarget represents an OVN load balancer Virtual IP target.
+type OVNLoadBalancerTarget struct {
+	Address net.IP
+	Port    uint64
+}
+
 // OVNLoadBalancerVIP represents a OVN load balancer Virtual IP entry.
 type OVNLoadBalancerVIP struct {
 	Protocol      string // Either "tcp" or "udp". But only applies to port based VIPs.
 	ListenAddress net.IP
 	ListenPort    uint64
-	TargetAddress net.IP
-	TargetPort    uint64
+	Targets       []OVNLoadBalancerTarget
 }
 
 // OVNRouterRoute represents a static route added to a logical router.
@@ -1642,6 +1656,83 @@ func (o *OVN) PortGroupPortClearACLRules(portGroupName OVNPortGroup, portName OV
 	return nil
 }
 
+// logicalSwitchPortQoSRules returns the QoS rule UUIDs belonging to a logical switch port.
+func (o *OVN) logicalSwitchPortQoSRules(portName OVNSwitchPort) ([]string, error) {
+	output, err := o.nbctl("--format=csv", "--no-headings", "--data=bare", "--colum=_uuid", "find", "qos",
+		fmt.Sprintf("external_ids:%s=%s", ovnExtIDLXDSwitchPort, string(portName)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleUUIDs := shared.SplitNTrimSpace(strings.TrimSpace(output), "\n", -1, true)
+
+	return ruleUUIDs, nil
+}
+
+// LogicalSwitchPortSetQoSRules applies a set of QoS rules to the logical switch port in the specified switch.
+// Any existing QoS rules for that logical switch port are removed, so providing no rules clears them.
+func (o *OVN) LogicalSwitchPortSetQoSRules(switchName OVNSwitch, portName OVNSwitchPort, qosRules ...OVNQoSRule) error {
+	removeRuleUUIDs, err := o.logicalSwitchPortQoSRules(portName)
+	if err != nil {
+		return err
+	}
+
+	args := []string{}
+
+	// Remove any existing rules assigned to the port. QoS rules are garbage collected once unreferenced.
+	for _, ruleUUID := range removeRuleUUIDs {
+		if len(args) > 0 {
+			args = append(args, "--")
+		}
+
+		args = append(args, "remove", "logical_switch", string(switchName), "qos_rules", ruleUUID)
+	}
+
+	// Add new rules.
+	for i, rule := range qosRules {
+		if len(args) > 0 {
+			args = append(args, "--")
+		}
+
+		match := fmt.Sprintf(`inport == "%s"`, portName)
+		if rule.Direction == "to-lport" {
+			match = fmt.Sprintf(`outport == "%s"`, portName)
+		}
+
+		args = append(args, fmt.Sprintf("--id=@qos%d", i), "create", "qos",
+			fmt.Sprintf("direction=%s", rule.Direction),
+			fmt.Sprintf("priority=%d", rule.Priority),
+			fmt.Sprintf("match=%s", strconv.Quote(match)),
+			fmt.Sprintf("external_ids:%s=%s", ovnExtIDLXDSwitch, switchName),
+			fmt.Sprintf("external_ids:%s=%s", ovnExtIDLXDSwitchPort, portName),
+		)
+
+		if rule.Rate > 0 {
+			args = append(args, fmt.Sprintf("bandwidth:rate=%d", rule.Rate))
+
+			if rule.Burst > 0 {
+				args = append(args, fmt.Sprintf("bandwidth:burst=%d", rule.Burst))
+			}
+		}
+
+		if rule.DSCP >= 0 {
+			args = append(args, fmt.Sprintf("action:dscp=%d", rule.DSCP))
+		}
+
+		args = append(args, "--", "add", "logical_switch", string(switchName), "qos_rules", fmt.Sprintf("@qos%d", i))
+	}
+
+	if len(args) > 0 {
+		_, err = o.nbctl(args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // LoadBalancerApply creates a new load balancer (if doesn't exist) on the specified routers and switches.
 // Providing an empty set of vips will delete the load balancer.
 func (o *OVN) LoadBalancerApply(loadBalancerName OVNLoadBalancer, routers []OVNRouter, switches []OVNSwitch, vips ...OVNLoadBalancerVIP) error {
@@ -1669,12 +1760,25 @@ func (o *OVN) LoadBalancerApply(loadBalancerName OVNLoadBalancer, routers []OVNR
 			return fmt.Errorf("Missing VIP listen address")
 		}
 
-		if r.TargetAddress == nil {
-			return fmt.Errorf("Missing VIP target address")
+		if len(r.Targets) == 0 {
+			return fmt.Errorf("Missing VIP target")
 		}
 
-		if (r.ListenPort > 0 && r.TargetPort <= 0) || (r.TargetPort > 0 && r.ListenPort <= 0) {
-			return fmt.Errorf("The listen and target ports must be specified together")
+		targetArgs := make([]string, 0, len(r.Targets))
+		for _, target := range r.Targets {
+			if target.Address == nil {
+				return fmt.Errorf("Missing VIP target address")
+			}
+
+			if (r.ListenPort > 0 && target.Port <= 0) || (target.Port > 0 && r.ListenPort <= 0) {
+				return fmt.Errorf("The listen and target ports must be specified together")
+			}
+
+			if r.ListenPort > 0 {
+				targetArgs = append(targetArgs, fmt.Sprintf("%s:%d", ipToString(target.Address), target.Port))
+			} else {
+				targetArgs = append(targetArgs, ipToString(target.Address))
+			}
 		}
 
 		if r.Protocol == "udp" {
@@ -1688,13 +1792,13 @@ func (o *OVN) LoadBalancerApply(loadBalancerName OVNLoadBalancer, routers []OVNR
 		if r.ListenPort > 0 {
 			args = append(args,
 				fmt.Sprintf("%s:%d", ipToString(r.ListenAddress), r.ListenPort),
-				fmt.Sprintf("%s:%d", ipToString(r.TargetAddress), r.TargetPort),
+				strings.Join(targetArgs, ","),
 				r.Protocol,
 			)
 		} else {
 			args = append(args,
 				fmt.Sprintf("%s", ipToString(r.ListenAddress)),
-				fmt.Sprintf("%s", ipToString(r.TargetAddress)),
+				strings.Join(targetArgs, ","),
 			)
 		}
 	}
@@ -1831,6 +1935,31 @@ func (o *OVN) AddressSetAdd(addressSetPrefix OVNAddressSet, addresses ...net.IPN
 	return nil
 }
 
+// AddressSetReplace replaces the addresses in the existing address sets with the supplied addresses.
+// The address set name used is "<addressSetPrefix>_ip<IP version>", e.g. "foo_ip4".
+func (o *OVN) AddressSetReplace(addressSetPrefix OVNAddressSet, addresses ...net.IPNet) error {
+	args := []string{
+		"clear", "address_set", fmt.Sprintf("%s_ip%d", addressSetPrefix, 4), "addresses",
+		"--", "clear", "address_set", fmt.Sprintf("%s_ip%d", addressSetPrefix, 6), "addresses",
+	}
+
+	for _, address := range addresses {
+		var ipVersion uint = 4
+		if address.IP.To4() == nil {
+			ipVersion = 6
+		}
+
+		args = append(args, "--", "add", "address_set", fmt.Sprintf("%s_ip%d", addressSetPrefix, ipVersion), "addresses", fmt.Sprintf(`"%s"`, address.String()))
+	}
+
+	_, err := o.nbctl(args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // AddressSetRemove removes the supplied addresses from the address set.
 // The address set name used is "<addressSetPrefix>_ip<IP version>", e.g. "foo_ip4".
 func (o *OVN) AddressSetRemove(addressSetPrefix OVNAddressSet, addresses ...net.IPNet) error {
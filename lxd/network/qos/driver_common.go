@@ -0,0 +1,315 @@
+package qos
+
+import (
+	"fmt"
+
+	"github.com/lxc/lxd/client"
+	"github.com/lxc/lxd/lxd/cluster"
+	"github.com/lxc/lxd/lxd/cluster/request"
+	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/network/acl"
+	"github.com/lxc/lxd/lxd/network/openvswitch"
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/lxd/revert"
+	"github.com/lxc/lxd/lxd/state"
+	"github.com/lxc/lxd/lxd/util"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/logger"
+	"github.com/lxc/lxd/shared/version"
+)
+
+// common represents a network QoS policy.
+type common struct {
+	logger      logger.Logger
+	state       *state.State
+	id          int64
+	projectName string
+	info        *api.NetworkQoSPolicy
+}
+
+// init initialise internal variables.
+func (d *common) init(state *state.State, id int64, projectName string, info *api.NetworkQoSPolicy) {
+	if info == nil {
+		d.info = &api.NetworkQoSPolicy{}
+	} else {
+		d.info = info
+	}
+
+	d.logger = logger.AddContext(logger.Log, logger.Ctx{"project": projectName, "networkQoSPolicy": d.info.Name})
+	d.id = id
+	d.projectName = projectName
+	d.state = state
+
+	if d.info.Config == nil {
+		d.info.Config = make(map[string]string)
+	}
+}
+
+// ID returns the network QoS policy ID.
+func (d *common) ID() int64 {
+	return d.id
+}
+
+// Project returns the project name.
+func (d *common) Project() string {
+	return d.projectName
+}
+
+// Info returns copy of internal info for the network QoS policy.
+func (d *common) Info() *api.NetworkQoSPolicy {
+	// Copy internal info to prevent modification externally.
+	info := api.NetworkQoSPolicy{}
+	info.Name = d.info.Name
+	info.Description = d.info.Description
+	info.Config = util.CopyConfig(d.info.Config)
+	info.UsedBy = nil // To indicate its not populated (use UsedBy() function to populate).
+
+	return &info
+}
+
+// usedBy returns a list of API endpoints referencing this QoS policy.
+// If firstOnly is true then search stops at first result.
+func (d *common) usedBy(firstOnly bool) ([]string, error) {
+	usedBy := []string{}
+
+	// Find all networks, profiles and instance NICs that use this network QoS policy.
+	err := UsedBy(d.state, d.projectName, func(usageType any, _ string, _ map[string]string) error {
+		switch u := usageType.(type) {
+		case db.Instance:
+			uri := fmt.Sprintf("/%s/instances/%s", version.APIVersion, u.Name)
+			if u.Project != project.Default {
+				uri += fmt.Sprintf("?project=%s", u.Project)
+			}
+
+			usedBy = append(usedBy, uri)
+		case *api.Network:
+			uri := fmt.Sprintf("/%s/networks/%s", version.APIVersion, u.Name)
+			if d.projectName != project.Default {
+				uri += fmt.Sprintf("?project=%s", d.projectName)
+			}
+
+			usedBy = append(usedBy, uri)
+		case db.Profile:
+			uri := fmt.Sprintf("/%s/profiles/%s", version.APIVersion, u.Name)
+			if u.Project != project.Default {
+				uri += fmt.Sprintf("?project=%s", u.Project)
+			}
+
+			usedBy = append(usedBy, uri)
+		default:
+			return fmt.Errorf("Unrecognised usage type %T", u)
+		}
+
+		if firstOnly {
+			return db.ErrInstanceListStop
+		}
+
+		return nil
+	}, d.info.Name)
+	if err != nil {
+		if err == db.ErrInstanceListStop {
+			return usedBy, nil
+		}
+
+		return nil, fmt.Errorf("Failed getting QoS policy usage: %w", err)
+	}
+
+	return usedBy, nil
+}
+
+// UsedBy returns a list of API endpoints referencing this QoS policy.
+func (d *common) UsedBy() ([]string, error) {
+	return d.usedBy(false)
+}
+
+// isUsed returns whether or not the QoS policy is in use.
+func (d *common) isUsed() (bool, error) {
+	usedBy, err := d.usedBy(true)
+	if err != nil {
+		return false, err
+	}
+
+	return len(usedBy) > 0, nil
+}
+
+// Etag returns the values used for etag generation.
+func (d *common) Etag() []any {
+	return []any{d.info.Name, d.info.Description, d.info.Config}
+}
+
+// Limits returns the limits defined by the QoS policy.
+func (d *common) Limits() (*Limits, error) {
+	return ParseLimits(d.info.Config)
+}
+
+// Update applies the supplied config to the QoS policy and to the running instance NICs it applies to.
+func (d *common) Update(config *api.NetworkQoSPolicyPut, clientType request.ClientType) error {
+	err := ValidateConfig(config.Config)
+	if err != nil {
+		return err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	if clientType == request.ClientTypeNormal {
+		oldConfig := d.info.NetworkQoSPolicyPut
+
+		// Update database. Its important this occurs before we attempt to apply to the NICs using the policy
+		// as usage functions will inspect the database.
+		err = d.state.Cluster.UpdateNetworkQoSPolicy(d.id, config)
+		if err != nil {
+			return err
+		}
+
+		// Apply changes internally and reinitialise.
+		d.info.NetworkQoSPolicyPut = *config
+		d.init(d.state, d.id, d.projectName, d.info)
+
+		revert.Add(func() {
+			_ = d.state.Cluster.UpdateNetworkQoSPolicy(d.id, &oldConfig)
+			d.info.NetworkQoSPolicyPut = oldConfig
+			d.init(d.state, d.id, d.projectName, d.info)
+		})
+	}
+
+	limits, err := d.Limits()
+	if err != nil {
+		return err
+	}
+
+	// Get a list of instance NICs that the policy applies to, directly or through their network.
+	nics, err := NICUsage(d.state, d.projectName, d.info.Name)
+	if err != nil {
+		return fmt.Errorf("Failed getting QoS policy instance NIC usage: %w", err)
+	}
+
+	var localNode string
+	err = d.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		localNode, err = tx.GetLocalNodeName()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed getting local cluster member name: %w", err)
+	}
+
+	hostNICs := false
+	ovnNICs := map[int64][]NICQoSUsage{}
+	for _, nic := range nics {
+		// OVN NICs share their QoS rules across the cluster.
+		if nic.OVN() {
+			ovnNICs[nic.NetworkID] = append(ovnNICs[nic.NetworkID], nic)
+			continue
+		}
+
+		hostNICs = true
+
+		// Apply the limits to the running NICs on this member.
+		if nic.Node != localNode || nic.HostName == "" || !shared.PathExists(fmt.Sprintf("/sys/class/net/%s", nic.HostName)) {
+			continue
+		}
+
+		err = HostInterfaceSetup(nic.HostName, limits, nic.InstanceTX())
+		if err != nil {
+			return fmt.Errorf("Failed applying QoS policy to instance %q NIC %q: %w", nic.InstanceName, nic.Name, err)
+		}
+	}
+
+	// If there are affected OVN NICs, then apply the changes, but only if the request type is normal.
+	// This way we won't apply the same changes multiple times for each LXD cluster member.
+	if len(ovnNICs) > 0 && clientType == request.ClientTypeNormal {
+		client, err := openvswitch.NewOVN(d.state)
+		if err != nil {
+			return fmt.Errorf("Failed to get OVN client: %w", err)
+		}
+
+		for networkID, networkNICs := range ovnNICs {
+			switchName := acl.OVNIntSwitchName(networkID)
+
+			// Get list of active switch ports (avoids repeated querying of OVN NB).
+			activePorts, err := client.LogicalSwitchPorts(switchName)
+			if err != nil {
+				return fmt.Errorf("Failed getting active ports: %w", err)
+			}
+
+			for _, nic := range networkNICs {
+				portName := acl.OVNIntSwitchInstancePortName(networkID, nic.InstanceUUID, nic.Name)
+
+				_, found := activePorts[portName]
+				if !found {
+					continue // No need to update a port that isn't started yet.
+				}
+
+				err = client.LogicalSwitchPortSetQoSRules(switchName, portName, limits.OVNRules()...)
+				if err != nil {
+					return fmt.Errorf("Failed applying QoS policy to instance %q NIC %q: %w", nic.InstanceName, nic.Name, err)
+				}
+			}
+		}
+	}
+
+	// Apply the changes to the instance NICs on the other cluster members.
+	if clientType == request.ClientTypeNormal && hostNICs {
+		notifier, err := cluster.NewNotifier(d.state, d.state.Endpoints.NetworkCert(), d.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return err
+		}
+
+		err = notifier(func(client lxd.InstanceServer) error {
+			return client.UseProject(d.projectName).UpdateNetworkQoSPolicy(d.info.Name, d.info.NetworkQoSPolicyPut, "")
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	revert.Success()
+	return nil
+}
+
+// Rename renames the QoS policy if not in use.
+func (d *common) Rename(newName string) error {
+	_, err := LoadByName(d.state, d.projectName, newName)
+	if err == nil {
+		return fmt.Errorf("A network QoS policy by that name exists already")
+	}
+
+	isUsed, err := d.isUsed()
+	if err != nil {
+		return err
+	}
+
+	if isUsed {
+		return fmt.Errorf("Cannot rename a network QoS policy that is in use")
+	}
+
+	err = ValidName(newName)
+	if err != nil {
+		return err
+	}
+
+	err = d.state.Cluster.RenameNetworkQoSPolicy(d.id, newName)
+	if err != nil {
+		return err
+	}
+
+	// Apply changes internally.
+	d.info.Name = newName
+
+	return nil
+}
+
+// Delete deletes the QoS policy if not in use.
+func (d *common) Delete() error {
+	isUsed, err := d.isUsed()
+	if err != nil {
+		return err
+	}
+
+	if isUsed {
+		return fmt.Errorf("Cannot delete a network QoS policy that is in use")
+	}
+
+	return d.state.Cluster.DeleteNetworkQoSPolicy(d.id)
+}
@@ -0,0 +1,152 @@
+package qos
+
+import (
+	"fmt"
+
+	"github.com/lxc/lxd/lxd/ip"
+)
+
+// hostTraffic represents the handling of one direction of the traffic of a host side interface.
+type hostTraffic struct {
+	rate     int64
+	burst    int64
+	priority string
+	dscp     int
+}
+
+// actions returns the tc actions setting the priority of the traffic and policing it, in that order.
+func (t hostTraffic) actions() []ip.Action {
+	actions := []ip.Action{}
+
+	if t.priority != "" {
+		actions = append(actions, &ip.ActionSkbedit{Priority: t.priority})
+	}
+
+	if t.rate > 0 {
+		burst := "1024k"
+		if t.burst > 0 {
+			burst = fmt.Sprintf("%db", t.burst)
+		}
+
+		actions = append(actions, &ip.ActionPolice{Rate: fmt.Sprintf("%dbit", t.rate), Burst: burst, Mtu: "64kb", Drop: true})
+	}
+
+	return actions
+}
+
+// addFilters adds the filters marking the traffic and running the actions to the parent qdisc.
+// IP traffic is matched first so that its DSCP can be set, the remaining traffic falls through to a catch-all filter.
+func (t hostTraffic) addFilters(devName string, parent string, actions []ip.Action) error {
+	filters := []*ip.U32Filter{}
+
+	if t.dscp >= 0 {
+		dsfield := fmt.Sprintf("0x%02x", t.dscp<<2)
+
+		filters = append(filters,
+			&ip.U32Filter{
+				Filter:  ip.Filter{Dev: devName, Parent: parent, Protocol: "ip", Prio: "1"},
+				Value:   "0",
+				Mask:    "0",
+				Actions: append([]ip.Action{&ip.ActionPedit{Munge: []string{"ip", "dsfield", "set", dsfield, "retain", "0xfc"}}, &ip.ActionCsum{Update: "ip"}}, actions...),
+			},
+			&ip.U32Filter{
+				Filter:  ip.Filter{Dev: devName, Parent: parent, Protocol: "ipv6", Prio: "2"},
+				Value:   "0",
+				Mask:    "0",
+				Actions: append([]ip.Action{&ip.ActionPedit{Munge: []string{"ip6", "traffic_class", "set", dsfield, "retain", "0xfc"}}}, actions...),
+			},
+		)
+	}
+
+	if len(actions) > 0 {
+		filters = append(filters, &ip.U32Filter{
+			Filter:  ip.Filter{Dev: devName, Parent: parent, Protocol: "all", Prio: "3"},
+			Value:   "0",
+			Mask:    "0",
+			Actions: actions,
+		})
+	}
+
+	for _, filter := range filters {
+		err := filter.Add()
+		if err != nil {
+			return fmt.Errorf("Failed to create tc filter on %q: %w", parent, err)
+		}
+	}
+
+	return nil
+}
+
+// HostInterfaceSetup applies the limits to the host side interface of an instance NIC, replacing any existing ones.
+// The interface receives the traffic sent by the instance (veth and tap devices), unless instanceTX is true, in
+// which case it transmits it (macvtap devices).
+func HostInterfaceSetup(devName string, limits *Limits, instanceTX bool) error {
+	// Clean any existing entry.
+	qdisc := &ip.Qdisc{Dev: devName, Root: true}
+	_ = qdisc.Delete()
+	qdisc = &ip.Qdisc{Dev: devName, Ingress: true}
+	_ = qdisc.Delete()
+
+	toInstance := hostTraffic{rate: limits.IngressRate, burst: limits.IngressBurst, dscp: -1}
+	fromInstance := hostTraffic{rate: limits.EgressRate, burst: limits.EgressBurst, priority: limits.Priority, dscp: limits.DSCP}
+
+	transmitted, received := toInstance, fromInstance
+	if instanceTX {
+		transmitted, received = fromInstance, toInstance
+	}
+
+	// Shape the transmitted traffic.
+	if transmitted.rate > 0 {
+		qdiscHTB := &ip.QdiscHTB{Qdisc: ip.Qdisc{Dev: devName, Handle: "1:0", Root: true}, Default: "10"}
+		err := qdiscHTB.Add()
+		if err != nil {
+			return fmt.Errorf("Failed to create root tc qdisc: %w", err)
+		}
+
+		classHTB := &ip.ClassHTB{Class: ip.Class{Dev: devName, Parent: "1:0", Classid: "1:10"}, Rate: fmt.Sprintf("%dbit", transmitted.rate)}
+		if transmitted.burst > 0 {
+			classHTB.Burst = fmt.Sprintf("%db", transmitted.burst)
+		}
+
+		err = classHTB.Add()
+		if err != nil {
+			return fmt.Errorf("Failed to create limit tc class: %w", err)
+		}
+
+		filter := &ip.U32Filter{Filter: ip.Filter{Dev: devName, Parent: "1:0", Protocol: "all", Flowid: "1:10"}, Value: "0", Mask: "0"}
+		err = filter.Add()
+		if err != nil {
+			return fmt.Errorf("Failed to create tc filter: %w", err)
+		}
+	}
+
+	// The received traffic can only be policed. Marking of the transmitted traffic happens before it is queued.
+	receivedActions := received.actions()
+	transmittedMarked := transmitted.priority != "" || transmitted.dscp >= 0
+	if len(receivedActions) > 0 || received.dscp >= 0 || transmittedMarked {
+		qdisc := &ip.QdiscClsact{Qdisc: ip.Qdisc{Dev: devName}}
+		err := qdisc.Add()
+		if err != nil {
+			return fmt.Errorf("Failed to create clsact tc qdisc: %w", err)
+		}
+
+		err = received.addFilters(devName, "ffff:fff2", receivedActions)
+		if err != nil {
+			return err
+		}
+
+		if transmittedMarked {
+			markActions := []ip.Action{}
+			if transmitted.priority != "" {
+				markActions = append(markActions, &ip.ActionSkbedit{Priority: transmitted.priority})
+			}
+
+			err = transmitted.addFilters(devName, "ffff:fff3", markActions)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package qos
+
+import (
+	"github.com/lxc/lxd/lxd/cluster/request"
+	"github.com/lxc/lxd/lxd/state"
+	"github.com/lxc/lxd/shared/api"
+)
+
+// NetworkQoSPolicy represents a network QoS policy.
+type NetworkQoSPolicy interface {
+	// Initialise.
+	init(state *state.State, id int64, projectName string, policyInfo *api.NetworkQoSPolicy)
+
+	// Info.
+	ID() int64
+	Project() string
+	Info() *api.NetworkQoSPolicy
+	Etag() []any
+	UsedBy() ([]string, error)
+	Limits() (*Limits, error)
+
+	// Modifications.
+	Update(config *api.NetworkQoSPolicyPut, clientType request.ClientType) error
+	Rename(newName string) error
+	Delete() error
+}
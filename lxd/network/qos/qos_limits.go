@@ -0,0 +1,114 @@
+package qos
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/lxc/lxd/shared/units"
+)
+
+// priorityClass describes how a priority class is applied to the traffic sent by an instance.
+type priorityClass struct {
+	skbPriority string // Packet priority used by the host queueing disciplines.
+	dscp        int    // DSCP used when the policy doesn't set one, -1 to leave the traffic unmarked.
+}
+
+// priorityClasses maps the priority classes to the packet priorities of the Linux TC_PRIO_* bands.
+var priorityClasses = map[string]priorityClass{
+	"low":    {skbPriority: "0:2", dscp: 8},  // TC_PRIO_BULK, CS1.
+	"normal": {skbPriority: "", dscp: -1},    // Left as is.
+	"high":   {skbPriority: "0:6", dscp: 34}, // TC_PRIO_INTERACTIVE, AF41.
+}
+
+// Limits represents the traffic shaping and marking of an instance NIC.
+// Ingress is the traffic received by the instance and egress the traffic sent by it.
+type Limits struct {
+	IngressRate  int64  // Bit rate, 0 for unlimited.
+	IngressBurst int64  // Bytes, 0 for the default.
+	EgressRate   int64  // Bit rate, 0 for unlimited.
+	EgressBurst  int64  // Bytes, 0 for the default.
+	Priority     string // Packet priority of the egress traffic, empty to leave it as is.
+	DSCP         int    // DSCP marking of the egress traffic, -1 to leave it as is.
+}
+
+// parseRate parses an optional bit rate.
+func parseRate(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	return units.ParseBitSizeString(value)
+}
+
+// parseBurst parses an optional burst size.
+func parseBurst(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	return units.ParseByteSizeString(value)
+}
+
+// ParseLimits returns the limits defined by a QoS policy config.
+func ParseLimits(config map[string]string) (*Limits, error) {
+	var err error
+
+	limits := &Limits{DSCP: -1}
+
+	limits.IngressRate, err = parseRate(config["limits.ingress"])
+	if err != nil {
+		return nil, fmt.Errorf("Invalid %q: %w", "limits.ingress", err)
+	}
+
+	limits.IngressBurst, err = parseBurst(config["limits.ingress.burst"])
+	if err != nil {
+		return nil, fmt.Errorf("Invalid %q: %w", "limits.ingress.burst", err)
+	}
+
+	limits.EgressRate, err = parseRate(config["limits.egress"])
+	if err != nil {
+		return nil, fmt.Errorf("Invalid %q: %w", "limits.egress", err)
+	}
+
+	limits.EgressBurst, err = parseBurst(config["limits.egress.burst"])
+	if err != nil {
+		return nil, fmt.Errorf("Invalid %q: %w", "limits.egress.burst", err)
+	}
+
+	if config["priority"] != "" {
+		class, found := priorityClasses[config["priority"]]
+		if !found {
+			return nil, fmt.Errorf("Invalid priority %q", config["priority"])
+		}
+
+		limits.Priority = class.skbPriority
+		limits.DSCP = class.dscp
+	}
+
+	// An explicit DSCP overrides the one of the priority class.
+	if config["dscp"] != "" {
+		limits.DSCP, err = strconv.Atoi(config["dscp"])
+		if err != nil || limits.DSCP < 0 || limits.DSCP > 63 {
+			return nil, fmt.Errorf("Invalid %q: %q", "dscp", config["dscp"])
+		}
+	}
+
+	return limits, nil
+}
+
+// NICLimits returns the limits defined by the "limits.*" settings of a NIC.
+func NICLimits(nicConfig map[string]string) (*Limits, error) {
+	ingress := nicConfig["limits.ingress"]
+	egress := nicConfig["limits.egress"]
+
+	// Apply max limit.
+	if nicConfig["limits.max"] != "" {
+		ingress = nicConfig["limits.max"]
+		egress = nicConfig["limits.max"]
+	}
+
+	return ParseLimits(map[string]string{
+		"limits.ingress": ingress,
+		"limits.egress":  egress,
+	})
+}
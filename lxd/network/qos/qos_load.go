@@ -0,0 +1,284 @@
+package qos
+
+import (
+	"fmt"
+
+	"github.com/lxc/lxd/lxd/db"
+	deviceConfig "github.com/lxc/lxd/lxd/device/config"
+	"github.com/lxc/lxd/lxd/instance/instancetype"
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/lxd/response"
+	"github.com/lxc/lxd/lxd/state"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+)
+
+// LoadByName loads and initialises a network QoS policy from the database by project and name.
+func LoadByName(s *state.State, projectName string, name string) (NetworkQoSPolicy, error) {
+	id, policyInfo, err := s.Cluster.GetNetworkQoSPolicy(projectName, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy NetworkQoSPolicy = &common{} // Only a single driver currently.
+	policy.init(s, id, projectName, policyInfo)
+
+	return policy, nil
+}
+
+// Create validates supplied record and creates new network QoS policy record in the database.
+func Create(s *state.State, projectName string, policyInfo *api.NetworkQoSPoliciesPost) error {
+	err := ValidName(policyInfo.Name)
+	if err != nil {
+		return err
+	}
+
+	err = ValidateConfig(policyInfo.Config)
+	if err != nil {
+		return err
+	}
+
+	// Insert DB record.
+	_, err = s.Cluster.CreateNetworkQoSPolicy(projectName, policyInfo)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Exists checks the QoS policy name provided exists in the project.
+func Exists(s *state.State, projectName string, name string) error {
+	names, err := s.Cluster.GetNetworkQoSPolicies(projectName)
+	if err != nil {
+		return err
+	}
+
+	if !shared.StringInSlice(name, names) {
+		return fmt.Errorf("Network QoS policy %q does not exist", name)
+	}
+
+	return nil
+}
+
+// nicLimitKeys lists the NIC settings that define limits without a QoS policy.
+var nicLimitKeys = []string{"limits.ingress", "limits.egress", "limits.max"}
+
+// HasNICLimits returns whether the NIC has limits set directly in its config.
+func HasNICLimits(nicConfig map[string]string) bool {
+	for _, key := range nicLimitKeys {
+		if nicConfig[key] != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NICPolicyName returns the name of the QoS policy applied to a NIC, either set on the NIC itself or inherited
+// from the config of its network. NICs with limits set directly in their config don't inherit the network's policy.
+func NICPolicyName(nicConfig map[string]string, netConfig map[string]string) string {
+	if nicConfig["qos"] != "" {
+		return nicConfig["qos"]
+	}
+
+	if nicConfig["network"] == "" || HasNICLimits(nicConfig) {
+		return ""
+	}
+
+	return netConfig["qos"]
+}
+
+// isInUseByDevice returns whether the device references the QoS policy.
+func isInUseByDevice(d deviceConfig.Device, name string) bool {
+	return d["type"] == "nic" && d["qos"] == name
+}
+
+// UsedBy finds all networks, profiles and instance NICs that reference the specified QoS policy and executes
+// usageFunc once for each of them.
+func UsedBy(s *state.State, policyProjectName string, usageFunc func(usageType any, nicName string, nicConfig map[string]string) error, name string) error {
+	// Find networks using the policy. Cheapest to do.
+	networkNames, err := s.Cluster.GetCreatedNetworks(policyProjectName)
+	if err != nil && !response.IsNotFoundError(err) {
+		return fmt.Errorf("Failed loading networks for project %q: %w", policyProjectName, err)
+	}
+
+	for _, networkName := range networkNames {
+		_, network, _, err := s.Cluster.GetNetworkInAnyState(policyProjectName, networkName)
+		if err != nil {
+			return fmt.Errorf("Failed to get network config for %q: %w", networkName, err)
+		}
+
+		if network.Config["qos"] == name {
+			err := usageFunc(network, "", nil)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Look for profiles. Next cheapest to do.
+	var profiles []db.Profile
+	err = s.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		profiles, err = tx.GetProfiles(db.ProfileFilter{})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, profile := range profiles {
+		// Get the profiles's effective network project name.
+		profileNetworkProjectName, _, err := project.NetworkProject(s.Cluster, profile.Project)
+		if err != nil {
+			return err
+		}
+
+		// Skip profiles who's effective network project doesn't match this policy's project.
+		if profileNetworkProjectName != policyProjectName {
+			continue
+		}
+
+		for devName, devConfig := range deviceConfig.NewDevices(db.DevicesToAPI(profile.Devices)) {
+			if isInUseByDevice(devConfig, name) {
+				err := usageFunc(profile, devName, devConfig)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// Find instances using the policy. Most expensive to do.
+	err = s.Cluster.InstanceList(nil, func(inst db.Instance, p db.Project, profiles []api.Profile) error {
+		// Skip instances who's effective network project doesn't match this policy's project.
+		if project.NetworkProjectFromRecord(&p) != policyProjectName {
+			return nil
+		}
+
+		devices := db.ExpandInstanceDevices(deviceConfig.NewDevices(db.DevicesToAPI(inst.Devices)), profiles)
+
+		for devName, devConfig := range devices {
+			if isInUseByDevice(devConfig, name) {
+				err := usageFunc(inst, devName, devConfig)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// NICQoSUsage info about an instance NIC and the network it is linked to, if any, which the QoS policy applies to.
+type NICQoSUsage struct {
+	InstanceProject string
+	InstanceName    string
+	InstanceUUID    string
+	InstanceType    instancetype.Type
+	Node            string
+	Name            string
+	HostName        string
+	NetworkID       int64
+	NetworkType     string
+	Config          map[string]string
+}
+
+// OVN returns whether the NIC is an OVN NIC, whose policy is applied with OVN QoS rules.
+func (u NICQoSUsage) OVN() bool {
+	return u.Config["nictype"] == "ovn" || u.NetworkType == "ovn"
+}
+
+// InstanceTX returns whether the host side interface of the NIC transmits the traffic sent by the instance.
+func (u NICQoSUsage) InstanceTX() bool {
+	return u.InstanceType == instancetype.VM && (u.Config["nictype"] == "macvlan" || u.NetworkType == "macvlan")
+}
+
+// NICUsage returns the instance NICs that the QoS policy applies to, either directly or through their network.
+func NICUsage(s *state.State, policyProjectName string, name string) ([]NICQoSUsage, error) {
+	usages := []NICQoSUsage{}
+	networks := map[string]*api.Network{}
+	networkIDs := map[string]int64{}
+
+	// loadNetwork returns the network a NIC is linked to. Macvlan networks only exist in the default project.
+	loadNetwork := func(networkName string) (int64, *api.Network, error) {
+		network, found := networks[networkName]
+		if found {
+			return networkIDs[networkName], network, nil
+		}
+
+		networkID, network, _, err := s.Cluster.GetNetworkInAnyState(policyProjectName, networkName)
+		if response.IsNotFoundError(err) && policyProjectName != project.Default {
+			networkID, network, _, err = s.Cluster.GetNetworkInAnyState(project.Default, networkName)
+		}
+
+		if err != nil {
+			return -1, nil, fmt.Errorf("Failed to load network %q: %w", networkName, err)
+		}
+
+		networks[networkName] = network
+		networkIDs[networkName] = networkID
+
+		return networkID, network, nil
+	}
+
+	err := s.Cluster.InstanceList(nil, func(inst db.Instance, p db.Project, profiles []api.Profile) error {
+		// Skip instances who's effective network project doesn't match this policy's project.
+		if project.NetworkProjectFromRecord(&p) != policyProjectName {
+			return nil
+		}
+
+		devices := db.ExpandInstanceDevices(deviceConfig.NewDevices(db.DevicesToAPI(inst.Devices)), profiles)
+
+		for devName, devConfig := range devices {
+			if devConfig["type"] != "nic" {
+				continue
+			}
+
+			usage := NICQoSUsage{
+				InstanceProject: inst.Project,
+				InstanceName:    inst.Name,
+				InstanceUUID:    inst.Config["volatile.uuid"],
+				InstanceType:    inst.Type,
+				Node:            inst.Node,
+				Name:            devName,
+				HostName:        inst.Config[fmt.Sprintf("volatile.%s.host_name", devName)],
+				NetworkID:       -1,
+				Config:          devConfig,
+			}
+
+			var netConfig map[string]string
+			if devConfig["network"] != "" {
+				networkID, network, err := loadNetwork(devConfig["network"])
+				if err != nil {
+					return err
+				}
+
+				usage.NetworkID = networkID
+				usage.NetworkType = network.Type
+				netConfig = network.Config
+			}
+
+			if NICPolicyName(devConfig, netConfig) == name {
+				usages = append(usages, usage)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return usages, nil
+}
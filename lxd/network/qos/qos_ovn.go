@@ -0,0 +1,42 @@
+package qos
+
+import (
+	"github.com/lxc/lxd/lxd/network/openvswitch"
+)
+
+// ovnQoSPriority is the priority of the QoS rules of instance ports. The rules of each port match distinct traffic.
+const ovnQoSPriority = 100
+
+// ovnRate converts a bit rate to the kbps used by OVN, rounding up so that small limits aren't lost.
+func ovnRate(rate int64) int64 {
+	return (rate + 999) / 1000
+}
+
+// OVNRules returns the OVN QoS rules applying the limits to an instance logical switch port.
+// OVN has no notion of packet priority, so the priority class only applies through its DSCP marking.
+func (l *Limits) OVNRules() []openvswitch.OVNQoSRule {
+	rules := []openvswitch.OVNQoSRule{}
+
+	// Traffic sent by the instance enters the logical switch from its port.
+	if l.EgressRate > 0 || l.DSCP >= 0 {
+		rules = append(rules, openvswitch.OVNQoSRule{
+			Direction: "from-lport",
+			Priority:  ovnQoSPriority,
+			Rate:      ovnRate(l.EgressRate),
+			Burst:     ovnRate(l.EgressBurst * 8),
+			DSCP:      l.DSCP,
+		})
+	}
+
+	if l.IngressRate > 0 {
+		rules = append(rules, openvswitch.OVNQoSRule{
+			Direction: "to-lport",
+			Priority:  ovnQoSPriority,
+			Rate:      ovnRate(l.IngressRate),
+			Burst:     ovnRate(l.IngressBurst * 8),
+			DSCP:      -1,
+		})
+	}
+
+	return rules
+}
@@ -0,0 +1,88 @@
+package qos
+
+import (
+	"fmt"
+
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/units"
+	"github.com/lxc/lxd/shared/validate"
+)
+
+// ValidName checks the QoS policy name is valid.
+func ValidName(name string) error {
+	if name == "" {
+		return fmt.Errorf("Name is required")
+	}
+
+	// Policy names are used in OVN and device config, so keep them to a safe character set.
+	err := validate.IsHostname(name)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ValidPriorities defines the valid priority classes.
+var ValidPriorities = []string{"low", "normal", "high"}
+
+// isBitRate validates a bit rate, such as "100Mbit".
+func isBitRate(value string) error {
+	_, err := units.ParseBitSizeString(value)
+	return err
+}
+
+// isByteSize validates a byte size, such as "1MiB".
+func isByteSize(value string) error {
+	_, err := units.ParseByteSizeString(value)
+	return err
+}
+
+// configRules returns the validation rules of the QoS policy config.
+func configRules() map[string]func(value string) error {
+	return map[string]func(value string) error{
+		"limits.ingress":       validate.Optional(isBitRate),
+		"limits.egress":        validate.Optional(isBitRate),
+		"limits.ingress.burst": validate.Optional(isByteSize),
+		"limits.egress.burst":  validate.Optional(isByteSize),
+		"priority":             validate.Optional(validate.IsOneOf(ValidPriorities...)),
+		"dscp":                 validate.Optional(validate.IsInRange(0, 63)),
+	}
+}
+
+// ValidateConfig checks the QoS policy config is valid.
+func ValidateConfig(config map[string]string) error {
+	rules := configRules()
+
+	// Run the validator against each field.
+	for k, validator := range rules {
+		err := validator(config[k])
+		if err != nil {
+			return fmt.Errorf("Invalid value for config option %q: %w", k, err)
+		}
+	}
+
+	// Look for any unknown fields.
+	for k := range config {
+		_, found := rules[k]
+		if found {
+			continue
+		}
+
+		// User keys are not validated.
+		if shared.IsUserConfig(k) {
+			continue
+		}
+
+		return fmt.Errorf("Invalid config option %q", k)
+	}
+
+	// A burst is only meaningful together with a rate limit.
+	for _, direction := range []string{"ingress", "egress"} {
+		if config[fmt.Sprintf("limits.%s.burst", direction)] != "" && config[fmt.Sprintf("limits.%s", direction)] == "" {
+			return fmt.Errorf("%q requires %q to be set", fmt.Sprintf("limits.%s.burst", direction), fmt.Sprintf("limits.%s", direction))
+		}
+	}
+
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 
 	clusterRequest "github.com/lxc/lxd/lxd/cluster/request"
 	"github.com/lxc/lxd/lxd/lifecycle"
+	"github.com/lxc/lxd/lxd/network"
 	"github.com/lxc/lxd/lxd/network/acl"
 	"github.com/lxc/lxd/lxd/project"
 	"github.com/lxc/lxd/lxd/request"
@@ -462,6 +463,14 @@ func networkACLPut(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// Re-apply the network peerings whose leaked routes are controlled by the ACL.
+	if clientType == clusterRequest.ClientTypeNormal {
+		err = network.UpdatePeersUsingACL(d.State(), projectName, netACL.Info().Name)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	d.State().Events.SendLifecycle(projectName, lifecycle.NetworkACLUpdated.Event(netACL, request.CreateRequestor(r), nil))
 
 	return response.EmptySyncResponse
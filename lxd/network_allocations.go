@@ -0,0 +1,358 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	clusterRequest "github.com/lxc/lxd/lxd/cluster/request"
+	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/device/nictype"
+	"github.com/lxc/lxd/lxd/instance"
+	"github.com/lxc/lxd/lxd/network"
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/lxd/rbac"
+	"github.com/lxc/lxd/lxd/response"
+	"github.com/lxc/lxd/lxd/state"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/version"
+)
+
+var networkAllocationsCmd = APIEndpoint{
+	Path: "network-allocations",
+
+	Get: APIEndpointAction{Handler: networkAllocationsGet, AccessHandler: allowAuthenticated},
+}
+
+// swagger:operation GET /1.0/network-allocations network-allocations network_allocations_get
+//
+// Get the network allocations in use
+//
+// Returns a list of the addresses in use on the managed networks of the project (structs), including the addresses
+// of the networks themselves, of their forwards, load balancers and reservations, and of the instance NICs.
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: query
+//     name: all-projects
+//     description: Retrieve entities from all projects
+//     type: boolean
+//     example: true
+// responses:
+//   "200":
+//     description: API endpoints
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           type: array
+//           description: List of network allocations
+//           items:
+//             $ref: "#/definitions/NetworkAllocation"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkAllocationsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	var err error
+	var projectNames []string
+
+	if shared.IsTrue(queryParam(r, "all-projects")) {
+		err = s.Cluster.Transaction(func(tx *db.ClusterTx) error {
+			projectNames, err = tx.GetProjectNames()
+			return err
+		})
+		if err != nil {
+			return response.SmartError(fmt.Errorf("Failed loading projects: %w", err))
+		}
+	} else {
+		projectNames = []string{projectParam(r)}
+
+		if !rbac.UserHasPermission(r, projectNames[0], "view") {
+			return response.Forbidden(nil)
+		}
+	}
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+
+	allocations := []api.NetworkAllocation{}
+
+	// Network level allocations are only listed once for all the projects sharing the same network project.
+	networkProjectsDone := make(map[string]struct{})
+
+	for _, projectName := range projectNames {
+		// Only list the projects the user is allowed to see.
+		if !rbac.UserHasPermission(r, projectName, "view") {
+			continue
+		}
+
+		networkProjectName, _, err := project.NetworkProject(s.Cluster, projectName)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		networkNames, err := s.Cluster.GetNetworks(networkProjectName)
+		if err != nil {
+			return response.SmartError(fmt.Errorf("Failed loading networks: %w", err))
+		}
+
+		_, networkProjectDone := networkProjectsDone[networkProjectName]
+
+		for _, networkName := range networkNames {
+			n, err := network.LoadByName(s, networkProjectName, networkName)
+			if err != nil {
+				return response.SmartError(fmt.Errorf("Failed loading network %q in project %q: %w", networkName, networkProjectName, err))
+			}
+
+			if n.Status() != api.NetworkStatusCreated {
+				continue
+			}
+
+			if !networkProjectDone {
+				networkAllocations, err := networkAllocationsNetwork(s, n)
+				if err != nil {
+					return response.SmartError(err)
+				}
+
+				allocations = append(allocations, networkAllocations...)
+			}
+
+			instanceAllocations, err := networkAllocationsInstances(n, projectName, clientType)
+			if err != nil {
+				return response.SmartError(err)
+			}
+
+			allocations = append(allocations, instanceAllocations...)
+		}
+
+		networkProjectsDone[networkProjectName] = struct{}{}
+
+		routedAllocations, err := networkAllocationsRoutedNICs(s, projectName)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		allocations = append(allocations, routedAllocations...)
+	}
+
+	return response.SyncResponse(true, allocations)
+}
+
+// networkAllocationAddress returns the address in CIDR notation for a single address.
+func networkAllocationAddress(address string) string {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return address
+	}
+
+	if ip.To4() != nil {
+		return fmt.Sprintf("%s/32", ip.String())
+	}
+
+	return fmt.Sprintf("%s/128", ip.String())
+}
+
+// networkAllocationNAT returns whether the network NATs the traffic of the given address.
+func networkAllocationNAT(netConfig map[string]string, address string) bool {
+	ip := net.ParseIP(address)
+	if ip != nil && ip.To4() == nil {
+		return shared.IsTrue(netConfig["ipv6.nat"])
+	}
+
+	return shared.IsTrue(netConfig["ipv4.nat"])
+}
+
+// networkAllocationsNetwork returns the addresses used by the network itself, its uplink addresses, forwards,
+// load balancers and address reservations.
+func networkAllocationsNetwork(s *state.State, n network.Network) ([]api.NetworkAllocation, error) {
+	allocations := []api.NetworkAllocation{}
+	netConfig := n.Config()
+	networkURL := api.NewURL().Path(version.APIVersion, "networks", n.Name()).Project(n.Project())
+
+	// Addresses of the network.
+	for _, key := range []string{"ipv4.address", "ipv6.address", "ipv4.gateway", "ipv6.gateway"} {
+		ip, _, err := net.ParseCIDR(netConfig[key])
+		if err != nil {
+			continue
+		}
+
+		allocations = append(allocations, api.NetworkAllocation{
+			Address: netConfig[key],
+			UsedBy:  networkURL.String(),
+			Type:    "network",
+			NAT:     networkAllocationNAT(netConfig, ip.String()),
+			Network: n.Name(),
+			Project: n.Project(),
+		})
+	}
+
+	// Addresses of the network on its uplink.
+	for _, key := range []string{"volatile.network.ipv4.address", "volatile.network.ipv6.address"} {
+		if netConfig[key] == "" {
+			continue
+		}
+
+		allocations = append(allocations, api.NetworkAllocation{
+			Address: networkAllocationAddress(netConfig[key]),
+			UsedBy:  networkURL.String(),
+			Type:    "network",
+			Network: netConfig["network"],
+			Project: n.Project(),
+		})
+	}
+
+	if n.Info().AddressForwards {
+		listenAddresses, err := s.Cluster.GetNetworkForwardListenAddresses(n.ID(), false)
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading network forwards: %w", err)
+		}
+
+		for _, listenAddress := range listenAddresses {
+			allocations = append(allocations, api.NetworkAllocation{
+				Address: networkAllocationAddress(listenAddress),
+				UsedBy:  api.NewURL().Path(version.APIVersion, "networks", n.Name(), "forwards", listenAddress).Project(n.Project()).String(),
+				Type:    "network-forward",
+				Network: n.Name(),
+				Project: n.Project(),
+			})
+		}
+	}
+
+	if n.Info().LoadBalancers {
+		listenAddresses, err := s.Cluster.GetNetworkLoadBalancerListenAddresses(n.ID(), false)
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading network load balancers: %w", err)
+		}
+
+		for _, listenAddress := range listenAddresses {
+			allocations = append(allocations, api.NetworkAllocation{
+				Address: networkAllocationAddress(listenAddress),
+				UsedBy:  api.NewURL().Path(version.APIVersion, "networks", n.Name(), "load-balancers", listenAddress).Project(n.Project()).String(),
+				Type:    "network-load-balancer",
+				Network: n.Name(),
+				Project: n.Project(),
+			})
+		}
+	}
+
+	if n.Info().AddressReservations {
+		reservations, err := s.Cluster.GetNetworkReservations(n.ID())
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading network address reservations: %w", err)
+		}
+
+		for _, reservation := range reservations {
+			allocations = append(allocations, api.NetworkAllocation{
+				Address: networkAllocationAddress(reservation.Address),
+				UsedBy:  api.NewURL().Path(version.APIVersion, "networks", n.Name(), "reservations", reservation.Address).Project(n.Project()).String(),
+				Type:    "network-reservation",
+				NAT:     networkAllocationNAT(netConfig, reservation.Address),
+				Network: n.Name(),
+				Project: reservation.Project,
+			})
+		}
+	}
+
+	return allocations, nil
+}
+
+// networkAllocationsInstances returns the addresses leased by the instances of the project on the network.
+func networkAllocationsInstances(n network.Network, projectName string, clientType clusterRequest.ClientType) ([]api.NetworkAllocation, error) {
+	allocations := []api.NetworkAllocation{}
+
+	leases, err := n.Leases(projectName, clientType)
+	if err != nil {
+		if errors.Is(err, network.ErrNotImplemented) {
+			return allocations, nil
+		}
+
+		return nil, fmt.Errorf("Failed loading leases of network %q: %w", n.Name(), err)
+	}
+
+	for _, lease := range leases {
+		// Uplink leases are listed with the network using the uplink.
+		if lease.Type == "uplink" {
+			continue
+		}
+
+		allocations = append(allocations, api.NetworkAllocation{
+			Address: networkAllocationAddress(lease.Address),
+			UsedBy:  api.NewURL().Path(version.APIVersion, "instances", lease.Hostname).Project(projectName).String(),
+			Type:    "instance",
+			NAT:     networkAllocationNAT(n.Config(), lease.Address),
+			Hwaddr:  lease.Hwaddr,
+			Network: n.Name(),
+			Project: projectName,
+		})
+	}
+
+	return allocations, nil
+}
+
+// networkAllocationsRoutedNICs returns the addresses of the routed and ipvlan NICs of the project's instances.
+// Those NICs aren't connected to a managed network, so their parent interface is reported as their network.
+func networkAllocationsRoutedNICs(s *state.State, projectName string) ([]api.NetworkAllocation, error) {
+	allocations := []api.NetworkAllocation{}
+
+	instances, err := instance.LoadByProject(s, projectName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading instances: %w", err)
+	}
+
+	for _, inst := range instances {
+		for devName, dev := range inst.ExpandedDevices() {
+			if dev["type"] != "nic" {
+				continue
+			}
+
+			nicType, err := nictype.NICType(s, inst.Project(), dev)
+			if err != nil || !shared.StringInSlice(nicType, []string{"routed", "ipvlan"}) {
+				continue
+			}
+
+			hwaddr := dev["hwaddr"]
+			if hwaddr == "" {
+				hwaddr = inst.LocalConfig()[fmt.Sprintf("volatile.%s.hwaddr", devName)]
+			}
+
+			for _, key := range []string{"ipv4.address", "ipv6.address"} {
+				for _, address := range shared.SplitNTrimSpace(dev[key], ",", -1, true) {
+					allocations = append(allocations, api.NetworkAllocation{
+						Address: networkAllocationAddress(address),
+						UsedBy:  api.NewURL().Path(version.APIVersion, "instances", inst.Name()).Project(inst.Project()).String(),
+						Type:    "instance",
+						Hwaddr:  hwaddr,
+						Network: dev["parent"],
+						Project: inst.Project(),
+					})
+				}
+			}
+		}
+	}
+
+	return allocations, nil
+}
@@ -22,17 +22,17 @@ import (
 var networkForwardsCmd = APIEndpoint{
 	Path: "networks/{networkName}/forwards",
 
-	Get:  APIEndpointAction{Handler: networkForwardsGet, AccessHandler: allowProjectPermission("networks", "view")},
-	Post: APIEndpointAction{Handler: networkForwardsPost, AccessHandler: allowProjectPermission("networks", "manage-networks")},
+	Get:  APIEndpointAction{Handler: networkForwardsGet, AccessHandler: allowNetworkPermission("view")},
+	Post: APIEndpointAction{Handler: networkForwardsPost, AccessHandler: allowNetworkPermission("manage-networks")},
 }
 
 var networkForwardCmd = APIEndpoint{
 	Path: "networks/{networkName}/forwards/{listenAddress}",
 
-	Delete: APIEndpointAction{Handler: networkForwardDelete, AccessHandler: allowProjectPermission("networks", "manage-networks")},
-	Get:    APIEndpointAction{Handler: networkForwardGet, AccessHandler: allowProjectPermission("networks", "view")},
-	Put:    APIEndpointAction{Handler: networkForwardPut, AccessHandler: allowProjectPermission("networks", "manage-networks")},
-	Patch:  APIEndpointAction{Handler: networkForwardPut, AccessHandler: allowProjectPermission("networks", "manage-networks")},
+	Delete: APIEndpointAction{Handler: networkForwardDelete, AccessHandler: allowNetworkPermission("manage-networks")},
+	Get:    APIEndpointAction{Handler: networkForwardGet, AccessHandler: allowNetworkPermission("view")},
+	Put:    APIEndpointAction{Handler: networkForwardPut, AccessHandler: allowNetworkPermission("manage-networks")},
+	Patch:  APIEndpointAction{Handler: networkForwardPut, AccessHandler: allowNetworkPermission("manage-networks")},
 }
 
 // API endpoints
@@ -21,17 +21,23 @@ import (
 var networkPeersCmd = APIEndpoint{
 	Path: "networks/{networkName}/peers",
 
-	Get:  APIEndpointAction{Handler: networkPeersGet, AccessHandler: allowProjectPermission("networks", "view")},
-	Post: APIEndpointAction{Handler: networkPeersPost, AccessHandler: allowProjectPermission("networks", "manage-networks")},
+	Get:  APIEndpointAction{Handler: networkPeersGet, AccessHandler: allowNetworkPermission("view")},
+	Post: APIEndpointAction{Handler: networkPeersPost, AccessHandler: allowNetworkPermission("manage-networks")},
 }
 
 var networkPeerCmd = APIEndpoint{
 	Path: "networks/{networkName}/peers/{peerName}",
 
-	Delete: APIEndpointAction{Handler: networkPeerDelete, AccessHandler: allowProjectPermission("networks", "manage-networks")},
-	Get:    APIEndpointAction{Handler: networkPeerGet, AccessHandler: allowProjectPermission("networks", "view")},
-	Put:    APIEndpointAction{Handler: networkPeerPut, AccessHandler: allowProjectPermission("networks", "manage-networks")},
-	Patch:  APIEndpointAction{Handler: networkPeerPut, AccessHandler: allowProjectPermission("networks", "manage-networks")},
+	Delete: APIEndpointAction{Handler: networkPeerDelete, AccessHandler: allowNetworkPermission("manage-networks")},
+	Get:    APIEndpointAction{Handler: networkPeerGet, AccessHandler: allowNetworkPermission("view")},
+	Put:    APIEndpointAction{Handler: networkPeerPut, AccessHandler: allowNetworkPermission("manage-networks")},
+	Patch:  APIEndpointAction{Handler: networkPeerPut, AccessHandler: allowNetworkPermission("manage-networks")},
+}
+
+var networkPeerInvitationsCmd = APIEndpoint{
+	Path: "networks/{networkName}/peer-invitations",
+
+	Get: APIEndpointAction{Handler: networkPeerInvitationsGet, AccessHandler: allowNetworkPermission("view")},
 }
 
 // API endpoints
@@ -478,3 +484,72 @@ func networkPeerPut(d *Daemon, r *http.Request) response.Response {
 
 	return response.EmptySyncResponse
 }
+
+// swagger:operation GET /1.0/networks/{networkName}/peer-invitations network-peers network_peer_invitations_get
+//
+// Get the network peer invitations
+//
+// Returns a list of pending peerings initiated by networks in other projects towards this network.
+// An invitation is accepted by creating a peer on this network that targets the inviting network.
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     description: API endpoints
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           type: array
+//           description: List of network peer invitations
+//           items:
+//             $ref: "#/definitions/NetworkPeerInvitation"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkPeerInvitationsGet(d *Daemon, r *http.Request) response.Response {
+	projectName, _, err := project.NetworkProject(d.State().Cluster, projectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	n, err := network.LoadByName(d.State(), projectName, mux.Vars(r)["networkName"])
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	if !n.Info().Peering {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support peering", n.Type()))
+	}
+
+	invitations, err := d.State().Cluster.GetNetworkPeerInvitations(projectName, n.Name())
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network peer invitations: %w", err))
+	}
+
+	return response.SyncResponse(true, invitations)
+}
@@ -0,0 +1,525 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	clusterRequest "github.com/lxc/lxd/lxd/cluster/request"
+	"github.com/lxc/lxd/lxd/lifecycle"
+	"github.com/lxc/lxd/lxd/network/qos"
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/lxd/request"
+	"github.com/lxc/lxd/lxd/response"
+	"github.com/lxc/lxd/lxd/util"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/logger"
+	"github.com/lxc/lxd/shared/version"
+)
+
+var networkQoSPoliciesCmd = APIEndpoint{
+	Path: "network-qos",
+
+	Get:  APIEndpointAction{Handler: networkQoSPoliciesGet, AccessHandler: allowProjectPermission("networks", "view")},
+	Post: APIEndpointAction{Handler: networkQoSPoliciesPost, AccessHandler: allowProjectPermission("networks", "manage-networks")},
+}
+
+var networkQoSPolicyCmd = APIEndpoint{
+	Path: "network-qos/{name}",
+
+	Delete: APIEndpointAction{Handler: networkQoSPolicyDelete, AccessHandler: allowProjectPermission("networks", "manage-networks")},
+	Get:    APIEndpointAction{Handler: networkQoSPolicyGet, AccessHandler: allowProjectPermission("networks", "view")},
+	Put:    APIEndpointAction{Handler: networkQoSPolicyPut, AccessHandler: allowProjectPermission("networks", "manage-networks")},
+	Patch:  APIEndpointAction{Handler: networkQoSPolicyPut, AccessHandler: allowProjectPermission("networks", "manage-networks")},
+	Post:   APIEndpointAction{Handler: networkQoSPolicyPost, AccessHandler: allowProjectPermission("networks", "manage-networks")},
+}
+
+// API endpoints.
+
+// swagger:operation GET /1.0/network-qos network-qos network_qos_get
+//
+// Get the network QoS policies
+//
+// Returns a list of network QoS policies (URLs).
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     description: API endpoints
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           type: array
+//           description: List of endpoints
+//           items:
+//             type: string
+//           example: |-
+//             [
+//               "/1.0/network-qos/foo",
+//               "/1.0/network-qos/bar"
+//             ]
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/network-qos?recursion=1 network-qos network_qos_get_recursion1
+//
+// Get the network QoS policies
+//
+// Returns a list of network QoS policies (structs).
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     description: API endpoints
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           type: array
+//           description: List of network QoS policies
+//           items:
+//             $ref: "#/definitions/NetworkQoSPolicy"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkQoSPoliciesGet(d *Daemon, r *http.Request) response.Response {
+	projectName, _, err := project.NetworkProject(d.State().Cluster, projectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	recursion := util.IsRecursionRequest(r)
+
+	// Get list of network QoS policies.
+	policyNames, err := d.cluster.GetNetworkQoSPolicies(projectName)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	resultString := []string{}
+	resultMap := []api.NetworkQoSPolicy{}
+	for _, policyName := range policyNames {
+		if !recursion {
+			resultString = append(resultString, fmt.Sprintf("/%s/network-qos/%s", version.APIVersion, policyName))
+		} else {
+			policy, err := qos.LoadByName(d.State(), projectName, policyName)
+			if err != nil {
+				continue
+			}
+
+			policyInfo := policy.Info()
+			policyInfo.UsedBy, _ = policy.UsedBy() // Ignore errors in UsedBy, will return nil.
+
+			resultMap = append(resultMap, *policyInfo)
+		}
+	}
+
+	if !recursion {
+		return response.SyncResponse(true, resultString)
+	}
+
+	return response.SyncResponse(true, resultMap)
+}
+
+// swagger:operation POST /1.0/network-qos network-qos network_qos_post
+//
+// Add a network QoS policy
+//
+// Creates a new network QoS policy.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: body
+//     name: policy
+//     description: QoS policy
+//     required: true
+//     schema:
+//       $ref: "#/definitions/NetworkQoSPoliciesPost"
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkQoSPoliciesPost(d *Daemon, r *http.Request) response.Response {
+	projectName, _, err := project.NetworkProject(d.State().Cluster, projectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.NetworkQoSPoliciesPost{}
+
+	// Parse the request into a record.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	_, err = qos.LoadByName(d.State(), projectName, req.Name)
+	if err == nil {
+		return response.BadRequest(fmt.Errorf("The network QoS policy already exists"))
+	}
+
+	err = qos.Create(d.State(), projectName, &req)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	policy, err := qos.LoadByName(d.State(), projectName, req.Name)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	d.State().Events.SendLifecycle(projectName, lifecycle.NetworkQoSPolicyCreated.Event(policy, request.CreateRequestor(r), nil))
+
+	url := fmt.Sprintf("/%s/network-qos/%s", version.APIVersion, req.Name)
+	return response.SyncResponseLocation(true, nil, url)
+}
+
+// swagger:operation DELETE /1.0/network-qos/{name} network-qos network_qos_policy_delete
+//
+// Delete the network QoS policy
+//
+// Removes the network QoS policy.
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkQoSPolicyDelete(d *Daemon, r *http.Request) response.Response {
+	projectName, _, err := project.NetworkProject(d.State().Cluster, projectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	policy, err := qos.LoadByName(d.State(), projectName, mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = policy.Delete()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	d.State().Events.SendLifecycle(projectName, lifecycle.NetworkQoSPolicyDeleted.Event(policy, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/network-qos/{name} network-qos network_qos_policy_get
+//
+// Get the network QoS policy
+//
+// Gets a specific network QoS policy.
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     description: QoS policy
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           $ref: "#/definitions/NetworkQoSPolicy"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkQoSPolicyGet(d *Daemon, r *http.Request) response.Response {
+	projectName, _, err := project.NetworkProject(d.State().Cluster, projectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	policy, err := qos.LoadByName(d.State(), projectName, mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	info := policy.Info()
+	info.UsedBy, err = policy.UsedBy()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, info, policy.Etag())
+}
+
+// swagger:operation PATCH /1.0/network-qos/{name} network-qos network_qos_policy_patch
+//
+// Partially update the network QoS policy
+//
+// Updates a subset of the network QoS policy configuration.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: body
+//     name: policy
+//     description: QoS policy configuration
+//     required: true
+//     schema:
+//       $ref: "#/definitions/NetworkQoSPolicyPut"
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "412":
+//     $ref: "#/responses/PreconditionFailed"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/network-qos/{name} network-qos network_qos_policy_put
+//
+// Update the network QoS policy
+//
+// Updates the entire network QoS policy configuration.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: body
+//     name: policy
+//     description: QoS policy configuration
+//     required: true
+//     schema:
+//       $ref: "#/definitions/NetworkQoSPolicyPut"
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "412":
+//     $ref: "#/responses/PreconditionFailed"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkQoSPolicyPut(d *Daemon, r *http.Request) response.Response {
+	projectName, _, err := project.NetworkProject(d.State().Cluster, projectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Get the existing network QoS policy.
+	policy, err := qos.LoadByName(d.State(), projectName, mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Validate the ETag.
+	err = util.EtagCheck(r, policy.Etag())
+	if err != nil {
+		return response.PreconditionFailed(err)
+	}
+
+	req := api.NetworkQoSPolicyPut{}
+
+	// Decode the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if r.Method == http.MethodPatch {
+		// If config being updated via "patch" method, then merge all existing config with the keys that
+		// are present in the request config.
+		for k, v := range policy.Info().Config {
+			_, ok := req.Config[k]
+			if !ok {
+				req.Config[k] = v
+			}
+		}
+	}
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+
+	err = policy.Update(&req, clientType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	d.State().Events.SendLifecycle(projectName, lifecycle.NetworkQoSPolicyUpdated.Event(policy, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation POST /1.0/network-qos/{name} network-qos network_qos_policy_post
+//
+// Rename the network QoS policy
+//
+// Renames an existing network QoS policy.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: body
+//     name: policy
+//     description: QoS policy rename request
+//     required: true
+//     schema:
+//       $ref: "#/definitions/NetworkQoSPolicyPost"
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkQoSPolicyPost(d *Daemon, r *http.Request) response.Response {
+	name := mux.Vars(r)["name"]
+
+	projectName, _, err := project.NetworkProject(d.State().Cluster, projectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.NetworkQoSPolicyPost{}
+
+	// Parse the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Get the existing network QoS policy.
+	policy, err := qos.LoadByName(d.State(), projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = policy.Rename(req.Name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	d.State().Events.SendLifecycle(projectName, lifecycle.NetworkQoSPolicyRenamed.Event(policy, request.CreateRequestor(r), logger.Ctx{"old_name": name}))
+
+	url := fmt.Sprintf("/%s/network-qos/%s", version.APIVersion, req.Name)
+	return response.SyncResponseLocation(true, nil, url)
+}
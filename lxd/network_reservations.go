@@ -0,0 +1,448 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+
+	clusterRequest "github.com/lxc/lxd/lxd/cluster/request"
+	"github.com/lxc/lxd/lxd/lifecycle"
+	"github.com/lxc/lxd/lxd/network"
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/lxd/request"
+	"github.com/lxc/lxd/lxd/response"
+	"github.com/lxc/lxd/lxd/util"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/version"
+)
+
+var networkReservationsCmd = APIEndpoint{
+	Path: "networks/{networkName}/reservations",
+
+	Get:  APIEndpointAction{Handler: networkReservationsGet, AccessHandler: allowNetworkPermission("view")},
+	Post: APIEndpointAction{Handler: networkReservationsPost, AccessHandler: allowNetworkPermission("manage-networks")},
+}
+
+var networkReservationCmd = APIEndpoint{
+	Path: "networks/{networkName}/reservations/{address}",
+
+	Delete: APIEndpointAction{Handler: networkReservationDelete, AccessHandler: allowNetworkPermission("manage-networks")},
+	Get:    APIEndpointAction{Handler: networkReservationGet, AccessHandler: allowNetworkPermission("view")},
+	Put:    APIEndpointAction{Handler: networkReservationPut, AccessHandler: allowNetworkPermission("manage-networks")},
+}
+
+// API endpoints
+
+// swagger:operation GET /1.0/networks/{networkName}/reservations network-reservations network_reservations_get
+//
+// Get the network address reservations
+//
+// Returns a list of network address reservations (URLs).
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     description: API endpoints
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           type: array
+//           description: List of endpoints
+//           items:
+//             type: string
+//           example: |-
+//             [
+//               "/1.0/networks/lxdbr0/reservations/10.0.0.10",
+//               "/1.0/networks/lxdbr0/reservations/10.0.0.11"
+//             ]
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/networks/{networkName}/reservations?recursion=1 network-reservations network_reservation_get_recursion1
+//
+// Get the network address reservations
+//
+// Returns a list of network address reservations (structs).
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     description: API endpoints
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           type: array
+//           description: List of network address reservations
+//           items:
+//             $ref: "#/definitions/NetworkReservation"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkReservationsGet(d *Daemon, r *http.Request) response.Response {
+	projectName, _, err := project.NetworkProject(d.State().Cluster, projectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	n, err := network.LoadByName(d.State(), projectName, mux.Vars(r)["networkName"])
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	if !n.Info().AddressReservations {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support address reservations", n.Type()))
+	}
+
+	records, err := d.State().Cluster.GetNetworkReservations(n.ID())
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network address reservations: %w", err))
+	}
+
+	if util.IsRecursionRequest(r) {
+		reservations := make([]*api.NetworkReservation, 0, len(records))
+		for _, record := range records {
+			reservations = append(reservations, record)
+		}
+
+		return response.SyncResponse(true, reservations)
+	}
+
+	reservationURLs := make([]string, 0, len(records))
+	for _, record := range records {
+		reservationURLs = append(reservationURLs, fmt.Sprintf("/%s/networks/%s/reservations/%s", version.APIVersion, url.PathEscape(n.Name()), url.PathEscape(record.Address)))
+	}
+
+	return response.SyncResponse(true, reservationURLs)
+}
+
+// swagger:operation POST /1.0/networks/{networkName}/reservations network-reservations network_reservations_post
+//
+// Add a network address reservation
+//
+// Creates a new network address reservation.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: body
+//     name: reservation
+//     description: Reservation
+//     required: true
+//     schema:
+//       $ref: "#/definitions/NetworkReservationsPost"
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkReservationsPost(d *Daemon, r *http.Request) response.Response {
+	networkProjectName, _, err := project.NetworkProject(d.State().Cluster, projectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Parse the request into a record.
+	req := api.NetworkReservationsPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	req.Normalise() // So we handle the request in normalised/canonical form.
+
+	if net.ParseIP(req.Address) == nil {
+		return response.BadRequest(fmt.Errorf("Invalid address %q", req.Address))
+	}
+
+	n, err := network.LoadByName(d.State(), networkProjectName, mux.Vars(r)["networkName"])
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	if !n.Info().AddressReservations {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support address reservations", n.Type()))
+	}
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+
+	// The reservation belongs to the project of the request, which is the project of the instance it is for.
+	err = n.ReservationCreate(projectParam(r), req, clientType)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed creating address reservation: %w", err))
+	}
+
+	if clientType == clusterRequest.ClientTypeNormal {
+		d.State().Events.SendLifecycle(networkProjectName, lifecycle.NetworkReservationCreated.Event(n, req.Address, request.CreateRequestor(r), nil))
+	}
+
+	url := fmt.Sprintf("/%s/networks/%s/reservations/%s", version.APIVersion, url.PathEscape(n.Name()), url.PathEscape(req.Address))
+	return response.SyncResponseLocation(true, nil, url)
+}
+
+// swagger:operation DELETE /1.0/networks/{networkName}/reservations/{address} network-reservations network_reservation_delete
+//
+// Delete the network address reservation
+//
+// Removes the network address reservation.
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkReservationDelete(d *Daemon, r *http.Request) response.Response {
+	projectName, _, err := project.NetworkProject(d.State().Cluster, projectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	n, err := network.LoadByName(d.State(), projectName, mux.Vars(r)["networkName"])
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	if !n.Info().AddressReservations {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support address reservations", n.Type()))
+	}
+
+	address := mux.Vars(r)["address"]
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+
+	err = n.ReservationDelete(address, clientType)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed deleting address reservation: %w", err))
+	}
+
+	if clientType == clusterRequest.ClientTypeNormal {
+		d.State().Events.SendLifecycle(projectName, lifecycle.NetworkReservationDeleted.Event(n, address, request.CreateRequestor(r), nil))
+	}
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/networks/{networkName}/reservations/{address} network-reservations network_reservation_get
+//
+// Get the network address reservation
+//
+// Gets a specific network address reservation.
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+// responses:
+//   "200":
+//     description: Address reservation
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           $ref: "#/definitions/NetworkReservation"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkReservationGet(d *Daemon, r *http.Request) response.Response {
+	projectName, _, err := project.NetworkProject(d.State().Cluster, projectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	n, err := network.LoadByName(d.State(), projectName, mux.Vars(r)["networkName"])
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	if !n.Info().AddressReservations {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support address reservations", n.Type()))
+	}
+
+	_, reservation, err := d.State().Cluster.GetNetworkReservation(n.ID(), mux.Vars(r)["address"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, reservation, reservation.Etag())
+}
+
+// swagger:operation PUT /1.0/networks/{networkName}/reservations/{address} network-reservations network_reservation_put
+//
+// Update the network address reservation
+//
+// Updates the entire network address reservation.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: body
+//     name: reservation
+//     description: Address reservation
+//     required: true
+//     schema:
+//       $ref: "#/definitions/NetworkReservationPut"
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "412":
+//     $ref: "#/responses/PreconditionFailed"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func networkReservationPut(d *Daemon, r *http.Request) response.Response {
+	projectName, _, err := project.NetworkProject(d.State().Cluster, projectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	n, err := network.LoadByName(d.State(), projectName, mux.Vars(r)["networkName"])
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	if !n.Info().AddressReservations {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support address reservations", n.Type()))
+	}
+
+	address := mux.Vars(r)["address"]
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+
+	if clientType == clusterRequest.ClientTypeNormal {
+		_, reservation, err := d.State().Cluster.GetNetworkReservation(n.ID(), address)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		// Validate the ETag.
+		err = util.EtagCheck(r, reservation.Etag())
+		if err != nil {
+			return response.PreconditionFailed(err)
+		}
+	}
+
+	// Decode the request.
+	req := api.NetworkReservationPut{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	req.Normalise() // So we handle the request in normalised/canonical form.
+
+	err = n.ReservationUpdate(address, req, clientType)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed updating address reservation: %w", err))
+	}
+
+	if clientType == clusterRequest.ClientTypeNormal {
+		d.State().Events.SendLifecycle(projectName, lifecycle.NetworkReservationUpdated.Event(n, address, request.CreateRequestor(r), nil))
+	}
+
+	return response.EmptySyncResponse
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/lxc/lxd/lxd/network"
 	"github.com/lxc/lxd/lxd/network/openvswitch"
 	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/lxd/rbac"
 	"github.com/lxc/lxd/lxd/request"
 	"github.com/lxc/lxd/lxd/resources"
 	"github.com/lxc/lxd/lxd/response"
@@ -44,30 +45,30 @@ var networkCreateLock sync.Mutex
 var networksCmd = APIEndpoint{
 	Path: "networks",
 
-	Get:  APIEndpointAction{Handler: networksGet, AccessHandler: allowProjectPermission("networks", "view")},
+	Get:  APIEndpointAction{Handler: networksGet, AccessHandler: allowNetworkPermission("view")},
 	Post: APIEndpointAction{Handler: networksPost, AccessHandler: allowProjectPermission("networks", "manage-networks")},
 }
 
 var networkCmd = APIEndpoint{
 	Path: "networks/{name}",
 
-	Delete: APIEndpointAction{Handler: networkDelete, AccessHandler: allowProjectPermission("networks", "manage-networks")},
-	Get:    APIEndpointAction{Handler: networkGet, AccessHandler: allowProjectPermission("networks", "view")},
-	Patch:  APIEndpointAction{Handler: networkPatch, AccessHandler: allowProjectPermission("networks", "manage-networks")},
-	Post:   APIEndpointAction{Handler: networkPost, AccessHandler: allowProjectPermission("networks", "manage-networks")},
-	Put:    APIEndpointAction{Handler: networkPut, AccessHandler: allowProjectPermission("networks", "manage-networks")},
+	Delete: APIEndpointAction{Handler: networkDelete, AccessHandler: allowNetworkPermission("manage-networks")},
+	Get:    APIEndpointAction{Handler: networkGet, AccessHandler: allowNetworkPermission("view")},
+	Patch:  APIEndpointAction{Handler: networkPatch, AccessHandler: allowNetworkPermission("manage-networks")},
+	Post:   APIEndpointAction{Handler: networkPost, AccessHandler: allowNetworkPermission("manage-networks")},
+	Put:    APIEndpointAction{Handler: networkPut, AccessHandler: allowNetworkPermission("manage-networks")},
 }
 
 var networkLeasesCmd = APIEndpoint{
 	Path: "networks/{name}/leases",
 
-	Get: APIEndpointAction{Handler: networkLeasesGet, AccessHandler: allowProjectPermission("networks", "view")},
+	Get: APIEndpointAction{Handler: networkLeasesGet, AccessHandler: allowNetworkPermission("view")},
 }
 
 var networkStateCmd = APIEndpoint{
 	Path: "networks/{name}/state",
 
-	Get: APIEndpointAction{Handler: networkStateGet, AccessHandler: allowProjectPermission("networks", "view")},
+	Get: APIEndpointAction{Handler: networkStateGet, AccessHandler: allowNetworkPermission("view")},
 }
 
 // API endpoints
@@ -203,9 +204,16 @@ func networksGet(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	// Users with permissions on individual networks only get to see those.
+	allNetworks := rbac.UserHasPermission(r, projectParam(r), "view")
+
 	resultString := []string{}
 	resultMap := []api.Network{}
 	for _, networkName := range networkNames {
+		if !allNetworks && !rbac.UserHasNetworkPermission(r, projectName, networkName, "view") {
+			continue
+		}
+
 		if !recursion {
 			resultString = append(resultString, fmt.Sprintf("/%s/networks/%s", version.APIVersion, networkName))
 		} else {
@@ -6,6 +6,7 @@ import (
 	"github.com/lxc/lxd/lxd/network"
 	"github.com/lxc/lxd/lxd/project"
 	"github.com/lxc/lxd/lxd/state"
+	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/logger"
 )
 
@@ -21,11 +22,11 @@ func networkAutoAttach(cluster *db.Cluster, devName string) error {
 	return network.AttachInterface(dbInfo.Name, devName)
 }
 
-// networkUpdateForkdnsServersTask runs every 30s and refreshes the forkdns servers list.
+// networkUpdateForkdnsServersTask runs every 30s and refreshes the forkdns servers list and the EVPN peers.
 func networkUpdateForkdnsServersTask(s *state.State, heartbeatData *cluster.APIHeartbeat) error {
 	logger.Debug("Refreshing forkdns servers")
 
-	// Use project.Default here as forkdns (fan bridge) networks don't support projects.
+	// Use project.Default here as forkdns (fan bridge) and EVPN bridge networks don't support projects.
 	projectName := project.Default
 
 	// Get a list of managed networks
@@ -41,7 +42,7 @@ func networkUpdateForkdnsServersTask(s *state.State, heartbeatData *cluster.APIH
 			continue
 		}
 
-		if n.Type() == "bridge" && n.Config()["bridge.mode"] == "fan" {
+		if n.Type() == "bridge" && shared.StringInSlice(n.Config()["bridge.mode"], []string{"fan", "evpn"}) {
 			err := n.HandleHeartbeat(heartbeatData)
 			if err != nil {
 				return err
@@ -91,6 +91,16 @@ func (c *Config) MetricsAddress() string {
 	return metricsAddress
 }
 
+// StorageBucketsAddress returns the address and port to setup the storage buckets listener on
+func (c *Config) StorageBucketsAddress() string {
+	storageBucketsAddress := c.m.GetString("core.storage_buckets_address")
+	if storageBucketsAddress != "" {
+		return util.CanonicalNetworkAddress(storageBucketsAddress, shared.HTTPSStorageBucketsDefaultPort)
+	}
+
+	return storageBucketsAddress
+}
+
 // MAASMachine returns the MAAS machine this instance is associated with, if
 // any.
 func (c *Config) MAASMachine() string {
@@ -240,6 +250,22 @@ func MetricsAddress(node *db.Node) (string, error) {
 	return config.MetricsAddress(), nil
 }
 
+// StorageBucketsAddress is a convenience for loading the node configuration and
+// returning the value of core.storage_buckets_address.
+func StorageBucketsAddress(node *db.Node) (string, error) {
+	var config *Config
+	err := node.Transaction(func(tx *db.NodeTx) error {
+		var err error
+		config, err = ConfigLoad(tx)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return config.StorageBucketsAddress(), nil
+}
+
 func (c *Config) update(values map[string]any) (map[string]string, error) {
 	changed, err := c.m.Change(values)
 	if err != nil {
@@ -277,6 +303,9 @@ var ConfigSchema = config.Schema{
 	// Network address for the metrics server
 	"core.metrics_address": {Validator: validate.Optional(validate.IsListenAddress(true, true, false))},
 
+	// Network address for the storage buckets server
+	"core.storage_buckets_address": {Validator: validate.Optional(validate.IsListenAddress(true, true, false))},
+
 	// MAAS machine this LXD instance is associated with
 	"maas.machine": {},
 
@@ -0,0 +1,169 @@
+package oci
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// ImageProperties returns the image properties recording how to run an image, based on its config and
+// unpacked root filesystem (used to resolve user and group names).
+func ImageProperties(config ImageConfig, rootfs string) (map[string]string, error) {
+	command := append(append([]string{}, config.Config.Entrypoint...), config.Config.Cmd...)
+	if len(command) == 0 {
+		return nil, fmt.Errorf("The image doesn't specify a command to run")
+	}
+
+	entrypoint, err := quoteCommand(command)
+	if err != nil {
+		return nil, err
+	}
+
+	properties := map[string]string{
+		"oci.entrypoint": entrypoint,
+	}
+
+	if config.Config.WorkingDir != "" {
+		properties["oci.cwd"] = config.Config.WorkingDir
+	}
+
+	if len(config.Config.Env) > 0 {
+		properties["oci.env"] = strings.Join(config.Config.Env, "\n")
+	}
+
+	if config.Config.User != "" {
+		uid, gid, err := resolveUser(rootfs, config.Config.User)
+		if err != nil {
+			return nil, err
+		}
+
+		properties["oci.uid"] = strconv.FormatInt(uid, 10)
+		properties["oci.gid"] = strconv.FormatInt(gid, 10)
+	}
+
+	return properties, nil
+}
+
+// InstanceConfig returns the instance configuration needed to run an image, based on the properties
+// recorded by ImageProperties. Returns nil if the image isn't an OCI image.
+func InstanceConfig(properties map[string]string) map[string]string {
+	if properties["oci.entrypoint"] == "" {
+		return nil
+	}
+
+	config := map[string]string{}
+	for _, key := range []string{"oci.entrypoint", "oci.cwd", "oci.uid", "oci.gid"} {
+		if properties[key] != "" {
+			config[key] = properties[key]
+		}
+	}
+
+	for _, line := range strings.Split(properties["oci.env"], "\n") {
+		fields := strings.SplitN(line, "=", 2)
+		if len(fields) != 2 || fields[0] == "" {
+			continue
+		}
+
+		config[fmt.Sprintf("environment.%s", fields[0])] = fields[1]
+	}
+
+	return config
+}
+
+// quoteCommand joins the command arguments into a string which liblxc splits back into the same arguments.
+// liblxc honours single and double quotes but no escape sequences.
+func quoteCommand(args []string) (string, error) {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch {
+		case arg != "" && !strings.ContainsAny(arg, " \t\n'\""):
+			quoted = append(quoted, arg)
+		case !strings.Contains(arg, "'"):
+			quoted = append(quoted, fmt.Sprintf("'%s'", arg))
+		case !strings.Contains(arg, `"`):
+			quoted = append(quoted, fmt.Sprintf(`"%s"`, arg))
+		default:
+			return "", fmt.Errorf("Command argument %q can't contain both single and double quotes", arg)
+		}
+	}
+
+	return strings.Join(quoted, " "), nil
+}
+
+// resolveUser resolves an image user of the form "user[:group]", where both can be names or IDs, to a uid
+// and gid using the passwd and group files of the root filesystem.
+func resolveUser(rootfs string, user string) (int64, int64, error) {
+	fields := strings.SplitN(user, ":", 2)
+
+	uid, err := strconv.ParseInt(fields[0], 10, 32)
+	gid := int64(0)
+	if err != nil {
+		entry, err := lookupEntry(rootfs, "/etc/passwd", fields[0])
+		if err != nil {
+			return -1, -1, fmt.Errorf("Failed resolving user %q: %w", fields[0], err)
+		}
+
+		uid, err = strconv.ParseInt(entry[2], 10, 32)
+		if err != nil {
+			return -1, -1, fmt.Errorf("Invalid uid for user %q: %w", fields[0], err)
+		}
+
+		gid, err = strconv.ParseInt(entry[3], 10, 32)
+		if err != nil {
+			return -1, -1, fmt.Errorf("Invalid gid for user %q: %w", fields[0], err)
+		}
+	}
+
+	if len(fields) < 2 {
+		return uid, gid, nil
+	}
+
+	gid, err = strconv.ParseInt(fields[1], 10, 32)
+	if err != nil {
+		entry, err := lookupEntry(rootfs, "/etc/group", fields[1])
+		if err != nil {
+			return -1, -1, fmt.Errorf("Failed resolving group %q: %w", fields[1], err)
+		}
+
+		gid, err = strconv.ParseInt(entry[2], 10, 32)
+		if err != nil {
+			return -1, -1, fmt.Errorf("Invalid gid for group %q: %w", fields[1], err)
+		}
+	}
+
+	return uid, gid, nil
+}
+
+// lookupEntry returns the fields of the named entry in a passwd or group file of the root filesystem.
+func lookupEntry(rootfs string, path string, name string) ([]string, error) {
+	target, err := resolvePath(rootfs, path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(target, os.O_RDONLY|unix.O_NOFOLLOW, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) >= 4 && fields[0] == name {
+			return fields, nil
+		}
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("No entry found in %q", path)
+}
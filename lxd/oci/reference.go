@@ -0,0 +1,80 @@
+package oci
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// repositoryPattern matches the path component of an image name as defined by the distribution spec.
+var repositoryPattern = regexp.MustCompile(`^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*(/[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*)*$`)
+
+// tagPattern matches an image tag as defined by the distribution spec.
+var tagPattern = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9._-]{0,127}$`)
+
+// digestPattern matches the supported content digests.
+var digestPattern = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// Reference represents an image in a repository, either by tag or by digest.
+type Reference struct {
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseReference parses an image reference of the form "repository[:tag][@digest]".
+// The tag defaults to "latest" when neither a tag nor a digest is provided.
+func ParseReference(ref string) (*Reference, error) {
+	r := &Reference{}
+
+	name := ref
+	if strings.Contains(name, "@") {
+		fields := strings.SplitN(name, "@", 2)
+		name = fields[0]
+		r.Digest = fields[1]
+
+		if !digestPattern.MatchString(r.Digest) {
+			return nil, fmt.Errorf("Invalid digest %q in image reference %q", r.Digest, ref)
+		}
+	}
+
+	i := strings.LastIndex(name, ":")
+	if i > strings.LastIndex(name, "/") {
+		r.Tag = name[i+1:]
+		name = name[:i]
+
+		if !tagPattern.MatchString(r.Tag) {
+			return nil, fmt.Errorf("Invalid tag %q in image reference %q", r.Tag, ref)
+		}
+	}
+
+	if !repositoryPattern.MatchString(name) {
+		return nil, fmt.Errorf("Invalid repository %q in image reference %q", name, ref)
+	}
+
+	r.Repository = name
+
+	if r.Tag == "" && r.Digest == "" {
+		r.Tag = "latest"
+	}
+
+	return r, nil
+}
+
+// Name returns the tag or digest to request the image manifest with.
+func (r *Reference) Name() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+
+	return r.Tag
+}
+
+// String returns the reference in its "repository:tag" or "repository@digest" form.
+func (r *Reference) String() string {
+	if r.Digest != "" {
+		return fmt.Sprintf("%s@%s", r.Repository, r.Digest)
+	}
+
+	return fmt.Sprintf("%s:%s", r.Repository, r.Tag)
+}
@@ -0,0 +1,497 @@
+package oci
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/osarch"
+)
+
+// maxManifestSize is the largest manifest, index or config blob that will be read from a registry.
+const maxManifestSize = 4 * 1024 * 1024
+
+// manifestMediaTypes are the manifest and index media types accepted from a registry.
+var manifestMediaTypes = []string{MediaTypeImageIndex, MediaTypeDockerManifestList, MediaTypeImageManifest, MediaTypeDockerManifest}
+
+// Image is an image resolved for a single architecture.
+type Image struct {
+	Reference    *Reference
+	Digest       string // Digest of the image manifest.
+	Architecture int
+	Manifest     Manifest
+	Config       ImageConfig
+}
+
+// Registry is a client for the distribution API of an OCI or Docker image registry.
+type Registry struct {
+	baseURL    string
+	dockerHub  bool
+	httpClient *http.Client
+	userAgent  string
+
+	tokens   map[string]string
+	tokensMu sync.Mutex
+}
+
+// NewRegistry returns a client for the registry at the given URL.
+func NewRegistry(server string, httpClient *http.Client, userAgent string) (*Registry, error) {
+	u, err := url.Parse(server)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid registry URL %q: %w", server, err)
+	}
+
+	if !shared.StringInSlice(u.Scheme, []string{"http", "https"}) || u.Host == "" {
+		return nil, fmt.Errorf("Invalid registry URL %q", server)
+	}
+
+	r := &Registry{
+		httpClient: httpClient,
+		userAgent:  userAgent,
+		tokens:     map[string]string{},
+	}
+
+	// Docker Hub serves its registry API from a dedicated host and keeps official images under "library/".
+	if shared.StringInSlice(u.Host, []string{"docker.io", "index.docker.io", "registry-1.docker.io"}) {
+		u.Host = "registry-1.docker.io"
+		r.dockerHub = true
+	}
+
+	r.baseURL = fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+
+	return r, nil
+}
+
+// reference parses an image reference, expanding Docker Hub's official image names.
+func (r *Registry) reference(name string) (*Reference, error) {
+	ref, err := ParseReference(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.dockerHub && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = fmt.Sprintf("library/%s", ref.Repository)
+	}
+
+	return ref, nil
+}
+
+// GetImage resolves the image reference to the manifest and config of the image for the given architecture.
+func (r *Registry) GetImage(name string, architecture int) (*Image, error) {
+	ref, err := r.reference(name)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType, digest, content, err := r.getManifest(ref.Repository, ref.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	if shared.StringInSlice(mediaType, []string{MediaTypeImageIndex, MediaTypeDockerManifestList}) {
+		index := Index{}
+		err = json.Unmarshal(content, &index)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing image index of %q: %w", ref, err)
+		}
+
+		desc, err := indexManifest(index, architecture)
+		if err != nil {
+			return nil, fmt.Errorf("Failed resolving %q: %w", ref, err)
+		}
+
+		mediaType, digest, content, err = r.getManifest(ref.Repository, desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !shared.StringInSlice(mediaType, []string{MediaTypeImageManifest, MediaTypeDockerManifest}) {
+		return nil, fmt.Errorf("Unsupported manifest type %q for %q", mediaType, ref)
+	}
+
+	img := &Image{
+		Reference: ref,
+		Digest:    digest,
+	}
+
+	err = json.Unmarshal(content, &img.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing image manifest of %q: %w", ref, err)
+	}
+
+	err = r.getConfig(ref.Repository, img.Manifest.Config, &img.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if img.Config.OS != "linux" {
+		return nil, fmt.Errorf("Image %q is not a Linux image (%q)", ref, img.Config.OS)
+	}
+
+	img.Architecture, err = osarch.ArchitectureId(img.Config.Architecture)
+	if err != nil {
+		return nil, fmt.Errorf("Image %q has an unsupported architecture: %w", ref, err)
+	}
+
+	if img.Architecture != architecture {
+		archName, _ := osarch.ArchitectureName(architecture)
+		return nil, fmt.Errorf("Image %q is not available for architecture %q", ref, archName)
+	}
+
+	return img, nil
+}
+
+// GetArchitectures returns the architectures the image reference is available for.
+func (r *Registry) GetArchitectures(name string) ([]int, error) {
+	ref, err := r.reference(name)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType, _, content, err := r.getManifest(ref.Repository, ref.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	architectures := []int{}
+
+	if shared.StringInSlice(mediaType, []string{MediaTypeImageIndex, MediaTypeDockerManifestList}) {
+		index := Index{}
+		err = json.Unmarshal(content, &index)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing image index of %q: %w", ref, err)
+		}
+
+		for _, desc := range index.Manifests {
+			if desc.Platform == nil || desc.Platform.OS != "linux" {
+				continue
+			}
+
+			id, err := osarch.ArchitectureId(desc.Platform.Architecture)
+			if err != nil || shared.IntInSlice(id, architectures) {
+				continue
+			}
+
+			architectures = append(architectures, id)
+		}
+
+		return architectures, nil
+	}
+
+	manifest := Manifest{}
+	err = json.Unmarshal(content, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing image manifest of %q: %w", ref, err)
+	}
+
+	config := ImageConfig{}
+	err = r.getConfig(ref.Repository, manifest.Config, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := osarch.ArchitectureId(config.Architecture)
+	if err != nil {
+		return nil, fmt.Errorf("Image %q has an unsupported architecture: %w", ref, err)
+	}
+
+	return append(architectures, id), nil
+}
+
+// GetBlob returns a reader for the blob with the given digest. Reading returns an error on reaching the end
+// of the blob if its content doesn't match the digest.
+func (r *Registry) GetBlob(repository string, digest string) (io.ReadCloser, error) {
+	if !digestPattern.MatchString(digest) {
+		return nil, fmt.Errorf("Unsupported blob digest %q", digest)
+	}
+
+	resp, err := r.do(repository, fmt.Sprintf("/v2/%s/blobs/%s", repository, digest), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &digestReader{ReadCloser: resp.Body, digest: digest, hash: sha256.New()}, nil
+}
+
+// getManifest fetches a manifest or index by tag or digest, returning its media type, digest and content.
+func (r *Registry) getManifest(repository string, name string) (string, string, []byte, error) {
+	resp, err := r.do(repository, fmt.Sprintf("/v2/%s/manifests/%s", repository, name), manifestMediaTypes)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize+1))
+	if err != nil {
+		return "", "", nil, fmt.Errorf("Failed reading manifest %q of %q: %w", name, repository, err)
+	}
+
+	if len(content) > maxManifestSize {
+		return "", "", nil, fmt.Errorf("Manifest %q of %q is too large", name, repository)
+	}
+
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(content))
+	if strings.HasPrefix(name, "sha256:") && name != digest {
+		return "", "", nil, fmt.Errorf("Digest mismatch for manifest of %q: %s != %s", repository, digest, name)
+	}
+
+	// Prefer the media type embedded in the manifest over the one reported by the server.
+	fields := struct {
+		MediaType string `json:"mediaType"`
+	}{}
+
+	err = json.Unmarshal(content, &fields)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("Failed parsing manifest %q of %q: %w", name, repository, err)
+	}
+
+	mediaType := fields.MediaType
+	if mediaType == "" {
+		mediaType, _, _ = mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	}
+
+	return mediaType, digest, content, nil
+}
+
+// getConfig fetches and parses an image config blob.
+func (r *Registry) getConfig(repository string, desc Descriptor, config *ImageConfig) error {
+	if desc.Size > maxManifestSize {
+		return fmt.Errorf("Image config of %q is too large", repository)
+	}
+
+	blob, err := r.GetBlob(repository, desc.Digest)
+	if err != nil {
+		return err
+	}
+
+	defer blob.Close()
+
+	content, err := io.ReadAll(blob)
+	if err != nil {
+		return fmt.Errorf("Failed reading image config of %q: %w", repository, err)
+	}
+
+	err = json.Unmarshal(content, config)
+	if err != nil {
+		return fmt.Errorf("Failed parsing image config of %q: %w", repository, err)
+	}
+
+	return nil
+}
+
+// do performs a GET request against the registry API, authenticating for the repository if challenged to.
+func (r *Registry) do(repository string, path string, accept []string) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest("GET", r.baseURL+path, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("User-Agent", r.userAgent)
+
+		if len(accept) > 0 {
+			req.Header.Set("Accept", strings.Join(accept, ", "))
+		}
+
+		r.tokensMu.Lock()
+		token := r.tokens[repository]
+		r.tokensMu.Unlock()
+
+		if token != "" {
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		}
+
+		resp, err := r.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			challenge := resp.Header.Get("WWW-Authenticate")
+			resp.Body.Close()
+
+			err = r.authenticate(repository, challenge)
+			if err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, api.StatusErrorf(resp.StatusCode, "Failed fetching %q from registry: %s", path, resp.Status)
+		}
+
+		return resp, nil
+	}
+}
+
+// authenticate obtains an anonymous pull token for the repository from the token server named in the challenge.
+func (r *Registry) authenticate(repository string, challenge string) error {
+	scheme, params := parseChallenge(challenge)
+	if !strings.EqualFold(scheme, "bearer") {
+		return fmt.Errorf("Unsupported registry authentication scheme %q", scheme)
+	}
+
+	u, err := url.Parse(params["realm"])
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("Invalid registry authentication realm %q", params["realm"])
+	}
+
+	scope := params["scope"]
+	if scope == "" {
+		scope = fmt.Sprintf("repository:%s:pull", repository)
+	}
+
+	query := u.Query()
+	query.Set("scope", scope)
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("User-Agent", r.userAgent)
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Failed requesting registry token: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Failed requesting registry token: %s", resp.Status)
+	}
+
+	fields := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+
+	err = json.NewDecoder(io.LimitReader(resp.Body, maxManifestSize)).Decode(&fields)
+	if err != nil {
+		return fmt.Errorf("Failed parsing registry token: %w", err)
+	}
+
+	token := fields.Token
+	if token == "" {
+		token = fields.AccessToken
+	}
+
+	if token == "" {
+		return fmt.Errorf("Registry didn't return a token")
+	}
+
+	r.tokensMu.Lock()
+	r.tokens[repository] = token
+	r.tokensMu.Unlock()
+
+	return nil
+}
+
+// parseChallenge parses a WWW-Authenticate header into its scheme and parameters.
+func parseChallenge(challenge string) (string, map[string]string) {
+	params := map[string]string{}
+
+	fields := strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+	if len(fields) < 2 {
+		return fields[0], params
+	}
+
+	rest := fields[1]
+	for rest != "" {
+		rest = strings.TrimLeft(rest, ", ")
+
+		i := strings.Index(rest, "=")
+		if i < 0 {
+			break
+		}
+
+		key := strings.ToLower(strings.TrimSpace(rest[:i]))
+		rest = rest[i+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value = rest[1:]
+				rest = ""
+			} else {
+				value = rest[1 : end+1]
+				rest = rest[end+2:]
+			}
+		} else {
+			end := strings.Index(rest, ",")
+			if end < 0 {
+				end = len(rest)
+			}
+
+			value = strings.TrimSpace(rest[:end])
+			rest = rest[end:]
+		}
+
+		params[key] = value
+	}
+
+	return fields[0], params
+}
+
+// indexManifest returns the descriptor of the Linux manifest for the given architecture in an image index.
+func indexManifest(index Index, architecture int) (*Descriptor, error) {
+	for i, desc := range index.Manifests {
+		if desc.Platform == nil || desc.Platform.OS != "linux" {
+			continue
+		}
+
+		id, err := osarch.ArchitectureId(desc.Platform.Architecture)
+		if err != nil || id != architecture {
+			continue
+		}
+
+		return &index.Manifests[i], nil
+	}
+
+	archName, _ := osarch.ArchitectureName(architecture)
+	return nil, fmt.Errorf("No image available for architecture %q", archName)
+}
+
+// digestReader verifies the digest of the content read through it.
+type digestReader struct {
+	io.ReadCloser
+
+	digest string
+	hash   hash.Hash
+}
+
+// Read reads from the underlying reader, checking the digest once the end of the content is reached.
+func (r *digestReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+
+	if err == io.EOF {
+		digest := fmt.Sprintf("sha256:%x", r.hash.Sum(nil))
+		if digest != r.digest {
+			return n, fmt.Errorf("Digest mismatch: %s != %s", digest, r.digest)
+		}
+	}
+
+	return n, err
+}
@@ -0,0 +1,317 @@
+package oci_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/lxd/lxd/oci"
+	"github.com/lxc/lxd/shared/osarch"
+)
+
+// testRegistry is a minimal registry serving a single repository which requires a bearer token.
+type testRegistry struct {
+	repository string
+	manifests  map[string][]byte // By tag and digest.
+	blobs      map[string][]byte // By digest.
+}
+
+func newTestRegistry(repository string) *testRegistry {
+	return &testRegistry{
+		repository: repository,
+		manifests:  map[string][]byte{},
+		blobs:      map[string][]byte{},
+	}
+}
+
+func (r *testRegistry) addBlob(content []byte) oci.Descriptor {
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(content))
+	r.blobs[digest] = content
+
+	return oci.Descriptor{Digest: digest, Size: int64(len(content))}
+}
+
+func (r *testRegistry) addManifest(tag string, manifest any) oci.Descriptor {
+	content, _ := json.Marshal(manifest)
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(content))
+	r.manifests[digest] = content
+
+	if tag != "" {
+		r.manifests[tag] = content
+	}
+
+	return oci.Descriptor{Digest: digest, Size: int64(len(content))}
+}
+
+func (r *testRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		if req.URL.Query().Get("scope") != fmt.Sprintf("repository:%s:pull", r.repository) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		w.Write([]byte(`{"token": "secret"}`))
+		return
+	}
+
+	if req.Header.Get("Authorization") != "Bearer secret" {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="http://%s/token",service="test",scope="repository:%s:pull"`, req.Host, r.repository))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	prefix := fmt.Sprintf("/v2/%s/", r.repository)
+	if !strings.HasPrefix(req.URL.Path, prefix) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	fields := strings.SplitN(strings.TrimPrefix(req.URL.Path, prefix), "/", 2)
+
+	var content []byte
+	if fields[0] == "manifests" {
+		content = r.manifests[fields[1]]
+	} else if fields[0] == "blobs" {
+		content = r.blobs[fields[1]]
+	}
+
+	if content == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Write(content)
+}
+
+type testEntry struct {
+	name     string
+	typeflag byte
+	content  string
+	linkname string
+}
+
+func testLayer(t *testing.T, entries []testEntry) []byte {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+
+	for _, entry := range entries {
+		hdr := &tar.Header{
+			Name:     entry.name,
+			Typeflag: entry.typeflag,
+			Linkname: entry.linkname,
+			Mode:     0644,
+			Uid:      os.Getuid(),
+			Gid:      os.Getgid(),
+			Size:     int64(len(entry.content)),
+		}
+
+		if entry.typeflag == tar.TypeDir {
+			hdr.Mode = 0755
+		}
+
+		require.NoError(t, tw.WriteHeader(hdr))
+		_, err := tw.Write([]byte(entry.content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	return buf.Bytes()
+}
+
+func TestRegistry_GetImage(t *testing.T) {
+	reg := newTestRegistry("library/app")
+
+	layers := [][]byte{
+		testLayer(t, []testEntry{
+			{name: "etc/", typeflag: tar.TypeDir},
+			{name: "etc/passwd", typeflag: tar.TypeReg, content: "root:x:0:0::/root:/bin/sh\napp:x:1000:1001::/home/app:/bin/sh\n"},
+			{name: "etc/motd", typeflag: tar.TypeReg, content: "hello"},
+			{name: "opt/", typeflag: tar.TypeDir},
+			{name: "opt/old", typeflag: tar.TypeReg, content: "old"},
+			{name: "usr/", typeflag: tar.TypeDir},
+			{name: "usr/lib/", typeflag: tar.TypeDir},
+			{name: "lib", typeflag: tar.TypeSymlink, linkname: "usr/lib"},
+		}),
+		testLayer(t, []testEntry{
+			{name: "etc/.wh.motd", typeflag: tar.TypeReg},
+			{name: "opt/.wh..wh..opq", typeflag: tar.TypeReg},
+			{name: "opt/new", typeflag: tar.TypeReg, content: "new"},
+			{name: "lib/libapp.so", typeflag: tar.TypeReg, content: "library"},
+			{name: "lib/libapp.so.1", typeflag: tar.TypeLink, linkname: "lib/libapp.so"},
+		}),
+	}
+
+	config := oci.ImageConfig{
+		Architecture: "amd64",
+		OS:           "linux",
+		Config: oci.RuntimeConfig{
+			User:       "app",
+			Env:        []string{"PATH=/usr/bin:/bin", "GREETING=hello world"},
+			Entrypoint: []string{"/usr/bin/app"},
+			Cmd:        []string{"--greeting", "hello world"},
+			WorkingDir: "/opt",
+		},
+	}
+
+	configContent, err := json.Marshal(config)
+	require.NoError(t, err)
+
+	manifest := oci.Manifest{SchemaVersion: 2, MediaType: oci.MediaTypeImageManifest, Config: reg.addBlob(configContent)}
+	for _, layer := range layers {
+		desc := reg.addBlob(layer)
+		desc.MediaType = oci.MediaTypeImageLayerGzip
+		manifest.Layers = append(manifest.Layers, desc)
+	}
+
+	manifestDesc := reg.addManifest("", manifest)
+	manifestDesc.MediaType = oci.MediaTypeImageManifest
+	manifestDesc.Platform = &oci.Platform{Architecture: "amd64", OS: "linux"}
+
+	reg.addManifest("latest", oci.Index{
+		SchemaVersion: 2,
+		MediaType:     oci.MediaTypeImageIndex,
+		Manifests:     []oci.Descriptor{manifestDesc},
+	})
+
+	server := httptest.NewServer(reg)
+	defer server.Close()
+
+	registry, err := oci.NewRegistry(server.URL, server.Client(), "test")
+	require.NoError(t, err)
+
+	architectures, err := registry.GetArchitectures("library/app")
+	require.NoError(t, err)
+	assert.Equal(t, []int{osarch.ARCH_64BIT_INTEL_X86}, architectures)
+
+	_, err = registry.GetImage("library/app", osarch.ARCH_64BIT_ARMV8_LITTLE_ENDIAN)
+	assert.Error(t, err)
+
+	img, err := registry.GetImage("library/app", osarch.ARCH_64BIT_INTEL_X86)
+	require.NoError(t, err)
+	assert.Equal(t, manifestDesc.Digest, img.Digest)
+	assert.Equal(t, "library/app:latest", img.Reference.String())
+	assert.Len(t, img.Manifest.Layers, 2)
+
+	// Pulling by digest resolves to the same image.
+	img, err = registry.GetImage(fmt.Sprintf("library/app@%s", manifestDesc.Digest), osarch.ARCH_64BIT_INTEL_X86)
+	require.NoError(t, err)
+	assert.Equal(t, manifestDesc.Digest, img.Digest)
+
+	rootfs := t.TempDir()
+	for _, layer := range img.Manifest.Layers {
+		blob, err := registry.GetBlob(img.Reference.Repository, layer.Digest)
+		require.NoError(t, err)
+
+		err = oci.UnpackLayer(blob, rootfs)
+		require.NoError(t, err)
+
+		_, err = io.Copy(io.Discard, blob)
+		require.NoError(t, err)
+		blob.Close()
+	}
+
+	assert.NoFileExists(t, filepath.Join(rootfs, "etc", "motd"))
+	assert.NoFileExists(t, filepath.Join(rootfs, "opt", "old"))
+	assert.FileExists(t, filepath.Join(rootfs, "opt", "new"))
+	assert.FileExists(t, filepath.Join(rootfs, "usr", "lib", "libapp.so"))
+	assert.FileExists(t, filepath.Join(rootfs, "usr", "lib", "libapp.so.1"))
+
+	properties, err := oci.ImageProperties(img.Config, rootfs)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"oci.entrypoint": "/usr/bin/app --greeting 'hello world'",
+		"oci.cwd":        "/opt",
+		"oci.env":        "PATH=/usr/bin:/bin\nGREETING=hello world",
+		"oci.uid":        "1000",
+		"oci.gid":        "1001",
+	}, properties)
+
+	assert.Equal(t, map[string]string{
+		"oci.entrypoint":       "/usr/bin/app --greeting 'hello world'",
+		"oci.cwd":              "/opt",
+		"oci.uid":              "1000",
+		"oci.gid":              "1001",
+		"environment.PATH":     "/usr/bin:/bin",
+		"environment.GREETING": "hello world",
+	}, oci.InstanceConfig(properties))
+}
+
+func TestRegistry_GetBlobDigestMismatch(t *testing.T) {
+	reg := newTestRegistry("app")
+	desc := reg.addBlob([]byte("content"))
+	reg.blobs[desc.Digest] = []byte("tampered")
+
+	server := httptest.NewServer(reg)
+	defer server.Close()
+
+	registry, err := oci.NewRegistry(server.URL, server.Client(), "test")
+	require.NoError(t, err)
+
+	blob, err := registry.GetBlob("app", desc.Digest)
+	require.NoError(t, err)
+	defer blob.Close()
+
+	_, err = io.ReadAll(blob)
+	assert.Error(t, err)
+}
+
+func TestUnpackLayer_Escape(t *testing.T) {
+	dir := t.TempDir()
+	rootfs := filepath.Join(dir, "rootfs")
+	require.NoError(t, os.Mkdir(rootfs, 0755))
+
+	layer := testLayer(t, []testEntry{
+		{name: "escape", typeflag: tar.TypeSymlink, linkname: "../.."},
+		{name: "escape/file", typeflag: tar.TypeReg, content: "content"},
+		{name: "../file", typeflag: tar.TypeReg, content: "content"},
+	})
+
+	err := oci.UnpackLayer(bytes.NewReader(layer), rootfs)
+	require.NoError(t, err)
+
+	assert.NoFileExists(t, filepath.Join(dir, "file"))
+	assert.FileExists(t, filepath.Join(rootfs, "file"))
+}
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		ref    string
+		result string
+		err    bool
+	}{
+		{ref: "alpine", result: "alpine:latest"},
+		{ref: "alpine:3.18", result: "alpine:3.18"},
+		{ref: "user/app:v1.0", result: "user/app:v1.0"},
+		{ref: fmt.Sprintf("app@sha256:%s", strings.Repeat("a", 64)), result: fmt.Sprintf("app@sha256:%s", strings.Repeat("a", 64))},
+		{ref: "Alpine", err: true},
+		{ref: "app:", err: true},
+		{ref: "app@sha256:abc", err: true},
+	}
+
+	for _, test := range tests {
+		ref, err := oci.ParseReference(test.ref)
+		if test.err {
+			assert.Error(t, err, test.ref)
+			continue
+		}
+
+		require.NoError(t, err, test.ref)
+		assert.Equal(t, test.result, ref.String())
+	}
+}
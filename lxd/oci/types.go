@@ -0,0 +1,61 @@
+package oci
+
+// Media types of the manifests, indexes and layers understood by LXD.
+const (
+	MediaTypeImageIndex            = "application/vnd.oci.image.index.v1+json"
+	MediaTypeImageManifest         = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeDockerManifestList    = "application/vnd.docker.distribution.manifest.list.v2+json"
+	MediaTypeDockerManifest        = "application/vnd.docker.distribution.manifest.v2+json"
+	MediaTypeImageLayer            = "application/vnd.oci.image.layer.v1.tar"
+	MediaTypeImageLayerGzip        = "application/vnd.oci.image.layer.v1.tar+gzip"
+	MediaTypeImageLayerNonDistGzip = "application/vnd.oci.image.layer.nondistributable.v1.tar+gzip"
+	MediaTypeDockerLayerGzip       = "application/vnd.docker.image.rootfs.diff.tar.gzip"
+	MediaTypeDockerForeignLayer    = "application/vnd.docker.image.rootfs.foreign.diff.tar.gzip"
+)
+
+// Descriptor describes content stored in a registry.
+type Descriptor struct {
+	MediaType string    `json:"mediaType"`
+	Digest    string    `json:"digest"`
+	Size      int64     `json:"size"`
+	Platform  *Platform `json:"platform,omitempty"`
+}
+
+// Platform describes the platform an image manifest applies to.
+type Platform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// Index references the manifests of an image for each of its platforms.
+type Index struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	MediaType     string       `json:"mediaType,omitempty"`
+	Manifests     []Descriptor `json:"manifests"`
+}
+
+// Manifest references the config and layers of an image for a single platform.
+type Manifest struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	MediaType     string       `json:"mediaType,omitempty"`
+	Config        Descriptor   `json:"config"`
+	Layers        []Descriptor `json:"layers"`
+}
+
+// ImageConfig is the configuration blob of an image.
+type ImageConfig struct {
+	Created      string        `json:"created,omitempty"`
+	Architecture string        `json:"architecture"`
+	OS           string        `json:"os"`
+	Config       RuntimeConfig `json:"config"`
+}
+
+// RuntimeConfig holds the execution parameters of an image.
+type RuntimeConfig struct {
+	User       string   `json:"User,omitempty"`
+	Env        []string `json:"Env,omitempty"`
+	Entrypoint []string `json:"Entrypoint,omitempty"`
+	Cmd        []string `json:"Cmd,omitempty"`
+	WorkingDir string   `json:"WorkingDir,omitempty"`
+}
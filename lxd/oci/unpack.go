@@ -0,0 +1,317 @@
+package oci
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Whiteout markers used by layers to delete content from the layers below them.
+const (
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = ".wh..wh..opq"
+)
+
+// maxSymlinks is the maximum number of symlinks followed when resolving a path in the root filesystem.
+const maxSymlinks = 255
+
+// UnpackLayer applies a layer on top of the root filesystem at rootfs. Both gzip compressed and uncompressed
+// layers are supported.
+func UnpackLayer(r io.Reader, rootfs string) error {
+	br := bufio.NewReader(r)
+
+	magic, err := br.Peek(4)
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("Failed reading layer: %w", err)
+	}
+
+	var layer io.Reader = br
+	if bytes.HasPrefix(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("Failed decompressing layer: %w", err)
+		}
+
+		defer gz.Close()
+		layer = gz
+	} else if bytes.Equal(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}) {
+		return fmt.Errorf("Zstandard compressed layers aren't supported")
+	}
+
+	type dirTimes struct {
+		path  string
+		mtime time.Time
+	}
+
+	// Paths created by this layer, which opaque whiteouts must preserve.
+	created := map[string]bool{}
+
+	// Directory times are applied last as creating their content changes them.
+	dirs := []dirTimes{}
+
+	tr := tar.NewReader(layer)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return fmt.Errorf("Failed reading layer: %w", err)
+		}
+
+		name := path.Clean("/" + hdr.Name)
+		if name == "/" {
+			continue
+		}
+
+		dir, base := path.Split(name)
+
+		// Handle whiteouts.
+		if base == whiteoutOpaque {
+			err = removeChildren(rootfs, dir, created)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if strings.HasPrefix(base, whiteoutPrefix) {
+			target, err := resolvePath(rootfs, path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)))
+			if err != nil {
+				return err
+			}
+
+			err = os.RemoveAll(target)
+			if err != nil {
+				return fmt.Errorf("Failed applying whiteout %q: %w", name, err)
+			}
+
+			continue
+		}
+
+		target, err := resolvePath(rootfs, name)
+		if err != nil {
+			return err
+		}
+
+		// Replace any existing entry, unless both are directories.
+		fi, err := os.Lstat(target)
+		if err == nil && !(fi.IsDir() && hdr.Typeflag == tar.TypeDir) {
+			err = os.RemoveAll(target)
+			if err != nil {
+				return fmt.Errorf("Failed replacing %q: %w", name, err)
+			}
+		}
+
+		err = os.MkdirAll(filepath.Dir(target), 0755)
+		if err != nil {
+			return fmt.Errorf("Failed creating parent directory of %q: %w", name, err)
+		}
+
+		mode := hdr.FileInfo().Mode()
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = os.Mkdir(target, mode.Perm())
+			if err != nil && !os.IsExist(err) {
+				return fmt.Errorf("Failed creating directory %q: %w", name, err)
+			}
+
+			dirs = append(dirs, dirTimes{path: target, mtime: hdr.ModTime})
+		case tar.TypeReg, tar.TypeRegA:
+			err = unpackFile(tr, target, mode)
+			if err != nil {
+				return fmt.Errorf("Failed creating file %q: %w", name, err)
+			}
+
+		case tar.TypeSymlink:
+			err = os.Symlink(hdr.Linkname, target)
+			if err != nil {
+				return fmt.Errorf("Failed creating symlink %q: %w", name, err)
+			}
+
+		case tar.TypeLink:
+			source, err := resolvePath(rootfs, hdr.Linkname)
+			if err != nil {
+				return err
+			}
+
+			err = os.Link(source, target)
+			if err != nil {
+				return fmt.Errorf("Failed creating hardlink %q: %w", name, err)
+			}
+
+			// Hardlinks share their metadata with the source.
+			created[name] = true
+			continue
+		case tar.TypeChar, tar.TypeBlock, tar.TypeFifo:
+			devType := map[byte]uint32{tar.TypeChar: unix.S_IFCHR, tar.TypeBlock: unix.S_IFBLK, tar.TypeFifo: unix.S_IFIFO}[hdr.Typeflag]
+
+			err = unix.Mknod(target, devType|uint32(mode.Perm()), int(unix.Mkdev(uint32(hdr.Devmajor), uint32(hdr.Devminor))))
+			if err != nil {
+				return fmt.Errorf("Failed creating device %q: %w", name, err)
+			}
+
+		default:
+			continue
+		}
+
+		created[name] = true
+
+		err = os.Lchown(target, hdr.Uid, hdr.Gid)
+		if err != nil {
+			return fmt.Errorf("Failed setting ownership of %q: %w", name, err)
+		}
+
+		// Set the mode after the ownership as changing the owner clears the setuid and setgid bits.
+		if hdr.Typeflag != tar.TypeSymlink {
+			err = os.Chmod(target, mode)
+			if err != nil {
+				return fmt.Errorf("Failed setting mode of %q: %w", name, err)
+			}
+		}
+
+		for key, value := range hdr.PAXRecords {
+			if !strings.HasPrefix(key, "SCHILY.xattr.") {
+				continue
+			}
+
+			err = unix.Lsetxattr(target, strings.TrimPrefix(key, "SCHILY.xattr."), []byte(value), 0)
+			if err != nil && err != unix.ENOTSUP {
+				return fmt.Errorf("Failed setting extended attributes of %q: %w", name, err)
+			}
+		}
+
+		if hdr.Typeflag != tar.TypeDir {
+			err = setTimes(target, hdr.ModTime)
+			if err != nil {
+				return fmt.Errorf("Failed setting times of %q: %w", name, err)
+			}
+		}
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		err := setTimes(dirs[i].path, dirs[i].mtime)
+		if err != nil {
+			return fmt.Errorf("Failed setting times of %q: %w", dirs[i].path, err)
+		}
+	}
+
+	return nil
+}
+
+// unpackFile writes the content of a regular file.
+func unpackFile(r io.Reader, target string, mode os.FileMode) error {
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode.Perm())
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	_, err = io.Copy(f, r)
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// setTimes sets the access and modification times of a path without following symlinks.
+func setTimes(target string, mtime time.Time) error {
+	ts := unix.NsecToTimespec(mtime.UnixNano())
+	return unix.UtimesNanoAt(unix.AT_FDCWD, target, []unix.Timespec{ts, ts}, unix.AT_SYMLINK_NOFOLLOW)
+}
+
+// removeChildren applies an opaque whiteout, removing the content of a directory that wasn't created by the
+// current layer.
+func removeChildren(rootfs string, dir string, created map[string]bool) error {
+	target, err := resolvePath(rootfs, dir)
+	if err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(target)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return fmt.Errorf("Failed applying opaque whiteout to %q: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		if created[path.Join(dir, entry.Name())] {
+			continue
+		}
+
+		err = os.RemoveAll(filepath.Join(target, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("Failed applying opaque whiteout to %q: %w", dir, err)
+		}
+	}
+
+	return nil
+}
+
+// resolvePath returns the location of name inside rootfs. Symlinks in the parent directories of name are
+// followed as if rootfs was the root directory, so the result can't point outside of it.
+func resolvePath(rootfs string, name string) (string, error) {
+	dir, base := path.Split(path.Clean("/" + name))
+
+	current := "/"
+	pending := strings.Split(dir, "/")
+	links := 0
+
+	for len(pending) > 0 {
+		component := pending[0]
+		pending = pending[1:]
+
+		if component == "" || component == "." {
+			continue
+		}
+
+		if component == ".." {
+			current = path.Dir(current)
+			continue
+		}
+
+		next := path.Join(current, component)
+
+		fi, err := os.Lstat(filepath.Join(rootfs, next))
+		if err != nil || fi.Mode()&os.ModeSymlink == 0 {
+			current = next
+			continue
+		}
+
+		links++
+		if links > maxSymlinks {
+			return "", fmt.Errorf("Too many levels of symbolic links resolving %q", name)
+		}
+
+		link, err := os.Readlink(filepath.Join(rootfs, next))
+		if err != nil {
+			return "", err
+		}
+
+		if path.IsAbs(link) {
+			current = "/"
+		}
+
+		pending = append(strings.Split(link, "/"), pending...)
+	}
+
+	return filepath.Join(rootfs, current, base), nil
+}
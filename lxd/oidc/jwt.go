@@ -0,0 +1,259 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// jwtHeader represents the JOSE header of a JSON Web Token.
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	Type      string `json:"typ"`
+}
+
+// Claims represents the claims of a JSON Web Token that are relevant to LXD.
+type Claims struct {
+	Issuer          string   `json:"iss"`
+	Subject         string   `json:"sub"`
+	Audience        audience `json:"aud"`
+	AuthorizedParty string   `json:"azp"`
+	Email           string   `json:"email"`
+	ExpiresAt       int64    `json:"exp"`
+	NotBefore       int64    `json:"nbf"`
+	IssuedAt        int64    `json:"iat"`
+}
+
+// audience represents the "aud" claim which can either be a single string or a list of strings.
+type audience []string
+
+// UnmarshalJSON implements json.Unmarshaler for the audience claim.
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	err := json.Unmarshal(data, &single)
+	if err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var multiple []string
+	err = json.Unmarshal(data, &multiple)
+	if err != nil {
+		return fmt.Errorf("Invalid audience claim: %w", err)
+	}
+
+	*a = multiple
+	return nil
+}
+
+// contains returns whether the audience contains the value.
+func (a audience) contains(value string) bool {
+	for _, v := range a {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// jsonWebKey represents a public key in a JSON Web Key Set.
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+
+	// RSA keys.
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// EC keys.
+	Curve string `json:"crv"`
+	X     string `json:"x"`
+	Y     string `json:"y"`
+}
+
+// publicKey converts the JSON Web Key to a public key.
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		if !e.IsInt64() || e.Int64() > int64(^uint32(0)>>1) {
+			return nil, fmt.Errorf("Invalid RSA exponent")
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("Unsupported elliptic curve %q", k.Curve)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("Invalid elliptic curve point")
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+
+	return nil, fmt.Errorf("Unsupported key type %q", k.KeyType)
+}
+
+// decodeBigInt decodes a base64url encoded big-endian integer.
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid key parameter: %w", err)
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
+
+// parsedToken represents a JSON Web Token that has been decoded but not yet verified.
+type parsedToken struct {
+	header    jwtHeader
+	claims    Claims
+	signed    string
+	signature []byte
+}
+
+// parseToken decodes a compact serialized JSON Web Token.
+func parseToken(token string) (*parsedToken, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("Malformed token")
+	}
+
+	t := &parsedToken{signed: parts[0] + "." + parts[1]}
+
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("Malformed token header: %w", err)
+	}
+
+	err = json.Unmarshal(header, &t.header)
+	if err != nil {
+		return nil, fmt.Errorf("Malformed token header: %w", err)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("Malformed token payload: %w", err)
+	}
+
+	err = json.Unmarshal(payload, &t.claims)
+	if err != nil {
+		return nil, fmt.Errorf("Malformed token payload: %w", err)
+	}
+
+	t.signature, err = base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("Malformed token signature: %w", err)
+	}
+
+	return t, nil
+}
+
+// verifySignature checks the token signature against the public key.
+func (t *parsedToken) verifySignature(key crypto.PublicKey) error {
+	var hash crypto.Hash
+	switch t.header.Algorithm {
+	case "RS256", "PS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "PS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "PS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("Unsupported signing algorithm %q", t.header.Algorithm)
+	}
+
+	h := hash.New()
+	_, _ = h.Write([]byte(t.signed))
+	digest := h.Sum(nil)
+
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		switch t.header.Algorithm[:2] {
+		case "RS":
+			return rsa.VerifyPKCS1v15(pub, hash, digest, t.signature)
+		case "PS":
+			return rsa.VerifyPSS(pub, hash, digest, t.signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+	case *ecdsa.PublicKey:
+		if t.header.Algorithm[:2] != "ES" {
+			break
+		}
+
+		// ECDSA signatures are the concatenation of the fixed size R and S values.
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(t.signature) != 2*size {
+			return fmt.Errorf("Invalid signature length")
+		}
+
+		r := new(big.Int).SetBytes(t.signature[:size])
+		s := new(big.Int).SetBytes(t.signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return fmt.Errorf("Invalid signature")
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("Signing algorithm %q doesn't match the key type", t.header.Algorithm)
+}
+
+// verifyClaims checks the time based claims of the token.
+func (c *Claims) verifyClaims(now time.Time, leeway time.Duration) error {
+	if c.ExpiresAt == 0 {
+		return fmt.Errorf("Token has no expiry")
+	}
+
+	if now.After(time.Unix(c.ExpiresAt, 0).Add(leeway)) {
+		return fmt.Errorf("Token has expired")
+	}
+
+	if c.NotBefore != 0 && now.Add(leeway).Before(time.Unix(c.NotBefore, 0)) {
+		return fmt.Errorf("Token is not valid yet")
+	}
+
+	if c.IssuedAt != 0 && now.Add(leeway).Before(time.Unix(c.IssuedAt, 0)) {
+		return fmt.Errorf("Token was issued in the future")
+	}
+
+	return nil
+}
@@ -0,0 +1,275 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// clockSkew is the allowed difference between the clocks of LXD and the issuer.
+const clockSkew = time.Minute
+
+// keysRefreshInterval is the minimum time between two fetches of the issuer's keys.
+const keysRefreshInterval = time.Minute
+
+// Verifier validates bearer tokens issued by an OpenID Connect provider.
+type Verifier struct {
+	issuer   string
+	clientID string
+	audience string
+
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	jwksURI     string
+	keys        map[string]crypto.PublicKey
+	keysFetched time.Time
+}
+
+// providerConfig represents the relevant fields of the OpenID Connect discovery document.
+type providerConfig struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+// NewVerifier returns a Verifier for tokens issued by the issuer to the client ID.
+// If audience is not empty then tokens must be issued for that audience, otherwise they must be issued for the
+// client ID. If httpClient is nil then http.DefaultClient is used to contact the issuer.
+func NewVerifier(issuer string, clientID string, audience string, httpClient *http.Client) (*Verifier, error) {
+	if issuer == "" {
+		return nil, fmt.Errorf("Missing OpenID Connect issuer")
+	}
+
+	if clientID == "" {
+		return nil, fmt.Errorf("Missing OpenID Connect client ID")
+	}
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Verifier{
+		issuer:     strings.TrimSuffix(issuer, "/"),
+		clientID:   clientID,
+		audience:   audience,
+		httpClient: httpClient,
+	}, nil
+}
+
+// Issuer returns the issuer URL.
+func (v *Verifier) Issuer() string {
+	return v.issuer
+}
+
+// ClientID returns the client ID.
+func (v *Verifier) ClientID() string {
+	return v.clientID
+}
+
+// Audience returns the expected audience.
+func (v *Verifier) Audience() string {
+	return v.audience
+}
+
+// IsRequest returns whether the request carries a bearer token.
+func IsRequest(r *http.Request) bool {
+	_, ok := bearerToken(r)
+	return ok
+}
+
+// bearerToken extracts the bearer token from the Authorization header.
+func bearerToken(r *http.Request) (string, bool) {
+	auth := r.Header.Get("Authorization")
+
+	prefix := "Bearer "
+	if len(auth) <= len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return "", false
+	}
+
+	return strings.TrimSpace(auth[len(prefix):]), true
+}
+
+// Auth validates the bearer token of the request and returns the identity of the user.
+func (v *Verifier) Auth(ctx context.Context, r *http.Request) (string, error) {
+	token, ok := bearerToken(r)
+	if !ok {
+		return "", fmt.Errorf("Missing bearer token")
+	}
+
+	claims, err := v.VerifyToken(ctx, token)
+	if err != nil {
+		return "", err
+	}
+
+	return claims.Subject, nil
+}
+
+// VerifyToken validates the token and returns its claims.
+func (v *Verifier) VerifyToken(ctx context.Context, token string) (*Claims, error) {
+	t, err := parseToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSuffix(t.claims.Issuer, "/") != v.issuer {
+		return nil, fmt.Errorf("Token issued by unexpected issuer %q", t.claims.Issuer)
+	}
+
+	if v.audience != "" {
+		if !t.claims.Audience.contains(v.audience) {
+			return nil, fmt.Errorf("Token not issued for audience %q", v.audience)
+		}
+	} else if !t.claims.Audience.contains(v.clientID) && t.claims.AuthorizedParty != v.clientID {
+		return nil, fmt.Errorf("Token not issued for client %q", v.clientID)
+	}
+
+	err = t.claims.verifyClaims(time.Now(), clockSkew)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.claims.Subject == "" {
+		return nil, fmt.Errorf("Token has no subject")
+	}
+
+	key, err := v.key(ctx, t.header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = t.verifySignature(key)
+	if err != nil {
+		return nil, fmt.Errorf("Failed verifying token signature: %w", err)
+	}
+
+	return &t.claims, nil
+}
+
+// key returns the issuer's public key with the given key ID, refreshing the key set if the key is unknown.
+func (v *Verifier) key(ctx context.Context, keyID string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	key := v.findKey(keyID)
+	if key != nil {
+		return key, nil
+	}
+
+	// Avoid hammering the issuer with requests for tokens signed by unknown keys.
+	if v.keys != nil && time.Since(v.keysFetched) < keysRefreshInterval {
+		return nil, fmt.Errorf("Token signed with unknown key %q", keyID)
+	}
+
+	err := v.fetchKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key = v.findKey(keyID)
+	if key == nil {
+		return nil, fmt.Errorf("Token signed with unknown key %q", keyID)
+	}
+
+	return key, nil
+}
+
+// findKey returns the cached key with the given key ID. If the key ID is empty and there is a single key then
+// that key is returned.
+func (v *Verifier) findKey(keyID string) crypto.PublicKey {
+	if keyID == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key
+		}
+	}
+
+	return v.keys[keyID]
+}
+
+// fetchKeys retrieves the issuer's JSON Web Key Set.
+func (v *Verifier) fetchKeys(ctx context.Context) error {
+	if v.jwksURI == "" {
+		config, err := discover(ctx, v.httpClient, v.issuer)
+		if err != nil {
+			return err
+		}
+
+		if config.JWKSURI == "" {
+			return fmt.Errorf("OpenID Connect issuer doesn't provide a key set")
+		}
+
+		v.jwksURI = config.JWKSURI
+	}
+
+	keySet := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+
+	err := getJSON(ctx, v.httpClient, v.jwksURI, &keySet)
+	if err != nil {
+		return fmt.Errorf("Failed fetching OpenID Connect keys: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(keySet.Keys))
+	for _, k := range keySet.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			// Skip keys we don't understand rather than failing on all of them.
+			continue
+		}
+
+		keys[k.KeyID] = key
+	}
+
+	v.keys = keys
+	v.keysFetched = time.Now()
+
+	return nil
+}
+
+// discover retrieves the OpenID Connect discovery document of the issuer.
+func discover(ctx context.Context, httpClient *http.Client, issuer string) (*providerConfig, error) {
+	config := &providerConfig{}
+
+	err := getJSON(ctx, httpClient, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", config)
+	if err != nil {
+		return nil, fmt.Errorf("Failed fetching OpenID Connect configuration: %w", err)
+	}
+
+	if strings.TrimSuffix(config.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+		return nil, fmt.Errorf("OpenID Connect configuration is for unexpected issuer %q", config.Issuer)
+	}
+
+	return config, nil
+}
+
+// getJSON performs a GET request and decodes the JSON response into target.
+func getJSON(ctx context.Context, httpClient *http.Client, url string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Unexpected response status %q", resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(target)
+}
@@ -0,0 +1,226 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/lxd/lxd/oidc"
+)
+
+// mockIssuer is a minimal OpenID Connect provider serving a discovery document and a key set.
+type mockIssuer struct {
+	server   *httptest.Server
+	rsaKey   *rsa.PrivateKey
+	ecKey    *ecdsa.PrivateKey
+	keyFetch int
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	m := &mockIssuer{rsaKey: rsaKey, ecKey: ecKey}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   m.server.URL,
+			"jwks_uri": m.server.URL + "/keys",
+		})
+	})
+
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		m.keyFetch++
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{
+				{
+					"kty": "RSA",
+					"kid": "rsa1",
+					"use": "sig",
+					"n":   b64(rsaKey.N.Bytes()),
+					"e":   b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+				},
+				{
+					"kty": "EC",
+					"kid": "ec1",
+					"crv": "P-256",
+					"x":   b64(ecKey.X.FillBytes(make([]byte, 32))),
+					"y":   b64(ecKey.Y.FillBytes(make([]byte, 32))),
+				},
+			},
+		})
+	})
+
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+
+	return m
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// token returns a token signed with the issuer's key using the algorithm.
+func (m *mockIssuer) token(t *testing.T, alg string, kid string, claims map[string]any) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch alg {
+	case "RS256":
+		signature, err = rsa.SignPKCS1v15(rand.Reader, m.rsaKey, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, m.ecKey, digest[:])
+		require.NoError(t, err)
+
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	return signed + "." + b64(signature)
+}
+
+// claims returns a valid set of claims for the issuer.
+func (m *mockIssuer) claims() map[string]any {
+	return map[string]any{
+		"iss": m.server.URL,
+		"sub": "user1",
+		"aud": []string{"lxd-client", "other"},
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"iat": time.Now().Unix(),
+	}
+}
+
+func TestVerifier_VerifyToken(t *testing.T) {
+	issuer := newMockIssuer(t)
+
+	v, err := oidc.NewVerifier(issuer.server.URL, "lxd-client", "", nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	// Valid tokens signed with both key types.
+	for _, alg := range []string{"RS256", "ES256"} {
+		kid := "rsa1"
+		if alg == "ES256" {
+			kid = "ec1"
+		}
+
+		claims, err := v.VerifyToken(ctx, issuer.token(t, alg, kid, issuer.claims()))
+		require.NoError(t, err, alg)
+		assert.Equal(t, "user1", claims.Subject)
+	}
+
+	// The key set is cached.
+	assert.Equal(t, 1, issuer.keyFetch)
+
+	cases := map[string]func(claims map[string]any) string{
+		"wrong issuer": func(claims map[string]any) string {
+			claims["iss"] = "https://example.com"
+			return issuer.token(t, "RS256", "rsa1", claims)
+		},
+		"wrong audience": func(claims map[string]any) string {
+			claims["aud"] = "other"
+			return issuer.token(t, "RS256", "rsa1", claims)
+		},
+		"expired": func(claims map[string]any) string {
+			claims["exp"] = time.Now().Add(-time.Hour).Unix()
+			return issuer.token(t, "RS256", "rsa1", claims)
+		},
+		"not yet valid": func(claims map[string]any) string {
+			claims["nbf"] = time.Now().Add(time.Hour).Unix()
+			return issuer.token(t, "RS256", "rsa1", claims)
+		},
+		"no subject": func(claims map[string]any) string {
+			delete(claims, "sub")
+			return issuer.token(t, "RS256", "rsa1", claims)
+		},
+		"key type mismatch": func(claims map[string]any) string {
+			return issuer.token(t, "RS256", "ec1", claims)
+		},
+		"unsigned": func(claims map[string]any) string {
+			return issuer.token(t, "none", "rsa1", claims)
+		},
+		"tampered": func(claims map[string]any) string {
+			parts := strings.Split(issuer.token(t, "RS256", "rsa1", claims), ".")
+			claims["sub"] = "admin"
+			payload, err := json.Marshal(claims)
+			require.NoError(t, err)
+
+			return parts[0] + "." + b64(payload) + "." + parts[2]
+		},
+		"malformed": func(claims map[string]any) string {
+			return "not-a-token"
+		},
+	}
+
+	for name, tokenFunc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := v.VerifyToken(ctx, tokenFunc(issuer.claims()))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestVerifier_Audience(t *testing.T) {
+	issuer := newMockIssuer(t)
+
+	v, err := oidc.NewVerifier(issuer.server.URL, "lxd-client", "https://lxd.example.com", nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	// Tokens for the client but not the audience are rejected.
+	_, err = v.VerifyToken(ctx, issuer.token(t, "RS256", "rsa1", issuer.claims()))
+	assert.Error(t, err)
+
+	// Tokens for the audience are accepted.
+	claims := issuer.claims()
+	claims["aud"] = "https://lxd.example.com"
+	_, err = v.VerifyToken(ctx, issuer.token(t, "RS256", "rsa1", claims))
+	assert.NoError(t, err)
+}
+
+func TestVerifier_Auth(t *testing.T) {
+	issuer := newMockIssuer(t)
+
+	v, err := oidc.NewVerifier(issuer.server.URL, "lxd-client", "", nil)
+	require.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodGet, "/1.0", nil)
+	assert.False(t, oidc.IsRequest(r))
+
+	r.Header.Set("Authorization", "Bearer "+issuer.token(t, "ES256", "ec1", issuer.claims()))
+	assert.True(t, oidc.IsRequest(r))
+
+	username, err := v.Auth(context.Background(), r)
+	require.NoError(t, err)
+	assert.Equal(t, "user1", username)
+}
@@ -32,3 +32,94 @@ func UserHasPermission(r *http.Request, project string, permission string) bool
 
 	return shared.StringInSlice(permission, ua.Projects[project])
 }
+
+// UserHasInstancePermission checks whether the requestor has a specific permission on an instance, either
+// through its project or on the instance itself.
+func UserHasInstancePermission(r *http.Request, project string, instanceName string, permission string) bool {
+	val := r.Context().Value(request.CtxAccess)
+	if val == nil {
+		return false
+	}
+
+	ua := val.(*UserAccess)
+	if ua.Admin || shared.StringInSlice(permission, ua.Projects[project]) {
+		return true
+	}
+
+	return shared.StringInSlice(permission, ua.Instances[project][instanceName])
+}
+
+// UserHasNetworkPermission checks whether the requestor has a specific permission on a network, either through
+// its project or on the network itself.
+func UserHasNetworkPermission(r *http.Request, project string, networkName string, permission string) bool {
+	val := r.Context().Value(request.CtxAccess)
+	if val == nil {
+		return false
+	}
+
+	ua := val.(*UserAccess)
+	if ua.Admin || shared.StringInSlice(permission, ua.Projects[project]) {
+		return true
+	}
+
+	return shared.StringInSlice(permission, ua.Networks[project][networkName])
+}
+
+// UserHasStoragePoolPermission checks whether the requestor has a specific permission on a storage pool.
+func UserHasStoragePoolPermission(r *http.Request, poolName string, permission string) bool {
+	val := r.Context().Value(request.CtxAccess)
+	if val == nil {
+		return false
+	}
+
+	ua := val.(*UserAccess)
+	if ua.Admin {
+		return true
+	}
+
+	return shared.StringInSlice(permission, ua.StoragePools[poolName])
+}
+
+// UserHasAnyInstancePermission checks whether the requestor has a specific permission on a project or on any
+// of the instances in it.
+func UserHasAnyInstancePermission(r *http.Request, project string, permission string) bool {
+	if UserHasPermission(r, project, permission) {
+		return true
+	}
+
+	val := r.Context().Value(request.CtxAccess)
+	if val == nil {
+		return false
+	}
+
+	ua := val.(*UserAccess)
+	for _, permissions := range ua.Instances[project] {
+		if shared.StringInSlice(permission, permissions) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UserHasAnyNetworkPermission checks whether the requestor has a specific permission on a project or on any of
+// the networks in it.
+func UserHasAnyNetworkPermission(r *http.Request, project string, permission string) bool {
+	if UserHasPermission(r, project, permission) {
+		return true
+	}
+
+	val := r.Context().Value(request.CtxAccess)
+	if val == nil {
+		return false
+	}
+
+	ua := val.(*UserAccess)
+	for _, permissions := range ua.Networks[project] {
+		if shared.StringInSlice(permission, permissions) {
+			return true
+		}
+	}
+
+	return false
+}
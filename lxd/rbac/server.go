@@ -49,6 +49,12 @@ var errUnknownUser = fmt.Errorf("Unknown RBAC user")
 type UserAccess struct {
 	Admin    bool
 	Projects map[string][]string
+
+	// Permissions on individual entities, keyed by project and entity name for instances and networks and by
+	// name for storage pools.
+	Instances    map[string]map[string][]string
+	Networks     map[string]map[string][]string
+	StoragePools map[string][]string
 }
 
 // Server represents an RBAC server.
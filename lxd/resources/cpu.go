@@ -2,6 +2,7 @@ package resources
 
 import (
 	"bufio"
+	"encoding/binary"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -17,6 +18,8 @@ import (
 )
 
 var sysDevicesCPU = "/sys/devices/system/cpu"
+var sysModule = "/sys/module"
+var devCPUID = "/dev/cpu/0/cpuid"
 
 // GetCPUIsolated returns a slice of IDs corresponding to isolated threads.
 func GetCPUIsolated() []int64 {
@@ -458,6 +461,75 @@ func GetCPU() (*api.ResourcesCPU, error) {
 	}
 
 	cpu.Architecture = strings.TrimRight(string(uname.Machine[:]), "\x00")
+	cpu.MemoryEncryption = GetCPUMemoryEncryption()
 
 	return &cpu, nil
 }
+
+// moduleParamEnabled returns whether a boolean parameter of a loaded kernel module is enabled.
+func moduleParamEnabled(module string, param string) bool {
+	content, err := ioutil.ReadFile(filepath.Join(sysModule, module, "parameters", param))
+	if err != nil {
+		return false
+	}
+
+	value := strings.TrimSpace(string(content))
+
+	return value == "Y" || value == "1"
+}
+
+// GetCPUMemoryEncryption returns the guest memory encryption technologies that KVM supports on the host.
+// These are "sev" and "sev-es" for AMD SEV and SEV-ES, and "tdx" for Intel TDX.
+func GetCPUMemoryEncryption() []string {
+	encryption := []string{}
+
+	if moduleParamEnabled("kvm_amd", "sev") && sysfsExists("/dev/sev") {
+		encryption = append(encryption, "sev")
+
+		if moduleParamEnabled("kvm_amd", "sev_es") {
+			encryption = append(encryption, "sev-es")
+		}
+	}
+
+	if moduleParamEnabled("kvm_intel", "tdx") {
+		encryption = append(encryption, "tdx")
+	}
+
+	return encryption
+}
+
+// SEVCapabilities represents the AMD SEV parameters of the host CPU.
+type SEVCapabilities struct {
+	CBitPos         uint32 // Position of the page table bit marking encrypted guest pages.
+	ReducedPhysBits uint32 // Number of physical address bits lost when memory encryption is enabled.
+}
+
+// GetSEVCapabilities returns the AMD SEV parameters of the host CPU, read from CPUID leaf 0x8000001f through
+// the cpuid kernel module.
+func GetSEVCapabilities() (*SEVCapabilities, error) {
+	f, err := os.Open(devCPUID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed opening %q (is the cpuid kernel module loaded?): %w", devCPUID, err)
+	}
+
+	defer f.Close()
+
+	// The offset selects the leaf, with the EAX, EBX, ECX and EDX registers being returned.
+	regs := make([]byte, 16)
+	_, err = f.ReadAt(regs, 0x8000001f)
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading CPUID leaf 0x8000001f: %w", err)
+	}
+
+	eax := binary.LittleEndian.Uint32(regs[0:4])
+	if !hasBit(eax, 1) {
+		return nil, fmt.Errorf("CPU doesn't support AMD SEV")
+	}
+
+	ebx := binary.LittleEndian.Uint32(regs[4:8])
+
+	return &SEVCapabilities{
+		CBitPos:         ebx & 0x3f,
+		ReducedPhysBits: (ebx >> 6) & 0x3f,
+	}, nil
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/lxc/lxd/lxd/cluster"
+	"github.com/lxc/lxd/lxd/db"
 	"github.com/lxc/lxd/lxd/instance/instancetype"
 	"github.com/lxc/lxd/lxd/response"
 )
@@ -76,3 +77,32 @@ func forwardedResponseIfVolumeIsRemote(d *Daemon, r *http.Request, poolName stri
 
 	return response.ForwardedResponse(client, r)
 }
+
+// forwardedResponseIfBucketIsRemote redirects a request to the node hosting the storage bucket with the given
+// pool ID, project and name. If the bucket is local or is on a remote storage pool, nothing gets done and nil is
+// returned. If more than one node has a matching bucket, an error is returned.
+//
+// This is used when no targetNode is specified, and saves users some typing when the bucket name is unique to a
+// node.
+func forwardedResponseIfBucketIsRemote(d *Daemon, r *http.Request, poolID int64, projectName string, bucketName string) response.Response {
+	if queryParam(r, "target") != "" {
+		return nil
+	}
+
+	var bucket *db.StorageBucket
+	err := d.cluster.Transaction(func(tx *db.ClusterTx) error {
+		var err error
+		bucket, err = tx.GetStoragePoolBucket(poolID, projectName, false, bucketName)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Buckets on remote storage pools are not associated with a member.
+	if bucket.Location == "" {
+		return nil
+	}
+
+	return forwardedResponseToNode(d, r, bucket.Location)
+}
@@ -0,0 +1,229 @@
+package scriptlet
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.starlark.net/starlark"
+
+	"github.com/lxc/lxd/lxd/cluster"
+	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/resources"
+	"github.com/lxc/lxd/lxd/scriptlet/load"
+	"github.com/lxc/lxd/lxd/state"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/logger"
+)
+
+// InstancePlacementReasonNew is used when placing a new instance.
+const InstancePlacementReasonNew = "new"
+
+// instancePlacementMaxSteps is the maximum number of execution steps allowed for the instance placement scriptlet.
+const instancePlacementMaxSteps = 10000000
+
+// InstancePlacement is the request passed to the instance placement scriptlet.
+type InstancePlacement struct {
+	api.InstancesPost
+
+	// Project the instance is being created in
+	Project string `json:"project"`
+
+	// Why the instance is being placed
+	Reason string `json:"reason"`
+}
+
+// InstancePlacementRun runs the instance placement scriptlet and returns the cluster member it chose.
+// Returns nil if the scriptlet didn't choose a member, and an error if the scriptlet rejected the request.
+func InstancePlacementRun(ctx context.Context, l logger.Logger, s *state.State, src string, req *InstancePlacement, candidateMembers []db.NodeInfo, leaderAddress string) (*db.NodeInfo, error) {
+	prog, err := load.InstancePlacementCompile(src)
+	if err != nil {
+		return nil, fmt.Errorf("Failed compiling instance placement scriptlet: %w", err)
+	}
+
+	logFunc := func(level string) *starlark.Builtin {
+		return starlark.NewBuiltin(fmt.Sprintf("log_%s", level), func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var sb strings.Builder
+			for _, arg := range args {
+				str, ok := starlark.AsString(arg)
+				if !ok {
+					str = arg.String()
+				}
+
+				sb.WriteString(str)
+			}
+
+			switch level {
+			case "error":
+				l.Error(fmt.Sprintf("Instance placement scriptlet: %s", sb.String()))
+			case "warn":
+				l.Warn(fmt.Sprintf("Instance placement scriptlet: %s", sb.String()))
+			default:
+				l.Info(fmt.Sprintf("Instance placement scriptlet: %s", sb.String()))
+			}
+
+			return starlark.None, nil
+		})
+	}
+
+	// candidateMember returns the candidate member named by the builtin's member_name argument.
+	candidateMember := func(b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (*db.NodeInfo, error) {
+		var memberName string
+
+		err := starlark.UnpackArgs(b.Name(), args, kwargs, "member_name", &memberName)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range candidateMembers {
+			if candidateMembers[i].Name == memberName {
+				return &candidateMembers[i], nil
+			}
+		}
+
+		return nil, fmt.Errorf("Cluster member %q isn't a candidate", memberName)
+	}
+
+	var targetMember *db.NodeInfo
+
+	setTarget := starlark.NewBuiltin("set_target", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		member, err := candidateMember(b, args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+
+		targetMember = member
+		l.Debug("Instance placement scriptlet set target", logger.Ctx{"member": member.Name})
+
+		return starlark.None, nil
+	})
+
+	getClusterMemberResources := starlark.NewBuiltin("get_cluster_member_resources", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		member, err := candidateMember(b, args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+
+		var localMemberName string
+		err = s.Cluster.Transaction(func(tx *db.ClusterTx) error {
+			localMemberName, err = tx.GetLocalNodeName()
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var res *api.Resources
+		if member.Name == localMemberName {
+			res, err = resources.GetResources()
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			client, err := cluster.Connect(member.Address, s.Endpoints.NetworkCert(), s.ServerCert(), nil, true)
+			if err != nil {
+				return nil, err
+			}
+
+			res, err = client.GetServerResources()
+			if err != nil {
+				return nil, fmt.Errorf("Failed getting resources of cluster member %q: %w", member.Name, err)
+			}
+		}
+
+		return StarlarkMarshal(res)
+	})
+
+	getClusterMemberInstanceCount := starlark.NewBuiltin("get_cluster_member_instance_count", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		member, err := candidateMember(b, args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+
+		var count int
+		err = s.Cluster.Transaction(func(tx *db.ClusterTx) error {
+			count, err = tx.GetNodeInstanceCount(member.ID)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return starlark.MakeInt(count), nil
+	})
+
+	thread := &starlark.Thread{
+		Name: "instance_placement",
+		Print: func(thread *starlark.Thread, msg string) {
+			l.Info(fmt.Sprintf("Instance placement scriptlet: %s", msg))
+		},
+	}
+
+	thread.SetMaxExecutionSteps(instancePlacementMaxSteps)
+
+	// Stop the scriptlet if the request goes away.
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			thread.Cancel("Request cancelled")
+		case <-done:
+		}
+	}()
+
+	globals, err := prog.Init(thread, starlark.StringDict{
+		"log_info":                          logFunc("info"),
+		"log_warn":                          logFunc("warn"),
+		"log_error":                         logFunc("error"),
+		"set_target":                        setTarget,
+		"get_cluster_member_resources":      getClusterMemberResources,
+		"get_cluster_member_instance_count": getClusterMemberInstanceCount,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed initializing instance placement scriptlet: %w", err)
+	}
+
+	fn, ok := globals[load.InstancePlacementFunction]
+	if !ok {
+		return nil, fmt.Errorf("Instance placement scriptlet doesn't define a %q function", load.InstancePlacementFunction)
+	}
+
+	// Prepare the arguments, always providing the config and devices so the scriptlet can use them directly.
+	if req.Config == nil {
+		req.Config = map[string]string{}
+	}
+
+	if req.Devices == nil {
+		req.Devices = map[string]map[string]string{}
+	}
+
+	reqValue, err := StarlarkMarshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("Failed marshalling request: %w", err)
+	}
+
+	members := make([]api.ClusterMember, 0, len(candidateMembers))
+	for _, candidate := range candidateMembers {
+		member, err := candidate.ToAPI(s.Cluster, s.Node, leaderAddress)
+		if err != nil {
+			return nil, err
+		}
+
+		members = append(members, *member)
+	}
+
+	membersValue, err := StarlarkMarshal(members)
+	if err != nil {
+		return nil, fmt.Errorf("Failed marshalling candidate members: %w", err)
+	}
+
+	// Call the function.
+	_, err = starlark.Call(thread, fn, starlark.Tuple{reqValue, membersValue}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Instance placement scriptlet: %w", err)
+	}
+
+	return targetMember, nil
+}
@@ -0,0 +1,56 @@
+package load
+
+import (
+	"fmt"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+
+	"github.com/lxc/lxd/shared"
+)
+
+// InstancePlacementFunction is the name of the function the instance placement scriptlet must define.
+const InstancePlacementFunction = "instance_placement"
+
+// InstancePlacementBuiltins lists the builtin functions available to the instance placement scriptlet.
+var InstancePlacementBuiltins = []string{
+	"log_info",
+	"log_warn",
+	"log_error",
+	"set_target",
+	"get_cluster_member_resources",
+	"get_cluster_member_instance_count",
+}
+
+// InstancePlacementCompile compiles the instance placement scriptlet and checks that it defines the
+// instance_placement function.
+func InstancePlacementCompile(src string) (*starlark.Program, error) {
+	isPredeclared := func(name string) bool {
+		return shared.StringInSlice(name, InstancePlacementBuiltins)
+	}
+
+	f, prog, err := starlark.SourceProgram("instance_placement.star", src, isPredeclared)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stmt := range f.Stmts {
+		def, ok := stmt.(*syntax.DefStmt)
+		if ok && def.Name.Name == InstancePlacementFunction {
+			return prog, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Scriptlet doesn't define a %q function", InstancePlacementFunction)
+}
+
+// InstancePlacementValidate validates the instance placement scriptlet.
+func InstancePlacementValidate(src string) error {
+	if src == "" {
+		return nil
+	}
+
+	_, err := InstancePlacementCompile(src)
+
+	return err
+}
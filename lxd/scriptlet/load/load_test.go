@@ -0,0 +1,31 @@
+package load_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/lxd/lxd/scriptlet/load"
+)
+
+func TestInstancePlacementValidate(t *testing.T) {
+	valid := []string{
+		"",
+		"def instance_placement(request, candidate_members):\n    pass\n",
+		"def instance_placement(request, candidate_members):\n    log_info(request[\"name\"])\n    set_target(candidate_members[0][\"server_name\"])\n",
+	}
+
+	for _, src := range valid {
+		assert.NoError(t, load.InstancePlacementValidate(src), src)
+	}
+
+	invalid := []string{
+		"def instance_placement(",
+		"def foo(request, candidate_members):\n    pass\n",
+		"def instance_placement(request, candidate_members):\n    unknown_builtin()\n",
+	}
+
+	for _, src := range invalid {
+		assert.Error(t, load.InstancePlacementValidate(src), src)
+	}
+}
@@ -0,0 +1,98 @@
+package scriptlet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"go.starlark.net/starlark"
+)
+
+// StarlarkMarshal converts input to a Starlark value.
+// The input is encoded as JSON first so that the resulting field names match those used by the API.
+func StarlarkMarshal(input any) (starlark.Value, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	err = decoder.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+
+	return starlarkValue(value)
+}
+
+// starlarkValue converts a value decoded from JSON to a Starlark value.
+func starlarkValue(value any) (starlark.Value, error) {
+	switch v := value.(type) {
+	case nil:
+		return starlark.None, nil
+	case bool:
+		return starlark.Bool(v), nil
+	case string:
+		return starlark.String(v), nil
+	case json.Number:
+		i, err := v.Int64()
+		if err == nil {
+			return starlark.MakeInt64(i), nil
+		}
+
+		u, err := strconv.ParseUint(v.String(), 10, 64)
+		if err == nil {
+			return starlark.MakeUint64(u), nil
+		}
+
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+
+		return starlark.Float(f), nil
+	case []any:
+		elems := make([]starlark.Value, 0, len(v))
+		for _, elem := range v {
+			starlarkElem, err := starlarkValue(elem)
+			if err != nil {
+				return nil, err
+			}
+
+			elems = append(elems, starlarkElem)
+		}
+
+		return starlark.NewList(elems), nil
+	case map[string]any:
+		// Insert the keys in a stable order.
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		dict := starlark.NewDict(len(v))
+		for _, key := range keys {
+			starlarkElem, err := starlarkValue(v[key])
+			if err != nil {
+				return nil, err
+			}
+
+			err = dict.SetKey(starlark.String(key), starlarkElem)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return dict, nil
+	}
+
+	return nil, fmt.Errorf("Unsupported type %T", value)
+}
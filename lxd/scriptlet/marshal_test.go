@@ -0,0 +1,21 @@
+package scriptlet_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/lxd/lxd/scriptlet"
+	"github.com/lxc/lxd/shared/api"
+)
+
+func TestStarlarkMarshal(t *testing.T) {
+	value, err := scriptlet.StarlarkMarshal(api.ResourcesMemory{Total: 1 << 63, Used: 1024})
+	require.NoError(t, err)
+	assert.Equal(t, `{"hugepages_size": 0, "hugepages_total": 0, "hugepages_used": 0, "total": 9223372036854775808, "used": 1024}`, value.String())
+
+	value, err = scriptlet.StarlarkMarshal(map[string]any{"name": "c1", "profiles": []string{"default"}, "ephemeral": false, "config": nil})
+	require.NoError(t, err)
+	assert.Equal(t, `{"config": None, "ephemeral": False, "name": "c1", "profiles": ["default"]}`, value.String())
+}
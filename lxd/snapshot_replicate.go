@@ -0,0 +1,438 @@
+package main
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	lxd "github.com/lxc/lxd/client"
+	"github.com/lxc/lxd/lxd/db"
+	dbCluster "github.com/lxc/lxd/lxd/db/cluster"
+	"github.com/lxc/lxd/lxd/instance"
+	"github.com/lxc/lxd/lxd/operations"
+	"github.com/lxc/lxd/lxd/state"
+	storagePools "github.com/lxc/lxd/lxd/storage"
+	"github.com/lxc/lxd/lxd/util"
+	"github.com/lxc/lxd/lxd/warnings"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/logger"
+	"github.com/lxc/lxd/shared/version"
+)
+
+// replicationTarget is a connection to the LXD server that snapshots are replicated to.
+type replicationTarget struct {
+	client      lxd.InstanceServer
+	address     string
+	certificate string
+}
+
+// operationURL returns the URL of an operation on the replication target.
+func (t *replicationTarget) operationURL(opID string) string {
+	return fmt.Sprintf("https://%s/1.0/operations/%s", t.address, url.PathEscape(opID))
+}
+
+// replicationConnect connects to the replication target configured in the snapshots.replicate.* keys.
+// The target's certificate must match snapshots.replicate.target.fingerprint and the target must trust
+// this server's certificate.
+func replicationConnect(s *state.State, config map[string]string, projectName string) (*replicationTarget, error) {
+	if config["snapshots.replicate.target.fingerprint"] == "" {
+		return nil, fmt.Errorf("The snapshots.replicate.target.fingerprint key must be set to replicate snapshots")
+	}
+
+	address := util.CanonicalNetworkAddress(config["snapshots.replicate.target"], shared.HTTPSDefaultPort)
+
+	// Pin the target's certificate to the configured fingerprint.
+	cert, err := shared.GetRemoteCertificate(fmt.Sprintf("https://%s", address), version.UserAgent)
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting certificate of replication target %q: %w", address, err)
+	}
+
+	if shared.CertFingerprint(cert) != strings.ToLower(config["snapshots.replicate.target.fingerprint"]) {
+		return nil, fmt.Errorf("Certificate of replication target %q doesn't match the configured fingerprint", address)
+	}
+
+	certificate := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+	serverCert := s.ServerCert()
+
+	args := &lxd.ConnectionArgs{
+		TLSServerCert: certificate,
+		TLSClientCert: string(serverCert.PublicKey()),
+		TLSClientKey:  string(serverCert.PrivateKey()),
+		UserAgent:     version.UserAgent,
+	}
+
+	client, err := lxd.ConnectLXD(fmt.Sprintf("https://%s", address), args)
+	if err != nil {
+		return nil, fmt.Errorf("Failed connecting to replication target %q: %w", address, err)
+	}
+
+	targetProject := config["snapshots.replicate.target.project"]
+	if targetProject == "" {
+		targetProject = projectName
+	}
+
+	return &replicationTarget{
+		client:      client.UseProject(targetProject),
+		address:     address,
+		certificate: certificate,
+	}, nil
+}
+
+// replicationConfig returns the config to apply to a replicated instance or volume.
+// Volatile and replication keys are specific to the source so aren't copied.
+func replicationConfig(config map[string]string) map[string]string {
+	targetConfig := make(map[string]string, len(config))
+	for k, v := range config {
+		if strings.HasPrefix(k, shared.ConfigVolatilePrefix) || strings.HasPrefix(k, "snapshots.replicate.") {
+			continue
+		}
+
+		targetConfig[k] = v
+	}
+
+	return targetConfig
+}
+
+// replicationStatus returns the volatile keys recording a successful replication of snapName at the given time.
+func replicationStatus(snapName string, replicatedAt time.Time) map[string]string {
+	return map[string]string{
+		"volatile.replicate.last_snapshot": snapName,
+		"volatile.replicate.last_success":  replicatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// replicationQueue runs the snapshot replications in the background, so that a slow transfer doesn't hold up
+// the snapshot schedulers. At most one replication runs at a time for each instance or volume. A replication
+// requested while another one is running for the same instance or volume runs once it's done, and only the
+// latest of those is kept as each replication transfers all the missing snapshots.
+type replicationQueue struct {
+	mu      sync.Mutex
+	running map[string]bool
+	pending map[string]replicationJob
+	runJob  func(s *state.State, job replicationJob) error
+}
+
+// replicationJob is a replication waiting to run.
+type replicationJob struct {
+	projectName string
+	opType      db.OperationType
+	resources   map[string][]string
+	run         func(op *operations.Operation) error
+}
+
+var replications = &replicationQueue{
+	running: map[string]bool{},
+	pending: map[string]replicationJob{},
+	runJob:  runReplicationJob,
+}
+
+// queue runs the replication job for the instance or volume identified by key, once any replication already
+// running for it is done.
+func (q *replicationQueue) queue(s *state.State, key string, job replicationJob) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.running[key] {
+		q.pending[key] = job
+		return
+	}
+
+	q.running[key] = true
+
+	go q.run(s, key, job)
+}
+
+// run runs the replication job and then those queued in the meantime for the same key.
+func (q *replicationQueue) run(s *state.State, key string, job replicationJob) {
+	for {
+		err := q.runJob(s, job)
+		if err != nil {
+			logger.Error("Failed replicating snapshots", logger.Ctx{"project": job.projectName, "entity": key, "err": err})
+		}
+
+		q.mu.Lock()
+		next, ok := q.pending[key]
+		if !ok {
+			delete(q.running, key)
+			q.mu.Unlock()
+			return
+		}
+
+		delete(q.pending, key)
+		q.mu.Unlock()
+
+		job = next
+	}
+}
+
+// runReplicationJob runs the replication job in its own operation and waits for it to finish.
+func runReplicationJob(s *state.State, job replicationJob) error {
+	op, err := operations.OperationCreate(s, job.projectName, operations.OperationClassTask, job.opType, job.resources, nil, job.run, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("Failed creating replication operation: %w", err)
+	}
+
+	chanRun, err := op.Run()
+	if err != nil {
+		return fmt.Errorf("Failed starting replication operation: %w", err)
+	}
+
+	return <-chanRun
+}
+
+// instanceReplicateQueue queues the replication of an instance after snapName was created.
+func instanceReplicateQueue(s *state.State, inst instance.Instance, snapName string) {
+	key := fmt.Sprintf("instance/%s/%s", inst.Project(), inst.Name())
+
+	replications.queue(s, key, replicationJob{
+		projectName: inst.Project(),
+		opType:      db.OperationSnapshotReplicate,
+		resources:   map[string][]string{"instances": {inst.Name()}},
+		run: func(op *operations.Operation) error {
+			// Reload the instance as its config may have changed while the replication was queued.
+			inst, err := instance.LoadByProjectAndName(s, inst.Project(), inst.Name())
+			if err != nil {
+				return err
+			}
+
+			if inst.ExpandedConfig()["snapshots.replicate.target"] == "" {
+				return nil
+			}
+
+			return instanceReplicate(s, inst, snapName, op)
+		},
+	})
+}
+
+// customVolumeReplicateQueue queues the replication of a custom volume after snapName was created.
+func customVolumeReplicateQueue(s *state.State, projectName string, poolName string, volName string, snapName string) {
+	key := fmt.Sprintf("volume/%s/%s/%s", projectName, poolName, volName)
+
+	replications.queue(s, key, replicationJob{
+		projectName: projectName,
+		opType:      db.OperationVolumeSnapshotReplicate,
+		resources:   map[string][]string{"storage_volumes": {volName}},
+		run: func(op *operations.Operation) error {
+			return customVolumeReplicate(s, projectName, poolName, volName, snapName, op)
+		},
+	})
+}
+
+// instanceReplicate pushes an instance and its snapshots to its replication target, refreshing the copy if it
+// already exists so that only the missing snapshots are transferred. snapName is the newest snapshot of the
+// instance and is recorded as the last replicated snapshot on success.
+func instanceReplicate(s *state.State, inst instance.Instance, snapName string, op *operations.Operation) error {
+	l := logger.AddContext(logger.Log, logger.Ctx{"project": inst.Project(), "instance": inst.Name(), "target": inst.ExpandedConfig()["snapshots.replicate.target"]})
+
+	err := instanceReplicateToTarget(s, inst, op)
+	if err != nil {
+		warnErr := s.Cluster.UpsertWarningLocalNode(inst.Project(), dbCluster.TypeInstance, inst.ID(), db.WarningSnapshotReplicationFailure, err.Error())
+		if warnErr != nil {
+			l.Warn("Failed to create warning", logger.Ctx{"err": warnErr})
+		}
+
+		return err
+	}
+
+	err = inst.VolatileSet(replicationStatus(snapName, time.Now()))
+	if err != nil {
+		return err
+	}
+
+	err = warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(s.Cluster, inst.Project(), db.WarningSnapshotReplicationFailure, dbCluster.TypeInstance, inst.ID())
+	if err != nil {
+		l.Warn("Failed to resolve warning", logger.Ctx{"err": err})
+	}
+
+	l.Info("Replicated instance", logger.Ctx{"snapshot": snapName})
+
+	return nil
+}
+
+func instanceReplicateToTarget(s *state.State, inst instance.Instance, op *operations.Operation) error {
+	target, err := replicationConnect(s, inst.ExpandedConfig(), inst.Project())
+	if err != nil {
+		return err
+	}
+
+	_, _, err = target.client.GetInstance(inst.Name())
+	if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+		return fmt.Errorf("Failed checking for instance on replication target: %w", err)
+	}
+
+	refresh := err == nil
+
+	render, _, err := inst.Render()
+	if err != nil {
+		return err
+	}
+
+	instAPI, ok := render.(*api.Instance)
+	if !ok {
+		return fmt.Errorf("Unexpected instance render type %T", render)
+	}
+
+	req := api.InstancesPost{
+		Name:        inst.Name(),
+		InstancePut: instAPI.Writable(),
+		Type:        api.InstanceType(instAPI.Type),
+		Source: api.InstanceSource{
+			Type:      "migration",
+			Mode:      "push",
+			Refresh:   refresh,
+			BaseImage: instAPI.Config["volatile.base_image"],
+		},
+	}
+
+	req.Config = replicationConfig(req.Config)
+
+	targetOp, err := target.client.CreateInstance(req)
+	if err != nil {
+		return fmt.Errorf("Failed creating instance on replication target: %w", err)
+	}
+
+	ws, err := newMigrationSource(inst, false, false)
+	if err != nil {
+		return err
+	}
+
+	return replicationPush(target, targetOp, ws, func() error { return ws.Do(s, op) })
+}
+
+// customVolumeReplicate pushes a custom volume and its snapshots to its replication target, refreshing the copy
+// if it already exists so that only the missing snapshots are transferred. snapName is the newest snapshot of
+// the volume and is recorded as the last replicated snapshot on success.
+func customVolumeReplicate(s *state.State, projectName string, poolName string, volName string, snapName string, op *operations.Operation) error {
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return err
+	}
+
+	volID, vol, err := s.Cluster.GetLocalStoragePoolVolume(projectName, volName, db.StoragePoolVolumeTypeCustom, pool.ID())
+	if err != nil {
+		return err
+	}
+
+	// The volume's config may have changed while the replication was queued.
+	if vol.Config["snapshots.replicate.target"] == "" {
+		return nil
+	}
+
+	l := logger.AddContext(logger.Log, logger.Ctx{"project": projectName, "pool": poolName, "volume": volName, "target": vol.Config["snapshots.replicate.target"]})
+
+	err = customVolumeReplicateToTarget(s, projectName, poolName, vol, op)
+	if err != nil {
+		warnErr := s.Cluster.UpsertWarningLocalNode(projectName, dbCluster.TypeStorageVolume, int(volID), db.WarningSnapshotReplicationFailure, err.Error())
+		if warnErr != nil {
+			l.Warn("Failed to create warning", logger.Ctx{"err": warnErr})
+		}
+
+		return err
+	}
+
+	// Reload the volume in case its config changed during the transfer.
+	_, vol, err = s.Cluster.GetLocalStoragePoolVolume(projectName, volName, db.StoragePoolVolumeTypeCustom, pool.ID())
+	if err != nil {
+		return err
+	}
+
+	for k, v := range replicationStatus(snapName, time.Now()) {
+		vol.Config[k] = v
+	}
+
+	err = s.Cluster.UpdateStoragePoolVolume(projectName, volName, db.StoragePoolVolumeTypeCustom, pool.ID(), vol.Description, vol.Config)
+	if err != nil {
+		return err
+	}
+
+	err = warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(s.Cluster, projectName, db.WarningSnapshotReplicationFailure, dbCluster.TypeStorageVolume, int(volID))
+	if err != nil {
+		l.Warn("Failed to resolve warning", logger.Ctx{"err": err})
+	}
+
+	l.Info("Replicated custom volume", logger.Ctx{"snapshot": snapName})
+
+	return nil
+}
+
+func customVolumeReplicateToTarget(s *state.State, projectName string, poolName string, vol *api.StorageVolume, op *operations.Operation) error {
+	target, err := replicationConnect(s, vol.Config, projectName)
+	if err != nil {
+		return err
+	}
+
+	targetPool := vol.Config["snapshots.replicate.target.pool"]
+	if targetPool == "" {
+		targetPool = poolName
+	}
+
+	_, _, err = target.client.GetStoragePoolVolume(targetPool, "custom", vol.Name)
+	if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+		return fmt.Errorf("Failed checking for volume on replication target: %w", err)
+	}
+
+	req := api.StorageVolumesPost{
+		Name:        vol.Name,
+		Type:        "custom",
+		ContentType: vol.ContentType,
+		StorageVolumePut: api.StorageVolumePut{
+			Config:      replicationConfig(vol.Config),
+			Description: vol.Description,
+		},
+		Source: api.StorageVolumeSource{
+			Type:    "migration",
+			Mode:    "push",
+			Refresh: err == nil,
+		},
+	}
+
+	targetOp, _, err := target.client.RawOperation("POST", fmt.Sprintf("/storage-pools/%s/volumes/custom", url.PathEscape(targetPool)), req, "")
+	if err != nil {
+		return fmt.Errorf("Failed creating volume on replication target: %w", err)
+	}
+
+	ws, err := newStorageMigrationSource(false)
+	if err != nil {
+		return err
+	}
+
+	return replicationPush(target, targetOp, ws, func() error { return ws.DoStorage(s, projectName, poolName, vol.Name, op) })
+}
+
+// replicationPush connects the migration source to the target operation's websockets, runs the migration and
+// waits for the target to finish.
+func replicationPush(target *replicationTarget, targetOp lxd.Operation, ws *migrationSourceWs, run func() error) error {
+	opAPI := targetOp.Get()
+
+	secrets := map[string]string{}
+	for k, v := range opAPI.Metadata {
+		secret, ok := v.(string)
+		if ok {
+			secrets[k] = secret
+		}
+	}
+
+	err := ws.ConnectTarget(target.certificate, target.operationURL(opAPI.ID), secrets)
+	if err != nil {
+		targetOp.Cancel()
+		return fmt.Errorf("Failed connecting to replication target: %w", err)
+	}
+
+	err = run()
+	if err != nil {
+		targetOp.Cancel()
+		return err
+	}
+
+	err = targetOp.Wait()
+	if err != nil {
+		return fmt.Errorf("Replication target failed: %w", err)
+	}
+
+	return nil
+}
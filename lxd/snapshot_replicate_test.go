@@ -0,0 +1,113 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/lxd/lxd/operations"
+	"github.com/lxc/lxd/lxd/state"
+)
+
+func TestReplicationConfig(t *testing.T) {
+	config := map[string]string{
+		"limits.cpu":                             "2",
+		"snapshots.schedule":                     "@hourly",
+		"snapshots.expiry":                       "1d",
+		"snapshots.replicate.target":             "10.0.0.2:8443",
+		"snapshots.replicate.target.fingerprint": "abcdef",
+		"snapshots.replicate.target.project":     "dr",
+		"snapshots.replicate.target.pool":        "remote",
+		"volatile.base_image":                    "1234",
+		"volatile.replicate.last_snapshot":       "snap1",
+		"volatile.replicate.last_success":        "2023-02-10T12:00:00Z",
+		"user.replicated":                        "yes",
+	}
+
+	assert.Equal(t, map[string]string{
+		"limits.cpu":         "2",
+		"snapshots.schedule": "@hourly",
+		"snapshots.expiry":   "1d",
+		"user.replicated":    "yes",
+	}, replicationConfig(config))
+
+	// The source config is left untouched.
+	assert.Equal(t, "10.0.0.2:8443", config["snapshots.replicate.target"])
+
+	assert.Empty(t, replicationConfig(map[string]string{}))
+	assert.Empty(t, replicationConfig(nil))
+}
+
+func TestReplicationStatus(t *testing.T) {
+	replicatedAt := time.Date(2023, 2, 10, 13, 14, 15, 0, time.FixedZone("CET", 3600))
+
+	assert.Equal(t, map[string]string{
+		"volatile.replicate.last_snapshot": "snap2",
+		"volatile.replicate.last_success":  "2023-02-10T12:14:15Z",
+	}, replicationStatus("snap2", replicatedAt))
+}
+
+func TestReplicationQueue(t *testing.T) {
+	started := make(chan string)
+	release := make(chan struct{})
+
+	var mu sync.Mutex
+	var runs []string
+
+	q := &replicationQueue{
+		running: map[string]bool{},
+		pending: map[string]replicationJob{},
+		runJob: func(s *state.State, job replicationJob) error {
+			return job.run(nil)
+		},
+	}
+
+	// job returns a replication job which records its run and waits to be released.
+	job := func(name string) replicationJob {
+		return replicationJob{
+			projectName: "default",
+			run: func(op *operations.Operation) error {
+				mu.Lock()
+				runs = append(runs, name)
+				mu.Unlock()
+
+				started <- name
+				<-release
+
+				return nil
+			},
+		}
+	}
+
+	// The first replication of an instance runs straight away.
+	q.queue(nil, "instance/default/c1", job("c1/snap0"))
+	assert.Equal(t, "c1/snap0", <-started)
+
+	// Other instances don't wait for it.
+	q.queue(nil, "instance/default/c2", job("c2/snap0"))
+	assert.Equal(t, "c2/snap0", <-started)
+
+	// Replications of a busy instance wait, and only the latest one is kept.
+	q.queue(nil, "instance/default/c1", job("c1/snap1"))
+	q.queue(nil, "instance/default/c1", job("c1/snap2"))
+
+	release <- struct{}{}
+	release <- struct{}{}
+
+	assert.Equal(t, "c1/snap2", <-started)
+	release <- struct{}{}
+
+	assert.Eventually(t, func() bool {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+
+		return len(q.running) == 0 && len(q.pending) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []string{"c1/snap0", "c2/snap0", "c1/snap2"}, runs)
+}
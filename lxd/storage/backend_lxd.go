@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -33,6 +34,8 @@ import (
 	"github.com/lxc/lxd/lxd/storage/drivers"
 	"github.com/lxc/lxd/lxd/storage/filesystem"
 	"github.com/lxc/lxd/lxd/storage/memorypipe"
+	"github.com/lxc/lxd/lxd/storage/s3"
+	"github.com/lxc/lxd/lxd/storage/s3/miniod"
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/api"
 	"github.com/lxc/lxd/shared/instancewriter"
@@ -891,7 +894,8 @@ func (b *lxdBackend) CreateInstanceFromCopy(inst instance.Instance, src instance
 		return fmt.Errorf("Instance types must match")
 	}
 
-	if src.Type() == instancetype.VM && src.IsRunning() {
+	// A running VM's disk can only be copied inconsistently, its writes need to be mirrored by the caller.
+	if src.Type() == instancetype.VM && src.IsRunning() && !allowInconsistent {
 		return fmt.Errorf("Unable to perform VM live migration: %w", drivers.ErrNotImplemented)
 	}
 
@@ -1956,8 +1960,15 @@ func (b *lxdBackend) DeleteInstance(inst instance.Instance, op *operations.Opera
 		return err
 	}
 
-	// Get any snapshots the instance has in the format <instance name>/<snapshot name>.
-	snapshots, err := b.state.Cluster.GetInstanceSnapshotsNames(inst.Project(), inst.Name())
+	volDBType, err := VolumeTypeToDBType(volType)
+	if err != nil {
+		return err
+	}
+
+	// Get any snapshot volumes the instance has on this member. The volumes are checked rather than the
+	// instance snapshot records as the volumes of an instance live migrated to another cluster member are
+	// removed while its snapshots remain.
+	snapshots, err := b.state.Cluster.GetLocalStoragePoolVolumeSnapshotsWithType(inst.Project(), inst.Name(), volDBType, b.ID())
 	if err != nil {
 		return err
 	}
@@ -2005,6 +2016,43 @@ func (b *lxdBackend) DeleteInstance(inst instance.Instance, op *operations.Opera
 	return nil
 }
 
+// EnsureInstanceSymlinks points the instance's symlinks to its volumes on this pool, replacing any pointing to
+// another pool. This is used when an instance has volumes on more than one pool, such as during a live move.
+func (b *lxdBackend) EnsureInstanceSymlinks(inst instance.Instance) error {
+	if inst.IsSnapshot() {
+		return fmt.Errorf("Instance must not be a snapshot")
+	}
+
+	// Check we can convert the instance to the volume types needed.
+	volType, err := InstanceTypeToVolumeType(inst.Type())
+	if err != nil {
+		return err
+	}
+
+	volDBType, err := VolumeTypeToDBType(volType)
+	if err != nil {
+		return err
+	}
+
+	volStorageName := project.Instance(inst.Project(), inst.Name())
+
+	err = b.ensureInstanceSymlink(inst.Type(), inst.Project(), inst.Name(), drivers.GetVolumeMountPath(b.name, volType, volStorageName))
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := b.state.Cluster.GetLocalStoragePoolVolumeSnapshotsWithType(inst.Project(), inst.Name(), volDBType, b.ID())
+	if err != nil {
+		return err
+	}
+
+	if len(snapshots) > 0 {
+		return b.ensureInstanceSnapshotSymlink(inst.Type(), inst.Project(), inst.Name())
+	}
+
+	return b.removeInstanceSnapshotSymlinkIfUnused(inst.Type(), inst.Project(), inst.Name())
+}
+
 // UpdateInstance updates an instance volume's config.
 func (b *lxdBackend) UpdateInstance(inst instance.Instance, newDesc string, newConfig map[string]string, op *operations.Operation) error {
 	l := logger.AddContext(b.logger, logger.Ctx{"project": inst.Project(), "instance": inst.Name(), "newDesc": newDesc, "newConfig": newConfig})
@@ -2124,8 +2172,9 @@ func (b *lxdBackend) MigrateInstance(inst instance.Instance, conn io.ReadWriteCl
 	l.Debug("MigrateInstance started")
 	defer l.Debug("MigrateInstance finished")
 
-	// rsync+dd can't handle running source instances
-	if inst.IsRunning() && args.MigrationType.FSType == migration.MigrationFSType_BLOCK_AND_RSYNC {
+	// rsync+dd can't handle running source instances, unless allowed to be inconsistent or the VM's writes are
+	// being captured elsewhere during live migration.
+	if inst.IsRunning() && args.MigrationType.FSType == migration.MigrationFSType_BLOCK_AND_RSYNC && !args.AllowInconsistent {
 		return fmt.Errorf("Rsync based migration doesn't support running virtual machines")
 	}
 
@@ -2226,6 +2275,99 @@ func (b *lxdBackend) BackupInstance(inst instance.Instance, tarWriter *instancew
 	return nil
 }
 
+// RestoreInstanceBackupDelta applies the root disk delta of an incremental backup to the stopped VM's volume.
+func (b *lxdBackend) RestoreInstanceBackupDelta(inst instance.Instance, srcData io.ReadSeeker, op *operations.Operation) error {
+	l := logger.AddContext(b.logger, logger.Ctx{"project": inst.Project(), "instance": inst.Name()})
+	l.Debug("RestoreInstanceBackupDelta started")
+	defer l.Debug("RestoreInstanceBackupDelta finished")
+
+	if inst.Type() != instancetype.VM {
+		return fmt.Errorf("Incremental backups are only supported for virtual machines")
+	}
+
+	if inst.IsRunning() {
+		return fmt.Errorf("Instance must not be running to apply an incremental backup")
+	}
+
+	volType, err := InstanceTypeToVolumeType(inst.Type())
+	if err != nil {
+		return err
+	}
+
+	// Load storage volume from database.
+	dbVol, err := VolumeDBGet(b, inst.Project(), inst.Name(), volType)
+	if err != nil {
+		return err
+	}
+
+	contentType := InstanceContentType(inst)
+	volStorageName := project.Instance(inst.Project(), inst.Name())
+	vol := b.GetVolume(volType, contentType, volStorageName, dbVol.Config)
+
+	return vol.MountTask(func(mountPath string, op *operations.Operation) error {
+		tr, cancelFunc, err := backup.TarReader(srcData, b.state.OS, mountPath)
+		if err != nil {
+			return err
+		}
+
+		defer cancelFunc()
+
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				return fmt.Errorf("Backup is missing %q", backup.DeltaFileName)
+			}
+
+			if err != nil {
+				return fmt.Errorf("Error reading backup file: %w", err)
+			}
+
+			if hdr.Name == backup.DeltaFileName {
+				break
+			}
+		}
+
+		delta, err := backup.NewDeltaReader(tr)
+		if err != nil {
+			return err
+		}
+
+		diskPath, err := b.driver.GetVolumeDiskPath(vol)
+		if err != nil {
+			return err
+		}
+
+		diskSize, err := drivers.BlockDiskSizeBytes(diskPath)
+		if err != nil {
+			return err
+		}
+
+		// Grow the disk if it was grown since the base backup.
+		if diskSize < delta.DiskSize() {
+			l.Debug("Growing volume to incremental backup disk size", logger.Ctx{"size": delta.DiskSize()})
+
+			err = b.driver.SetVolumeQuota(vol, fmt.Sprintf("%d", delta.DiskSize()), false, op)
+			if err != nil {
+				return err
+			}
+		}
+
+		f, err := os.OpenFile(diskPath, os.O_WRONLY, 0)
+		if err != nil {
+			return fmt.Errorf("Failed opening disk %q: %w", diskPath, err)
+		}
+
+		defer f.Close()
+
+		err = delta.Apply(f)
+		if err != nil {
+			return err
+		}
+
+		return f.Close()
+	}, op)
+}
+
 // GetInstanceUsage returns the disk usage of the instance's root volume.
 func (b *lxdBackend) GetInstanceUsage(inst instance.Instance) (int64, error) {
 	l := logger.AddContext(b.logger, logger.Ctx{"project": inst.Project(), "instance": inst.Name()})
@@ -5067,3 +5209,470 @@ func (b *lxdBackend) CreateCustomVolumeFromBackup(srcBackup backup.Info, srcData
 	revert.Success()
 	return nil
 }
+
+// bucketMemberSpecific returns whether buckets on this pool are specific to a single cluster member.
+func (b *lxdBackend) bucketMemberSpecific() bool {
+	return !b.driver.Info().Remote
+}
+
+// getBucket loads the bucket record from the database.
+func (b *lxdBackend) getBucket(projectName string, bucketName string) (*db.StorageBucket, error) {
+	var bucket *db.StorageBucket
+	err := b.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		var err error
+		bucket, err = tx.GetStoragePoolBucket(b.id, projectName, b.bucketMemberSpecific(), bucketName)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bucket, nil
+}
+
+// getBucketVolume returns the volume used for storing the bucket.
+func (b *lxdBackend) getBucketVolume(projectName string, bucketName string, config map[string]string) (drivers.Volume, error) {
+	vol := b.GetVolume(drivers.VolumeTypeBucket, drivers.ContentTypeFS, project.StorageVolume(projectName, bucketName), config)
+
+	// Local buckets are stored on a filesystem volume so need the volume defaults applied.
+	if b.bucketMemberSpecific() {
+		err := b.driver.FillVolumeConfig(vol)
+		if err != nil {
+			return vol, err
+		}
+	}
+
+	return vol, nil
+}
+
+// GetBucketURL returns the S3 URL of the bucket, or nil if the bucket cannot be accessed.
+func (b *lxdBackend) GetBucketURL(bucketName string) *url.URL {
+	if !b.driver.Info().Buckets {
+		return nil
+	}
+
+	if !b.bucketMemberSpecific() {
+		return b.driver.GetBucketURL(bucketName)
+	}
+
+	address := b.state.Endpoints.StorageBucketsAddress()
+	if address == "" {
+		return nil
+	}
+
+	return &url.URL{
+		Scheme: "https",
+		Host:   address,
+		Path:   fmt.Sprintf("/%s", bucketName),
+	}
+}
+
+// CreateBucket creates a new storage bucket.
+func (b *lxdBackend) CreateBucket(projectName string, bucket api.StorageBucketsPost, op *operations.Operation) error {
+	l := logger.AddContext(b.logger, logger.Ctx{"project": projectName, "bucketName": bucket.Name, "desc": bucket.Description, "config": bucket.Config})
+	l.Debug("CreateBucket started")
+	defer l.Debug("CreateBucket finished")
+
+	err := b.isStatusReady()
+	if err != nil {
+		return err
+	}
+
+	if !b.driver.Info().Buckets {
+		return fmt.Errorf("Storage pool does not support buckets")
+	}
+
+	err = s3.ValidateBucketName(bucket.Name)
+	if err != nil {
+		return err
+	}
+
+	vol, err := b.getBucketVolume(projectName, bucket.Name, bucket.Config)
+	if err != nil {
+		return err
+	}
+
+	err = b.driver.ValidateBucket(vol)
+	if err != nil {
+		return err
+	}
+
+	memberSpecific := b.bucketMemberSpecific()
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Create the database record, storing the volume config so that the volume defaults used are kept.
+	bucket.Config = vol.Config()
+
+	var bucketID int64
+	err = b.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		// Local buckets are served from a single S3 endpoint on each member so their names must be unique
+		// across all of the member's pools and projects.
+		if memberSpecific {
+			_, err := tx.GetStoragePoolLocalBucket(bucket.Name)
+			if err == nil {
+				return api.StatusErrorf(http.StatusConflict, "A bucket for that name already exists")
+			} else if !response.IsNotFoundError(err) {
+				return err
+			}
+		}
+
+		bucketID, err = tx.CreateStoragePoolBucket(b.id, projectName, memberSpecific, bucket)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		_ = b.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
+			return tx.DeleteStoragePoolBucket(b.id, bucketID)
+		})
+	})
+
+	if memberSpecific {
+		// Make sure the parent directory exists on pools created before buckets were supported.
+		err = os.MkdirAll(drivers.GetVolumeMountPath(b.name, drivers.VolumeTypeBucket, ""), 0711)
+		if err != nil {
+			return fmt.Errorf("Failed creating buckets directory: %w", err)
+		}
+
+		err = b.driver.CreateVolume(vol, nil, op)
+		if err != nil {
+			return err
+		}
+	} else {
+		err = b.driver.CreateBucket(vol, op)
+		if err != nil {
+			return err
+		}
+	}
+
+	revert.Success()
+	return nil
+}
+
+// UpdateBucket updates the storage bucket's description and config.
+func (b *lxdBackend) UpdateBucket(projectName string, bucketName string, bucket api.StorageBucketPut, op *operations.Operation) error {
+	l := logger.AddContext(b.logger, logger.Ctx{"project": projectName, "bucketName": bucketName, "desc": bucket.Description, "config": bucket.Config})
+	l.Debug("UpdateBucket started")
+	defer l.Debug("UpdateBucket finished")
+
+	curBucket, err := b.getBucket(projectName, bucketName)
+	if err != nil {
+		return err
+	}
+
+	curVol, err := b.getBucketVolume(projectName, bucketName, curBucket.Config)
+	if err != nil {
+		return err
+	}
+
+	// Keep the volume defaults that were stored at creation time.
+	for k, v := range curVol.Config() {
+		_, found := bucket.Config[k]
+		if !found && k != "size" {
+			if bucket.Config == nil {
+				bucket.Config = make(map[string]string)
+			}
+
+			bucket.Config[k] = v
+		}
+	}
+
+	newVol, err := b.getBucketVolume(projectName, bucketName, bucket.Config)
+	if err != nil {
+		return err
+	}
+
+	err = b.driver.ValidateBucket(newVol)
+	if err != nil {
+		return err
+	}
+
+	changedConfig, _ := b.detectChangedConfig(curBucket.Config, bucket.Config)
+	if len(changedConfig) > 0 {
+		if b.bucketMemberSpecific() {
+			for k := range changedConfig {
+				if k != "size" {
+					return fmt.Errorf("Bucket %q property cannot be changed", k)
+				}
+			}
+
+			err = b.driver.SetVolumeQuota(curVol, changedConfig["size"], false, op)
+			if err != nil {
+				return err
+			}
+		} else {
+			err = b.driver.UpdateBucket(curVol, changedConfig)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = b.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		return tx.UpdateStoragePoolBucket(b.id, curBucket.ID, &bucket)
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteBucket deletes the storage bucket and all of its contents.
+func (b *lxdBackend) DeleteBucket(projectName string, bucketName string, op *operations.Operation) error {
+	l := logger.AddContext(b.logger, logger.Ctx{"project": projectName, "bucketName": bucketName})
+	l.Debug("DeleteBucket started")
+	defer l.Debug("DeleteBucket finished")
+
+	bucket, err := b.getBucket(projectName, bucketName)
+	if err != nil {
+		return err
+	}
+
+	vol, err := b.getBucketVolume(projectName, bucketName, bucket.Config)
+	if err != nil {
+		return err
+	}
+
+	if b.bucketMemberSpecific() {
+		unlock := locking.Lock(drivers.OperationLockName("ActivateBucket", b.name, vol.Type(), vol.ContentType(), vol.Name()))
+		defer unlock()
+
+		err = miniod.Stop(vol.Name())
+		if err != nil {
+			return fmt.Errorf("Failed stopping bucket server: %w", err)
+		}
+
+		if b.driver.HasVolume(vol) {
+			_, err = b.driver.UnmountVolume(vol, false, op)
+			if err != nil {
+				return err
+			}
+
+			err = b.driver.DeleteVolume(vol, op)
+			if err != nil {
+				return err
+			}
+		}
+	} else {
+		err = b.driver.DeleteBucket(vol, op)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = b.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		return tx.DeleteStoragePoolBucket(b.id, bucket.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ActivateBucket mounts the local bucket volume and starts the S3 server for it if not already running.
+func (b *lxdBackend) ActivateBucket(projectName string, bucketName string, op *operations.Operation) (*miniod.Process, error) {
+	if !b.bucketMemberSpecific() {
+		return nil, fmt.Errorf("Remote buckets cannot be activated")
+	}
+
+	volStorageName := project.StorageVolume(projectName, bucketName)
+
+	p := miniod.Get(volStorageName)
+	if p != nil {
+		return p, nil
+	}
+
+	unlock := locking.Lock(drivers.OperationLockName("ActivateBucket", b.name, drivers.VolumeTypeBucket, drivers.ContentTypeFS, volStorageName))
+	defer unlock()
+
+	bucket, err := b.getBucket(projectName, bucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	vol, err := b.getBucketVolume(projectName, bucketName, bucket.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	err = b.driver.MountVolume(vol, op)
+	if err != nil {
+		return nil, err
+	}
+
+	return miniod.Start(b.state.ShutdownCtx, volStorageName, vol.MountPath(), bucketName)
+}
+
+// CreateBucketKey creates a new access key for the storage bucket.
+// Any access or secret key not supplied is randomly generated.
+func (b *lxdBackend) CreateBucketKey(projectName string, bucketName string, key api.StorageBucketKeysPost, op *operations.Operation) (*api.StorageBucketKey, error) {
+	l := logger.AddContext(b.logger, logger.Ctx{"project": projectName, "bucketName": bucketName, "keyName": key.Name, "role": key.Role})
+	l.Debug("CreateBucketKey started")
+	defer l.Debug("CreateBucketKey finished")
+
+	if key.Role == "" {
+		key.Role = s3.RoleReadOnly
+	}
+
+	creds := s3.Credentials{AccessKey: key.AccessKey, SecretKey: key.SecretKey}
+
+	err := b.driver.ValidateBucketKey(key.Name, creds, key.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket, err := b.getBucket(projectName, bucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	if b.bucketMemberSpecific() {
+		creds, err = s3.GenerateCredentials(creds)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		vol, err := b.getBucketVolume(projectName, bucketName, bucket.Config)
+		if err != nil {
+			return nil, err
+		}
+
+		newCreds, err := b.driver.CreateBucketKey(vol, key.Name, creds, key.Role, op)
+		if err != nil {
+			return nil, err
+		}
+
+		revert.Add(func() { _ = b.driver.DeleteBucketKey(vol, key.Name, op) })
+
+		creds = *newCreds
+	}
+
+	key.AccessKey = creds.AccessKey
+	key.SecretKey = creds.SecretKey
+
+	err = b.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		_, err := tx.CreateStoragePoolBucketKey(bucket.ID, key)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	revert.Success()
+
+	return &api.StorageBucketKey{
+		StorageBucketKeyPut: key.StorageBucketKeyPut,
+		Name:                key.Name,
+	}, nil
+}
+
+// UpdateBucketKey updates the storage bucket key's role and credentials.
+// Any access or secret key not supplied is randomly generated.
+func (b *lxdBackend) UpdateBucketKey(projectName string, bucketName string, keyName string, key api.StorageBucketKeyPut, op *operations.Operation) error {
+	l := logger.AddContext(b.logger, logger.Ctx{"project": projectName, "bucketName": bucketName, "keyName": keyName, "role": key.Role})
+	l.Debug("UpdateBucketKey started")
+	defer l.Debug("UpdateBucketKey finished")
+
+	creds := s3.Credentials{AccessKey: key.AccessKey, SecretKey: key.SecretKey}
+
+	err := b.driver.ValidateBucketKey(keyName, creds, key.Role)
+	if err != nil {
+		return err
+	}
+
+	bucket, err := b.getBucket(projectName, bucketName)
+	if err != nil {
+		return err
+	}
+
+	var curKey *db.StorageBucketKey
+	err = b.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		curKey, err = tx.GetStoragePoolBucketKey(bucket.ID, keyName)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if b.bucketMemberSpecific() {
+		creds, err = s3.GenerateCredentials(creds)
+		if err != nil {
+			return err
+		}
+	} else if curKey.StorageBucketKeyPut != key {
+		vol, err := b.getBucketVolume(projectName, bucketName, bucket.Config)
+		if err != nil {
+			return err
+		}
+
+		newCreds, err := b.driver.UpdateBucketKey(vol, keyName, creds, key.Role, op)
+		if err != nil {
+			return err
+		}
+
+		creds = *newCreds
+	}
+
+	key.AccessKey = creds.AccessKey
+	key.SecretKey = creds.SecretKey
+
+	err = b.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		return tx.UpdateStoragePoolBucketKey(bucket.ID, curKey.ID, &key)
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteBucketKey deletes the storage bucket key.
+func (b *lxdBackend) DeleteBucketKey(projectName string, bucketName string, keyName string, op *operations.Operation) error {
+	l := logger.AddContext(b.logger, logger.Ctx{"project": projectName, "bucketName": bucketName, "keyName": keyName})
+	l.Debug("DeleteBucketKey started")
+	defer l.Debug("DeleteBucketKey finished")
+
+	bucket, err := b.getBucket(projectName, bucketName)
+	if err != nil {
+		return err
+	}
+
+	var key *db.StorageBucketKey
+	err = b.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		key, err = tx.GetStoragePoolBucketKey(bucket.ID, keyName)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if !b.bucketMemberSpecific() {
+		vol, err := b.getBucketVolume(projectName, bucketName, bucket.Config)
+		if err != nil {
+			return err
+		}
+
+		err = b.driver.DeleteBucketKey(vol, keyName, op)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = b.state.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		return tx.DeleteStoragePoolBucketKey(bucket.ID, key.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"io"
+	"net/url"
 	"time"
 
 	"github.com/lxc/lxd/lxd/backup"
@@ -12,6 +13,7 @@ import (
 	"github.com/lxc/lxd/lxd/revert"
 	"github.com/lxc/lxd/lxd/state"
 	"github.com/lxc/lxd/lxd/storage/drivers"
+	"github.com/lxc/lxd/lxd/storage/s3/miniod"
 	"github.com/lxc/lxd/shared/api"
 	"github.com/lxc/lxd/shared/instancewriter"
 	"github.com/lxc/lxd/shared/logger"
@@ -133,6 +135,10 @@ func (b *mockBackend) DeleteInstance(inst instance.Instance, op *operations.Oper
 	return nil
 }
 
+func (b *mockBackend) EnsureInstanceSymlinks(inst instance.Instance) error {
+	return nil
+}
+
 func (b *mockBackend) UpdateInstance(inst instance.Instance, newDesc string, newConfig map[string]string, op *operations.Operation) error {
 	return nil
 }
@@ -197,6 +203,10 @@ func (b *mockBackend) DeleteInstanceSnapshot(inst instance.Instance, op *operati
 	return nil
 }
 
+func (b *mockBackend) RestoreInstanceBackupDelta(inst instance.Instance, srcData io.ReadSeeker, op *operations.Operation) error {
+	return nil
+}
+
 func (b *mockBackend) RestoreInstanceSnapshot(inst instance.Instance, src instance.Instance, op *operations.Operation) error {
 	return nil
 }
@@ -300,3 +310,35 @@ func (b *mockBackend) BackupCustomVolume(projectName string, volName string, tar
 func (b *mockBackend) CreateCustomVolumeFromBackup(srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) error {
 	return nil
 }
+
+func (b *mockBackend) CreateBucket(projectName string, bucket api.StorageBucketsPost, op *operations.Operation) error {
+	return nil
+}
+
+func (b *mockBackend) UpdateBucket(projectName string, bucketName string, bucket api.StorageBucketPut, op *operations.Operation) error {
+	return nil
+}
+
+func (b *mockBackend) DeleteBucket(projectName string, bucketName string, op *operations.Operation) error {
+	return nil
+}
+
+func (b *mockBackend) ActivateBucket(projectName string, bucketName string, op *operations.Operation) (*miniod.Process, error) {
+	return nil, nil
+}
+
+func (b *mockBackend) GetBucketURL(bucketName string) *url.URL {
+	return nil
+}
+
+func (b *mockBackend) CreateBucketKey(projectName string, bucketName string, key api.StorageBucketKeysPost, op *operations.Operation) (*api.StorageBucketKey, error) {
+	return nil, nil
+}
+
+func (b *mockBackend) UpdateBucketKey(projectName string, bucketName string, keyName string, key api.StorageBucketKeyPut, op *operations.Operation) error {
+	return nil
+}
+
+func (b *mockBackend) DeleteBucketKey(projectName string, bucketName string, keyName string, op *operations.Operation) error {
+	return nil
+}
@@ -90,11 +90,12 @@ func (d *btrfs) Info() Info {
 		OptimizedBackupHeader: true,
 		PreservesInodes:       !d.state.OS.RunningInUserNS,
 		Remote:                d.isRemote(),
-		VolumeTypes:           []VolumeType{VolumeTypeCustom, VolumeTypeImage, VolumeTypeContainer, VolumeTypeVM},
+		VolumeTypes:           []VolumeType{VolumeTypeCustom, VolumeTypeImage, VolumeTypeContainer, VolumeTypeVM, VolumeTypeBucket},
 		BlockBacking:          false,
 		RunningCopyFreeze:     false,
 		DirectIO:              true,
 		MountedRoot:           true,
+		Buckets:               true,
 	}
 }
 
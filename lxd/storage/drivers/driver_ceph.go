@@ -73,16 +73,18 @@ func (d *ceph) isRemote() bool {
 // Info returns info about the driver and its environment.
 func (d *ceph) Info() Info {
 	return Info{
-		Name:              "ceph",
-		Version:           cephVersion,
-		OptimizedImages:   true,
-		PreservesInodes:   false,
-		Remote:            d.isRemote(),
-		VolumeTypes:       []VolumeType{VolumeTypeCustom, VolumeTypeImage, VolumeTypeContainer, VolumeTypeVM},
-		BlockBacking:      true,
-		RunningCopyFreeze: true,
-		DirectIO:          true,
-		MountedRoot:       false,
+		Name:                "ceph",
+		Version:             cephVersion,
+		OptimizedImages:     true,
+		PreservesInodes:     false,
+		Remote:              d.isRemote(),
+		VolumeTypes:         []VolumeType{VolumeTypeCustom, VolumeTypeImage, VolumeTypeContainer, VolumeTypeVM},
+		LiveMigrationShared: true,
+		BlockBacking:        true,
+		RunningCopyFreeze:   true,
+		DirectIO:            true,
+		MountedRoot:         false,
+		Buckets:             d.config["ceph.rgw.endpoint"] != "",
 	}
 }
 
@@ -260,6 +262,7 @@ func (d *ceph) Validate(config map[string]string) error {
 		"ceph.rbd.clone_copy":        validate.Optional(validate.IsBool),
 		"ceph.rbd.du":                validate.Optional(validate.IsBool),
 		"ceph.rbd.features":          validate.IsAny,
+		"ceph.rgw.endpoint":          validate.Optional(validateHTTPURL),
 		"ceph.user.name":             validate.IsAny,
 		"volatile.pool.pristine":     validate.IsAny,
 		"volume.block.filesystem":    validate.Optional(validate.IsOneOf(cephAllowedFilesystems...)),
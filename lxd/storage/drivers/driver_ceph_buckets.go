@@ -0,0 +1,237 @@
+package drivers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/lxc/lxd/lxd/operations"
+	"github.com/lxc/lxd/lxd/revert"
+	"github.com/lxc/lxd/lxd/storage/s3"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/units"
+)
+
+// cephRadosgwUser represents the user info returned by radosgw-admin.
+type cephRadosgwUser struct {
+	UserID string `json:"user_id"`
+	Keys   []struct {
+		User      string `json:"user"`
+		AccessKey string `json:"access_key"`
+		SecretKey string `json:"secret_key"`
+	} `json:"keys"`
+}
+
+// validateHTTPURL validates that the value is an absolute HTTP or HTTPS URL.
+func validateHTTPURL(value string) error {
+	u, err := url.ParseRequestURI(value)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("Only HTTP and HTTPS URLs are supported")
+	}
+
+	return nil
+}
+
+// radosgwAdmin runs radosgw-admin against the pool's cluster using the pool's ceph user.
+func (d *ceph) radosgwAdmin(args ...string) (string, error) {
+	cmd := []string{"--cluster", d.config["ceph.cluster_name"], "--id", d.config["ceph.user.name"]}
+
+	return shared.RunCommand("radosgw-admin", append(cmd, args...)...)
+}
+
+// radosgwBucketUser returns the name of the radosgw user that owns the bucket.
+func (d *ceph) radosgwBucketUser(bucketName string) string {
+	return fmt.Sprintf("lxd-bucket-%s", bucketName)
+}
+
+// radosgwBucketSubuser returns the name of the radosgw subuser used for a bucket key.
+func (d *ceph) radosgwBucketSubuser(bucketName string, keyName string) string {
+	return fmt.Sprintf("%s:%s", d.radosgwBucketUser(bucketName), keyName)
+}
+
+// radosgwUserKeys parses the radosgw-admin user info output and returns the S3 credentials for the user.
+func (d *ceph) radosgwUserKeys(out string, userName string) (*s3.Credentials, error) {
+	user := cephRadosgwUser{}
+	err := json.Unmarshal([]byte(out), &user)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing radosgw user info: %w", err)
+	}
+
+	for _, key := range user.Keys {
+		if key.User == userName {
+			return &s3.Credentials{AccessKey: key.AccessKey, SecretKey: key.SecretKey}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("No S3 keys found for radosgw user %q", userName)
+}
+
+// radosgwBucketQuota applies the bucket size quota.
+func (d *ceph) radosgwBucketQuota(bucketName string, size string) error {
+	sizeBytes, err := units.ParseByteSizeString(size)
+	if err != nil {
+		return err
+	}
+
+	if sizeBytes <= 0 {
+		_, err = d.radosgwAdmin("quota", "disable", "--quota-scope=bucket", fmt.Sprintf("--bucket=%s", bucketName))
+		return err
+	}
+
+	_, err = d.radosgwAdmin("quota", "set", "--quota-scope=bucket", fmt.Sprintf("--bucket=%s", bucketName), fmt.Sprintf("--max-size=%d", sizeBytes))
+	if err != nil {
+		return err
+	}
+
+	_, err = d.radosgwAdmin("quota", "enable", "--quota-scope=bucket", fmt.Sprintf("--bucket=%s", bucketName))
+	return err
+}
+
+// GetBucketURL returns the URL of the specified bucket.
+func (d *ceph) GetBucketURL(bucketName string) *url.URL {
+	u, err := url.ParseRequestURI(d.config["ceph.rgw.endpoint"])
+	if err != nil {
+		return nil
+	}
+
+	u.Path = "/" + bucketName
+
+	return u
+}
+
+// CreateBucket creates a new bucket on the RADOS gateway.
+func (d *ceph) CreateBucket(bucket Volume, op *operations.Operation) error {
+	bucketURL := d.GetBucketURL(bucket.name)
+	if bucketURL == nil {
+		return fmt.Errorf("Invalid %q config", "ceph.rgw.endpoint")
+	}
+
+	_, err := d.radosgwAdmin("bucket", "stats", fmt.Sprintf("--bucket=%s", bucket.name))
+	if err == nil {
+		return fmt.Errorf("A bucket with name %q already exists", bucket.name)
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Create a dedicated user that owns the bucket, the bucket keys are created as subusers of it.
+	userName := d.radosgwBucketUser(bucket.name)
+	out, err := d.radosgwAdmin("user", "create", fmt.Sprintf("--uid=%s", userName), fmt.Sprintf("--display-name=%s", userName), "--max-buckets=1")
+	if err != nil {
+		return fmt.Errorf("Failed creating radosgw user: %w", err)
+	}
+
+	revert.Add(func() {
+		_, _ = d.radosgwAdmin("user", "rm", fmt.Sprintf("--uid=%s", userName), "--purge-keys", "--purge-data")
+	})
+
+	creds, err := d.radosgwUserKeys(out, userName)
+	if err != nil {
+		return err
+	}
+
+	bucketURL.Path = ""
+	client := s3.NewClient(bucketURL, *creds, nil)
+	err = client.CreateBucket(context.TODO(), bucket.name)
+	if err != nil {
+		return err
+	}
+
+	if bucket.config["size"] != "" {
+		err = d.radosgwBucketQuota(bucket.name, bucket.config["size"])
+		if err != nil {
+			return err
+		}
+	}
+
+	revert.Success()
+	return nil
+}
+
+// DeleteBucket deletes an existing bucket and its objects from the RADOS gateway.
+func (d *ceph) DeleteBucket(bucket Volume, op *operations.Operation) error {
+	_, err := d.radosgwAdmin("bucket", "rm", fmt.Sprintf("--bucket=%s", bucket.name), "--purge-objects")
+	if err != nil && !strings.Contains(err.Error(), "No such file or directory") {
+		return fmt.Errorf("Failed deleting bucket: %w", err)
+	}
+
+	_, err = d.radosgwAdmin("user", "rm", fmt.Sprintf("--uid=%s", d.radosgwBucketUser(bucket.name)), "--purge-keys")
+	if err != nil && !strings.Contains(err.Error(), "No such file or directory") {
+		return fmt.Errorf("Failed deleting radosgw user: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateBucket updates an existing bucket.
+func (d *ceph) UpdateBucket(bucket Volume, changedConfig map[string]string) error {
+	newSize, sizeChanged := changedConfig["size"]
+	if sizeChanged {
+		if newSize == "" {
+			newSize = "0"
+		}
+
+		err := d.radosgwBucketQuota(bucket.name, newSize)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateBucketKey creates a new bucket key as a subuser of the bucket user.
+func (d *ceph) CreateBucketKey(bucket Volume, keyName string, creds s3.Credentials, roleName string, op *operations.Operation) (*s3.Credentials, error) {
+	access := "full"
+	if roleName == s3.RoleReadOnly {
+		access = "read"
+	}
+
+	subuserName := d.radosgwBucketSubuser(bucket.name, keyName)
+	args := []string{"subuser", "create", fmt.Sprintf("--uid=%s", d.radosgwBucketUser(bucket.name)), fmt.Sprintf("--subuser=%s", subuserName), fmt.Sprintf("--access=%s", access), "--key-type=s3"}
+
+	if creds.AccessKey != "" {
+		args = append(args, fmt.Sprintf("--access-key=%s", creds.AccessKey))
+	} else {
+		args = append(args, "--gen-access-key")
+	}
+
+	if creds.SecretKey != "" {
+		args = append(args, fmt.Sprintf("--secret-key=%s", creds.SecretKey))
+	} else {
+		args = append(args, "--gen-secret")
+	}
+
+	out, err := d.radosgwAdmin(args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating radosgw subuser: %w", err)
+	}
+
+	return d.radosgwUserKeys(out, subuserName)
+}
+
+// UpdateBucketKey updates an existing bucket key by recreating its subuser.
+func (d *ceph) UpdateBucketKey(bucket Volume, keyName string, creds s3.Credentials, roleName string, op *operations.Operation) (*s3.Credentials, error) {
+	err := d.DeleteBucketKey(bucket, keyName, op)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.CreateBucketKey(bucket, keyName, creds, roleName, op)
+}
+
+// DeleteBucketKey deletes an existing bucket key.
+func (d *ceph) DeleteBucketKey(bucket Volume, keyName string, op *operations.Operation) error {
+	_, err := d.radosgwAdmin("subuser", "rm", fmt.Sprintf("--uid=%s", d.radosgwBucketUser(bucket.name)), fmt.Sprintf("--subuser=%s", d.radosgwBucketSubuser(bucket.name, keyName)), "--purge-keys")
+	if err != nil {
+		return fmt.Errorf("Failed deleting radosgw subuser: %w", err)
+	}
+
+	return nil
+}
@@ -2,13 +2,17 @@ package drivers
 
 import (
 	"fmt"
+	"net/url"
 	"os/exec"
 	"strings"
 
 	"github.com/lxc/lxd/lxd/migration"
+	"github.com/lxc/lxd/lxd/operations"
 	"github.com/lxc/lxd/lxd/state"
+	"github.com/lxc/lxd/lxd/storage/s3"
 	"github.com/lxc/lxd/shared"
 	"github.com/lxc/lxd/shared/logger"
+	"github.com/lxc/lxd/shared/validate"
 )
 
 type common struct {
@@ -261,3 +265,82 @@ func (d *common) runFiller(vol Volume, devPath string, filler *VolumeFiller) err
 	filler.Size = volSize
 	return nil
 }
+
+// ValidateBucket validates the supplied bucket config.
+func (d *common) ValidateBucket(bucket Volume) error {
+	return d.validateBucket(bucket, nil)
+}
+
+// validateBucket validates a bucket config against common rules and optional driver specific rules.
+func (d *common) validateBucket(bucket Volume, driverRules map[string]func(value string) error) error {
+	rules := map[string]func(value string) error{
+		"size": validate.Optional(validate.IsSize),
+	}
+
+	// Merge driver specific rules into common rules.
+	for field, validator := range driverRules {
+		rules[field] = validator
+	}
+
+	for k, v := range bucket.config {
+		validator, found := rules[k]
+		if !found {
+			return fmt.Errorf("Invalid option for bucket %q option %q", bucket.name, k)
+		}
+
+		err := validator(v)
+		if err != nil {
+			return fmt.Errorf("Invalid value for bucket %q option %q: %w", bucket.name, k, err)
+		}
+	}
+
+	return nil
+}
+
+// GetBucketURL returns the URL of the specified bucket.
+func (d *common) GetBucketURL(bucketName string) *url.URL {
+	return nil
+}
+
+// CreateBucket creates a new bucket.
+func (d *common) CreateBucket(bucket Volume, op *operations.Operation) error {
+	return ErrNotSupported
+}
+
+// DeleteBucket deletes an existing bucket.
+func (d *common) DeleteBucket(bucket Volume, op *operations.Operation) error {
+	return ErrNotSupported
+}
+
+// UpdateBucket updates an existing bucket.
+func (d *common) UpdateBucket(bucket Volume, changedConfig map[string]string) error {
+	return ErrNotSupported
+}
+
+// ValidateBucketKey validates the supplied bucket key config.
+func (d *common) ValidateBucketKey(keyName string, creds s3.Credentials, roleName string) error {
+	if keyName == "" {
+		return fmt.Errorf("Key name is required")
+	}
+
+	if strings.Contains(keyName, "/") || strings.Contains(keyName, ":") {
+		return fmt.Errorf("Key name cannot contain %q or %q", "/", ":")
+	}
+
+	return s3.ValidateRole(roleName)
+}
+
+// CreateBucketKey creates a new bucket key.
+func (d *common) CreateBucketKey(bucket Volume, keyName string, creds s3.Credentials, roleName string, op *operations.Operation) (*s3.Credentials, error) {
+	return nil, ErrNotSupported
+}
+
+// UpdateBucketKey updates an existing bucket key.
+func (d *common) UpdateBucketKey(bucket Volume, keyName string, creds s3.Credentials, roleName string, op *operations.Operation) (*s3.Credentials, error) {
+	return nil, ErrNotSupported
+}
+
+// DeleteBucketKey deletes an existing bucket key.
+func (d *common) DeleteBucketKey(bucket Volume, keyName string, op *operations.Operation) error {
+	return ErrNotSupported
+}
@@ -34,11 +34,12 @@ func (d *dir) Info() Info {
 		OptimizedImages:   false,
 		PreservesInodes:   false,
 		Remote:            d.isRemote(),
-		VolumeTypes:       []VolumeType{VolumeTypeCustom, VolumeTypeImage, VolumeTypeContainer, VolumeTypeVM},
+		VolumeTypes:       []VolumeType{VolumeTypeCustom, VolumeTypeImage, VolumeTypeContainer, VolumeTypeVM, VolumeTypeBucket},
 		BlockBacking:      false,
 		RunningCopyFreeze: true,
 		DirectIO:          true,
 		MountedRoot:       true,
+		Buckets:           true,
 	}
 }
 
@@ -38,7 +38,7 @@ func (d *dir) setupInitialQuota(vol Volume) (func(), error) {
 	defer revert.Fail()
 
 	// Define a function to revert the quota being setup.
-	revertFunc := func() { d.deleteQuota(volPath, volID) }
+	revertFunc := func() { d.deleteQuota(volPath, vol.volType, volID) }
 	revert.Add(revertFunc)
 
 	// Initialise the volume's project using the volume ID and set the quota.
@@ -47,7 +47,7 @@ func (d *dir) setupInitialQuota(vol Volume) (func(), error) {
 		return nil, err
 	}
 
-	err = d.setQuota(volPath, volID, sizeBytes)
+	err = d.setQuota(volPath, vol.volType, volID, sizeBytes)
 	if err != nil {
 		return nil, err
 	}
@@ -57,7 +57,7 @@ func (d *dir) setupInitialQuota(vol Volume) (func(), error) {
 }
 
 // deleteQuota removes the project quota for a volID from a path.
-func (d *dir) deleteQuota(path string, volID int64) error {
+func (d *dir) deleteQuota(path string, volType VolumeType, volID int64) error {
 	if volID == volIDQuotaSkip {
 		// Disabled on purpose, just ignore
 		return nil
@@ -73,7 +73,7 @@ func (d *dir) deleteQuota(path string, volID int64) error {
 		return nil
 	}
 
-	err = quota.DeleteProject(path, d.quotaProjectID(volID))
+	err = quota.DeleteProject(path, d.quotaProjectID(volType, volID))
 	if err != nil {
 		return err
 	}
@@ -82,17 +82,22 @@ func (d *dir) deleteQuota(path string, volID int64) error {
 }
 
 // quotaProjectID generates a project quota ID from a volume ID.
-func (d *dir) quotaProjectID(volID int64) uint32 {
+// Bucket volume IDs come from a different table than other volume types, so they use a separate range of IDs.
+func (d *dir) quotaProjectID(volType VolumeType, volID int64) uint32 {
 	if volID == volIDQuotaSkip {
 		// Disabled on purpose, just ignore
 		return 0
 	}
 
+	if volType == VolumeTypeBucket {
+		return uint32(volID + 2000000000)
+	}
+
 	return uint32(volID + 10000)
 }
 
 // setQuota sets the project quota on the path. The volID generates a quota project ID.
-func (d *dir) setQuota(path string, volID int64, sizeBytes int64) error {
+func (d *dir) setQuota(path string, volType VolumeType, volID int64, sizeBytes int64) error {
 	if volID == volIDQuotaSkip {
 		// Disabled on purpose, just ignore.
 		return nil
@@ -112,14 +117,14 @@ func (d *dir) setQuota(path string, volID int64, sizeBytes int64) error {
 		return nil
 	}
 
-	projectID := d.quotaProjectID(volID)
+	projectID := d.quotaProjectID(volType, volID)
 	currentProjectID, err := quota.GetProject(path)
 	if err != nil {
 		return err
 	}
 
 	// Remove current project if desired project ID is different.
-	if currentProjectID != d.quotaProjectID(volID) {
+	if currentProjectID != d.quotaProjectID(volType, volID) {
 		err = quota.DeleteProject(path, currentProjectID)
 		if err != nil {
 			return err
@@ -180,7 +180,7 @@ func (d *dir) DeleteVolume(vol Volume, op *operations.Operation) error {
 	}
 
 	// Remove the project quota.
-	err = d.deleteQuota(volPath, volID)
+	err = d.deleteQuota(volPath, vol.volType, volID)
 	if err != nil {
 		return err
 	}
@@ -243,7 +243,7 @@ func (d *dir) GetVolumeUsage(vol Volume) (int64, error) {
 		return -1, err
 	}
 
-	projectID := d.quotaProjectID(volID)
+	projectID := d.quotaProjectID(vol.volType, volID)
 
 	// Get project quota used.
 	size, err := quota.GetProjectUsage(volPath, projectID)
@@ -313,7 +313,7 @@ func (d *dir) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 		d.logger.Debug("Accounting for VM image file size", logger.Ctx{"sizeBytes": sizeBytes})
 	}
 
-	return d.setQuota(vol.MountPath(), volID, sizeBytes)
+	return d.setQuota(vol.MountPath(), vol.volType, volID, sizeBytes)
 }
 
 // GetVolumeDiskPath returns the location of a disk volume.
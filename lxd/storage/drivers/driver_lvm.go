@@ -27,6 +27,9 @@ var lvmAllowedFilesystems = []string{"btrfs", "ext4", "xfs"}
 
 type lvm struct {
 	common
+
+	// clustered indicates the pool uses a shared volume group managed by lvmlockd.
+	clustered bool
 }
 
 func (d *lvm) load() error {
@@ -35,6 +38,14 @@ func (d *lvm) load() error {
 		"storage_lvm_skipactivation": d.patchStorageSkipActivation,
 	}
 
+	// Shared volume groups need lvmlockd to be running.
+	if d.clustered {
+		_, err := exec.LookPath("lvmlockctl")
+		if err != nil {
+			return fmt.Errorf("Required tool %q is missing", "lvmlockctl")
+		}
+	}
+
 	// Done if previously loaded.
 	if lvmLoaded {
 		return nil
@@ -78,19 +89,30 @@ func (d *lvm) load() error {
 	return nil
 }
 
+// isRemote returns true when the pool uses a shared volume group.
+func (d *lvm) isRemote() bool {
+	return d.clustered
+}
+
 // Info returns info about the driver and its environment.
 func (d *lvm) Info() Info {
+	name := "lvm"
+	if d.clustered {
+		name = "lvmcluster"
+	}
+
 	return Info{
-		Name:              "lvm",
+		Name:              name,
 		Version:           lvmVersion,
 		OptimizedImages:   d.usesThinpool(), // Only thinpool pools support optimized images.
 		PreservesInodes:   false,
 		Remote:            d.isRemote(),
-		VolumeTypes:       []VolumeType{VolumeTypeCustom, VolumeTypeImage, VolumeTypeContainer, VolumeTypeVM},
+		VolumeTypes:       []VolumeType{VolumeTypeCustom, VolumeTypeImage, VolumeTypeContainer, VolumeTypeVM, VolumeTypeBucket},
 		BlockBacking:      true,
 		RunningCopyFreeze: true,
 		DirectIO:          true,
 		MountedRoot:       false,
+		Buckets:           !d.clustered, // Bucket volumes are mounted on a single member.
 	}
 }
 
@@ -113,6 +135,11 @@ func (d *lvm) Create() error {
 	}
 
 	if d.config["source"] == "" || d.config["source"] == defaultSource {
+		// Loop files are local to a single member so can't back a shared volume group.
+		if d.clustered {
+			return fmt.Errorf("Loop backed pools are not supported by the %q driver", d.Info().Name)
+		}
+
 		// We are using a LXD internal loopback file.
 		d.config["source"] = defaultSource
 		if d.config["lvm.vg_name"] == "" {
@@ -218,6 +245,18 @@ func (d *lvm) Create() error {
 		if !vgExists {
 			return fmt.Errorf("The requested volume group %q does not exist", d.config["lvm.vg_name"])
 		}
+
+		// Check the volume group can be accessed from multiple members.
+		if d.clustered {
+			lockType, err := d.volumeGroupLockType(d.config["lvm.vg_name"])
+			if err != nil {
+				return err
+			}
+
+			if !shared.StringInSlice(lockType, []string{"dlm", "sanlock"}) {
+				return fmt.Errorf("Volume group %q is not a shared volume group", d.config["lvm.vg_name"])
+			}
+		}
 	} else {
 		return fmt.Errorf("Invalid source property")
 	}
@@ -289,7 +328,12 @@ func (d *lvm) Create() error {
 		}
 
 		// Create volume group.
-		_, err := shared.TryRunCommand("vgcreate", d.config["lvm.vg_name"], pvName)
+		args := []string{d.config["lvm.vg_name"], pvName}
+		if d.clustered {
+			args = append([]string{"--shared"}, args...)
+		}
+
+		_, err := shared.TryRunCommand("vgcreate", args...)
 		if err != nil {
 			return err
 		}
@@ -297,6 +341,14 @@ func (d *lvm) Create() error {
 		revert.Add(func() { shared.TryRunCommand("vgremove", d.config["lvm.vg_name"]) })
 	}
 
+	// Start the lockspace so that logical volumes can be created and activated.
+	if d.clustered {
+		err = d.startVolumeGroupLock(d.config["lvm.vg_name"])
+		if err != nil {
+			return err
+		}
+	}
+
 	// Create thin pool if needed.
 	if d.usesThinpool() && !thinPoolExists {
 		err = d.createDefaultThinPool(d.Info().Version, d.config["lvm.vg_name"], d.thinpoolName(), d.config["lvm.thinpool_metadata_size"])
@@ -454,6 +506,21 @@ func (d *lvm) Validate(config map[string]string) error {
 		return err
 	}
 
+	// Thin pools can't be shared between members.
+	if d.clustered {
+		if shared.IsTrue(config["lvm.use_thinpool"]) {
+			return fmt.Errorf("The key lvm.use_thinpool cannot be set to true for the %q driver", d.Info().Name)
+		}
+
+		if config["lvm.thinpool_name"] != "" {
+			return fmt.Errorf("The key lvm.thinpool_name cannot be used with the %q driver", d.Info().Name)
+		}
+
+		if config["lvm.thinpool_metadata_size"] != "" {
+			return fmt.Errorf("The key lvm.thinpool_metadata_size cannot be used with the %q driver", d.Info().Name)
+		}
+	}
+
 	if shared.IsFalse(config["lvm.use_thinpool"]) {
 		if config["lvm.thinpool_name"] != "" {
 			return fmt.Errorf("The key lvm.use_thinpool cannot be set to false when lvm.thinpool_name is set")
@@ -507,6 +574,18 @@ func (d *lvm) Update(changedConfig map[string]string) error {
 // Mount mounts the storage pool (for loopback image pools this creates a loop device), and checks the volume group
 // and thin pool volume (if used) exists.
 func (d *lvm) Mount() (bool, error) {
+	// Only the member creating a shared volume group runs Create, so the others need to work out the volume
+	// group name from their own config using the same defaults.
+	if d.clustered && d.config["lvm.vg_name"] == "" && d.config["source"] != "" {
+		if filepath.IsAbs(d.config["source"]) {
+			d.config["lvm.vg_name"] = d.name
+		} else {
+			d.config["lvm.vg_name"] = d.config["source"]
+		}
+
+		d.config["source"] = d.config["lvm.vg_name"]
+	}
+
 	if d.config["lvm.vg_name"] == "" {
 		return false, fmt.Errorf("Cannot mount pool as %q is not specified", "lvm.vg_name")
 	}
@@ -550,6 +629,14 @@ func (d *lvm) Mount() (bool, error) {
 		return false, fmt.Errorf("Volume group %s not found", d.config["lvm.vg_name"])
 	}
 
+	// Join the volume group's lockspace so volumes can be activated on this member.
+	if d.clustered {
+		err := d.startVolumeGroupLock(d.config["lvm.vg_name"])
+		if err != nil {
+			return false, err
+		}
+	}
+
 	// Ensure thinpool exists if needed for storage pool.
 	if d.usesThinpool() {
 		waitUntil := time.Now().Add(waitDuration)
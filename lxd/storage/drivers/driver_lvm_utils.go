@@ -35,6 +35,11 @@ var errLVMNotFound = fmt.Errorf("Not found")
 
 // usesThinpool indicates whether the config specifies to use a thin pool or not.
 func (d *lvm) usesThinpool() bool {
+	// Shared volume groups don't support thin pools.
+	if d.clustered {
+		return false
+	}
+
 	// Default is to use a thinpool.
 	return shared.IsTrueOrEmpty(d.config["lvm.use_thinpool"])
 }
@@ -124,6 +129,26 @@ func (d *lvm) volumeGroupExists(vgName string) (bool, []string, error) {
 	return true, tags, nil
 }
 
+// volumeGroupLockType returns the lock type of an LVM Volume Group ("dlm" or "sanlock" for shared volume groups).
+func (d *lvm) volumeGroupLockType(vgName string) (string, error) {
+	output, err := shared.RunCommand("vgs", "--noheadings", "-o", "vg_lock_type", vgName)
+	if err != nil {
+		return "", fmt.Errorf("Error getting lock type of LVM volume group %q: %w", vgName, err)
+	}
+
+	return strings.TrimSpace(output), nil
+}
+
+// startVolumeGroupLock starts the lvmlockd lockspace of a shared LVM Volume Group on this host.
+func (d *lvm) startVolumeGroupLock(vgName string) error {
+	_, err := shared.TryRunCommand("vgchange", "--lock-start", vgName)
+	if err != nil {
+		return fmt.Errorf("Error starting lockspace of LVM volume group %q: %w", vgName, err)
+	}
+
+	return nil
+}
+
 // volumeGroupExtentSize gets the volume group's physical extent size in bytes.
 func (d *lvm) volumeGroupExtentSize(vgName string) (int64, error) {
 	output, err := shared.RunCommand("vgs", "--noheadings", "--nosuffix", "--units", "b", "-o", "vg_extent_size", vgName)
@@ -466,6 +491,49 @@ func (d *lvm) renameLogicalVolume(volDevPath string, newVolDevPath string) error
 	return nil
 }
 
+// renameLogicalVolumes renames the logical volumes of a volume and its snapshots without touching the volume's
+// mount paths. For VMs, the filesystem volume's logical volumes are renamed too.
+func (d *lvm) renameLogicalVolumes(vol Volume, newVolName string, op *operations.Operation) error {
+	snapNames, err := d.VolumeSnapshots(vol, op)
+	if err != nil {
+		return err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	for _, snapName := range snapNames {
+		snapVolDevPath := d.lvmDevPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, GetSnapshotVolumeName(vol.name, snapName))
+		newSnapVolDevPath := d.lvmDevPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, GetSnapshotVolumeName(newVolName, snapName))
+		err = d.renameLogicalVolume(snapVolDevPath, newSnapVolDevPath)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() { d.renameLogicalVolume(newSnapVolDevPath, snapVolDevPath) })
+	}
+
+	volDevPath := d.lvmDevPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, vol.name)
+	newVolDevPath := d.lvmDevPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, newVolName)
+	err = d.renameLogicalVolume(volDevPath, newVolDevPath)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() { d.renameLogicalVolume(newVolDevPath, volDevPath) })
+
+	if vol.IsVMBlock() {
+		fsVol := vol.NewVMBlockFilesystemVolume()
+		err = d.renameLogicalVolumes(fsVol, newVolName, op)
+		if err != nil {
+			return err
+		}
+	}
+
+	revert.Success()
+	return nil
+}
+
 // lvmFullVolumeName returns the logical volume's full name with volume type prefix. It also converts the supplied
 // volName to a name suitable for use as a logical volume using volNameToLVName(). If an empty volType is passed
 // then just the volName is returned. If an invalid volType is passed then an empty string is returned.
@@ -753,7 +821,13 @@ func (d *lvm) activateVolume(vol Volume) (bool, error) {
 	}
 
 	if !shared.PathExists(volDevPath) {
-		_, err := shared.RunCommand("lvchange", "--activate", "y", "--ignoreactivationskip", volDevPath)
+		// Take an exclusive lock on shared volume groups so the volume can only be active on one member.
+		activation := "y"
+		if d.clustered {
+			activation = "ey"
+		}
+
+		_, err := shared.RunCommand("lvchange", "--activate", activation, "--ignoreactivationskip", volDevPath)
 		if err != nil {
 			return false, fmt.Errorf("Failed to activate LVM logical volume %q: %w", volDevPath, err)
 		}
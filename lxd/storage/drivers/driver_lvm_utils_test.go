@@ -66,3 +66,14 @@ func Example_lvm_parseLogicalVolumeName() {
 	// custom_proj_testvol--with--hyphens.block: Unrecognised
 	// custom_proj_testvol--with--hyphens.block-snap1--with--hyphens.block: snap1-with-hyphens.block
 }
+
+func Example_lvm_clustered() {
+	for _, d := range []*lvm{{}, {clustered: true}} {
+		d.config = map[string]string{"lvm.use_thinpool": "true"}
+		info := d.Info()
+		fmt.Printf("%s: remote=%v thinpool=%v buckets=%v\n", info.Name, info.Remote, d.usesThinpool(), info.Buckets)
+	}
+
+	// Output: lvm: remote=false thinpool=true buckets=true
+	// lvmcluster: remote=true thinpool=false buckets=false
+}
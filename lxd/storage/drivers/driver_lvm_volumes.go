@@ -258,6 +258,17 @@ func (d *lvm) FillVolumeConfig(vol Volume) error {
 	return nil
 }
 
+// ValidateBucket validates the supplied bucket config.
+// The filesystem keys are allowed as local buckets are stored on a filesystem volume.
+func (d *lvm) ValidateBucket(bucket Volume) error {
+	rules := map[string]func(value string) error{
+		"block.filesystem":    validate.Optional(validate.IsOneOf(lvmAllowedFilesystems...)),
+		"block.mount_options": validate.IsAny,
+	}
+
+	return d.validateBucket(bucket, rules)
+}
+
 // ValidateVolume validates the supplied volume config.
 func (d *lvm) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
 	rules := map[string]func(value string) error{
@@ -764,6 +775,37 @@ func (d *lvm) RenameVolume(vol Volume, newVolName string, op *operations.Operati
 
 // MigrateVolume sends a volume for migration.
 func (d *lvm) MigrateVolume(vol Volume, conn io.ReadWriteCloser, volSrcArgs *migration.VolumeSourceArgs, op *operations.Operation) error {
+	// If data is set, this request is coming from the clustering code.
+	// In this case, we only need to deactivate the volume (releasing its lock) and rename it if needed, as the
+	// shared volume group is visible from all cluster members.
+	if d.clustered && volSrcArgs.Data != nil {
+		data, ok := volSrcArgs.Data.(string)
+		if ok {
+			_, err := d.deactivateVolume(vol)
+			if err != nil {
+				return err
+			}
+
+			if vol.IsVMBlock() {
+				fsVol := vol.NewVMBlockFilesystemVolume()
+				_, err = d.deactivateVolume(fsVol)
+				if err != nil {
+					return err
+				}
+			}
+
+			// Rename the logical volumes only, the mount paths are created on the target member.
+			if vol.name != data {
+				err = d.renameLogicalVolumes(vol, data, op)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+	}
+
 	return genericVFSMigrateVolume(d, d.state, vol, conn, volSrcArgs, op)
 }
 
@@ -7,6 +7,7 @@ type Info struct {
 	VolumeTypes           []VolumeType // Supported volume types.
 	Remote                bool         // Whether the driver uses a remote backing store.
 	VolumeMultiNode       bool         // Whether volumes can be used on multiple nodes concurrently.
+	LiveMigrationShared   bool         // Whether instance volumes can be used by a live migration target while still in use.
 	OptimizedImages       bool         // Whether driver stores images as separate volume.
 	OptimizedBackups      bool         // Whether driver supports optimized volume backups.
 	OptimizedBackupHeader bool         // Whether driver generates an optimised backup header file in backup.
@@ -15,6 +16,7 @@ type Info struct {
 	RunningCopyFreeze     bool         // Whether instance should be frozen during snapshot if running.
 	DirectIO              bool         // Whether the driver supports direct I/O.
 	MountedRoot           bool         // Whether the pool directory itself is a mount.
+	Buckets               bool         // Whether the driver supports S3 storage buckets.
 }
 
 // VolumeFiller provides a struct for filling a volume.
@@ -102,11 +102,12 @@ func (d *zfs) Info() Info {
 		OptimizedBackups:  true,
 		PreservesInodes:   true,
 		Remote:            d.isRemote(),
-		VolumeTypes:       []VolumeType{VolumeTypeCustom, VolumeTypeImage, VolumeTypeContainer, VolumeTypeVM},
+		VolumeTypes:       []VolumeType{VolumeTypeCustom, VolumeTypeImage, VolumeTypeContainer, VolumeTypeVM, VolumeTypeBucket},
 		BlockBacking:      false,
 		RunningCopyFreeze: false,
 		DirectIO:          zfsDirectIO,
 		MountedRoot:       false,
+		Buckets:           true,
 	}
 
 	return info
@@ -51,6 +51,17 @@ func (d *zfs) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Oper
 		revert.Add(func() { os.Remove(vol.MountPath()) })
 	}
 
+	// Pools created before bucket support was added don't have the parent bucket dataset.
+	if vol.volType == VolumeTypeBucket {
+		parentDataset := filepath.Join(d.config["zfs.pool_name"], BaseDirectories[VolumeTypeBucket][0])
+		if !d.checkDataset(parentDataset) {
+			err := d.createDataset(parentDataset, "mountpoint=legacy")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	// Look for previously deleted images.
 	if vol.volType == VolumeTypeImage && d.checkDataset(d.dataset(vol, true)) {
 		canRestore := true
@@ -2,12 +2,14 @@ package drivers
 
 import (
 	"io"
+	"net/url"
 
 	"github.com/lxc/lxd/lxd/backup"
 	"github.com/lxc/lxd/lxd/migration"
 	"github.com/lxc/lxd/lxd/operations"
 	"github.com/lxc/lxd/lxd/revert"
 	"github.com/lxc/lxd/lxd/state"
+	"github.com/lxc/lxd/lxd/storage/s3"
 	"github.com/lxc/lxd/shared/api"
 	"github.com/lxc/lxd/shared/instancewriter"
 	"github.com/lxc/lxd/shared/logger"
@@ -89,4 +91,15 @@ type Driver interface {
 	// Backup.
 	BackupVolume(vol Volume, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots []string, op *operations.Operation) error
 	CreateVolumeFromBackup(vol Volume, srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) (VolumePostHook, revert.Hook, error)
+
+	// Buckets.
+	ValidateBucket(bucket Volume) error
+	GetBucketURL(bucketName string) *url.URL
+	CreateBucket(bucket Volume, op *operations.Operation) error
+	DeleteBucket(bucket Volume, op *operations.Operation) error
+	UpdateBucket(bucket Volume, changedConfig map[string]string) error
+	ValidateBucketKey(keyName string, creds s3.Credentials, roleName string) error
+	CreateBucketKey(bucket Volume, keyName string, creds s3.Credentials, roleName string, op *operations.Operation) (*s3.Credentials, error)
+	UpdateBucketKey(bucket Volume, keyName string, creds s3.Credentials, roleName string, op *operations.Operation) (*s3.Credentials, error)
+	DeleteBucketKey(bucket Volume, keyName string, op *operations.Operation) error
 }
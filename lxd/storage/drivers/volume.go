@@ -53,6 +53,9 @@ const VolumeTypeContainer = VolumeType("containers")
 // VolumeTypeVM represents a virtual-machine storage volume.
 const VolumeTypeVM = VolumeType("virtual-machines")
 
+// VolumeTypeBucket represents a bucket storage volume.
+const VolumeTypeBucket = VolumeType("buckets")
+
 // ContentType indicates the format of the volume.
 type ContentType string
 
@@ -68,6 +71,7 @@ type VolumePostHook func(vol Volume) error
 
 // BaseDirectories maps volume types to the expected directories.
 var BaseDirectories = map[VolumeType][]string{
+	VolumeTypeBucket:    {"buckets"},
 	VolumeTypeContainer: {"containers", "containers-snapshots"},
 	VolumeTypeCustom:    {"custom", "custom-snapshots"},
 	VolumeTypeImage:     {"images"},
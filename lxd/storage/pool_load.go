@@ -3,6 +3,7 @@ package storage
 import (
 	"fmt"
 
+	"github.com/lxc/lxd/lxd/db"
 	"github.com/lxc/lxd/lxd/instance"
 	"github.com/lxc/lxd/lxd/project"
 	"github.com/lxc/lxd/lxd/response"
@@ -22,6 +23,32 @@ const PoolIDTemporary = -1
 func volIDFuncMake(state *state.State, poolID int64) func(volType drivers.VolumeType, volName string) (int64, error) {
 	// Return a function to retrieve a volume ID for a volume Name for use in driver.
 	return func(volType drivers.VolumeType, volName string) (int64, error) {
+		// Buckets are stored separately from the other volume types so use the bucket ID.
+		if volType == drivers.VolumeTypeBucket {
+			projectName, bucketName := project.StorageVolumeParts(volName)
+
+			var bucketID int64
+			err := state.Cluster.Transaction(func(tx *db.ClusterTx) error {
+				bucket, err := tx.GetStoragePoolBucket(poolID, projectName, true, bucketName)
+				if err != nil {
+					return err
+				}
+
+				bucketID = bucket.ID
+
+				return nil
+			})
+			if err != nil {
+				if response.IsNotFoundError(err) {
+					return -1, fmt.Errorf("Failed to get bucket ID for project %q, bucket %q: Bucket doesn't exist", projectName, bucketName)
+				}
+
+				return -1, err
+			}
+
+			return bucketID, nil
+		}
+
 		volTypeID, err := VolumeTypeToDBType(volType)
 		if err != nil {
 			return -1, err
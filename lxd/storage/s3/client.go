@@ -0,0 +1,123 @@
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Error represents an S3 error response.
+type Error struct {
+	XMLName   xml.Name `xml:"Error"`
+	Code      string   `xml:"Code"`
+	Message   string   `xml:"Message"`
+	Resource  string   `xml:"Resource,omitempty"`
+	RequestID string   `xml:"RequestId,omitempty"`
+}
+
+// Error returns the error message.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Response writes the error to the HTTP response writer using the supplied status code.
+func (e *Error) Response(w http.ResponseWriter, statusCode int) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write([]byte(xml.Header))
+	_ = xml.NewEncoder(w).Encode(e)
+}
+
+// Client is a minimal S3 client used for managing buckets on an S3 compatible server.
+type Client struct {
+	endpoint   *url.URL
+	creds      Credentials
+	httpClient *http.Client
+}
+
+// NewClient returns a new S3 client for the server at the endpoint URL using the supplied credentials.
+// If httpClient is nil then http.DefaultClient is used.
+func NewClient(endpoint *url.URL, creds Credentials, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Client{
+		endpoint:   endpoint,
+		creds:      creds,
+		httpClient: httpClient,
+	}
+}
+
+// CreateBucket creates a bucket. If the bucket already exists and is owned by the client's credentials then no
+// error is returned.
+func (c *Client) CreateBucket(ctx context.Context, bucketName string) error {
+	err := c.bucketRequest(ctx, http.MethodPut, bucketName)
+	if err != nil {
+		s3Err, ok := err.(*Error)
+		if ok && s3Err.Code == "BucketAlreadyOwnedByYou" {
+			return nil
+		}
+
+		return fmt.Errorf("Failed creating bucket %q: %w", bucketName, err)
+	}
+
+	return nil
+}
+
+// DeleteBucket deletes a bucket. If the bucket doesn't exist then no error is returned.
+func (c *Client) DeleteBucket(ctx context.Context, bucketName string) error {
+	err := c.bucketRequest(ctx, http.MethodDelete, bucketName)
+	if err != nil {
+		s3Err, ok := err.(*Error)
+		if ok && s3Err.Code == "NoSuchBucket" {
+			return nil
+		}
+
+		return fmt.Errorf("Failed deleting bucket %q: %w", bucketName, err)
+	}
+
+	return nil
+}
+
+// bucketRequest performs a signed request with an empty body against the bucket.
+func (c *Client) bucketRequest(ctx context.Context, method string, bucketName string) error {
+	u := *c.endpoint
+	u.Path = "/" + bucketName
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	// SHA256 hash of an empty body.
+	Sign(req, c.creds, "", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", time.Now())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	s3Err := &Error{}
+	err = xml.Unmarshal(body, s3Err)
+	if err != nil || s3Err.Code == "" {
+		return fmt.Errorf("Unexpected response status %d", resp.StatusCode)
+	}
+
+	return s3Err
+}
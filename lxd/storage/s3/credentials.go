@@ -0,0 +1,121 @@
+package s3
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// RoleAdmin is the role name used for keys with full read and write access to a bucket.
+const RoleAdmin = "admin"
+
+// RoleReadOnly is the role name used for keys with read-only access to a bucket.
+const RoleReadOnly = "read-only"
+
+// Roles is the list of valid bucket key role names.
+var Roles = []string{RoleAdmin, RoleReadOnly}
+
+// Credentials represents a set of S3 access credentials.
+type Credentials struct {
+	AccessKey string
+	SecretKey string
+}
+
+// keyChars is the set of characters used when generating access and secret keys.
+const keyChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+// randomKey returns a random string of the specified length using characters from keyChars.
+func randomKey(length int) (string, error) {
+	var sb strings.Builder
+
+	max := big.NewInt(int64(len(keyChars)))
+	for i := 0; i < length; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("Failed generating random key: %w", err)
+		}
+
+		sb.WriteByte(keyChars[n.Int64()])
+	}
+
+	return sb.String(), nil
+}
+
+// GenerateCredentials fills in any missing access or secret key in the supplied credentials with a random value.
+func GenerateCredentials(creds Credentials) (Credentials, error) {
+	var err error
+
+	if creds.AccessKey == "" {
+		creds.AccessKey, err = randomKey(20)
+		if err != nil {
+			return creds, err
+		}
+	}
+
+	if creds.SecretKey == "" {
+		creds.SecretKey, err = randomKey(40)
+		if err != nil {
+			return creds, err
+		}
+	}
+
+	return creds, nil
+}
+
+// ValidateBucketName checks that the bucket name is a valid S3 bucket name.
+// Only lower case letters, numbers, hyphens and dots are allowed, and the name must start and end with a letter
+// or number. Names formatted as IP addresses are not allowed.
+func ValidateBucketName(bucketName string) error {
+	if len(bucketName) < 3 || len(bucketName) > 63 {
+		return fmt.Errorf("Bucket name must be between 3 and 63 characters long")
+	}
+
+	for _, r := range bucketName {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '.' {
+			return fmt.Errorf("Bucket name can only contain lower case letters, numbers, hyphens and dots")
+		}
+	}
+
+	isAlphaNumeric := func(b byte) bool {
+		return (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9')
+	}
+
+	if !isAlphaNumeric(bucketName[0]) || !isAlphaNumeric(bucketName[len(bucketName)-1]) {
+		return fmt.Errorf("Bucket name must start and end with a letter or number")
+	}
+
+	if strings.Contains(bucketName, "..") {
+		return fmt.Errorf("Bucket name cannot contain two adjacent dots")
+	}
+
+	parts := strings.Split(bucketName, ".")
+	if len(parts) == 4 {
+		isIP := true
+		for _, part := range parts {
+			for _, r := range part {
+				if r < '0' || r > '9' {
+					isIP = false
+					break
+				}
+			}
+		}
+
+		if isIP {
+			return fmt.Errorf("Bucket name cannot be formatted as an IP address")
+		}
+	}
+
+	return nil
+}
+
+// ValidateRole checks that the role name is valid.
+func ValidateRole(roleName string) error {
+	for _, role := range Roles {
+		if roleName == role {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Invalid key role %q", roleName)
+}
@@ -0,0 +1,236 @@
+package miniod
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/lxc/lxd/lxd/storage/s3"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/logger"
+)
+
+// minioHost is the address the MinIO processes listen on.
+const minioHost = "127.0.0.1"
+
+// minioAdminUser is the name of the MinIO root user.
+const minioAdminUser = "lxd-admin"
+
+// minioStartTimeout is the time to wait for a MinIO process to become ready.
+const minioStartTimeout = 10 * time.Second
+
+// minioLock protects the minios map.
+var minioLock sync.Mutex
+
+// minios contains the running MinIO processes indexed by volume name.
+var minios = make(map[string]*Process)
+
+// Process represents a running MinIO process serving a single bucket.
+type Process struct {
+	cmd        *exec.Cmd
+	exited     chan struct{}
+	url        url.URL
+	creds      s3.Credentials
+	bucketName string
+}
+
+// URL returns the URL of the MinIO process.
+func (p *Process) URL() url.URL {
+	return p.url
+}
+
+// AdminCredentials returns the credentials of the MinIO root user.
+func (p *Process) AdminCredentials() s3.Credentials {
+	return p.creds
+}
+
+// running returns whether the MinIO process is still running.
+func (p *Process) running() bool {
+	select {
+	case <-p.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// Stop stops the MinIO process.
+func (p *Process) Stop() error {
+	if !p.running() {
+		return nil
+	}
+
+	err := p.cmd.Process.Signal(os.Interrupt)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-p.exited:
+	case <-time.After(10 * time.Second):
+		_ = p.cmd.Process.Kill()
+		<-p.exited
+	}
+
+	return nil
+}
+
+// waitReady waits for the MinIO process to report that it is ready to serve requests.
+func (p *Process) waitReady(ctx context.Context) error {
+	healthURL := p.url
+	healthURL.Path = "/minio/health/live"
+
+	for {
+		if !p.running() {
+			return fmt.Errorf("MinIO process exited unexpectedly")
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthURL.String(), nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err == nil {
+			_ = resp.Body.Close()
+
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Timed out waiting for MinIO to start")
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// Get returns the running MinIO process for the volume or nil if not running.
+func Get(volName string) *Process {
+	minioLock.Lock()
+	defer minioLock.Unlock()
+
+	p := minios[volName]
+	if p == nil || !p.running() {
+		return nil
+	}
+
+	return p
+}
+
+// Start starts a MinIO process serving the bucket stored at bucketPath, if not already running.
+// The bucket is created if it doesn't already exist.
+func Start(ctx context.Context, volName string, bucketPath string, bucketName string) (*Process, error) {
+	minioLock.Lock()
+	defer minioLock.Unlock()
+
+	p := minios[volName]
+	if p != nil && p.running() {
+		return p, nil
+	}
+
+	_, err := exec.LookPath("minio")
+	if err != nil {
+		return nil, fmt.Errorf("Storage buckets require the minio command")
+	}
+
+	port, err := shared.AllocatePort()
+	if err != nil {
+		return nil, fmt.Errorf("Failed allocating port for MinIO: %w", err)
+	}
+
+	creds, err := s3.GenerateCredentials(s3.Credentials{AccessKey: minioAdminUser})
+	if err != nil {
+		return nil, err
+	}
+
+	p = &Process{
+		exited:     make(chan struct{}),
+		url:        url.URL{Scheme: "http", Host: fmt.Sprintf("%s:%d", minioHost, port)},
+		creds:      creds,
+		bucketName: bucketName,
+	}
+
+	logPath := shared.LogPath(fmt.Sprintf("minio-%s.log", volName))
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("Failed opening MinIO log file %q: %w", logPath, err)
+	}
+
+	defer logFile.Close()
+
+	p.cmd = exec.Command("minio", "server", "--quiet", "--address", p.url.Host, bucketPath)
+	p.cmd.Stdout = logFile
+	p.cmd.Stderr = logFile
+	p.cmd.Env = append(os.Environ(),
+		fmt.Sprintf("MINIO_ROOT_USER=%s", creds.AccessKey),
+		fmt.Sprintf("MINIO_ROOT_PASSWORD=%s", creds.SecretKey),
+		"MINIO_BROWSER=off",
+		"MINIO_UPDATE=off",
+	)
+
+	err = p.cmd.Start()
+	if err != nil {
+		return nil, fmt.Errorf("Failed starting MinIO: %w", err)
+	}
+
+	go func() {
+		_ = p.cmd.Wait()
+		close(p.exited)
+	}()
+
+	startCtx, cancel := context.WithTimeout(ctx, minioStartTimeout)
+	defer cancel()
+
+	err = p.waitReady(startCtx)
+	if err == nil {
+		err = s3.NewClient(&p.url, p.creds, nil).CreateBucket(startCtx, bucketName)
+	}
+
+	if err != nil {
+		_ = p.Stop()
+		return nil, err
+	}
+
+	logger.Debug("MinIO started", logger.Ctx{"volName": volName, "bucketName": bucketName, "url": p.url.String()})
+	minios[volName] = p
+
+	return p, nil
+}
+
+// Stop stops the MinIO process for the volume if running.
+func Stop(volName string) error {
+	minioLock.Lock()
+	defer minioLock.Unlock()
+
+	p := minios[volName]
+	if p == nil {
+		return nil
+	}
+
+	delete(minios, volName)
+
+	return p.Stop()
+}
+
+// StopAll stops all running MinIO processes.
+func StopAll() {
+	minioLock.Lock()
+	defer minioLock.Unlock()
+
+	for volName, p := range minios {
+		err := p.Stop()
+		if err != nil {
+			logger.Warn("Failed stopping MinIO", logger.Ctx{"volName": volName, "err": err})
+		}
+
+		delete(minios, volName)
+	}
+}
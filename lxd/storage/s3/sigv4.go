@@ -0,0 +1,262 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// authAlgorithm is the only signing algorithm supported.
+const authAlgorithm = "AWS4-HMAC-SHA256"
+
+// amzDateFormat is the timestamp format used in the X-Amz-Date header.
+const amzDateFormat = "20060102T150405Z"
+
+// DefaultRegion is the region used when signing requests if none is specified.
+const DefaultRegion = "us-east-1"
+
+// UnsignedPayload is the payload hash value used when the request body is not included in the signature.
+const UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// StreamingPayload is the payload hash value used by clients sending chunk signed request bodies.
+const StreamingPayload = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+
+// maxClockSkew is the maximum allowed difference between the request timestamp and the local time.
+const maxClockSkew = 15 * time.Minute
+
+// Authorization represents the parsed AWS signature version 4 Authorization header of a request.
+type Authorization struct {
+	AccessKey     string
+	Date          string
+	Region        string
+	Service       string
+	SignedHeaders []string
+	Signature     string
+}
+
+// ParseAuthorization parses the AWS signature version 4 Authorization header of the request.
+func ParseAuthorization(r *http.Request) (*Authorization, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return nil, fmt.Errorf("Missing authorization header")
+	}
+
+	if !strings.HasPrefix(header, authAlgorithm+" ") {
+		return nil, fmt.Errorf("Unsupported authorization algorithm")
+	}
+
+	auth := &Authorization{}
+
+	for _, field := range strings.Split(strings.TrimPrefix(header, authAlgorithm+" "), ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(field), "=")
+		if !found {
+			return nil, fmt.Errorf("Invalid authorization header field %q", field)
+		}
+
+		switch key {
+		case "Credential":
+			parts := strings.Split(value, "/")
+			if len(parts) != 5 || parts[4] != "aws4_request" {
+				return nil, fmt.Errorf("Invalid authorization credential")
+			}
+
+			auth.AccessKey = parts[0]
+			auth.Date = parts[1]
+			auth.Region = parts[2]
+			auth.Service = parts[3]
+		case "SignedHeaders":
+			auth.SignedHeaders = strings.Split(value, ";")
+		case "Signature":
+			auth.Signature = value
+		}
+	}
+
+	if auth.AccessKey == "" || len(auth.SignedHeaders) == 0 || auth.Signature == "" {
+		return nil, fmt.Errorf("Incomplete authorization header")
+	}
+
+	return auth, nil
+}
+
+// Verify checks that the request signature matches the one computed using the supplied secret key.
+func (a *Authorization) Verify(r *http.Request, secretKey string) error {
+	amzDate := r.Header.Get("X-Amz-Date")
+	if amzDate == "" {
+		return fmt.Errorf("Missing X-Amz-Date header")
+	}
+
+	requestTime, err := time.Parse(amzDateFormat, amzDate)
+	if err != nil {
+		return fmt.Errorf("Invalid X-Amz-Date header: %w", err)
+	}
+
+	if !strings.HasPrefix(amzDate, a.Date) {
+		return fmt.Errorf("Credential date doesn't match X-Amz-Date header")
+	}
+
+	skew := time.Since(requestTime)
+	if skew > maxClockSkew || skew < -maxClockSkew {
+		return fmt.Errorf("Request time too skewed")
+	}
+
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash == "" {
+		return fmt.Errorf("Missing X-Amz-Content-Sha256 header")
+	}
+
+	signedHost := false
+	for _, name := range a.SignedHeaders {
+		if name == "host" {
+			signedHost = true
+			break
+		}
+	}
+
+	if !signedHost {
+		return fmt.Errorf("Host header must be signed")
+	}
+
+	canonicalReq := canonicalRequest(r, a.SignedHeaders, payloadHash)
+	expected := signature(secretKey, a.Date, a.Region, a.Service, stringToSign(amzDate, a.Date, a.Region, a.Service, canonicalReq))
+
+	if !hmac.Equal([]byte(expected), []byte(a.Signature)) {
+		return fmt.Errorf("Signature mismatch")
+	}
+
+	return nil
+}
+
+// Sign adds an AWS signature version 4 Authorization header to the request using the supplied credentials.
+// The payloadHash should be the hex encoded SHA256 hash of the request body, or UnsignedPayload.
+func Sign(r *http.Request, creds Credentials, region string, payloadHash string, now time.Time) {
+	if region == "" {
+		region = DefaultRegion
+	}
+
+	amzDate := now.UTC().Format(amzDateFormat)
+	date := amzDate[:8]
+
+	r.Header.Del("Authorization")
+	r.Header.Set("X-Amz-Date", amzDate)
+	r.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	// Sign the host header, the headers describing the body and all of the amz specific headers.
+	signedHeaders := []string{"host"}
+	for name := range r.Header {
+		name = strings.ToLower(name)
+		if strings.HasPrefix(name, "x-amz-") || name == "content-type" || name == "content-md5" || name == "range" {
+			signedHeaders = append(signedHeaders, name)
+		}
+	}
+
+	sort.Strings(signedHeaders)
+
+	canonicalReq := canonicalRequest(r, signedHeaders, payloadHash)
+	sig := signature(creds.SecretKey, date, region, "s3", stringToSign(amzDate, date, region, "s3", canonicalReq))
+
+	r.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s/%s/s3/aws4_request, SignedHeaders=%s, Signature=%s", authAlgorithm, creds.AccessKey, date, region, strings.Join(signedHeaders, ";"), sig))
+}
+
+// canonicalRequest returns the canonical form of the request used for computing its signature.
+func canonicalRequest(r *http.Request, signedHeaders []string, payloadHash string) string {
+	var sb strings.Builder
+
+	sb.WriteString(r.Method + "\n")
+
+	path := r.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	sb.WriteString(path + "\n")
+	sb.WriteString(canonicalQuery(r) + "\n")
+
+	for _, name := range signedHeaders {
+		var value string
+		if name == "host" {
+			value = r.Host
+			if value == "" {
+				value = r.URL.Host
+			}
+		} else {
+			values := r.Header.Values(name)
+			for i := range values {
+				values[i] = strings.Join(strings.Fields(values[i]), " ")
+			}
+
+			value = strings.Join(values, ",")
+		}
+
+		sb.WriteString(name + ":" + value + "\n")
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(strings.Join(signedHeaders, ";") + "\n")
+	sb.WriteString(payloadHash)
+
+	return sb.String()
+}
+
+// canonicalQuery returns the sorted and URI encoded query string of the request.
+func canonicalQuery(r *http.Request) string {
+	query := r.URL.Query()
+
+	params := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			params = append(params, uriEncode(key)+"="+uriEncode(value))
+		}
+	}
+
+	sort.Strings(params)
+
+	return strings.Join(params, "&")
+}
+
+// uriEncode encodes the string using the rules required for AWS signatures.
+func uriEncode(s string) string {
+	var sb strings.Builder
+
+	for _, b := range []byte(s) {
+		if (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') || b == '-' || b == '_' || b == '.' || b == '~' {
+			sb.WriteByte(b)
+		} else {
+			sb.WriteString(fmt.Sprintf("%%%02X", b))
+		}
+	}
+
+	return sb.String()
+}
+
+// stringToSign returns the string that gets signed for the given canonical request.
+func stringToSign(amzDate string, date string, region string, service string, canonicalReq string) string {
+	hash := sha256.Sum256([]byte(canonicalReq))
+
+	return strings.Join([]string{
+		authAlgorithm,
+		amzDate,
+		fmt.Sprintf("%s/%s/%s/aws4_request", date, region, service),
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+}
+
+// signature returns the hex encoded signature of the string using a signing key derived from the secret key.
+func signature(secretKey string, date string, region string, service string, stringToSign string) string {
+	hmacSHA256 := func(key []byte, data string) []byte {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte(data))
+		return h.Sum(nil)
+	}
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
@@ -331,6 +331,16 @@ func validateVolumeCommonRules(vol drivers.Volume) map[string]func(string) error
 		rules["block.filesystem"] = validate.IsAny
 	}
 
+	// Snapshot replication is only supported for custom volumes.
+	if vol.Type() == drivers.VolumeTypeCustom {
+		rules["snapshots.replicate.target"] = validate.Optional(validate.IsListenAddress(true, false, false))
+		rules["snapshots.replicate.target.fingerprint"] = validate.Optional(validate.IsCertificateFingerprint)
+		rules["snapshots.replicate.target.pool"] = validate.IsAny
+		rules["snapshots.replicate.target.project"] = validate.IsAny
+		rules["volatile.replicate.last_snapshot"] = validate.IsAny
+		rules["volatile.replicate.last_success"] = validate.IsAny
+	}
+
 	// security.shifted and security.unmapped are only relevant for custom filesystem volumes.
 	if vol.Type() == drivers.VolumeTypeCustom && vol.ContentType() == drivers.ContentTypeFS {
 		rules["security.shifted"] = validate.Optional(validate.IsBool)
@@ -0,0 +1,1244 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lxc/lxd/lxd/db"
+	"github.com/lxc/lxd/lxd/lifecycle"
+	"github.com/lxc/lxd/lxd/project"
+	"github.com/lxc/lxd/lxd/request"
+	"github.com/lxc/lxd/lxd/response"
+	storagePools "github.com/lxc/lxd/lxd/storage"
+	"github.com/lxc/lxd/lxd/storage/s3"
+	"github.com/lxc/lxd/lxd/util"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/version"
+)
+
+var storagePoolBucketsCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/buckets",
+
+	Get:  APIEndpointAction{Handler: storagePoolBucketsGet, AccessHandler: allowProjectPermission("storage-volumes", "view")},
+	Post: APIEndpointAction{Handler: storagePoolBucketsPost, AccessHandler: allowProjectPermission("storage-volumes", "manage-storage-volumes")},
+}
+
+var storagePoolBucketCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/buckets/{bucketName}",
+
+	Delete: APIEndpointAction{Handler: storagePoolBucketDelete, AccessHandler: allowProjectPermission("storage-volumes", "manage-storage-volumes")},
+	Get:    APIEndpointAction{Handler: storagePoolBucketGet, AccessHandler: allowProjectPermission("storage-volumes", "view")},
+	Patch:  APIEndpointAction{Handler: storagePoolBucketPut, AccessHandler: allowProjectPermission("storage-volumes", "manage-storage-volumes")},
+	Put:    APIEndpointAction{Handler: storagePoolBucketPut, AccessHandler: allowProjectPermission("storage-volumes", "manage-storage-volumes")},
+}
+
+var storagePoolBucketKeysCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/buckets/{bucketName}/keys",
+
+	Get:  APIEndpointAction{Handler: storagePoolBucketKeysGet, AccessHandler: allowProjectPermission("storage-volumes", "view")},
+	Post: APIEndpointAction{Handler: storagePoolBucketKeysPost, AccessHandler: allowProjectPermission("storage-volumes", "manage-storage-volumes")},
+}
+
+var storagePoolBucketKeyCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/buckets/{bucketName}/keys/{keyName}",
+
+	Delete: APIEndpointAction{Handler: storagePoolBucketKeyDelete, AccessHandler: allowProjectPermission("storage-volumes", "manage-storage-volumes")},
+	Get:    APIEndpointAction{Handler: storagePoolBucketKeyGet, AccessHandler: allowProjectPermission("storage-volumes", "view")},
+	Put:    APIEndpointAction{Handler: storagePoolBucketKeyPut, AccessHandler: allowProjectPermission("storage-volumes", "manage-storage-volumes")},
+}
+
+// storagePoolBucketsLoadPool loads the storage pool from the request and checks that it supports buckets.
+func storagePoolBucketsLoadPool(d *Daemon, r *http.Request) (storagePools.Pool, error) {
+	pool, err := storagePools.LoadByName(d.State(), mux.Vars(r)["poolName"])
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading storage pool: %w", err)
+	}
+
+	if !pool.Driver().Info().Buckets {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Storage pool driver %q does not support buckets", pool.Driver().Info().Name)
+	}
+
+	return pool, nil
+}
+
+// storagePoolBucketURL returns the API URL of the storage bucket.
+func storagePoolBucketURL(poolName string, bucketName string) string {
+	return fmt.Sprintf("/%s/storage-pools/%s/buckets/%s", version.APIVersion, url.PathEscape(poolName), url.PathEscape(bucketName))
+}
+
+// API endpoints
+
+// swagger:operation GET /1.0/storage-pools/{poolName}/buckets storage storage_pool_buckets_get
+//
+// Get the storage pool buckets
+//
+// Returns a list of storage pool buckets (URLs).
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: query
+//     name: target
+//     description: Cluster member name
+//     type: string
+//     example: lxd01
+// responses:
+//   "200":
+//     description: API endpoints
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           type: array
+//           description: List of endpoints
+//           items:
+//             type: string
+//           example: |-
+//             [
+//               "/1.0/storage-pools/local/buckets/foo",
+//               "/1.0/storage-pools/local/buckets/bar"
+//             ]
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/storage-pools/{poolName}/buckets?recursion=1 storage storage_pool_buckets_get_recursion1
+//
+// Get the storage pool buckets
+//
+// Returns a list of storage pool buckets (structs).
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: query
+//     name: target
+//     description: Cluster member name
+//     type: string
+//     example: lxd01
+// responses:
+//   "200":
+//     description: API endpoints
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           type: array
+//           description: List of storage pool buckets
+//           items:
+//             $ref: "#/definitions/StorageBucket"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func storagePoolBucketsGet(d *Daemon, r *http.Request) response.Response {
+	resp := forwardedResponseIfTargetIsRemote(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	projectName, err := project.StorageVolumeProject(d.State().Cluster, projectParam(r), db.StoragePoolVolumeTypeCustom)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := storagePoolBucketsLoadPool(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	poolID := pool.ID()
+	filter := db.StorageBucketFilter{
+		PoolID:  &poolID,
+		Project: &projectName,
+	}
+
+	targetMember := queryParam(r, "target")
+	memberSpecific := targetMember != ""
+
+	var localNodeName string
+	var dbBuckets []*db.StorageBucket
+	err = d.State().Cluster.Transaction(func(tx *db.ClusterTx) error {
+		localNodeName, err = tx.GetLocalNodeName()
+		if err != nil {
+			return err
+		}
+
+		dbBuckets, err = tx.GetStoragePoolBuckets(memberSpecific, filter)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading storage buckets: %w", err))
+	}
+
+	if util.IsRecursionRequest(r) {
+		buckets := make([]*api.StorageBucket, 0, len(dbBuckets))
+		for _, dbBucket := range dbBuckets {
+			// The S3 URL of local buckets on other members is only known by those members.
+			if dbBucket.Location == "" || dbBucket.Location == localNodeName {
+				u := pool.GetBucketURL(dbBucket.Name)
+				if u != nil {
+					dbBucket.S3URL = u.String()
+				}
+			}
+
+			buckets = append(buckets, &dbBucket.StorageBucket)
+		}
+
+		return response.SyncResponse(true, buckets)
+	}
+
+	bucketURLs := make([]string, 0, len(dbBuckets))
+	for _, dbBucket := range dbBuckets {
+		bucketURLs = append(bucketURLs, storagePoolBucketURL(pool.Name(), dbBucket.Name))
+	}
+
+	return response.SyncResponse(true, bucketURLs)
+}
+
+// swagger:operation GET /1.0/storage-pools/{poolName}/buckets/{bucketName} storage storage_pool_bucket_get
+//
+// Get the storage pool bucket
+//
+// Gets a specific storage pool bucket.
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: query
+//     name: target
+//     description: Cluster member name
+//     type: string
+//     example: lxd01
+// responses:
+//   "200":
+//     description: Storage pool bucket
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           $ref: "#/definitions/StorageBucket"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "404":
+//     $ref: "#/responses/NotFound"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func storagePoolBucketGet(d *Daemon, r *http.Request) response.Response {
+	resp := forwardedResponseIfTargetIsRemote(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	projectName, err := project.StorageVolumeProject(d.State().Cluster, projectParam(r), db.StoragePoolVolumeTypeCustom)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := storagePoolBucketsLoadPool(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	bucketName := mux.Vars(r)["bucketName"]
+
+	resp = forwardedResponseIfBucketIsRemote(d, r, pool.ID(), projectName, bucketName)
+	if resp != nil {
+		return resp
+	}
+
+	var bucket *db.StorageBucket
+	err = d.State().Cluster.Transaction(func(tx *db.ClusterTx) error {
+		bucket, err = tx.GetStoragePoolBucket(pool.ID(), projectName, true, bucketName)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	u := pool.GetBucketURL(bucket.Name)
+	if u != nil {
+		bucket.S3URL = u.String()
+	}
+
+	return response.SyncResponseETag(true, bucket.StorageBucket, bucket.Etag())
+}
+
+// swagger:operation POST /1.0/storage-pools/{poolName}/buckets storage storage_pool_bucket_post
+//
+// Add a storage pool bucket
+//
+// Creates a new storage pool bucket.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: query
+//     name: target
+//     description: Cluster member name
+//     type: string
+//     example: lxd01
+//   - in: body
+//     name: bucket
+//     description: Bucket
+//     required: true
+//     schema:
+//       $ref: "#/definitions/StorageBucketsPost"
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func storagePoolBucketsPost(d *Daemon, r *http.Request) response.Response {
+	resp := forwardedResponseIfTargetIsRemote(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	projectName, err := project.StorageVolumeProject(d.State().Cluster, projectParam(r), db.StoragePoolVolumeTypeCustom)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := storagePoolBucketsLoadPool(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Parse the request into a record.
+	req := api.StorageBucketsPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = pool.CreateBucket(projectName, req, nil)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed creating storage bucket: %w", err))
+	}
+
+	d.State().Events.SendLifecycle(projectName, lifecycle.StorageBucketCreated.Event(pool.Name(), projectName, req.Name, request.CreateRequestor(r), nil))
+
+	return response.SyncResponseLocation(true, nil, storagePoolBucketURL(pool.Name(), req.Name))
+}
+
+// swagger:operation PATCH /1.0/storage-pools/{poolName}/buckets/{bucketName} storage storage_pool_bucket_patch
+//
+// Partially update the storage bucket
+//
+// Updates a subset of the storage bucket configuration.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: query
+//     name: target
+//     description: Cluster member name
+//     type: string
+//     example: lxd01
+//   - in: body
+//     name: bucket
+//     description: Storage bucket configuration
+//     required: true
+//     schema:
+//       $ref: "#/definitions/StorageBucketPut"
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "412":
+//     $ref: "#/responses/PreconditionFailed"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/storage-pools/{poolName}/buckets/{bucketName} storage storage_pool_bucket_put
+//
+// Update the storage bucket
+//
+// Updates the entire storage bucket configuration.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: query
+//     name: target
+//     description: Cluster member name
+//     type: string
+//     example: lxd01
+//   - in: body
+//     name: bucket
+//     description: Storage bucket configuration
+//     required: true
+//     schema:
+//       $ref: "#/definitions/StorageBucketPut"
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "412":
+//     $ref: "#/responses/PreconditionFailed"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func storagePoolBucketPut(d *Daemon, r *http.Request) response.Response {
+	resp := forwardedResponseIfTargetIsRemote(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	projectName, err := project.StorageVolumeProject(d.State().Cluster, projectParam(r), db.StoragePoolVolumeTypeCustom)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := storagePoolBucketsLoadPool(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	bucketName := mux.Vars(r)["bucketName"]
+
+	resp = forwardedResponseIfBucketIsRemote(d, r, pool.ID(), projectName, bucketName)
+	if resp != nil {
+		return resp
+	}
+
+	var bucket *db.StorageBucket
+	err = d.State().Cluster.Transaction(func(tx *db.ClusterTx) error {
+		bucket, err = tx.GetStoragePoolBucket(pool.ID(), projectName, true, bucketName)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Validate the ETag.
+	err = util.EtagCheck(r, bucket.Etag())
+	if err != nil {
+		return response.PreconditionFailed(err)
+	}
+
+	// Decode the request.
+	req := api.StorageBucketPut{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if r.Method == http.MethodPatch {
+		if req.Description == "" {
+			req.Description = bucket.Description
+		}
+
+		// If config being updated via "patch" method, then merge all existing config with the keys that
+		// are present in the request config.
+		for k, v := range bucket.Config {
+			_, ok := req.Config[k]
+			if !ok {
+				if req.Config == nil {
+					req.Config = make(map[string]string)
+				}
+
+				req.Config[k] = v
+			}
+		}
+	}
+
+	err = pool.UpdateBucket(projectName, bucketName, req, nil)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed updating storage bucket: %w", err))
+	}
+
+	d.State().Events.SendLifecycle(projectName, lifecycle.StorageBucketUpdated.Event(pool.Name(), projectName, bucketName, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation DELETE /1.0/storage-pools/{poolName}/buckets/{bucketName} storage storage_pool_bucket_delete
+//
+// Delete the storage bucket
+//
+// Removes the storage bucket and all of its objects.
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: query
+//     name: target
+//     description: Cluster member name
+//     type: string
+//     example: lxd01
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func storagePoolBucketDelete(d *Daemon, r *http.Request) response.Response {
+	resp := forwardedResponseIfTargetIsRemote(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	projectName, err := project.StorageVolumeProject(d.State().Cluster, projectParam(r), db.StoragePoolVolumeTypeCustom)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := storagePoolBucketsLoadPool(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	bucketName := mux.Vars(r)["bucketName"]
+
+	resp = forwardedResponseIfBucketIsRemote(d, r, pool.ID(), projectName, bucketName)
+	if resp != nil {
+		return resp
+	}
+
+	err = pool.DeleteBucket(projectName, bucketName, nil)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed deleting storage bucket: %w", err))
+	}
+
+	d.State().Events.SendLifecycle(projectName, lifecycle.StorageBucketDeleted.Event(pool.Name(), projectName, bucketName, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/storage-pools/{poolName}/buckets/{bucketName}/keys storage storage_pool_bucket_keys_get
+//
+// Get the storage pool bucket keys
+//
+// Returns a list of storage pool bucket keys (URLs).
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: query
+//     name: target
+//     description: Cluster member name
+//     type: string
+//     example: lxd01
+// responses:
+//   "200":
+//     description: API endpoints
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           type: array
+//           description: List of endpoints
+//           items:
+//             type: string
+//           example: |-
+//             [
+//               "/1.0/storage-pools/local/buckets/foo/keys/my-read-only-key",
+//               "/1.0/storage-pools/local/buckets/foo/keys/admin"
+//             ]
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/storage-pools/{poolName}/buckets/{bucketName}/keys?recursion=1 storage storage_pool_bucket_keys_get_recursion1
+//
+// Get the storage pool bucket keys
+//
+// Returns a list of storage pool bucket keys (structs).
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: query
+//     name: target
+//     description: Cluster member name
+//     type: string
+//     example: lxd01
+// responses:
+//   "200":
+//     description: API endpoints
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           type: array
+//           description: List of storage pool bucket keys
+//           items:
+//             $ref: "#/definitions/StorageBucketKey"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func storagePoolBucketKeysGet(d *Daemon, r *http.Request) response.Response {
+	resp := forwardedResponseIfTargetIsRemote(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	projectName, err := project.StorageVolumeProject(d.State().Cluster, projectParam(r), db.StoragePoolVolumeTypeCustom)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := storagePoolBucketsLoadPool(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	bucketName := mux.Vars(r)["bucketName"]
+
+	resp = forwardedResponseIfBucketIsRemote(d, r, pool.ID(), projectName, bucketName)
+	if resp != nil {
+		return resp
+	}
+
+	var dbBucketKeys []*db.StorageBucketKey
+	err = d.State().Cluster.Transaction(func(tx *db.ClusterTx) error {
+		bucket, err := tx.GetStoragePoolBucket(pool.ID(), projectName, true, bucketName)
+		if err != nil {
+			return fmt.Errorf("Failed loading storage bucket: %w", err)
+		}
+
+		dbBucketKeys, err = tx.GetStoragePoolBucketKeys(bucket.ID)
+		if err != nil {
+			return fmt.Errorf("Failed loading storage bucket keys: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if util.IsRecursionRequest(r) {
+		bucketKeys := make([]*api.StorageBucketKey, 0, len(dbBucketKeys))
+		for _, dbBucketKey := range dbBucketKeys {
+			bucketKeys = append(bucketKeys, &dbBucketKey.StorageBucketKey)
+		}
+
+		return response.SyncResponse(true, bucketKeys)
+	}
+
+	bucketKeyURLs := make([]string, 0, len(dbBucketKeys))
+	for _, dbBucketKey := range dbBucketKeys {
+		bucketKeyURLs = append(bucketKeyURLs, fmt.Sprintf("%s/keys/%s", storagePoolBucketURL(pool.Name(), bucketName), url.PathEscape(dbBucketKey.Name)))
+	}
+
+	return response.SyncResponse(true, bucketKeyURLs)
+}
+
+// swagger:operation POST /1.0/storage-pools/{poolName}/buckets/{bucketName}/keys storage storage_pool_bucket_keys_post
+//
+// Add a storage pool bucket key.
+//
+// Creates a new storage pool bucket key. If the access or secret key are not specified they are generated.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: query
+//     name: target
+//     description: Cluster member name
+//     type: string
+//     example: lxd01
+//   - in: body
+//     name: bucket
+//     description: Bucket key
+//     required: true
+//     schema:
+//       $ref: "#/definitions/StorageBucketKeysPost"
+// responses:
+//   "200":
+//     description: Storage pool bucket key
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           $ref: "#/definitions/StorageBucketKey"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func storagePoolBucketKeysPost(d *Daemon, r *http.Request) response.Response {
+	resp := forwardedResponseIfTargetIsRemote(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	projectName, err := project.StorageVolumeProject(d.State().Cluster, projectParam(r), db.StoragePoolVolumeTypeCustom)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := storagePoolBucketsLoadPool(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	bucketName := mux.Vars(r)["bucketName"]
+
+	resp = forwardedResponseIfBucketIsRemote(d, r, pool.ID(), projectName, bucketName)
+	if resp != nil {
+		return resp
+	}
+
+	// Parse the request into a record.
+	req := api.StorageBucketKeysPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	key, err := pool.CreateBucketKey(projectName, bucketName, req, nil)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed creating storage bucket key: %w", err))
+	}
+
+	d.State().Events.SendLifecycle(projectName, lifecycle.StorageBucketKeyCreated.Event(pool.Name(), projectName, bucketName, req.Name, request.CreateRequestor(r), nil))
+
+	u := fmt.Sprintf("%s/keys/%s", storagePoolBucketURL(pool.Name(), bucketName), url.PathEscape(req.Name))
+
+	return response.SyncResponseLocation(true, key, u)
+}
+
+// swagger:operation GET /1.0/storage-pools/{poolName}/buckets/{bucketName}/keys/{keyName} storage storage_pool_bucket_key_get
+//
+// Get the storage pool bucket key
+//
+// Gets a specific storage pool bucket key.
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: query
+//     name: target
+//     description: Cluster member name
+//     type: string
+//     example: lxd01
+// responses:
+//   "200":
+//     description: Storage pool bucket key
+//     schema:
+//       type: object
+//       description: Sync response
+//       properties:
+//         type:
+//           type: string
+//           description: Response type
+//           example: sync
+//         status:
+//           type: string
+//           description: Status description
+//           example: Success
+//         status_code:
+//           type: integer
+//           description: Status code
+//           example: 200
+//         metadata:
+//           $ref: "#/definitions/StorageBucketKey"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "404":
+//     $ref: "#/responses/NotFound"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func storagePoolBucketKeyGet(d *Daemon, r *http.Request) response.Response {
+	resp := forwardedResponseIfTargetIsRemote(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	projectName, err := project.StorageVolumeProject(d.State().Cluster, projectParam(r), db.StoragePoolVolumeTypeCustom)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := storagePoolBucketsLoadPool(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	bucketName := mux.Vars(r)["bucketName"]
+
+	resp = forwardedResponseIfBucketIsRemote(d, r, pool.ID(), projectName, bucketName)
+	if resp != nil {
+		return resp
+	}
+
+	keyName := mux.Vars(r)["keyName"]
+
+	var bucketKey *db.StorageBucketKey
+	err = d.State().Cluster.Transaction(func(tx *db.ClusterTx) error {
+		bucket, err := tx.GetStoragePoolBucket(pool.ID(), projectName, true, bucketName)
+		if err != nil {
+			return fmt.Errorf("Failed loading storage bucket: %w", err)
+		}
+
+		bucketKey, err = tx.GetStoragePoolBucketKey(bucket.ID, keyName)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, bucketKey.StorageBucketKey, bucketKey.Etag())
+}
+
+// swagger:operation PUT /1.0/storage-pools/{poolName}/buckets/{bucketName}/keys/{keyName} storage storage_pool_bucket_key_put
+//
+// Update the storage bucket key
+//
+// Updates the entire storage bucket key configuration. If the access or secret key are not specified they are
+// regenerated.
+//
+// ---
+// consumes:
+//   - application/json
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: query
+//     name: target
+//     description: Cluster member name
+//     type: string
+//     example: lxd01
+//   - in: body
+//     name: bucket
+//     description: Storage bucket key configuration
+//     required: true
+//     schema:
+//       $ref: "#/definitions/StorageBucketKeyPut"
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "412":
+//     $ref: "#/responses/PreconditionFailed"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func storagePoolBucketKeyPut(d *Daemon, r *http.Request) response.Response {
+	resp := forwardedResponseIfTargetIsRemote(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	projectName, err := project.StorageVolumeProject(d.State().Cluster, projectParam(r), db.StoragePoolVolumeTypeCustom)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := storagePoolBucketsLoadPool(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	bucketName := mux.Vars(r)["bucketName"]
+
+	resp = forwardedResponseIfBucketIsRemote(d, r, pool.ID(), projectName, bucketName)
+	if resp != nil {
+		return resp
+	}
+
+	keyName := mux.Vars(r)["keyName"]
+
+	var bucketKey *db.StorageBucketKey
+	err = d.State().Cluster.Transaction(func(tx *db.ClusterTx) error {
+		bucket, err := tx.GetStoragePoolBucket(pool.ID(), projectName, true, bucketName)
+		if err != nil {
+			return fmt.Errorf("Failed loading storage bucket: %w", err)
+		}
+
+		bucketKey, err = tx.GetStoragePoolBucketKey(bucket.ID, keyName)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Validate the ETag.
+	err = util.EtagCheck(r, bucketKey.Etag())
+	if err != nil {
+		return response.PreconditionFailed(err)
+	}
+
+	// Decode the request.
+	req := api.StorageBucketKeyPut{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = pool.UpdateBucketKey(projectName, bucketName, keyName, req, nil)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed updating storage bucket key: %w", err))
+	}
+
+	d.State().Events.SendLifecycle(projectName, lifecycle.StorageBucketKeyUpdated.Event(pool.Name(), projectName, bucketName, keyName, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation DELETE /1.0/storage-pools/{poolName}/buckets/{bucketName}/keys/{keyName} storage storage_pool_bucket_key_delete
+//
+// Delete the storage bucket key
+//
+// Removes the storage bucket key.
+//
+// ---
+// produces:
+//   - application/json
+// parameters:
+//   - in: query
+//     name: project
+//     description: Project name
+//     type: string
+//     example: default
+//   - in: query
+//     name: target
+//     description: Cluster member name
+//     type: string
+//     example: lxd01
+// responses:
+//   "200":
+//     $ref: "#/responses/EmptySyncResponse"
+//   "400":
+//     $ref: "#/responses/BadRequest"
+//   "403":
+//     $ref: "#/responses/Forbidden"
+//   "500":
+//     $ref: "#/responses/InternalServerError"
+func storagePoolBucketKeyDelete(d *Daemon, r *http.Request) response.Response {
+	resp := forwardedResponseIfTargetIsRemote(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	projectName, err := project.StorageVolumeProject(d.State().Cluster, projectParam(r), db.StoragePoolVolumeTypeCustom)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := storagePoolBucketsLoadPool(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	bucketName := mux.Vars(r)["bucketName"]
+
+	resp = forwardedResponseIfBucketIsRemote(d, r, pool.ID(), projectName, bucketName)
+	if resp != nil {
+		return resp
+	}
+
+	keyName := mux.Vars(r)["keyName"]
+
+	err = pool.DeleteBucketKey(projectName, bucketName, keyName, nil)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed deleting storage bucket key: %w", err))
+	}
+
+	d.State().Events.SendLifecycle(projectName, lifecycle.StorageBucketKeyDeleted.Event(pool.Name(), projectName, bucketName, keyName, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// storageBucketsProxy authenticates an S3 request against the local bucket's keys and forwards it to the
+// bucket's S3 server, re-signing it using the server's own credentials.
+// Only path-style requests using AWS signature version 4 Authorization headers are supported.
+func storageBucketsProxy(d *Daemon, w http.ResponseWriter, r *http.Request) {
+	s := d.State()
+
+	errorResponse := func(statusCode int, code string, message string) {
+		s3Err := s3.Error{Code: code, Message: message, Resource: r.URL.Path}
+		s3Err.Response(w, statusCode)
+	}
+
+	bucketName, objectName, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucketName == "" {
+		errorResponse(http.StatusForbidden, "AccessDenied", "Listing buckets is not supported")
+		return
+	}
+
+	// Don't allow path traversal into other buckets.
+	for _, part := range strings.Split(objectName, "/") {
+		if part == "." || part == ".." {
+			errorResponse(http.StatusBadRequest, "InvalidRequest", "Invalid object name")
+			return
+		}
+	}
+
+	// The bucket itself is managed through the LXD API.
+	if objectName == "" && r.Method == http.MethodDelete && r.URL.RawQuery == "" {
+		errorResponse(http.StatusForbidden, "AccessDenied", "Buckets must be deleted using the LXD API")
+		return
+	}
+
+	auth, err := s3.ParseAuthorization(r)
+	if err != nil {
+		errorResponse(http.StatusForbidden, "AccessDenied", err.Error())
+		return
+	}
+
+	// Chunk signed request bodies are signed using the client's secret key and so cannot be forwarded.
+	if r.Header.Get("X-Amz-Content-Sha256") == s3.StreamingPayload {
+		errorResponse(http.StatusNotImplemented, "NotImplemented", "Chunk signed payloads are not supported")
+		return
+	}
+
+	var bucket *db.StorageBucket
+	var bucketKey *db.StorageBucketKey
+	err = s.Cluster.Transaction(func(tx *db.ClusterTx) error {
+		bucket, err = tx.GetStoragePoolLocalBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		bucketKeys, err := tx.GetStoragePoolBucketKeys(bucket.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range bucketKeys {
+			if key.AccessKey == auth.AccessKey {
+				bucketKey = key
+				break
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		if response.IsNotFoundError(err) {
+			errorResponse(http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+			return
+		}
+
+		errorResponse(http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	if bucketKey == nil {
+		errorResponse(http.StatusForbidden, "InvalidAccessKeyId", "The access key ID you provided does not exist in our records")
+		return
+	}
+
+	err = auth.Verify(r, bucketKey.SecretKey)
+	if err != nil {
+		errorResponse(http.StatusForbidden, "SignatureDoesNotMatch", err.Error())
+		return
+	}
+
+	if bucketKey.Role == s3.RoleReadOnly && r.Method != http.MethodGet && r.Method != http.MethodHead {
+		errorResponse(http.StatusForbidden, "AccessDenied", "Access key is read-only")
+		return
+	}
+
+	pool, err := storagePools.LoadByName(s, bucket.PoolName)
+	if err != nil {
+		errorResponse(http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	minioProc, err := pool.ActivateBucket(bucket.Project, bucket.Name, nil)
+	if err != nil {
+		errorResponse(http.StatusServiceUnavailable, "ServiceUnavailable", err.Error())
+		return
+	}
+
+	minioURL := minioProc.URL()
+	proxy := httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = minioURL.Scheme
+			req.URL.Host = minioURL.Host
+			req.Host = minioURL.Host
+
+			s3.Sign(req, minioProc.AdminCredentials(), auth.Region, req.Header.Get("X-Amz-Content-Sha256"), time.Now())
+		},
+	}
+
+	proxy.ServeHTTP(w, r)
+}
@@ -40,8 +40,8 @@ var storagePoolCmd = APIEndpoint{
 
 	Delete: APIEndpointAction{Handler: storagePoolDelete},
 	Get:    APIEndpointAction{Handler: storagePoolGet, AccessHandler: allowAuthenticated},
-	Patch:  APIEndpointAction{Handler: storagePoolPatch},
-	Put:    APIEndpointAction{Handler: storagePoolPut},
+	Patch:  APIEndpointAction{Handler: storagePoolPatch, AccessHandler: allowStoragePoolPermission("manage-storage-pools")},
+	Put:    APIEndpointAction{Handler: storagePoolPut, AccessHandler: allowStoragePoolPermission("manage-storage-pools")},
 }
 
 // swagger:operation GET /1.0/storage-pools storage storage_pools_get
@@ -21,6 +21,7 @@ import (
 	"github.com/lxc/lxd/lxd/db"
 	"github.com/lxc/lxd/lxd/filter"
 	"github.com/lxc/lxd/lxd/instance"
+	"github.com/lxc/lxd/lxd/instance/instancetype"
 	"github.com/lxc/lxd/lxd/operations"
 	"github.com/lxc/lxd/lxd/project"
 	"github.com/lxc/lxd/lxd/rbac"
@@ -1038,7 +1039,11 @@ func storagePoolVolumePost(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	// Check if a running instance is using it.
+	// Check if a running instance is using it. A block volume used by a single running VM can still be moved to
+	// another pool, by mirroring the VM's disk to the new volume.
+	var runningInst instance.Instance
+	var runningDevices []string
+
 	err = storagePools.VolumeUsedByInstanceDevices(d.State(), srcPoolName, projectName, vol, true, func(dbInst db.Instance, project db.Project, profiles []api.Profile, usedByDevices []string) error {
 		inst, err := instance.Load(d.State(), db.InstanceToArgs(&dbInst), profiles)
 		if err != nil {
@@ -1046,7 +1051,12 @@ func storagePoolVolumePost(d *Daemon, r *http.Request) response.Response {
 		}
 
 		if inst.IsRunning() {
-			return fmt.Errorf("Volume is still in use by running instances")
+			if runningInst != nil || inst.Type() != instancetype.VM {
+				return fmt.Errorf("Volume is still in use by running instances")
+			}
+
+			runningInst = inst
+			runningDevices = usedByDevices
 		}
 
 		return nil
@@ -1055,6 +1065,17 @@ func storagePoolVolumePost(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	if runningInst != nil {
+		// Only the pool can be changed and the VM's disk device must be its own, as profile changes would
+		// affect the running VM.
+		_, isLocal := runningInst.LocalDevices()[runningDevices[0]]
+		if req.Pool == "" || req.Pool == srcPoolName || req.Name != vol.Name || projectName != targetProjectName || vol.ContentType != db.StoragePoolVolumeContentTypeNameBlock || len(runningDevices) != 1 || !isLocal {
+			return response.SmartError(fmt.Errorf("Volume is still in use by running instances"))
+		}
+
+		return storagePoolVolumeTypePostMoveLive(d, r, srcPoolName, projectName, vol, req, runningInst, runningDevices[0])
+	}
+
 	// Detect a rename request.
 	if (req.Pool == "" || req.Pool == srcPoolName) && (projectName == targetProjectName) {
 		return storagePoolVolumeTypePostRename(d, r, srcPoolName, projectName, vol, req)
@@ -1184,6 +1205,109 @@ func storagePoolVolumeTypePostMove(d *Daemon, r *http.Request, poolName string,
 	return operations.OperationResponse(op)
 }
 
+// storagePoolVolumeTypePostMoveLive handles volume move type POST requests for a block volume used by a running
+// VM. The VM's disk is mirrored to the volume on the new pool before switching over to it.
+func storagePoolVolumeTypePostMoveLive(d *Daemon, r *http.Request, poolName string, projectName string, vol *api.StorageVolume, req api.StorageVolumePost, inst instance.Instance, devName string) response.Response {
+	vm, ok := inst.(instance.VM)
+	if !ok {
+		return response.SmartError(fmt.Errorf("Instance is not a VM"))
+	}
+
+	pool, err := storagePools.LoadByName(d.State(), poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	newPool, err := storagePools.LoadByName(d.State(), req.Pool)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	newVol := *vol
+	newVol.Name = req.Name
+
+	run := func(op *operations.Operation) error {
+		revert := revert.New()
+		defer revert.Fail()
+
+		// The copy is inconsistent as the VM keeps writing to the volume, which is addressed by mirroring
+		// the VM's disk to it below.
+		err = newPool.CreateCustomVolumeFromCopy(projectName, projectName, newVol.Name, "", nil, pool.Name(), vol.Name, true, op)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() { newPool.DeleteCustomVolume(projectName, newVol.Name, op) })
+
+		// The new volume remains mounted until the VM's disk device is stopped.
+		err = newPool.MountCustomVolume(projectName, newVol.Name, op)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() { newPool.UnmountCustomVolume(projectName, newVol.Name, op) })
+
+		diskPath, err := newPool.GetCustomVolumeDisk(projectName, newVol.Name)
+		if err != nil {
+			return err
+		}
+
+		err = instanceMoveDiskLive(vm, devName, newPool, diskPath, op)
+		if err != nil {
+			return err
+		}
+
+		// The VM is now using the new pool, so there's no going back.
+		revert.Success()
+
+		// Update the VM's device directly, as updating the running VM would re-attach its disk.
+		localDevices := inst.LocalDevices().Clone()
+		localDevices[devName]["pool"] = newPool.Name()
+
+		err = d.State().Cluster.Transaction(func(tx *db.ClusterTx) error {
+			devices, err := db.APIToDevices(localDevices.CloneNative())
+			if err != nil {
+				return err
+			}
+
+			return tx.UpdateDevice("instance", inst.ID(), devices)
+		})
+		if err != nil {
+			return fmt.Errorf("Failed updating instance device %q: %w", devName, err)
+		}
+
+		// Update devices using the volume in other instances and profiles.
+		err = storagePoolVolumeUpdateUsers(d, projectName, pool.Name(), vol, newPool.Name(), &newVol)
+		if err != nil {
+			return err
+		}
+
+		_, err = pool.UnmountCustomVolume(projectName, vol.Name, op)
+		if err != nil {
+			return err
+		}
+
+		err = pool.DeleteCustomVolume(projectName, vol.Name, op)
+		if err != nil {
+			return err
+		}
+
+		inst, err := instance.LoadByProjectAndName(d.State(), inst.Project(), inst.Name())
+		if err != nil {
+			return err
+		}
+
+		return inst.UpdateBackupFile()
+	}
+
+	op, err := operations.OperationCreate(d.State(), projectName, operations.OperationClassTask, db.OperationVolumeMove, nil, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
 // storageGetVolumeNameFromURL retrieves the volume name from the URL name segment.
 func storageGetVolumeNameFromURL(r *http.Request) (string, error) {
 	fields := strings.Split(mux.Vars(r)["name"], "/")
@@ -1242,6 +1242,13 @@ func autoCreateCustomVolumeSnapshots(ctx context.Context, d *Daemon, volumes []d
 			err = pool.CreateCustomVolumeSnapshot(v.ProjectName, v.Name, snapshotName, expiry, nil)
 			if err != nil {
 				logger.Error("Error creating volume snapshot", logger.Ctx{"err": err, "volume": v})
+				ch <- struct{}{}
+				return
+			}
+
+			// Queue pushing the new snapshot to the replication target if configured.
+			if v.Config["snapshots.replicate.target"] != "" {
+				customVolumeReplicateQueue(d.State(), v.ProjectName, v.PoolName, v.Name, snapshotName)
 			}
 
 			ch <- struct{}{}
@@ -0,0 +1,317 @@
+package syslog
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/lxc/lxd/lxd/events"
+	"github.com/lxc/lxd/shared"
+	"github.com/lxc/lxd/shared/api"
+	"github.com/lxc/lxd/shared/logger"
+)
+
+const (
+	// Number of events which can be queued while the syslog server is slow or unreachable.
+	bufferSize = 1024
+
+	// Retry settings for failed writes.
+	minBackoff = 500 * time.Millisecond
+	maxBackoff = time.Minute
+	maxRetries = 10
+
+	// Timeout for connecting and writing a single message.
+	timeout = 10 * time.Second
+
+	// Facility used for all messages (daemon).
+	facility = 3
+
+	// Application name used in the messages.
+	appName = "lxd"
+)
+
+// Syslog severities.
+const (
+	severityCritical = 2
+	severityError    = 3
+	severityWarning  = 4
+	severityNotice   = 5
+	severityInfo     = 6
+	severityDebug    = 7
+)
+
+// ParseAddress parses a syslog server address of the form [udp|tcp://]<host>:<port>.
+// Returns the network and the address to dial. UDP is used if no scheme is given.
+func ParseAddress(address string) (string, string, error) {
+	network := "udp"
+	hostPort := address
+
+	if strings.Contains(address, "://") {
+		u, err := url.Parse(address)
+		if err != nil {
+			return "", "", err
+		}
+
+		if u.Scheme != "udp" && u.Scheme != "tcp" {
+			return "", "", fmt.Errorf("Unsupported scheme %q, must be udp or tcp", u.Scheme)
+		}
+
+		if u.Path != "" && u.Path != "/" {
+			return "", "", fmt.Errorf("Address must not contain a path")
+		}
+
+		network = u.Scheme
+		hostPort = u.Host
+	}
+
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return "", "", err
+	}
+
+	if host == "" || port == "" {
+		return "", "", fmt.Errorf("Address must contain a host and a port")
+	}
+
+	return network, hostPort, nil
+}
+
+// Client sends events to a syslog server using the RFC 5424 format.
+type Client struct {
+	network  string
+	address  string
+	logLevel logrus.Level
+	types    []string
+	hostname string
+
+	conn     net.Conn
+	messages chan string
+	dropped  uint64
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+// NewClient returns a Client sending events of the given types to the syslog server at the given address.
+// Logging events are only sent if their level is at least logLevel.
+func NewClient(address string, logLevel string, types []string) (*Client, error) {
+	network, hostPort, err := ParseAddress(address)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid syslog address %q: %w", address, err)
+	}
+
+	level, err := logrus.ParseLevel(logLevel)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid log level %q: %w", logLevel, err)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c := &Client{
+		network:  network,
+		address:  hostPort,
+		logLevel: level,
+		types:    types,
+		hostname: hostname,
+		messages: make(chan string, bufferSize),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	c.wg.Add(1)
+	go c.run()
+
+	return c, nil
+}
+
+// Stop stops the client, flushing the messages which are already queued.
+func (c *Client) Stop() {
+	c.once.Do(func() {
+		c.cancel()
+		c.wg.Wait()
+	})
+}
+
+// HandleEvent queues an event to be sent to the syslog server.
+// It's called with the event server lock held, so it never blocks nor logs. If the queue is full, the event is
+// dropped and counted, and the dropped events are reported by the sending goroutine.
+func (c *Client) HandleEvent(event api.Event) {
+	if !shared.StringInSlice(event.Type, c.types) {
+		return
+	}
+
+	record, err := events.NewRecord(event)
+	if err != nil {
+		return
+	}
+
+	severity := severityInfo
+	switch event.Type {
+	case "logging":
+		level, err := logrus.ParseLevel(record.Level)
+		if err != nil || level > c.logLevel {
+			return
+		}
+
+		severity = levelSeverity(level)
+	case "lifecycle":
+		severity = severityNotice
+	default:
+		if record.Level == "error" {
+			severity = severityError
+		}
+	}
+
+	hostname := event.Location
+	if hostname == "" {
+		hostname = c.hostname
+	}
+
+	msg := formatMessage(severity, event.Timestamp, hostname, event.Type, event.Project, record)
+
+	select {
+	case c.messages <- msg:
+	case <-c.ctx.Done():
+	default:
+		atomic.AddUint64(&c.dropped, 1)
+	}
+}
+
+// levelSeverity converts a log level to a syslog severity.
+func levelSeverity(level logrus.Level) int {
+	switch level {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return severityCritical
+	case logrus.ErrorLevel:
+		return severityError
+	case logrus.WarnLevel:
+		return severityWarning
+	case logrus.InfoLevel:
+		return severityInfo
+	}
+
+	return severityDebug
+}
+
+// formatMessage returns an RFC 5424 message.
+func formatMessage(severity int, timestamp time.Time, hostname string, msgID string, projectName string, record *events.Record) string {
+	msg := record.String()
+	if projectName != "" {
+		msg = fmt.Sprintf("project=%q %s", projectName, msg)
+	}
+
+	// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+	return fmt.Sprintf("<%d>1 %s %s %s %d %s - %s", facility*8+severity, timestamp.UTC().Format(time.RFC3339Nano), hostname, appName, os.Getpid(), msgID, msg)
+}
+
+// run sends the queued messages.
+func (c *Client) run() {
+	defer c.wg.Done()
+	defer c.disconnect()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			// Flush whatever is left, without retrying.
+			for {
+				select {
+				case msg := <-c.messages:
+					_ = c.write(msg)
+					continue
+				default:
+				}
+
+				return
+			}
+
+		case msg := <-c.messages:
+			c.writeWithRetries(msg)
+			c.reportDropped()
+		}
+	}
+}
+
+// reportDropped logs and resets the number of events dropped as the queue was full.
+func (c *Client) reportDropped() {
+	dropped := atomic.SwapUint64(&c.dropped, 0)
+	if dropped > 0 {
+		logger.Warn("Dropped events as the syslog event queue was full", logger.Ctx{"address": c.address, "dropped": dropped})
+	}
+}
+
+// writeWithRetries writes a message, reconnecting with an exponential backoff on failures.
+// While it's retrying, new events accumulate in the queue.
+func (c *Client) writeWithRetries(msg string) {
+	backoff := minBackoff
+
+	for i := 0; i < maxRetries; i++ {
+		err := c.write(msg)
+		if err == nil {
+			return
+		}
+
+		c.disconnect()
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+
+	logger.Warn("Giving up sending event to syslog server", logger.Ctx{"address": c.address, "retries": maxRetries})
+}
+
+// write sends a single message, connecting to the server if needed.
+// Messages sent over TCP use octet counting framing (RFC 6587).
+func (c *Client) write(msg string) error {
+	if c.conn == nil {
+		conn, err := net.DialTimeout(c.network, c.address, timeout)
+		if err != nil {
+			return err
+		}
+
+		c.conn = conn
+	}
+
+	err := c.conn.SetWriteDeadline(time.Now().Add(timeout))
+	if err != nil {
+		return err
+	}
+
+	if c.network == "tcp" {
+		msg = fmt.Sprintf("%d %s", len(msg), msg)
+	}
+
+	_, err = c.conn.Write([]byte(msg))
+
+	return err
+}
+
+// disconnect closes the connection to the server.
+func (c *Client) disconnect() {
+	if c.conn != nil {
+		_ = c.conn.Close()
+		c.conn = nil
+	}
+}
@@ -0,0 +1,135 @@
+package syslog
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/lxd/shared/api"
+)
+
+func TestParseAddress(t *testing.T) {
+	cases := []struct {
+		address  string
+		network  string
+		hostPort string
+		valid    bool
+	}{
+		{"10.0.0.1:514", "udp", "10.0.0.1:514", true},
+		{"udp://10.0.0.1:514", "udp", "10.0.0.1:514", true},
+		{"tcp://[2001:db8::1]:601", "tcp", "[2001:db8::1]:601", true},
+		{"tcp://syslog.example.com:601", "tcp", "syslog.example.com:601", true},
+		{"http://10.0.0.1:514", "", "", false},
+		{"tcp://10.0.0.1", "", "", false},
+		{"10.0.0.1", "", "", false},
+		{"tcp://10.0.0.1:514/foo", "", "", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.address, func(t *testing.T) {
+			network, hostPort, err := ParseAddress(c.address)
+			if !c.valid {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, c.network, network)
+			assert.Equal(t, c.hostPort, hostPort)
+		})
+	}
+}
+
+func newEvent(t *testing.T, eventType string, metadata any) api.Event {
+	data, err := json.Marshal(metadata)
+	require.NoError(t, err)
+
+	return api.Event{
+		Type:      eventType,
+		Timestamp: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+		Metadata:  data,
+		Project:   "default",
+		Location:  "node1",
+	}
+}
+
+func TestClient_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	client, err := NewClient(conn.LocalAddr().String(), "info", []string{"lifecycle", "logging"})
+	require.NoError(t, err)
+	defer client.Stop()
+
+	client.HandleEvent(newEvent(t, "operation", api.Operation{ID: "1234"}))
+	client.HandleEvent(newEvent(t, "logging", api.EventLogging{Message: "Ignored", Level: "debug"}))
+	client.HandleEvent(newEvent(t, "lifecycle", api.EventLifecycle{
+		Action:    "instance-deleted",
+		Source:    "/1.0/instances/c1",
+		Requestor: &api.EventLifecycleRequestor{Username: "alice", Protocol: "tls"},
+	}))
+
+	client.HandleEvent(newEvent(t, "logging", api.EventLogging{Message: "Disk full", Level: "warn"}))
+
+	err = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	require.NoError(t, err)
+
+	buf := make([]byte, 4096)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+
+	pattern := regexp.MustCompile(`^<29>1 2023-01-02T03:04:05Z node1 lxd [0-9]+ lifecycle - project="default" msg="instance-deleted" requestor-address="" requestor-protocol="tls" requestor-username="alice" source="/1.0/instances/c1"$`)
+	assert.Regexp(t, pattern, string(buf[:n]))
+
+	n, _, err = conn.ReadFrom(buf)
+	require.NoError(t, err)
+
+	assert.Regexp(t, regexp.MustCompile(`^<28>1 .* logging - project="default" msg="Disk full"$`), string(buf[:n]))
+}
+
+func TestClient_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	client, err := NewClient("tcp://"+listener.Addr().String(), "info", []string{"lifecycle"})
+	require.NoError(t, err)
+	defer client.Stop()
+
+	for _, action := range []string{"instance-created", "instance-started"} {
+		client.HandleEvent(newEvent(t, "lifecycle", api.EventLifecycle{Action: action}))
+	}
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	err = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	require.NoError(t, err)
+
+	// Messages are framed using octet counting.
+	reader := bufio.NewReader(conn)
+	for _, action := range []string{"instance-created", "instance-started"} {
+		length, err := reader.ReadString(' ')
+		require.NoError(t, err)
+
+		n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+		require.NoError(t, err)
+
+		msg := make([]byte, n)
+		_, err = io.ReadFull(reader, msg)
+		require.NoError(t, err)
+
+		assert.Contains(t, string(msg), `msg="`+action+`"`)
+	}
+}
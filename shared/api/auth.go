@@ -0,0 +1,79 @@
+package api
+
+// AuthPermission represents a permission granted by an authorization group.
+// Refer to doc/authentication.md for details.
+//
+// swagger:model
+//
+// API extension: auth_groups
+type AuthPermission struct {
+	// Type of the entity the permission applies to (project, instance, network or storage_pool)
+	// Example: instance
+	EntityType string `json:"entity_type" yaml:"entity_type"`
+
+	// Project of the entity (for project, instance and network entities)
+	// Example: default
+	Project string `json:"project,omitempty" yaml:"project,omitempty"`
+
+	// Name of the entity (for instance, network and storage_pool entities)
+	// Example: c1
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Entitlement granted on the entity (can_view, can_edit, can_exec or can_manage_snapshots)
+	// Example: can_exec
+	Entitlement string `json:"entitlement" yaml:"entitlement"`
+}
+
+// AuthGroupPost used for renaming an authorization group.
+//
+// swagger:model
+//
+// API extension: auth_groups
+type AuthGroupPost struct {
+	// The new name for the group
+	// Example: operators
+	Name string `json:"name" yaml:"name"`
+}
+
+// AuthGroupPut used for updating an authorization group.
+//
+// swagger:model
+//
+// API extension: auth_groups
+type AuthGroupPut struct {
+	// Description of the group
+	// Example: Instance operators
+	Description string `json:"description" yaml:"description"`
+
+	// List of permissions granted to members of the group
+	Permissions []AuthPermission `json:"permissions" yaml:"permissions"`
+
+	// List of fingerprints of the trusted certificates that are members of the group
+	// Example: ["fd200419b271f1dc2a5591b693cc5774b7f234e1ff8c6b78ad703b6888fe2b69"]
+	Identities []string `json:"identities" yaml:"identities"`
+}
+
+// AuthGroup used for displaying an authorization group.
+//
+// swagger:model
+//
+// API extension: auth_groups
+type AuthGroup struct {
+	AuthGroupPost `yaml:",inline"`
+	AuthGroupPut  `yaml:",inline"`
+}
+
+// Writable converts a full AuthGroup struct into a AuthGroupPut struct (filters read-only fields).
+func (group *AuthGroup) Writable() AuthGroupPut {
+	return group.AuthGroupPut
+}
+
+// AuthGroupsPost used for creating an authorization group.
+//
+// swagger:model
+//
+// API extension: auth_groups
+type AuthGroupsPost struct {
+	AuthGroupPost `yaml:",inline"`
+	AuthGroupPut  `yaml:",inline"`
+}
@@ -33,6 +33,12 @@ type InstanceBackupsPost struct {
 	//
 	// API extension: backup_compression_algorithm
 	CompressionAlgorithm string `json:"compression_algorithm" yaml:"compression_algorithm"`
+
+	// Checkpoint of the previous backup to only include the root disk blocks changed since (virtual machines only)
+	// Example: 2e9b3b3a-6b1d-4d4a-9a6b-1f3f2c9a7e51
+	//
+	// API extension: backup_vm_incremental
+	IncrementalFrom string `json:"incremental_from" yaml:"incremental_from"`
 }
 
 // InstanceBackup represents a LXD instance backup.
@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 // InstanceExecControl represents a message on the instance exec "control" socket.
 //
 // API extension: instances
@@ -53,4 +57,68 @@ type InstanceExecPost struct {
 	// Current working directory for the command
 	// Example: /home/foo/
 	Cwd string `json:"cwd" yaml:"cwd"`
+
+	// Whether the command keeps running in a session the client can detach from and reattach to (requires interactive)
+	// Example: false
+	//
+	// API extension: instance_exec_sessions
+	Detachable bool `json:"detachable" yaml:"detachable"`
+}
+
+// InstanceExecSession represents a detachable exec session of a LXD instance.
+//
+// swagger:model
+//
+// API extension: instance_exec_sessions
+type InstanceExecSession struct {
+	// Session ID
+	// Example: 0d9e5d1c-7d7b-4f6e-9a48-4b36dbd0a3b6
+	ID string `json:"id" yaml:"id"`
+
+	// Command and its arguments
+	// Example: ["bash"]
+	Command []string `json:"command" yaml:"command"`
+
+	// UID of the user the command runs as
+	// Example: 1000
+	User uint32 `json:"user" yaml:"user"`
+
+	// GID of the user the command runs as
+	// Example: 1000
+	Group uint32 `json:"group" yaml:"group"`
+
+	// Current working directory of the command
+	// Example: /home/foo/
+	Cwd string `json:"cwd" yaml:"cwd"`
+
+	// Session status (Running or Exited)
+	// Example: Running
+	Status string `json:"status" yaml:"status"`
+
+	// Whether a client is attached to the session
+	// Example: false
+	Attached bool `json:"attached" yaml:"attached"`
+
+	// Exit status of the command (-1 while running)
+	// Example: -1
+	Return int `json:"return" yaml:"return"`
+
+	// When the session was created
+	// Example: 2022-08-10T12:00:00Z
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+}
+
+// InstanceExecSessionPost represents a request to attach to a detachable exec session.
+//
+// swagger:model
+//
+// API extension: instance_exec_sessions
+type InstanceExecSessionPost struct {
+	// Terminal width in characters
+	// Example: 80
+	Width int `json:"width" yaml:"width"`
+
+	// Terminal height in rows
+	// Example: 24
+	Height int `json:"height" yaml:"height"`
 }
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"time"
+)
+
+// InstanceGuest represents the information reported by the guest of a LXD virtual machine.
+//
+// swagger:model
+//
+// API extension: instances_vm_guest_info
+type InstanceGuest struct {
+	// Operating system information
+	OS InstanceGuestOS `json:"os" yaml:"os"`
+
+	// Running processes
+	Processes []InstanceGuestProcess `json:"processes" yaml:"processes"`
+
+	// Logged-in users
+	Users []InstanceGuestUser `json:"users" yaml:"users"`
+
+	// Mounted filesystems
+	Filesystems []InstanceGuestFilesystem `json:"filesystems" yaml:"filesystems"`
+
+	// Installed packages per package manager
+	Packages []InstanceGuestPackages `json:"packages" yaml:"packages"`
+}
+
+// InstanceGuestOS represents the operating system information of a LXD virtual machine's guest.
+//
+// swagger:model
+//
+// API extension: instances_vm_guest_info
+type InstanceGuestOS struct {
+	// Operating system name
+	// Example: Ubuntu
+	Name string `json:"name" yaml:"name"`
+
+	// Operating system version
+	// Example: 22.04.1 LTS (Jammy Jellyfish)
+	Version string `json:"version" yaml:"version"`
+
+	// Operating system identifier
+	// Example: ubuntu
+	ID string `json:"id" yaml:"id"`
+
+	// Kernel name
+	// Example: Linux
+	Kernel string `json:"kernel" yaml:"kernel"`
+
+	// Kernel version
+	// Example: 5.15.0-46-generic
+	KernelVersion string `json:"kernel_version" yaml:"kernel_version"`
+
+	// Kernel architecture
+	// Example: x86_64
+	KernelArchitecture string `json:"kernel_architecture" yaml:"kernel_architecture"`
+
+	// Hostname
+	// Example: v1
+	Hostname string `json:"hostname" yaml:"hostname"`
+}
+
+// InstanceGuestProcess represents a process running in a LXD virtual machine's guest.
+//
+// swagger:model
+//
+// API extension: instances_vm_guest_info
+type InstanceGuestProcess struct {
+	// Process ID
+	// Example: 1
+	PID int64 `json:"pid" yaml:"pid"`
+
+	// Parent process ID
+	// Example: 0
+	PPID int64 `json:"ppid" yaml:"ppid"`
+
+	// Name of the user running the process
+	// Example: root
+	User string `json:"user" yaml:"user"`
+
+	// Process state
+	// Example: S
+	State string `json:"state" yaml:"state"`
+
+	// Command line
+	// Example: /sbin/init
+	Command string `json:"command" yaml:"command"`
+
+	// Resident memory in bytes
+	// Example: 13107200
+	Memory int64 `json:"memory" yaml:"memory"`
+
+	// CPU time in nanoseconds
+	// Example: 3200000000
+	CPUTime int64 `json:"cpu_time" yaml:"cpu_time"`
+}
+
+// InstanceGuestUser represents a user logged into a LXD virtual machine's guest.
+//
+// swagger:model
+//
+// API extension: instances_vm_guest_info
+type InstanceGuestUser struct {
+	// User name
+	// Example: ubuntu
+	Name string `json:"name" yaml:"name"`
+
+	// Terminal
+	// Example: pts/0
+	Terminal string `json:"terminal" yaml:"terminal"`
+
+	// Remote host the user logged in from
+	// Example: 10.0.0.1
+	Host string `json:"host" yaml:"host"`
+
+	// Login time
+	// Example: 2022-08-10T12:00:00Z
+	LoginAt time.Time `json:"login_at" yaml:"login_at"`
+}
+
+// InstanceGuestFilesystem represents a filesystem mounted in a LXD virtual machine's guest.
+//
+// swagger:model
+//
+// API extension: instances_vm_guest_info
+type InstanceGuestFilesystem struct {
+	// Mount point
+	// Example: /
+	Mountpoint string `json:"mountpoint" yaml:"mountpoint"`
+
+	// Source device
+	// Example: /dev/sda2
+	Device string `json:"device" yaml:"device"`
+
+	// Filesystem type
+	// Example: ext4
+	Type string `json:"type" yaml:"type"`
+
+	// Total size in bytes
+	// Example: 10737418240
+	Total int64 `json:"total" yaml:"total"`
+
+	// Used space in bytes
+	// Example: 2147483648
+	Used int64 `json:"used" yaml:"used"`
+}
+
+// InstanceGuestPackages represents the packages installed with a package manager in a LXD virtual machine's guest.
+//
+// swagger:model
+//
+// API extension: instances_vm_guest_info
+type InstanceGuestPackages struct {
+	// Package manager
+	// Example: dpkg
+	Manager string `json:"manager" yaml:"manager"`
+
+	// Number of installed packages
+	// Example: 612
+	Count int64 `json:"count" yaml:"count"`
+}
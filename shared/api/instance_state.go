@@ -56,6 +56,62 @@ type InstanceState struct {
 
 	// CPU usage information
 	CPU InstanceStateCPU `json:"cpu" yaml:"cpu"`
+
+	// Guest memory encryption information (virtual machines only)
+	//
+	// API extension: instances_vm_confidential
+	MemoryEncryption *InstanceStateMemoryEncryption `json:"memory_encryption,omitempty" yaml:"memory_encryption,omitempty"`
+
+	// Virtual NUMA nodes and their placement on the host (virtual machines only)
+	//
+	// API extension: instances_vm_numa
+	NUMANodes []InstanceStateNUMANode `json:"numa_nodes,omitempty" yaml:"numa_nodes,omitempty"`
+}
+
+// InstanceStateMemoryEncryption represents the guest memory encryption section of a LXD virtual machine's state.
+//
+// swagger:model
+//
+// API extension: instances_vm_confidential
+type InstanceStateMemoryEncryption struct {
+	// Memory encryption technology (sev, sev-es or tdx)
+	// Example: sev-es
+	Type string `json:"type" yaml:"type"`
+
+	// Guest policy (AMD SEV only)
+	// Example: 7
+	Policy uint32 `json:"policy,omitempty" yaml:"policy,omitempty"`
+
+	// Base64 encoded launch measurement to use for remote attestation (AMD SEV only)
+	// Example: Xr0cOVUqfXzN8Fz8SAm4mDA0rlHBLQpnMrRhX8k4r2i4nN1fQ6vE4i5n1a7Xv8G7
+	Measurement string `json:"measurement,omitempty" yaml:"measurement,omitempty"`
+}
+
+// InstanceStateNUMANode represents a virtual NUMA node section of a LXD virtual machine's state.
+//
+// swagger:model
+//
+// API extension: instances_vm_numa
+type InstanceStateNUMANode struct {
+	// Virtual NUMA node identifier
+	// Example: 0
+	ID uint64 `json:"id" yaml:"id"`
+
+	// vCPUs of the node
+	// Example: [0, 1, 2, 3]
+	CPUs []uint64 `json:"cpus" yaml:"cpus"`
+
+	// Memory of the node in bytes
+	// Example: 4294967296
+	Memory int64 `json:"memory" yaml:"memory"`
+
+	// Host NUMA nodes whose CPUs run the vCPUs of the node (empty if not bound)
+	// Example: [0]
+	HostCPUNodes []uint64 `json:"host_cpu_nodes" yaml:"host_cpu_nodes"`
+
+	// Host NUMA nodes the memory of the node is allocated from
+	// Example: [0]
+	HostMemoryNodes []uint64 `json:"host_memory_nodes" yaml:"host_memory_nodes"`
 }
 
 // InstanceStateDisk represents the disk information section of a LXD instance's state.
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"net"
+	"strings"
+)
+
+// NetworkAllocation used for displaying an address allocated on a managed network.
+//
+// swagger:model
+//
+// API extension: network_allocations
+type NetworkAllocation struct {
+	// The allocated address in CIDR notation
+	// Example: 198.51.100.2/32
+	Address string `json:"address" yaml:"address"`
+
+	// Name of the entity using the address
+	// Example: /1.0/instances/c1
+	UsedBy string `json:"used_by" yaml:"used_by"`
+
+	// Type of the entity using the address (instance, network, network-forward, network-load-balancer or network-reservation)
+	// Example: instance
+	Type string `json:"type" yaml:"type"`
+
+	// Whether the address is NATed
+	// Example: false
+	NAT bool `json:"nat" yaml:"nat"`
+
+	// Hardware address of the instance NIC using the address
+	// Example: 00:16:3e:2c:89:d9
+	Hwaddr string `json:"hwaddr" yaml:"hwaddr"`
+
+	// Name of the network the address belongs to
+	// Example: lxdbr0
+	Network string `json:"network" yaml:"network"`
+
+	// Project the entity using the address belongs to
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+}
+
+// NetworkReservationsPost represents the fields of a new LXD network address reservation
+//
+// swagger:model
+//
+// API extension: network_allocations
+type NetworkReservationsPost struct {
+	NetworkReservationPut `yaml:",inline"`
+
+	// The reserved address
+	// Example: 198.51.100.10
+	Address string `json:"address" yaml:"address"`
+}
+
+// Normalise normalises the fields in the reservation so that they are comparable with ones stored.
+func (r *NetworkReservationsPost) Normalise() {
+	ip := net.ParseIP(strings.TrimSpace(r.Address))
+	if ip != nil {
+		r.Address = ip.String() // Replace with canonical form if specified.
+	}
+
+	r.NetworkReservationPut.Normalise()
+}
+
+// NetworkReservationPut represents the modifiable fields of a LXD network address reservation
+//
+// swagger:model
+//
+// API extension: network_allocations
+type NetworkReservationPut struct {
+	// Description of the reservation
+	// Example: Address of the future web server
+	Description string `json:"description" yaml:"description"`
+
+	// Name of the instance the address is reserved for (optional)
+	// Example: c1
+	Instance string `json:"instance" yaml:"instance"`
+}
+
+// Normalise normalises the fields in the reservation so that they are comparable with ones stored.
+func (r *NetworkReservationPut) Normalise() {
+	r.Description = strings.TrimSpace(r.Description)
+	r.Instance = strings.TrimSpace(r.Instance)
+}
+
+// NetworkReservation used for displaying a network address reservation.
+//
+// swagger:model
+//
+// API extension: network_allocations
+type NetworkReservation struct {
+	NetworkReservationPut `yaml:",inline"`
+
+	// The reserved address
+	// Example: 198.51.100.10
+	Address string `json:"address" yaml:"address"`
+
+	// Project of the instance the address is reserved for
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+}
+
+// Etag returns the values used for etag generation.
+func (r *NetworkReservation) Etag() []any {
+	return []any{r.Address, r.Description, r.Instance, r.Project}
+}
+
+// Writable converts a full NetworkReservation struct into a NetworkReservationPut struct (filters read-only fields).
+func (r *NetworkReservation) Writable() NetworkReservationPut {
+	return r.NetworkReservationPut
+}
@@ -19,6 +19,12 @@ type NetworkPeersPost struct {
 	// Name of the target network
 	// Example: network1
 	TargetNetwork string `json:"target_network" yaml:"target_network"`
+
+	// Type of the peer (network or uplink)
+	// Example: network
+	//
+	// API extension: network_peer_invitations
+	Type string `json:"type" yaml:"type"`
 }
 
 // NetworkPeerPut represents the modifiable fields of a LXD network peering
@@ -64,6 +70,13 @@ type NetworkPeer struct {
 	// Example: Pending
 	Status string `json:"status" yaml:"status"`
 
+	// Type of the peer (network or uplink)
+	// Read only: true
+	// Example: network
+	//
+	// API extension: network_peer_invitations
+	Type string `json:"type" yaml:"type"`
+
 	// List of URLs of objects using this network peering
 	// Read only: true
 	// Example: ["/1.0/network-acls/test", "/1.0/network-acls/foo"]
@@ -79,3 +92,30 @@ func (p *NetworkPeer) Etag() []any {
 func (p *NetworkPeer) Writable() NetworkPeerPut {
 	return p.NetworkPeerPut
 }
+
+// NetworkPeerInvitation used for displaying a pending network peering from another network targeting this network.
+//
+// swagger:model
+//
+// API extension: network_peer_invitations
+type NetworkPeerInvitation struct {
+	// Name of the peer on the source network
+	// Read only: true
+	// Example: project1-network1
+	Name string `json:"name" yaml:"name"`
+
+	// Description of the peer on the source network
+	// Read only: true
+	// Example: Peering with network1 in project1
+	Description string `json:"description" yaml:"description"`
+
+	// Name of the project of the source network
+	// Read only: true
+	// Example: project2
+	SourceProject string `json:"source_project" yaml:"source_project"`
+
+	// Name of the source network
+	// Read only: true
+	// Example: network2
+	SourceNetwork string `json:"source_network" yaml:"source_network"`
+}
@@ -0,0 +1,57 @@
+package api
+
+// NetworkQoSPolicyPost used for renaming a network QoS policy.
+//
+// swagger:model
+//
+// API extension: network_qos
+type NetworkQoSPolicyPost struct {
+	// The new name for the QoS policy
+	// Example: bar
+	Name string `json:"name" yaml:"name"`
+}
+
+// NetworkQoSPolicyPut used for updating a network QoS policy.
+//
+// swagger:model
+//
+// API extension: network_qos
+type NetworkQoSPolicyPut struct {
+	// Description of the QoS policy
+	// Example: Tenant bandwidth limits
+	Description string `json:"description" yaml:"description"`
+
+	// QoS policy configuration map (refer to doc/howto/network_qos.md)
+	// Example: {"limits.ingress": "100Mbit", "limits.egress": "50Mbit", "priority": "low"}
+	Config map[string]string `json:"config" yaml:"config"`
+}
+
+// NetworkQoSPolicy used for displaying a network QoS policy.
+//
+// swagger:model
+//
+// API extension: network_qos
+type NetworkQoSPolicy struct {
+	NetworkQoSPolicyPost `yaml:",inline"`
+	NetworkQoSPolicyPut  `yaml:",inline"`
+
+	// List of URLs of objects using this QoS policy
+	// Read only: true
+	// Example: ["/1.0/instances/c1", "/1.0/networks/lxdbr0"]
+	UsedBy []string `json:"used_by" yaml:"used_by"`
+}
+
+// Writable converts a full NetworkQoSPolicy struct into a NetworkQoSPolicyPut struct (filters read-only fields).
+func (policy *NetworkQoSPolicy) Writable() NetworkQoSPolicyPut {
+	return policy.NetworkQoSPolicyPut
+}
+
+// NetworkQoSPoliciesPost used for creating a network QoS policy.
+//
+// swagger:model
+//
+// API extension: network_qos
+type NetworkQoSPoliciesPost struct {
+	NetworkQoSPolicyPost `yaml:",inline"`
+	NetworkQoSPolicyPut  `yaml:",inline"`
+}
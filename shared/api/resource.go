@@ -61,6 +61,12 @@ type ResourcesCPU struct {
 	// Total number of CPU threads (from all sockets and cores)
 	// Example: 1
 	Total uint64 `json:"total" yaml:"total"`
+
+	// Guest memory encryption technologies supported by the host (sev, sev-es or tdx)
+	// Example: ["sev", "sev-es"]
+	//
+	// API extension: instances_vm_confidential
+	MemoryEncryption []string `json:"memory_encryption,omitempty" yaml:"memory_encryption,omitempty"`
 }
 
 // ResourcesCPUSocket represents a CPU socket on the system
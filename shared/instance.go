@@ -158,20 +158,27 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 		_, err := GetSnapshotExpiry(time.Time{}, value)
 		return err
 	},
+	"snapshots.replicate.target":             validate.Optional(validate.IsListenAddress(true, false, false)),
+	"snapshots.replicate.target.fingerprint": validate.Optional(validate.IsCertificateFingerprint),
+	"snapshots.replicate.target.project":     validate.IsAny,
 
 	// Volatile keys.
-	"volatile.apply_template":         validate.IsAny,
-	"volatile.base_image":             validate.IsAny,
-	"volatile.cloud-init.instance-id": validate.Optional(validate.IsUUID),
-	"volatile.evacuate.origin":        validate.IsAny,
-	"volatile.last_state.idmap":       validate.IsAny,
-	"volatile.last_state.power":       validate.IsAny,
-	"volatile.idmap.base":             validate.IsAny,
-	"volatile.idmap.current":          validate.IsAny,
-	"volatile.idmap.next":             validate.IsAny,
-	"volatile.apply_quota":            validate.IsAny,
-	"volatile.uuid":                   validate.Optional(validate.IsUUID),
-	"volatile.vsock_id":               validate.Optional(validate.IsInt64),
+	"volatile.apply_template":          validate.IsAny,
+	"volatile.backup.checkpoint":       validate.Optional(validate.IsUUID),
+	"volatile.base_image":              validate.IsAny,
+	"volatile.cloud-init.instance-id":  validate.Optional(validate.IsUUID),
+	"volatile.evacuate.origin":         validate.IsAny,
+	"volatile.last_state.idmap":        validate.IsAny,
+	"volatile.last_state.power":        validate.IsAny,
+	"volatile.move.source_pool":        validate.IsAny,
+	"volatile.idmap.base":              validate.IsAny,
+	"volatile.idmap.current":           validate.IsAny,
+	"volatile.idmap.next":              validate.IsAny,
+	"volatile.apply_quota":             validate.IsAny,
+	"volatile.uuid":                    validate.Optional(validate.IsUUID),
+	"volatile.vsock_id":                validate.Optional(validate.IsInt64),
+	"volatile.replicate.last_snapshot": validate.IsAny,
+	"volatile.replicate.last_success":  validate.IsAny,
 
 	// Caller is responsible for full validation of any raw.* value.
 	"raw.idmap": validate.IsAny,
@@ -234,6 +241,11 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	"nvidia.require.cuda":        validate.IsAny,
 	"nvidia.require.driver":      validate.IsAny,
 
+	"oci.entrypoint": validate.IsAny,
+	"oci.cwd":        validate.Optional(validate.IsAbsFilePath),
+	"oci.uid":        validate.Optional(validate.IsUint32),
+	"oci.gid":        validate.Optional(validate.IsUint32),
+
 	// Caller is responsible for full validation of any raw.* value.
 	"raw.lxc":     validate.IsAny,
 	"raw.seccomp": validate.IsAny,
@@ -269,7 +281,9 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 
 // InstanceConfigKeysVM is a map of config key to validator. (keys applying to VM only)
 var InstanceConfigKeysVM = map[string]func(value string) error{
+	"limits.cpu.nodes":        validate.Optional(validate.IsListOf(validate.IsUint32Range)),
 	"limits.memory.hugepages": validate.Optional(validate.IsBool),
+	"limits.memory.nodes":     validate.Optional(validate.IsListOf(validate.IsUint32Range)),
 
 	"migration.stateful": validate.Optional(validate.IsBool),
 
@@ -278,6 +292,11 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 
 	"security.agent.metrics": validate.Optional(validate.IsBool),
 	"security.secureboot":    validate.Optional(validate.IsBool),
+	"security.sev":           validate.Optional(validate.IsBool),
+	"security.sev.policy.es": validate.Optional(validate.IsBool),
+	"security.tdx":           validate.Optional(validate.IsBool),
+
+	"snapshots.consistent": validate.Optional(validate.IsBool),
 
 	"agent.nic_config": validate.Optional(validate.IsBool),
 }
@@ -342,6 +361,10 @@ func ConfigKeyChecker(key string, instanceType instancetype.Type) (func(value st
 			return validate.IsAny, nil
 		}
 
+		if strings.HasSuffix(key, ".last_state.parent") {
+			return validate.IsAny, nil
+		}
+
 		if strings.HasSuffix(key, ".apply_quota") {
 			return validate.IsAny, nil
 		}
@@ -2,6 +2,8 @@ package validate
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net"
 	"os/exec"
@@ -636,6 +638,16 @@ func IsUUID(value string) error {
 	return nil
 }
 
+// IsCertificateFingerprint validates whether a value is a full SHA-256 certificate fingerprint.
+func IsCertificateFingerprint(value string) error {
+	fingerprint, err := hex.DecodeString(value)
+	if err != nil || len(fingerprint) != sha256.Size {
+		return fmt.Errorf("Invalid certificate fingerprint")
+	}
+
+	return nil
+}
+
 // IsPCIAddress validates whether a value is a PCI address.
 func IsPCIAddress(value string) error {
 	regexHex, err := regexp.Compile(`^([0-9a-fA-F]{4}?:)?[0-9a-fA-F]{2}:[0-9a-fA-F]{2}\.[0-9a-fA-F]$`)
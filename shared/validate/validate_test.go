@@ -29,6 +29,25 @@ func ExampleIsNetworkMAC() {
 	// , false
 }
 
+func ExampleIsCertificateFingerprint() {
+	tests := []string{
+		"7b6b6b3f6c9fa3ed8d7a4e2ef4d4b8e1a7b0c7e5a8b2a9b0e1c2d3f4a5b6c7d8",
+		"7b6b6b3f6c9fa3ed", // too short
+		"zb6b6b3f6c9fa3ed8d7a4e2ef4d4b8e1a7b0c7e5a8b2a9b0e1c2d3f4a5b6c7d8", // invalid hex
+		"",
+	}
+
+	for _, v := range tests {
+		err := validate.IsCertificateFingerprint(v)
+		fmt.Printf("%s, %t\n", v, err == nil)
+	}
+
+	// Output: 7b6b6b3f6c9fa3ed8d7a4e2ef4d4b8e1a7b0c7e5a8b2a9b0e1c2d3f4a5b6c7d8, true
+	// 7b6b6b3f6c9fa3ed, false
+	// zb6b6b3f6c9fa3ed8d7a4e2ef4d4b8e1a7b0c7e5a8b2a9b0e1c2d3f4a5b6c7d8, false
+	// , false
+}
+
 func ExampleIsPCIAddress() {
 	tests := []string{
 		"0000:12:ab.0", // valid
@@ -328,6 +328,32 @@ var APIExtensions = []string{
 	"container_syscall_intercept_sched_setscheduler",
 	"storage_lvm_thinpool_metadata_size",
 	"storage_volume_state_total",
+	"network_load_balancer",
+	"storage_buckets",
+	"oidc",
+	"auth_groups",
+	"instances_placement_scriptlet",
+	"cluster_healing",
+	"event_log_targets",
+	"metrics_daemon",
+	"storage_lvm_clustered",
+	"snapshot_replication",
+	"migration_vm_live",
+	"image_oci",
+	"instances_vm_hotplug",
+	"storage_vm_live_move",
+	"backup_vm_incremental",
+	"instances_vm_confidential",
+	"instances_vm_numa",
+	"instances_vm_consistent_snapshots",
+	"instances_vm_guest_info",
+	"instance_exec_sessions",
+	"network_bridge_evpn",
+	"network_acl_nic_routed_ipvlan",
+	"network_qos",
+	"network_allocations",
+	"network_peer_invitations",
+	"network_acl_nic_macvlan",
 }
 
 // APIExtensionsCount returns the number of available API extensions.
@@ -0,0 +1,29 @@
+# Test helper for OpenID Connect authentication
+
+spawn_oidc() {
+    (
+        cd mini-oidc || return
+        go build ./...
+    )
+
+    mini-oidc/mini-oidc -port-file "${TEST_DIR}/oidc.port" -user-file "${TEST_DIR}/oidc.user" &
+    echo $! > "${TEST_DIR}/oidc.pid"
+
+    # Wait for the provider to be listening.
+    for _ in $(seq 50); do
+        [ -s "${TEST_DIR}/oidc.port" ] && break
+        sleep 0.1
+    done
+}
+
+set_oidc() {
+    echo "$1" > "${TEST_DIR}/oidc.user"
+}
+
+kill_oidc() {
+    [ -e "${TEST_DIR}/oidc.pid" ] || return 0
+
+    kill -9 "$(cat "${TEST_DIR}/oidc.pid")" || true
+    rm -f "${TEST_DIR}/oidc.pid" "${TEST_DIR}/oidc.port" "${TEST_DIR}/oidc.user"
+    rm -f mini-oidc/mini-oidc
+}
@@ -89,6 +89,7 @@ cleanup() {
 
   umount -l "${TEST_DIR}/dev"
   kill_external_auth_daemon "$TEST_DIR"
+  kill_oidc
   cleanup_lxds "$TEST_DIR"
 
   echo ""
@@ -196,6 +197,7 @@ if [ "${1:-"all"}" != "standalone" ]; then
     # run_test test_clustering_upgrade "clustering upgrade"
     run_test test_clustering_groups "clustering groups"
     run_test test_clustering_events "clustering events"
+    run_test test_clustering_instance_placement_scriptlet "clustering instance placement scriptlet"
 fi
 
 if [ "${1:-"all"}" != "cluster" ]; then
@@ -248,6 +250,7 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_snap_restore "snapshot restores"
     run_test test_snap_expiry "snapshot expiry"
     run_test test_snap_schedule "snapshot scheduling"
+    run_test test_snap_replicate "snapshot replication"
     run_test test_config_profiles "profiles and configuration"
     run_test test_config_edit "container configuration edit"
     run_test test_config_edit_container_snapshot_pool_config "container and snapshot volume configuration edit"
@@ -258,6 +261,9 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_network "network management"
     run_test test_network_acl "network ACL management"
     run_test test_network_forward "network address forwards"
+    run_test test_network_load_balancer "network load balancers"
+    run_test test_network_qos "network QoS policies"
+    run_test test_network_allocations "network allocations"
     run_test test_network_zone "network DNS zones"
     run_test test_idmap "id mapping"
     run_test test_template "file templating"
@@ -277,9 +283,13 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_storage_driver_btrfs "btrfs storage driver"
     run_test test_storage_driver_ceph "ceph storage driver"
     run_test test_storage_driver_cephfs "cephfs storage driver"
+    run_test test_storage_driver_lvmcluster "lvmcluster storage driver"
+    run_test test_storage_buckets "storage buckets"
     run_test test_resources "resources"
     run_test test_kernel_limits "kernel limits"
     run_test test_macaroon_auth "macaroon authentication"
+    run_test test_oidc "OpenID Connect authentication"
+    run_test test_auth_groups "authorization groups"
     run_test test_console "console"
     run_test test_query "query"
     run_test test_storage_local_volume_handling "storage local volume handling"
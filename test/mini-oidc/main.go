@@ -0,0 +1,184 @@
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// A minimal OpenID Connect provider for testing. It supports the device authorization grant and approves any
+// login request immediately, issuing tokens for the user read from the user file (defaults to "user1").
+
+var (
+	issuer   string
+	key      *rsa.PrivateKey
+	userFile string
+)
+
+func main() {
+	listen := flag.String("listen", "127.0.0.1:0", "address to listen on")
+	portFile := flag.String("port-file", "", "file to write the listening port to")
+	flag.StringVar(&userFile, "user-file", "", "file containing the user to issue tokens for")
+	flag.Parse()
+
+	var err error
+	key, err = rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	l, err := net.Listen("tcp", *listen)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	port := l.Addr().(*net.TCPAddr).Port
+	issuer = fmt.Sprintf("http://127.0.0.1:%d", port)
+
+	if *portFile != "" {
+		err = os.WriteFile(*portFile, []byte(fmt.Sprintf("%d\n", port)), 0644)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", discovery)
+	mux.HandleFunc("/keys", keys)
+	mux.HandleFunc("/device", device)
+	mux.HandleFunc("/token", token)
+
+	log.Fatal(http.Serve(l, mux))
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                        issuer,
+		"jwks_uri":                      issuer + "/keys",
+		"device_authorization_endpoint": issuer + "/device",
+		"token_endpoint":                issuer + "/token",
+	})
+}
+
+func keys(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key1",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   b64(key.N.Bytes()),
+			"e":   b64(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+}
+
+func device(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"device_code":      "device-" + r.FormValue("client_id"),
+		"user_code":        "ABCD-EFGH",
+		"verification_uri": issuer + "/device",
+		"expires_in":       60,
+		"interval":         1,
+	})
+}
+
+func token(w http.ResponseWriter, r *http.Request) {
+	clientID := r.FormValue("client_id")
+
+	switch r.FormValue("grant_type") {
+	case "urn:ietf:params:oauth:grant-type:device_code":
+		if r.FormValue("device_code") != "device-"+clientID {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+	case "refresh_token":
+		if r.FormValue("refresh_token") != "refresh-"+clientID {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	accessToken, err := sign(clientID, r.FormValue("audience"))
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token":  accessToken,
+		"refresh_token": "refresh-" + clientID,
+		"token_type":    "Bearer",
+		"expires_in":    300,
+	})
+}
+
+// sign returns a signed access token for the current user.
+func sign(clientID string, audience string) (string, error) {
+	user := "user1"
+	if userFile != "" {
+		content, err := os.ReadFile(userFile)
+		if err == nil && strings.TrimSpace(string(content)) != "" {
+			user = strings.TrimSpace(string(content))
+		}
+	}
+
+	aud := []string{clientID}
+	if audience != "" {
+		aud = append(aud, audience)
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "kid": "key1", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := json.Marshal(map[string]any{
+		"iss": issuer,
+		"sub": user,
+		"aud": aud,
+		"azp": clientID,
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(5 * time.Minute).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signed := b64(header) + "." + b64(claims)
+	digest := sha256.Sum256([]byte(signed))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signed + "." + b64(signature), nil
+}
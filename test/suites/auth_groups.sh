@@ -0,0 +1,78 @@
+test_auth_groups() {
+  ensure_import_testimage
+  ensure_has_localhost_remote "${LXD_ADDR}"
+
+  lxc init testimage c1
+  lxc init testimage c2
+
+  # Create and trust a new client certificate
+  openssl req -x509 -newkey rsa:2048 -keyout "${TEST_DIR}/auth.key" -nodes -out "${TEST_DIR}/auth.crt" -subj "/CN=lxd.local"
+  lxc config trust add "${TEST_DIR}/auth.crt"
+  fingerprint="$(openssl x509 -in "${TEST_DIR}/auth.crt" -noout -fingerprint -sha256 | sed 's/.*=//; s/://g' | tr '[:upper:]' '[:lower:]')"
+
+  auth_query() {
+    curl -k -s --cert "${TEST_DIR}/auth.crt" --key "${TEST_DIR}/auth.key" -X "${1}" "https://${LXD_ADDR}${2}"
+  }
+
+  # The certificate isn't in any group yet so it has full access
+  auth_query GET "/1.0/instances" | grep -F "/1.0/instances/c2"
+  auth_query GET "/1.0/auth/groups" | grep "\"status_code\":200"
+
+  # Check validation
+  lxc auth group create viewers --description "Read-only users"
+  ! lxc auth group create viewers || false
+  ! lxc auth group permission add viewers image default/foo can_view || false
+  ! lxc auth group permission add viewers network default/lxdbr0 can_exec || false
+  ! lxc auth group permission add viewers instance c1 can_view || false
+  ! lxc auth group permission add viewers project nonexistent can_view || false
+  ! lxc auth group identity add viewers 0000000000000000000000000000000000000000000000000000000000000000 || false
+
+  # Grant view access to a single instance
+  lxc auth group permission add viewers instance default/c1 can_view
+  ! lxc auth group permission add viewers instance default/c1 can_view || false
+  lxc auth group identity add viewers "${fingerprint}"
+  lxc auth group show viewers | grep -F "${fingerprint}"
+  lxc auth group list | grep viewers
+
+  auth_query GET "/1.0/instances" | grep -F "/1.0/instances/c1"
+  ! auth_query GET "/1.0/instances" | grep -F "/1.0/instances/c2" || false
+  auth_query GET "/1.0/instances/c1" | grep "\"status_code\":200"
+  auth_query GET "/1.0/instances/c2" | grep "\"error_code\":403"
+  auth_query PUT "/1.0/instances/c1/state" | grep "\"error_code\":403"
+  auth_query POST "/1.0/instances" | grep "\"error_code\":403"
+  auth_query GET "/1.0/auth/groups" | grep "\"error_code\":403"
+
+  # Grant exec access to the instance
+  lxc auth group permission add viewers instance default/c1 can_exec
+  ! auth_query PUT "/1.0/instances/c1/state" | grep "\"error_code\":403" || false
+  auth_query PUT "/1.0/instances/c2/state" | grep "\"error_code\":403"
+  lxc auth group permission remove viewers instance default/c1 can_exec
+  ! lxc auth group permission remove viewers instance default/c1 can_exec || false
+
+  # Grant view access to the whole project
+  lxc auth group permission add viewers project default can_view
+  auth_query GET "/1.0/instances" | grep -F "/1.0/instances/c2"
+  auth_query GET "/1.0/instances/c2" | grep "\"status_code\":200"
+  auth_query PATCH "/1.0/instances/c2" | grep "\"error_code\":403"
+
+  # Renaming keeps the permissions
+  lxc auth group rename viewers auditors
+  ! lxc auth group show viewers || false
+  auth_query GET "/1.0/instances/c2" | grep "\"status_code\":200"
+  auth_query POST "/1.0/instances" | grep "\"error_code\":403"
+
+  # Removing the identity from its only group restores full access
+  lxc auth group identity remove auditors "${fingerprint}"
+  ! lxc auth group identity remove auditors "${fingerprint}" || false
+  auth_query GET "/1.0/auth/groups" | grep "\"status_code\":200"
+
+  # Removing the certificate removes it from its groups
+  lxc auth group identity add auditors "${fingerprint}"
+  lxc config trust remove "${fingerprint}"
+  ! lxc auth group show auditors | grep -F "${fingerprint}" || false
+
+  lxc auth group delete auditors
+  ! lxc auth group list | grep auditors || false
+
+  lxc delete c1 c2
+}
@@ -3481,3 +3481,100 @@ test_clustering_events() {
   kill_lxd "${LXD_FOUR_DIR}"
   kill_lxd "${LXD_FIVE_DIR}"
 }
+
+test_clustering_instance_placement_scriptlet() {
+  # shellcheck disable=2039
+  local LXD_DIR
+
+  setup_clustering_bridge
+  prefix="lxd$$"
+  bridge="${prefix}"
+
+  setup_clustering_netns 1
+  LXD_ONE_DIR=$(mktemp -d -p "${TEST_DIR}" XXX)
+  chmod +x "${LXD_ONE_DIR}"
+  ns1="${prefix}1"
+  spawn_lxd_and_bootstrap_cluster "${ns1}" "${bridge}" "${LXD_ONE_DIR}"
+
+  # Add a newline at the end of each line. YAML as weird rules..
+  cert=$(sed ':a;N;$!ba;s/\n/\n\n/g' "${LXD_ONE_DIR}/cluster.crt")
+
+  # Spawn a second node
+  setup_clustering_netns 2
+  LXD_TWO_DIR=$(mktemp -d -p "${TEST_DIR}" XXX)
+  chmod +x "${LXD_TWO_DIR}"
+  ns2="${prefix}2"
+  spawn_lxd_and_join_cluster "${ns2}" "${bridge}" "${cert}" 2 1 "${LXD_TWO_DIR}"
+
+  # Use node1 for all cluster actions.
+  LXD_DIR="${LXD_ONE_DIR}"
+
+  ensure_import_testimage
+
+  # Invalid scriptlets are rejected.
+  ! lxc config set instances.placement.scriptlet="def foo(" || false
+  ! lxc config set instances.placement.scriptlet="def foo(): pass" || false
+
+  # Place instances on a member in the requested rack.
+  lxc cluster set node1 user.rack rack1
+  lxc cluster set node2 user.rack rack2
+  lxc config set instances.placement.scriptlet="$(cat << EOF
+def instance_placement(request, candidate_members):
+    log_info("Placing ", request["name"], " in project ", request["project"], " for reason ", request["reason"])
+
+    if request["config"].get("user.reject", "") == "true":
+        fail("Rejected by placement scriptlet")
+
+    rack = request["config"].get("user.rack", "")
+    for member in candidate_members:
+        if member["config"].get("user.rack", "") != rack:
+            continue
+
+        resources = get_cluster_member_resources(member["server_name"])
+        if resources["memory"]["total"] > 0 and get_cluster_member_instance_count(member["server_name"]) >= 0:
+            set_target(member["server_name"])
+            return
+EOF
+)"
+
+  lxc init testimage c1 -c user.rack=rack2
+  lxc ls c1 -c nL --format csv | grep -Fx "c1,node2"
+
+  lxc init testimage c2 -c user.rack=rack2
+  lxc ls c2 -c nL --format csv | grep -Fx "c2,node2"
+
+  lxc init testimage c3 -c user.rack=rack1
+  lxc ls c3 -c nL --format csv | grep -Fx "c3,node1"
+
+  # Without a matching rack the scriptlet doesn't choose and the default placement applies.
+  lxc init testimage c4
+  lxc ls c4 -c nL --format csv | grep -Fx "c4,node1"
+
+  # The scriptlet can reject the request.
+  ! lxc init testimage c5 -c user.reject=true || false
+
+  # An explicit target bypasses the scriptlet.
+  lxc init testimage c5 -c user.reject=true --target node2
+  lxc ls c5 -c nL --format csv | grep -Fx "c5,node2"
+
+  # Members that can't be auto-targeted aren't candidates.
+  lxc cluster set node2 scheduler.instance manual
+  lxc init testimage c6 -c user.rack=rack2
+  lxc ls c6 -c nL --format csv | grep -Fx "c6,node1"
+  lxc cluster unset node2 scheduler.instance
+
+  lxc config unset instances.placement.scriptlet
+  lxc delete -f c1 c2 c3 c4 c5 c6
+
+  shutdown_lxd "${LXD_ONE_DIR}"
+  shutdown_lxd "${LXD_TWO_DIR}"
+  sleep 0.5
+  rm -f "${LXD_TWO_DIR}/unix.socket"
+  rm -f "${LXD_ONE_DIR}/unix.socket"
+
+  teardown_clustering_netns
+  teardown_clustering_bridge
+
+  kill_lxd "${LXD_ONE_DIR}"
+  kill_lxd "${LXD_TWO_DIR}"
+}
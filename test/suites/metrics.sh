@@ -13,6 +13,12 @@ test_metrics() {
   # c2 metrics should not exist as it's not running
   ! lxc query "/1.0/metrics" | grep "name=\"c2\"" || false
 
+  # daemon metrics should show when not filtering by project
+  lxc query "/1.0/metrics" | grep "^lxd_go_goroutines "
+  lxc query "/1.0/metrics" | grep "^lxd_api_requests_total{code=\"200\",endpoint=\"/1.0/metrics\",method=\"GET\"}"
+  lxc query "/1.0/metrics" | grep "^lxd_event_listeners{type=\"api\"}"
+  ! lxc query "/1.0/metrics?project=default" | grep "^lxd_go_goroutines " || false
+
   # create new certificate
   openssl req -x509 -newkey rsa:2048 -keyout "${TEST_DIR}/metrics.key" -nodes -out "${TEST_DIR}/metrics.crt" -subj "/CN=lxd.local"
 
@@ -0,0 +1,67 @@
+test_network_allocations() {
+  ensure_import_testimage
+  ensure_has_localhost_remote "${LXD_ADDR}"
+
+  netName=lxdt$$
+
+  lxc network create "${netName}" \
+        ipv4.address=192.0.2.1/24 \
+        ipv6.address=fd42:4242:4242:1010::1/64 \
+        ipv6.dhcp.stateful=true
+
+  # Check reservation validation.
+  ! lxc network reserve "${netName}" 198.51.100.10 || false # Outside of the network subnet.
+  ! lxc network reserve "${netName}" 192.0.2.1 || false # Network address.
+  ! lxc network reserve "${netName}" 192.0.2.0 || false # Subnet address.
+  ! lxc network reserve "${netName}" 192.0.2.10 --instance 1invalid || false # Invalid instance name.
+
+  lxc network forward create "${netName}" 192.0.2.20
+  ! lxc network reserve "${netName}" 192.0.2.20 || false # Forward listen address.
+  lxc network forward delete "${netName}" 192.0.2.20
+
+  # Check basic reservation creation, listing and deletion.
+  lxc network reserve "${netName}" 192.0.2.10 --description "Reserved address"
+  ! lxc network reserve "${netName}" 192.0.2.10 || false # Duplicate reservation.
+  lxc network list-reservations "${netName}" | grep 192.0.2.10
+  lxc network list-reservations "${netName}" | grep "Reserved address"
+  lxc query "/1.0/networks/${netName}/reservations/192.0.2.10" | jq -r .description | grep "Reserved address"
+  lxc network list-allocations --format csv | grep "/1.0/networks/${netName}/reservations/192.0.2.10,192.0.2.10/32,NETWORK-RESERVATION"
+  lxc network unreserve "${netName}" 192.0.2.10
+  ! lxc network list-reservations "${netName}" | grep 192.0.2.10 || false
+  ! lxc network unreserve "${netName}" 192.0.2.10 || false
+
+  # Check the network addresses are listed.
+  lxc network list-allocations --format csv | grep "/1.0/networks/${netName},192.0.2.1/24,NETWORK"
+  lxc network list-allocations --format csv | grep "/1.0/networks/${netName},fd42:4242:4242:1010::1/64,NETWORK"
+
+  # Check reservations for an instance that doesn't exist yet.
+  lxc network reserve "${netName}" 192.0.2.11 --instance c1
+  lxc network reserve "${netName}" fd42:4242:4242:1010::11 --instance c1
+  lxc network list-reservations "${netName}" --format csv | grep "192.0.2.11,c1"
+
+  # Check the reserved addresses can't be used as static addresses of other instances.
+  lxc init testimage c2 -n "${netName}"
+  lxc config device override c2 eth0 ipv4.address=192.0.2.11
+  ! lxc start c2 || false
+  lxc delete -f c2
+
+  # Check the reserved addresses are assigned to the instance NIC.
+  lxc init testimage c1 -n "${netName}"
+  lxc start c1
+  grep -F "192.0.2.11" "${LXD_DIR}/networks/${netName}/dnsmasq.hosts/c1.eth0"
+  grep -F "[fd42:4242:4242:1010::11]" "${LXD_DIR}/networks/${netName}/dnsmasq.hosts/c1.eth0"
+  lxc delete -f c1
+
+  # Check reservations without instances are written as dnsmasq host entries.
+  lxc network reserve "${netName}" 192.0.2.12
+  grep -F "192.0.2.12" "${LXD_DIR}/networks/${netName}/dnsmasq.hosts/.lxd-reserved-192-0-2-12"
+  lxc network unreserve "${netName}" 192.0.2.12
+  ! ls "${LXD_DIR}/networks/${netName}/dnsmasq.hosts/.lxd-reserved-192-0-2-12" || false
+
+  lxc network unreserve "${netName}" 192.0.2.11
+  lxc network unreserve "${netName}" fd42:4242:4242:1010::11
+
+  # Check reservations are removed with the network.
+  lxc network reserve "${netName}" 192.0.2.13
+  lxc network delete "${netName}"
+}
@@ -0,0 +1,102 @@
+test_network_load_balancer() {
+  ensure_import_testimage
+  ensure_has_localhost_remote "${LXD_ADDR}"
+
+  firewallDriver=$(lxc info | awk -F ":" '/firewall:/{gsub(/ /, "", $0); print $2}')
+  netName=lxdt$$
+
+  lxc network create "${netName}" \
+        ipv4.address=192.0.2.1/24 \
+        ipv6.address=fd42:4242:4242:1010::1/64
+
+  # Check creating empty load balancer doesn't create any firewall rules.
+  lxc network load-balancer create "${netName}" 198.51.100.1
+  if [ "$firewallDriver" = "xtables" ]; then
+    ! iptables -w -t nat -S | grep -c "generated for LXD network-load-balancer ${netName}" || false
+  else
+    ! nft -nn list chain inet lxd "lbprert.${netName}" || false
+    ! nft -nn list chain inet lxd "lbout.${netName}" || false
+    ! nft -nn list chain inet lxd "lbpstrt.${netName}" || false
+  fi
+
+  # Check load balancer is exported via BGP prefixes.
+  lxc query /internal/testing/bgp | grep "198.51.100.1/32"
+
+  # Check a forward can't be created using the same listen address.
+  ! lxc network forward create "${netName}" 198.51.100.1 || false
+
+  lxc network load-balancer delete "${netName}" 198.51.100.1
+
+  # Check deleting network load balancer removes load balancer BGP prefix.
+  ! lxc query /internal/testing/bgp | grep "198.51.100.1/32" || false
+
+  # Check a load balancer can't be created using the listen address of an existing forward.
+  lxc network forward create "${netName}" 198.51.100.2
+  ! lxc network load-balancer create "${netName}" 198.51.100.2 || false
+  lxc network forward delete "${netName}" 198.51.100.2
+
+  lxc network load-balancer create "${netName}" 198.51.100.1
+
+  # Check can't add backends outside of the network's subnet.
+  ! lxc network load-balancer backend add "${netName}" 198.51.100.1 be1 198.51.100.3 || false
+
+  # Check can't add a port that references an unknown backend.
+  ! lxc network load-balancer port add "${netName}" 198.51.100.1 tcp 80 be1 || false
+
+  lxc network load-balancer backend add "${netName}" 198.51.100.1 be1 192.0.2.2
+  lxc network load-balancer backend add "${netName}" 198.51.100.1 be2 192.0.2.3 8080
+
+  # Check can't add a duplicate backend name.
+  ! lxc network load-balancer backend add "${netName}" 198.51.100.1 be2 192.0.2.4 || false
+
+  # Check adding a port spreads connections over the backends.
+  lxc network load-balancer port add "${netName}" 198.51.100.1 tcp 80 be1,be2
+  if [ "$firewallDriver" = "xtables" ]; then
+    iptables -w -t nat -S | grep -- "-A PREROUTING -d 198.51.100.1/32 -p tcp -m tcp --dport 80 -m statistic --mode random --probability 0.50000000000 -m comment --comment \"generated for LXD network-load-balancer ${netName}\" -j DNAT --to-destination 192.0.2.2:80"
+    iptables -w -t nat -S | grep -- "-A PREROUTING -d 198.51.100.1/32 -p tcp -m tcp --dport 80 -m comment --comment \"generated for LXD network-load-balancer ${netName}\" -j DNAT --to-destination 192.0.2.3:8080"
+    iptables -w -t nat -S | grep -- "-A POSTROUTING -s 192.0.2.2/32 -d 192.0.2.2/32 -p tcp -m tcp --dport 80 -m comment --comment \"generated for LXD network-load-balancer ${netName}\" -j MASQUERADE"
+    iptables -w -t nat -S | grep -- "-A POSTROUTING -s 192.0.2.3/32 -d 192.0.2.3/32 -p tcp -m tcp --dport 8080 -m comment --comment \"generated for LXD network-load-balancer ${netName}\" -j MASQUERADE"
+  else
+    nft -nn list chain inet lxd "lbprert.${netName}" | grep "ip daddr 198.51.100.1 tcp dport 80 dnat ip to numgen random mod 2 map"
+    nft -nn list chain inet lxd "lbout.${netName}" | grep "ip daddr 198.51.100.1 tcp dport 80 dnat ip to numgen random mod 2 map"
+    nft -nn list chain inet lxd "lbpstrt.${netName}" | grep "ip saddr 192.0.2.2 ip daddr 192.0.2.2 tcp dport 80 masquerade"
+    nft -nn list chain inet lxd "lbpstrt.${netName}" | grep "ip saddr 192.0.2.3 ip daddr 192.0.2.3 tcp dport 8080 masquerade"
+  fi
+
+  # Check can't add a port with mismatched backend target ports.
+  lxc network load-balancer backend add "${netName}" 198.51.100.1 be3 192.0.2.4 90,91
+  ! lxc network load-balancer port add "${netName}" 198.51.100.1 tcp 81-83 be3 || false
+
+  # Check can't remove a backend that doesn't exist.
+  ! lxc network load-balancer backend remove "${netName}" 198.51.100.1 be4 || false
+  lxc network load-balancer backend remove "${netName}" 198.51.100.1 be3
+
+  # Check removing ports clears the firewall rules.
+  lxc network load-balancer port remove "${netName}" 198.51.100.1 tcp 80
+  if [ "$firewallDriver" = "xtables" ]; then
+    ! iptables -w -t nat -S | grep -c "generated for LXD network-load-balancer ${netName}" || false
+  else
+    ! nft -nn list chain inet lxd "lbprert.${netName}" || false
+    ! nft -nn list chain inet lxd "lbout.${netName}" || false
+    ! nft -nn list chain inet lxd "lbpstrt.${netName}" || false
+  fi
+
+  lxc network load-balancer port add "${netName}" 198.51.100.1 tcp 80 be1,be2
+
+  # Check load balancer is exported via BGP prefixes before network delete.
+  lxc query /internal/testing/bgp | grep "198.51.100.1/32"
+
+  # Check deleting the network clears the load balancer firewall rules.
+  lxc network delete "${netName}"
+
+  # Check deleting network removes load balancer BGP prefix.
+  ! lxc query /internal/testing/bgp | grep "198.51.100.1/32" || false
+
+  if [ "$firewallDriver" = "xtables" ]; then
+    ! iptables -w -t nat -S | grep -c "generated for LXD network-load-balancer ${netName}" || false
+  else
+    ! nft -nn list chain inet lxd "lbprert.${netName}" || false
+    ! nft -nn list chain inet lxd "lbout.${netName}" || false
+    ! nft -nn list chain inet lxd "lbpstrt.${netName}" || false
+  fi
+}
@@ -0,0 +1,94 @@
+test_network_qos() {
+  ensure_import_testimage
+  ensure_has_localhost_remote "${LXD_ADDR}"
+
+  # Check basic QoS policy creation, listing, deletion and project namespacing support.
+  ! lxc network qos create 192.168.1.1 || false # Don't allow non-hostname compatible names.
+  lxc network qos create testqos
+  lxc project create testproj -c features.networks=true
+  lxc project create testproj2 -c features.networks=false
+  lxc network qos create testqos --project testproj
+  lxc project show testproj | grep testqos # Check project sees testqos using it.
+  ! lxc network qos create testqos --project testproj2 || false
+  lxc network qos ls | grep testqos
+  lxc network qos ls --project testproj | grep testqos
+  lxc network qos delete testqos
+  lxc network qos delete testqos --project testproj
+  ! lxc network qos ls | grep testqos || false
+  ! lxc network qos ls --project testproj | grep testqos || false
+  lxc project delete testproj
+  lxc project delete testproj2
+
+  # QoS policy config validation.
+  ! lxc network qos create testqos limits.ingress=foo || false # Invalid rate.
+  ! lxc network qos create testqos limits.ingress.burst=1MiB || false # Burst without rate.
+  ! lxc network qos create testqos priority=urgent || false # Invalid priority.
+  ! lxc network qos create testqos dscp=64 || false # Invalid DSCP.
+  ! lxc network qos create testqos non.userkey=foo || false # Invalid key.
+
+  # QoS policy creation from stdin.
+  cat <<EOF | lxc network qos create testqos
+description: Test QoS policy
+config:
+  limits.ingress: 100Mbit
+  limits.egress: 50Mbit
+  limits.egress.burst: 1MiB
+  priority: low
+  user.mykey: foo
+EOF
+  lxc network qos show testqos | grep "description: Test QoS policy"
+  lxc network qos show testqos | grep "limits.ingress: 100Mbit"
+  lxc network qos show testqos | grep "priority: low"
+  lxc network qos show testqos | grep "user.mykey: foo"
+
+  # QoS policy patch. Check for merged config and replaced description.
+  lxc query -X PATCH -d "{\\\"config\\\": {\\\"dscp\\\": \\\"46\\\"}}" /1.0/network-qos/testqos
+  lxc network qos show testqos | grep "user.mykey: foo"
+  lxc network qos show testqos | grep 'dscp: "46"'
+  lxc network qos show testqos | grep 'description: ""'
+
+  # QoS policy edit from stdin.
+  cat <<EOF | lxc network qos edit testqos
+description: Test QoS policy updated
+config:
+  limits.ingress: 10Mbit
+  priority: high
+EOF
+  lxc network qos show testqos | grep "description: Test QoS policy updated"
+  lxc network qos get testqos limits.ingress | grep 10Mbit
+  ! lxc network qos get testqos limits.egress | grep 50Mbit || false
+
+  # QoS policy custom config.
+  lxc network qos set testqos user.somekey foo
+  lxc network qos get testqos user.somekey | grep foo
+  ! lxc network qos set testqos non.userkey foo || false
+  lxc network qos unset testqos user.somekey
+  ! lxc network qos get testqos user.somekey | grep foo || false
+
+  # QoS policy usage by NICs.
+  lxc profile create qosprofile
+  ! lxc profile device add qosprofile eth0 nic nictype=p2p qos=missing || false # Unknown policy.
+  ! lxc profile device add qosprofile eth0 nic nictype=p2p qos=testqos limits.max=10Mbit || false # Exclusive with limits.
+  lxc profile device add qosprofile eth0 nic nictype=p2p qos=testqos
+  lxc network qos show testqos | grep "/1.0/profiles/qosprofile"
+  ! lxc network qos rename testqos testqos2 || false # Policy in use.
+  ! lxc network qos delete testqos || false # Policy in use.
+
+  # QoS policy changes are applied to the running instance NICs.
+  lxc init testimage qosct -p default -p qosprofile
+  lxc start qosct
+  host_name=$(lxc config get qosct volatile.eth0.host_name)
+  tc qdisc show dev "${host_name}" | grep htb
+  lxc network qos unset testqos limits.ingress
+  ! tc qdisc show dev "${host_name}" | grep htb || false
+  lxc delete -f qosct
+
+  lxc profile delete qosprofile
+
+  # QoS policy rename.
+  ! lxc network qos rename testqos 192.168.1.1 || false # Don't allow non-hostname compatible names.
+  lxc network qos rename testqos testqos2
+  lxc network qos show testqos2
+
+  lxc network qos delete testqos2
+}
@@ -0,0 +1,41 @@
+test_oidc() {
+    ensure_has_localhost_remote "${LXD_ADDR}"
+
+    # Setup the OpenID Connect provider.
+    spawn_oidc
+    set_oidc user1
+
+    # OpenID Connect stays disabled until both the issuer and client ID are set.
+    lxc config set "oidc.issuer=http://127.0.0.1:$(cat "${TEST_DIR}/oidc.port")"
+    ! lxc query /1.0 | jq -r '.auth_methods[]' | grep -qx oidc || false
+    lxc config set "oidc.client.id=device"
+
+    # The server advertises the authentication method.
+    lxc query /1.0 | jq -r '.auth_methods[]' | grep -qx oidc
+
+    # Unauthenticated requests are told where to authenticate.
+    curl -s -o /dev/null -w "%{http_code}" "https://${LXD_ADDR}/1.0/instances" -k | grep -qx 401
+    curl -s -D - -o /dev/null "https://${LXD_ADDR}/1.0/instances" -k | grep -qi "^X-LXD-OIDC-issuer: http://127.0.0.1"
+
+    # Invalid tokens are rejected.
+    [ "$(curl -s -o /dev/null -w "%{http_code}" -H "Authorization: Bearer invalid" "https://${LXD_ADDR}/1.0/instances" -k)" = "401" ]
+
+    # Login using the device authorization flow.
+    lxc remote add --accept-certificate oidc "${LXD_ADDR}" --auth-type oidc
+    [ -s "${LXD_CONF}/oidctokens/oidc.json" ]
+    [ "$(lxc query oidc:/1.0 | jq -r '.auth')" = "trusted" ]
+    lxc list oidc:
+
+    # Tokens are refreshed when no longer accepted.
+    jq '.access_token = "invalid"' "${LXD_CONF}/oidctokens/oidc.json" > "${TEST_DIR}/oidc.json"
+    mv "${TEST_DIR}/oidc.json" "${LXD_CONF}/oidctokens/oidc.json"
+    lxc list oidc:
+    [ "$(jq -r '.access_token' "${LXD_CONF}/oidctokens/oidc.json")" != "invalid" ]
+
+    # Cleanup.
+    lxc remote remove oidc
+    lxc config unset oidc.issuer
+    lxc config unset oidc.client.id
+
+    kill_oidc
+}
@@ -0,0 +1,82 @@
+test_storage_buckets() {
+  # shellcheck disable=2039
+  local lxd_backend
+
+  lxd_backend=$(storage_backend "$LXD_DIR")
+
+  if [ "$lxd_backend" = "ceph" ]; then
+    if [ -z "${LXD_CEPH_CEPHRGW_URL:-}" ]; then
+      echo "==> SKIP: Storage buckets require LXD_CEPH_CEPHRGW_URL on ceph"
+      return
+    fi
+
+    lxc storage create s3 ceph volume.size=25MB ceph.osd.pg_num=16 ceph.rgw.endpoint="${LXD_CEPH_CEPHRGW_URL}"
+  else
+    if ! command -v minio >/dev/null 2>&1; then
+      echo "==> SKIP: Storage buckets require the minio command"
+      return
+    fi
+
+    lxc config set core.storage_buckets_address "127.0.0.1:$(local_tcp_port)"
+    lxc storage create s3 "${lxd_backend}"
+  fi
+
+  # Check invalid bucket names are rejected.
+  ! lxc storage bucket create s3 "InvalidName" || false
+  ! lxc storage bucket create s3 "a" || false
+
+  # Create a bucket and check its initial admin key.
+  lxc storage bucket create s3 bucket1 size=1GiB
+  lxc storage bucket list s3 | grep -q bucket1
+  lxc storage bucket show s3 bucket1 | grep -q "s3_url:"
+  [ "$(lxc storage bucket get s3 bucket1 size)" = "1GiB" ]
+  lxc storage bucket key list s3 bucket1 | grep -q admin
+
+  # Check duplicate buckets are rejected.
+  ! lxc storage bucket create s3 bucket1 || false
+
+  # Update the bucket.
+  lxc storage bucket set s3 bucket1 size=2GiB
+  [ "$(lxc storage bucket get s3 bucket1 size)" = "2GiB" ]
+  lxc storage bucket show s3 bucket1 | sed 's/^description:.*/description: foo/' | lxc storage bucket edit s3 bucket1
+  lxc storage bucket show s3 bucket1 | grep -q "description: foo"
+  lxc storage bucket unset s3 bucket1 size
+  [ "$(lxc storage bucket get s3 bucket1 size)" = "" ]
+
+  # Create keys with generated and specified credentials.
+  lxc storage bucket key create s3 bucket1 reader
+  lxc storage bucket key show s3 bucket1 reader | grep -q "role: read-only"
+  lxc storage bucket key create s3 bucket1 writer --role=admin --access-key=lxdtestaccesskey --secret-key=lxdtestsecretkey
+  lxc storage bucket key show s3 bucket1 writer | grep -q "role: admin"
+  lxc storage bucket key show s3 bucket1 writer | grep -q "access-key: lxdtestaccesskey"
+  ! lxc storage bucket key create s3 bucket1 invalid --role=invalid || false
+  ! lxc storage bucket key create s3 bucket1 reader || false
+
+  # Update a key.
+  lxc storage bucket key show s3 bucket1 reader | sed 's/^role:.*/role: admin/' | lxc storage bucket key edit s3 bucket1 reader
+  lxc storage bucket key show s3 bucket1 reader | grep -q "role: admin"
+
+  # Check unauthenticated S3 requests are rejected.
+  s3URL=$(lxc storage bucket show s3 bucket1 | awk '/^s3_url:/ {print $2}')
+  [ "$(curl -sk -o /dev/null -w "%{http_code}" "${s3URL}")" = "403" ]
+
+  # Delete keys.
+  lxc storage bucket key delete s3 bucket1 reader
+  lxc storage bucket key delete s3 bucket1 writer
+  ! lxc storage bucket key show s3 bucket1 reader || false
+
+  # Check buckets are project specific.
+  lxc project create foo -c features.storage.volumes=true
+  ! lxc storage bucket show s3 bucket1 --project foo || false
+  lxc project delete foo
+
+  # Delete the bucket.
+  lxc storage bucket delete s3 bucket1
+  ! lxc storage bucket show s3 bucket1 || false
+
+  lxc storage delete s3
+
+  if [ "$lxd_backend" != "ceph" ]; then
+    lxc config unset core.storage_buckets_address
+  fi
+}
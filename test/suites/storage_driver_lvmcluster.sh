@@ -0,0 +1,77 @@
+test_storage_driver_lvmcluster() {
+  # shellcheck disable=2039
+  local LXD_STORAGE_DIR lxd_backend
+
+  lxd_backend=$(storage_backend "$LXD_DIR")
+  if [ "$lxd_backend" != "lvm" ]; then
+    return
+  fi
+
+  # Requires lvmlockd to be running with a lock manager (sanlock or dlm).
+  if ! command -v lvmlockctl >/dev/null 2>&1 || ! lvmlockctl --info >/dev/null 2>&1; then
+    echo "==> SKIP: lvmcluster tests require a running lvmlockd"
+    return
+  fi
+
+  LXD_STORAGE_DIR=$(mktemp -d -p "${TEST_DIR}" XXXXXXXXX)
+  chmod +x "${LXD_STORAGE_DIR}"
+  spawn_lxd "${LXD_STORAGE_DIR}" false
+
+  (
+    set -e
+    # shellcheck disable=2030
+    LXD_DIR="${LXD_STORAGE_DIR}"
+    pool="lxdtest-$(basename "${LXD_DIR}")-pool1"
+
+    # Loop files can't be shared between cluster members.
+    ! lxc storage create "${pool}" lvmcluster || false
+
+    configure_loop_device loop_file_1 loop_device_1
+
+    # Thin pools can't be used with shared volume groups.
+    # shellcheck disable=SC2154
+    ! lxc storage create "${pool}" lvmcluster source="${loop_device_1}" lvm.use_thinpool=true || false
+    ! lxc storage create "${pool}" lvmcluster source="${loop_device_1}" lvm.thinpool_name=bla || false
+
+    lxc storage create "${pool}" lvmcluster source="${loop_device_1}" volume.size=25MB
+    vgs --noheadings -o vg_lock_type "${pool}" | grep -qE "sanlock|dlm"
+    lxc storage show "${pool}" | grep -q "driver: lvmcluster"
+    lxc storage info "${pool}"
+
+    # Set default storage pool for image import.
+    lxc profile device add default root disk path="/" pool="${pool}"
+    ensure_import_testimage
+
+    # Volumes are activated with an exclusive lock while in use.
+    lxc launch testimage c1
+    lvs --noheadings -o lv_active_exclusively "${pool}/containers_c1" | grep -q "active exclusively"
+    lxc snapshot c1
+    lxc stop -f c1
+    ! lvs --noheadings -o lv_active "${pool}/containers_c1" | grep -q "active" || false
+
+    lxc storage volume create "${pool}" vol1
+    lxc storage volume attach "${pool}" vol1 c1 /opt
+    lxc start c1
+    lxc exec c1 -- touch /opt/foo
+    lxc stop -f c1
+    lxc storage volume detach "${pool}" vol1 c1
+    lxc storage volume delete "${pool}" vol1
+
+    lxc delete -f c1
+    lxc image delete testimage
+    lxc profile device remove default root
+    lxc storage delete "${pool}"
+
+    # Use an existing shared volume group.
+    vgcreate --shared "${pool}-vg" "${loop_device_1}"
+    ! lxc storage create "${pool}" lvmcluster source="${pool}-vg" lvm.use_thinpool=true || false
+    lxc storage create "${pool}" lvmcluster source="${pool}-vg"
+    lxc storage delete "${pool}"
+
+    # shellcheck disable=SC2154
+    deconfigure_loop_device "${loop_file_1}" "${loop_device_1}"
+  )
+
+  # shellcheck disable=SC2031
+  kill_lxd "${LXD_STORAGE_DIR}"
+}